@@ -3,11 +3,14 @@ package agents
 import (
 	"context"
 	"errors"
+	"sync"
 	"testing"
 	
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	
+	"github.com/zhucl121/langchain-go/core/callbacks"
+	"github.com/zhucl121/langchain-go/core/chat"
 	"github.com/zhucl121/langchain-go/core/runnable"
 	"github.com/zhucl121/langchain-go/core/tools"
 	"github.com/zhucl121/langchain-go/pkg/types"
@@ -220,6 +223,36 @@ func TestExecutor_Execute(t *testing.T) {
 		assert.False(t, result.Success)
 		assert.Equal(t, 3, result.TotalSteps)
 	})
+	
+	t.Run("Prompt caching", func(t *testing.T) {
+		// 每一步的模型调用都应开启自动提示词缓存
+		calls := 0
+		mockLLM.InvokeFunc = func(ctx context.Context, messages []types.Message, opts ...runnable.Option) (types.Message, error) {
+			calls++
+			assert.True(t, chat.PromptCachingFromContext(ctx))
+			if calls == 1 {
+				return types.NewAssistantMessage("Thought: I need to use calculator\nAction: calculator\nAction Input: 6*7"), nil
+			}
+			return types.NewAssistantMessage("Final Answer: 42"), nil
+		}
+		
+		config := AgentConfig{
+			Type:  AgentTypeReAct,
+			LLM:   mockLLM,
+			Tools: []tools.Tool{mockTool},
+		}
+		
+		agent, err := CreateAgent(config)
+		require.NoError(t, err)
+		
+		executor := NewExecutor(agent).WithMaxSteps(5).WithPromptCaching(true)
+		
+		result, err := executor.Execute(context.Background(), "What is 6*7?")
+		
+		assert.NoError(t, err)
+		assert.True(t, result.Success)
+		assert.Equal(t, 2, calls)
+	})
 }
 
 // TestAgentAction
@@ -442,3 +475,113 @@ func TestExecutor_ToolCallError(t *testing.T) {
 	assert.GreaterOrEqual(t, len(result.Steps), 1) // 至少有一步
 	assert.NotNil(t, result.Steps[0].Error) // 第一步应该有错误
 }
+
+// agentRecorder 记录 Agent 执行的回调事件
+type agentRecorder struct {
+	callbacks.BaseHandler
+
+	mu     sync.Mutex
+	events []string
+	runs   map[string]callbacks.RunInfo
+}
+
+func (r *agentRecorder) record(event string, info callbacks.RunInfo) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+	r.runs[info.RunID] = info
+}
+
+func (r *agentRecorder) OnChainStart(ctx context.Context, e *callbacks.ChainStartEvent) {
+	r.record("start:"+e.Name, e.RunInfo)
+}
+
+func (r *agentRecorder) OnChainEnd(ctx context.Context, e *callbacks.ChainEndEvent) {
+	r.record("end:"+e.Name, e.RunInfo)
+}
+
+func (r *agentRecorder) OnToolStart(ctx context.Context, e *callbacks.ToolStartEvent) {
+	r.record("tool:"+e.Name, e.RunInfo)
+}
+
+func (r *agentRecorder) OnAgentAction(ctx context.Context, e *callbacks.AgentActionEvent) {
+	r.record("action:"+e.Tool, e.RunInfo)
+}
+
+func (r *agentRecorder) OnAgentFinish(ctx context.Context, e *callbacks.AgentFinishEvent) {
+	r.record("finish:"+e.Output, e.RunInfo)
+}
+
+func TestExecutor_Callbacks(t *testing.T) {
+	mockLLM := NewMockChatModel()
+	calls := 0
+	mockLLM.InvokeFunc = func(ctx context.Context, messages []types.Message, opts ...runnable.Option) (types.Message, error) {
+		calls++
+		if calls == 1 {
+			return types.NewAssistantMessage("Thought: I need to use calculator\nAction: calculator\nAction Input: 6*7"), nil
+		}
+		return types.NewAssistantMessage("Final Answer: 42"), nil
+	}
+
+	agent, err := CreateAgent(AgentConfig{
+		Type:  AgentTypeReAct,
+		LLM:   mockLLM,
+		Tools: []tools.Tool{NewMockTool("calculator", "Calculate")},
+	})
+	require.NoError(t, err)
+
+	rec := &agentRecorder{runs: make(map[string]callbacks.RunInfo)}
+	ctx := callbacks.WithHandlers(context.Background(), rec)
+	_, err = NewExecutor(agent).Execute(ctx, "What is 6*7?")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"start:react",
+		"start:agent_step", "action:calculator", "tool:calculator", "end:agent_step",
+		"start:agent_step", "finish:42", "end:agent_step",
+		"end:react",
+	}, rec.events)
+
+	// 工具运行的父运行是第一步，步骤的父运行是 Agent 运行
+	var agentRun, toolRun callbacks.RunInfo
+	for _, info := range rec.runs {
+		switch info.Type {
+		case callbacks.RunTypeAgent:
+			agentRun = info
+		case callbacks.RunTypeTool:
+			toolRun = info
+		}
+	}
+	step := rec.runs[toolRun.ParentRunID]
+	assert.Equal(t, "agent_step", step.Name)
+	assert.Equal(t, 1, step.Metadata["step"])
+	assert.Equal(t, agentRun.RunID, step.ParentRunID)
+}
+
+func TestExecutor_GlobalCallbacks(t *testing.T) {
+	mockLLM := NewMockChatModel()
+	mockLLM.InvokeFunc = func(ctx context.Context, messages []types.Message, opts ...runnable.Option) (types.Message, error) {
+		return types.NewAssistantMessage("Final Answer: 42"), nil
+	}
+
+	agent, err := CreateAgent(AgentConfig{
+		Type:  AgentTypeReAct,
+		LLM:   mockLLM,
+		Tools: []tools.Tool{NewMockTool("calculator", "Calculate")},
+	})
+	require.NoError(t, err)
+
+	// 只安装全局处理器，上下文中没有处理器
+	rec := &agentRecorder{runs: make(map[string]callbacks.RunInfo)}
+	callbacks.AddGlobalHandlers(rec)
+	defer callbacks.ResetGlobalHandlers()
+
+	_, err = NewExecutor(agent).Execute(context.Background(), "What is 6*7?")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"start:react",
+		"start:agent_step", "finish:42", "end:agent_step",
+		"end:react",
+	}, rec.events)
+}
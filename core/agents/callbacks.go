@@ -0,0 +1,33 @@
+package agents
+
+import (
+	"context"
+
+	"github.com/zhucl121/langchain-go/core/callbacks"
+)
+
+// startAgent 开始一次 Agent 执行的回调运行，运行名称为 Agent 类型。
+func startAgent(ctx context.Context, agent Agent, input string) (context.Context, *callbacks.Run) {
+	return callbacks.StartAgent(ctx, string(agent.GetType()), input)
+}
+
+// startStep 开始 Agent 第 step 步（从 1 开始）的回调运行。
+//
+// 步骤序号记录在元数据 "step" 中，由这一步的模型和工具调用继承。
+func startStep(ctx context.Context, step int) (context.Context, *callbacks.Run) {
+	if !callbacks.HasHandlers(ctx) {
+		return ctx, nil
+	}
+	ctx = callbacks.WithMetadata(ctx, map[string]any{"step": step})
+	return callbacks.StartChain(ctx, "agent_step", step)
+}
+
+// reportAction 向 Agent 的回调运行发送 OnAgentAction 或 OnAgentFinish。
+func reportAction(ctx context.Context, run *callbacks.Run, action *AgentAction) {
+	switch action.Type {
+	case ActionToolCall:
+		run.AgentAction(ctx, action.Tool, action.ToolInput, action.Log)
+	case ActionFinish:
+		run.AgentFinish(ctx, action.FinalAnswer, action.Log)
+	}
+}
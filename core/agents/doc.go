@@ -32,4 +32,11 @@
 //	executor := agents.NewExecutor(agent)
 //	result, err := executor.Execute(ctx, "帮我查询今天的天气")
 //
+// # 回调
+//
+// 上下文中有回调处理器（callbacks.WithHandlers）时，每次执行是一次 Agent 运行，
+// 每一步是名为 "agent_step" 的子运行（元数据 "step" 为步骤序号），
+// 这一步的模型和工具调用又是步骤的子运行。规划出的行动和最终答案
+// 以 OnAgentAction / OnAgentFinish 发送。
+//
 package agents
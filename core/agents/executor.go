@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"time"
 	
+	"github.com/zhucl121/langchain-go/core/callbacks"
+	"github.com/zhucl121/langchain-go/core/chat"
 	"github.com/zhucl121/langchain-go/core/middleware"
 	"github.com/zhucl121/langchain-go/core/tools"
 )
@@ -17,6 +19,7 @@ import (
 //   - 最大步数控制
 //   - 中间件集成
 //   - Skill 集成
+//   - 提示词缓存
 //
 type Executor struct {
 	agent           Agent
@@ -25,6 +28,7 @@ type Executor struct {
 	middlewareChain *middleware.Chain
 	skillManager    SkillManager
 	enabledSkills   []string
+	promptCaching   bool
 }
 
 // NewExecutor 创建 Agent 执行器。
@@ -68,6 +72,16 @@ func (e *Executor) WithVerbose(verbose bool) *Executor {
 	return e
 }
 
+// WithPromptCaching 设置是否自动缓存提示词。
+//
+// 启用后，每一步的模型调用都会在系统提示词和工具定义上放置缓存断点
+// （见 chat.WithPromptCaching），后续步骤复用缓存的前缀，只为新增的对话付费。
+// 仅对支持显式缓存的提供商（Anthropic）生效，OpenAI 会自动缓存相同的前缀。
+func (e *Executor) WithPromptCaching(enabled bool) *Executor {
+	e.promptCaching = enabled
+	return e
+}
+
 // WithMiddleware 添加中间件。
 func (e *Executor) WithMiddleware(mw middleware.Middleware) *Executor {
 	e.middlewareChain.Use(mw)
@@ -85,6 +99,17 @@ func (e *Executor) WithMiddleware(mw middleware.Middleware) *Executor {
 //   - error: 错误
 //
 func (e *Executor) Execute(ctx context.Context, input string) (*AgentResult, error) {
+	ctx = e.withPromptCaching(ctx)
+
+	ctx, run := startAgent(ctx, e.agent, input)
+	result, err := e.execute(ctx, run, input)
+	run.EndChain(ctx, result, err)
+	return result, err
+}
+
+// execute 执行 Agent 循环，run 为本次执行的回调运行。
+func (e *Executor) execute(ctx context.Context, run *callbacks.Run, input string) (*AgentResult, error) {
+
 	// 初始化 Skills
 	if err := e.initializeSkills(ctx); err != nil {
 		return nil, fmt.Errorf("failed to initialize skills: %w", err)
@@ -106,13 +131,15 @@ func (e *Executor) Execute(ctx context.Context, input string) (*AgentResult, err
 			fmt.Printf("\n[Step %d]\n", step+1)
 		}
 
+		stepCtx, stepRun := startStep(ctx, step+1)
+
 		// 使用中间件包装 Plan 调用
 		var action *AgentAction
 		var err error
 
 		if e.middlewareChain.Len() > 0 {
 			// 通过中间件执行
-			planResult, planErr := e.middlewareChain.Execute(ctx, input, func(ctx context.Context, in any) (any, error) {
+			planResult, planErr := e.middlewareChain.Execute(stepCtx, input, func(ctx context.Context, in any) (any, error) {
 				return e.agent.Plan(ctx, input, history)
 			})
 
@@ -123,10 +150,11 @@ func (e *Executor) Execute(ctx context.Context, input string) (*AgentResult, err
 			}
 		} else {
 			// 直接执行
-			action, err = e.agent.Plan(ctx, input, history)
+			action, err = e.agent.Plan(stepCtx, input, history)
 		}
 
 		if err != nil {
+			stepRun.EndChain(stepCtx, nil, err)
 			result.Error = err
 			return result, fmt.Errorf("executor: plan failed at step %d: %w", step+1, err)
 		}
@@ -134,24 +162,27 @@ func (e *Executor) Execute(ctx context.Context, input string) (*AgentResult, err
 		if e.verbose {
 			fmt.Printf("Action: %+v\n", action)
 		}
+		reportAction(ctx, run, action)
 
 		// 检查行动类型
 		switch action.Type {
 		case ActionFinish:
 			// 任务完成
+			stepRun.EndChain(stepCtx, action, nil)
 			result.Output = action.FinalAnswer
 			result.Success = true
 			return result, nil
 
 		case ActionToolCall:
 			// 执行工具调用
-			observation, toolErr := e.executeToolCall(ctx, action)
+			observation, toolErr := e.executeToolCall(stepCtx, action)
 
 			currentStep := AgentStep{
 				Action:      action,
 				Observation: observation,
 				Error:       toolErr,
 			}
+			stepRun.EndChain(stepCtx, currentStep, toolErr)
 
 			result.Steps = append(result.Steps, currentStep)
 			history = append(history, currentStep)
@@ -165,10 +196,12 @@ func (e *Executor) Execute(ctx context.Context, input string) (*AgentResult, err
 
 		case ActionError:
 			result.Error = fmt.Errorf("agent returned error action")
+			stepRun.EndChain(stepCtx, action, result.Error)
 			return result, result.Error
 
 		default:
 			result.Error = fmt.Errorf("unknown action type: %s", action.Type)
+			stepRun.EndChain(stepCtx, action, result.Error)
 			return result, result.Error
 		}
 	}
@@ -187,7 +220,7 @@ func (e *Executor) executeToolCall(ctx context.Context, action *AgentAction) (st
 	}
 
 	// 执行工具
-	toolResult, err := tool.Execute(ctx, action.ToolInput)
+	toolResult, err := tools.Run(ctx, tool, action.ToolInput)
 	if err != nil {
 		return "", fmt.Errorf("tool execution failed: %w", err)
 	}
@@ -226,6 +259,22 @@ func (e *Executor) Stream(
 	input string,
 	callback func(step AgentStep) error,
 ) (*AgentResult, error) {
+	ctx = e.withPromptCaching(ctx)
+
+	ctx, run := startAgent(ctx, e.agent, input)
+	result, err := e.stream(ctx, run, input, callback)
+	run.EndChain(ctx, result, err)
+	return result, err
+}
+
+// stream 流式执行 Agent 循环，run 为本次执行的回调运行。
+func (e *Executor) stream(
+	ctx context.Context,
+	run *callbacks.Run,
+	input string,
+	callback func(step AgentStep) error,
+) (*AgentResult, error) {
+
 	result := &AgentResult{
 		Steps:      make([]AgentStep, 0),
 		TotalSteps: 0,
@@ -236,16 +285,20 @@ func (e *Executor) Stream(
 
 	for step := 0; step < e.maxSteps; step++ {
 		result.TotalSteps = step + 1
+		stepCtx, stepRun := startStep(ctx, step+1)
 
 		// 规划
-		action, err := e.agent.Plan(ctx, input, history)
+		action, err := e.agent.Plan(stepCtx, input, history)
 		if err != nil {
+			stepRun.EndChain(stepCtx, nil, err)
 			result.Error = err
 			return result, err
 		}
+		reportAction(ctx, run, action)
 
 		// 检查完成
 		if action.Type == ActionFinish {
+			stepRun.EndChain(stepCtx, action, nil)
 			result.Output = action.FinalAnswer
 			result.Success = true
 			return result, nil
@@ -253,13 +306,14 @@ func (e *Executor) Stream(
 
 		// 执行工具
 		if action.Type == ActionToolCall {
-			observation, toolErr := e.executeToolCall(ctx, action)
+			observation, toolErr := e.executeToolCall(stepCtx, action)
 
 			currentStep := AgentStep{
 				Action:      action,
 				Observation: observation,
 				Error:       toolErr,
 			}
+			stepRun.EndChain(stepCtx, currentStep, toolErr)
 
 			result.Steps = append(result.Steps, currentStep)
 			history = append(history, currentStep)
@@ -270,6 +324,8 @@ func (e *Executor) Stream(
 					return result, fmt.Errorf("executor: callback failed: %w", err)
 				}
 			}
+		} else {
+			stepRun.EndChain(stepCtx, action, nil)
 		}
 	}
 
@@ -305,6 +361,14 @@ func (e *Executor) Batch(ctx context.Context, inputs []string) ([]*AgentResult,
 	return results, nil
 }
 
+// withPromptCaching 在启用提示词缓存时为上下文开启自动缓存。
+func (e *Executor) withPromptCaching(ctx context.Context) context.Context {
+	if !e.promptCaching {
+		return ctx
+	}
+	return chat.WithPromptCaching(ctx)
+}
+
 // GetAgent 返回 Agent。
 func (e *Executor) GetAgent() Agent {
 	return e.agent
@@ -387,6 +451,14 @@ func NewAgentExecutor(config AgentExecutorConfig) *AgentExecutor {
 //   - error: 错误
 //
 func (ae *AgentExecutor) Run(ctx context.Context, input string) (*AgentResult, error) {
+	ctx, run := startAgent(ctx, ae.agent, input)
+	result, err := ae.run(ctx, run, input)
+	run.EndChain(ctx, result, err)
+	return result, err
+}
+
+// run 执行 Agent 循环，run 为本次执行的回调运行。
+func (ae *AgentExecutor) run(ctx context.Context, run *callbacks.Run, input string) (*AgentResult, error) {
 	result := &AgentResult{
 		Steps:      make([]AgentStep, 0),
 		TotalSteps: 0,
@@ -402,9 +474,12 @@ func (ae *AgentExecutor) Run(ctx context.Context, input string) (*AgentResult, e
 			fmt.Printf("\n[Step %d]\n", step+1)
 		}
 
+		stepCtx, stepRun := startStep(ctx, step+1)
+
 		// 规划下一步
-		action, err := ae.agent.Plan(ctx, input, history)
+		action, err := ae.agent.Plan(stepCtx, input, history)
 		if err != nil {
+			stepRun.EndChain(stepCtx, nil, err)
 			result.Error = err
 			return result, fmt.Errorf("agent executor: plan failed at step %d: %w", step+1, err)
 		}
@@ -412,9 +487,11 @@ func (ae *AgentExecutor) Run(ctx context.Context, input string) (*AgentResult, e
 		if ae.verbose {
 			fmt.Printf("Action: %+v\n", action)
 		}
+		reportAction(ctx, run, action)
 
 		// 检查是否完成
 		if action.Type == ActionFinish {
+			stepRun.EndChain(stepCtx, action, nil)
 			result.Output = action.FinalAnswer
 			result.Success = true
 			return result, nil
@@ -422,13 +499,14 @@ func (ae *AgentExecutor) Run(ctx context.Context, input string) (*AgentResult, e
 
 		// 执行工具调用
 		if action.Type == ActionToolCall {
-			observation, toolErr := ae.executeToolWithExecutor(ctx, action)
+			observation, toolErr := ae.executeToolWithExecutor(stepCtx, action)
 
 			currentStep := AgentStep{
 				Action:      action,
 				Observation: observation,
 				Error:       toolErr,
 			}
+			stepRun.EndChain(stepCtx, currentStep, toolErr)
 
 			result.Steps = append(result.Steps, currentStep)
 			history = append(history, currentStep)
@@ -439,6 +517,8 @@ func (ae *AgentExecutor) Run(ctx context.Context, input string) (*AgentResult, e
 					fmt.Printf("Error: %v\n", toolErr)
 				}
 			}
+		} else {
+			stepRun.EndChain(stepCtx, action, nil)
 		}
 	}
 
@@ -524,6 +604,8 @@ func (ae *AgentExecutor) Stream(ctx context.Context, input string) <-chan AgentS
 	go func() {
 		defer close(eventChan)
 
+		ctx, run := startAgent(ctx, ae.agent, input)
+
 		// 发送开始事件
 		eventChan <- AgentStreamEvent{
 			Type:      EventTypeStart,
@@ -540,9 +622,13 @@ func (ae *AgentExecutor) Stream(ctx context.Context, input string) <-chan AgentS
 				Timestamp: time.Now(),
 			}
 
+			stepCtx, stepRun := startStep(ctx, step+1)
+
 			// 规划
-			action, err := ae.agent.Plan(ctx, input, history)
+			action, err := ae.agent.Plan(stepCtx, input, history)
 			if err != nil {
+				stepRun.EndChain(stepCtx, nil, err)
+				run.EndChain(ctx, history, err)
 				eventChan <- AgentStreamEvent{
 					Type:      EventTypeError,
 					Error:     err,
@@ -550,9 +636,12 @@ func (ae *AgentExecutor) Stream(ctx context.Context, input string) <-chan AgentS
 				}
 				return
 			}
+			reportAction(ctx, run, action)
 
 			// 检查完成
 			if action.Type == ActionFinish {
+				stepRun.EndChain(stepCtx, action, nil)
+				run.EndChain(ctx, action.FinalAnswer, nil)
 				eventChan <- AgentStreamEvent{
 					Type:        EventTypeFinish,
 					Action:      action,
@@ -572,7 +661,12 @@ func (ae *AgentExecutor) Stream(ctx context.Context, input string) <-chan AgentS
 					Timestamp: time.Now(),
 				}
 
-				observation, toolErr := ae.executeToolWithExecutor(ctx, action)
+				observation, toolErr := ae.executeToolWithExecutor(stepCtx, action)
+				stepRun.EndChain(stepCtx, AgentStep{
+					Action:      action,
+					Observation: observation,
+					Error:       toolErr,
+				}, toolErr)
 
 				// 发送工具结果事件
 				eventChan <- AgentStreamEvent{
@@ -589,10 +683,13 @@ func (ae *AgentExecutor) Stream(ctx context.Context, input string) <-chan AgentS
 					Observation: observation,
 					Error:       toolErr,
 				})
+			} else {
+				stepRun.EndChain(stepCtx, action, nil)
 			}
 		}
 
 		// 达到最大步数
+		run.EndChain(ctx, history, ErrAgentMaxSteps)
 		eventChan <- AgentStreamEvent{
 			Type:      EventTypeError,
 			Error:     ErrAgentMaxSteps,
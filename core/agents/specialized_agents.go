@@ -274,7 +274,7 @@ func (ra *ResearcherAgent) research(ctx context.Context, query string) (string,
 	// 1. 使用搜索工具收集信息
 	searchResult := ""
 	if ra.searchTool != nil {
-		result, err := ra.searchTool.Execute(ctx, map[string]any{"query": query})
+		result, err := tools.Run(ctx, ra.searchTool, map[string]any{"query": query})
 		if err == nil {
 			searchResult = fmt.Sprintf("%v", result)
 		}
@@ -0,0 +1,283 @@
+package callbacks
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zhucl121/langchain-go/pkg/types"
+)
+
+// recorder 记录收到的事件
+type recorder struct {
+	BaseHandler
+
+	mu     sync.Mutex
+	events []string
+	starts map[string]RunInfo
+	ends   map[string]error
+}
+
+func newRecorder() *recorder {
+	return &recorder{starts: make(map[string]RunInfo), ends: make(map[string]error)}
+}
+
+func (r *recorder) start(name string, info RunInfo) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, name+":"+info.Name)
+	r.starts[info.RunID] = info
+}
+
+func (r *recorder) end(name string, info RunInfo, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, name+":"+info.Name)
+	r.ends[info.RunID] = err
+}
+
+func (r *recorder) OnLLMStart(ctx context.Context, e *LLMStartEvent) {
+	r.start("llm_start", e.RunInfo)
+}
+
+func (r *recorder) OnLLMToken(ctx context.Context, e *LLMTokenEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, "token:"+e.Token)
+}
+
+func (r *recorder) OnLLMEnd(ctx context.Context, e *LLMEndEvent) {
+	r.end("llm_end", e.RunInfo, e.Err)
+}
+
+func (r *recorder) OnToolStart(ctx context.Context, e *ToolStartEvent) {
+	r.start("tool_start", e.RunInfo)
+}
+
+func (r *recorder) OnToolEnd(ctx context.Context, e *ToolEndEvent) {
+	r.end("tool_end", e.RunInfo, e.Err)
+}
+
+func (r *recorder) OnRetrieverStart(ctx context.Context, e *RetrieverStartEvent) {
+	r.start("retriever_start", e.RunInfo)
+}
+
+func (r *recorder) OnRetrieverEnd(ctx context.Context, e *RetrieverEndEvent) {
+	r.end("retriever_end", e.RunInfo, e.Err)
+}
+
+func (r *recorder) OnChainStart(ctx context.Context, e *ChainStartEvent) {
+	r.start("chain_start", e.RunInfo)
+}
+
+func (r *recorder) OnChainEnd(ctx context.Context, e *ChainEndEvent) {
+	r.end("chain_end", e.RunInfo, e.Err)
+}
+
+func (r *recorder) OnAgentAction(ctx context.Context, e *AgentActionEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, "action:"+e.Tool)
+}
+
+func TestRun_NoHandlers(t *testing.T) {
+	ctx, run := StartChain(context.Background(), "chain", nil)
+	assert.Nil(t, run)
+	assert.Nil(t, RunFromContext(ctx))
+
+	// nil Run 的方法都是空操作
+	run.EndChain(ctx, nil, nil)
+	run.LLMToken(ctx, "token")
+	run.AgentAction(ctx, "tool", nil, "")
+	assert.Empty(t, run.ID())
+}
+
+func TestRun_ParentChild(t *testing.T) {
+	rec := newRecorder()
+	ctx := WithHandlers(context.Background(), rec)
+
+	chainCtx, chain := StartChain(ctx, "agent", "input")
+	require.NotNil(t, chain)
+	assert.Same(t, chain, RunFromContext(chainCtx))
+
+	llmCtx, llm := StartLLM(chainCtx, "gpt-4o", LLMStartEvent{Provider: "openai", Model: "gpt-4o"})
+	llm.LLMToken(llmCtx, "Hel")
+	llm.LLMToken(llmCtx, "lo")
+	llm.EndLLM(llmCtx, types.NewAssistantMessage("Hello"), nil)
+
+	chain.AgentAction(chainCtx, "search", map[string]any{"q": "go"}, "")
+	toolCtx, tool := StartTool(chainCtx, "search", map[string]any{"q": "go"})
+	toolErr := errors.New("boom")
+	tool.EndTool(toolCtx, nil, toolErr)
+
+	chain.EndChain(chainCtx, "output", nil)
+
+	assert.Equal(t, []string{
+		"chain_start:agent",
+		"llm_start:gpt-4o", "token:Hel", "token:lo", "llm_end:gpt-4o",
+		"action:search",
+		"tool_start:search", "tool_end:search",
+		"chain_end:agent",
+	}, rec.events)
+
+	assert.Empty(t, chain.Info().ParentRunID)
+	assert.Equal(t, chain.ID(), rec.starts[llm.ID()].ParentRunID)
+	assert.Equal(t, chain.ID(), rec.starts[tool.ID()].ParentRunID)
+	assert.Equal(t, chain.ID(), chain.Info().TraceID)
+	assert.Equal(t, chain.ID(), rec.starts[tool.ID()].TraceID)
+	assert.Equal(t, RunTypeLLM, rec.starts[llm.ID()].Type)
+	assert.Equal(t, toolErr, rec.ends[tool.ID()])
+	assert.NotEqual(t, llm.ID(), tool.ID())
+}
+
+func TestRun_TagsMetadataAndNextRun(t *testing.T) {
+	rec := newRecorder()
+	ctx := WithHandlers(context.Background(), rec)
+	ctx = WithTags(ctx, "prod")
+	ctx = WithMetadata(ctx, map[string]any{"user": "u1"})
+	ctx = WithRunName(ctx, "my-chain")
+	ctx = WithRunID(ctx, "run-1")
+
+	chainCtx, chain := StartChain(ctx, "default", nil)
+	require.NotNil(t, chain)
+	assert.Equal(t, "run-1", chain.ID())
+	assert.Equal(t, "my-chain", chain.Info().Name)
+	assert.Equal(t, []string{"prod"}, chain.Info().Tags)
+	assert.Equal(t, "u1", chain.Info().Metadata["user"])
+
+	// 运行名称和 ID 只作用于下一次运行，标签和元数据由子运行继承
+	_, child := StartTool(WithMetadata(chainCtx, map[string]any{"step": 1}), "tool", nil)
+	require.NotNil(t, child)
+	assert.NotEqual(t, "run-1", child.ID())
+	assert.Equal(t, "tool", child.Info().Name)
+	assert.Equal(t, []string{"prod"}, child.Info().Tags)
+	assert.Equal(t, map[string]any{"user": "u1", "step": 1}, child.Info().Metadata)
+}
+
+// legacy 只实现 types.CallbackHandler
+type legacy struct {
+	mu     sync.Mutex
+	starts []any
+	ends   []any
+	errs   []error
+}
+
+func (l *legacy) OnStart(ctx context.Context, input any) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.starts = append(l.starts, input)
+	return nil
+}
+
+func (l *legacy) OnEnd(ctx context.Context, output any) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.ends = append(l.ends, output)
+	return nil
+}
+
+func (l *legacy) OnError(ctx context.Context, err error) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.errs = append(l.errs, err)
+	return nil
+}
+
+func TestFromCallbackHandlers(t *testing.T) {
+	rec := newRecorder()
+	old := &legacy{}
+
+	handlers := FromCallbackHandlers(rec, old, nil)
+	require.Len(t, handlers, 2)
+	assert.Same(t, rec, handlers[0])
+
+	ctx := WithHandlers(context.Background(), handlers...)
+	ctx, chain := StartChain(ctx, "chain", "in")
+	_, tool := StartTool(ctx, "tool", map[string]any{"a": 1})
+	tool.EndTool(ctx, nil, errors.New("failed"))
+	chain.EndChain(ctx, "out", nil)
+
+	assert.Equal(t, []any{"in", map[string]any{"a": 1}}, old.starts)
+	assert.Equal(t, []any{"out"}, old.ends)
+	require.Len(t, old.errs, 1)
+	assert.EqualError(t, old.errs[0], "failed")
+	assert.Len(t, rec.events, 4)
+}
+
+func TestWithConfig(t *testing.T) {
+	old := &legacy{}
+	config := types.NewConfig().WithCallbacks(old).WithTags("t").WithRunName("named")
+
+	ctx := WithConfig(context.Background(), config)
+	ctx, run := StartChain(ctx, "chain", "in")
+	require.NotNil(t, run)
+	assert.Equal(t, "named", run.Info().Name)
+	assert.Equal(t, []string{"t"}, run.Info().Tags)
+
+	run.EndChain(ctx, "out", nil)
+	assert.Equal(t, []any{"in"}, old.starts)
+
+	assert.Equal(t, context.Background(), WithConfig(context.Background(), nil))
+}
+
+func TestGlobalHandlers(t *testing.T) {
+	global := newRecorder()
+	AddGlobalHandlers(global)
+	defer ResetGlobalHandlers()
+
+	local := newRecorder()
+	ctx, chain := StartChain(context.Background(), "chain", nil)
+	require.NotNil(t, chain)
+
+	storeCtx, query := StartVectorStore(WithHandlers(ctx, local), "InMemoryVectorStore", "q")
+	require.NotNil(t, query)
+	assert.Equal(t, RunTypeVectorStore, query.Info().Type)
+	assert.Equal(t, chain.ID(), query.Info().ParentRunID)
+	query.EndRetriever(storeCtx, nil, nil)
+	chain.EndChain(ctx, nil, nil)
+
+	assert.Equal(t, []string{
+		"chain_start:chain",
+		"retriever_start:InMemoryVectorStore",
+		"retriever_end:InMemoryVectorStore",
+		"chain_end:chain",
+	}, global.events)
+	assert.Equal(t, []string{
+		"retriever_start:InMemoryVectorStore",
+		"retriever_end:InMemoryVectorStore",
+	}, local.events)
+
+	ResetGlobalHandlers()
+	_, run := StartChain(context.Background(), "chain", nil)
+	assert.Nil(t, run)
+}
+
+// contextKey 是 contextHandler 放入上下文的键
+type contextKey struct{}
+
+// contextHandler 把运行 ID 放入组件的上下文
+type contextHandler struct {
+	BaseHandler
+}
+
+func (contextHandler) RunContext(ctx context.Context, info RunInfo) context.Context {
+	return context.WithValue(ctx, contextKey{}, info.RunID)
+}
+
+func TestContextHandler(t *testing.T) {
+	ctx := WithHandlers(context.Background(), newRecorder(), contextHandler{})
+
+	chainCtx, chain := StartChain(ctx, "chain", nil)
+	require.NotNil(t, chain)
+	assert.Equal(t, chain.ID(), chainCtx.Value(contextKey{}))
+	assert.Same(t, chain, RunFromContext(chainCtx))
+
+	toolCtx, tool := StartTool(chainCtx, "tool", nil)
+	require.NotNil(t, tool)
+	assert.Equal(t, tool.ID(), toolCtx.Value(contextKey{}))
+	assert.Equal(t, chain.ID(), tool.Info().ParentRunID)
+}
@@ -0,0 +1,58 @@
+// Package callbacks 提供贯穿模型、工具、检索器、链、图和 Agent 的回调系统。
+//
+// 每个内置组件执行时都会开始一次运行（run），并向上下文中的回调处理器发送类型化事件：
+//
+//   - LLM：OnLLMStart、OnLLMToken（流式输出的每个增量）、OnLLMEnd
+//   - 工具：OnToolStart、OnToolEnd
+//   - 检索器和向量存储查询：OnRetrieverStart、OnRetrieverEnd
+//   - 链（Runnable、RAG 链、图、Agent 及其每一步）：OnChainStart、OnChainEnd
+//   - 图节点：OnNodeStart、OnNodeEnd
+//   - Agent：OnAgentAction、OnAgentFinish
+//
+// 每个事件都带有 RunInfo：运行 ID、父运行 ID、运行类型、名称、标签和元数据。
+// 当前运行保存在上下文中，组件内部发起的调用自动成为它的子运行，
+// 因此处理器可以重建完整的运行树。
+//
+// # 使用示例
+//
+//	type printer struct {
+//	    callbacks.BaseHandler
+//	}
+//
+//	func (printer) OnLLMToken(ctx context.Context, event *callbacks.LLMTokenEvent) {
+//	    fmt.Print(event.Token)
+//	}
+//
+//	func (printer) OnToolEnd(ctx context.Context, event *callbacks.ToolEndEvent) {
+//	    fmt.Println(event.Name, event.Output, event.Err)
+//	}
+//
+//	ctx = callbacks.WithHandlers(ctx, printer{})
+//	result, err := executor.Execute(ctx, "今天天气如何？")
+//
+// 处理器也可以通过 runnable.WithCallbacks 传给单次调用，效果相同：
+// 处理器加入上下文，由本次调用及其所有子运行继承。只实现了
+// types.CallbackHandler（OnStart / OnEnd / OnError）的旧处理器会被适配，
+// 在每次运行开始和结束时调用。
+//
+// 需要接收所有运行事件的处理器（例如 observability 包的 OpenTelemetry 追踪）
+// 可以用 AddGlobalHandlers 在进程启动时安装一次，不必在每个上下文中传入。
+// 处理器还可以实现 ContextHandler，为运行派生执行上下文（例如放入运行对应的 Span），
+// 组件及其下游调用都在该上下文中执行。
+//
+// # 实现组件
+//
+// 组件开始运行时调用对应的 Start 函数，结束时调用返回的 *Run 的 End 方法：
+//
+//	ctx, run := callbacks.StartRetriever(ctx, "my-retriever", query)
+//	docs, err := r.search(ctx, query)
+//	run.EndRetriever(ctx, docs, err)
+//
+// 没有任何处理器时 Start 返回 nil，nil *Run 的所有方法都是空操作，不产生开销。
+//
+// # 注意
+//
+// 事件在执行组件的 goroutine 中同步发送，处理器应尽快返回，并且必须并发安全。
+// 事件中的输入输出是原始值的引用，处理器不应修改它们。
+//
+package callbacks
@@ -0,0 +1,221 @@
+package callbacks
+
+import (
+	"time"
+
+	"github.com/zhucl121/langchain-go/pkg/types"
+)
+
+// RunType 是运行的类型。
+type RunType string
+
+const (
+	// RunTypeLLM 聊天模型调用
+	RunTypeLLM RunType = "llm"
+
+	// RunTypeTool 工具调用
+	RunTypeTool RunType = "tool"
+
+	// RunTypeRetriever 文档检索
+	RunTypeRetriever RunType = "retriever"
+
+	// RunTypeChain 链（Runnable、RAG 链、图、Agent 的一步等）
+	RunTypeChain RunType = "chain"
+
+	// RunTypeNode 图节点
+	RunTypeNode RunType = "node"
+
+	// RunTypeAgent Agent 执行
+	RunTypeAgent RunType = "agent"
+
+	// RunTypeVectorStore 向量存储查询
+	RunTypeVectorStore RunType = "vectorstore"
+)
+
+// RunInfo 是事件所属运行的信息。
+type RunInfo struct {
+	// RunID 运行 ID
+	RunID string `json:"run_id"`
+
+	// ParentRunID 父运行 ID，顶层运行为空
+	ParentRunID string `json:"parent_run_id,omitempty"`
+
+	// TraceID 运行树顶层运行的 ID，顶层运行为自身的 RunID
+	TraceID string `json:"trace_id"`
+
+	// Type 运行类型
+	Type RunType `json:"type"`
+
+	// Name 运行名称（模型名、工具名、节点名等）
+	Name string `json:"name"`
+
+	// Tags 标签，包括从父运行继承的标签
+	Tags []string `json:"tags,omitempty"`
+
+	// Metadata 元数据，包括从父运行继承的元数据
+	Metadata map[string]any `json:"metadata,omitempty"`
+
+	// StartTime 运行开始时间
+	StartTime time.Time `json:"start_time"`
+}
+
+// LLMStartEvent 在聊天模型调用开始时发送。
+type LLMStartEvent struct {
+	RunInfo
+
+	// Provider 提供商名称
+	Provider string
+
+	// Model 模型名称
+	Model string
+
+	// Messages 输入消息
+	Messages []types.Message
+
+	// Tools 绑定的工具（可选）
+	Tools []types.Tool
+}
+
+// LLMTokenEvent 在流式输出的每个文本增量到达时发送。
+type LLMTokenEvent struct {
+	RunInfo
+
+	// Token 文本增量
+	Token string
+
+	// Time 到达时间
+	Time time.Time
+}
+
+// LLMEndEvent 在聊天模型调用结束时发送。
+type LLMEndEvent struct {
+	RunInfo
+
+	// Output 响应消息，Token 用量在 Output.UsageMetadata 中
+	Output types.Message
+
+	// Err 调用错误，成功时为 nil
+	Err error
+
+	// EndTime 结束时间
+	EndTime time.Time
+}
+
+// ToolStartEvent 在工具调用开始时发送。
+type ToolStartEvent struct {
+	RunInfo
+
+	// Input 工具参数
+	Input map[string]any
+}
+
+// ToolEndEvent 在工具调用结束时发送。
+type ToolEndEvent struct {
+	RunInfo
+
+	// Output 工具结果
+	Output any
+
+	// Err 调用错误，成功时为 nil
+	Err error
+
+	// EndTime 结束时间
+	EndTime time.Time
+}
+
+// RetrieverStartEvent 在检索开始时发送。
+type RetrieverStartEvent struct {
+	RunInfo
+
+	// Query 查询文本
+	Query string
+}
+
+// RetrieverEndEvent 在检索结束时发送。
+type RetrieverEndEvent struct {
+	RunInfo
+
+	// Documents 检索到的文档
+	Documents []*types.Document
+
+	// Err 检索错误，成功时为 nil
+	Err error
+
+	// EndTime 结束时间
+	EndTime time.Time
+}
+
+// ChainStartEvent 在链（包括图和 Agent）开始时发送。
+type ChainStartEvent struct {
+	RunInfo
+
+	// Inputs 输入
+	Inputs any
+}
+
+// ChainEndEvent 在链（包括图和 Agent）结束时发送。
+type ChainEndEvent struct {
+	RunInfo
+
+	// Outputs 输出
+	Outputs any
+
+	// Err 执行错误，成功时为 nil
+	Err error
+
+	// EndTime 结束时间
+	EndTime time.Time
+}
+
+// NodeStartEvent 在图节点开始时发送。
+type NodeStartEvent struct {
+	RunInfo
+
+	// State 节点的输入状态
+	State any
+}
+
+// NodeEndEvent 在图节点结束时发送。
+type NodeEndEvent struct {
+	RunInfo
+
+	// State 节点的输出状态
+	State any
+
+	// Err 执行错误，成功时为 nil
+	Err error
+
+	// EndTime 结束时间
+	EndTime time.Time
+}
+
+// AgentActionEvent 在 Agent 决定调用工具时发送，RunInfo 是 Agent 当前步骤的运行。
+type AgentActionEvent struct {
+	RunInfo
+
+	// Tool 工具名称
+	Tool string
+
+	// ToolInput 工具参数
+	ToolInput map[string]any
+
+	// Log 思考过程
+	Log string
+
+	// Time 发送时间
+	Time time.Time
+}
+
+// AgentFinishEvent 在 Agent 给出最终答案时发送，RunInfo 是 Agent 当前步骤的运行。
+type AgentFinishEvent struct {
+	RunInfo
+
+	// Output 最终答案
+	Output string
+
+	// Log 思考过程
+	Log string
+
+	// Time 发送时间
+	Time time.Time
+}
@@ -0,0 +1,155 @@
+package callbacks
+
+import (
+	"context"
+
+	"github.com/zhucl121/langchain-go/pkg/types"
+)
+
+// Handler 是回调处理器。
+//
+// 只关心部分事件的处理器可以嵌入 BaseHandler。
+type Handler interface {
+	OnLLMStart(ctx context.Context, event *LLMStartEvent)
+	OnLLMToken(ctx context.Context, event *LLMTokenEvent)
+	OnLLMEnd(ctx context.Context, event *LLMEndEvent)
+
+	OnToolStart(ctx context.Context, event *ToolStartEvent)
+	OnToolEnd(ctx context.Context, event *ToolEndEvent)
+
+	OnRetrieverStart(ctx context.Context, event *RetrieverStartEvent)
+	OnRetrieverEnd(ctx context.Context, event *RetrieverEndEvent)
+
+	OnChainStart(ctx context.Context, event *ChainStartEvent)
+	OnChainEnd(ctx context.Context, event *ChainEndEvent)
+
+	OnNodeStart(ctx context.Context, event *NodeStartEvent)
+	OnNodeEnd(ctx context.Context, event *NodeEndEvent)
+
+	OnAgentAction(ctx context.Context, event *AgentActionEvent)
+	OnAgentFinish(ctx context.Context, event *AgentFinishEvent)
+}
+
+// ContextHandler 是可以为运行派生执行上下文的处理器（可选接口）。
+//
+// 运行开始的事件发送完成后，Start 函数依次调用实现了该接口的处理器的 RunContext，
+// 并把最终得到的上下文返回给组件。组件用它执行本次运行，因此子运行以及组件
+// 发起的下游调用（例如经过 otelhttp 的 HTTP 请求）都能看到处理器放入的值，
+// 例如本次运行的 OpenTelemetry Span。
+type ContextHandler interface {
+	RunContext(ctx context.Context, info RunInfo) context.Context
+}
+
+// BaseHandler 是所有方法都为空操作的 Handler，用于嵌入。
+//
+// BaseHandler 同时实现了 types.CallbackHandler，因此嵌入它的处理器
+// 也可以通过 runnable.WithCallbacks 传入。
+type BaseHandler struct{}
+
+var (
+	_ Handler               = BaseHandler{}
+	_ types.CallbackHandler = BaseHandler{}
+)
+
+func (BaseHandler) OnLLMStart(ctx context.Context, event *LLMStartEvent)             {}
+func (BaseHandler) OnLLMToken(ctx context.Context, event *LLMTokenEvent)             {}
+func (BaseHandler) OnLLMEnd(ctx context.Context, event *LLMEndEvent)                 {}
+func (BaseHandler) OnToolStart(ctx context.Context, event *ToolStartEvent)           {}
+func (BaseHandler) OnToolEnd(ctx context.Context, event *ToolEndEvent)               {}
+func (BaseHandler) OnRetrieverStart(ctx context.Context, event *RetrieverStartEvent) {}
+func (BaseHandler) OnRetrieverEnd(ctx context.Context, event *RetrieverEndEvent)     {}
+func (BaseHandler) OnChainStart(ctx context.Context, event *ChainStartEvent)         {}
+func (BaseHandler) OnChainEnd(ctx context.Context, event *ChainEndEvent)             {}
+func (BaseHandler) OnNodeStart(ctx context.Context, event *NodeStartEvent)           {}
+func (BaseHandler) OnNodeEnd(ctx context.Context, event *NodeEndEvent)               {}
+func (BaseHandler) OnAgentAction(ctx context.Context, event *AgentActionEvent)       {}
+func (BaseHandler) OnAgentFinish(ctx context.Context, event *AgentFinishEvent)       {}
+
+// OnStart 实现 types.CallbackHandler（空操作）。
+func (BaseHandler) OnStart(ctx context.Context, input any) error { return nil }
+
+// OnEnd 实现 types.CallbackHandler（空操作）。
+func (BaseHandler) OnEnd(ctx context.Context, output any) error { return nil }
+
+// OnError 实现 types.CallbackHandler（空操作）。
+func (BaseHandler) OnError(ctx context.Context, err error) error { return nil }
+
+// FromCallbackHandlers 把 types.CallbackHandler 转换为 Handler。
+//
+// 本身实现了 Handler 的处理器原样返回；其他处理器被适配为：
+// 每次运行开始时调用 OnStart（输入为消息、工具参数、查询或链的输入），
+// 结束时调用 OnEnd（输出）或 OnError（错误）。适配器的返回错误被忽略。
+//
+// 参数：
+//   - handlers: 处理器列表
+//
+// 返回：
+//   - []Handler: 转换后的处理器
+//
+func FromCallbackHandlers(handlers ...types.CallbackHandler) []Handler {
+	result := make([]Handler, 0, len(handlers))
+	for _, h := range handlers {
+		switch h := h.(type) {
+		case nil:
+		case Handler:
+			result = append(result, h)
+		default:
+			result = append(result, legacyHandler{h: h})
+		}
+	}
+	return result
+}
+
+// legacyHandler 把 types.CallbackHandler 适配为 Handler。
+type legacyHandler struct {
+	BaseHandler
+	h types.CallbackHandler
+}
+
+func (l legacyHandler) end(ctx context.Context, output any, err error) {
+	if err != nil {
+		_ = l.h.OnError(ctx, err)
+		return
+	}
+	_ = l.h.OnEnd(ctx, output)
+}
+
+func (l legacyHandler) OnLLMStart(ctx context.Context, e *LLMStartEvent) {
+	_ = l.h.OnStart(ctx, e.Messages)
+}
+
+func (l legacyHandler) OnLLMEnd(ctx context.Context, e *LLMEndEvent) {
+	l.end(ctx, e.Output, e.Err)
+}
+
+func (l legacyHandler) OnToolStart(ctx context.Context, e *ToolStartEvent) {
+	_ = l.h.OnStart(ctx, e.Input)
+}
+
+func (l legacyHandler) OnToolEnd(ctx context.Context, e *ToolEndEvent) {
+	l.end(ctx, e.Output, e.Err)
+}
+
+func (l legacyHandler) OnRetrieverStart(ctx context.Context, e *RetrieverStartEvent) {
+	_ = l.h.OnStart(ctx, e.Query)
+}
+
+func (l legacyHandler) OnRetrieverEnd(ctx context.Context, e *RetrieverEndEvent) {
+	l.end(ctx, e.Documents, e.Err)
+}
+
+func (l legacyHandler) OnChainStart(ctx context.Context, e *ChainStartEvent) {
+	_ = l.h.OnStart(ctx, e.Inputs)
+}
+
+func (l legacyHandler) OnChainEnd(ctx context.Context, e *ChainEndEvent) {
+	l.end(ctx, e.Outputs, e.Err)
+}
+
+func (l legacyHandler) OnNodeStart(ctx context.Context, e *NodeStartEvent) {
+	_ = l.h.OnStart(ctx, e.State)
+}
+
+func (l legacyHandler) OnNodeEnd(ctx context.Context, e *NodeEndEvent) {
+	l.end(ctx, e.State, e.Err)
+}
@@ -0,0 +1,419 @@
+package callbacks
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/zhucl121/langchain-go/pkg/types"
+)
+
+// 上下文键
+type (
+	handlersKey struct{}
+	runKey      struct{}
+	tagsKey     struct{}
+	metadataKey struct{}
+	nextRunKey  struct{}
+)
+
+// 全局处理器
+var (
+	globalMu       sync.RWMutex
+	globalHandlers []Handler
+)
+
+// nextRun 是只作用于下一次开始的运行的设置
+type nextRun struct {
+	name string
+	id   string
+}
+
+// Run 是一次正在进行的运行。
+//
+// nil *Run 的所有方法都是空操作。
+type Run struct {
+	info     RunInfo
+	handlers []Handler
+}
+
+// ID 返回运行 ID，nil 时返回空字符串。
+func (r *Run) ID() string {
+	if r == nil {
+		return ""
+	}
+	return r.info.RunID
+}
+
+// Info 返回运行信息，nil 时返回零值。
+func (r *Run) Info() RunInfo {
+	if r == nil {
+		return RunInfo{}
+	}
+	return r.info
+}
+
+// WithHandlers 在上下文中添加回调处理器。
+//
+// 处理器由之后在该上下文中开始的所有运行及其子运行继承。
+//
+// 参数：
+//   - ctx: 上下文
+//   - handlers: 回调处理器
+//
+// 返回：
+//   - context.Context: 带处理器的上下文
+//
+func WithHandlers(ctx context.Context, handlers ...Handler) context.Context {
+	if len(handlers) == 0 {
+		return ctx
+	}
+	existing := HandlersFromContext(ctx)
+	merged := make([]Handler, 0, len(existing)+len(handlers))
+	merged = append(merged, existing...)
+	merged = append(merged, handlers...)
+	return context.WithValue(ctx, handlersKey{}, merged)
+}
+
+// HandlersFromContext 返回上下文中的回调处理器。
+func HandlersFromContext(ctx context.Context) []Handler {
+	handlers, _ := ctx.Value(handlersKey{}).([]Handler)
+	return handlers
+}
+
+// HasHandlers 报告在该上下文中开始的运行是否有处理器（全局处理器或上下文中的处理器）。
+//
+// 组件可以在构造开始事件之前用它跳过没有处理器时的额外开销。
+func HasHandlers(ctx context.Context) bool {
+	return len(HandlersFromContext(ctx)) > 0 || len(GlobalHandlers()) > 0
+}
+
+// AddGlobalHandlers 添加全局回调处理器。
+//
+// 全局处理器接收所有运行的事件，不需要通过上下文传入，
+// 用于在进程启动时统一安装追踪、记录等处理器。全局处理器先于上下文中的处理器调用。
+func AddGlobalHandlers(handlers ...Handler) {
+	globalMu.Lock()
+	defer globalMu.Unlock()
+	merged := make([]Handler, 0, len(globalHandlers)+len(handlers))
+	merged = append(merged, globalHandlers...)
+	globalHandlers = append(merged, handlers...)
+}
+
+// GlobalHandlers 返回全局回调处理器。
+func GlobalHandlers() []Handler {
+	globalMu.RLock()
+	defer globalMu.RUnlock()
+	return globalHandlers
+}
+
+// ResetGlobalHandlers 移除所有全局回调处理器。
+func ResetGlobalHandlers() {
+	globalMu.Lock()
+	defer globalMu.Unlock()
+	globalHandlers = nil
+}
+
+// WithTags 在上下文中添加标签，由之后开始的运行及其子运行继承。
+func WithTags(ctx context.Context, tags ...string) context.Context {
+	if len(tags) == 0 {
+		return ctx
+	}
+	existing, _ := ctx.Value(tagsKey{}).([]string)
+	merged := make([]string, 0, len(existing)+len(tags))
+	merged = append(merged, existing...)
+	merged = append(merged, tags...)
+	return context.WithValue(ctx, tagsKey{}, merged)
+}
+
+// WithMetadata 在上下文中添加元数据，由之后开始的运行及其子运行继承。
+func WithMetadata(ctx context.Context, metadata map[string]any) context.Context {
+	if len(metadata) == 0 {
+		return ctx
+	}
+	existing, _ := ctx.Value(metadataKey{}).(map[string]any)
+	merged := make(map[string]any, len(existing)+len(metadata))
+	for k, v := range existing {
+		merged[k] = v
+	}
+	for k, v := range metadata {
+		merged[k] = v
+	}
+	return context.WithValue(ctx, metadataKey{}, merged)
+}
+
+// WithRunName 设置下一次开始的运行的名称，不影响其子运行。
+func WithRunName(ctx context.Context, name string) context.Context {
+	next, _ := ctx.Value(nextRunKey{}).(nextRun)
+	next.name = name
+	return context.WithValue(ctx, nextRunKey{}, next)
+}
+
+// WithRunID 设置下一次开始的运行的 ID，不影响其子运行。
+//
+// 用于调用方预先生成运行 ID，以便之后查询该运行。
+func WithRunID(ctx context.Context, id string) context.Context {
+	next, _ := ctx.Value(nextRunKey{}).(nextRun)
+	next.id = id
+	return context.WithValue(ctx, nextRunKey{}, next)
+}
+
+// WithConfig 把 types.Config 中的回调处理器、标签、元数据、运行名称和运行 ID 加入上下文。
+//
+// 参数：
+//   - ctx: 上下文
+//   - config: 运行时配置，可以为 nil
+//
+// 返回：
+//   - context.Context: 新的上下文
+//
+func WithConfig(ctx context.Context, config *types.Config) context.Context {
+	if config == nil {
+		return ctx
+	}
+	ctx = WithHandlers(ctx, FromCallbackHandlers(config.Callbacks...)...)
+	ctx = WithTags(ctx, config.Tags...)
+	ctx = WithMetadata(ctx, config.Metadata)
+	if config.RunName != "" {
+		ctx = WithRunName(ctx, config.RunName)
+	}
+	if config.RunID != "" {
+		ctx = WithRunID(ctx, config.RunID)
+	}
+	return ctx
+}
+
+// RunFromContext 返回上下文中当前的运行，没有时返回 nil。
+func RunFromContext(ctx context.Context) *Run {
+	run, _ := ctx.Value(runKey{}).(*Run)
+	return run
+}
+
+// start 开始一次运行。
+//
+// 没有全局处理器，上下文中也没有处理器时返回原上下文和 nil。
+func start(ctx context.Context, runType RunType, name string) (context.Context, *Run) {
+	next, hasNext := ctx.Value(nextRunKey{}).(nextRun)
+	if hasNext {
+		// 只作用于本次运行
+		ctx = context.WithValue(ctx, nextRunKey{}, nextRun{})
+	}
+
+	handlers := HandlersFromContext(ctx)
+	if global := GlobalHandlers(); len(global) > 0 {
+		handlers = append(append(make([]Handler, 0, len(global)+len(handlers)), global...), handlers...)
+	}
+	if len(handlers) == 0 {
+		return ctx, nil
+	}
+
+	if next.name != "" {
+		name = next.name
+	}
+	id := next.id
+	if id == "" {
+		id = uuid.NewString()
+	}
+
+	run := &Run{
+		info: RunInfo{
+			RunID:     id,
+			TraceID:   id,
+			Type:      runType,
+			Name:      name,
+			StartTime: time.Now(),
+		},
+		handlers: handlers,
+	}
+	if parent := RunFromContext(ctx); parent != nil {
+		run.info.ParentRunID = parent.info.RunID
+		run.info.TraceID = parent.info.TraceID
+	}
+	if tags, _ := ctx.Value(tagsKey{}).([]string); len(tags) > 0 {
+		run.info.Tags = tags
+	}
+	if metadata, _ := ctx.Value(metadataKey{}).(map[string]any); len(metadata) > 0 {
+		run.info.Metadata = metadata
+	}
+
+	return context.WithValue(ctx, runKey{}, run), run
+}
+
+// dispatch 依次调用每个处理器。
+func (r *Run) dispatch(fn func(Handler)) {
+	for _, h := range r.handlers {
+		fn(h)
+	}
+}
+
+// runContext 依次调用实现了 ContextHandler 的处理器，返回组件执行本次运行使用的上下文。
+func (r *Run) runContext(ctx context.Context) context.Context {
+	for _, h := range r.handlers {
+		if ch, ok := h.(ContextHandler); ok {
+			ctx = ch.RunContext(ctx, r.info)
+		}
+	}
+	return ctx
+}
+
+// StartLLM 开始一次聊天模型调用，发送 OnLLMStart。
+//
+// 参数：
+//   - ctx: 上下文
+//   - name: 运行名称（通常为模型名称）
+//   - event: 事件内容，RunInfo 由本函数填写
+//
+// 返回：
+//   - context.Context: 携带本次运行的上下文
+//   - *Run: 运行，上下文中没有处理器时为 nil
+//
+func StartLLM(ctx context.Context, name string, event LLMStartEvent) (context.Context, *Run) {
+	ctx, run := start(ctx, RunTypeLLM, name)
+	if run != nil {
+		event.RunInfo = run.info
+		run.dispatch(func(h Handler) { h.OnLLMStart(ctx, &event) })
+		ctx = run.runContext(ctx)
+	}
+	return ctx, run
+}
+
+// LLMToken 发送 OnLLMToken。
+func (r *Run) LLMToken(ctx context.Context, token string) {
+	if r == nil {
+		return
+	}
+	event := LLMTokenEvent{RunInfo: r.info, Token: token, Time: time.Now()}
+	r.dispatch(func(h Handler) { h.OnLLMToken(ctx, &event) })
+}
+
+// EndLLM 结束聊天模型调用，发送 OnLLMEnd。
+func (r *Run) EndLLM(ctx context.Context, output types.Message, err error) {
+	if r == nil {
+		return
+	}
+	event := LLMEndEvent{RunInfo: r.info, Output: output, Err: err, EndTime: time.Now()}
+	r.dispatch(func(h Handler) { h.OnLLMEnd(ctx, &event) })
+}
+
+// StartTool 开始一次工具调用，发送 OnToolStart。
+func StartTool(ctx context.Context, name string, input map[string]any) (context.Context, *Run) {
+	ctx, run := start(ctx, RunTypeTool, name)
+	if run != nil {
+		event := ToolStartEvent{RunInfo: run.info, Input: input}
+		run.dispatch(func(h Handler) { h.OnToolStart(ctx, &event) })
+		ctx = run.runContext(ctx)
+	}
+	return ctx, run
+}
+
+// EndTool 结束工具调用，发送 OnToolEnd。
+func (r *Run) EndTool(ctx context.Context, output any, err error) {
+	if r == nil {
+		return
+	}
+	event := ToolEndEvent{RunInfo: r.info, Output: output, Err: err, EndTime: time.Now()}
+	r.dispatch(func(h Handler) { h.OnToolEnd(ctx, &event) })
+}
+
+// StartRetriever 开始一次检索，发送 OnRetrieverStart。
+func StartRetriever(ctx context.Context, name string, query string) (context.Context, *Run) {
+	ctx, run := start(ctx, RunTypeRetriever, name)
+	if run != nil {
+		event := RetrieverStartEvent{RunInfo: run.info, Query: query}
+		run.dispatch(func(h Handler) { h.OnRetrieverStart(ctx, &event) })
+		ctx = run.runContext(ctx)
+	}
+	return ctx, run
+}
+
+// StartVectorStore 开始一次向量存储查询，发送 OnRetrieverStart（RunInfo.Type 为
+// RunTypeVectorStore），结束时使用 EndRetriever。
+func StartVectorStore(ctx context.Context, name string, query string) (context.Context, *Run) {
+	ctx, run := start(ctx, RunTypeVectorStore, name)
+	if run != nil {
+		event := RetrieverStartEvent{RunInfo: run.info, Query: query}
+		run.dispatch(func(h Handler) { h.OnRetrieverStart(ctx, &event) })
+		ctx = run.runContext(ctx)
+	}
+	return ctx, run
+}
+
+// EndRetriever 结束检索或向量存储查询，发送 OnRetrieverEnd。
+func (r *Run) EndRetriever(ctx context.Context, documents []*types.Document, err error) {
+	if r == nil {
+		return
+	}
+	event := RetrieverEndEvent{RunInfo: r.info, Documents: documents, Err: err, EndTime: time.Now()}
+	r.dispatch(func(h Handler) { h.OnRetrieverEnd(ctx, &event) })
+}
+
+// StartChain 开始一次链的运行，发送 OnChainStart。
+func StartChain(ctx context.Context, name string, inputs any) (context.Context, *Run) {
+	return startChain(ctx, RunTypeChain, name, inputs)
+}
+
+// StartAgent 开始一次 Agent 执行，发送 OnChainStart（RunInfo.Type 为 RunTypeAgent），
+// 结束时使用 EndChain。
+func StartAgent(ctx context.Context, name string, inputs any) (context.Context, *Run) {
+	return startChain(ctx, RunTypeAgent, name, inputs)
+}
+
+// startChain 开始链或 Agent 的运行。
+func startChain(ctx context.Context, runType RunType, name string, inputs any) (context.Context, *Run) {
+	ctx, run := start(ctx, runType, name)
+	if run != nil {
+		event := ChainStartEvent{RunInfo: run.info, Inputs: inputs}
+		run.dispatch(func(h Handler) { h.OnChainStart(ctx, &event) })
+		ctx = run.runContext(ctx)
+	}
+	return ctx, run
+}
+
+// EndChain 结束链或 Agent 的运行，发送 OnChainEnd。
+func (r *Run) EndChain(ctx context.Context, outputs any, err error) {
+	if r == nil {
+		return
+	}
+	event := ChainEndEvent{RunInfo: r.info, Outputs: outputs, Err: err, EndTime: time.Now()}
+	r.dispatch(func(h Handler) { h.OnChainEnd(ctx, &event) })
+}
+
+// StartNode 开始一次图节点的执行，发送 OnNodeStart。
+func StartNode(ctx context.Context, name string, state any) (context.Context, *Run) {
+	ctx, run := start(ctx, RunTypeNode, name)
+	if run != nil {
+		event := NodeStartEvent{RunInfo: run.info, State: state}
+		run.dispatch(func(h Handler) { h.OnNodeStart(ctx, &event) })
+		ctx = run.runContext(ctx)
+	}
+	return ctx, run
+}
+
+// EndNode 结束图节点的执行，发送 OnNodeEnd。
+func (r *Run) EndNode(ctx context.Context, state any, err error) {
+	if r == nil {
+		return
+	}
+	event := NodeEndEvent{RunInfo: r.info, State: state, Err: err, EndTime: time.Now()}
+	r.dispatch(func(h Handler) { h.OnNodeEnd(ctx, &event) })
+}
+
+// AgentAction 发送 OnAgentAction。
+func (r *Run) AgentAction(ctx context.Context, tool string, toolInput map[string]any, log string) {
+	if r == nil {
+		return
+	}
+	event := AgentActionEvent{RunInfo: r.info, Tool: tool, ToolInput: toolInput, Log: log, Time: time.Now()}
+	r.dispatch(func(h Handler) { h.OnAgentAction(ctx, &event) })
+}
+
+// AgentFinish 发送 OnAgentFinish。
+func (r *Run) AgentFinish(ctx context.Context, output string, log string) {
+	if r == nil {
+		return
+	}
+	event := AgentFinishEvent{RunInfo: r.info, Output: output, Log: log, Time: time.Now()}
+	r.dispatch(func(h Handler) { h.OnAgentFinish(ctx, &event) })
+}
@@ -0,0 +1,144 @@
+package chat
+
+import (
+	"context"
+
+	"github.com/zhucl121/langchain-go/pkg/types"
+)
+
+// promptCachingKey 是自动提示词缓存的上下文键
+type promptCachingKey struct{}
+
+// WithPromptCaching 在上下文中开启自动提示词缓存。
+//
+// 开启后，支持显式缓存的提供商（Anthropic）会通过 ApplyPromptCaching
+// 在系统提示词和工具定义上自动放置缓存断点。agents.Executor 的
+// WithPromptCaching 即通过此函数为每一步的模型调用开启缓存。
+//
+// 参数：
+//   - ctx: 上下文
+//
+// 返回：
+//   - context.Context: 开启自动缓存的上下文
+//
+func WithPromptCaching(ctx context.Context) context.Context {
+	return context.WithValue(ctx, promptCachingKey{}, true)
+}
+
+// PromptCachingFromContext 返回上下文是否开启了自动提示词缓存。
+func PromptCachingFromContext(ctx context.Context) bool {
+	enabled, _ := ctx.Value(promptCachingKey{}).(bool)
+	return enabled
+}
+
+// ApplyPromptCaching 在系统提示词和工具定义上放置缓存断点。
+//
+// 规则：
+//   - 最后一条系统消息标记缓存断点（已有系统消息带断点时不修改）
+//   - 最后一个工具标记缓存断点（已有工具带断点时不修改）
+//
+// 工具定义在请求中位于系统提示词之前，两个断点分别缓存工具和工具+系统提示词，
+// 每一步只有新增的对话消息需要重新计算。
+//
+// 参数：
+//   - messages: 消息列表
+//   - tools: 工具列表
+//
+// 返回：
+//   - []types.Message: 标记后的消息列表（副本，不修改原列表）
+//   - []types.Tool: 标记后的工具列表（副本，不修改原列表）
+//
+func ApplyPromptCaching(messages []types.Message, tools []types.Tool) ([]types.Message, []types.Tool) {
+	lastSystem := -1
+	for i, msg := range messages {
+		if msg.Role != types.RoleSystem {
+			continue
+		}
+		if msg.CacheControl != nil {
+			lastSystem = -1
+			break
+		}
+		lastSystem = i
+	}
+	if lastSystem >= 0 {
+		messages = append([]types.Message(nil), messages...)
+		messages[lastSystem] = messages[lastSystem].WithCacheControl(types.NewCacheControl())
+	}
+
+	if len(tools) > 0 {
+		marked := false
+		for _, tool := range tools {
+			if tool.CacheControl != nil {
+				marked = true
+				break
+			}
+		}
+		if !marked {
+			tools = append([]types.Tool(nil), tools...)
+			tools[len(tools)-1].CacheControl = types.NewCacheControl()
+		}
+	}
+
+	return messages, tools
+}
+
+// SystemToAnthropic 将系统消息转换为 Anthropic 的 system 文本块数组。
+//
+// 每条系统消息对应一个文本块，带缓存断点的消息添加 cache_control。
+// 系统消息都不带缓存断点时，使用 MessagesToAnthropic 返回的字符串即可。
+//
+// 参数：
+//   - messages: 消息列表
+//
+// 返回：
+//   - []map[string]any: Anthropic 格式的 system 文本块，没有系统消息时为空
+//
+func SystemToAnthropic(messages []types.Message) []map[string]any {
+	result := make([]map[string]any, 0)
+	for _, msg := range messages {
+		if msg.Role != types.RoleSystem || msg.Content == "" {
+			continue
+		}
+
+		block := map[string]any{"type": "text", "text": msg.Content}
+		if msg.CacheControl != nil {
+			block["cache_control"] = msg.CacheControl.ToMap()
+		}
+		result = append(result, block)
+	}
+	return result
+}
+
+// HasSystemCacheControl 返回是否有系统消息带缓存断点。
+func HasSystemCacheControl(messages []types.Message) bool {
+	for _, msg := range messages {
+		if msg.Role == types.RoleSystem && msg.CacheControl != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// anthropicContentWithCacheControl 在 Anthropic content 的最后一个块上添加缓存断点。
+//
+// 字符串内容会转换为单个文本块。
+func anthropicContentWithCacheControl(content any, cacheControl *types.CacheControl) any {
+	switch c := content.(type) {
+	case string:
+		if c == "" {
+			return c
+		}
+		return []map[string]any{{
+			"type":          "text",
+			"text":          c,
+			"cache_control": cacheControl.ToMap(),
+		}}
+	case []map[string]any:
+		if len(c) > 0 {
+			c[len(c)-1]["cache_control"] = cacheControl.ToMap()
+		}
+		return c
+	default:
+		return content
+	}
+}
@@ -0,0 +1,109 @@
+package chat
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zhucl121/langchain-go/pkg/types"
+)
+
+func TestPromptCachingContext(t *testing.T) {
+	assert.False(t, PromptCachingFromContext(context.Background()))
+	assert.True(t, PromptCachingFromContext(WithPromptCaching(context.Background())))
+}
+
+func TestApplyPromptCaching(t *testing.T) {
+	messages := []types.Message{
+		types.NewSystemMessage("base prompt"),
+		types.NewSystemMessage("skills"),
+		types.NewUserMessage("Hello"),
+	}
+	tools := []types.Tool{{Name: "search"}, {Name: "calculator"}}
+
+	cachedMessages, cachedTools := ApplyPromptCaching(messages, tools)
+
+	// 最后一条系统消息和最后一个工具带断点
+	assert.Nil(t, cachedMessages[0].CacheControl)
+	assert.NotNil(t, cachedMessages[1].CacheControl)
+	assert.Nil(t, cachedMessages[2].CacheControl)
+	assert.Nil(t, cachedTools[0].CacheControl)
+	assert.NotNil(t, cachedTools[1].CacheControl)
+
+	// 不修改原列表
+	assert.Nil(t, messages[1].CacheControl)
+	assert.Nil(t, tools[1].CacheControl)
+
+	t.Run("keeps explicit breakpoints", func(t *testing.T) {
+		explicit := types.NewCacheControlWithTTL("1h")
+		messages := []types.Message{
+			types.NewSystemMessage("base prompt").WithCacheControl(explicit),
+			types.NewSystemMessage("dynamic"),
+		}
+		tools := []types.Tool{{Name: "search", CacheControl: explicit}, {Name: "calculator"}}
+
+		cachedMessages, cachedTools := ApplyPromptCaching(messages, tools)
+
+		assert.Same(t, explicit, cachedMessages[0].CacheControl)
+		assert.Nil(t, cachedMessages[1].CacheControl)
+		assert.Same(t, explicit, cachedTools[0].CacheControl)
+		assert.Nil(t, cachedTools[1].CacheControl)
+	})
+}
+
+func TestSystemToAnthropic(t *testing.T) {
+	messages := []types.Message{
+		types.NewSystemMessage("base prompt").WithCacheControl(types.NewCacheControl()),
+		types.NewSystemMessage("dynamic"),
+		types.NewUserMessage("Hello"),
+	}
+
+	require.True(t, HasSystemCacheControl(messages))
+	assert.False(t, HasSystemCacheControl(messages[1:]))
+
+	assert.Equal(t, []map[string]any{
+		{"type": "text", "text": "base prompt", "cache_control": map[string]any{"type": "ephemeral"}},
+		{"type": "text", "text": "dynamic"},
+	}, SystemToAnthropic(messages))
+}
+
+func TestMessagesToAnthropic_CacheControl(t *testing.T) {
+	cacheControl := types.NewCacheControl()
+	messages := []types.Message{
+		types.NewUserMessage("long document").WithCacheControl(cacheControl),
+		{
+			Role: types.RoleAssistant,
+			ToolCalls: []types.ToolCall{{
+				ID:       "call_1",
+				Type:     "function",
+				Function: types.FunctionCall{Name: "search", Arguments: `{"q":"go"}`},
+			}},
+		},
+		types.NewToolMessage("call_1", "results").WithCacheControl(cacheControl),
+		types.NewMessageWithParts(types.RoleUser,
+			types.NewTextContent("first"),
+			types.NewTextContent("second").WithCacheControl(cacheControl)),
+	}
+
+	_, result, err := MessagesToAnthropic(messages)
+	require.NoError(t, err)
+	require.Len(t, result, 4)
+
+	expected := map[string]any{"type": "ephemeral"}
+
+	// 字符串内容转换为带断点的文本块
+	assert.Equal(t, []map[string]any{
+		{"type": "text", "text": "long document", "cache_control": expected},
+	}, result[0]["content"])
+
+	// 断点放在 tool_result 块上
+	toolResult := result[2]["content"].([]map[string]any)
+	assert.Equal(t, expected, toolResult[0]["cache_control"])
+
+	// 多模态内容级别的断点
+	parts := result[3]["content"].([]map[string]any)
+	assert.NotContains(t, parts[0], "cache_control")
+	assert.Equal(t, expected, parts[1]["cache_control"])
+}
@@ -0,0 +1,143 @@
+package chat
+
+import (
+	"context"
+
+	"github.com/zhucl121/langchain-go/core/callbacks"
+	"github.com/zhucl121/langchain-go/core/runnable"
+	"github.com/zhucl121/langchain-go/pkg/types"
+)
+
+// TraceInvoke 在一次 LLM 运行中执行聊天模型调用。
+//
+// 提供商的 Invoke 通过它向上下文中的回调处理器（callbacks.WithHandlers
+// 或 runnable.WithCallbacks 传入）发送 OnLLMStart 和 OnLLMEnd。
+// 上下文中有 StreamWriter（例如在状态图的节点中调用）时，
+// 调用成功后把完整的响应作为一个 EventStream 事件发送给它。
+//
+// 参数：
+//   - ctx: 上下文
+//   - model: 执行调用的模型，提供运行名称、提供商和绑定的工具
+//   - messages: 输入消息
+//   - opts: 调用选项
+//   - invoke: 实际的调用，使用传入的上下文
+//
+// 返回：
+//   - types.Message: 响应消息
+//   - error: 调用错误
+//
+// 示例：
+//
+//	func (m *ChatModel) Invoke(ctx context.Context, messages []types.Message, opts ...runnable.Option) (types.Message, error) {
+//	    return chat.TraceInvoke(ctx, m, messages, opts, func(ctx context.Context) (types.Message, error) {
+//	        return m.invoke(ctx, messages, opts...)
+//	    })
+//	}
+//
+func TraceInvoke(
+	ctx context.Context,
+	model ChatModel,
+	messages []types.Message,
+	opts []runnable.Option,
+	invoke func(ctx context.Context) (types.Message, error),
+) (types.Message, error) {
+	ctx, run := startLLM(ctx, model, messages, opts)
+	message, err := invoke(ctx)
+	if err == nil {
+		runnable.EmitStreamEvent(ctx, runnable.StreamEvent[any]{
+			Type: runnable.EventStream,
+			Name: model.GetName(),
+			Data: message,
+		})
+	}
+	run.EndLLM(ctx, message, err)
+	return message, err
+}
+
+// TraceStream 在一次 LLM 运行中执行流式聊天模型调用。
+//
+// 与 TraceInvoke 相同，并对流中每个带文本的 EventStream 发送 OnLLMToken。
+// OnLLMEnd 在流结束时发送，输出为 EventEnd 中的完整消息。
+// ctx 取消后不再向返回的 channel 发送事件，读完上游的流并以 ctx.Err()
+// 作为错误发送 OnLLMEnd，调用方中途放弃读取时不会阻塞上游的生产者。
+//
+// 参数：
+//   - ctx: 上下文
+//   - model: 执行调用的模型
+//   - messages: 输入消息
+//   - opts: 调用选项
+//   - stream: 实际的流式调用，使用传入的上下文
+//
+// 返回：
+//   - <-chan runnable.StreamEvent[types.Message]: 流式事件 channel
+//   - error: 启动错误
+//
+func TraceStream(
+	ctx context.Context,
+	model ChatModel,
+	messages []types.Message,
+	opts []runnable.Option,
+	stream func(ctx context.Context) (<-chan runnable.StreamEvent[types.Message], error),
+) (<-chan runnable.StreamEvent[types.Message], error) {
+	ctx, run := startLLM(ctx, model, messages, opts)
+	events, err := stream(ctx)
+	if run == nil {
+		return events, err
+	}
+	if err != nil {
+		run.EndLLM(ctx, types.Message{}, err)
+		return nil, err
+	}
+
+	out := make(chan runnable.StreamEvent[types.Message], cap(events))
+	go func() {
+		defer close(out)
+
+		var (
+			output    types.Message
+			streamErr error
+		)
+		for event := range events {
+			switch event.Type {
+			case runnable.EventStream:
+				if event.Data.Content != "" {
+					run.LLMToken(ctx, event.Data.Content)
+				}
+			case runnable.EventEnd:
+				output = event.Data
+			case runnable.EventError:
+				streamErr = event.Error
+			}
+
+			select {
+			case out <- event:
+			case <-ctx.Done():
+				for range events {
+				}
+				run.EndLLM(ctx, output, ctx.Err())
+				return
+			}
+		}
+		run.EndLLM(ctx, output, streamErr)
+	}()
+
+	return out, nil
+}
+
+// startLLM 把调用选项中的回调加入上下文并开始 LLM 运行。
+func startLLM(ctx context.Context, model ChatModel, messages []types.Message, opts []runnable.Option) (context.Context, *callbacks.Run) {
+	ctx = runnable.NewOptions(opts...).CallbackContext(ctx)
+	if !callbacks.HasHandlers(ctx) {
+		return ctx, nil
+	}
+
+	event := callbacks.LLMStartEvent{
+		Provider: model.GetProvider(),
+		Model:    model.GetModelName(),
+		Messages: messages,
+	}
+	if bound, ok := model.(interface{ GetBoundTools() []types.Tool }); ok {
+		event.Tools = bound.GetBoundTools()
+	}
+	return callbacks.StartLLM(ctx, model.GetModelName(), event)
+}
@@ -38,6 +38,81 @@
 //	}
 //	fmt.Println(response.Content)
 //
+// Token 用量和结束原因：
+//
+// 所有提供商都会在响应消息（流式输出时为 EventEnd 中的完整消息）上设置
+// UsageMetadata 和 FinishReason，结束原因统一归一化为 types.FinishReason：
+//
+//	if usage := response.UsageMetadata; usage != nil {
+//	    fmt.Println(usage.InputTokens, usage.OutputTokens, usage.CachedTokens)
+//	}
+//	if response.FinishReason == types.FinishReasonLength {
+//	    // 输出被截断
+//	}
+//
+// 多模态输入：
+//
+// Message.Parts 按顺序保存文本、图像、音频和文件内容，各提供商转换为自己的格式
+// （OpenAI image_url / input_audio / file，Anthropic image / document，
+// Gemini inlineData / fileData，Ollama images）；不支持的内容类型返回错误：
+//
+//	image, _ := types.NewImageContentFromFile("screenshot.png")
+//	msg := types.NewMessageWithParts(types.RoleUser,
+//	    types.NewTextContent("What is wrong in this screenshot?"),
+//	    image,
+//	)
+//	response, err := model.Invoke(ctx, []types.Message{msg})
+//
+// 扩展思考：
+//
+// Anthropic 扩展思考、OpenAI 推理强度和推理摘要、Gemini 思考预算统一通过
+// types.ThinkingConfig 配置，可以在提供商 Config 中设置，也可以通过 runnable.WithThinking
+// 按调用覆盖。思考内容以思考块出现在 Message.ContentBlocks 中（流式输出时为
+// EventStream 中的增量块），不会混入 Content。Anthropic 的思考块带有签名，
+// 把响应消息原样放回对话历史即可在工具调用轮次中回传：
+//
+//	response, err := model.Invoke(ctx, messages,
+//	    runnable.WithThinking(types.NewThinkingConfig(4096)))
+//	fmt.Println(response.ThinkingText())
+//	messages = append(messages, response) // 保留签名的思考块
+//
+// 单次调用参数：
+//
+// 温度、最大 token 数、停止序列、随机种子、工具选择、并行工具调用和响应格式
+// 可以通过 runnable.Option 按调用设置，优先于提供商 Config 中的配置。提供商
+// 不支持的参数会被忽略（如 Anthropic 不支持随机种子和响应格式）：
+//
+//	response, err := modelWithTools.Invoke(ctx, messages,
+//	    runnable.WithTemperature(0),
+//	    runnable.WithStop("Observation:"),
+//	    runnable.WithToolChoice(types.NewSpecificToolChoice("get_weather")),
+//	    runnable.WithParallelToolCalls(false),
+//	)
+//
+// 提示词缓存：
+//
+// 在消息、多模态内容或工具上设置 types.CacheControl 即放置缓存断点，Anthropic
+// 转换为 cache_control；OpenAI 自动缓存相同的前缀，忽略断点。通过 chat.WithPromptCaching
+// 或 Anthropic Config.PromptCaching 开启自动模式，会在系统提示词和工具定义上放置断点
+// （agents.Executor.WithPromptCaching 在每一步都开启）。缓存命中和写入的 token 数分别
+// 记录在 UsageMetadata.CachedTokens 和 CacheCreationTokens 中：
+//
+//	system := types.NewSystemMessage(longPrompt).WithCacheControl(types.NewCacheControl())
+//	response, err := model.Invoke(chat.WithPromptCaching(ctx), messages)
+//	fmt.Println(response.UsageMetadata.CachedTokens)
+//
+// 错误处理：
+//
+// 所有提供商把 HTTP 错误响应和流中的错误事件转换为 chat/errors 中的类型化错误
+// （限流、鉴权失败、超出上下文长度、内容拦截、无效请求、服务端错误、超时），
+// WithRetry、WithFallbacks、router 和 failover.CircuitBreaker 均按类型决定是否重试、
+// 降级和熔断：
+//
+//	var rateLimited *chaterrors.RateLimitedError
+//	if errors.As(err, &rateLimited) {
+//	    fmt.Println(rateLimited.Provider, rateLimited.RetryAfter())
+//	}
+//
 // 工具调用示例：
 //
 //	// 定义工具
@@ -93,10 +168,12 @@
 //   - OpenAI (GPT-3.5, GPT-4, GPT-4o 等)
 //   - Anthropic (Claude 3 系列)
 //   - Ollama (本地模型)
+//   - OpenAI 兼容服务 (vLLM、llama.cpp、LM Studio 等，见 providers/openaicompat)
 //
 // 提供商特定功能：
 //   - OpenAI: 支持 Vision、JSON Mode、Function Calling
 //   - Anthropic: 支持 Computer Use、Vision、Tool Use
 //   - Ollama: 支持本地运行开源模型
+//   - OpenAI 兼容服务: 按能力标志降级不支持的参数，可通过配置注册新服务
 //
 package chat
@@ -0,0 +1,32 @@
+// Package errors 定义所有聊天模型提供商共用的类型化错误。
+//
+// 各提供商把 HTTP 错误响应、流中的错误事件和传输层超时统一转换为以下类型：
+//
+//   - *RateLimitedError: 限流（HTTP 429），RetryAfter 返回服务端建议的等待时间
+//   - *AuthenticationError: 鉴权失败（HTTP 401/403）
+//   - *ContextLengthExceededError: 超出模型上下文长度
+//   - *ContentFilteredError: 内容被安全策略拦截
+//   - *InvalidRequestError: 其他请求错误（HTTP 4xx）
+//   - *ServerError: 服务端错误（HTTP 5xx、过载）
+//   - *TimeoutError: 请求超时
+//
+// 所有类型都嵌入 ProviderError，携带提供商名称、HTTP 状态码、提供商错误码和原始信息，
+// 并实现 runnable.ClassifiedError，因此 RetryRunnable、FallbackRunnable 和模型路由器
+// 直接按类型决定是否重试和降级，RateLimitedError 还实现 runnable.RetryAfterError。
+//
+// 判断错误类型可以使用 errors.As 或哨兵错误：
+//
+//	response, err := model.Invoke(ctx, messages)
+//
+//	var rateLimited *chaterrors.RateLimitedError
+//	if errors.As(err, &rateLimited) {
+//	    time.Sleep(rateLimited.RetryAfter())
+//	}
+//
+//	if errors.Is(err, chaterrors.ErrContextLengthExceeded) {
+//	    // 裁剪历史消息后重试
+//	}
+//
+// 包名与标准库 errors 相同，导入时建议使用别名 chaterrors。
+//
+package errors
@@ -0,0 +1,178 @@
+package errors
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zhucl121/langchain-go/core/runnable"
+)
+
+// 哨兵错误，用于 errors.Is 判断错误类型。
+var (
+	// ErrRateLimited 限流
+	ErrRateLimited = stderrors.New("rate limited")
+
+	// ErrAuthentication 鉴权失败
+	ErrAuthentication = stderrors.New("authentication failed")
+
+	// ErrContextLengthExceeded 超出上下文长度
+	ErrContextLengthExceeded = stderrors.New("context length exceeded")
+
+	// ErrContentFiltered 内容被拦截
+	ErrContentFiltered = stderrors.New("content filtered")
+
+	// ErrInvalidRequest 无效请求
+	ErrInvalidRequest = stderrors.New("invalid request")
+
+	// ErrServer 服务端错误
+	ErrServer = stderrors.New("server error")
+
+	// ErrTimeout 超时
+	ErrTimeout = stderrors.New("timeout")
+)
+
+// ProviderError 是所有类型化错误的公共部分。
+type ProviderError struct {
+	// Provider 是提供商名称（如 "openai"、"anthropic"）
+	Provider string
+
+	// StatusCode 是 HTTP 状态码，流中的错误事件为 0
+	StatusCode int
+
+	// Code 是提供商返回的错误码或错误类型（如 "rate_limit_exceeded"、"overloaded_error"）
+	Code string
+
+	// Message 是提供商返回的错误信息
+	Message string
+
+	// Err 是底层错误（可选），如传输层错误
+	Err error
+}
+
+// Unwrap 返回底层错误。
+func (e *ProviderError) Unwrap() error {
+	return e.Err
+}
+
+// format 按 "provider: kind (status N, code): message" 的格式生成错误信息。
+func (e *ProviderError) format(kind error) string {
+	s := kind.Error()
+	if e.Provider != "" {
+		s = e.Provider + ": " + s
+	}
+
+	var details []string
+	if e.StatusCode > 0 {
+		details = append(details, fmt.Sprintf("status %d", e.StatusCode))
+	}
+	if e.Code != "" {
+		details = append(details, e.Code)
+	}
+	if len(details) > 0 {
+		s += " (" + strings.Join(details, ", ") + ")"
+	}
+
+	if e.Message != "" {
+		s += ": " + e.Message
+	}
+	return s
+}
+
+// RateLimitedError 表示请求被限流。
+type RateLimitedError struct {
+	ProviderError
+
+	// Delay 是服务端建议的重试等待时间，未知时为 0
+	Delay time.Duration
+}
+
+func (e *RateLimitedError) Error() string                 { return e.format(ErrRateLimited) }
+func (e *RateLimitedError) Is(target error) bool          { return target == ErrRateLimited }
+func (e *RateLimitedError) ErrorKind() runnable.ErrorKind { return runnable.ErrorKindRateLimit }
+func (e *RateLimitedError) RetryAfter() time.Duration     { return e.Delay }
+
+// AuthenticationError 表示 API 密钥无效或没有权限。
+type AuthenticationError struct {
+	ProviderError
+}
+
+func (e *AuthenticationError) Error() string                 { return e.format(ErrAuthentication) }
+func (e *AuthenticationError) Is(target error) bool          { return target == ErrAuthentication }
+func (e *AuthenticationError) ErrorKind() runnable.ErrorKind { return runnable.ErrorKindPermanent }
+
+// ContextLengthExceededError 表示输入超出模型的上下文长度。
+//
+// 重试无效，但可以降级到上下文更大的模型。
+type ContextLengthExceededError struct {
+	ProviderError
+}
+
+func (e *ContextLengthExceededError) Error() string        { return e.format(ErrContextLengthExceeded) }
+func (e *ContextLengthExceededError) Is(target error) bool { return target == ErrContextLengthExceeded }
+func (e *ContextLengthExceededError) ErrorKind() runnable.ErrorKind {
+	return runnable.ErrorKindContextLength
+}
+
+// ContentFilteredError 表示输入或输出被提供商的内容安全策略拦截。
+type ContentFilteredError struct {
+	ProviderError
+}
+
+func (e *ContentFilteredError) Error() string                 { return e.format(ErrContentFiltered) }
+func (e *ContentFilteredError) Is(target error) bool          { return target == ErrContentFiltered }
+func (e *ContentFilteredError) ErrorKind() runnable.ErrorKind { return runnable.ErrorKindPermanent }
+
+// InvalidRequestError 表示请求参数无效（其他 4xx 错误）。
+type InvalidRequestError struct {
+	ProviderError
+}
+
+func (e *InvalidRequestError) Error() string                 { return e.format(ErrInvalidRequest) }
+func (e *InvalidRequestError) Is(target error) bool          { return target == ErrInvalidRequest }
+func (e *InvalidRequestError) ErrorKind() runnable.ErrorKind { return runnable.ErrorKindPermanent }
+
+// ServerError 表示提供商服务端错误或过载。
+type ServerError struct {
+	ProviderError
+}
+
+func (e *ServerError) Error() string                 { return e.format(ErrServer) }
+func (e *ServerError) Is(target error) bool          { return target == ErrServer }
+func (e *ServerError) ErrorKind() runnable.ErrorKind { return runnable.ErrorKindServer }
+
+// TimeoutError 表示请求超时。
+type TimeoutError struct {
+	ProviderError
+}
+
+func (e *TimeoutError) Error() string                 { return e.format(ErrTimeout) }
+func (e *TimeoutError) Is(target error) bool          { return target == ErrTimeout }
+func (e *TimeoutError) ErrorKind() runnable.ErrorKind { return runnable.ErrorKindTimeout }
+
+// IsProviderFault 返回错误是否说明提供商本身不可用。
+//
+// 限流、鉴权失败、服务端错误、超时以及未分类的错误属于提供商的问题，
+// 应计入熔断器和健康评分；超出上下文长度、内容拦截和无效请求由请求本身导致，
+// 换一个请求即可成功，不应计入。调用方取消（context.Canceled）也不计入。
+//
+// 参数：
+//   - err: 错误
+//
+// 返回：
+//   - bool: 是否计为提供商故障，err 为 nil 时返回 false
+//
+func IsProviderFault(err error) bool {
+	switch {
+	case err == nil, stderrors.Is(err, context.Canceled):
+		return false
+	case stderrors.Is(err, ErrContextLengthExceeded),
+		stderrors.Is(err, ErrContentFiltered),
+		stderrors.Is(err, ErrInvalidRequest):
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,211 @@
+package errors
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zhucl121/langchain-go/core/runnable"
+)
+
+func response(status int, header map[string]string) *http.Response {
+	resp := &http.Response{StatusCode: status, Header: http.Header{}}
+	for k, v := range header {
+		resp.Header.Set(k, v)
+	}
+	return resp
+}
+
+func TestFromResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		status   int
+		header   map[string]string
+		body     string
+		sentinel error
+		code     string
+		message  string
+	}{
+		{
+			name: "openai rate limit", provider: "openai", status: 429,
+			body:     `{"error":{"message":"Rate limit reached","type":"requests","code":"rate_limit_exceeded"}}`,
+			sentinel: ErrRateLimited, code: "rate_limit_exceeded", message: "Rate limit reached",
+		},
+		{
+			name: "openai context length", provider: "openai", status: 400,
+			body:     `{"error":{"message":"This model's maximum context length is 8192 tokens","type":"invalid_request_error","code":"context_length_exceeded"}}`,
+			sentinel: ErrContextLengthExceeded, code: "context_length_exceeded",
+		},
+		{
+			name: "azure content filter", provider: "azure", status: 400,
+			body:     `{"error":{"message":"The response was filtered due to the prompt triggering Azure OpenAI's content management policy.","code":"content_filter"}}`,
+			sentinel: ErrContentFiltered, code: "content_filter",
+		},
+		{
+			name: "anthropic auth", provider: "anthropic", status: 401,
+			body:     `{"type":"error","error":{"type":"authentication_error","message":"invalid x-api-key"}}`,
+			sentinel: ErrAuthentication, code: "authentication_error", message: "invalid x-api-key",
+		},
+		{
+			name: "anthropic overloaded", provider: "anthropic", status: 529,
+			body:     `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`,
+			sentinel: ErrServer, code: "overloaded_error",
+		},
+		{
+			name: "anthropic prompt too long", provider: "anthropic", status: 400,
+			body:     `{"type":"error","error":{"type":"invalid_request_error","message":"prompt is too long: 210000 tokens > 200000 maximum"}}`,
+			sentinel: ErrContextLengthExceeded, code: "invalid_request_error",
+		},
+		{
+			name: "gemini resource exhausted", provider: "gemini", status: 429,
+			body:     `{"error":{"code":429,"message":"Quota exceeded","status":"RESOURCE_EXHAUSTED"}}`,
+			sentinel: ErrRateLimited, code: "RESOURCE_EXHAUSTED",
+		},
+		{
+			name: "ollama", provider: "ollama", status: 404,
+			body:     `{"error":"model \"llama9\" not found"}`,
+			sentinel: ErrInvalidRequest, message: `model "llama9" not found`,
+		},
+		{
+			name: "bedrock throttling", provider: "bedrock", status: 400,
+			header:   map[string]string{"x-amzn-ErrorType": "ThrottlingException:http://internal.amazon.com/coral/com.amazon.bedrock/"},
+			body:     `{"message":"Too many requests, please wait before trying again."}`,
+			sentinel: ErrRateLimited, code: "ThrottlingException",
+		},
+		{
+			name: "gateway timeout", provider: "openai", status: 504,
+			body:     `<html>Gateway Timeout</html>`,
+			sentinel: ErrTimeout, message: "<html>Gateway Timeout</html>",
+		},
+		{
+			name: "bad request", provider: "openai", status: 400,
+			body:     `{"error":{"message":"Invalid value for 'temperature'","type":"invalid_request_error"}}`,
+			sentinel: ErrInvalidRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := FromResponse(tt.provider, response(tt.status, tt.header), []byte(tt.body))
+			require.Error(t, err)
+			assert.ErrorIs(t, err, tt.sentinel)
+
+			assert.Contains(t, err.Error(), fmt.Sprintf("%s: %s (status %d", tt.provider, tt.sentinel, tt.status))
+			if tt.message != "" {
+				assert.Contains(t, err.Error(), tt.message)
+			}
+
+			base := providerError(err)
+			require.NotNil(t, base)
+			assert.Equal(t, tt.status, base.StatusCode)
+			if tt.code != "" {
+				assert.Equal(t, tt.code, base.Code)
+			}
+		})
+	}
+}
+
+// providerError 返回类型化错误中的 ProviderError。
+func providerError(err error) *ProviderError {
+	switch e := err.(type) {
+	case *RateLimitedError:
+		return &e.ProviderError
+	case *AuthenticationError:
+		return &e.ProviderError
+	case *ContextLengthExceededError:
+		return &e.ProviderError
+	case *ContentFilteredError:
+		return &e.ProviderError
+	case *InvalidRequestError:
+		return &e.ProviderError
+	case *ServerError:
+		return &e.ProviderError
+	case *TimeoutError:
+		return &e.ProviderError
+	}
+	return nil
+}
+
+func TestFromResponse_RetryAfter(t *testing.T) {
+	tests := []struct {
+		name   string
+		header map[string]string
+		body   string
+		want   time.Duration
+	}{
+		{name: "seconds", header: map[string]string{"Retry-After": "2"}, want: 2 * time.Second},
+		{name: "milliseconds", header: map[string]string{"retry-after-ms": "150", "Retry-After": "1"}, want: 150 * time.Millisecond},
+		{name: "gemini retry info", body: `{"error":{"code":429,"status":"RESOURCE_EXHAUSTED","details":[{"@type":"type.googleapis.com/google.rpc.RetryInfo","retryDelay":"7s"}]}}`, want: 7 * time.Second},
+		{name: "none", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := FromResponse("test", response(429, tt.header), []byte(tt.body))
+
+			var rateLimited *RateLimitedError
+			require.ErrorAs(t, err, &rateLimited)
+			assert.Equal(t, tt.want, rateLimited.RetryAfter())
+
+			after, ok := runnable.RetryAfter(err)
+			assert.Equal(t, tt.want > 0, ok)
+			assert.Equal(t, tt.want, after)
+		})
+	}
+
+	at := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+	err := FromResponse("test", response(429, map[string]string{"Retry-After": at}), nil)
+	after, _ := runnable.RetryAfter(err)
+	assert.InDelta(t, float64(time.Minute), float64(after), float64(2*time.Second))
+}
+
+func TestClassification(t *testing.T) {
+	tests := []struct {
+		err      error
+		kind     runnable.ErrorKind
+		fault    bool
+		retrying bool
+	}{
+		{New("openai", 429, "", "slow down"), runnable.ErrorKindRateLimit, true, true},
+		{New("openai", 401, "", "bad key"), runnable.ErrorKindPermanent, true, false},
+		{New("anthropic", 0, "overloaded_error", "Overloaded"), runnable.ErrorKindServer, true, true},
+		{New("openai", 400, "context_length_exceeded", "too long"), runnable.ErrorKindContextLength, false, false},
+		{New("azure", 400, "content_filter", "filtered"), runnable.ErrorKindPermanent, false, false},
+		{New("openai", 422, "", "bad"), runnable.ErrorKindPermanent, false, false},
+		{FromTransport("openai", fmt.Errorf("send: %w", context.DeadlineExceeded)), runnable.ErrorKindTimeout, true, true},
+		{FromTransport("openai", context.Canceled), runnable.ErrorKindCanceled, false, false},
+		{stderrors.New("unknown"), runnable.ErrorKindUnknown, true, true},
+	}
+
+	for _, tt := range tests {
+		wrapped := fmt.Errorf("chain: %w", tt.err)
+		assert.Equal(t, tt.kind, runnable.ClassifyError(wrapped), "%v", tt.err)
+		assert.Equal(t, tt.fault, IsProviderFault(wrapped), "%v", tt.err)
+		assert.Equal(t, tt.retrying, runnable.ClassifyError(wrapped).Retryable(), "%v", tt.err)
+	}
+
+	timeout := FromTransport("openai", context.DeadlineExceeded)
+	assert.ErrorIs(t, timeout, ErrTimeout)
+	assert.ErrorIs(t, timeout, context.DeadlineExceeded)
+	assert.Equal(t, "openai: timeout: context deadline exceeded", timeout.Error())
+	assert.False(t, IsProviderFault(nil))
+}
+
+func TestFromBody(t *testing.T) {
+	// vLLM 在流中返回的错误数据块，code 为 HTTP 状态码
+	err := FromBody("vllm", 0, []byte(`{"error":{"object":"error","message":"max_tokens is too large","type":"BadRequestError","code":400}}`))
+	assert.ErrorIs(t, err, ErrInvalidRequest)
+	assert.Equal(t, "vllm: invalid request (status 400, BadRequestError): max_tokens is too large", err.Error())
+
+	// Anthropic 流中的 error 事件
+	err = FromBody("anthropic", 0, []byte(`{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`))
+	assert.ErrorIs(t, err, ErrServer)
+	assert.Equal(t, "anthropic: server error (overloaded_error): Overloaded", err.Error())
+}
@@ -0,0 +1,321 @@
+package errors
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 各提供商的错误码，按类型分组（比较时忽略大小写）。
+var (
+	rateLimitCodes = []string{
+		"rate_limit_exceeded", "rate_limit_error", "resource_exhausted",
+		"throttlingexception", "toomanyrequests", "too_many_requests",
+	}
+	authenticationCodes = []string{
+		"authentication_error", "permission_error", "invalid_api_key", "unauthenticated",
+		"permission_denied", "accessdeniedexception", "unrecognizedclientexception",
+	}
+	contextLengthCodes = []string{
+		"context_length_exceeded", "string_above_max_length",
+	}
+	contentFilterCodes = []string{
+		"content_filter", "content_policy_violation", "responsibleaipolicyviolation",
+	}
+	timeoutCodes = []string{
+		"timeout", "deadline_exceeded", "modeltimeoutexception",
+	}
+	serverCodes = []string{
+		"server_error", "api_error", "overloaded_error", "internal", "unavailable",
+		"internalserverexception", "serviceunavailableexception", "modelnotreadyexception",
+	}
+)
+
+// contextLengthPhrases 是各提供商表示超出上下文长度的错误信息
+var contextLengthPhrases = []string{
+	"maximum context length",
+	"context length",
+	"context window",
+	"prompt is too long",
+	"input is too long",
+	"too many tokens",
+	"exceeds the maximum number of tokens",
+	"input token count",
+}
+
+// contentFilterPhrases 是各提供商表示内容被拦截的错误信息
+var contentFilterPhrases = []string{
+	"content management policy",
+	"content filter",
+	"content policy",
+	"safety system",
+}
+
+// New 根据状态码、错误码和错误信息创建类型化错误。
+//
+// 用于流中的错误事件等已经解析出错误码的场景，HTTP 错误响应使用 FromResponse。
+//
+// 参数：
+//   - provider: 提供商名称
+//   - statusCode: HTTP 状态码，未知时为 0
+//   - code: 提供商错误码或错误类型，可以为空
+//   - message: 错误信息
+//
+// 返回：
+//   - error: 类型化错误，无法从状态码和错误码判断时为 *ServerError
+//
+func New(provider string, statusCode int, code, message string) error {
+	return classify(ProviderError{
+		Provider:   provider,
+		StatusCode: statusCode,
+		Code:       code,
+		Message:    message,
+	}, []string{code}, 0)
+}
+
+// FromResponse 把 HTTP 错误响应转换为类型化错误。
+//
+// 支持的响应体格式：
+//   - {"error": {"message", "type", "code", "status"}}（OpenAI、Azure、Anthropic、Gemini）
+//   - {"error": "..."}（Ollama）
+//   - {"message": "..."} 加 x-amzn-ErrorType 响应头（Bedrock）
+//
+// 无法解析的响应体原样作为错误信息。限流等待时间依次取自 retry-after-ms、
+// Retry-After（秒数或 HTTP 日期）响应头和 Gemini 的 RetryInfo。
+//
+// 参数：
+//   - provider: 提供商名称
+//   - resp: HTTP 响应
+//   - body: 已读取的响应体
+//
+// 返回：
+//   - error: 类型化错误
+//
+func FromResponse(provider string, resp *http.Response, body []byte) error {
+	return fromBody(provider, resp.StatusCode, resp.Header, body)
+}
+
+// FromBody 把 JSON 错误体转换为类型化错误。
+//
+// 用于流中以数据块形式返回的错误（如 Anthropic 的 error 事件、
+// OpenAI 兼容服务的 {"error": {...}} 数据块），格式同 FromResponse。
+// statusCode 为 0 时使用错误体中数字形式的 code（如 vLLM 的 "code": 400）。
+//
+// 参数：
+//   - provider: 提供商名称
+//   - statusCode: HTTP 状态码，未知时为 0
+//   - body: 错误体
+//
+// 返回：
+//   - error: 类型化错误
+//
+func FromBody(provider string, statusCode int, body []byte) error {
+	return fromBody(provider, statusCode, nil, body)
+}
+
+// fromBody 解析错误体和响应头并分类。
+func fromBody(provider string, statusCode int, header http.Header, body []byte) error {
+	parsed := parseBody(body)
+	if statusCode == 0 {
+		statusCode = parsed.status
+	}
+
+	codes := parsed.codes
+	if amzn := header.Get("x-amzn-ErrorType"); amzn != "" {
+		// 格式为 "ThrottlingException:http://internal.amazon.com/..."
+		codes = append(codes, strings.SplitN(amzn, ":", 2)[0])
+	}
+
+	code := ""
+	for _, c := range codes {
+		if c != "" {
+			code = c
+			break
+		}
+	}
+
+	delay := retryAfter(header)
+	if delay == 0 {
+		delay = parsed.retryDelay
+	}
+
+	return classify(ProviderError{
+		Provider:   provider,
+		StatusCode: statusCode,
+		Code:       code,
+		Message:    parsed.message,
+	}, codes, delay)
+}
+
+// FromTransport 把传输层错误转换为类型化错误。
+//
+// 超时（net.Error.Timeout() 或 context.DeadlineExceeded）转换为 *TimeoutError，
+// 其他错误（包括调用方取消）原样返回。
+//
+// 参数：
+//   - provider: 提供商名称
+//   - err: 传输层错误
+//
+// 返回：
+//   - error: 转换后的错误，err 为 nil 时返回 nil
+//
+func FromTransport(provider string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var netErr net.Error
+	if stderrors.Is(err, context.DeadlineExceeded) || (stderrors.As(err, &netErr) && netErr.Timeout()) {
+		return &TimeoutError{ProviderError{Provider: provider, Message: err.Error(), Err: err}}
+	}
+	return err
+}
+
+// classify 按错误码、状态码和错误信息确定错误类型。
+//
+// 上下文长度和内容拦截通常以 HTTP 400 返回，因此优先根据错误码和错误信息判断。
+func classify(base ProviderError, codes []string, delay time.Duration) error {
+	message := strings.ToLower(base.Message)
+	status := base.StatusCode
+
+	switch {
+	case hasCode(codes, contextLengthCodes) || containsAny(message, contextLengthPhrases):
+		return &ContextLengthExceededError{base}
+	case hasCode(codes, contentFilterCodes) || containsAny(message, contentFilterPhrases):
+		return &ContentFilteredError{base}
+	case status == http.StatusTooManyRequests || hasCode(codes, rateLimitCodes):
+		return &RateLimitedError{ProviderError: base, Delay: delay}
+	case status == http.StatusUnauthorized || status == http.StatusForbidden || hasCode(codes, authenticationCodes):
+		return &AuthenticationError{base}
+	case status == http.StatusRequestTimeout || status == http.StatusGatewayTimeout || hasCode(codes, timeoutCodes):
+		return &TimeoutError{base}
+	case status >= 500 || hasCode(codes, serverCodes):
+		return &ServerError{base}
+	case status >= 400:
+		return &InvalidRequestError{base}
+	default:
+		return &ServerError{base}
+	}
+}
+
+// parsedBody 是从错误响应体中解析出的内容。
+type parsedBody struct {
+	message    string
+	codes      []string
+	status     int
+	retryDelay time.Duration
+}
+
+// errorBody 覆盖各提供商错误响应体的字段。
+type errorBody struct {
+	Error   json.RawMessage `json:"error"`
+	Message string          `json:"message"`
+	Type    string          `json:"__type"`
+}
+
+// errorDetail 是 {"error": {...}} 中的错误对象。
+type errorDetail struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+	Code    any    `json:"code"`
+	Status  string `json:"status"`
+	Details []struct {
+		Type       string `json:"@type"`
+		RetryDelay string `json:"retryDelay"`
+	} `json:"details"`
+}
+
+// parseBody 解析错误响应体。
+func parseBody(body []byte) parsedBody {
+	raw := strings.TrimSpace(string(body))
+
+	var eb errorBody
+	if err := json.Unmarshal(body, &eb); err != nil {
+		return parsedBody{message: raw}
+	}
+
+	parsed := parsedBody{message: eb.Message, codes: []string{eb.Type}}
+
+	var detail errorDetail
+	var text string
+	switch {
+	case len(eb.Error) == 0:
+	case json.Unmarshal(eb.Error, &detail) == nil:
+		if detail.Message != "" {
+			parsed.message = detail.Message
+		}
+		// Gemini 和 vLLM 的 code 是 HTTP 状态码，Gemini 的 status 才是错误码
+		switch code := detail.Code.(type) {
+		case string:
+			parsed.codes = append([]string{code, detail.Type, detail.Status}, parsed.codes...)
+		case float64:
+			parsed.status = int(code)
+			parsed.codes = append([]string{detail.Type, detail.Status}, parsed.codes...)
+		default:
+			parsed.codes = append([]string{detail.Type, detail.Status}, parsed.codes...)
+		}
+		for _, d := range detail.Details {
+			if strings.HasSuffix(d.Type, "RetryInfo") {
+				parsed.retryDelay, _ = time.ParseDuration(d.RetryDelay)
+			}
+		}
+	case json.Unmarshal(eb.Error, &text) == nil:
+		parsed.message = text
+	}
+
+	if parsed.message == "" {
+		parsed.message = raw
+	}
+	return parsed
+}
+
+// retryAfter 解析响应头中的重试等待时间。
+func retryAfter(header http.Header) time.Duration {
+	if ms, err := strconv.Atoi(header.Get("retry-after-ms")); err == nil && ms > 0 {
+		return time.Duration(ms) * time.Millisecond
+	}
+
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+		return time.Duration(seconds * float64(time.Second))
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if d := time.Until(at); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// hasCode 返回 codes 中是否有属于 known 的错误码。
+func hasCode(codes []string, known []string) bool {
+	for _, code := range codes {
+		if code == "" {
+			continue
+		}
+		for _, k := range known {
+			if strings.EqualFold(code, k) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// containsAny 返回 s 是否包含任一短语。
+func containsAny(s string, phrases []string) bool {
+	for _, phrase := range phrases {
+		if strings.Contains(s, phrase) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,8 +1,10 @@
 package chat
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/zhucl121/langchain-go/pkg/types"
 )
@@ -16,7 +18,7 @@ import (
 //
 // OpenAI 消息格式：
 //   - role: "system" | "user" | "assistant" | "tool"
-//   - content: 消息内容（字符串）
+//   - content: 消息内容（字符串；多模态消息为 content 数组，见 PartsToOpenAI）
 //   - tool_calls: 工具调用列表（仅 assistant）
 //   - tool_call_id: 工具调用 ID（仅 tool）
 //
@@ -40,6 +42,15 @@ func MessagesToOpenAI(messages []types.Message) ([]map[string]any, error) {
 			"content": msg.Content,
 		}
 
+		// 多模态内容使用 content 数组
+		if len(msg.Parts) > 0 {
+			parts, err := PartsToOpenAI(msg.Parts)
+			if err != nil {
+				return nil, fmt.Errorf("invalid message at index %d: %w", i, err)
+			}
+			openaiMsg["content"] = parts
+		}
+
 		// 添加可选字段
 		if msg.Name != "" {
 			openaiMsg["name"] = msg.Name
@@ -78,6 +89,9 @@ func MessagesToOpenAI(messages []types.Message) ([]map[string]any, error) {
 //   - system 消息单独处理，不在 messages 数组中
 //   - role 只有 "user" 和 "assistant"
 //   - tool_use 和 tool_result 作为 content 的一部分
+//   - 多模态消息的图像和文档作为 content 的一部分（见 PartsToAnthropic）
+//   - 带缓存断点的消息在最后一个内容块上添加 cache_control
+//   - 系统消息合并为字符串，其缓存断点需通过 SystemToAnthropic 转换
 //
 // 参数：
 //   - messages: 消息列表
@@ -116,10 +130,13 @@ func MessagesToAnthropic(messages []types.Message) (string, []map[string]any, er
 			"role": role,
 		}
 
+		// 带签名的思考块需要原样回传（工具调用轮次中 Anthropic 要求）
+		thinking := ThinkingToAnthropic(msg.ContentBlocks)
+
 		// 处理内容
-		if msg.Role == types.RoleAssistant && len(msg.ToolCalls) > 0 {
-			// Assistant 消息带工具调用
-			content := make([]map[string]any, 0)
+		if msg.Role == types.RoleAssistant && (len(msg.ToolCalls) > 0 || len(thinking) > 0) {
+			// Assistant 消息带工具调用或思考块，思考块必须在最前面
+			content := thinking
 
 			// 如果有文本内容，添加 text block
 			if msg.Content != "" {
@@ -155,17 +172,197 @@ func MessagesToAnthropic(messages []types.Message) (string, []map[string]any, er
 				},
 			}
 			anthropicMsg["content"] = content
+		} else if len(msg.Parts) > 0 {
+			// 多模态消息
+			content, err := PartsToAnthropic(msg.Parts)
+			if err != nil {
+				return "", nil, fmt.Errorf("invalid message at index %d: %w", i, err)
+			}
+			anthropicMsg["content"] = content
 		} else {
 			// 普通消息
 			anthropicMsg["content"] = msg.Content
 		}
 
+		// 缓存断点放在消息的最后一个内容块上
+		if msg.CacheControl != nil {
+			anthropicMsg["content"] = anthropicContentWithCacheControl(anthropicMsg["content"], msg.CacheControl)
+		}
+
 		result = append(result, anthropicMsg)
 	}
 
 	return systemMessage, result, nil
 }
 
+// ThinkingToAnthropic 将思考内容块转换为 Anthropic 的 content 块。
+//
+// 只转换带签名的思考块（由 Anthropic 返回）：普通思考块转换为 thinking，
+// 被加密隐藏的思考块转换为 redacted_thinking。其他提供商的思考块没有签名，会被忽略。
+//
+// 参数：
+//   - blocks: 消息的内容块
+//
+// 返回：
+//   - []map[string]any: Anthropic 格式的 content 块
+//
+func ThinkingToAnthropic(blocks []*types.ContentBlock) []map[string]any {
+	result := make([]map[string]any, 0)
+	for _, block := range blocks {
+		if block.Type != types.ContentBlockThinking || block.Signature == "" {
+			continue
+		}
+
+		if block.Redacted {
+			result = append(result, map[string]any{
+				"type": "redacted_thinking",
+				"data": block.Signature,
+			})
+			continue
+		}
+
+		result = append(result, map[string]any{
+			"type":      "thinking",
+			"thinking":  block.Content,
+			"signature": block.Signature,
+		})
+	}
+	return result
+}
+
+// PartsToOpenAI 将多模态内容转换为 OpenAI 的 content 数组。
+//
+// 转换规则：
+//   - 文本：{"type": "text"}
+//   - 图像：{"type": "image_url"}，URL 或 Base64 data URL，支持 ImageDetail
+//   - 音频：{"type": "input_audio"}，仅支持数据（wav、mp3）
+//   - 文件：{"type": "file"}，仅支持数据（如 PDF）
+//
+// 参数：
+//   - parts: 多模态内容
+//
+// 返回：
+//   - []map[string]any: OpenAI 格式的 content 数组
+//   - error: 不支持的内容
+//
+func PartsToOpenAI(parts []*types.MultimodalContent) ([]map[string]any, error) {
+	result := make([]map[string]any, 0, len(parts))
+
+	for _, part := range parts {
+		switch part.Type {
+		case types.ContentTypeText:
+			result = append(result, map[string]any{"type": "text", "text": part.Text})
+
+		case types.ContentTypeImage:
+			url := part.ImageURL
+			if len(part.ImageData) > 0 {
+				url = part.DataURL()
+			}
+			if url == "" {
+				return nil, fmt.Errorf("image part has neither url nor data")
+			}
+			imageURL := map[string]any{"url": url}
+			if part.ImageDetail != "" {
+				imageURL["detail"] = part.ImageDetail
+			}
+			result = append(result, map[string]any{"type": "image_url", "image_url": imageURL})
+
+		case types.ContentTypeAudio:
+			if len(part.AudioData) == 0 {
+				return nil, fmt.Errorf("audio part must contain data")
+			}
+			result = append(result, map[string]any{
+				"type": "input_audio",
+				"input_audio": map[string]any{
+					"data":   base64.StdEncoding.EncodeToString(part.AudioData),
+					"format": string(part.AudioFormat),
+				},
+			})
+
+		case types.ContentTypeFile:
+			if len(part.FileData) == 0 {
+				return nil, fmt.Errorf("file part must contain data")
+			}
+			file := map[string]any{"file_data": part.DataURL()}
+			if part.FileName != "" {
+				file["filename"] = part.FileName
+			}
+			result = append(result, map[string]any{"type": "file", "file": file})
+
+		default:
+			return nil, fmt.Errorf("unsupported content part type: %s", part.Type)
+		}
+	}
+
+	return result, nil
+}
+
+// PartsToAnthropic 将多模态内容转换为 Anthropic 的 content 数组。
+//
+// 转换规则：
+//   - 文本：{"type": "text"}
+//   - 图像：{"type": "image"}，source 为 url 或 base64
+//   - 文件：{"type": "document"}，文本文件使用 text source，其他（如 PDF）使用 url 或 base64
+//   - 带缓存断点的内容添加 cache_control
+//
+// 参数：
+//   - parts: 多模态内容
+//
+// 返回：
+//   - []map[string]any: Anthropic 格式的 content 数组
+//   - error: 不支持的内容（Anthropic 不支持音频和视频）
+//
+func PartsToAnthropic(parts []*types.MultimodalContent) ([]map[string]any, error) {
+	result := make([]map[string]any, 0, len(parts))
+
+	for _, part := range parts {
+		switch part.Type {
+		case types.ContentTypeText:
+			result = append(result, map[string]any{"type": "text", "text": part.Text})
+
+		case types.ContentTypeImage, types.ContentTypeFile:
+			blockType := "image"
+			if part.Type == types.ContentTypeFile {
+				blockType = "document"
+			}
+
+			var source map[string]any
+			switch data, mediaType := part.Data(), part.MediaType(); {
+			case len(data) > 0 && part.Type == types.ContentTypeFile && strings.HasPrefix(mediaType, "text/"):
+				source = map[string]any{"type": "text", "media_type": "text/plain", "data": string(data)}
+			case len(data) > 0:
+				if mediaType == "" {
+					return nil, fmt.Errorf("%s part requires a media type", part.Type)
+				}
+				source = map[string]any{
+					"type":       "base64",
+					"media_type": mediaType,
+					"data":       base64.StdEncoding.EncodeToString(data),
+				}
+			case part.URL() != "":
+				source = map[string]any{"type": "url", "url": part.URL()}
+			default:
+				return nil, fmt.Errorf("%s part has neither url nor data", part.Type)
+			}
+
+			block := map[string]any{"type": blockType, "source": source}
+			if blockType == "document" && part.FileName != "" {
+				block["title"] = part.FileName
+			}
+			result = append(result, block)
+
+		default:
+			return nil, fmt.Errorf("unsupported content part type: %s", part.Type)
+		}
+
+		if part.CacheControl != nil {
+			result[len(result)-1]["cache_control"] = part.CacheControl.ToMap()
+		}
+	}
+
+	return result, nil
+}
+
 // OpenAIResponseToMessage 将 OpenAI 响应转换为 Message。
 //
 // 参数：
@@ -185,6 +382,15 @@ func OpenAIResponseToMessage(response map[string]any) (types.Message, error) {
 		msg.Content = content
 	}
 
+	// 兼容服务（DeepSeek、vLLM 等）在 reasoning_content 中返回思考过程，部分服务使用 reasoning 字段
+	reasoning, _ := response["reasoning_content"].(string)
+	if reasoning == "" {
+		reasoning, _ = response["reasoning"].(string)
+	}
+	if reasoning != "" {
+		msg.ContentBlocks = append(msg.ContentBlocks, types.NewThinkingContentBlock(reasoning))
+	}
+
 	// 提取工具调用
 	if toolCallsRaw, ok := response["tool_calls"]; ok {
 		if toolCallsArray, ok := toolCallsRaw.([]any); ok {
@@ -229,6 +435,8 @@ func OpenAIResponseToMessage(response map[string]any) (types.Message, error) {
 
 // AnthropicResponseToMessage 将 Anthropic 响应转换为 Message。
 //
+// thinking 和 redacted_thinking 块转换为 ContentBlocks 中的思考块（保留签名）。
+//
 // 参数：
 //   - content: Anthropic API 响应的 content 数组
 //
@@ -258,6 +466,16 @@ func AnthropicResponseToMessage(content []any) (types.Message, error) {
 				textParts = append(textParts, text)
 			}
 
+		case "thinking":
+			thinking, _ := block["thinking"].(string)
+			signature, _ := block["signature"].(string)
+			msg.ContentBlocks = append(msg.ContentBlocks,
+				types.NewThinkingContentBlock(thinking).WithSignature(signature))
+
+		case "redacted_thinking":
+			data, _ := block["data"].(string)
+			msg.ContentBlocks = append(msg.ContentBlocks, types.NewRedactedThinkingContentBlock(data))
+
 		case "tool_use":
 			tc := types.ToolCall{
 				Type: "function",
@@ -297,6 +515,36 @@ func AnthropicResponseToMessage(content []any) (types.Message, error) {
 	return msg, nil
 }
 
+// NormalizeFinishReason 将提供商返回的结束原因归一化为 types.FinishReason。
+//
+// 支持 OpenAI（stop、length、tool_calls）、Anthropic（end_turn、max_tokens、tool_use）、
+// Gemini（STOP、MAX_TOKENS、SAFETY）、Bedrock 和 Ollama 的取值，大小写不敏感。
+// 无法识别的原因原样返回。
+//
+// 参数：
+//   - reason: 提供商返回的结束原因
+//
+// 返回：
+//   - types.FinishReason: 归一化的结束原因，reason 为空时返回空
+//
+func NormalizeFinishReason(reason string) types.FinishReason {
+	switch strings.ToLower(reason) {
+	case "":
+		return ""
+	case "stop", "end_turn", "stop_sequence", "finish", "complete", "eos":
+		return types.FinishReasonStop
+	case "length", "max_tokens", "model_length":
+		return types.FinishReasonLength
+	case "tool_calls", "tool_use", "function_call":
+		return types.FinishReasonToolCalls
+	case "content_filter", "content_filtered", "safety", "recitation", "blocklist",
+		"prohibited_content", "spii", "guardrail_intervened", "refusal":
+		return types.FinishReasonContentFilter
+	default:
+		return types.FinishReason(reason)
+	}
+}
+
 // MergeMessages 合并相邻的相同角色的消息。
 //
 // 某些提供商（如 Anthropic）不允许连续的相同角色消息，
@@ -222,6 +222,97 @@ func TestMessagesToAnthropic(t *testing.T) {
 	}
 }
 
+func TestMessagesToOpenAI_Parts(t *testing.T) {
+	image := types.NewImageContentFromData([]byte("png"), types.ImageFormatPNG)
+	image.ImageDetail = "high"
+
+	msg := types.NewMessageWithParts(types.RoleUser,
+		types.NewTextContent("What is this?"),
+		image,
+		types.NewImageContent("https://example.com/a.jpg", types.ImageFormatJPEG),
+		types.NewAudioContentFromData([]byte("wav"), types.AudioFormatWAV),
+		types.NewFileContentFromData([]byte("%PDF"), "application/pdf", "report.pdf"),
+	)
+
+	result, err := MessagesToOpenAI([]types.Message{msg})
+	require.NoError(t, err)
+
+	content, ok := result[0]["content"].([]map[string]any)
+	require.True(t, ok)
+	require.Len(t, content, 5)
+
+	assert.Equal(t, map[string]any{"type": "text", "text": "What is this?"}, content[0])
+	assert.Equal(t, map[string]any{"url": "data:image/png;base64,cG5n", "detail": "high"}, content[1]["image_url"])
+	assert.Equal(t, map[string]any{"url": "https://example.com/a.jpg"}, content[2]["image_url"])
+	assert.Equal(t, map[string]any{"data": "d2F2", "format": "wav"}, content[3]["input_audio"])
+	assert.Equal(t, map[string]any{"file_data": "data:application/pdf;base64,JVBERg==", "filename": "report.pdf"}, content[4]["file"])
+
+	// 音频只支持数据
+	_, err = MessagesToOpenAI([]types.Message{types.NewMessageWithParts(types.RoleUser,
+		types.NewAudioContent("https://example.com/a.mp3", types.AudioFormatMP3))})
+	assert.Error(t, err)
+}
+
+func TestMessagesToAnthropic_Parts(t *testing.T) {
+	msg := types.NewMessageWithParts(types.RoleUser,
+		types.NewImageContentFromData([]byte("png"), types.ImageFormatPNG),
+		types.NewImageContent("https://example.com/a.jpg", types.ImageFormatJPEG),
+		types.NewFileContentFromData([]byte("%PDF"), "application/pdf", "report.pdf"),
+		types.NewFileContentFromData([]byte("hello"), "text/plain", ""),
+		types.NewTextContent("Summarize"),
+	)
+
+	_, result, err := MessagesToAnthropic([]types.Message{msg})
+	require.NoError(t, err)
+
+	content, ok := result[0]["content"].([]map[string]any)
+	require.True(t, ok)
+	require.Len(t, content, 5)
+
+	assert.Equal(t, "image", content[0]["type"])
+	assert.Equal(t, map[string]any{"type": "base64", "media_type": "image/png", "data": "cG5n"}, content[0]["source"])
+	assert.Equal(t, map[string]any{"type": "url", "url": "https://example.com/a.jpg"}, content[1]["source"])
+	assert.Equal(t, "document", content[2]["type"])
+	assert.Equal(t, "report.pdf", content[2]["title"])
+	assert.Equal(t, map[string]any{"type": "base64", "media_type": "application/pdf", "data": "JVBERg=="}, content[2]["source"])
+	assert.Equal(t, map[string]any{"type": "text", "media_type": "text/plain", "data": "hello"}, content[3]["source"])
+	assert.Equal(t, map[string]any{"type": "text", "text": "Summarize"}, content[4])
+
+	// Anthropic 不支持音频
+	_, _, err = MessagesToAnthropic([]types.Message{types.NewMessageWithParts(types.RoleUser,
+		types.NewAudioContentFromData([]byte("wav"), types.AudioFormatWAV))})
+	assert.Error(t, err)
+}
+
+func TestMessagesToAnthropic_Thinking(t *testing.T) {
+	assistant := types.Message{
+		Role: types.RoleAssistant,
+		ToolCalls: []types.ToolCall{
+			{ID: "toolu_1", Type: "function", Function: types.FunctionCall{Name: "search", Arguments: `{"q":"go"}`}},
+		},
+		ContentBlocks: []*types.ContentBlock{
+			types.NewThinkingContentBlock("I should search").WithSignature("sig-1"),
+			types.NewRedactedThinkingContentBlock("encrypted"),
+			// 其他提供商的思考块没有签名，不回传
+			types.NewThinkingContentBlock("unsigned"),
+		},
+	}
+
+	_, result, err := MessagesToAnthropic([]types.Message{
+		types.NewUserMessage("Find Go docs"),
+		assistant,
+		types.NewToolMessage("toolu_1", "found"),
+	})
+	require.NoError(t, err)
+
+	content, ok := result[1]["content"].([]map[string]any)
+	require.True(t, ok)
+	require.Len(t, content, 3)
+	assert.Equal(t, map[string]any{"type": "thinking", "thinking": "I should search", "signature": "sig-1"}, content[0])
+	assert.Equal(t, map[string]any{"type": "redacted_thinking", "data": "encrypted"}, content[1])
+	assert.Equal(t, "tool_use", content[2]["type"])
+}
+
 func TestOpenAIResponseToMessage(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -265,6 +356,18 @@ func TestOpenAIResponseToMessage(t *testing.T) {
 				assert.Equal(t, `{"location":"NYC"}`, msg.ToolCalls[0].Function.Arguments)
 			},
 		},
+		{
+			name: "response with reasoning content",
+			response: map[string]any{
+				"role":              "assistant",
+				"content":           "42",
+				"reasoning_content": "6 times 7",
+			},
+			validate: func(t *testing.T, msg types.Message) {
+				assert.Equal(t, "42", msg.Content)
+				assert.Equal(t, "6 times 7", msg.ThinkingText())
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -343,6 +446,23 @@ func TestAnthropicResponseToMessage(t *testing.T) {
 				assert.Equal(t, "NYC", args["location"])
 			},
 		},
+		{
+			name: "response with thinking",
+			content: []any{
+				map[string]any{"type": "thinking", "thinking": "Let me think", "signature": "sig"},
+				map[string]any{"type": "redacted_thinking", "data": "encrypted"},
+				map[string]any{"type": "text", "text": "Answer"},
+			},
+			validate: func(t *testing.T, msg types.Message) {
+				assert.Equal(t, "Answer", msg.Content)
+				require.Len(t, msg.ContentBlocks, 2)
+				assert.Equal(t, types.ContentBlockThinking, msg.ContentBlocks[0].Type)
+				assert.Equal(t, "Let me think", msg.ContentBlocks[0].Content)
+				assert.Equal(t, "sig", msg.ContentBlocks[0].Signature)
+				assert.True(t, msg.ContentBlocks[1].Redacted)
+				assert.Equal(t, "encrypted", msg.ContentBlocks[1].Signature)
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -458,3 +578,26 @@ func TestExtractSystemMessage(t *testing.T) {
 		})
 	}
 }
+
+func TestNormalizeFinishReason(t *testing.T) {
+	tests := map[string]types.FinishReason{
+		"":                     "",
+		"stop":                 types.FinishReasonStop,
+		"end_turn":             types.FinishReasonStop,
+		"STOP":                 types.FinishReasonStop,
+		"length":               types.FinishReasonLength,
+		"max_tokens":           types.FinishReasonLength,
+		"MAX_TOKENS":           types.FinishReasonLength,
+		"tool_calls":           types.FinishReasonToolCalls,
+		"tool_use":             types.FinishReasonToolCalls,
+		"SAFETY":               types.FinishReasonContentFilter,
+		"guardrail_intervened": types.FinishReasonContentFilter,
+		"pause_turn":           "pause_turn",
+	}
+
+	for reason, want := range tests {
+		if got := NormalizeFinishReason(reason); got != want {
+			t.Errorf("NormalizeFinishReason(%q) = %q, want %q", reason, got, want)
+		}
+	}
+}
@@ -257,3 +257,38 @@ func ConvertToolsToAnthropic(tools []types.Tool) []map[string]any {
 	}
 	return result
 }
+
+// ResolveThinking 返回本次调用生效的扩展思考配置。
+//
+// 通过 runnable.WithThinking 传入的单次调用配置优先于模型配置。
+//
+// 参数：
+//   - defaults: 模型配置中的思考配置，可以为 nil
+//   - opts: 调用选项
+//
+// 返回：
+//   - *types.ThinkingConfig: 生效的配置，未配置时为 nil
+//
+func ResolveThinking(defaults *types.ThinkingConfig, opts ...runnable.Option) *types.ThinkingConfig {
+	return ResolveOptions(defaults, opts...).Thinking
+}
+
+// ResolveOptions 解析本次调用的选项。
+//
+// 未通过 runnable.WithThinking 设置思考配置时使用模型配置，其他生成参数
+// （温度、停止序列、工具选择等）未设置时为零值，由提供商回退到模型配置。
+//
+// 参数：
+//   - thinking: 模型配置中的思考配置，可以为 nil
+//   - opts: 调用选项
+//
+// 返回：
+//   - *runnable.Options: 本次调用生效的选项
+//
+func ResolveOptions(thinking *types.ThinkingConfig, opts ...runnable.Option) *runnable.Options {
+	options := runnable.NewOptions(opts...)
+	if options.Thinking == nil {
+		options.Thinking = thinking
+	}
+	return options
+}
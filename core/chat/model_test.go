@@ -6,6 +6,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/zhucl121/langchain-go/core/runnable"
 	"github.com/zhucl121/langchain-go/pkg/types"
 )
 
@@ -174,3 +175,18 @@ func TestBaseChatModel_Batch(t *testing.T) {
 	// 这个测试应该在具体的实现类中进行
 	t.Skip("BaseChatModel.Batch requires concrete implementation")
 }
+
+func TestResolveOptions(t *testing.T) {
+	defaults := types.NewThinkingConfig(1024)
+
+	options := ResolveOptions(defaults, runnable.WithTemperature(0), runnable.WithStop("END"))
+	assert.Same(t, defaults, options.Thinking)
+	require.NotNil(t, options.Temperature)
+	assert.Equal(t, 0.0, *options.Temperature)
+	assert.Equal(t, []string{"END"}, options.Stop)
+	assert.Nil(t, options.ToolChoice)
+
+	override := types.NewReasoningConfig(types.ReasoningEffortHigh)
+	assert.Same(t, override, ResolveOptions(defaults, runnable.WithThinking(override)).Thinking)
+	assert.Same(t, override, ResolveThinking(defaults, runnable.WithThinking(override)))
+}
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/zhucl121/langchain-go/core/chat"
+	chaterrors "github.com/zhucl121/langchain-go/core/chat/errors"
 	"github.com/zhucl121/langchain-go/core/runnable"
 	"github.com/zhucl121/langchain-go/pkg/types"
 )
@@ -63,6 +64,19 @@ type Config struct {
 
 	// Timeout 是请求超时时间（可选，默认 60 秒）
 	Timeout time.Duration
+
+	// Thinking 是扩展思考配置（可选），可通过 runnable.WithThinking 按调用覆盖
+	//
+	// 启用后请求不再发送 Temperature 和 TopK（Anthropic 不支持二者与扩展思考同时使用），
+	// MaxTokens 不大于思考预算时自动加上预算。
+	Thinking *types.ThinkingConfig
+
+	// PromptCaching 是否自动放置提示词缓存断点（可选）
+	//
+	// 启用后在系统提示词和工具定义上放置缓存断点（见 chat.ApplyPromptCaching），
+	// 也可以通过 chat.WithPromptCaching 按上下文开启。消息、多模态内容和工具上
+	// 显式设置的 CacheControl 始终生效。
+	PromptCaching bool
 }
 
 // Validate 验证配置的有效性。
@@ -138,13 +152,20 @@ func New(config Config) (*ChatModel, error) {
 
 // Invoke 实现 Runnable 接口，执行单次调用。
 func (m *ChatModel) Invoke(ctx context.Context, messages []types.Message, opts ...runnable.Option) (types.Message, error) {
+	return chat.TraceInvoke(ctx, m, messages, opts, func(ctx context.Context) (types.Message, error) {
+		return m.invoke(ctx, messages, opts...)
+	})
+}
+
+// invoke 执行单次调用。
+func (m *ChatModel) invoke(ctx context.Context, messages []types.Message, opts ...runnable.Option) (types.Message, error) {
 	// 验证消息
 	if err := chat.ValidateMessages(messages); err != nil {
 		return types.Message{}, err
 	}
 
 	// 构建请求
-	reqBody, err := m.buildRequest(messages, false)
+	reqBody, err := m.buildRequest(messages, false, chat.ResolveOptions(m.config.Thinking, opts...), m.promptCaching(ctx))
 	if err != nil {
 		return types.Message{}, fmt.Errorf("failed to build request: %w", err)
 	}
@@ -167,18 +188,28 @@ func (m *ChatModel) Invoke(ctx context.Context, messages []types.Message, opts .
 		return types.Message{}, fmt.Errorf("failed to convert response: %w", err)
 	}
 
+	message.FinishReason = chat.NormalizeFinishReason(response.StopReason)
+	message.UsageMetadata = response.Usage.toUsageMetadata()
+
 	return message, nil
 }
 
 // Stream 实现 Runnable 接口，执行流式调用。
 func (m *ChatModel) Stream(ctx context.Context, messages []types.Message, opts ...runnable.Option) (<-chan runnable.StreamEvent[types.Message], error) {
+	return chat.TraceStream(ctx, m, messages, opts, func(ctx context.Context) (<-chan runnable.StreamEvent[types.Message], error) {
+		return m.stream(ctx, messages, opts...)
+	})
+}
+
+// stream 执行流式调用。
+func (m *ChatModel) stream(ctx context.Context, messages []types.Message, opts ...runnable.Option) (<-chan runnable.StreamEvent[types.Message], error) {
 	// 验证消息
 	if err := chat.ValidateMessages(messages); err != nil {
 		return nil, err
 	}
 
 	// 构建请求
-	reqBody, err := m.buildRequest(messages, true)
+	reqBody, err := m.buildRequest(messages, true, chat.ResolveOptions(m.config.Thinking, opts...), m.promptCaching(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("failed to build request: %w", err)
 	}
@@ -217,7 +248,8 @@ func (m *ChatModel) Stream(ctx context.Context, messages []types.Message, opts .
 		}
 	}()
 
-	return out, nil
+	// 在图等外层执行器中运行时，把 token 事件转发给外层
+	return runnable.ForwardStream(ctx, out), nil
 }
 
 // BindTools 实现 ChatModel 接口，绑定工具。
@@ -325,42 +357,92 @@ func (m *ChatModel) WithFallbacks(fallbacks ...runnable.Runnable[[]types.Message
 	return runnable.NewFallbackRunnable[[]types.Message, types.Message](m, fallbacks)
 }
 
+// minThinkingBudget 是 Anthropic 要求的最小思考预算
+const minThinkingBudget = 1024
+
+// promptCaching 返回本次调用是否自动放置提示词缓存断点。
+func (m *ChatModel) promptCaching(ctx context.Context) bool {
+	return m.config.PromptCaching || chat.PromptCachingFromContext(ctx)
+}
+
 // buildRequest 构建 Anthropic API 请求体。
-func (m *ChatModel) buildRequest(messages []types.Message, stream bool) ([]byte, error) {
+//
+// options 中设置的单次调用参数优先于模型配置；Anthropic 不支持随机种子和响应格式，
+// options 中的这两个参数会被忽略。
+func (m *ChatModel) buildRequest(messages []types.Message, stream bool, options *runnable.Options, promptCaching bool) ([]byte, error) {
+	// 自动缓存系统提示词和工具定义
+	tools := m.GetBoundTools()
+	if promptCaching {
+		messages, tools = chat.ApplyPromptCaching(messages, tools)
+	}
+
 	// 转换消息格式（Anthropic 要求提取系统消息）
 	systemMessage, anthropicMessages, err := chat.MessagesToAnthropic(messages)
 	if err != nil {
 		return nil, err
 	}
 
+	maxTokens := m.config.MaxTokens
+	if options.MaxTokens > 0 {
+		maxTokens = options.MaxTokens
+	}
+
 	// 构建请求
 	request := map[string]any{
 		"model":      m.config.Model,
 		"messages":   anthropicMessages,
-		"max_tokens": m.config.MaxTokens,
+		"max_tokens": maxTokens,
 		"stream":     stream,
 	}
 
-	// 添加系统消息
-	if systemMessage != "" {
+	// 添加系统消息（带缓存断点时使用文本块数组）
+	if chat.HasSystemCacheControl(messages) {
+		request["system"] = chat.SystemToAnthropic(messages)
+	} else if systemMessage != "" {
 		request["system"] = systemMessage
 	}
 
+	// 添加扩展思考
+	thinking := options.Thinking
+	thinkingEnabled := thinking != nil && thinking.Enabled
+	if thinkingEnabled {
+		budget := max(thinking.Budget(), minThinkingBudget)
+		request["thinking"] = map[string]any{
+			"type":          "enabled",
+			"budget_tokens": budget,
+		}
+		if maxTokens <= budget {
+			request["max_tokens"] = maxTokens + budget
+		}
+	}
+
 	// 添加可选参数
-	if m.config.Temperature > 0 && m.config.Temperature != 1.0 {
+	if !thinkingEnabled && options.Temperature != nil {
+		request["temperature"] = *options.Temperature
+	} else if !thinkingEnabled && m.config.Temperature > 0 && m.config.Temperature != 1.0 {
 		request["temperature"] = m.config.Temperature
 	}
 	if m.config.TopP > 0 {
 		request["top_p"] = m.config.TopP
 	}
-	if m.config.TopK > 0 {
+	if !thinkingEnabled && m.config.TopK > 0 {
 		request["top_k"] = m.config.TopK
 	}
 
+	if len(options.Stop) > 0 {
+		request["stop_sequences"] = options.Stop
+	}
+
 	// 添加工具
-	tools := m.GetBoundTools()
 	if len(tools) > 0 {
 		request["tools"] = chat.ConvertToolsToAnthropic(tools)
+
+		// 只设置并行工具调用时使用 auto 模式
+		if options.ToolChoice != nil {
+			request["tool_choice"] = options.ToolChoice.ToAnthropic(options.ParallelToolCalls)
+		} else if options.ParallelToolCalls != nil {
+			request["tool_choice"] = types.NewToolChoice(types.ToolChoiceAuto).ToAnthropic(options.ParallelToolCalls)
+		}
 	}
 
 	return json.Marshal(request)
@@ -382,7 +464,7 @@ func (m *ChatModel) doRequest(ctx context.Context, body []byte) ([]byte, error)
 	// 发送请求
 	resp, err := m.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return nil, chaterrors.FromTransport(m.GetProvider(), fmt.Errorf("failed to send request: %w", err))
 	}
 	defer resp.Body.Close()
 
@@ -394,7 +476,7 @@ func (m *ChatModel) doRequest(ctx context.Context, body []byte) ([]byte, error)
 
 	// 检查状态码
 	if resp.StatusCode != http.StatusOK {
-		return nil, m.parseError(resp.StatusCode, respBody)
+		return nil, m.parseError(resp, respBody)
 	}
 
 	return respBody, nil
@@ -417,14 +499,14 @@ func (m *ChatModel) doStreamRequest(ctx context.Context, body []byte) (*http.Res
 	// 发送请求
 	resp, err := m.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return nil, chaterrors.FromTransport(m.GetProvider(), fmt.Errorf("failed to send request: %w", err))
 	}
 
 	// 检查状态码
 	if resp.StatusCode != http.StatusOK {
 		defer resp.Body.Close()
 		respBody, _ := io.ReadAll(resp.Body)
-		return nil, m.parseError(resp.StatusCode, respBody)
+		return nil, m.parseError(resp, respBody)
 	}
 
 	return resp, nil
@@ -437,6 +519,12 @@ func (m *ChatModel) processStream(reader io.Reader, out chan<- runnable.StreamEv
 	fullMessage.Role = types.RoleAssistant
 
 	var currentToolCalls []types.ToolCall
+	var thinkingBlocks []*types.ContentBlock
+	var streamUsage usage
+
+	// 内容块索引到工具调用和思考块位置的映射
+	toolIndex := make(map[int]int)
+	thinkingIndex := make(map[int]int)
 
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -468,7 +556,12 @@ func (m *ChatModel) processStream(reader io.Reader, out chan<- runnable.StreamEv
 		// 根据事件类型处理
 		switch eventType {
 		case "message_start":
-			// 消息开始，暂不处理
+			// 消息开始，携带输入 token 用量
+			var event messageStartEvent
+			if err := json.Unmarshal([]byte(data), &event); err != nil {
+				continue
+			}
+			streamUsage = event.Message.Usage
 
 		case "content_block_start":
 			// 内容块开始
@@ -477,8 +570,9 @@ func (m *ChatModel) processStream(reader io.Reader, out chan<- runnable.StreamEv
 				continue
 			}
 
-			// 如果是工具使用块，初始化
-			if event.ContentBlock.Type == "tool_use" {
+			switch event.ContentBlock.Type {
+			case "tool_use":
+				// 工具使用块，初始化
 				tc := types.ToolCall{
 					Type: "function",
 					ID:   event.ContentBlock.ID,
@@ -487,7 +581,17 @@ func (m *ChatModel) processStream(reader io.Reader, out chan<- runnable.StreamEv
 						Arguments: "",
 					},
 				}
+				toolIndex[event.Index] = len(currentToolCalls)
 				currentToolCalls = append(currentToolCalls, tc)
+
+			case "thinking":
+				// 思考块，内容和签名通过增量到达
+				thinkingIndex[event.Index] = len(thinkingBlocks)
+				thinkingBlocks = append(thinkingBlocks, types.NewThinkingContentBlock(""))
+
+			case "redacted_thinking":
+				// 被加密隐藏的思考块，数据在开始事件中完整给出
+				thinkingBlocks = append(thinkingBlocks, types.NewRedactedThinkingContentBlock(event.ContentBlock.Data))
 			}
 
 		case "content_block_delta":
@@ -497,7 +601,8 @@ func (m *ChatModel) processStream(reader io.Reader, out chan<- runnable.StreamEv
 				continue
 			}
 
-			if event.Delta.Type == "text_delta" {
+			switch event.Delta.Type {
+			case "text_delta":
 				// 文本内容
 				fullMessage.Content += event.Delta.Text
 
@@ -510,10 +615,31 @@ func (m *ChatModel) processStream(reader io.Reader, out chan<- runnable.StreamEv
 					},
 					Name: m.GetName(),
 				}
-			} else if event.Delta.Type == "input_json_delta" {
+
+			case "thinking_delta":
+				// 思考内容增量，以思考块发送，不计入 Content
+				if i, ok := thinkingIndex[event.Index]; ok {
+					thinkingBlocks[i].Content += event.Delta.Thinking
+				}
+				out <- runnable.StreamEvent[types.Message]{
+					Type: runnable.EventStream,
+					Data: types.Message{
+						Role:          types.RoleAssistant,
+						ContentBlocks: []*types.ContentBlock{types.NewThinkingContentBlock(event.Delta.Thinking)},
+					},
+					Name: m.GetName(),
+				}
+
+			case "signature_delta":
+				// 思考块签名
+				if i, ok := thinkingIndex[event.Index]; ok {
+					thinkingBlocks[i].Signature += event.Delta.Signature
+				}
+
+			case "input_json_delta":
 				// 工具参数增量
-				if event.Index < len(currentToolCalls) {
-					currentToolCalls[event.Index].Function.Arguments += event.Delta.PartialJSON
+				if i, ok := toolIndex[event.Index]; ok {
+					currentToolCalls[i].Function.Arguments += event.Delta.PartialJSON
 				}
 			}
 
@@ -521,7 +647,13 @@ func (m *ChatModel) processStream(reader io.Reader, out chan<- runnable.StreamEv
 			// 内容块结束，暂不处理
 
 		case "message_delta":
-			// 消息级别的增量更新，暂不处理
+			// 消息级别的增量更新：结束原因和累计输出 token 数
+			var event messageDeltaEvent
+			if err := json.Unmarshal([]byte(data), &event); err != nil {
+				continue
+			}
+			fullMessage.FinishReason = chat.NormalizeFinishReason(event.Delta.StopReason)
+			streamUsage.OutputTokens = event.Usage.OutputTokens
 
 		case "message_stop":
 			// 消息结束
@@ -529,6 +661,8 @@ func (m *ChatModel) processStream(reader io.Reader, out chan<- runnable.StreamEv
 			if len(currentToolCalls) > 0 {
 				fullMessage.ToolCalls = currentToolCalls
 			}
+			fullMessage.ContentBlocks = thinkingBlocks
+			fullMessage.UsageMetadata = streamUsage.toUsageMetadata()
 
 			// 发送结束事件
 			out <- runnable.StreamEvent[types.Message]{
@@ -539,12 +673,8 @@ func (m *ChatModel) processStream(reader io.Reader, out chan<- runnable.StreamEv
 			return nil
 
 		case "error":
-			// 错误事件
-			var errEvent errorEvent
-			if err := json.Unmarshal([]byte(data), &errEvent); err != nil {
-				return fmt.Errorf("stream error")
-			}
-			return fmt.Errorf("stream error: %s", errEvent.Error.Message)
+			// 错误事件（如 overloaded_error）
+			return chaterrors.FromBody(m.GetProvider(), 0, []byte(data))
 		}
 	}
 
@@ -555,14 +685,9 @@ func (m *ChatModel) processStream(reader io.Reader, out chan<- runnable.StreamEv
 	return nil
 }
 
-// parseError 解析错误响应。
-func (m *ChatModel) parseError(statusCode int, body []byte) error {
-	var errResp errorResponse
-	if err := json.Unmarshal(body, &errResp); err != nil {
-		return fmt.Errorf("HTTP %d: %s", statusCode, string(body))
-	}
-
-	return fmt.Errorf("Anthropic API error (HTTP %d): %s", statusCode, errResp.Error.Message)
+// parseError 把错误响应转换为类型化错误（见 chat/errors）。
+func (m *ChatModel) parseError(resp *http.Response, body []byte) error {
+	return chaterrors.FromResponse(m.GetProvider(), resp, body)
 }
 
 // anthropicResponse 是 Anthropic API 的响应结构。
@@ -574,16 +699,51 @@ type anthropicResponse struct {
 	Model        string  `json:"model"`
 	StopReason   string  `json:"stop_reason"`
 	StopSequence *string `json:"stop_sequence"`
-	Usage        usage   `json:"usage"`
+	Usage        *usage  `json:"usage"`
 }
 
+// usage 是 Anthropic 的 token 用量。
+//
+// input_tokens 不包含缓存读取和写入的 token。
 type usage struct {
-	InputTokens  int `json:"input_tokens"`
-	OutputTokens int `json:"output_tokens"`
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+}
+
+// toUsageMetadata 转换为标准用量（InputTokens 包含缓存 token），u 为 nil 时返回 nil。
+func (u *usage) toUsageMetadata() *types.UsageMetadata {
+	if u == nil {
+		return nil
+	}
+	input := u.InputTokens + u.CacheCreationInputTokens + u.CacheReadInputTokens
+	return &types.UsageMetadata{
+		InputTokens:         input,
+		OutputTokens:        u.OutputTokens,
+		TotalTokens:         input + u.OutputTokens,
+		CachedTokens:        u.CacheReadInputTokens,
+		CacheCreationTokens: u.CacheCreationInputTokens,
+	}
 }
 
 // 流式事件结构
 
+type messageStartEvent struct {
+	Message struct {
+		Usage usage `json:"usage"`
+	} `json:"message"`
+}
+
+type messageDeltaEvent struct {
+	Delta struct {
+		StopReason string `json:"stop_reason"`
+	} `json:"delta"`
+	Usage struct {
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+}
+
 type contentBlockStartEvent struct {
 	Index        int          `json:"index"`
 	ContentBlock contentBlock `json:"content_block"`
@@ -593,6 +753,7 @@ type contentBlock struct {
 	Type string `json:"type"`
 	ID   string `json:"id,omitempty"`
 	Name string `json:"name,omitempty"`
+	Data string `json:"data,omitempty"`
 }
 
 type contentBlockDeltaEvent struct {
@@ -604,20 +765,6 @@ type delta struct {
 	Type        string `json:"type"`
 	Text        string `json:"text,omitempty"`
 	PartialJSON string `json:"partial_json,omitempty"`
-}
-
-type errorEvent struct {
-	Error struct {
-		Type    string `json:"type"`
-		Message string `json:"message"`
-	} `json:"error"`
-}
-
-// errorResponse 是 Anthropic API 的错误响应。
-type errorResponse struct {
-	Type  string `json:"type"`
-	Error struct {
-		Type    string `json:"type"`
-		Message string `json:"message"`
-	} `json:"error"`
+	Thinking    string `json:"thinking,omitempty"`
+	Signature   string `json:"signature,omitempty"`
 }
@@ -1,12 +1,20 @@
 package anthropic
 
 import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/zhucl121/langchain-go/core/chat"
+	chaterrors "github.com/zhucl121/langchain-go/core/chat/errors"
+	"github.com/zhucl121/langchain-go/core/runnable"
 	"github.com/zhucl121/langchain-go/pkg/types"
 )
 
@@ -281,3 +289,290 @@ func TestChatModel_buildRequest(t *testing.T) {
 //     assert.NotEmpty(t, response.Content)
 //     assert.Contains(t, strings.ToLower(response.Content), "hello")
 // }
+
+func TestChatModel_Invoke_Usage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{
+			"id": "msg_1", "type": "message", "role": "assistant",
+			"content": [{"type": "text", "text": "Hi"}],
+			"stop_reason": "end_turn",
+			"usage": {"input_tokens": 20, "output_tokens": 5, "cache_creation_input_tokens": 10, "cache_read_input_tokens": 100}
+		}`)
+	}))
+	defer server.Close()
+
+	model, err := New(Config{APIKey: "test-key", BaseURL: server.URL, MaxTokens: 100})
+	require.NoError(t, err)
+
+	response, err := model.Invoke(context.Background(), []types.Message{types.NewUserMessage("Hello")})
+	require.NoError(t, err)
+
+	assert.Equal(t, types.FinishReasonStop, response.FinishReason)
+	require.NotNil(t, response.UsageMetadata)
+	assert.Equal(t, types.UsageMetadata{
+		InputTokens:         130,
+		OutputTokens:        5,
+		TotalTokens:         135,
+		CachedTokens:        100,
+		CacheCreationTokens: 10,
+	}, *response.UsageMetadata)
+}
+
+func TestChatModel_Invoke_CallOptions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, float64(0), req["temperature"])
+		assert.Equal(t, float64(64), req["max_tokens"])
+		assert.Equal(t, []any{"END"}, req["stop_sequences"])
+		assert.Equal(t, map[string]any{"type": "any", "disable_parallel_tool_use": true}, req["tool_choice"])
+		assert.NotContains(t, req, "seed")
+
+		io.WriteString(w, `{
+			"id": "msg_1", "type": "message", "role": "assistant",
+			"content": [{"type": "text", "text": "Hi"}],
+			"stop_reason": "end_turn",
+			"usage": {"input_tokens": 5, "output_tokens": 1}
+		}`)
+	}))
+	defer server.Close()
+
+	model, err := New(Config{APIKey: "test-key", BaseURL: server.URL, MaxTokens: 100, Temperature: 0.5})
+	require.NoError(t, err)
+	withTools := model.BindTools([]types.Tool{{Name: "search", Parameters: types.Schema{Type: "object"}}})
+
+	_, err = withTools.Invoke(context.Background(), []types.Message{types.NewUserMessage("Hello")},
+		runnable.WithTemperature(0),
+		runnable.WithMaxTokens(64),
+		runnable.WithStop("END"),
+		runnable.WithSeed(7),
+		runnable.WithToolChoice(types.NewToolChoice(types.ToolChoiceRequired)),
+		runnable.WithParallelToolCalls(false))
+	require.NoError(t, err)
+}
+
+func TestChatModel_Invoke_PromptCaching(t *testing.T) {
+	var req map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req = nil
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		io.WriteString(w, `{
+			"id": "msg_1", "type": "message", "role": "assistant",
+			"content": [{"type": "text", "text": "Hi"}],
+			"stop_reason": "end_turn",
+			"usage": {"input_tokens": 5, "output_tokens": 1}
+		}`)
+	}))
+	defer server.Close()
+
+	model, err := New(Config{APIKey: "test-key", BaseURL: server.URL, MaxTokens: 100})
+	require.NoError(t, err)
+	withTools := model.BindTools([]types.Tool{
+		{Name: "search", Parameters: types.Schema{Type: "object"}},
+		{Name: "calculator", Parameters: types.Schema{Type: "object"}},
+	})
+
+	messages := []types.Message{
+		types.NewSystemMessage("You are a helpful assistant."),
+		types.NewUserMessage("Hello"),
+	}
+	ephemeral := map[string]any{"type": "ephemeral"}
+
+	t.Run("disabled", func(t *testing.T) {
+		_, err := withTools.Invoke(context.Background(), messages)
+		require.NoError(t, err)
+
+		assert.Equal(t, "You are a helpful assistant.", req["system"])
+		for _, tool := range req["tools"].([]any) {
+			assert.NotContains(t, tool, "cache_control")
+		}
+	})
+
+	t.Run("automatic", func(t *testing.T) {
+		_, err := withTools.Invoke(chat.WithPromptCaching(context.Background()), messages)
+		require.NoError(t, err)
+
+		assert.Equal(t, []any{
+			map[string]any{"type": "text", "text": "You are a helpful assistant.", "cache_control": ephemeral},
+		}, req["system"])
+		tools := req["tools"].([]any)
+		assert.NotContains(t, tools[0], "cache_control")
+		assert.Equal(t, ephemeral, tools[1].(map[string]any)["cache_control"])
+	})
+
+	t.Run("explicit message breakpoint", func(t *testing.T) {
+		_, err := model.Invoke(context.Background(), []types.Message{
+			types.NewUserMessage("long document").WithCacheControl(types.NewCacheControl()),
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, []any{
+			map[string]any{"type": "text", "text": "long document", "cache_control": ephemeral},
+		}, req["messages"].([]any)[0].(map[string]any)["content"])
+		assert.NotContains(t, req, "system")
+	})
+}
+
+func TestChatModel_Stream_Usage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, event := range []struct{ name, data string }{
+			{"message_start", `{"type":"message_start","message":{"usage":{"input_tokens":12,"output_tokens":1}}}`},
+			{"content_block_start", `{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`},
+			{"content_block_delta", `{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Hi"}}`},
+			{"content_block_stop", `{"type":"content_block_stop","index":0}`},
+			{"message_delta", `{"type":"message_delta","delta":{"stop_reason":"max_tokens"},"usage":{"output_tokens":7}}`},
+			{"message_stop", `{"type":"message_stop"}`},
+		} {
+			io.WriteString(w, "event: "+event.name+"\ndata: "+event.data+"\n\n")
+		}
+	}))
+	defer server.Close()
+
+	model, err := New(Config{APIKey: "test-key", BaseURL: server.URL, MaxTokens: 100})
+	require.NoError(t, err)
+
+	stream, err := model.Stream(context.Background(), []types.Message{types.NewUserMessage("Hello")})
+	require.NoError(t, err)
+
+	var final types.Message
+	for event := range stream {
+		require.NoError(t, event.Error)
+		if event.Type == runnable.EventEnd {
+			final = event.Data
+		}
+	}
+
+	assert.Equal(t, "Hi", final.Content)
+	assert.Equal(t, types.FinishReasonLength, final.FinishReason)
+	require.NotNil(t, final.UsageMetadata)
+	assert.Equal(t, 12, final.UsageMetadata.InputTokens)
+	assert.Equal(t, 7, final.UsageMetadata.OutputTokens)
+	assert.Equal(t, 19, final.UsageMetadata.TotalTokens)
+}
+
+func TestChatModel_Invoke_Thinking(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, map[string]any{"type": "enabled", "budget_tokens": float64(2048)}, req["thinking"])
+		// MaxTokens 不大于预算时加上预算，且不发送 temperature
+		assert.Equal(t, float64(2148), req["max_tokens"])
+		assert.NotContains(t, req, "temperature")
+
+		io.WriteString(w, `{
+			"id": "msg_1", "type": "message", "role": "assistant",
+			"content": [
+				{"type": "thinking", "thinking": "The user greets me.", "signature": "sig-1"},
+				{"type": "text", "text": "Hi"}
+			],
+			"stop_reason": "end_turn",
+			"usage": {"input_tokens": 10, "output_tokens": 20}
+		}`)
+	}))
+	defer server.Close()
+
+	model, err := New(Config{APIKey: "test-key", BaseURL: server.URL, MaxTokens: 100, Temperature: 0.5})
+	require.NoError(t, err)
+
+	response, err := model.Invoke(context.Background(), []types.Message{types.NewUserMessage("Hello")},
+		runnable.WithThinking(types.NewThinkingConfig(2048)))
+	require.NoError(t, err)
+
+	assert.Equal(t, "Hi", response.Content)
+	require.Len(t, response.ContentBlocks, 1)
+	assert.Equal(t, "The user greets me.", response.ContentBlocks[0].Content)
+	assert.Equal(t, "sig-1", response.ContentBlocks[0].Signature)
+}
+
+func TestChatModel_Stream_Thinking(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Contains(t, req, "thinking")
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, event := range []struct{ name, data string }{
+			{"message_start", `{"type":"message_start","message":{"usage":{"input_tokens":12,"output_tokens":1}}}`},
+			{"content_block_start", `{"type":"content_block_start","index":0,"content_block":{"type":"thinking","thinking":""}}`},
+			{"content_block_delta", `{"type":"content_block_delta","index":0,"delta":{"type":"thinking_delta","thinking":"Need the "}}`},
+			{"content_block_delta", `{"type":"content_block_delta","index":0,"delta":{"type":"thinking_delta","thinking":"weather."}}`},
+			{"content_block_delta", `{"type":"content_block_delta","index":0,"delta":{"type":"signature_delta","signature":"sig-1"}}`},
+			{"content_block_stop", `{"type":"content_block_stop","index":0}`},
+			{"content_block_start", `{"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"toolu_1","name":"get_weather","input":{}}}`},
+			{"content_block_delta", `{"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"{\"city\":\"Oslo\"}"}}`},
+			{"content_block_stop", `{"type":"content_block_stop","index":1}`},
+			{"message_delta", `{"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":30}}`},
+			{"message_stop", `{"type":"message_stop"}`},
+		} {
+			io.WriteString(w, "event: "+event.name+"\ndata: "+event.data+"\n\n")
+		}
+	}))
+	defer server.Close()
+
+	model, err := New(Config{APIKey: "test-key", BaseURL: server.URL, MaxTokens: 100, Thinking: types.NewThinkingConfig(1024)})
+	require.NoError(t, err)
+
+	stream, err := model.Stream(context.Background(), []types.Message{types.NewUserMessage("Weather in Oslo?")})
+	require.NoError(t, err)
+
+	var thinking, content string
+	var final types.Message
+	for event := range stream {
+		require.NoError(t, event.Error)
+		switch event.Type {
+		case runnable.EventStream:
+			content += event.Data.Content
+			thinking += event.Data.ThinkingText()
+		case runnable.EventEnd:
+			final = event.Data
+		}
+	}
+
+	assert.Equal(t, "Need the weather.", thinking)
+	assert.Empty(t, content)
+	assert.Empty(t, final.Content)
+	require.Len(t, final.ContentBlocks, 1)
+	assert.Equal(t, "Need the weather.", final.ContentBlocks[0].Content)
+	assert.Equal(t, "sig-1", final.ContentBlocks[0].Signature)
+	require.Len(t, final.ToolCalls, 1)
+	assert.Equal(t, `{"city":"Oslo"}`, final.ToolCalls[0].Function.Arguments)
+	assert.Equal(t, types.FinishReasonToolCalls, final.FinishReason)
+}
+
+func TestChatModel_TypedErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-api-key") != "test-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			io.WriteString(w, `{"type":"error","error":{"type":"authentication_error","message":"invalid x-api-key"}}`)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, "event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"usage\":{\"input_tokens\":5}}}\n\n")
+		io.WriteString(w, "event: error\ndata: {\"type\":\"error\",\"error\":{\"type\":\"overloaded_error\",\"message\":\"Overloaded\"}}\n\n")
+	}))
+	defer server.Close()
+
+	badKey, err := New(Config{APIKey: "wrong", BaseURL: server.URL, MaxTokens: 100})
+	require.NoError(t, err)
+	_, err = badKey.Invoke(context.Background(), []types.Message{types.NewUserMessage("Hello")})
+	assert.ErrorIs(t, err, chaterrors.ErrAuthentication)
+	assert.False(t, runnable.ClassifyError(err).Retryable())
+
+	model, err := New(Config{APIKey: "test-key", BaseURL: server.URL, MaxTokens: 100})
+	require.NoError(t, err)
+	stream, err := model.Stream(context.Background(), []types.Message{types.NewUserMessage("Hello")})
+	require.NoError(t, err)
+
+	var streamErr error
+	for event := range stream {
+		if event.Type == runnable.EventError {
+			streamErr = event.Error
+		}
+	}
+
+	var serverErr *chaterrors.ServerError
+	require.ErrorAs(t, streamErr, &serverErr)
+	assert.Equal(t, "overloaded_error", serverErr.Code)
+	assert.True(t, runnable.ClassifyError(streamErr).Retryable())
+}
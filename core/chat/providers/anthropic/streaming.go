@@ -7,6 +7,8 @@ import (
 	"io"
 	"strings"
 
+	"github.com/zhucl121/langchain-go/core/chat"
+	chaterrors "github.com/zhucl121/langchain-go/core/chat/errors"
 	"github.com/zhucl121/langchain-go/pkg/types"
 )
 
@@ -22,7 +24,7 @@ import (
 //
 func (m *ChatModel) StreamTokens(ctx context.Context, messages []types.Message) (<-chan types.StreamEvent, error) {
 	// 构建请求体
-	reqBody, err := m.buildRequest(messages, true)
+	reqBody, err := m.buildRequest(messages, true, chat.ResolveOptions(m.config.Thinking), m.promptCaching(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -165,7 +167,10 @@ func (m *ChatModel) processTokenStream(reader io.Reader, out chan<- types.Stream
 
 		case "error":
 			// 错误
-			return event.Error
+			if event.Error == nil {
+				return chaterrors.New(m.GetProvider(), 0, "", "stream error")
+			}
+			return chaterrors.New(m.GetProvider(), 0, event.Error.Type, event.Error.Message)
 		}
 	}
 
@@ -293,7 +298,3 @@ type anthropicError struct {
 	Type    string `json:"type"`
 	Message string `json:"message"`
 }
-
-func (e *anthropicError) Error() string {
-	return e.Message
-}
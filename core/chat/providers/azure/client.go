@@ -1,6 +1,7 @@
 package azure
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -10,12 +11,17 @@ import (
 	"strings"
 	"time"
 
+	"github.com/zhucl121/langchain-go/core/chat"
+	chaterrors "github.com/zhucl121/langchain-go/core/chat/errors"
+	"github.com/zhucl121/langchain-go/core/runnable"
 	"github.com/zhucl121/langchain-go/pkg/types"
 )
 
 // AzureOpenAIClient 实现 Azure OpenAI Service 集成
 //
 // Azure OpenAI Service 是 Microsoft Azure 上的 OpenAI 模型托管服务。
+// AzureOpenAIClient 实现了 chat.ChatModel 接口，可以用于 Agent、Chain、
+// StateGraph 以及 WithFallbacks 等任何接受 ChatModel 的地方。
 //
 // 支持的模型:
 //   - GPT-3.5-Turbo
@@ -35,6 +41,7 @@ import (
 //	client := azure.New(config)
 //
 type AzureOpenAIClient struct {
+	*chat.BaseChatModel
 	config     Config
 	httpClient *http.Client
 }
@@ -44,42 +51,45 @@ type Config struct {
 	// Endpoint Azure OpenAI 资源端点
 	// 格式: https://<your-resource-name>.openai.azure.com
 	Endpoint string
-	
+
 	// APIKey API 密钥
 	APIKey string
-	
+
 	// Deployment 部署名称（模型部署的名称）
 	Deployment string
-	
+
 	// APIVersion API 版本
 	// 推荐: "2024-02-01", "2023-12-01-preview"
 	APIVersion string
-	
+
 	// Temperature 温度参数 (0.0-2.0)
 	Temperature float32
-	
+
 	// TopP 核采样参数
 	TopP float32
-	
+
 	// MaxTokens 最大输出 token 数
 	MaxTokens int
-	
+
 	// PresencePenalty 存在惩罚 (-2.0 到 2.0)
 	PresencePenalty float32
-	
+
 	// FrequencyPenalty 频率惩罚 (-2.0 到 2.0)
 	FrequencyPenalty float32
-	
+
 	// Stop 停止序列
 	Stop []string
-	
+
 	// HTTPClient 自定义 HTTP 客户端
 	HTTPClient *http.Client
-	
+
 	// Timeout 请求超时时间
 	Timeout time.Duration
 }
 
+// streamUsageAPIVersion 支持流式用量（stream_options.include_usage）的最低 API 版本
+const streamUsageAPIVersion = "2024-09-01"
+
 // DefaultConfig 返回默认配置
 func DefaultConfig() Config {
 	return Config{
@@ -96,278 +106,473 @@ func New(config Config) (*AzureOpenAIClient, error) {
 	if config.Endpoint == "" {
 		return nil, fmt.Errorf("azure: endpoint is required")
 	}
-	
+
 	if config.APIKey == "" {
 		return nil, fmt.Errorf("azure: API key is required")
 	}
-	
+
 	if config.Deployment == "" {
 		return nil, fmt.Errorf("azure: deployment name is required")
 	}
-	
+
 	if config.APIVersion == "" {
 		config.APIVersion = "2024-02-01"
 	}
-	
+
 	if config.Timeout == 0 {
 		config.Timeout = 60 * time.Second
 	}
-	
+
 	// 确保 endpoint 不以斜杠结尾
 	config.Endpoint = strings.TrimSuffix(config.Endpoint, "/")
-	
+
 	httpClient := config.HTTPClient
 	if httpClient == nil {
 		httpClient = &http.Client{
 			Timeout: config.Timeout,
 		}
 	}
-	
+
 	return &AzureOpenAIClient{
-		config:     config,
-		httpClient: httpClient,
+		BaseChatModel: chat.NewBaseChatModel(config.Deployment, "azure"),
+		config:        config,
+		httpClient:    httpClient,
 	}, nil
 }
 
-// Invoke 调用 Azure OpenAI API 生成响应
-func (c *AzureOpenAIClient) Invoke(ctx context.Context, messages []types.Message, opts ...Option) (types.Message, error) {
-	// 应用选项
-	config := c.config
-	for _, opt := range opts {
-		opt(&config)
+// Invoke 实现 Runnable 接口，调用 Azure OpenAI API 生成响应
+func (c *AzureOpenAIClient) Invoke(ctx context.Context, messages []types.Message, opts ...runnable.Option) (types.Message, error) {
+	return chat.TraceInvoke(ctx, c, messages, opts, func(ctx context.Context) (types.Message, error) {
+		return c.invoke(ctx, messages, opts...)
+	})
+}
+
+// invoke 执行单次调用。
+func (c *AzureOpenAIClient) invoke(ctx context.Context, messages []types.Message, opts ...runnable.Option) (types.Message, error) {
+	if err := chat.ValidateMessages(messages); err != nil {
+		return types.Message{}, err
 	}
-	
-	// 转换消息格式
-	azureMessages, err := c.convertMessages(messages)
+
+	// 构建请求
+	reqBody, err := c.buildRequest(messages, false, runnable.NewOptions(opts...))
 	if err != nil {
-		return types.Message{}, fmt.Errorf("azure: failed to convert messages: %w", err)
+		return types.Message{}, err
 	}
-	
-	// 构建请求
-	reqBody := AzureRequest{
-		Messages:         azureMessages,
-		Temperature:      config.Temperature,
-		TopP:             config.TopP,
-		MaxTokens:        config.MaxTokens,
-		PresencePenalty:  config.PresencePenalty,
-		FrequencyPenalty: config.FrequencyPenalty,
-		Stop:             config.Stop,
-	}
-	
+
 	// 发送请求
-	response, err := c.chatCompletion(ctx, &config, &reqBody)
+	response, err := c.chatCompletion(ctx, reqBody)
 	if err != nil {
 		return types.Message{}, err
 	}
-	
+
 	// 解析响应
 	return c.parseResponse(response)
 }
 
-// Stream 流式生成响应
-func (c *AzureOpenAIClient) Stream(ctx context.Context, messages []types.Message, opts ...Option) (<-chan types.StreamEvent, error) {
-	// 应用选项
-	config := c.config
-	for _, opt := range opts {
-		opt(&config)
+// Stream 实现 Runnable 接口，流式生成响应
+//
+// 文本增量以 EventStream 事件返回，EventEnd 事件携带完整消息（包括工具调用）。
+//
+func (c *AzureOpenAIClient) Stream(ctx context.Context, messages []types.Message, opts ...runnable.Option) (<-chan runnable.StreamEvent[types.Message], error) {
+	return chat.TraceStream(ctx, c, messages, opts, func(ctx context.Context) (<-chan runnable.StreamEvent[types.Message], error) {
+		return c.stream(ctx, messages, opts...)
+	})
+}
+
+// stream 执行流式调用。
+func (c *AzureOpenAIClient) stream(ctx context.Context, messages []types.Message, opts ...runnable.Option) (<-chan runnable.StreamEvent[types.Message], error) {
+	if err := chat.ValidateMessages(messages); err != nil {
+		return nil, err
 	}
-	
-	// 转换消息格式
-	azureMessages, err := c.convertMessages(messages)
+
+	// 构建请求
+	reqBody, err := c.buildRequest(messages, true, runnable.NewOptions(opts...))
 	if err != nil {
-		return nil, fmt.Errorf("azure: failed to convert messages: %w", err)
+		return nil, err
 	}
-	
-	// 构建请求
-	reqBody := AzureRequest{
-		Messages:         azureMessages,
-		Temperature:      config.Temperature,
-		TopP:             config.TopP,
-		MaxTokens:        config.MaxTokens,
-		PresencePenalty:  config.PresencePenalty,
-		FrequencyPenalty: config.FrequencyPenalty,
-		Stop:             config.Stop,
-		Stream:           true,
-	}
-	
+
 	// 创建流式响应通道
-	eventChan := make(chan types.StreamEvent, 10)
-	
+	out := make(chan runnable.StreamEvent[types.Message], 10)
+
 	go func() {
-		defer close(eventChan)
-		
-		if err := c.chatCompletionStream(ctx, &config, &reqBody, eventChan); err != nil {
-			eventChan <- types.StreamEvent{
+		defer close(out)
+
+		out <- runnable.StreamEvent[types.Message]{
+			Type: runnable.EventStart,
+			Name: c.GetName(),
+		}
+
+		if err := c.chatCompletionStream(ctx, reqBody, out); err != nil {
+			out <- runnable.StreamEvent[types.Message]{
+				Type:  runnable.EventError,
 				Error: err,
 			}
 		}
 	}()
-	
-	return eventChan, nil
+
+	// 在图等外层执行器中运行时，把 token 事件转发给外层
+	return runnable.ForwardStream(ctx, out), nil
 }
 
-// Batch 批量处理消息
-func (c *AzureOpenAIClient) Batch(ctx context.Context, messagesList [][]types.Message, opts ...Option) ([]types.Message, error) {
+// Batch 实现 Runnable 接口，并行处理多组消息
+func (c *AzureOpenAIClient) Batch(ctx context.Context, messagesList [][]types.Message, opts ...runnable.Option) ([]types.Message, error) {
+	if len(messagesList) == 0 {
+		return []types.Message{}, nil
+	}
+
 	results := make([]types.Message, len(messagesList))
-	
+	errs := make([]error, len(messagesList))
+
+	done := make(chan struct{}, len(messagesList))
 	for i, messages := range messagesList {
-		result, err := c.Invoke(ctx, messages, opts...)
+		go func(idx int, msgs []types.Message) {
+			results[idx], errs[idx] = c.Invoke(ctx, msgs, opts...)
+			done <- struct{}{}
+		}(i, messages)
+	}
+	for range messagesList {
+		<-done
+	}
+
+	for i, err := range errs {
 		if err != nil {
 			return nil, fmt.Errorf("azure: batch request %d failed: %w", i, err)
 		}
-		results[i] = result
 	}
-	
+
 	return results, nil
 }
 
+// BindTools 实现 ChatModel 接口，绑定工具
+//
+// 工具以 OpenAI function calling 格式（tools / tool_calls）发送。
+//
+func (c *AzureOpenAIClient) BindTools(tools []types.Tool) chat.ChatModel {
+	newClient := c.clone()
+	newClient.SetBoundTools(tools)
+	return newClient
+}
+
+// WithStructuredOutput 实现 ChatModel 接口，配置结构化输出
+//
+// 使用 response_format 的 json_schema 模式（需要 API 版本 2024-08-01-preview 及以上）。
+//
+func (c *AzureOpenAIClient) WithStructuredOutput(schema types.Schema) chat.ChatModel {
+	newClient := c.clone()
+	newClient.SetOutputSchema(schema)
+	return newClient
+}
+
+// WithConfig 实现 Runnable 接口
+func (c *AzureOpenAIClient) WithConfig(config *types.Config) runnable.Runnable[[]types.Message, types.Message] {
+	newClient := c.clone()
+	newClient.SetConfig(config)
+	return newClient
+}
+
+// WithRetry 实现 Runnable 接口
+func (c *AzureOpenAIClient) WithRetry(policy types.RetryPolicy) runnable.Runnable[[]types.Message, types.Message] {
+	return runnable.NewRetryRunnable[[]types.Message, types.Message](c, policy)
+}
+
+// WithFallbacks 实现 Runnable 接口
+func (c *AzureOpenAIClient) WithFallbacks(fallbacks ...runnable.Runnable[[]types.Message, types.Message]) runnable.Runnable[[]types.Message, types.Message] {
+	return runnable.NewFallbackRunnable[[]types.Message, types.Message](c, fallbacks)
+}
+
+// WithOptions 返回应用了选项的新客户端
+//
+// 绑定的工具和结构化输出配置会保留。
+//
+// 示例：
+//
+//	creative := client.WithOptions(azure.WithTemperature(0.9), azure.WithMaxTokens(1000))
+//	response, _ := creative.Invoke(ctx, messages)
+//
+func (c *AzureOpenAIClient) WithOptions(opts ...Option) *AzureOpenAIClient {
+	newClient := c.clone()
+	for _, opt := range opts {
+		opt(&newClient.config)
+	}
+	return newClient
+}
+
 // ==================== 内部方法 ====================
 
+// clone 复制客户端（包括工具、结构化输出和运行时配置）
+func (c *AzureOpenAIClient) clone() *AzureOpenAIClient {
+	newClient := &AzureOpenAIClient{
+		BaseChatModel: chat.NewBaseChatModel(c.config.Deployment, "azure"),
+		config:        c.config,
+		httpClient:    c.httpClient,
+	}
+	newClient.SetConfig(c.GetConfig())
+	newClient.SetBoundTools(c.GetBoundTools())
+	if schema := c.GetOutputSchema(); schema != nil {
+		newClient.SetOutputSchema(*schema)
+	}
+	return newClient
+}
+
+// buildRequest 构建请求体
+//
+// options 中设置的单次调用参数优先于模型配置。
+//
+func (c *AzureOpenAIClient) buildRequest(messages []types.Message, stream bool, options *runnable.Options) (*AzureRequest, error) {
+	azureMessages, err := c.convertMessages(messages)
+	if err != nil {
+		return nil, fmt.Errorf("azure: failed to convert messages: %w", err)
+	}
+
+	reqBody := &AzureRequest{
+		Messages:         azureMessages,
+		TopP:             c.config.TopP,
+		MaxTokens:        c.config.MaxTokens,
+		PresencePenalty:  c.config.PresencePenalty,
+		FrequencyPenalty: c.config.FrequencyPenalty,
+		Stop:             c.config.Stop,
+		Seed:             options.Seed,
+		Stream:           stream,
+	}
+
+	// 单次调用的生成参数（温度为 0 时也需要发送）
+	if options.Temperature != nil {
+		temperature := float32(*options.Temperature)
+		reqBody.Temperature = &temperature
+	} else if c.config.Temperature > 0 {
+		reqBody.Temperature = &c.config.Temperature
+	}
+	if options.MaxTokens > 0 {
+		reqBody.MaxTokens = options.MaxTokens
+	}
+	if len(options.Stop) > 0 {
+		reqBody.Stop = options.Stop
+	}
+
+	// 流式用量（stream_options）从 API 版本 2024-09-01-preview 开始支持，
+	// 旧版本会拒绝未知参数
+	if stream && c.config.APIVersion >= streamUsageAPIVersion {
+		reqBody.StreamOptions = map[string]any{"include_usage": true}
+	}
+
+	// 添加工具
+	if tools := c.GetBoundTools(); len(tools) > 0 {
+		reqBody.Tools = chat.ConvertToolsToOpenAI(tools)
+		reqBody.ToolChoice = "auto"
+		if options.ToolChoice != nil {
+			reqBody.ToolChoice = options.ToolChoice.ToOpenAI()
+		}
+		reqBody.ParallelToolCalls = options.ParallelToolCalls
+	}
+
+	// 添加结构化输出（单次调用的响应格式优先）
+	if format := options.ResponseFormat; format != nil {
+		reqBody.ResponseFormat = format.ToOpenAI()
+	} else if schema := c.GetOutputSchema(); schema != nil {
+		reqBody.ResponseFormat = map[string]any{
+			"type": "json_schema",
+			"json_schema": map[string]any{
+				"name":   "response",
+				"schema": schema.ToMap(),
+				"strict": true,
+			},
+		}
+	}
+
+	return reqBody, nil
+}
+
 func (c *AzureOpenAIClient) convertMessages(messages []types.Message) ([]AzureMessage, error) {
 	if len(messages) == 0 {
 		return nil, fmt.Errorf("azure: messages are required")
 	}
-	
+
 	azureMessages := make([]AzureMessage, 0, len(messages))
-	
+
 	for _, msg := range messages {
-		azureMessages = append(azureMessages, AzureMessage{
+		azureMsg := AzureMessage{
 			Role:    string(msg.Role),
 			Content: msg.Content,
-		})
+			Name:    msg.Name,
+		}
+
+		// 工具调用（assistant）和工具结果（tool）
+		for _, tc := range msg.ToolCalls {
+			toolType := tc.Type
+			if toolType == "" {
+				toolType = "function"
+			}
+			azureMsg.ToolCalls = append(azureMsg.ToolCalls, AzureToolCall{
+				ID:   tc.ID,
+				Type: toolType,
+				Function: AzureFunctionCall{
+					Name:      tc.Function.Name,
+					Arguments: tc.Function.Arguments,
+				},
+			})
+		}
+		if msg.Role == types.RoleTool {
+			azureMsg.ToolCallID = msg.ToolCallID
+		}
+
+		azureMessages = append(azureMessages, azureMsg)
 	}
-	
+
 	return azureMessages, nil
 }
 
-func (c *AzureOpenAIClient) chatCompletion(ctx context.Context, config *Config, reqBody *AzureRequest) (*AzureResponse, error) {
+// newRequest 创建 chat completions 请求
+func (c *AzureOpenAIClient) newRequest(ctx context.Context, reqBody *AzureRequest) (*http.Request, error) {
 	bodyBytes, err := json.Marshal(reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("azure: failed to marshal request: %w", err)
 	}
-	
+
 	// 构建 URL
 	url := fmt.Sprintf("%s/openai/deployments/%s/chat/completions?api-version=%s",
-		config.Endpoint, config.Deployment, config.APIVersion)
-	
+		c.config.Endpoint, c.config.Deployment, c.config.APIVersion)
+
 	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(bodyBytes))
 	if err != nil {
 		return nil, fmt.Errorf("azure: failed to create request: %w", err)
 	}
-	
+
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("api-key", config.APIKey)
-	
+	req.Header.Set("api-key", c.config.APIKey)
+	if reqBody.Stream {
+		req.Header.Set("Accept", "text/event-stream")
+	}
+
+	return req, nil
+}
+
+func (c *AzureOpenAIClient) chatCompletion(ctx context.Context, reqBody *AzureRequest) (*AzureResponse, error) {
+	req, err := c.newRequest(ctx, reqBody)
+	if err != nil {
+		return nil, err
+	}
+
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("azure: request failed: %w", err)
+		return nil, chaterrors.FromTransport(c.GetProvider(), fmt.Errorf("azure: request failed: %w", err))
 	}
 	defer resp.Body.Close()
-	
+
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("azure: API error (status %d): %s", resp.StatusCode, string(body))
+		return nil, chaterrors.FromResponse(c.GetProvider(), resp, body)
 	}
-	
+
 	var response AzureResponse
 	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
 		return nil, fmt.Errorf("azure: failed to decode response: %w", err)
 	}
-	
+
 	return &response, nil
 }
 
-func (c *AzureOpenAIClient) chatCompletionStream(ctx context.Context, config *Config, reqBody *AzureRequest, eventChan chan<- types.StreamEvent) error {
-	bodyBytes, err := json.Marshal(reqBody)
+func (c *AzureOpenAIClient) chatCompletionStream(ctx context.Context, reqBody *AzureRequest, out chan<- runnable.StreamEvent[types.Message]) error {
+	req, err := c.newRequest(ctx, reqBody)
 	if err != nil {
-		return fmt.Errorf("azure: failed to marshal request: %w", err)
+		return err
 	}
-	
-	url := fmt.Sprintf("%s/openai/deployments/%s/chat/completions?api-version=%s",
-		config.Endpoint, config.Deployment, config.APIVersion)
-	
-	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(bodyBytes))
-	if err != nil {
-		return fmt.Errorf("azure: failed to create request: %w", err)
-	}
-	
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("api-key", config.APIKey)
-	req.Header.Set("Accept", "text/event-stream")
-	
+
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("azure: request failed: %w", err)
+		return chaterrors.FromTransport(c.GetProvider(), fmt.Errorf("azure: request failed: %w", err))
 	}
 	defer resp.Body.Close()
-	
+
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("azure: API error (status %d): %s", resp.StatusCode, string(body))
+		return chaterrors.FromResponse(c.GetProvider(), resp, body)
 	}
-	
+
 	// 解析 SSE 流
-	return c.parseSSEStream(resp.Body, eventChan)
+	return c.parseSSEStream(resp.Body, out)
 }
 
-func (c *AzureOpenAIClient) parseSSEStream(reader io.Reader, eventChan chan<- types.StreamEvent) error {
-	buf := make([]byte, 4096)
-	leftover := ""
-	
-	for {
-		n, err := reader.Read(buf)
-		if err != nil && err != io.EOF {
-			return fmt.Errorf("azure: failed to read stream: %w", err)
+// parseSSEStream 解析 SSE 流，转发文本增量并累积工具调用
+func (c *AzureOpenAIClient) parseSSEStream(reader io.Reader, out chan<- runnable.StreamEvent[types.Message]) error {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	fullMessage := types.Message{Role: types.RoleAssistant}
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || !strings.HasPrefix(line, "data: ") {
+			continue
 		}
-		
-		if n == 0 {
+
+		jsonData := strings.TrimPrefix(line, "data: ")
+		if jsonData == "[DONE]" {
 			break
 		}
-		
-		data := leftover + string(buf[:n])
-		lines := strings.Split(data, "\n")
-		
-		// 保留最后一个不完整的行
-		if !strings.HasSuffix(data, "\n") {
-			leftover = lines[len(lines)-1]
-			lines = lines[:len(lines)-1]
-		} else {
-			leftover = ""
+
+		var chunk AzureStreamChunk
+		if err := json.Unmarshal([]byte(jsonData), &chunk); err != nil {
+			continue
+		}
+
+		// 用量在 choices 为空的最后一个数据块中
+		if chunk.Usage != nil {
+			fullMessage.UsageMetadata = chunk.Usage.toUsageMetadata()
+		}
+
+		// Azure 内容过滤的首个数据块没有 choices
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+
+		if reason := chunk.Choices[0].FinishReason; reason != "" {
+			fullMessage.FinishReason = chat.NormalizeFinishReason(reason)
 		}
-		
-		// 处理每一行
-		for _, line := range lines {
-			line = strings.TrimSpace(line)
-			if line == "" || !strings.HasPrefix(line, "data: ") {
-				continue
+
+		delta := chunk.Choices[0].Delta
+
+		// 提取文本
+		if delta.Content != "" {
+			fullMessage.Content += delta.Content
+			out <- runnable.StreamEvent[types.Message]{
+				Type: runnable.EventStream,
+				Data: types.Message{
+					Role:    types.RoleAssistant,
+					Content: delta.Content,
+				},
+				Name: c.GetName(),
 			}
-			
-			jsonData := strings.TrimPrefix(line, "data: ")
-			if jsonData == "[DONE]" {
-				break
+		}
+
+		// 累积工具调用
+		for _, tc := range delta.ToolCalls {
+			for len(fullMessage.ToolCalls) <= tc.Index {
+				fullMessage.ToolCalls = append(fullMessage.ToolCalls, types.ToolCall{Type: "function"})
 			}
-			
-			var chunk AzureStreamChunk
-			if err := json.Unmarshal([]byte(jsonData), &chunk); err != nil {
-				continue
+			call := &fullMessage.ToolCalls[tc.Index]
+			if tc.ID != "" {
+				call.ID = tc.ID
 			}
-			
-			// 提取文本
-			if len(chunk.Choices) > 0 && chunk.Choices[0].Delta.Content != "" {
-				eventChan <- types.StreamEvent{
-					Data: types.Message{
-						Role:    types.RoleAssistant,
-						Content: chunk.Choices[0].Delta.Content,
-					},
-				}
+			if tc.Type != "" {
+				call.Type = tc.Type
 			}
+			if tc.Function.Name != "" {
+				call.Function.Name = tc.Function.Name
+			}
+			call.Function.Arguments += tc.Function.Arguments
 		}
-		
-		if err == io.EOF {
-			break
-		}
 	}
-	
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("azure: failed to read stream: %w", err)
+	}
+
+	out <- runnable.StreamEvent[types.Message]{
+		Type: runnable.EventEnd,
+		Data: fullMessage,
+		Name: c.GetName(),
+	}
+
 	return nil
 }
 
@@ -375,18 +580,37 @@ func (c *AzureOpenAIClient) parseResponse(response *AzureResponse) (types.Messag
 	if len(response.Choices) == 0 {
 		return types.Message{}, fmt.Errorf("azure: no choices in response")
 	}
-	
+
 	choice := response.Choices[0]
-	
-	return types.Message{
-		Role:    types.RoleAssistant,
-		Content: choice.Message.Content,
-	}, nil
+
+	message := types.Message{
+		Role:          types.RoleAssistant,
+		Content:       choice.Message.Content,
+		FinishReason:  chat.NormalizeFinishReason(choice.FinishReason),
+		UsageMetadata: response.Usage.toUsageMetadata(),
+	}
+
+	for _, tc := range choice.Message.ToolCalls {
+		toolType := tc.Type
+		if toolType == "" {
+			toolType = "function"
+		}
+		message.ToolCalls = append(message.ToolCalls, types.ToolCall{
+			ID:   tc.ID,
+			Type: toolType,
+			Function: types.FunctionCall{
+				Name:      tc.Function.Name,
+				Arguments: tc.Function.Arguments,
+			},
+		})
+	}
+
+	return message, nil
 }
 
 // ==================== 选项模式 ====================
 
-// Option 配置选项
+// Option 配置选项，通过 WithOptions 应用
 type Option func(*Config)
 
 // WithTemperature 设置温度
@@ -434,21 +658,44 @@ func WithStop(stop []string) Option {
 // ==================== API 类型定义 ====================
 
 // AzureRequest Azure OpenAI API 请求
+
 type AzureRequest struct {
-	Messages         []AzureMessage `json:"messages"`
-	Temperature      float32        `json:"temperature,omitempty"`
-	TopP             float32        `json:"top_p,omitempty"`
-	MaxTokens        int            `json:"max_tokens,omitempty"`
-	PresencePenalty  float32        `json:"presence_penalty,omitempty"`
-	FrequencyPenalty float32        `json:"frequency_penalty,omitempty"`
-	Stop             []string       `json:"stop,omitempty"`
-	Stream           bool           `json:"stream,omitempty"`
+	Messages          []AzureMessage   `json:"messages"`
+	Temperature       *float32         `json:"temperature,omitempty"`
+	TopP              float32          `json:"top_p,omitempty"`
+	MaxTokens         int              `json:"max_tokens,omitempty"`
+	PresencePenalty   float32          `json:"presence_penalty,omitempty"`
+	FrequencyPenalty  float32          `json:"frequency_penalty,omitempty"`
+	Stop              []string         `json:"stop,omitempty"`
+	Seed              *int             `json:"seed,omitempty"`
+	Stream            bool             `json:"stream,omitempty"`
+	Tools             []map[string]any `json:"tools,omitempty"`
+	ToolChoice        any              `json:"tool_choice,omitempty"`
+	ResponseFormat    map[string]any   `json:"response_format,omitempty"`
+	ParallelToolCalls *bool            `json:"parallel_tool_calls,omitempty"`
+	StreamOptions     map[string]any   `json:"stream_options,omitempty"`
 }
 
 // AzureMessage Azure 消息格式
 type AzureMessage struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role       string          `json:"role"`
+	Content    string          `json:"content"`
+	Name       string          `json:"name,omitempty"`
+	ToolCalls  []AzureToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string          `json:"tool_call_id,omitempty"`
+}
+
+// AzureToolCall 工具调用
+type AzureToolCall struct {
+	ID       string            `json:"id"`
+	Type     string            `json:"type"`
+	Function AzureFunctionCall `json:"function"`
+}
+
+// AzureFunctionCall 函数调用
+type AzureFunctionCall struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
 }
 
 // AzureResponse Azure OpenAI API 响应
@@ -458,7 +705,7 @@ type AzureResponse struct {
 	Created int64         `json:"created"`
 	Model   string        `json:"model"`
 	Choices []AzureChoice `json:"choices"`
-	Usage   AzureUsage    `json:"usage"`
+	Usage   *AzureUsage   `json:"usage"`
 }
 
 // AzureChoice 选择项
@@ -470,9 +717,29 @@ type AzureChoice struct {
 
 // AzureUsage token 使用情况
 type AzureUsage struct {
-	PromptTokens     int `json:"prompt_tokens"`
-	CompletionTokens int `json:"completion_tokens"`
-	TotalTokens      int `json:"total_tokens"`
+	PromptTokens        int `json:"prompt_tokens"`
+	CompletionTokens    int `json:"completion_tokens"`
+	TotalTokens         int `json:"total_tokens"`
+	PromptTokensDetails struct {
+		CachedTokens int `json:"cached_tokens"`
+	} `json:"prompt_tokens_details"`
+	CompletionTokensDetails struct {
+		ReasoningTokens int `json:"reasoning_tokens"`
+	} `json:"completion_tokens_details"`
+}
+
+// toUsageMetadata 转换为标准用量，u 为 nil 时返回 nil
+func (u *AzureUsage) toUsageMetadata() *types.UsageMetadata {
+	if u == nil {
+		return nil
+	}
+	return &types.UsageMetadata{
+		InputTokens:     u.PromptTokens,
+		OutputTokens:    u.CompletionTokens,
+		TotalTokens:     u.TotalTokens,
+		CachedTokens:    u.PromptTokensDetails.CachedTokens,
+		ReasoningTokens: u.CompletionTokensDetails.ReasoningTokens,
+	}
 }
 
 // AzureStreamChunk 流式响应块
@@ -482,6 +749,7 @@ type AzureStreamChunk struct {
 	Created int64               `json:"created"`
 	Model   string              `json:"model"`
 	Choices []AzureStreamChoice `json:"choices"`
+	Usage   *AzureUsage         `json:"usage"`
 }
 
 // AzureStreamChoice 流式选择项
@@ -493,6 +761,15 @@ type AzureStreamChoice struct {
 
 // AzureMessageDelta 流式消息增量
 type AzureMessageDelta struct {
-	Role    string `json:"role,omitempty"`
-	Content string `json:"content,omitempty"`
+	Role      string               `json:"role,omitempty"`
+	Content   string               `json:"content,omitempty"`
+	ToolCalls []AzureToolCallDelta `json:"tool_calls,omitempty"`
+}
+
+// AzureToolCallDelta 流式工具调用增量
+type AzureToolCallDelta struct {
+	Index    int               `json:"index"`
+	ID       string            `json:"id,omitempty"`
+	Type     string            `json:"type,omitempty"`
+	Function AzureFunctionCall `json:"function"`
 }
@@ -1,8 +1,16 @@
 package azure
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/zhucl121/langchain-go/core/chat"
+	"github.com/zhucl121/langchain-go/core/runnable"
 	"github.com/zhucl121/langchain-go/pkg/types"
 )
 
@@ -284,6 +292,230 @@ func TestParseResponse(t *testing.T) {
 	})
 }
 
+// weatherTool 测试用工具
+var weatherTool = types.Tool{
+	Name:        "get_weather",
+	Description: "Get the weather for a city",
+	Parameters: types.Schema{
+		Type: "object",
+		Properties: map[string]types.Schema{
+			"city": {Type: "string"},
+		},
+		Required: []string{"city"},
+	},
+}
+
+// newTestClient 创建指向测试服务器的客户端
+func newTestClient(t *testing.T, handler http.HandlerFunc) *AzureOpenAIClient {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := New(Config{
+		Endpoint:   server.URL,
+		APIKey:     "test-key",
+		Deployment: "gpt-4o",
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	return client
+}
+
+func TestChatModelInterface(t *testing.T) {
+	var model chat.ChatModel
+	model, _ = New(Config{Endpoint: "https://test.openai.azure.com", APIKey: "k", Deployment: "gpt-4o"})
+
+	if model.GetProvider() != "azure" || model.GetModelName() != "gpt-4o" {
+		t.Errorf("unexpected provider/model: %s/%s", model.GetProvider(), model.GetModelName())
+	}
+
+	bound := model.BindTools([]types.Tool{weatherTool})
+	if bound == model {
+		t.Error("BindTools should return a new instance")
+	}
+	if len(bound.(*AzureOpenAIClient).GetBoundTools()) != 1 {
+		t.Error("expected bound tool")
+	}
+	if len(model.(*AzureOpenAIClient).GetBoundTools()) != 0 {
+		t.Error("BindTools should not modify the original client")
+	}
+
+	structured := bound.WithStructuredOutput(types.Schema{Type: "object"})
+	if structured.(*AzureOpenAIClient).GetOutputSchema() == nil || len(structured.(*AzureOpenAIClient).GetBoundTools()) != 1 {
+		t.Error("WithStructuredOutput should keep tools and set schema")
+	}
+}
+
+func TestInvoke_ToolCalls(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/openai/deployments/gpt-4o/chat/completions" || r.Header.Get("api-key") != "test-key" {
+			t.Errorf("unexpected request: %s %v", r.URL.Path, r.Header)
+		}
+
+		var req AzureRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		if len(req.Tools) != 1 || req.ToolChoice != "auto" {
+			t.Errorf("expected tools in request, got %+v", req.Tools)
+		}
+		if last := req.Messages[len(req.Messages)-1]; last.Role != "tool" || last.ToolCallID != "call_1" {
+			t.Errorf("expected tool result message, got %+v", last)
+		}
+		if prev := req.Messages[len(req.Messages)-2]; len(prev.ToolCalls) != 1 || prev.ToolCalls[0].Function.Name != "get_weather" {
+			t.Errorf("expected assistant tool call, got %+v", prev)
+		}
+
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":null,"tool_calls":[
+			{"id":"call_2","type":"function","function":{"name":"get_weather","arguments":"{\"city\":\"Oslo\"}"}}]},
+			"finish_reason":"tool_calls"}],
+			"usage":{"prompt_tokens":50,"completion_tokens":12,"total_tokens":62,"prompt_tokens_details":{"cached_tokens":32}}}`)
+	})
+
+	messages := []types.Message{
+		types.NewUserMessage("Weather in Paris and Oslo?"),
+		{Role: types.RoleAssistant, ToolCalls: []types.ToolCall{{
+			ID: "call_1", Type: "function",
+			Function: types.FunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`},
+		}}},
+		types.NewToolMessage("call_1", "sunny"),
+	}
+
+	response, err := client.BindTools([]types.Tool{weatherTool}).Invoke(context.Background(), messages)
+	if err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+	if len(response.ToolCalls) != 1 || response.ToolCalls[0].ID != "call_2" || response.ToolCalls[0].Function.Arguments != `{"city":"Oslo"}` {
+		t.Errorf("unexpected tool calls: %+v", response.ToolCalls)
+	}
+	if response.FinishReason != types.FinishReasonToolCalls {
+		t.Errorf("unexpected finish reason: %q", response.FinishReason)
+	}
+	if usage := response.UsageMetadata; usage == nil || usage.InputTokens != 50 || usage.TotalTokens != 62 || usage.CachedTokens != 32 {
+		t.Errorf("unexpected usage: %+v", usage)
+	}
+}
+
+func TestStream(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var req AzureRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if !req.Stream {
+			t.Error("expected stream=true")
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, chunk := range []string{
+			`{"choices":[]}`,
+			`{"choices":[{"delta":{"role":"assistant","content":"Let me "}}]}`,
+			`{"choices":[{"delta":{"content":"check."}}]}`,
+			`{"choices":[{"delta":{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"get_weather","arguments":"{\"ci"}}]}}]}`,
+			`{"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":"ty\":\"Oslo\"}"}}]}}]}`,
+			`{"choices":[{"delta":{},"finish_reason":"tool_calls"}]}`,
+			`{"choices":[],"usage":{"prompt_tokens":20,"completion_tokens":9,"total_tokens":29}}`,
+			`[DONE]`,
+		} {
+			fmt.Fprintf(w, "data: %s\n\n", chunk)
+		}
+	})
+
+	stream, err := client.Stream(context.Background(), []types.Message{types.NewUserMessage("Weather?")})
+	if err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+
+	var kinds []runnable.EventType
+	var content strings.Builder
+	var final types.Message
+	for event := range stream {
+		kinds = append(kinds, event.Type)
+		switch event.Type {
+		case runnable.EventStream:
+			content.WriteString(event.Data.Content)
+		case runnable.EventEnd:
+			final = event.Data
+		case runnable.EventError:
+			t.Fatalf("stream error: %v", event.Error)
+		}
+	}
+
+	if len(kinds) != 4 || kinds[0] != runnable.EventStart || kinds[3] != runnable.EventEnd {
+		t.Errorf("unexpected events: %v", kinds)
+	}
+	if content.String() != "Let me check." || final.Content != "Let me check." {
+		t.Errorf("unexpected content: %q / %q", content.String(), final.Content)
+	}
+	if len(final.ToolCalls) != 1 || final.ToolCalls[0].Function.Arguments != `{"city":"Oslo"}` {
+		t.Errorf("unexpected tool calls: %+v", final.ToolCalls)
+	}
+	if final.FinishReason != types.FinishReasonToolCalls || final.UsageMetadata == nil || final.UsageMetadata.TotalTokens != 29 {
+		t.Errorf("unexpected finish reason / usage: %q %+v", final.FinishReason, final.UsageMetadata)
+	}
+}
+
+func TestBuildRequest_StreamOptions(t *testing.T) {
+	client, _ := New(Config{Endpoint: "https://test.openai.azure.com", APIKey: "k", Deployment: "gpt-4o"})
+	reqBody, _ := client.buildRequest([]types.Message{types.NewUserMessage("hi")}, true, runnable.NewOptions())
+	if reqBody.StreamOptions != nil {
+		t.Error("stream_options should not be sent to API versions that reject it")
+	}
+
+	client, _ = New(Config{Endpoint: "https://test.openai.azure.com", APIKey: "k", Deployment: "gpt-4o", APIVersion: "2024-10-21"})
+	reqBody, _ = client.buildRequest([]types.Message{types.NewUserMessage("hi")}, true, runnable.NewOptions())
+	if reqBody.StreamOptions["include_usage"] != true {
+		t.Errorf("expected include_usage, got %v", reqBody.StreamOptions)
+	}
+}
+
+func TestInvoke_CallOptions(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+		json.NewDecoder(r.Body).Decode(&req)
+		if req["temperature"] != float64(0) || req["max_tokens"] != float64(64) || req["seed"] != float64(7) {
+			t.Errorf("expected per-call sampling options, got %v", req)
+		}
+		if req["tool_choice"] != "none" || req["parallel_tool_calls"] != false {
+			t.Errorf("expected per-call tool options, got %v", req)
+		}
+		if format, _ := req["response_format"].(map[string]any); format["type"] != "json_object" {
+			t.Errorf("expected json_object response format, got %v", req["response_format"])
+		}
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"{}"}}]}`)
+	})
+
+	model := client.BindTools([]types.Tool{{Name: "get_weather", Parameters: types.Schema{Type: "object"}}})
+	_, err := model.Invoke(context.Background(), []types.Message{types.NewUserMessage("hi")},
+		runnable.WithTemperature(0),
+		runnable.WithMaxTokens(64),
+		runnable.WithSeed(7),
+		runnable.WithToolChoice(types.NewToolChoice(types.ToolChoiceNone)),
+		runnable.WithParallelToolCalls(false),
+		runnable.WithResponseFormat(types.NewJSONObjectFormat()))
+	if err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+}
+
+func TestWithOptions(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var req AzureRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Temperature == nil || *req.Temperature != 0.2 || req.MaxTokens != 64 || req.ResponseFormat == nil {
+			t.Errorf("expected options and response format in request, got %+v", req)
+		}
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"{}"}}]}`)
+	})
+
+	structured := client.WithStructuredOutput(types.Schema{Type: "object"}).(*AzureOpenAIClient)
+	configured := structured.WithOptions(WithTemperature(0.2), WithMaxTokens(64))
+	if _, err := configured.Invoke(context.Background(), []types.Message{types.NewUserMessage("hi")}); err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+	if client.config.Temperature == 0.2 {
+		t.Error("WithOptions should not modify the original client")
+	}
+}
+
 // 集成测试（需要真实 Azure OpenAI 资源）
 func TestAzureOpenAIIntegration(t *testing.T) {
 	t.Skip("Integration test - requires Azure OpenAI configuration")
@@ -30,19 +30,34 @@
 //
 //	stream, _ := client.Stream(ctx, messages)
 //	for event := range stream {
-//	    if event.Error != nil {
+//	    switch event.Type {
+//	    case runnable.EventStream:
+//	        fmt.Print(event.Data.Content)
+//	    case runnable.EventError:
 //	        log.Fatal(event.Error)
 //	    }
-//	    fmt.Print(event.Data.Content)
 //	}
 //
+// 工具调用（OpenAI function calling 格式）：
+//
+//	modelWithTools := client.BindTools([]types.Tool{weatherTool})
+//	response, _ := modelWithTools.Invoke(ctx, messages)
+//	for _, call := range response.ToolCalls {
+//	    fmt.Println(call.Function.Name, call.Function.Arguments)
+//	}
+//
+// AzureOpenAIClient 实现了 chat.ChatModel，可以直接用于 Agent、StateGraph
+// 或作为其他模型的 fallback：
+//
+//	model := openaiModel.WithFallbacks(client)
+//
 // 自定义参数：
 //
-//	response, _ := client.Invoke(ctx, messages,
+//	response, _ := client.WithOptions(
 //	    azure.WithTemperature(0.9),
 //	    azure.WithMaxTokens(1000),
 //	    azure.WithPresencePenalty(0.6),
-//	)
+//	).Invoke(ctx, messages)
 //
 // 查找端点和部署：
 //
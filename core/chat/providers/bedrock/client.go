@@ -3,18 +3,29 @@ package bedrock
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
+	"github.com/zhucl121/langchain-go/core/chat"
+	chaterrors "github.com/zhucl121/langchain-go/core/chat/errors"
+	"github.com/zhucl121/langchain-go/core/runnable"
 	"github.com/zhucl121/langchain-go/pkg/types"
 )
 
 // BedrockClient 实现 AWS Bedrock API 集成
 //
 // AWS Bedrock 提供托管的基础模型服务，支持多个提供商的模型。
+// BedrockClient 实现了 chat.ChatModel 接口；Anthropic 模型的工具调用使用
+// Claude 原生的 tool_use / tool_result 格式；启用 UseConverse 后使用与模型无关的
+// Converse API，所有支持工具的模型都可以调用工具。
+//
+// 请求使用 AWS Signature V4 签名，凭证依次从 Config.Credentials、
+// AccessKey/SecretKey、环境变量和共享凭证文件中获取。
 //
 // 支持的模型系列:
 //   - anthropic.claude-v2, anthropic.claude-3-*
@@ -26,54 +37,76 @@ import (
 // 使用示例:
 //
 //	config := bedrock.Config{
-//	    Region:    "us-east-1",
-//	    AccessKey: "your-access-key",
-//	    SecretKey: "your-secret-key",
-//	    Model:     "anthropic.claude-v2",
+//	    Region: "us-east-1",
+//	    Model:  "anthropic.claude-v2",
 //	}
-//	client := bedrock.New(config)
+//	client, err := bedrock.New(config) // 使用环境变量或 ~/.aws/credentials
 //
 type BedrockClient struct {
+	*chat.BaseChatModel
 	config     Config
 	httpClient *http.Client
+
+	// 单次调用的工具选择（见 withCallOptions）
+	toolChoice        *types.ToolChoice
+	parallelToolCalls *bool
 }
 
 // Config 是 AWS Bedrock 的配置
 type Config struct {
 	// Region AWS 区域
 	Region string
-	
+
 	// AccessKey AWS 访问密钥 ID
 	AccessKey string
-	
+
 	// SecretKey AWS 秘密访问密钥
 	SecretKey string
-	
+
 	// SessionToken 会话令牌（如果使用临时凭证）
 	SessionToken string
-	
+
+	// Profile 共享凭证文件中的配置名称，未设置 AccessKey 时使用
+	Profile string
+
+	// Credentials 自定义凭证提供者，优先于 AccessKey / SecretKey
+	Credentials CredentialsProvider
+
+	// Endpoint 自定义端点，默认为 https://bedrock-runtime.<Region>.amazonaws.com
+	Endpoint string
+
+	// UseConverse 使用 Converse API（与模型无关的消息和工具格式）
+	UseConverse bool
+
 	// Model 模型 ID
 	Model string
-	
+
 	// Temperature 温度参数
 	Temperature float32
-	
+
 	// TopP 核采样参数
 	TopP float32
-	
+
 	// MaxTokens 最大输出 token 数
 	MaxTokens int
-	
+
 	// StopSequences 停止序列
 	StopSequences []string
-	
+
 	// HTTPClient 自定义 HTTP 客户端
 	HTTPClient *http.Client
-	
+
 	// Timeout 请求超时时间
 	Timeout time.Duration
 }
 
+// structuredOutputTool 结构化输出使用的工具名称
+//
+// Claude 没有原生的 JSON Schema 输出模式，WithStructuredOutput 通过强制调用
+// 以输出 Schema 为参数的工具实现，工具参数即为结构化输出。
+//
+const structuredOutputTool = "structured_output"
+
 // DefaultConfig 返回默认配置
 func DefaultConfig() Config {
 	return Config{
@@ -87,165 +120,356 @@ func DefaultConfig() Config {
 }
 
 // New 创建新的 Bedrock 客户端
+//
+// 凭证的解析顺序：
+//  1. Config.Credentials
+//  2. Config.AccessKey / SecretKey / SessionToken
+//  3. 环境变量 AWS_ACCESS_KEY_ID / AWS_SECRET_ACCESS_KEY / AWS_SESSION_TOKEN
+//  4. 共享凭证文件（AWS_SHARED_CREDENTIALS_FILE 或 ~/.aws/credentials）中的 Profile
+//
+// 使用默认凭证链且找不到凭证时返回 ErrNoCredentials。
+//
 func New(config Config) (*BedrockClient, error) {
 	if config.Region == "" {
 		return nil, fmt.Errorf("bedrock: region is required")
 	}
-	
-	if config.AccessKey == "" {
-		return nil, fmt.Errorf("bedrock: access key is required")
-	}
-	
-	if config.SecretKey == "" {
-		return nil, fmt.Errorf("bedrock: secret key is required")
+
+	if config.Credentials == nil {
+		switch {
+		case config.AccessKey != "" && config.SecretKey != "":
+			config.Credentials = NewStaticCredentials(config.AccessKey, config.SecretKey, config.SessionToken)
+		case config.SecretKey != "":
+			return nil, fmt.Errorf("bedrock: access key is required")
+		case config.AccessKey != "":
+			return nil, fmt.Errorf("bedrock: secret key is required")
+		default:
+			config.Credentials = DefaultCredentials(config.Profile)
+			if _, err := config.Credentials.Retrieve(context.Background()); err != nil {
+				return nil, err
+			}
+		}
 	}
-	
+
 	if config.Model == "" {
 		config.Model = "anthropic.claude-v2"
 	}
-	
+
 	if config.Timeout == 0 {
 		config.Timeout = 60 * time.Second
 	}
-	
+
 	httpClient := config.HTTPClient
 	if httpClient == nil {
 		httpClient = &http.Client{
 			Timeout: config.Timeout,
 		}
 	}
-	
+
 	return &BedrockClient{
-		config:     config,
-		httpClient: httpClient,
+		BaseChatModel: chat.NewBaseChatModel(config.Model, "bedrock"),
+		config:        config,
+		httpClient:    httpClient,
 	}, nil
 }
 
-// Invoke 调用 Bedrock API 生成响应
-func (c *BedrockClient) Invoke(ctx context.Context, messages []types.Message, opts ...Option) (types.Message, error) {
-	// 应用选项
-	config := c.config
-	for _, opt := range opts {
-		opt(&config)
+// Invoke 实现 Runnable 接口，调用 Bedrock API 生成响应
+func (c *BedrockClient) Invoke(ctx context.Context, messages []types.Message, opts ...runnable.Option) (types.Message, error) {
+	return chat.TraceInvoke(ctx, c, messages, opts, func(ctx context.Context) (types.Message, error) {
+		return c.invoke(ctx, messages, opts...)
+	})
+}
+
+// invoke 执行单次调用。
+func (c *BedrockClient) invoke(ctx context.Context, messages []types.Message, opts ...runnable.Option) (types.Message, error) {
+	if err := chat.ValidateMessages(messages); err != nil {
+		return types.Message{}, err
 	}
-	
+
 	// 根据模型类型构建不同的请求
-	reqBody, err := c.buildRequest(&config, messages)
+	client := c.withCallOptions(runnable.NewOptions(opts...))
+	reqBody, err := client.buildRequest(&client.config, messages)
 	if err != nil {
 		return types.Message{}, fmt.Errorf("bedrock: failed to build request: %w", err)
 	}
-	
+
 	// 发送请求
-	response, err := c.invokeModel(ctx, &config, reqBody)
+	response, err := client.invokeModel(ctx, &client.config, reqBody)
 	if err != nil {
 		return types.Message{}, err
 	}
-	
+
 	// 解析响应
-	return c.parseResponse(&config, response)
+	return client.parseResponse(&client.config, response)
 }
 
-// Stream 流式生成响应
-func (c *BedrockClient) Stream(ctx context.Context, messages []types.Message, opts ...Option) (<-chan types.StreamEvent, error) {
-	// 应用选项
-	config := c.config
-	for _, opt := range opts {
-		opt(&config)
+// Stream 实现 Runnable 接口，流式生成响应
+//
+// 文本增量以 EventStream 事件返回，EventEnd 事件携带完整消息（包括工具调用）。
+//
+func (c *BedrockClient) Stream(ctx context.Context, messages []types.Message, opts ...runnable.Option) (<-chan runnable.StreamEvent[types.Message], error) {
+	return chat.TraceStream(ctx, c, messages, opts, func(ctx context.Context) (<-chan runnable.StreamEvent[types.Message], error) {
+		return c.stream(ctx, messages, opts...)
+	})
+}
+
+// stream 执行流式调用。
+func (c *BedrockClient) stream(ctx context.Context, messages []types.Message, opts ...runnable.Option) (<-chan runnable.StreamEvent[types.Message], error) {
+	if err := chat.ValidateMessages(messages); err != nil {
+		return nil, err
 	}
-	
+
 	// 构建请求
-	reqBody, err := c.buildRequest(&config, messages)
+	client := c.withCallOptions(runnable.NewOptions(opts...))
+	reqBody, err := client.buildRequest(&client.config, messages)
 	if err != nil {
 		return nil, fmt.Errorf("bedrock: failed to build request: %w", err)
 	}
-	
+
 	// 创建流式响应通道
-	eventChan := make(chan types.StreamEvent, 10)
-	
+	out := make(chan runnable.StreamEvent[types.Message], 10)
+
 	go func() {
-		defer close(eventChan)
-		
-		if err := c.invokeModelStream(ctx, &config, reqBody, eventChan); err != nil {
-			eventChan <- types.StreamEvent{
+		defer close(out)
+
+		out <- runnable.StreamEvent[types.Message]{
+			Type: runnable.EventStart,
+			Name: c.GetName(),
+		}
+
+		if err := client.invokeModelStream(ctx, &client.config, reqBody, out); err != nil {
+			out <- runnable.StreamEvent[types.Message]{
+				Type:  runnable.EventError,
 				Error: err,
 			}
 		}
 	}()
-	
-	return eventChan, nil
+
+	// 在图等外层执行器中运行时，把 token 事件转发给外层
+	return runnable.ForwardStream(ctx, out), nil
 }
 
-// Batch 批量处理消息
-func (c *BedrockClient) Batch(ctx context.Context, messagesList [][]types.Message, opts ...Option) ([]types.Message, error) {
+// Batch 实现 Runnable 接口，并行处理多组消息
+func (c *BedrockClient) Batch(ctx context.Context, messagesList [][]types.Message, opts ...runnable.Option) ([]types.Message, error) {
+	if len(messagesList) == 0 {
+		return []types.Message{}, nil
+	}
+
 	results := make([]types.Message, len(messagesList))
-	
+	errs := make([]error, len(messagesList))
+
+	done := make(chan struct{}, len(messagesList))
 	for i, messages := range messagesList {
-		result, err := c.Invoke(ctx, messages, opts...)
+		go func(idx int, msgs []types.Message) {
+			results[idx], errs[idx] = c.Invoke(ctx, msgs, opts...)
+			done <- struct{}{}
+		}(i, messages)
+	}
+	for range messagesList {
+		<-done
+	}
+
+	for i, err := range errs {
 		if err != nil {
 			return nil, fmt.Errorf("bedrock: batch request %d failed: %w", i, err)
 		}
-		results[i] = result
 	}
-	
+
 	return results, nil
 }
 
+// BindTools 实现 ChatModel 接口，绑定工具
+//
+// 原生 API 仅 Anthropic 模型支持工具调用；启用 UseConverse 后其他模型也可以调用工具。
+//
+func (c *BedrockClient) BindTools(tools []types.Tool) chat.ChatModel {
+	newClient := c.clone()
+	newClient.SetBoundTools(tools)
+	return newClient
+}
+
+// WithStructuredOutput 实现 ChatModel 接口，配置结构化输出
+//
+// Anthropic 模型通过强制调用 structured_output 工具实现，响应的 Content 为
+// 符合 Schema 的 JSON；其他模型在提示词中附加 Schema 说明。
+//
+func (c *BedrockClient) WithStructuredOutput(schema types.Schema) chat.ChatModel {
+	newClient := c.clone()
+	newClient.SetOutputSchema(schema)
+	return newClient
+}
+
+// WithConfig 实现 Runnable 接口
+func (c *BedrockClient) WithConfig(config *types.Config) runnable.Runnable[[]types.Message, types.Message] {
+	newClient := c.clone()
+	newClient.SetConfig(config)
+	return newClient
+}
+
+// WithRetry 实现 Runnable 接口
+func (c *BedrockClient) WithRetry(policy types.RetryPolicy) runnable.Runnable[[]types.Message, types.Message] {
+	return runnable.NewRetryRunnable[[]types.Message, types.Message](c, policy)
+}
+
+// WithFallbacks 实现 Runnable 接口
+func (c *BedrockClient) WithFallbacks(fallbacks ...runnable.Runnable[[]types.Message, types.Message]) runnable.Runnable[[]types.Message, types.Message] {
+	return runnable.NewFallbackRunnable[[]types.Message, types.Message](c, fallbacks)
+}
+
+// WithOptions 返回应用了选项的新客户端
+//
+// 绑定的工具和结构化输出配置会保留。
+//
+// 示例：
+//
+//	precise := client.WithOptions(bedrock.WithTemperature(0.1), bedrock.WithMaxTokens(500))
+//	response, _ := precise.Invoke(ctx, messages)
+//
+func (c *BedrockClient) WithOptions(opts ...Option) *BedrockClient {
+	newClient := c.clone()
+	for _, opt := range opts {
+		opt(&newClient.config)
+	}
+	return newClient
+}
+
 // ==================== 内部方法 ====================
 
+// clone 复制客户端（包括工具、结构化输出和运行时配置）
+func (c *BedrockClient) clone() *BedrockClient {
+	newClient := &BedrockClient{
+		BaseChatModel: chat.NewBaseChatModel(c.config.Model, "bedrock"),
+		config:        c.config,
+		httpClient:    c.httpClient,
+	}
+	newClient.SetConfig(c.GetConfig())
+	newClient.SetBoundTools(c.GetBoundTools())
+	if schema := c.GetOutputSchema(); schema != nil {
+		newClient.SetOutputSchema(*schema)
+	}
+	return newClient
+}
+
+// withCallOptions 返回应用了单次调用参数的客户端
+//
+// 温度、最大 token 数和停止序列覆盖配置；JSON 响应格式通过结构化输出实现
+// （json_object 使用任意对象的 Schema），text 格式清除结构化输出；工具选择
+// 在构建请求时使用。Bedrock 不支持随机种子，Seed 会被忽略。
+//
+func (c *BedrockClient) withCallOptions(options *runnable.Options) *BedrockClient {
+	newClient := &BedrockClient{
+		BaseChatModel:     chat.NewBaseChatModel(c.config.Model, "bedrock"),
+		config:            c.config,
+		httpClient:        c.httpClient,
+		toolChoice:        options.ToolChoice,
+		parallelToolCalls: options.ParallelToolCalls,
+	}
+	newClient.SetConfig(c.GetConfig())
+	newClient.SetBoundTools(c.GetBoundTools())
+
+	if options.Temperature != nil {
+		newClient.config.Temperature = float32(*options.Temperature)
+	}
+	if options.MaxTokens > 0 {
+		newClient.config.MaxTokens = options.MaxTokens
+	}
+	if len(options.Stop) > 0 {
+		newClient.config.StopSequences = options.Stop
+	}
+
+	switch format := options.ResponseFormat; {
+	case format == nil:
+		if schema := c.GetOutputSchema(); schema != nil {
+			newClient.SetOutputSchema(*schema)
+		}
+	case format.Type == types.ResponseFormatJSONSchema && format.Schema != nil:
+		newClient.SetOutputSchema(*format.Schema)
+	case format.IsJSON():
+		newClient.SetOutputSchema(types.Schema{Type: "object"})
+	}
+
+	return newClient
+}
+
 func (c *BedrockClient) buildRequest(config *Config, messages []types.Message) (map[string]interface{}, error) {
+	if config.UseConverse {
+		return c.buildConverseRequest(config, messages)
+	}
+
 	// 根据模型提供商构建不同格式的请求
 	if isAnthropicModel(config.Model) {
 		return c.buildAnthropicRequest(config, messages)
-	} else if isTitanModel(config.Model) {
+	}
+
+	if len(c.GetBoundTools()) > 0 {
+		return nil, fmt.Errorf("model %s does not support tool calling, enable UseConverse", config.Model)
+	}
+
+	// 其他模型通过提示词约束结构化输出
+	if schema := c.GetOutputSchema(); schema != nil {
+		messages = withSchemaInstruction(messages, *schema)
+	}
+
+	if isTitanModel(config.Model) {
 		return c.buildTitanRequest(config, messages)
 	} else if isLlamaModel(config.Model) {
 		return c.buildLlamaRequest(config, messages)
 	}
-	
+
 	// 默认使用 Anthropic 格式
 	return c.buildAnthropicRequest(config, messages)
 }
 
 func (c *BedrockClient) buildAnthropicRequest(config *Config, messages []types.Message) (map[string]interface{}, error) {
-	// 转换消息格式
-	anthropicMessages := make([]map[string]interface{}, 0, len(messages))
-	var systemPrompt string
-	
-	for _, msg := range messages {
-		if msg.Role == types.RoleSystem {
-			systemPrompt = msg.Content
-			continue
-		}
-		
-		role := "user"
-		if msg.Role == types.RoleAssistant {
-			role = "assistant"
-		}
-		
-		anthropicMessages = append(anthropicMessages, map[string]interface{}{
-			"role":    role,
-			"content": msg.Content,
-		})
+	// 转换消息格式（工具调用为 tool_use，工具结果为 tool_result）
+	systemPrompt, anthropicMessages, err := chat.MessagesToAnthropic(messages)
+	if err != nil {
+		return nil, err
 	}
-	
+
 	reqBody := map[string]interface{}{
 		"messages":    anthropicMessages,
 		"max_tokens":  config.MaxTokens,
 		"temperature": config.Temperature,
 		"top_p":       config.TopP,
 	}
-	
-	if systemPrompt != "" {
+
+	if chat.HasSystemCacheControl(messages) {
+		reqBody["system"] = chat.SystemToAnthropic(messages)
+	} else if systemPrompt != "" {
 		reqBody["system"] = systemPrompt
 	}
-	
+
 	if len(config.StopSequences) > 0 {
 		reqBody["stop_sequences"] = config.StopSequences
 	}
-	
+
+	// 添加工具
+	tools := chat.ConvertToolsToAnthropic(c.GetBoundTools())
+
+	// 结构化输出：强制调用以 Schema 为参数的工具
+	if schema := c.GetOutputSchema(); schema != nil {
+		tools = append(tools, map[string]any{
+			"name":         structuredOutputTool,
+			"description":  "Respond with a JSON object matching the schema.",
+			"input_schema": schema.ToMap(),
+		})
+		reqBody["tool_choice"] = map[string]any{
+			"type": "tool",
+			"name": structuredOutputTool,
+		}
+	} else if len(tools) > 0 && c.toolChoice != nil {
+		reqBody["tool_choice"] = c.toolChoice.ToAnthropic(c.parallelToolCalls)
+	} else if len(tools) > 0 && c.parallelToolCalls != nil {
+		reqBody["tool_choice"] = types.NewToolChoice(types.ToolChoiceAuto).ToAnthropic(c.parallelToolCalls)
+	}
+
+	if len(tools) > 0 {
+		reqBody["tools"] = tools
+	}
+
 	// Anthropic 格式需要 anthropic_version
 	reqBody["anthropic_version"] = "bedrock-2023-05-31"
-	
+
 	return reqBody, nil
 }
 
@@ -256,14 +480,14 @@ func (c *BedrockClient) buildTitanRequest(config *Config, messages []types.Messa
 		promptBuilder.WriteString(msg.Content)
 		promptBuilder.WriteString("\n")
 	}
-	
+
 	return map[string]interface{}{
 		"inputText": promptBuilder.String(),
 		"textGenerationConfig": map[string]interface{}{
-			"temperature":    config.Temperature,
-			"topP":           config.TopP,
-			"maxTokenCount":  config.MaxTokens,
-			"stopSequences":  config.StopSequences,
+			"temperature":   config.Temperature,
+			"topP":          config.TopP,
+			"maxTokenCount": config.MaxTokens,
+			"stopSequences": config.StopSequences,
 		},
 	}, nil
 }
@@ -286,7 +510,7 @@ func (c *BedrockClient) buildLlamaRequest(config *Config, messages []types.Messa
 			promptBuilder.WriteString(" ")
 		}
 	}
-	
+
 	return map[string]interface{}{
 		"prompt":      promptBuilder.String(),
 		"temperature": config.Temperature,
@@ -295,121 +519,277 @@ func (c *BedrockClient) buildLlamaRequest(config *Config, messages []types.Messa
 	}, nil
 }
 
+// withSchemaInstruction 在消息前加入要求按 Schema 输出 JSON 的系统提示
+func withSchemaInstruction(messages []types.Message, schema types.Schema) []types.Message {
+	schemaJSON, _ := json.Marshal(schema)
+	instruction := types.NewSystemMessage(fmt.Sprintf(
+		"Respond only with a JSON object that matches this JSON Schema:\n%s", schemaJSON))
+	return append([]types.Message{instruction}, messages...)
+}
+
 func (c *BedrockClient) invokeModel(ctx context.Context, config *Config, reqBody map[string]interface{}) (map[string]interface{}, error) {
-	bodyBytes, err := json.Marshal(reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("bedrock: failed to marshal request: %w", err)
+	action := "invoke"
+	if config.UseConverse {
+		action = "converse"
 	}
-	
-	// 构建 Bedrock Runtime API 端点
-	url := fmt.Sprintf("https://bedrock-runtime.%s.amazonaws.com/model/%s/invoke",
-		config.Region, config.Model)
-	
-	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(bodyBytes))
-	if err != nil {
-		return nil, fmt.Errorf("bedrock: failed to create request: %w", err)
-	}
-	
-	// 设置请求头
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json")
-	
-	// 签名请求（AWS Signature V4）
-	if err := c.signRequest(req, bodyBytes, config); err != nil {
-		return nil, fmt.Errorf("bedrock: failed to sign request: %w", err)
-	}
-	
-	resp, err := c.httpClient.Do(req)
+
+	resp, err := c.post(ctx, config, action, "application/json", reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("bedrock: request failed: %w", err)
+		return nil, err
 	}
 	defer resp.Body.Close()
-	
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("bedrock: API error (status %d): %s", resp.StatusCode, string(body))
-	}
-	
+
 	var response map[string]interface{}
 	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
 		return nil, fmt.Errorf("bedrock: failed to decode response: %w", err)
 	}
-	
+
 	return response, nil
 }
 
-func (c *BedrockClient) invokeModelStream(ctx context.Context, config *Config, reqBody map[string]interface{}, eventChan chan<- types.StreamEvent) error {
-	// Bedrock 流式 API 实现
-	// 注意：流式 API 需要特殊的事件流处理
-	// 这里提供基础实现框架
-	
+func (c *BedrockClient) invokeModelStream(ctx context.Context, config *Config, reqBody map[string]interface{}, out chan<- runnable.StreamEvent[types.Message]) error {
+	action := "invoke-with-response-stream"
+	if config.UseConverse {
+		action = "converse-stream"
+	}
+
+	resp, err := c.post(ctx, config, action, "application/vnd.amazon.eventstream", reqBody)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return c.parseEventStream(resp.Body, out)
+}
+
+// post 签名并发送请求到 /model/{modelId}/{action}
+func (c *BedrockClient) post(ctx context.Context, config *Config, action, accept string, reqBody map[string]interface{}) (*http.Response, error) {
 	bodyBytes, err := json.Marshal(reqBody)
 	if err != nil {
-		return fmt.Errorf("bedrock: failed to marshal request: %w", err)
+		return nil, fmt.Errorf("bedrock: failed to marshal request: %w", err)
+	}
+
+	endpoint := config.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://bedrock-runtime.%s.amazonaws.com", config.Region)
 	}
-	
-	url := fmt.Sprintf("https://bedrock-runtime.%s.amazonaws.com/model/%s/invoke-with-response-stream",
-		config.Region, config.Model)
-	
+
+	// 模型 ID 可能包含 ":"（版本）或 "/"（ARN），需要整体编码为一个路径段
+	url := fmt.Sprintf("%s/model/%s/%s", strings.TrimSuffix(endpoint, "/"), escapeURI(config.Model, true), action)
+
 	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(bodyBytes))
 	if err != nil {
-		return fmt.Errorf("bedrock: failed to create request: %w", err)
+		return nil, fmt.Errorf("bedrock: failed to create request: %w", err)
 	}
-	
+
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/vnd.amazon.eventstream")
-	
-	// 签名请求
+	req.Header.Set("Accept", accept)
+
 	if err := c.signRequest(req, bodyBytes, config); err != nil {
-		return fmt.Errorf("bedrock: failed to sign request: %w", err)
+		return nil, fmt.Errorf("bedrock: failed to sign request: %w", err)
 	}
-	
+
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("bedrock: request failed: %w", err)
+		return nil, chaterrors.FromTransport(c.GetProvider(), fmt.Errorf("bedrock: request failed: %w", err))
 	}
-	defer resp.Body.Close()
-	
+
 	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("bedrock: API error (status %d): %s", resp.StatusCode, string(body))
-	}
-	
-	// 解析事件流
-	// AWS Event Stream 格式比较复杂，这里提供简化版本
-	// 实际使用时建议使用 AWS SDK
-	return c.parseEventStream(resp.Body, eventChan)
-}
-
-func (c *BedrockClient) parseEventStream(reader io.Reader, eventChan chan<- types.StreamEvent) error {
-	// 简化的事件流解析
-	// 实际实现需要处理 AWS Event Stream 二进制格式
-	decoder := json.NewDecoder(reader)
-	
-	for {
-		var event map[string]interface{}
-		if err := decoder.Decode(&event); err != nil {
-			if err == io.EOF {
-				break
+		return nil, chaterrors.FromResponse(c.GetProvider(), resp, body)
+	}
+
+	return resp, nil
+}
+
+// parseEventStream 解析 application/vnd.amazon.eventstream 响应
+//
+// InvokeModelWithResponseStream 的事件类型为 chunk，消息体为 {"bytes": "<base64>"}；
+// ConverseStream 的事件类型为 contentBlockDelta 等，消息体即为事件内容。
+//
+func (c *BedrockClient) parseEventStream(reader io.Reader, out chan<- runnable.StreamEvent[types.Message]) error {
+	acc := newStreamAccumulator()
+
+	err := readEventStream(reader, func(eventType string, payload []byte) error {
+		var text string
+
+		if eventType == "chunk" {
+			var event map[string]interface{}
+			if err := json.Unmarshal(payload, &event); err != nil {
+				return fmt.Errorf("bedrock: invalid chunk event: %w", err)
+			}
+			chunk, err := decodeChunk(event)
+			if err != nil {
+				return err
+			}
+			text = acc.add(chunk)
+		} else {
+			var err error
+			if text, err = acc.addConverse(eventType, payload); err != nil {
+				return err
 			}
-			return err
 		}
-		
-		// 提取文本内容
-		if completion, ok := event["completion"].(string); ok {
-			eventChan <- types.StreamEvent{
+
+		if text != "" {
+			out <- runnable.StreamEvent[types.Message]{
+				Type: runnable.EventStream,
 				Data: types.Message{
 					Role:    types.RoleAssistant,
-					Content: completion,
+					Content: text,
 				},
+				Name: c.GetName(),
 			}
 		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	out <- runnable.StreamEvent[types.Message]{
+		Type: runnable.EventEnd,
+		Data: c.structuredResult(acc.message()),
+		Name: c.GetName(),
 	}
-	
+
 	return nil
 }
 
+// decodeChunk 解析流式响应的数据块
+//
+// Bedrock 的数据块形如 {"bytes": "<base64 编码的模型事件>"}，没有 bytes 字段时
+// 数据块本身就是模型事件。
+//
+func decodeChunk(event map[string]interface{}) (map[string]interface{}, error) {
+	encoded, ok := event["bytes"].(string)
+	if !ok {
+		return event, nil
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("bedrock: invalid chunk encoding: %w", err)
+	}
+
+	var chunk map[string]interface{}
+	if err := json.Unmarshal(raw, &chunk); err != nil {
+		return nil, fmt.Errorf("bedrock: invalid chunk: %w", err)
+	}
+	return chunk, nil
+}
+
+// streamAccumulator 累积流式响应中的文本、工具调用、用量和结束原因
+type streamAccumulator struct {
+	content      bytes.Buffer
+	toolCalls    []types.ToolCall
+	usage        *types.UsageMetadata
+	finishReason string
+
+	// blocks 内容块索引 -> 工具调用索引
+	blocks map[int]int
+}
+
+func newStreamAccumulator() *streamAccumulator {
+	return &streamAccumulator{blocks: make(map[int]int)}
+}
+
+// add 处理一个模型事件，返回新增的文本
+func (a *streamAccumulator) add(chunk map[string]interface{}) string {
+	var text string
+
+	// Bedrock 在最后一个数据块中附加调用指标（所有模型通用）
+	if metrics, ok := chunk["amazon-bedrock-invocationMetrics"].(map[string]interface{}); ok && a.usage == nil {
+		a.usage = types.NewUsageMetadata(intValue(metrics["inputTokenCount"]), intValue(metrics["outputTokenCount"]))
+	}
+
+	switch chunk["type"] {
+	case "message_start":
+		// Claude Messages API：输入 token 用量
+		message, _ := chunk["message"].(map[string]interface{})
+		if usage, ok := message["usage"].(map[string]interface{}); ok {
+			a.usage = anthropicUsage(usage)
+		}
+
+	case "message_delta":
+		// Claude Messages API：结束原因和累计输出 token 数
+		delta, _ := chunk["delta"].(map[string]interface{})
+		a.finishReason, _ = delta["stop_reason"].(string)
+		if usage, ok := chunk["usage"].(map[string]interface{}); ok && a.usage != nil {
+			a.usage.OutputTokens = intValue(usage["output_tokens"])
+			a.usage.TotalTokens = a.usage.InputTokens + a.usage.OutputTokens
+		}
+
+	case "content_block_start":
+		// Claude Messages API：工具调用开始
+		block, _ := chunk["content_block"].(map[string]interface{})
+		if block["type"] == "tool_use" {
+			index, _ := chunk["index"].(float64)
+			id, _ := block["id"].(string)
+			name, _ := block["name"].(string)
+			a.blocks[int(index)] = len(a.toolCalls)
+			a.toolCalls = append(a.toolCalls, types.ToolCall{
+				ID:       id,
+				Type:     "function",
+				Function: types.FunctionCall{Name: name},
+			})
+		}
+
+	case "content_block_delta":
+		delta, _ := chunk["delta"].(map[string]interface{})
+		switch delta["type"] {
+		case "text_delta":
+			text, _ = delta["text"].(string)
+		case "input_json_delta":
+			index, _ := chunk["index"].(float64)
+			if i, ok := a.blocks[int(index)]; ok {
+				partial, _ := delta["partial_json"].(string)
+				a.toolCalls[i].Function.Arguments += partial
+			}
+		}
+
+	default:
+		// Claude Text Completions、Titan 和 Llama
+		for _, key := range []string{"completion", "outputText", "generation"} {
+			if s, ok := chunk[key].(string); ok {
+				text = s
+				break
+			}
+		}
+		for _, key := range []string{"stop_reason", "completionReason"} {
+			if s, ok := chunk[key].(string); ok && s != "" {
+				a.finishReason = s
+			}
+		}
+	}
+
+	a.content.WriteString(text)
+	return text
+}
+
+// message 返回累积的完整消息
+func (a *streamAccumulator) message() types.Message {
+	for i := range a.toolCalls {
+		// 无参数的工具调用没有 input_json_delta
+		if a.toolCalls[i].Function.Arguments == "" {
+			a.toolCalls[i].Function.Arguments = "{}"
+		}
+	}
+
+	return types.Message{
+		Role:          types.RoleAssistant,
+		Content:       a.content.String(),
+		ToolCalls:     a.toolCalls,
+		UsageMetadata: a.usage,
+		FinishReason:  chat.NormalizeFinishReason(a.finishReason),
+	}
+}
+
 func (c *BedrockClient) parseResponse(config *Config, response map[string]interface{}) (types.Message, error) {
+	if config.UseConverse {
+		return c.parseConverseResponse(response)
+	}
+
 	// 根据模型类型解析不同格式的响应
 	if isAnthropicModel(config.Model) {
 		return c.parseAnthropicResponse(response)
@@ -418,31 +798,49 @@ func (c *BedrockClient) parseResponse(config *Config, response map[string]interf
 	} else if isLlamaModel(config.Model) {
 		return c.parseLlamaResponse(response)
 	}
-	
+
 	return c.parseAnthropicResponse(response)
 }
 
 func (c *BedrockClient) parseAnthropicResponse(response map[string]interface{}) (types.Message, error) {
-	// Anthropic 响应格式
+	// Anthropic 响应格式（text 和 tool_use 内容块）
 	content, ok := response["content"].([]interface{})
 	if !ok || len(content) == 0 {
 		return types.Message{}, fmt.Errorf("bedrock: invalid response format")
 	}
-	
-	firstContent, ok := content[0].(map[string]interface{})
-	if !ok {
-		return types.Message{}, fmt.Errorf("bedrock: invalid content format")
+
+	message, err := chat.AnthropicResponseToMessage(content)
+	if err != nil {
+		return types.Message{}, fmt.Errorf("bedrock: %w", err)
 	}
-	
-	text, ok := firstContent["text"].(string)
-	if !ok {
-		return types.Message{}, fmt.Errorf("bedrock: no text in response")
+
+	stopReason, _ := response["stop_reason"].(string)
+	message.FinishReason = chat.NormalizeFinishReason(stopReason)
+	if usage, ok := response["usage"].(map[string]interface{}); ok {
+		message.UsageMetadata = anthropicUsage(usage)
 	}
-	
-	return types.Message{
-		Role:    types.RoleAssistant,
-		Content: text,
-	}, nil
+
+	return c.structuredResult(message), nil
+}
+
+// structuredResult 将 structured_output 工具调用的参数作为消息内容返回
+func (c *BedrockClient) structuredResult(message types.Message) types.Message {
+	if c.GetOutputSchema() == nil {
+		return message
+	}
+
+	for i, tc := range message.ToolCalls {
+		if tc.Function.Name == structuredOutputTool {
+			message.Content = tc.Function.Arguments
+			message.ToolCalls = append(message.ToolCalls[:i:i], message.ToolCalls[i+1:]...)
+			if len(message.ToolCalls) == 0 {
+				message.ToolCalls = nil
+			}
+			break
+		}
+	}
+
+	return message
 }
 
 func (c *BedrockClient) parseTitanResponse(response map[string]interface{}) (types.Message, error) {
@@ -450,20 +848,24 @@ func (c *BedrockClient) parseTitanResponse(response map[string]interface{}) (typ
 	if !ok || len(results) == 0 {
 		return types.Message{}, fmt.Errorf("bedrock: invalid Titan response")
 	}
-	
+
 	firstResult, ok := results[0].(map[string]interface{})
 	if !ok {
 		return types.Message{}, fmt.Errorf("bedrock: invalid Titan result")
 	}
-	
+
 	text, ok := firstResult["outputText"].(string)
 	if !ok {
 		return types.Message{}, fmt.Errorf("bedrock: no output text in Titan response")
 	}
-	
+
+	completionReason, _ := firstResult["completionReason"].(string)
+
 	return types.Message{
-		Role:    types.RoleAssistant,
-		Content: text,
+		Role:          types.RoleAssistant,
+		Content:       text,
+		FinishReason:  chat.NormalizeFinishReason(completionReason),
+		UsageMetadata: types.NewUsageMetadata(intValue(response["inputTextTokenCount"]), intValue(firstResult["tokenCount"])),
 	}, nil
 }
 
@@ -472,26 +874,52 @@ func (c *BedrockClient) parseLlamaResponse(response map[string]interface{}) (typ
 	if !ok {
 		return types.Message{}, fmt.Errorf("bedrock: invalid Llama response")
 	}
-	
+
+	stopReason, _ := response["stop_reason"].(string)
+
 	return types.Message{
-		Role:    types.RoleAssistant,
-		Content: generation,
+		Role:          types.RoleAssistant,
+		Content:       generation,
+		FinishReason:  chat.NormalizeFinishReason(stopReason),
+		UsageMetadata: types.NewUsageMetadata(intValue(response["prompt_token_count"]), intValue(response["generation_token_count"])),
 	}, nil
 }
 
+// signRequest 使用 AWS Signature V4 签名请求
 func (c *BedrockClient) signRequest(req *http.Request, body []byte, config *Config) error {
-	// AWS Signature V4 签名
-	// 注意：这是简化实现，实际使用建议使用 AWS SDK
-	// 完整的签名算法参考：https://docs.aws.amazon.com/general/latest/gr/signature-version-4.html
-	
-	// 这里提供基础框架，实际实现需要完整的签名逻辑
-	// 或者使用 github.com/aws/aws-sdk-go-v2
-	
-	return fmt.Errorf("bedrock: AWS Signature V4 implementation required - please use AWS SDK")
+	creds, err := config.Credentials.Retrieve(req.Context())
+	if err != nil {
+		return err
+	}
+
+	signer := sigV4Signer{service: "bedrock", region: config.Region}
+	signer.sign(req, body, creds, time.Now())
+	return nil
 }
 
 // ==================== 辅助函数 ====================
 
+// anthropicUsage 解析 Claude 的 usage（input_tokens 不包含缓存读取和写入的 token）
+func anthropicUsage(usage map[string]interface{}) *types.UsageMetadata {
+	cacheRead := intValue(usage["cache_read_input_tokens"])
+	cacheWrite := intValue(usage["cache_creation_input_tokens"])
+	input := intValue(usage["input_tokens"]) + cacheWrite + cacheRead
+	output := intValue(usage["output_tokens"])
+	return &types.UsageMetadata{
+		InputTokens:         input,
+		OutputTokens:        output,
+		TotalTokens:         input + output,
+		CachedTokens:        cacheRead,
+		CacheCreationTokens: cacheWrite,
+	}
+}
+
+// intValue 将 JSON 数字转换为 int
+func intValue(v interface{}) int {
+	f, _ := v.(float64)
+	return int(f)
+}
+
 func isAnthropicModel(model string) bool {
 	return len(model) >= 10 && model[:10] == "anthropic."
 }
@@ -506,7 +934,7 @@ func isLlamaModel(model string) bool {
 
 // ==================== 选项模式 ====================
 
-// Option 配置选项
+// Option 配置选项，通过 WithOptions 应用
 type Option func(*Config)
 
 // WithTemperature 设置温度
@@ -1,11 +1,50 @@
 package bedrock
 
 import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/zhucl121/langchain-go/core/chat"
+	"github.com/zhucl121/langchain-go/core/runnable"
 	"github.com/zhucl121/langchain-go/pkg/types"
 )
 
+// newTestClient 创建测试用客户端
+func newTestClient(t *testing.T, model string) *BedrockClient {
+	client, err := New(Config{
+		Region:    "us-east-1",
+		AccessKey: "test",
+		SecretKey: "test",
+		Model:     model,
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	return client
+}
+
+// weatherTool 测试用工具
+var weatherTool = types.Tool{
+	Name:        "get_weather",
+	Description: "Get the weather for a city",
+	Parameters: types.Schema{
+		Type: "object",
+		Properties: map[string]types.Schema{
+			"city": {Type: "string"},
+		},
+		Required: []string{"city"},
+	},
+}
+
 func TestNew(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -76,6 +115,29 @@ func TestNew(t *testing.T) {
 	}
 }
 
+func TestNew_CredentialChain(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	t.Setenv("AWS_ACCESS_KEY", "")
+	t.Setenv("AWS_SECRET_KEY", "")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(t.TempDir(), "missing"))
+
+	if _, err := New(Config{Region: "us-east-1"}); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("expected ErrNoCredentials, got %v", err)
+	}
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDENV")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "env-secret")
+	client, err := New(Config{Region: "us-east-1"})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	creds, _ := client.config.Credentials.Retrieve(context.Background())
+	if creds.AccessKeyID != "AKIDENV" {
+		t.Errorf("unexpected credentials: %+v", creds)
+	}
+}
+
 func TestDefaultConfig(t *testing.T) {
 	config := DefaultConfig()
 
@@ -122,14 +184,7 @@ func TestModelDetection(t *testing.T) {
 }
 
 func TestBuildAnthropicRequest(t *testing.T) {
-	client := &BedrockClient{
-		config: Config{
-			Region:    "us-east-1",
-			AccessKey: "test",
-			SecretKey: "test",
-			Model:     "anthropic.claude-v2",
-		},
-	}
+	client := newTestClient(t, "anthropic.claude-v2")
 
 	messages := []types.Message{
 		{Role: types.RoleSystem, Content: "You are helpful"},
@@ -168,14 +223,7 @@ func TestBuildAnthropicRequest(t *testing.T) {
 }
 
 func TestBuildTitanRequest(t *testing.T) {
-	client := &BedrockClient{
-		config: Config{
-			Region:    "us-east-1",
-			AccessKey: "test",
-			SecretKey: "test",
-			Model:     "amazon.titan-text-v1",
-		},
-	}
+	client := newTestClient(t, "amazon.titan-text-v1")
 
 	messages := []types.Message{
 		{Role: types.RoleUser, Content: "Hello"},
@@ -196,14 +244,7 @@ func TestBuildTitanRequest(t *testing.T) {
 }
 
 func TestBuildLlamaRequest(t *testing.T) {
-	client := &BedrockClient{
-		config: Config{
-			Region:    "us-east-1",
-			AccessKey: "test",
-			SecretKey: "test",
-			Model:     "meta.llama2-13b",
-		},
-	}
+	client := newTestClient(t, "meta.llama2-13b")
 
 	messages := []types.Message{
 		{Role: types.RoleSystem, Content: "You are helpful"},
@@ -266,3 +307,473 @@ func TestOptions(t *testing.T) {
 		}
 	})
 }
+
+func TestChatModelInterface(t *testing.T) {
+	var model chat.ChatModel = newTestClient(t, "anthropic.claude-3-haiku-20240307-v1:0")
+
+	if model.GetProvider() != "bedrock" || model.GetModelName() != "anthropic.claude-3-haiku-20240307-v1:0" {
+		t.Errorf("unexpected provider/model: %s/%s", model.GetProvider(), model.GetModelName())
+	}
+
+	bound := model.BindTools([]types.Tool{weatherTool})
+	if len(bound.(*BedrockClient).GetBoundTools()) != 1 || len(model.(*BedrockClient).GetBoundTools()) != 0 {
+		t.Error("BindTools should return a new instance with tools")
+	}
+}
+
+func TestBuildAnthropicRequest_Tools(t *testing.T) {
+	client := newTestClient(t, "anthropic.claude-3-sonnet-20240229-v1:0").BindTools([]types.Tool{weatherTool}).(*BedrockClient)
+
+	messages := []types.Message{
+		types.NewUserMessage("Weather in Paris?"),
+		{Role: types.RoleAssistant, ToolCalls: []types.ToolCall{{
+			ID: "toolu_1", Type: "function",
+			Function: types.FunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`},
+		}}},
+		types.NewToolMessage("toolu_1", "sunny"),
+	}
+
+	reqBody, err := client.buildRequest(&client.config, messages)
+	if err != nil {
+		t.Fatalf("Failed to build request: %v", err)
+	}
+
+	data, _ := json.Marshal(reqBody)
+	for _, want := range []string{
+		`"tools":[{"description":"Get the weather for a city","input_schema"`,
+		`{"id":"toolu_1","input":{"city":"Paris"},"name":"get_weather","type":"tool_use"}`,
+		`{"content":"sunny","tool_use_id":"toolu_1","type":"tool_result"}`,
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("expected %s in request:\n%s", want, data)
+		}
+	}
+
+	// 非 Anthropic 模型不支持工具
+	titan := newTestClient(t, "amazon.titan-text-express-v1").BindTools([]types.Tool{weatherTool}).(*BedrockClient)
+	if _, err := titan.buildRequest(&titan.config, messages[:1]); err == nil {
+		t.Error("expected error for tools on Titan")
+	}
+}
+
+func TestParseAnthropicResponse_ToolUse(t *testing.T) {
+	client := newTestClient(t, "anthropic.claude-3-sonnet-20240229-v1:0")
+
+	var response map[string]interface{}
+	json.Unmarshal([]byte(`{"content":[
+		{"type":"text","text":"Let me check."},
+		{"type":"tool_use","id":"toolu_2","name":"get_weather","input":{"city":"Oslo"}}]}`), &response)
+
+	msg, err := client.parseResponse(&client.config, response)
+	if err != nil {
+		t.Fatalf("parseResponse failed: %v", err)
+	}
+	if msg.Content != "Let me check." || len(msg.ToolCalls) != 1 || msg.ToolCalls[0].ID != "toolu_2" ||
+		msg.ToolCalls[0].Function.Arguments != `{"city":"Oslo"}` {
+		t.Errorf("unexpected message: %+v", msg)
+	}
+}
+
+func TestStructuredOutput(t *testing.T) {
+	schema := types.Schema{Type: "object", Properties: map[string]types.Schema{"answer": {Type: "integer"}}}
+	client := newTestClient(t, "anthropic.claude-3-sonnet-20240229-v1:0").WithStructuredOutput(schema).(*BedrockClient)
+
+	reqBody, err := client.buildRequest(&client.config, []types.Message{types.NewUserMessage("?")})
+	if err != nil {
+		t.Fatalf("Failed to build request: %v", err)
+	}
+	if choice, _ := reqBody["tool_choice"].(map[string]any); choice["name"] != structuredOutputTool {
+		t.Errorf("expected forced structured output tool, got %v", reqBody["tool_choice"])
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal([]byte(`{"content":[{"type":"tool_use","id":"toolu_3","name":"structured_output","input":{"answer":42}}]}`), &response)
+	msg, err := client.parseResponse(&client.config, response)
+	if err != nil {
+		t.Fatalf("parseResponse failed: %v", err)
+	}
+	if msg.Content != `{"answer":42}` || len(msg.ToolCalls) != 0 {
+		t.Errorf("expected structured content, got %+v", msg)
+	}
+
+	// 其他模型通过提示词约束
+	llama := newTestClient(t, "meta.llama2-13b").WithStructuredOutput(schema).(*BedrockClient)
+	reqBody, _ = llama.buildRequest(&llama.config, []types.Message{types.NewUserMessage("?")})
+	if !strings.Contains(reqBody["prompt"].(string), "JSON Schema") {
+		t.Errorf("expected schema instruction in prompt: %v", reqBody["prompt"])
+	}
+}
+
+func TestParseEventStream(t *testing.T) {
+	client := newTestClient(t, "anthropic.claude-3-sonnet-20240229-v1:0")
+
+	var stream strings.Builder
+	for _, event := range []string{
+		`{"type":"message_start","message":{"role":"assistant","usage":{"input_tokens":25,"cache_read_input_tokens":100,"output_tokens":1}}}`,
+		`{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+		`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Checking"}}`,
+		`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":" now."}}`,
+		`{"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"toolu_4","name":"get_weather"}}`,
+		`{"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"{\"city\":"}}`,
+		`{"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"\"Oslo\"}"}}`,
+		`{"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":18}}`,
+		`{"type":"message_stop","amazon-bedrock-invocationMetrics":{"inputTokenCount":125,"outputTokenCount":18}}`,
+	} {
+		payload := fmt.Sprintf(`{"bytes":%q}`, base64.StdEncoding.EncodeToString([]byte(event)))
+		stream.Write(encodeEvent(map[string]string{
+			":message-type": "event",
+			":event-type":   "chunk",
+			":content-type": "application/json",
+		}, []byte(payload)))
+	}
+
+	out := make(chan runnable.StreamEvent[types.Message], 20)
+	if err := client.parseEventStream(strings.NewReader(stream.String()), out); err != nil {
+		t.Fatalf("parseEventStream failed: %v", err)
+	}
+	close(out)
+
+	var content strings.Builder
+	var final types.Message
+	for event := range out {
+		switch event.Type {
+		case runnable.EventStream:
+			content.WriteString(event.Data.Content)
+		case runnable.EventEnd:
+			final = event.Data
+		}
+	}
+
+	if content.String() != "Checking now." || final.Content != "Checking now." {
+		t.Errorf("unexpected content: %q / %q", content.String(), final.Content)
+	}
+	if len(final.ToolCalls) != 1 || final.ToolCalls[0].ID != "toolu_4" || final.ToolCalls[0].Function.Arguments != `{"city":"Oslo"}` {
+		t.Errorf("unexpected tool calls: %+v", final.ToolCalls)
+	}
+	want := types.UsageMetadata{InputTokens: 125, OutputTokens: 18, TotalTokens: 143, CachedTokens: 100}
+	if final.FinishReason != types.FinishReasonToolCalls || final.UsageMetadata == nil || *final.UsageMetadata != want {
+		t.Errorf("unexpected finish reason / usage: %q %+v", final.FinishReason, final.UsageMetadata)
+	}
+}
+
+func TestInvoke_InvalidMessages(t *testing.T) {
+	client := newTestClient(t, "anthropic.claude-v2")
+	if _, err := client.Invoke(context.Background(), nil); err == nil {
+		t.Error("expected error for empty messages")
+	}
+}
+
+// newServerClient 创建指向 httptest 服务器的客户端
+func newServerClient(t *testing.T, model string, useConverse bool, handler http.HandlerFunc) *BedrockClient {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := New(Config{
+		Region:       "us-west-2",
+		AccessKey:    "AKIDTEST",
+		SecretKey:    "test-secret",
+		SessionToken: "test-token",
+		Model:        model,
+		MaxTokens:    256,
+		Endpoint:     server.URL,
+		UseConverse:  useConverse,
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	return client
+}
+
+func TestInvoke_Signed(t *testing.T) {
+	client := newServerClient(t, "anthropic.claude-3-haiku-20240307-v1:0", false, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/model/anthropic.claude-3-haiku-20240307-v1%3A0/invoke" {
+			t.Errorf("unexpected path: %s", r.URL.EscapedPath())
+		}
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKIDTEST/") || !strings.Contains(auth, "/us-west-2/bedrock/aws4_request") {
+			t.Errorf("unexpected Authorization: %s", auth)
+		}
+		if r.Header.Get("X-Amz-Security-Token") != "test-token" || r.Header.Get("X-Amz-Date") == "" {
+			t.Errorf("missing signing headers: %v", r.Header)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"content":[{"type":"text","text":"Hello from Bedrock"}],"stop_reason":"end_turn","usage":{"input_tokens":9,"output_tokens":4}}`)
+	})
+
+	response, err := client.Invoke(context.Background(), []types.Message{types.NewUserMessage("Hi")})
+	if err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+	if response.Content != "Hello from Bedrock" {
+		t.Errorf("unexpected content: %q", response.Content)
+	}
+	if response.FinishReason != types.FinishReasonStop || response.UsageMetadata == nil || response.UsageMetadata.TotalTokens != 13 {
+		t.Errorf("unexpected finish reason / usage: %q %+v", response.FinishReason, response.UsageMetadata)
+	}
+}
+
+func TestInvoke_APIError(t *testing.T) {
+	client := newServerClient(t, "anthropic.claude-v2", false, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		io.WriteString(w, `{"message":"The security token included in the request is invalid."}`)
+	})
+
+	_, err := client.Invoke(context.Background(), []types.Message{types.NewUserMessage("Hi")})
+	if err == nil || !strings.Contains(err.Error(), "status 403") {
+		t.Errorf("expected API error, got %v", err)
+	}
+}
+
+func TestConverse_ToolUse(t *testing.T) {
+	client := newServerClient(t, "mistral.mistral-large-2407-v1:0", true, func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/converse") {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+
+		var req map[string]any
+		json.NewDecoder(r.Body).Decode(&req)
+
+		system := req["system"].([]any)
+		if system[0].(map[string]any)["text"] != "Be brief." {
+			t.Errorf("unexpected system: %v", req["system"])
+		}
+		tools := req["toolConfig"].(map[string]any)["tools"].([]any)
+		spec := tools[0].(map[string]any)["toolSpec"].(map[string]any)
+		if spec["name"] != "get_weather" || spec["inputSchema"].(map[string]any)["json"] == nil {
+			t.Errorf("unexpected tool spec: %v", spec)
+		}
+
+		// 工具结果与用户消息合并为一条 user 消息
+		messages := req["messages"].([]any)
+		if len(messages) != 3 {
+			t.Fatalf("expected 3 messages, got %d: %v", len(messages), messages)
+		}
+		toolUse := messages[1].(map[string]any)["content"].([]any)[0].(map[string]any)["toolUse"].(map[string]any)
+		if toolUse["toolUseId"] != "tooluse_1" || toolUse["input"].(map[string]any)["city"] != "Paris" {
+			t.Errorf("unexpected toolUse: %v", toolUse)
+		}
+		toolResult := messages[2].(map[string]any)["content"].([]any)[0].(map[string]any)["toolResult"].(map[string]any)
+		if toolResult["toolUseId"] != "tooluse_1" {
+			t.Errorf("unexpected toolResult: %v", toolResult)
+		}
+
+		io.WriteString(w, `{
+			"output": {"message": {"role": "assistant", "content": [
+				{"text": "Let me check Oslo too."},
+				{"toolUse": {"toolUseId": "tooluse_2", "name": "get_weather", "input": {"city": "Oslo"}}}
+			]}},
+			"stopReason": "tool_use",
+			"usage": {"inputTokens": 30, "outputTokens": 12, "totalTokens": 42}
+		}`)
+	})
+
+	model := client.BindTools([]types.Tool{weatherTool})
+	response, err := model.Invoke(context.Background(), []types.Message{
+		types.NewSystemMessage("Be brief."),
+		types.NewUserMessage("Weather in Paris?"),
+		{
+			Role: types.RoleAssistant,
+			ToolCalls: []types.ToolCall{{
+				ID:       "tooluse_1",
+				Type:     "function",
+				Function: types.FunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`},
+			}},
+		},
+		types.NewToolMessage("tooluse_1", "Sunny, 22C"),
+	})
+	if err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+
+	if response.Content != "Let me check Oslo too." {
+		t.Errorf("unexpected content: %q", response.Content)
+	}
+	if len(response.ToolCalls) != 1 || response.ToolCalls[0].ID != "tooluse_2" || response.ToolCalls[0].Function.Arguments != `{"city":"Oslo"}` {
+		t.Errorf("unexpected tool calls: %+v", response.ToolCalls)
+	}
+	if response.FinishReason != types.FinishReasonToolCalls {
+		t.Errorf("unexpected finish reason: %q", response.FinishReason)
+	}
+	if usage := response.UsageMetadata; usage == nil || usage.InputTokens != 30 || usage.OutputTokens != 12 || usage.TotalTokens != 42 {
+		t.Errorf("unexpected usage: %+v", usage)
+	}
+}
+
+func TestConverse_StructuredOutput(t *testing.T) {
+	schema := types.Schema{Type: "object", Properties: map[string]types.Schema{"answer": {Type: "integer"}}}
+
+	client := newServerClient(t, "meta.llama3-1-70b-instruct-v1:0", true, func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+		json.NewDecoder(r.Body).Decode(&req)
+
+		choice := req["toolConfig"].(map[string]any)["toolChoice"].(map[string]any)["tool"].(map[string]any)
+		if choice["name"] != structuredOutputTool {
+			t.Errorf("unexpected toolChoice: %v", choice)
+		}
+
+		io.WriteString(w, `{"output":{"message":{"role":"assistant","content":[
+			{"toolUse":{"toolUseId":"t1","name":"structured_output","input":{"answer":42}}}
+		]}},"stopReason":"tool_use"}`)
+	})
+
+	response, err := client.WithStructuredOutput(schema).Invoke(context.Background(), []types.Message{types.NewUserMessage("?")})
+	if err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+	if response.Content != `{"answer":42}` || len(response.ToolCalls) != 0 {
+		t.Errorf("unexpected response: %+v", response)
+	}
+}
+
+func TestInvoke_CallOptions(t *testing.T) {
+	t.Run("anthropic", func(t *testing.T) {
+		client := newServerClient(t, "anthropic.claude-3-haiku-20240307-v1:0", false, func(w http.ResponseWriter, r *http.Request) {
+			var req map[string]any
+			json.NewDecoder(r.Body).Decode(&req)
+			if req["temperature"] != float64(0) || req["max_tokens"] != float64(64) {
+				t.Errorf("expected per-call sampling options, got %v", req)
+			}
+			if stop := req["stop_sequences"].([]any); len(stop) != 1 || stop[0] != "END" {
+				t.Errorf("unexpected stop sequences: %v", req["stop_sequences"])
+			}
+			choice := req["tool_choice"].(map[string]any)
+			if choice["type"] != "tool" || choice["name"] != "get_weather" || choice["disable_parallel_tool_use"] != true {
+				t.Errorf("unexpected tool_choice: %v", choice)
+			}
+			io.WriteString(w, `{"content":[{"type":"text","text":"ok"}],"stop_reason":"end_turn"}`)
+		})
+
+		_, err := client.BindTools([]types.Tool{weatherTool}).Invoke(context.Background(), []types.Message{types.NewUserMessage("?")},
+			runnable.WithTemperature(0),
+			runnable.WithMaxTokens(64),
+			runnable.WithStop("END"),
+			runnable.WithToolChoice(types.NewSpecificToolChoice("get_weather")),
+			runnable.WithParallelToolCalls(false))
+		if err != nil {
+			t.Fatalf("Invoke failed: %v", err)
+		}
+		// 单次调用参数不修改客户端配置
+		if client.config.MaxTokens != 256 {
+			t.Errorf("client config was modified: %+v", client.config)
+		}
+	})
+
+	t.Run("converse", func(t *testing.T) {
+		client := newServerClient(t, "meta.llama3-1-70b-instruct-v1:0", true, func(w http.ResponseWriter, r *http.Request) {
+			var req map[string]any
+			json.NewDecoder(r.Body).Decode(&req)
+			if inference := req["inferenceConfig"].(map[string]any); inference["maxTokens"] != float64(64) {
+				t.Errorf("unexpected inferenceConfig: %v", inference)
+			}
+			// 响应格式通过结构化输出工具实现
+			choice := req["toolConfig"].(map[string]any)["toolChoice"].(map[string]any)["tool"].(map[string]any)
+			if choice["name"] != structuredOutputTool {
+				t.Errorf("unexpected toolChoice: %v", choice)
+			}
+			io.WriteString(w, `{"output":{"message":{"role":"assistant","content":[
+				{"toolUse":{"toolUseId":"t1","name":"structured_output","input":{"answer":42}}}
+			]}},"stopReason":"tool_use"}`)
+		})
+
+		response, err := client.Invoke(context.Background(), []types.Message{types.NewUserMessage("?")},
+			runnable.WithMaxTokens(64),
+			runnable.WithResponseFormat(types.NewJSONObjectFormat()))
+		if err != nil {
+			t.Fatalf("Invoke failed: %v", err)
+		}
+		if response.Content != `{"answer":42}` {
+			t.Errorf("unexpected response: %+v", response)
+		}
+	})
+
+	if choice := converseToolChoice(types.NewToolChoice(types.ToolChoiceRequired)); choice["any"] == nil {
+		t.Errorf("expected any tool choice, got %v", choice)
+	}
+	if choice := converseToolChoice(types.NewToolChoice(types.ToolChoiceNone)); choice != nil {
+		t.Errorf("expected no tool choice for none, got %v", choice)
+	}
+}
+
+func TestConverse_Stream(t *testing.T) {
+	client := newServerClient(t, "cohere.command-r-plus-v1:0", true, func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/converse-stream") {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") == "" {
+			t.Error("expected signed request")
+		}
+
+		w.Header().Set("Content-Type", "application/vnd.amazon.eventstream")
+		for _, event := range []struct{ kind, payload string }{
+			{"messageStart", `{"role":"assistant"}`},
+			{"contentBlockDelta", `{"contentBlockIndex":0,"delta":{"text":"Checking"}}`},
+			{"contentBlockDelta", `{"contentBlockIndex":0,"delta":{"text":" the weather."}}`},
+			{"contentBlockStop", `{"contentBlockIndex":0}`},
+			{"contentBlockStart", `{"contentBlockIndex":1,"start":{"toolUse":{"toolUseId":"tooluse_9","name":"get_weather"}}}`},
+			{"contentBlockDelta", `{"contentBlockIndex":1,"delta":{"toolUse":{"input":"{\"city\":"}}}`},
+			{"contentBlockDelta", `{"contentBlockIndex":1,"delta":{"toolUse":{"input":"\"Rome\"}"}}}`},
+			{"contentBlockStop", `{"contentBlockIndex":1}`},
+			{"messageStop", `{"stopReason":"tool_use"}`},
+			{"metadata", `{"usage":{"inputTokens":10,"outputTokens":5,"totalTokens":15}}`},
+		} {
+			w.Write(encodeEvent(map[string]string{
+				":message-type": "event",
+				":event-type":   event.kind,
+				":content-type": "application/json",
+			}, []byte(event.payload)))
+		}
+	})
+
+	stream, err := client.BindTools([]types.Tool{weatherTool}).Stream(context.Background(), []types.Message{types.NewUserMessage("Weather in Rome?")})
+	if err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+
+	var content strings.Builder
+	var final types.Message
+	for event := range stream {
+		switch event.Type {
+		case runnable.EventStream:
+			content.WriteString(event.Data.Content)
+		case runnable.EventEnd:
+			final = event.Data
+		case runnable.EventError:
+			t.Fatalf("stream error: %v", event.Error)
+		}
+	}
+
+	if content.String() != "Checking the weather." || final.Content != "Checking the weather." {
+		t.Errorf("unexpected content: %q / %q", content.String(), final.Content)
+	}
+	if len(final.ToolCalls) != 1 || final.ToolCalls[0].ID != "tooluse_9" || final.ToolCalls[0].Function.Arguments != `{"city":"Rome"}` {
+		t.Errorf("unexpected tool calls: %+v", final.ToolCalls)
+	}
+	if final.FinishReason != types.FinishReasonToolCalls || final.UsageMetadata == nil || final.UsageMetadata.TotalTokens != 15 {
+		t.Errorf("unexpected finish reason / usage: %q %+v", final.FinishReason, final.UsageMetadata)
+	}
+}
+
+func TestStream_Exception(t *testing.T) {
+	client := newServerClient(t, "anthropic.claude-3-haiku-20240307-v1:0", false, func(w http.ResponseWriter, r *http.Request) {
+		w.Write(encodeEvent(map[string]string{
+			":message-type":   "exception",
+			":exception-type": "modelStreamErrorException",
+		}, []byte(`{"message":"model failed"}`)))
+	})
+
+	stream, err := client.Stream(context.Background(), []types.Message{types.NewUserMessage("Hi")})
+	if err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+
+	var streamErr error
+	for event := range stream {
+		if event.Type == runnable.EventError {
+			streamErr = event.Error
+		}
+	}
+	if streamErr == nil || !strings.Contains(streamErr.Error(), "modelStreamErrorException") {
+		t.Errorf("expected stream exception, got %v", streamErr)
+	}
+}
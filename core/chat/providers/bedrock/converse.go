@@ -0,0 +1,314 @@
+package bedrock
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/zhucl121/langchain-go/core/chat"
+	"github.com/zhucl121/langchain-go/pkg/types"
+)
+
+// Converse API
+//
+// Converse 是 Bedrock 与模型无关的统一接口：所有模型使用相同的消息、
+// 工具（toolSpec / toolUse / toolResult）和推理参数格式，
+// 工具调用可用于 Anthropic 以外的模型（如 Mistral、Llama 3.1、Cohere Command R）。
+//
+// 参考：https://docs.aws.amazon.com/bedrock/latest/APIReference/API_runtime_Converse.html
+//
+
+// buildConverseRequest 构建 Converse / ConverseStream 请求
+func (c *BedrockClient) buildConverseRequest(config *Config, messages []types.Message) (map[string]interface{}, error) {
+	system, converseMessages, err := convertConverseMessages(messages)
+	if err != nil {
+		return nil, err
+	}
+
+	inference := map[string]interface{}{
+		"temperature": config.Temperature,
+		"topP":        config.TopP,
+	}
+	if config.MaxTokens > 0 {
+		inference["maxTokens"] = config.MaxTokens
+	}
+	if len(config.StopSequences) > 0 {
+		inference["stopSequences"] = config.StopSequences
+	}
+
+	reqBody := map[string]interface{}{
+		"messages":        converseMessages,
+		"inferenceConfig": inference,
+	}
+	if len(system) > 0 {
+		reqBody["system"] = system
+	}
+
+	tools := make([]map[string]interface{}, 0, len(c.GetBoundTools())+1)
+	for _, tool := range c.GetBoundTools() {
+		tools = append(tools, converseToolSpec(tool.Name, tool.Description, tool.Parameters))
+	}
+
+	toolConfig := map[string]interface{}{}
+
+	// 结构化输出：强制调用以 Schema 为参数的工具
+	if schema := c.GetOutputSchema(); schema != nil {
+		tools = append(tools, converseToolSpec(structuredOutputTool,
+			"Respond with a JSON object matching the schema.", *schema))
+		toolConfig["toolChoice"] = map[string]interface{}{
+			"tool": map[string]interface{}{"name": structuredOutputTool},
+		}
+	} else if c.toolChoice != nil {
+		if choice := converseToolChoice(*c.toolChoice); choice != nil {
+			toolConfig["toolChoice"] = choice
+		}
+	}
+
+	if len(tools) > 0 {
+		toolConfig["tools"] = tools
+		reqBody["toolConfig"] = toolConfig
+	}
+
+	return reqBody, nil
+}
+
+// converseToolChoice 转换工具选择
+//
+// Converse 不支持 none 模式，返回 nil 时不设置 toolChoice。
+//
+func converseToolChoice(choice types.ToolChoice) map[string]interface{} {
+	switch choice.Mode {
+	case types.ToolChoiceAuto:
+		return map[string]interface{}{"auto": map[string]interface{}{}}
+	case types.ToolChoiceRequired:
+		return map[string]interface{}{"any": map[string]interface{}{}}
+	case types.ToolChoiceTool:
+		return map[string]interface{}{"tool": map[string]interface{}{"name": choice.Name}}
+	default:
+		return nil
+	}
+}
+
+// converseToolSpec 转换工具定义为 toolSpec 格式
+func converseToolSpec(name, description string, schema types.Schema) map[string]interface{} {
+	return map[string]interface{}{
+		"toolSpec": map[string]interface{}{
+			"name":        name,
+			"description": description,
+			"inputSchema": map[string]interface{}{"json": schema.ToMap()},
+		},
+	}
+}
+
+// convertConverseMessages 转换消息为 Converse 格式
+//
+// 系统消息放入 system 字段；工具结果作为 user 消息的 toolResult 内容块；
+// Converse 要求 user / assistant 交替出现，相邻的同角色消息会被合并。
+//
+func convertConverseMessages(messages []types.Message) ([]map[string]interface{}, []map[string]interface{}, error) {
+	var system []map[string]interface{}
+	var result []map[string]interface{}
+
+	appendBlocks := func(role string, blocks []map[string]interface{}) {
+		if len(blocks) == 0 {
+			return
+		}
+		if n := len(result); n > 0 && result[n-1]["role"] == role {
+			result[n-1]["content"] = append(result[n-1]["content"].([]map[string]interface{}), blocks...)
+			return
+		}
+		result = append(result, map[string]interface{}{
+			"role":    role,
+			"content": blocks,
+		})
+	}
+
+	for _, msg := range messages {
+		switch msg.Role {
+		case types.RoleSystem:
+			system = append(system, map[string]interface{}{"text": msg.Content})
+
+		case types.RoleUser:
+			appendBlocks("user", []map[string]interface{}{{"text": msg.Content}})
+
+		case types.RoleAssistant:
+			var blocks []map[string]interface{}
+			if msg.Content != "" {
+				blocks = append(blocks, map[string]interface{}{"text": msg.Content})
+			}
+			for _, tc := range msg.ToolCalls {
+				input := map[string]interface{}{}
+				if tc.Function.Arguments != "" {
+					if err := json.Unmarshal([]byte(tc.Function.Arguments), &input); err != nil {
+						return nil, nil, fmt.Errorf("invalid arguments for tool call %s: %w", tc.ID, err)
+					}
+				}
+				blocks = append(blocks, map[string]interface{}{
+					"toolUse": map[string]interface{}{
+						"toolUseId": tc.ID,
+						"name":      tc.Function.Name,
+						"input":     input,
+					},
+				})
+			}
+			appendBlocks("assistant", blocks)
+
+		case types.RoleTool:
+			appendBlocks("user", []map[string]interface{}{{
+				"toolResult": map[string]interface{}{
+					"toolUseId": msg.ToolCallID,
+					"content":   []map[string]interface{}{{"text": msg.Content}},
+					"status":    "success",
+				},
+			}})
+
+		default:
+			return nil, nil, fmt.Errorf("unsupported message role: %s", msg.Role)
+		}
+	}
+
+	if len(result) == 0 {
+		return nil, nil, fmt.Errorf("at least one non-system message is required")
+	}
+
+	return system, result, nil
+}
+
+// parseConverseResponse 解析 Converse 响应
+func (c *BedrockClient) parseConverseResponse(response map[string]interface{}) (types.Message, error) {
+	output, _ := response["output"].(map[string]interface{})
+	message, ok := output["message"].(map[string]interface{})
+	if !ok {
+		return types.Message{}, fmt.Errorf("bedrock: invalid Converse response")
+	}
+
+	content, _ := message["content"].([]interface{})
+
+	var text strings.Builder
+	var toolCalls []types.ToolCall
+	for _, item := range content {
+		block, _ := item.(map[string]interface{})
+
+		if s, ok := block["text"].(string); ok {
+			text.WriteString(s)
+			continue
+		}
+
+		if toolUse, ok := block["toolUse"].(map[string]interface{}); ok {
+			id, _ := toolUse["toolUseId"].(string)
+			name, _ := toolUse["name"].(string)
+			arguments, err := json.Marshal(toolUse["input"])
+			if err != nil {
+				return types.Message{}, fmt.Errorf("bedrock: invalid tool input: %w", err)
+			}
+			if toolUse["input"] == nil {
+				arguments = []byte("{}")
+			}
+			toolCalls = append(toolCalls, types.ToolCall{
+				ID:   id,
+				Type: "function",
+				Function: types.FunctionCall{
+					Name:      name,
+					Arguments: string(arguments),
+				},
+			})
+		}
+	}
+
+	stopReason, _ := response["stopReason"].(string)
+
+	result := types.Message{
+		Role:         types.RoleAssistant,
+		Content:      text.String(),
+		ToolCalls:    toolCalls,
+		FinishReason: chat.NormalizeFinishReason(stopReason),
+	}
+	if usage, ok := response["usage"].(map[string]interface{}); ok {
+		result.UsageMetadata = converseUsage(usage)
+	}
+
+	return c.structuredResult(result), nil
+}
+
+// converseUsage 解析 Converse 的 usage
+//
+// inputTokens 不包含缓存读取和写入的 token（cacheReadInputTokens / cacheWriteInputTokens）。
+//
+func converseUsage(usage map[string]interface{}) *types.UsageMetadata {
+	cacheRead := intValue(usage["cacheReadInputTokens"])
+	cacheWrite := intValue(usage["cacheWriteInputTokens"])
+	input := intValue(usage["inputTokens"]) + cacheWrite + cacheRead
+	output := intValue(usage["outputTokens"])
+	return &types.UsageMetadata{
+		InputTokens:         input,
+		OutputTokens:        output,
+		TotalTokens:         input + output,
+		CachedTokens:        cacheRead,
+		CacheCreationTokens: cacheWrite,
+	}
+}
+
+// addConverse 处理一个 ConverseStream 事件，返回新增的文本
+func (a *streamAccumulator) addConverse(eventType string, payload []byte) (string, error) {
+	var event struct {
+		StopReason        string                 `json:"stopReason"`
+		Usage             map[string]interface{} `json:"usage"`
+		ContentBlockIndex int                    `json:"contentBlockIndex"`
+		Start             struct {
+			ToolUse *struct {
+				ToolUseID string `json:"toolUseId"`
+				Name      string `json:"name"`
+			} `json:"toolUse"`
+		} `json:"start"`
+		Delta struct {
+			Text    string `json:"text"`
+			ToolUse *struct {
+				Input string `json:"input"`
+			} `json:"toolUse"`
+		} `json:"delta"`
+	}
+
+	switch eventType {
+	case "contentBlockStart", "contentBlockDelta", "messageStop", "metadata":
+	default:
+		// messageStart、contentBlockStop
+		return "", nil
+	}
+
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return "", fmt.Errorf("bedrock: invalid %s event: %w", eventType, err)
+	}
+
+	switch eventType {
+	case "messageStop":
+		a.finishReason = event.StopReason
+		return "", nil
+	case "metadata":
+		if event.Usage != nil {
+			a.usage = converseUsage(event.Usage)
+		}
+		return "", nil
+	}
+
+	if eventType == "contentBlockStart" {
+		if toolUse := event.Start.ToolUse; toolUse != nil {
+			a.blocks[event.ContentBlockIndex] = len(a.toolCalls)
+			a.toolCalls = append(a.toolCalls, types.ToolCall{
+				ID:       toolUse.ToolUseID,
+				Type:     "function",
+				Function: types.FunctionCall{Name: toolUse.Name},
+			})
+		}
+		return "", nil
+	}
+
+	if toolUse := event.Delta.ToolUse; toolUse != nil {
+		if i, ok := a.blocks[event.ContentBlockIndex]; ok {
+			a.toolCalls[i].Function.Arguments += toolUse.Input
+		}
+		return "", nil
+	}
+
+	a.content.WriteString(event.Delta.Text)
+	return event.Delta.Text, nil
+}
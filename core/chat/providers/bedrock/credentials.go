@@ -0,0 +1,223 @@
+package bedrock
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNoCredentials 表示凭证链中没有找到可用的 AWS 凭证。
+var ErrNoCredentials = errors.New("bedrock: no AWS credentials found")
+
+// Credentials 是 AWS 访问凭证。
+type Credentials struct {
+	// AccessKeyID 访问密钥 ID
+	AccessKeyID string
+
+	// SecretAccessKey 秘密访问密钥
+	SecretAccessKey string
+
+	// SessionToken 会话令牌（临时凭证，可选）
+	SessionToken string
+
+	// Source 凭证来源（用于调试）
+	Source string
+}
+
+// CredentialsProvider 提供 AWS 凭证。
+//
+// 每次签名请求时都会调用 Retrieve，实现可以自行缓存或刷新凭证。
+//
+type CredentialsProvider interface {
+	// Retrieve 返回当前可用的凭证，没有凭证时返回 ErrNoCredentials
+	Retrieve(ctx context.Context) (Credentials, error)
+}
+
+// StaticCredentials 是固定的凭证。
+type StaticCredentials Credentials
+
+// NewStaticCredentials 创建固定凭证。
+//
+// 参数：
+//   - accessKey: 访问密钥 ID
+//   - secretKey: 秘密访问密钥
+//   - sessionToken: 会话令牌，不使用临时凭证时为空
+//
+// 返回：
+//   - StaticCredentials: 凭证提供者
+//
+func NewStaticCredentials(accessKey, secretKey, sessionToken string) StaticCredentials {
+	return StaticCredentials{
+		AccessKeyID:     accessKey,
+		SecretAccessKey: secretKey,
+		SessionToken:    sessionToken,
+		Source:          "static",
+	}
+}
+
+// Retrieve 实现 CredentialsProvider 接口。
+func (s StaticCredentials) Retrieve(ctx context.Context) (Credentials, error) {
+	if s.AccessKeyID == "" || s.SecretAccessKey == "" {
+		return Credentials{}, ErrNoCredentials
+	}
+	return Credentials(s), nil
+}
+
+// EnvCredentials 从环境变量读取凭证。
+//
+// 读取 AWS_ACCESS_KEY_ID、AWS_SECRET_ACCESS_KEY 和 AWS_SESSION_TOKEN
+// （兼容旧的 AWS_ACCESS_KEY / AWS_SECRET_KEY）。
+//
+type EnvCredentials struct{}
+
+// Retrieve 实现 CredentialsProvider 接口。
+func (EnvCredentials) Retrieve(ctx context.Context) (Credentials, error) {
+	creds := Credentials{
+		AccessKeyID:     firstEnv("AWS_ACCESS_KEY_ID", "AWS_ACCESS_KEY"),
+		SecretAccessKey: firstEnv("AWS_SECRET_ACCESS_KEY", "AWS_SECRET_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		Source:          "env",
+	}
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return Credentials{}, ErrNoCredentials
+	}
+	return creds, nil
+}
+
+// SharedCredentials 从共享凭证文件（~/.aws/credentials）读取凭证。
+type SharedCredentials struct {
+	// Filename 凭证文件路径，为空时使用 AWS_SHARED_CREDENTIALS_FILE 或 ~/.aws/credentials
+	Filename string
+
+	// Profile 配置名称，为空时使用 AWS_PROFILE 或 "default"
+	Profile string
+}
+
+// Retrieve 实现 CredentialsProvider 接口。
+func (s SharedCredentials) Retrieve(ctx context.Context) (Credentials, error) {
+	filename := s.Filename
+	if filename == "" {
+		filename = os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	}
+	if filename == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return Credentials{}, ErrNoCredentials
+		}
+		filename = filepath.Join(home, ".aws", "credentials")
+	}
+
+	profile := s.Profile
+	if profile == "" {
+		profile = firstEnv("AWS_PROFILE", "AWS_DEFAULT_PROFILE")
+	}
+	if profile == "" {
+		profile = "default"
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Credentials{}, ErrNoCredentials
+		}
+		return Credentials{}, fmt.Errorf("bedrock: failed to open shared credentials: %w", err)
+	}
+	defer file.Close()
+
+	values, err := parseProfile(file, profile)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("bedrock: failed to read shared credentials %s: %w", filename, err)
+	}
+
+	creds := Credentials{
+		AccessKeyID:     values["aws_access_key_id"],
+		SecretAccessKey: values["aws_secret_access_key"],
+		SessionToken:    values["aws_session_token"],
+		Source:          "shared:" + profile,
+	}
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return Credentials{}, ErrNoCredentials
+	}
+	return creds, nil
+}
+
+// ChainCredentials 依次尝试多个凭证提供者，返回第一个可用的凭证。
+type ChainCredentials []CredentialsProvider
+
+// Retrieve 实现 CredentialsProvider 接口。
+//
+// 提供者返回 ErrNoCredentials 时继续尝试下一个，其他错误直接返回。
+//
+func (c ChainCredentials) Retrieve(ctx context.Context) (Credentials, error) {
+	for _, provider := range c {
+		creds, err := provider.Retrieve(ctx)
+		if err == nil {
+			return creds, nil
+		}
+		if !errors.Is(err, ErrNoCredentials) {
+			return Credentials{}, err
+		}
+	}
+	return Credentials{}, ErrNoCredentials
+}
+
+// DefaultCredentials 返回默认凭证链：环境变量，然后是共享凭证文件。
+//
+// 参数：
+//   - profile: 共享凭证文件中的配置名称，为空时使用 AWS_PROFILE 或 "default"
+//
+// 返回：
+//   - CredentialsProvider: 凭证链
+//
+func DefaultCredentials(profile string) CredentialsProvider {
+	return ChainCredentials{
+		EnvCredentials{},
+		SharedCredentials{Profile: profile},
+	}
+}
+
+// parseProfile 解析 INI 格式的凭证文件，返回指定配置的键值。
+func parseProfile(file *os.File, profile string) (map[string]string, error) {
+	values := make(map[string]string)
+	inProfile := false
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			inProfile = name == profile
+			continue
+		}
+
+		if !inProfile {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		values[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(value)
+	}
+
+	return values, scanner.Err()
+}
+
+// firstEnv 返回第一个非空的环境变量。
+func firstEnv(keys ...string) string {
+	for _, key := range keys {
+		if value := os.Getenv(key); value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -10,13 +10,11 @@
 //   - Cohere Command: cohere.command-text-v14, cohere.command-light-text-v14
 //   - Meta Llama: meta.llama2-13b-chat-v1, meta.llama2-70b-chat-v1
 //
-// 基本使用：
+// 基本使用（凭证从环境变量或 ~/.aws/credentials 读取）：
 //
 //	config := bedrock.Config{
-//	    Region:    "us-east-1",
-//	    AccessKey: os.Getenv("AWS_ACCESS_KEY_ID"),
-//	    SecretKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
-//	    Model:     "anthropic.claude-v2",
+//	    Region: "us-east-1",
+//	    Model:  "anthropic.claude-v2",
 //	}
 //	client, _ := bedrock.New(config)
 //
@@ -26,32 +24,84 @@
 //	response, _ := client.Invoke(context.Background(), messages)
 //	fmt.Println(response.Content)
 //
-// 使用临时凭证：
+// 凭证：
 //
+// 请求使用 AWS Signature V4 签名，凭证的解析顺序为 Config.Credentials、
+// AccessKey/SecretKey/SessionToken、环境变量（AWS_ACCESS_KEY_ID、
+// AWS_SECRET_ACCESS_KEY、AWS_SESSION_TOKEN）、共享凭证文件中的 Profile：
+//
+//	// 临时凭证
 //	config := bedrock.Config{
 //	    Region:       "us-east-1",
 //	    AccessKey:    "ASIA...",
 //	    SecretKey:    "...",
 //	    SessionToken: "...",
-//	    Model:        "anthropic.claude-v2",
 //	}
 //
-// 流式输出：
+//	// 共享凭证文件中的指定配置
+//	config := bedrock.Config{Region: "us-east-1", Profile: "prod"}
+//
+//	// 自定义凭证链（实现 CredentialsProvider 即可接入 STS 等来源）
+//	config := bedrock.Config{
+//	    Region: "us-east-1",
+//	    Credentials: bedrock.ChainCredentials{
+//	        bedrock.EnvCredentials{},
+//	        bedrock.SharedCredentials{Filename: "/etc/app/aws-credentials"},
+//	    },
+//	}
+//
+// 流式输出（解码 application/vnd.amazon.eventstream 二进制帧并校验 CRC）：
 //
 //	stream, _ := client.Stream(ctx, messages)
 //	for event := range stream {
-//	    if event.Error != nil {
+//	    switch event.Type {
+//	    case runnable.EventStream:
+//	        fmt.Print(event.Data.Content)
+//	    case runnable.EventError:
 //	        log.Fatal(event.Error)
 //	    }
-//	    fmt.Print(event.Data.Content)
 //	}
 //
+// 工具调用（原生 API 仅支持 Anthropic 模型，使用 Claude 的 tool_use / tool_result 格式）：
+//
+//	modelWithTools := client.BindTools([]types.Tool{weatherTool})
+//	response, _ := modelWithTools.Invoke(ctx, messages)
+//	for _, call := range response.ToolCalls {
+//	    fmt.Println(call.ID, call.Function.Name, call.Function.Arguments)
+//	}
+//
+// 结构化输出：Anthropic 模型通过强制调用工具实现，其他模型通过提示词约束：
+//
+//	structured := client.WithStructuredOutput(answerSchema)
+//	response, _ := structured.Invoke(ctx, messages) // response.Content 为 JSON
+//
+// Converse API：
+//
+// 设置 UseConverse 后使用与模型无关的 Converse / ConverseStream 接口，
+// 消息、工具和推理参数格式对所有模型一致，Mistral、Llama 3.1、Cohere Command R
+// 等模型也可以调用工具：
+//
+//	client, _ := bedrock.New(bedrock.Config{
+//	    Region:      "us-east-1",
+//	    Model:       "mistral.mistral-large-2407-v1:0",
+//	    UseConverse: true,
+//	})
+//	response, _ := client.BindTools([]types.Tool{weatherTool}).Invoke(ctx, messages)
+//
+// 测试：Endpoint 可以指向本地 httptest 服务器：
+//
+//	server := httptest.NewServer(handler)
+//	client, _ := bedrock.New(bedrock.Config{Region: "us-east-1", Endpoint: server.URL, ...})
+//
+// BedrockClient 实现了 chat.ChatModel，可以直接用于 Agent、StateGraph
+// 或作为其他模型的 fallback。
+//
 // 自定义参数：
 //
-//	response, _ := client.Invoke(ctx, messages,
+//	response, _ := client.WithOptions(
 //	    bedrock.WithTemperature(0.8),
 //	    bedrock.WithMaxTokens(1000),
-//	)
+//	).Invoke(ctx, messages)
 //
 // 特点：
 //   - 托管服务，无需自己部署模型
@@ -63,6 +113,6 @@
 // 注意：
 //   - 需要有效的 AWS 凭证
 //   - 需要在 AWS Bedrock 中启用相应的模型访问权限
-//   - 建议使用 AWS SDK 以获得完整的功能支持
+//   - 凭证链不包含 EC2/ECS 实例角色，需要时可实现 CredentialsProvider
 //
 package bedrock
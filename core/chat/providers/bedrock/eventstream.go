@@ -0,0 +1,223 @@
+package bedrock
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+
+	chaterrors "github.com/zhucl121/langchain-go/core/chat/errors"
+)
+
+const (
+	// eventStreamPreludeLen 前导长度：总长度、头部长度和前导 CRC 各 4 字节
+	eventStreamPreludeLen = 12
+
+	// eventStreamMinMessageLen 最短消息长度：前导加消息 CRC
+	eventStreamMinMessageLen = eventStreamPreludeLen + 4
+
+	// eventStreamMaxMessageLen 单条消息的最大长度（16 MB）
+	eventStreamMaxMessageLen = 16 * 1024 * 1024
+)
+
+// 事件流头部值类型
+const (
+	headerBoolTrue byte = iota
+	headerBoolFalse
+	headerByte
+	headerInt16
+	headerInt32
+	headerInt64
+	headerBytes
+	headerString
+	headerTimestamp
+	headerUUID
+)
+
+// eventStreamMessage 是 application/vnd.amazon.eventstream 中的一条消息。
+type eventStreamMessage struct {
+	// Headers 头部，值为 bool、int8/16/32/64、[]byte 或 string
+	// （时间戳为 int64 毫秒，UUID 为 16 字节的 []byte）
+	Headers map[string]any
+
+	// Payload 消息体
+	Payload []byte
+}
+
+// header 返回字符串类型的头部值。
+func (m eventStreamMessage) header(name string) string {
+	s, _ := m.Headers[name].(string)
+	return s
+}
+
+// err 将 exception / error 消息转换为错误，普通事件返回 nil。
+func (m eventStreamMessage) err() error {
+	switch m.header(":message-type") {
+	case "exception":
+		var body struct {
+			Message string `json:"message"`
+		}
+		_ = json.Unmarshal(m.Payload, &body)
+		if body.Message == "" {
+			body.Message = string(m.Payload)
+		}
+		return chaterrors.New("bedrock", 0, m.header(":exception-type"), body.Message)
+	case "error":
+		return chaterrors.New("bedrock", 0, m.header(":error-code"), m.header(":error-message"))
+	}
+	return nil
+}
+
+// eventStreamDecoder 解码 AWS 事件流的二进制帧。
+//
+// 每条消息的格式：
+//
+//	| 总长度 (4) | 头部长度 (4) | 前导 CRC (4) | 头部 | 消息体 | 消息 CRC (4) |
+//
+// 前导 CRC 覆盖前 8 字节，消息 CRC 覆盖 CRC 之前的全部内容，均为 CRC32 (IEEE)。
+//
+type eventStreamDecoder struct {
+	reader io.Reader
+}
+
+func newEventStreamDecoder(reader io.Reader) *eventStreamDecoder {
+	return &eventStreamDecoder{reader: reader}
+}
+
+// next 读取下一条消息，流结束时返回 io.EOF。
+func (d *eventStreamDecoder) next() (eventStreamMessage, error) {
+	prelude := make([]byte, eventStreamPreludeLen)
+	if _, err := io.ReadFull(d.reader, prelude); err != nil {
+		if errors.Is(err, io.EOF) {
+			return eventStreamMessage{}, io.EOF
+		}
+		return eventStreamMessage{}, fmt.Errorf("bedrock: failed to read event prelude: %w", err)
+	}
+
+	// 在 int64 中计算，避免 uint32 溢出绕过长度检查
+	totalLen := int64(binary.BigEndian.Uint32(prelude[0:4]))
+	headersLen := int64(binary.BigEndian.Uint32(prelude[4:8]))
+	if crc := crc32.ChecksumIEEE(prelude[0:8]); crc != binary.BigEndian.Uint32(prelude[8:12]) {
+		return eventStreamMessage{}, fmt.Errorf("bedrock: event prelude checksum mismatch")
+	}
+	if totalLen < eventStreamMinMessageLen || totalLen > eventStreamMaxMessageLen ||
+		headersLen > totalLen-eventStreamMinMessageLen {
+		return eventStreamMessage{}, fmt.Errorf("bedrock: invalid event length %d (headers %d)", totalLen, headersLen)
+	}
+
+	rest := make([]byte, totalLen-eventStreamPreludeLen)
+	if _, err := io.ReadFull(d.reader, rest); err != nil {
+		return eventStreamMessage{}, fmt.Errorf("bedrock: truncated event: %w", err)
+	}
+
+	body, messageCRC := rest[:len(rest)-4], binary.BigEndian.Uint32(rest[len(rest)-4:])
+	crc := crc32.Update(crc32.ChecksumIEEE(prelude), crc32.IEEETable, body)
+	if crc != messageCRC {
+		return eventStreamMessage{}, fmt.Errorf("bedrock: event message checksum mismatch")
+	}
+
+	headers, err := decodeEventHeaders(body[:headersLen])
+	if err != nil {
+		return eventStreamMessage{}, err
+	}
+
+	return eventStreamMessage{
+		Headers: headers,
+		Payload: body[headersLen:],
+	}, nil
+}
+
+// decodeEventHeaders 解析头部：名称长度 (1) | 名称 | 类型 (1) | 值。
+func decodeEventHeaders(data []byte) (map[string]any, error) {
+	headers := make(map[string]any)
+	invalid := fmt.Errorf("bedrock: malformed event headers")
+
+	for len(data) > 0 {
+		nameLen := int(data[0])
+		if len(data) < 1+nameLen+1 {
+			return nil, invalid
+		}
+		name := string(data[1 : 1+nameLen])
+		kind := data[1+nameLen]
+		data = data[2+nameLen:]
+
+		var value any
+		var size int
+		switch kind {
+		case headerBoolTrue:
+			value = true
+		case headerBoolFalse:
+			value = false
+		case headerByte:
+			size = 1
+		case headerInt16:
+			size = 2
+		case headerInt32:
+			size = 4
+		case headerInt64, headerTimestamp:
+			size = 8
+		case headerUUID:
+			size = 16
+		case headerBytes, headerString:
+			if len(data) < 2 {
+				return nil, invalid
+			}
+			size = int(binary.BigEndian.Uint16(data))
+			data = data[2:]
+		default:
+			return nil, fmt.Errorf("bedrock: unknown event header type %d", kind)
+		}
+
+		if len(data) < size {
+			return nil, invalid
+		}
+		raw := data[:size]
+		data = data[size:]
+
+		switch kind {
+		case headerByte:
+			value = int8(raw[0])
+		case headerInt16:
+			value = int16(binary.BigEndian.Uint16(raw))
+		case headerInt32:
+			value = int32(binary.BigEndian.Uint32(raw))
+		case headerInt64, headerTimestamp:
+			value = int64(binary.BigEndian.Uint64(raw))
+		case headerBytes, headerUUID:
+			value = append([]byte(nil), raw...)
+		case headerString:
+			value = string(raw)
+		}
+
+		headers[name] = value
+	}
+
+	return headers, nil
+}
+
+// readEventStream 依次读取事件流中的事件，直到流结束。
+//
+// exception / error 消息会终止读取并返回对应的错误。
+//
+func readEventStream(reader io.Reader, handle func(eventType string, payload []byte) error) error {
+	decoder := newEventStreamDecoder(reader)
+	for {
+		msg, err := decoder.next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+
+		if err := msg.err(); err != nil {
+			return err
+		}
+
+		if err := handle(msg.header(":event-type"), msg.Payload); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package bedrock
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// encodeEvent 按事件流格式编码一条消息（字符串头部）
+func encodeEvent(headers map[string]string, payload []byte) []byte {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var hdr bytes.Buffer
+	for _, name := range names {
+		hdr.WriteByte(byte(len(name)))
+		hdr.WriteString(name)
+		hdr.WriteByte(headerString)
+		binary.Write(&hdr, binary.BigEndian, uint16(len(headers[name])))
+		hdr.WriteString(headers[name])
+	}
+
+	var msg bytes.Buffer
+	binary.Write(&msg, binary.BigEndian, uint32(eventStreamPreludeLen+hdr.Len()+len(payload)+4))
+	binary.Write(&msg, binary.BigEndian, uint32(hdr.Len()))
+	binary.Write(&msg, binary.BigEndian, crc32.ChecksumIEEE(msg.Bytes()))
+	msg.Write(hdr.Bytes())
+	msg.Write(payload)
+	binary.Write(&msg, binary.BigEndian, crc32.ChecksumIEEE(msg.Bytes()))
+	return msg.Bytes()
+}
+
+func TestEventStreamDecoder(t *testing.T) {
+	var stream bytes.Buffer
+	stream.Write(encodeEvent(map[string]string{":event-type": "chunk", ":message-type": "event"}, []byte(`{"a":1}`)))
+	stream.Write(encodeEvent(map[string]string{":event-type": "messageStop"}, nil))
+
+	decoder := newEventStreamDecoder(&stream)
+
+	msg, err := decoder.next()
+	if err != nil {
+		t.Fatalf("next failed: %v", err)
+	}
+	if msg.header(":event-type") != "chunk" || string(msg.Payload) != `{"a":1}` {
+		t.Errorf("unexpected message: %+v", msg)
+	}
+
+	msg, err = decoder.next()
+	if err != nil {
+		t.Fatalf("next failed: %v", err)
+	}
+	if msg.header(":event-type") != "messageStop" || len(msg.Payload) != 0 {
+		t.Errorf("unexpected message: %+v", msg)
+	}
+
+	if _, err := decoder.next(); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+}
+
+func TestEventStreamDecoder_HeaderTypes(t *testing.T) {
+	var hdr bytes.Buffer
+	writeName := func(name string, kind byte) {
+		hdr.WriteByte(byte(len(name)))
+		hdr.WriteString(name)
+		hdr.WriteByte(kind)
+	}
+	writeName("t", headerBoolTrue)
+	writeName("f", headerBoolFalse)
+	writeName("i32", headerInt32)
+	binary.Write(&hdr, binary.BigEndian, int32(-7))
+	writeName("ts", headerTimestamp)
+	binary.Write(&hdr, binary.BigEndian, int64(1700000000000))
+	writeName("b", headerBytes)
+	binary.Write(&hdr, binary.BigEndian, uint16(2))
+	hdr.Write([]byte{0xde, 0xad})
+
+	headers, err := decodeEventHeaders(hdr.Bytes())
+	if err != nil {
+		t.Fatalf("decodeEventHeaders failed: %v", err)
+	}
+	if headers["t"] != true || headers["f"] != false || headers["i32"] != int32(-7) || headers["ts"] != int64(1700000000000) {
+		t.Errorf("unexpected headers: %+v", headers)
+	}
+	if b, _ := headers["b"].([]byte); !bytes.Equal(b, []byte{0xde, 0xad}) {
+		t.Errorf("unexpected bytes header: %v", headers["b"])
+	}
+
+	if _, err := decodeEventHeaders([]byte{3, 'a', 'b'}); err == nil {
+		t.Error("expected error for truncated header")
+	}
+}
+
+func TestEventStreamDecoder_Checksum(t *testing.T) {
+	msg := encodeEvent(map[string]string{":event-type": "chunk"}, []byte(`{}`))
+
+	corrupted := append([]byte(nil), msg...)
+	corrupted[len(corrupted)-5] ^= 0xff
+	if _, err := newEventStreamDecoder(bytes.NewReader(corrupted)).next(); err == nil || !strings.Contains(err.Error(), "message checksum") {
+		t.Errorf("expected message checksum error, got %v", err)
+	}
+
+	corrupted = append([]byte(nil), msg...)
+	corrupted[1] ^= 0xff
+	if _, err := newEventStreamDecoder(bytes.NewReader(corrupted)).next(); err == nil || !strings.Contains(err.Error(), "prelude checksum") {
+		t.Errorf("expected prelude checksum error, got %v", err)
+	}
+
+	if _, err := newEventStreamDecoder(bytes.NewReader(msg[:len(msg)-2])).next(); err == nil {
+		t.Error("expected error for truncated message")
+	}
+}
+
+func TestEventStreamDecoder_InvalidLength(t *testing.T) {
+	// prelude 构造带正确 CRC 的前导，后面跟足够的数据
+	prelude := func(totalLen, headersLen uint32) []byte {
+		var msg bytes.Buffer
+		binary.Write(&msg, binary.BigEndian, totalLen)
+		binary.Write(&msg, binary.BigEndian, headersLen)
+		binary.Write(&msg, binary.BigEndian, crc32.ChecksumIEEE(msg.Bytes()))
+		msg.Write(make([]byte, 64))
+		return msg.Bytes()
+	}
+
+	tests := []struct {
+		name       string
+		totalLen   uint32
+		headersLen uint32
+	}{
+		{"headers length overflow", 32, 0xFFFFFFF8},
+		{"headers longer than message", 32, 17},
+		{"total length below prelude", 8, 0},
+		{"total length without message crc", 12, 0},
+		{"total length too large", eventStreamMaxMessageLen + 1, 0},
+		{"total length max uint32", 0xFFFFFFFF, 0xFFFFFFF0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newEventStreamDecoder(bytes.NewReader(prelude(tt.totalLen, tt.headersLen))).next()
+			if err == nil || !strings.Contains(err.Error(), "invalid event length") {
+				t.Errorf("expected invalid length error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestReadEventStream_Exception(t *testing.T) {
+	var stream bytes.Buffer
+	stream.Write(encodeEvent(map[string]string{
+		":message-type":   "exception",
+		":exception-type": "throttlingException",
+	}, []byte(`{"message":"Too many requests"}`)))
+
+	err := readEventStream(&stream, func(string, []byte) error {
+		t.Error("handler should not be called for exceptions")
+		return nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "throttlingException") || !strings.Contains(err.Error(), "Too many requests") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
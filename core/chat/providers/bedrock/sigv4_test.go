@@ -0,0 +1,135 @@
+package bedrock
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testCredentials AWS 文档中 SigV4 测试套件使用的凭证
+var testCredentials = Credentials{
+	AccessKeyID:     "AKIDEXAMPLE",
+	SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+}
+
+var testSigningTime = time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+
+func TestSigV4_GetVanilla(t *testing.T) {
+	req, _ := http.NewRequest("GET", "https://example.amazonaws.com/", nil)
+
+	signer := sigV4Signer{service: "service", region: "us-east-1"}
+	signer.sign(req, nil, testCredentials, testSigningTime)
+
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+		"SignedHeaders=host;x-amz-date, " +
+		"Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("Authorization = %q, want %q", got, want)
+	}
+	if got := req.Header.Get("X-Amz-Date"); got != "20150830T123600Z" {
+		t.Errorf("X-Amz-Date = %q", got)
+	}
+}
+
+func TestSigV4_SessionToken(t *testing.T) {
+	req, _ := http.NewRequest("POST", "https://bedrock-runtime.us-east-1.amazonaws.com/model/m/invoke", strings.NewReader("{}"))
+	req.Header.Set("Content-Type", "application/json")
+
+	creds := testCredentials
+	creds.SessionToken = "session-token"
+
+	signer := sigV4Signer{service: "bedrock", region: "us-east-1"}
+	signer.sign(req, []byte("{}"), creds, testSigningTime)
+
+	if req.Header.Get("X-Amz-Security-Token") != "session-token" {
+		t.Error("expected X-Amz-Security-Token header")
+	}
+	if auth := req.Header.Get("Authorization"); !strings.Contains(auth, "SignedHeaders=content-type;host;x-amz-date;x-amz-security-token,") {
+		t.Errorf("unexpected signed headers: %s", auth)
+	}
+}
+
+func TestCanonicalURI(t *testing.T) {
+	u, _ := url.Parse("https://example.com/model/" + escapeURI("anthropic.claude-3-haiku-20240307-v1:0", true) + "/invoke")
+
+	if got := u.EscapedPath(); got != "/model/anthropic.claude-3-haiku-20240307-v1%3A0/invoke" {
+		t.Errorf("EscapedPath = %q", got)
+	}
+	if got := canonicalURI(u); got != "/model/anthropic.claude-3-haiku-20240307-v1%253A0/invoke" {
+		t.Errorf("canonicalURI = %q", got)
+	}
+}
+
+func TestSharedCredentials(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "credentials")
+	content := `[default]
+aws_access_key_id = AKIDDEFAULT
+aws_secret_access_key = default-secret
+
+# 临时凭证
+[dev]
+aws_access_key_id=AKIDDEV
+aws_secret_access_key=dev-secret
+aws_session_token=dev-token
+`
+	if err := os.WriteFile(filename, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("AWS_PROFILE", "")
+	t.Setenv("AWS_DEFAULT_PROFILE", "")
+
+	creds, err := SharedCredentials{Filename: filename}.Retrieve(context.Background())
+	if err != nil || creds.AccessKeyID != "AKIDDEFAULT" || creds.SecretAccessKey != "default-secret" {
+		t.Errorf("default profile: %+v, %v", creds, err)
+	}
+
+	creds, err = SharedCredentials{Filename: filename, Profile: "dev"}.Retrieve(context.Background())
+	if err != nil || creds.AccessKeyID != "AKIDDEV" || creds.SessionToken != "dev-token" {
+		t.Errorf("dev profile: %+v, %v", creds, err)
+	}
+
+	if _, err := (SharedCredentials{Filename: filename, Profile: "missing"}).Retrieve(context.Background()); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("expected ErrNoCredentials, got %v", err)
+	}
+}
+
+func TestDefaultCredentials(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "credentials")
+	if err := os.WriteFile(filename, []byte("[default]\naws_access_key_id=FILE\naws_secret_access_key=file-secret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filename)
+	t.Setenv("AWS_PROFILE", "")
+
+	// 环境变量优先
+	t.Setenv("AWS_ACCESS_KEY_ID", "ENV")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "env-secret")
+	t.Setenv("AWS_SESSION_TOKEN", "env-token")
+
+	creds, err := DefaultCredentials("").Retrieve(context.Background())
+	if err != nil || creds.AccessKeyID != "ENV" || creds.SessionToken != "env-token" || creds.Source != "env" {
+		t.Errorf("env credentials: %+v, %v", creds, err)
+	}
+
+	// 没有环境变量时使用共享凭证文件
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+
+	creds, err = DefaultCredentials("").Retrieve(context.Background())
+	if err != nil || creds.AccessKeyID != "FILE" || creds.Source != "shared:default" {
+		t.Errorf("shared credentials: %+v, %v", creds, err)
+	}
+
+	// 都没有时返回 ErrNoCredentials
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(t.TempDir(), "missing"))
+	if _, err := DefaultCredentials("").Retrieve(context.Background()); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("expected ErrNoCredentials, got %v", err)
+	}
+}
@@ -1,8 +1,10 @@
 package gemini
 
 import (
+	"bufio"
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -10,12 +12,18 @@ import (
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/zhucl121/langchain-go/core/chat"
+	chaterrors "github.com/zhucl121/langchain-go/core/chat/errors"
+	"github.com/zhucl121/langchain-go/core/runnable"
 	"github.com/zhucl121/langchain-go/pkg/types"
 )
 
 // GeminiClient 实现 Google Gemini API 集成
 //
 // Google Gemini 是 Google 的多模态大语言模型系列。
+// GeminiClient 实现了 chat.ChatModel 接口，工具调用映射为 Gemini 原生的
+// functionDeclarations / functionCall / functionResponse。
 //
 // 支持的模型:
 //   - gemini-pro: 文本生成
@@ -32,6 +40,7 @@ import (
 //	client := gemini.New(config)
 //
 type GeminiClient struct {
+	*chat.BaseChatModel
 	config     Config
 	httpClient *http.Client
 }
@@ -40,37 +49,43 @@ type GeminiClient struct {
 type Config struct {
 	// APIKey Google API 密钥
 	APIKey string
-	
+
 	// Model 模型名称
 	// 支持: "gemini-pro", "gemini-pro-vision", "gemini-1.5-pro", "gemini-1.5-flash"
 	Model string
-	
+
 	// Temperature 温度参数 (0.0-2.0)
 	Temperature float32
-	
+
 	// TopP 核采样参数
 	TopP float32
-	
+
 	// TopK Top-K 采样参数
 	TopK int
-	
+
 	// MaxTokens 最大输出 token 数
 	MaxTokens int
-	
+
 	// StopSequences 停止序列
 	StopSequences []string
-	
+
 	// SafetySettings 安全设置
 	SafetySettings []SafetySetting
-	
+
 	// HTTPClient 自定义 HTTP 客户端
 	HTTPClient *http.Client
-	
+
 	// Timeout 请求超时时间
 	Timeout time.Duration
-	
+
 	// BaseURL API 基础 URL（用于自定义端点）
 	BaseURL string
+
+	// Thinking 思考配置（Gemini 2.5 等思考模型），可通过 runnable.WithThinking 按调用覆盖
+	//
+	// 启用时返回思考摘要（includeThoughts），未设置预算和强度时由模型动态决定预算；
+	// Enabled 为 false 时关闭思考（thinkingBudget=0）。
+	Thinking *types.ThinkingConfig
 }
 
 // SafetySetting 安全设置
@@ -97,291 +112,602 @@ func New(config Config) (*GeminiClient, error) {
 	if config.APIKey == "" {
 		return nil, fmt.Errorf("gemini: API key is required")
 	}
-	
+
 	if config.Model == "" {
 		config.Model = "gemini-pro"
 	}
-	
+
 	if config.BaseURL == "" {
 		config.BaseURL = "https://generativelanguage.googleapis.com/v1beta"
 	}
-	
+
 	if config.Timeout == 0 {
 		config.Timeout = 60 * time.Second
 	}
-	
+
 	httpClient := config.HTTPClient
 	if httpClient == nil {
 		httpClient = &http.Client{
 			Timeout: config.Timeout,
 		}
 	}
-	
+
 	return &GeminiClient{
-		config:     config,
-		httpClient: httpClient,
+		BaseChatModel: chat.NewBaseChatModel(config.Model, "gemini"),
+		config:        config,
+		httpClient:    httpClient,
 	}, nil
 }
 
-// Invoke 调用 Gemini API 生成响应
-func (c *GeminiClient) Invoke(ctx context.Context, messages []types.Message, opts ...Option) (types.Message, error) {
-	// 应用选项
-	config := c.config
-	for _, opt := range opts {
-		opt(&config)
-	}
-	
-	// 转换消息格式
-	contents, err := c.convertMessages(messages)
-	if err != nil {
-		return types.Message{}, fmt.Errorf("gemini: failed to convert messages: %w", err)
+// Invoke 实现 Runnable 接口，调用 Gemini API 生成响应
+func (c *GeminiClient) Invoke(ctx context.Context, messages []types.Message, opts ...runnable.Option) (types.Message, error) {
+	return chat.TraceInvoke(ctx, c, messages, opts, func(ctx context.Context) (types.Message, error) {
+		return c.invoke(ctx, messages, opts...)
+	})
+}
+
+// invoke 执行单次调用。
+func (c *GeminiClient) invoke(ctx context.Context, messages []types.Message, opts ...runnable.Option) (types.Message, error) {
+	if err := chat.ValidateMessages(messages); err != nil {
+		return types.Message{}, err
 	}
-	
+
 	// 构建请求
-	reqBody := GeminiRequest{
-		Contents: contents,
-		GenerationConfig: GenerationConfig{
-			Temperature:    config.Temperature,
-			TopP:           config.TopP,
-			TopK:           config.TopK,
-			MaxOutputTokens: config.MaxTokens,
-			StopSequences:  config.StopSequences,
-		},
-	}
-	
-	// 添加安全设置
-	if len(config.SafetySettings) > 0 {
-		reqBody.SafetySettings = config.SafetySettings
+	reqBody, err := c.buildRequest(messages, chat.ResolveOptions(c.config.Thinking, opts...))
+	if err != nil {
+		return types.Message{}, err
 	}
-	
+
 	// 发送请求
-	response, err := c.generateContent(ctx, &config, &reqBody)
+	response, err := c.generateContent(ctx, reqBody)
 	if err != nil {
 		return types.Message{}, err
 	}
-	
+
 	// 解析响应
 	return c.parseResponse(response)
 }
 
-// Stream 流式生成响应
-func (c *GeminiClient) Stream(ctx context.Context, messages []types.Message, opts ...Option) (<-chan types.StreamEvent, error) {
-	// 应用选项
-	config := c.config
-	for _, opt := range opts {
-		opt(&config)
-	}
-	
-	// 转换消息格式
-	contents, err := c.convertMessages(messages)
-	if err != nil {
-		return nil, fmt.Errorf("gemini: failed to convert messages: %w", err)
+// Stream 实现 Runnable 接口，流式生成响应
+//
+// 文本增量以 EventStream 事件返回，EventEnd 事件携带完整消息（包括工具调用）。
+//
+func (c *GeminiClient) Stream(ctx context.Context, messages []types.Message, opts ...runnable.Option) (<-chan runnable.StreamEvent[types.Message], error) {
+	return chat.TraceStream(ctx, c, messages, opts, func(ctx context.Context) (<-chan runnable.StreamEvent[types.Message], error) {
+		return c.stream(ctx, messages, opts...)
+	})
+}
+
+// stream 执行流式调用。
+func (c *GeminiClient) stream(ctx context.Context, messages []types.Message, opts ...runnable.Option) (<-chan runnable.StreamEvent[types.Message], error) {
+	if err := chat.ValidateMessages(messages); err != nil {
+		return nil, err
 	}
-	
+
 	// 构建请求
-	reqBody := GeminiRequest{
-		Contents: contents,
-		GenerationConfig: GenerationConfig{
-			Temperature:    config.Temperature,
-			TopP:           config.TopP,
-			TopK:           config.TopK,
-			MaxOutputTokens: config.MaxTokens,
-			StopSequences:  config.StopSequences,
-		},
-	}
-	
-	if len(config.SafetySettings) > 0 {
-		reqBody.SafetySettings = config.SafetySettings
+	reqBody, err := c.buildRequest(messages, chat.ResolveOptions(c.config.Thinking, opts...))
+	if err != nil {
+		return nil, err
 	}
-	
+
 	// 创建流式响应通道
-	eventChan := make(chan types.StreamEvent, 10)
-	
+	out := make(chan runnable.StreamEvent[types.Message], 10)
+
 	go func() {
-		defer close(eventChan)
-		
-		if err := c.streamContent(ctx, &config, &reqBody, eventChan); err != nil {
-			eventChan <- types.StreamEvent{
+		defer close(out)
+
+		out <- runnable.StreamEvent[types.Message]{
+			Type: runnable.EventStart,
+			Name: c.GetName(),
+		}
+
+		if err := c.streamContent(ctx, reqBody, out); err != nil {
+			out <- runnable.StreamEvent[types.Message]{
+				Type:  runnable.EventError,
 				Error: err,
 			}
 		}
 	}()
-	
-	return eventChan, nil
+
+	// 在图等外层执行器中运行时，把 token 事件转发给外层
+	return runnable.ForwardStream(ctx, out), nil
 }
 
-// Batch 批量处理消息
-func (c *GeminiClient) Batch(ctx context.Context, messagesList [][]types.Message, opts ...Option) ([]types.Message, error) {
+// Batch 实现 Runnable 接口，并行处理多组消息
+func (c *GeminiClient) Batch(ctx context.Context, messagesList [][]types.Message, opts ...runnable.Option) ([]types.Message, error) {
+	if len(messagesList) == 0 {
+		return []types.Message{}, nil
+	}
+
 	results := make([]types.Message, len(messagesList))
-	
+	errs := make([]error, len(messagesList))
+
+	done := make(chan struct{}, len(messagesList))
 	for i, messages := range messagesList {
-		result, err := c.Invoke(ctx, messages, opts...)
+		go func(idx int, msgs []types.Message) {
+			results[idx], errs[idx] = c.Invoke(ctx, msgs, opts...)
+			done <- struct{}{}
+		}(i, messages)
+	}
+	for range messagesList {
+		<-done
+	}
+
+	for i, err := range errs {
 		if err != nil {
 			return nil, fmt.Errorf("gemini: batch request %d failed: %w", i, err)
 		}
-		results[i] = result
 	}
-	
+
 	return results, nil
 }
 
+// BindTools 实现 ChatModel 接口，绑定工具
+//
+// 工具转换为 Gemini 的 functionDeclarations。Gemini 的函数调用没有 ID，
+// 返回的每个 ToolCall 会生成唯一 ID，工具结果消息通过该 ID 找回函数名。
+//
+func (c *GeminiClient) BindTools(tools []types.Tool) chat.ChatModel {
+	newClient := c.clone()
+	newClient.SetBoundTools(tools)
+	return newClient
+}
+
+// WithStructuredOutput 实现 ChatModel 接口，配置结构化输出
+//
+// 使用 generationConfig 的 responseMimeType（application/json）和 responseSchema。
+//
+func (c *GeminiClient) WithStructuredOutput(schema types.Schema) chat.ChatModel {
+	newClient := c.clone()
+	newClient.SetOutputSchema(schema)
+	return newClient
+}
+
+// WithConfig 实现 Runnable 接口
+func (c *GeminiClient) WithConfig(config *types.Config) runnable.Runnable[[]types.Message, types.Message] {
+	newClient := c.clone()
+	newClient.SetConfig(config)
+	return newClient
+}
+
+// WithRetry 实现 Runnable 接口
+func (c *GeminiClient) WithRetry(policy types.RetryPolicy) runnable.Runnable[[]types.Message, types.Message] {
+	return runnable.NewRetryRunnable[[]types.Message, types.Message](c, policy)
+}
+
+// WithFallbacks 实现 Runnable 接口
+func (c *GeminiClient) WithFallbacks(fallbacks ...runnable.Runnable[[]types.Message, types.Message]) runnable.Runnable[[]types.Message, types.Message] {
+	return runnable.NewFallbackRunnable[[]types.Message, types.Message](c, fallbacks)
+}
+
+// WithOptions 返回应用了选项的新客户端
+//
+// 绑定的工具和结构化输出配置会保留。
+//
+// 示例：
+//
+//	creative := client.WithOptions(gemini.WithTemperature(0.9), gemini.WithTopK(50))
+//	response, _ := creative.Invoke(ctx, messages)
+//
+func (c *GeminiClient) WithOptions(opts ...Option) *GeminiClient {
+	newClient := c.clone()
+	for _, opt := range opts {
+		opt(&newClient.config)
+	}
+	return newClient
+}
+
 // ==================== 内部方法 ====================
 
+// clone 复制客户端（包括工具、结构化输出和运行时配置）
+func (c *GeminiClient) clone() *GeminiClient {
+	newClient := &GeminiClient{
+		BaseChatModel: chat.NewBaseChatModel(c.config.Model, "gemini"),
+		config:        c.config,
+		httpClient:    c.httpClient,
+	}
+	newClient.SetConfig(c.GetConfig())
+	newClient.SetBoundTools(c.GetBoundTools())
+	if schema := c.GetOutputSchema(); schema != nil {
+		newClient.SetOutputSchema(*schema)
+	}
+	return newClient
+}
+
+// buildRequest 构建请求体
+//
+// options 中设置的单次调用参数优先于模型配置；Gemini 不支持关闭并行函数调用，
+// ParallelToolCalls 会被忽略。
+//
+func (c *GeminiClient) buildRequest(messages []types.Message, options *runnable.Options) (*GeminiRequest, error) {
+	contents, err := c.convertMessages(messages)
+	if err != nil {
+		return nil, fmt.Errorf("gemini: failed to convert messages: %w", err)
+	}
+
+	reqBody := &GeminiRequest{
+		Contents: contents,
+		GenerationConfig: GenerationConfig{
+			TopP:            c.config.TopP,
+			TopK:            c.config.TopK,
+			MaxOutputTokens: c.config.MaxTokens,
+			StopSequences:   c.config.StopSequences,
+		},
+		SafetySettings: c.config.SafetySettings,
+	}
+
+	// 单次调用的生成参数（温度为 0 时也需要发送）
+	if options.Temperature != nil {
+		temperature := float32(*options.Temperature)
+		reqBody.GenerationConfig.Temperature = &temperature
+	} else if c.config.Temperature > 0 {
+		reqBody.GenerationConfig.Temperature = &c.config.Temperature
+	}
+	if options.MaxTokens > 0 {
+		reqBody.GenerationConfig.MaxOutputTokens = options.MaxTokens
+	}
+	if len(options.Stop) > 0 {
+		reqBody.GenerationConfig.StopSequences = options.Stop
+	}
+	reqBody.GenerationConfig.Seed = options.Seed
+
+	// 添加思考配置
+	if options.Thinking != nil {
+		reqBody.GenerationConfig.ThinkingConfig = toThinkingConfig(options.Thinking)
+	}
+
+	// 添加工具
+	if tools := c.GetBoundTools(); len(tools) > 0 {
+		declarations := make([]FunctionDeclaration, len(tools))
+		for i, tool := range tools {
+			declarations[i] = FunctionDeclaration{
+				Name:        tool.Name,
+				Description: tool.Description,
+				Parameters:  toGeminiSchema(tool.Parameters),
+			}
+		}
+		reqBody.Tools = []Tool{{FunctionDeclarations: declarations}}
+
+		if options.ToolChoice != nil {
+			reqBody.ToolConfig = toToolConfig(*options.ToolChoice)
+		}
+	}
+
+	// 添加结构化输出（单次调用的响应格式优先）
+	if format := options.ResponseFormat; format != nil {
+		reqBody.GenerationConfig.ResponseMimeType = "text/plain"
+		if format.IsJSON() {
+			reqBody.GenerationConfig.ResponseMimeType = "application/json"
+		}
+		if format.Type == types.ResponseFormatJSONSchema && format.Schema != nil {
+			reqBody.GenerationConfig.ResponseSchema = toGeminiSchema(*format.Schema)
+		}
+	} else if schema := c.GetOutputSchema(); schema != nil {
+		reqBody.GenerationConfig.ResponseMimeType = "application/json"
+		reqBody.GenerationConfig.ResponseSchema = toGeminiSchema(*schema)
+	}
+
+	return reqBody, nil
+}
+
+// toToolConfig 转换工具选择（required 和指定工具都使用 ANY 模式）
+func toToolConfig(choice types.ToolChoice) *ToolConfig {
+	config := &FunctionCallingConfig{Mode: "AUTO"}
+	switch choice.Mode {
+	case types.ToolChoiceNone:
+		config.Mode = "NONE"
+	case types.ToolChoiceRequired:
+		config.Mode = "ANY"
+	case types.ToolChoiceTool:
+		config.Mode = "ANY"
+		config.AllowedFunctionNames = []string{choice.Name}
+	}
+	return &ToolConfig{FunctionCallingConfig: config}
+}
+
+// toThinkingConfig 转换思考配置
+func toThinkingConfig(thinking *types.ThinkingConfig) *ThinkingConfig {
+	budget := 0
+	if thinking.Enabled {
+		// -1 表示由模型动态决定预算
+		budget = -1
+		if thinking.BudgetTokens > 0 || thinking.Effort != "" {
+			budget = thinking.Budget()
+		}
+	}
+	return &ThinkingConfig{
+		ThinkingBudget:  &budget,
+		IncludeThoughts: thinking.Enabled,
+	}
+}
+
 func (c *GeminiClient) convertMessages(messages []types.Message) ([]Content, error) {
 	if len(messages) == 0 {
 		return nil, fmt.Errorf("gemini: messages are required")
 	}
-	
+
 	contents := make([]Content, 0, len(messages))
-	
+
+	// 工具调用 ID -> 函数名，用于把工具结果映射为 functionResponse
+	toolNames := make(map[string]string)
+
 	for _, msg := range messages {
-		// Gemini 使用 "user" 和 "model" 角色
-		role := "user"
-		if msg.Role == types.RoleAssistant {
-			role = "model"
-		} else if msg.Role == types.RoleSystem {
+		switch msg.Role {
+		case types.RoleAssistant:
+			// Gemini 使用 "model" 表示助手
+			parts := make([]Part, 0, 1+len(msg.ToolCalls))
+			if msg.Content != "" || len(msg.ToolCalls) == 0 {
+				parts = append(parts, Part{Text: msg.Content})
+			}
+			for _, tc := range msg.ToolCalls {
+				args := map[string]any{}
+				if tc.Function.Arguments != "" {
+					if err := json.Unmarshal([]byte(tc.Function.Arguments), &args); err != nil {
+						return nil, fmt.Errorf("gemini: invalid arguments for tool call %s: %w", tc.ID, err)
+					}
+				}
+				toolNames[tc.ID] = tc.Function.Name
+				parts = append(parts, Part{FunctionCall: &FunctionCall{Name: tc.Function.Name, Args: args}})
+			}
+			contents = append(contents, Content{Role: "model", Parts: parts})
+
+		case types.RoleTool:
+			name := toolNames[msg.ToolCallID]
+			if name == "" {
+				name = msg.Name
+			}
+			part := Part{FunctionResponse: &FunctionResponse{
+				Name:     name,
+				Response: functionResponseBody(msg.Content),
+			}}
+
+			// 同一轮的多个工具结果合并到一个 content 中
+			if n := len(contents); n > 0 && contents[n-1].Role == "user" && contents[n-1].Parts[0].FunctionResponse != nil {
+				contents[n-1].Parts = append(contents[n-1].Parts, part)
+				continue
+			}
+			contents = append(contents, Content{Role: "user", Parts: []Part{part}})
+
+		default:
 			// 系统消息作为用户消息的一部分
-			role = "user"
-		}
-		
-		parts := []Part{
-			{Text: msg.Content},
+			parts := []Part{{Text: msg.Content}}
+			if len(msg.Parts) > 0 {
+				var err error
+				if parts, err = convertParts(msg.Parts); err != nil {
+					return nil, err
+				}
+			}
+			contents = append(contents, Content{
+				Role:  "user",
+				Parts: parts,
+			})
 		}
-		
-		contents = append(contents, Content{
-			Role:  role,
-			Parts: parts,
-		})
 	}
-	
+
 	return contents, nil
 }
 
-func (c *GeminiClient) generateContent(ctx context.Context, config *Config, reqBody *GeminiRequest) (*GeminiResponse, error) {
+// convertParts 转换多模态内容
+//
+// 数据使用 inlineData（Base64），URL 使用 fileData（如 Files API 或 Cloud Storage 的 URI）。
+// Gemini 支持图像、音频、视频和文档（如 PDF），都需要媒体类型。
+//
+func convertParts(parts []*types.MultimodalContent) ([]Part, error) {
+	result := make([]Part, 0, len(parts))
+
+	for _, part := range parts {
+		if part.IsText() {
+			result = append(result, Part{Text: part.Text})
+			continue
+		}
+
+		mimeType := part.MediaType()
+		if mimeType == "" {
+			return nil, fmt.Errorf("gemini: %s part requires a media type", part.Type)
+		}
+
+		switch {
+		case len(part.Data()) > 0:
+			result = append(result, Part{InlineData: &Blob{
+				MimeType: mimeType,
+				Data:     base64.StdEncoding.EncodeToString(part.Data()),
+			}})
+		case part.URL() != "":
+			result = append(result, Part{FileData: &FileData{
+				MimeType: mimeType,
+				FileURI:  part.URL(),
+			}})
+		default:
+			return nil, fmt.Errorf("gemini: %s part has neither url nor data", part.Type)
+		}
+	}
+
+	return result, nil
+}
+
+// functionResponseBody 将工具结果转换为 functionResponse.response（必须是 JSON 对象）
+func functionResponseBody(content string) map[string]any {
+	var body map[string]any
+	if err := json.Unmarshal([]byte(content), &body); err == nil && body != nil {
+		return body
+	}
+	return map[string]any{"result": content}
+}
+
+// toGeminiSchema 将 JSON Schema 转换为 Gemini 支持的 OpenAPI Schema 子集
+//
+// Gemini 不接受 additionalProperties 等字段，这些字段会被递归移除。
+//
+func toGeminiSchema(schema types.Schema) map[string]any {
+	result := schema.ToMap()
+	stripUnsupported(result)
+	return result
+}
+
+// stripUnsupported 递归移除 Gemini 不支持的 Schema 字段
+func stripUnsupported(schema map[string]any) {
+	delete(schema, "additionalProperties")
+	delete(schema, "$schema")
+
+	if props, ok := schema["properties"].(map[string]any); ok {
+		for _, prop := range props {
+			if propSchema, ok := prop.(map[string]any); ok {
+				stripUnsupported(propSchema)
+			}
+		}
+	}
+	if items, ok := schema["items"].(map[string]any); ok {
+		stripUnsupported(items)
+	}
+}
+
+// newRequest 创建 generateContent / streamGenerateContent 请求
+func (c *GeminiClient) newRequest(ctx context.Context, reqBody *GeminiRequest, stream bool) (*http.Request, error) {
 	bodyBytes, err := json.Marshal(reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("gemini: failed to marshal request: %w", err)
 	}
-	
+
 	url := fmt.Sprintf("%s/models/%s:generateContent?key=%s",
-		config.BaseURL, config.Model, config.APIKey)
-	
+		c.config.BaseURL, c.config.Model, c.config.APIKey)
+	if stream {
+		url = fmt.Sprintf("%s/models/%s:streamGenerateContent?key=%s&alt=sse",
+			c.config.BaseURL, c.config.Model, c.config.APIKey)
+	}
+
 	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(bodyBytes))
 	if err != nil {
 		return nil, fmt.Errorf("gemini: failed to create request: %w", err)
 	}
-	
+
 	req.Header.Set("Content-Type", "application/json")
-	
+
+	return req, nil
+}
+
+func (c *GeminiClient) generateContent(ctx context.Context, reqBody *GeminiRequest) (*GeminiResponse, error) {
+	req, err := c.newRequest(ctx, reqBody, false)
+	if err != nil {
+		return nil, err
+	}
+
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("gemini: request failed: %w", err)
+		return nil, chaterrors.FromTransport(c.GetProvider(), fmt.Errorf("gemini: request failed: %w", err))
 	}
 	defer resp.Body.Close()
-	
+
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("gemini: API error (status %d): %s", resp.StatusCode, string(body))
+		return nil, chaterrors.FromResponse(c.GetProvider(), resp, body)
 	}
-	
+
 	var response GeminiResponse
 	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
 		return nil, fmt.Errorf("gemini: failed to decode response: %w", err)
 	}
-	
+
 	return &response, nil
 }
 
-func (c *GeminiClient) streamContent(ctx context.Context, config *Config, reqBody *GeminiRequest, eventChan chan<- types.StreamEvent) error {
-	bodyBytes, err := json.Marshal(reqBody)
-	if err != nil {
-		return fmt.Errorf("gemini: failed to marshal request: %w", err)
-	}
-	
-	url := fmt.Sprintf("%s/models/%s:streamGenerateContent?key=%s&alt=sse",
-		config.BaseURL, config.Model, config.APIKey)
-	
-	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(bodyBytes))
+func (c *GeminiClient) streamContent(ctx context.Context, reqBody *GeminiRequest, out chan<- runnable.StreamEvent[types.Message]) error {
+	resp, err := c.doStreamRequest(ctx, reqBody)
 	if err != nil {
-		return fmt.Errorf("gemini: failed to create request: %w", err)
-	}
-	
-	req.Header.Set("Content-Type", "application/json")
-	
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("gemini: request failed: %w", err)
+		return err
 	}
 	defer resp.Body.Close()
-	
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("gemini: API error (status %d): %s", resp.StatusCode, string(body))
-	}
-	
+
 	// 解析 SSE 流
-	return c.parseSSEStream(resp.Body, eventChan)
+	return c.parseSSEStream(resp.Body, out)
 }
 
-func (c *GeminiClient) parseSSEStream(reader io.Reader, eventChan chan<- types.StreamEvent) error {
-	buf := make([]byte, 4096)
-	leftover := ""
-	
-	for {
-		n, err := reader.Read(buf)
-		if err != nil && err != io.EOF {
-			return fmt.Errorf("gemini: failed to read stream: %w", err)
+// parseSSEStream 解析 SSE 流，转发文本增量并收集函数调用
+func (c *GeminiClient) parseSSEStream(reader io.Reader, out chan<- runnable.StreamEvent[types.Message]) error {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	fullMessage := types.Message{Role: types.RoleAssistant}
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+
+		var chunk GeminiResponse
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &chunk); err != nil {
+			continue
 		}
-		
-		if n == 0 {
-			break
+		// 每个数据块都携带累计用量，保留最后一个
+		if chunk.UsageMetadata != nil {
+			fullMessage.UsageMetadata = chunk.UsageMetadata.toUsageMetadata()
+		}
+
+		if len(chunk.Candidates) == 0 {
+			continue
+		}
+
+		candidate := chunk.Candidates[0]
+		if candidate.FinishReason == "SAFETY" {
+			return fmt.Errorf("gemini: content blocked due to safety settings")
 		}
-		
-		data := leftover + string(buf[:n])
-		lines := strings.Split(data, "\n")
-		
-		// 保留最后一个不完整的行
-		if !strings.HasSuffix(data, "\n") {
-			leftover = lines[len(lines)-1]
-			lines = lines[:len(lines)-1]
-		} else {
-			leftover = ""
+		if candidate.FinishReason != "" {
+			fullMessage.FinishReason = chat.NormalizeFinishReason(candidate.FinishReason)
 		}
-		
-		// 处理每一行
-		for _, line := range lines {
-			line = strings.TrimSpace(line)
-			if line == "" || !strings.HasPrefix(line, "data: ") {
+
+		for _, part := range candidate.Content.Parts {
+			if part.FunctionCall != nil {
+				toolCall, err := toToolCall(part.FunctionCall)
+				if err != nil {
+					return err
+				}
+				fullMessage.ToolCalls = append(fullMessage.ToolCalls, toolCall)
 				continue
 			}
-			
-			jsonData := strings.TrimPrefix(line, "data: ")
-			if jsonData == "[DONE]" {
-				break
-			}
-			
-			var chunk GeminiResponse
-			if err := json.Unmarshal([]byte(jsonData), &chunk); err != nil {
+
+			// 思考摘要以思考块发送，不计入 Content
+			if part.Thought {
+				if len(fullMessage.ContentBlocks) == 0 {
+					fullMessage.ContentBlocks = []*types.ContentBlock{types.NewThinkingContentBlock("")}
+				}
+				fullMessage.ContentBlocks[0].Content += part.Text
+				out <- runnable.StreamEvent[types.Message]{
+					Type: runnable.EventStream,
+					Data: types.Message{
+						Role:          types.RoleAssistant,
+						ContentBlocks: []*types.ContentBlock{types.NewThinkingContentBlock(part.Text)},
+					},
+					Name: c.GetName(),
+				}
 				continue
 			}
-			
-			// 提取文本
-			if len(chunk.Candidates) > 0 && len(chunk.Candidates[0].Content.Parts) > 0 {
-				text := chunk.Candidates[0].Content.Parts[0].Text
-				eventChan <- types.StreamEvent{
+
+			if part.Text != "" {
+				fullMessage.Content += part.Text
+				out <- runnable.StreamEvent[types.Message]{
+					Type: runnable.EventStream,
 					Data: types.Message{
 						Role:    types.RoleAssistant,
-						Content: text,
+						Content: part.Text,
 					},
+					Name: c.GetName(),
 				}
 			}
 		}
-		
-		if err == io.EOF {
-			break
-		}
 	}
-	
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("gemini: failed to read stream: %w", err)
+	}
+
+	fullMessage.FinishReason = finishReason(fullMessage)
+
+	out <- runnable.StreamEvent[types.Message]{
+		Type: runnable.EventEnd,
+		Data: fullMessage,
+		Name: c.GetName(),
+	}
+
 	return nil
 }
 
@@ -389,29 +715,76 @@ func (c *GeminiClient) parseResponse(response *GeminiResponse) (types.Message, e
 	if len(response.Candidates) == 0 {
 		return types.Message{}, fmt.Errorf("gemini: no candidates in response")
 	}
-	
+
 	candidate := response.Candidates[0]
-	
+
 	// 检查安全评级
 	if candidate.FinishReason == "SAFETY" {
 		return types.Message{}, fmt.Errorf("gemini: content blocked due to safety settings")
 	}
-	
-	// 提取文本
+
+	message := types.Message{Role: types.RoleAssistant}
+
+	// 提取文本和函数调用
 	var content strings.Builder
 	for _, part := range candidate.Content.Parts {
+		if part.FunctionCall != nil {
+			toolCall, err := toToolCall(part.FunctionCall)
+			if err != nil {
+				return types.Message{}, err
+			}
+			message.ToolCalls = append(message.ToolCalls, toolCall)
+			continue
+		}
+		if part.Thought {
+			message.ContentBlocks = append(message.ContentBlocks, types.NewThinkingContentBlock(part.Text))
+			continue
+		}
 		content.WriteString(part.Text)
 	}
-	
-	return types.Message{
-		Role:    types.RoleAssistant,
-		Content: content.String(),
+	message.Content = content.String()
+	message.UsageMetadata = response.UsageMetadata.toUsageMetadata()
+	message.FinishReason = chat.NormalizeFinishReason(candidate.FinishReason)
+	message.FinishReason = finishReason(message)
+
+	return message, nil
+}
+
+// finishReason 返回消息的结束原因
+//
+// Gemini 调用函数时 finishReason 仍为 STOP，这里与其他提供商保持一致返回 tool_calls。
+//
+func finishReason(message types.Message) types.FinishReason {
+	if message.FinishReason == types.FinishReasonStop && len(message.ToolCalls) > 0 {
+		return types.FinishReasonToolCalls
+	}
+	return message.FinishReason
+}
+
+// toToolCall 将 Gemini 函数调用转换为 ToolCall，并生成唯一 ID
+func toToolCall(call *FunctionCall) (types.ToolCall, error) {
+	args := call.Args
+	if args == nil {
+		args = map[string]any{}
+	}
+	arguments, err := json.Marshal(args)
+	if err != nil {
+		return types.ToolCall{}, fmt.Errorf("gemini: failed to marshal function args: %w", err)
+	}
+
+	return types.ToolCall{
+		ID:   "call_" + uuid.NewString(),
+		Type: "function",
+		Function: types.FunctionCall{
+			Name:      call.Name,
+			Arguments: string(arguments),
+		},
 	}, nil
 }
 
 // ==================== 选项模式 ====================
 
-// Option 配置选项
+// Option 配置选项，通过 WithOptions 应用
 type Option func(*Config)
 
 // WithTemperature 设置温度
@@ -449,6 +822,13 @@ func WithStopSequences(sequences []string) Option {
 	}
 }
 
+// WithThinking 设置思考配置
+func WithThinking(thinking *types.ThinkingConfig) Option {
+	return func(c *Config) {
+		c.Thinking = thinking
+	}
+}
+
 // WithSafetySettings 设置安全级别
 func WithSafetySettings(settings []SafetySetting) Option {
 	return func(c *Config) {
@@ -461,6 +841,8 @@ func WithSafetySettings(settings []SafetySetting) Option {
 // GeminiRequest Gemini API 请求
 type GeminiRequest struct {
 	Contents         []Content        `json:"contents"`
+	Tools            []Tool           `json:"tools,omitempty"`
+	ToolConfig       *ToolConfig      `json:"toolConfig,omitempty"`
 	GenerationConfig GenerationConfig `json:"generationConfig,omitempty"`
 	SafetySettings   []SafetySetting  `json:"safetySettings,omitempty"`
 }
@@ -473,21 +855,110 @@ type Content struct {
 
 // Part 内容部分
 type Part struct {
-	Text string `json:"text,omitempty"`
+	Text             string            `json:"text,omitempty"`
+	Thought          bool              `json:"thought,omitempty"`
+	InlineData       *Blob             `json:"inlineData,omitempty"`
+	FileData         *FileData         `json:"fileData,omitempty"`
+	FunctionCall     *FunctionCall     `json:"functionCall,omitempty"`
+	FunctionResponse *FunctionResponse `json:"functionResponse,omitempty"`
+}
+
+// Blob 内联数据（Base64）
+type Blob struct {
+	MimeType string `json:"mimeType"`
+	Data     string `json:"data"`
+}
+
+// FileData 通过 URI 引用的文件
+type FileData struct {
+	MimeType string `json:"mimeType"`
+	FileURI  string `json:"fileUri"`
+}
+
+// FunctionCall 模型请求的函数调用
+type FunctionCall struct {
+	Name string         `json:"name"`
+	Args map[string]any `json:"args"`
+}
+
+// FunctionResponse 函数执行结果
+type FunctionResponse struct {
+	Name     string         `json:"name"`
+	Response map[string]any `json:"response"`
+}
+
+// Tool 工具定义
+type Tool struct {
+	FunctionDeclarations []FunctionDeclaration `json:"functionDeclarations"`
+}
+
+// ToolConfig 工具调用配置
+type ToolConfig struct {
+	FunctionCallingConfig *FunctionCallingConfig `json:"functionCallingConfig,omitempty"`
+}
+
+// FunctionCallingConfig 函数调用模式
+type FunctionCallingConfig struct {
+	Mode                 string   `json:"mode"`
+	AllowedFunctionNames []string `json:"allowedFunctionNames,omitempty"`
+}
+
+// FunctionDeclaration 函数声明
+type FunctionDeclaration struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Parameters  map[string]any `json:"parameters,omitempty"`
 }
 
 // GenerationConfig 生成配置
 type GenerationConfig struct {
-	Temperature     float32  `json:"temperature,omitempty"`
-	TopP            float32  `json:"topP,omitempty"`
-	TopK            int      `json:"topK,omitempty"`
-	MaxOutputTokens int      `json:"maxOutputTokens,omitempty"`
-	StopSequences   []string `json:"stopSequences,omitempty"`
+	Temperature      *float32        `json:"temperature,omitempty"`
+	TopP             float32         `json:"topP,omitempty"`
+	TopK             int             `json:"topK,omitempty"`
+	MaxOutputTokens  int             `json:"maxOutputTokens,omitempty"`
+	StopSequences    []string        `json:"stopSequences,omitempty"`
+	Seed             *int            `json:"seed,omitempty"`
+	ResponseMimeType string          `json:"responseMimeType,omitempty"`
+	ResponseSchema   map[string]any  `json:"responseSchema,omitempty"`
+	ThinkingConfig   *ThinkingConfig `json:"thinkingConfig,omitempty"`
+}
+
+// ThinkingConfig 思考配置
+type ThinkingConfig struct {
+	ThinkingBudget  *int `json:"thinkingBudget,omitempty"`
+	IncludeThoughts bool `json:"includeThoughts,omitempty"`
 }
 
 // GeminiResponse Gemini API 响应
 type GeminiResponse struct {
-	Candidates []Candidate `json:"candidates"`
+	Candidates    []Candidate    `json:"candidates"`
+	UsageMetadata *UsageMetadata `json:"usageMetadata,omitempty"`
+}
+
+// UsageMetadata token 使用情况
+//
+// candidatesTokenCount 不包含思考 token（thoughtsTokenCount）。
+//
+type UsageMetadata struct {
+	PromptTokenCount        int `json:"promptTokenCount"`
+	CandidatesTokenCount    int `json:"candidatesTokenCount"`
+	TotalTokenCount         int `json:"totalTokenCount"`
+	CachedContentTokenCount int `json:"cachedContentTokenCount,omitempty"`
+	ThoughtsTokenCount      int `json:"thoughtsTokenCount,omitempty"`
+}
+
+// toUsageMetadata 转换为标准用量，u 为 nil 时返回 nil
+func (u *UsageMetadata) toUsageMetadata() *types.UsageMetadata {
+	if u == nil {
+		return nil
+	}
+	return &types.UsageMetadata{
+		InputTokens:     u.PromptTokenCount,
+		OutputTokens:    u.CandidatesTokenCount + u.ThoughtsTokenCount,
+		TotalTokens:     u.TotalTokenCount,
+		CachedTokens:    u.CachedContentTokenCount,
+		ReasoningTokens: u.ThoughtsTokenCount,
+	}
 }
 
 // Candidate 候选响应
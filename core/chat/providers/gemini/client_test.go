@@ -1,8 +1,16 @@
 package gemini
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/zhucl121/langchain-go/core/chat"
+	"github.com/zhucl121/langchain-go/core/runnable"
 	"github.com/zhucl121/langchain-go/pkg/types"
 )
 
@@ -145,6 +153,38 @@ func TestConvertMessages(t *testing.T) {
 	}
 }
 
+func TestConvertMessages_Parts(t *testing.T) {
+	client := &GeminiClient{config: Config{APIKey: "test", Model: "gemini-1.5-pro"}}
+
+	msg := types.NewMessageWithParts(types.RoleUser,
+		types.NewTextContent("Transcribe and describe"),
+		types.NewImageContentFromData([]byte("png"), types.ImageFormatPNG),
+		types.NewAudioContentFromData([]byte("wav"), types.AudioFormatWAV),
+		types.NewFileContent("https://generativelanguage.googleapis.com/v1beta/files/abc", "application/pdf"),
+	)
+
+	contents, err := client.convertMessages([]types.Message{msg})
+	if err != nil {
+		t.Fatalf("convertMessages failed: %v", err)
+	}
+
+	data, _ := json.Marshal(contents[0].Parts)
+	want := `[{"text":"Transcribe and describe"},` +
+		`{"inlineData":{"mimeType":"image/png","data":"cG5n"}},` +
+		`{"inlineData":{"mimeType":"audio/wav","data":"d2F2"}},` +
+		`{"fileData":{"mimeType":"application/pdf","fileUri":"https://generativelanguage.googleapis.com/v1beta/files/abc"}}]`
+	if string(data) != want {
+		t.Errorf("parts = %s\nwant %s", data, want)
+	}
+
+	// 没有媒体类型时报错
+	_, err = client.convertMessages([]types.Message{types.NewMessageWithParts(types.RoleUser,
+		types.NewFileContent("gs://bucket/file", ""))})
+	if err == nil {
+		t.Error("expected error for missing media type")
+	}
+}
+
 func TestOptions(t *testing.T) {
 	config := Config{
 		APIKey: "test",
@@ -204,6 +244,292 @@ func TestOptions(t *testing.T) {
 	})
 }
 
+// weatherTool 测试用工具
+var weatherTool = types.Tool{
+	Name:        "get_weather",
+	Description: "Get the weather for a city",
+	Parameters: types.Schema{
+		Type: "object",
+		Properties: map[string]types.Schema{
+			"city": {Type: "string"},
+		},
+		Required: []string{"city"},
+	},
+}
+
+// newTestClient 创建指向测试服务器的客户端
+func newTestClient(t *testing.T, handler http.HandlerFunc) *GeminiClient {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := New(Config{
+		APIKey:  "test-key",
+		Model:   "gemini-1.5-flash",
+		BaseURL: server.URL,
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	return client
+}
+
+func TestChatModelInterface(t *testing.T) {
+	var model chat.ChatModel
+	model, _ = New(Config{APIKey: "k", Model: "gemini-1.5-pro"})
+
+	if model.GetProvider() != "gemini" || model.GetModelName() != "gemini-1.5-pro" {
+		t.Errorf("unexpected provider/model: %s/%s", model.GetProvider(), model.GetModelName())
+	}
+
+	bound := model.BindTools([]types.Tool{weatherTool})
+	if len(bound.(*GeminiClient).GetBoundTools()) != 1 || len(model.(*GeminiClient).GetBoundTools()) != 0 {
+		t.Error("BindTools should return a new instance with tools")
+	}
+}
+
+func TestInvoke_FunctionCalling(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/models/gemini-1.5-flash:generateContent" || r.URL.Query().Get("key") != "test-key" {
+			t.Errorf("unexpected request: %s", r.URL)
+		}
+
+		var req GeminiRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		if len(req.Tools) != 1 || req.Tools[0].FunctionDeclarations[0].Name != "get_weather" {
+			t.Errorf("expected function declarations, got %+v", req.Tools)
+		}
+
+		// model 轮携带 functionCall，两个工具结果合并为一个 functionResponse 轮
+		if len(req.Contents) != 3 {
+			t.Fatalf("expected 3 contents, got %+v", req.Contents)
+		}
+		model := req.Contents[1]
+		if model.Role != "model" || model.Parts[0].FunctionCall == nil || model.Parts[0].FunctionCall.Args["city"] != "Paris" {
+			t.Errorf("unexpected model turn: %+v", model)
+		}
+		results := req.Contents[2]
+		if len(results.Parts) != 2 || results.Parts[0].FunctionResponse.Name != "get_weather" ||
+			results.Parts[0].FunctionResponse.Response["result"] != "sunny" ||
+			results.Parts[1].FunctionResponse.Response["temp"] != float64(12) {
+			t.Errorf("unexpected function responses: %+v", results)
+		}
+
+		fmt.Fprint(w, `{"candidates":[{"content":{"role":"model","parts":[
+			{"text":"Checking Rome."},
+			{"functionCall":{"name":"get_weather","args":{"city":"Rome"}}}]},"finishReason":"STOP"}],
+			"usageMetadata":{"promptTokenCount":40,"candidatesTokenCount":10,"thoughtsTokenCount":6,"totalTokenCount":56,"cachedContentTokenCount":16}}`)
+	})
+
+	messages := []types.Message{
+		types.NewUserMessage("Weather in Paris and Oslo?"),
+		{Role: types.RoleAssistant, ToolCalls: []types.ToolCall{
+			{ID: "call_1", Type: "function", Function: types.FunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`}},
+			{ID: "call_2", Type: "function", Function: types.FunctionCall{Name: "get_weather", Arguments: `{"city":"Oslo"}`}},
+		}},
+		types.NewToolMessage("call_1", "sunny"),
+		types.NewToolMessage("call_2", `{"temp":12}`),
+	}
+
+	response, err := client.BindTools([]types.Tool{weatherTool}).Invoke(context.Background(), messages)
+	if err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+	if response.Content != "Checking Rome." {
+		t.Errorf("unexpected content: %q", response.Content)
+	}
+	if len(response.ToolCalls) != 1 || response.ToolCalls[0].ID == "" ||
+		response.ToolCalls[0].Function.Name != "get_weather" || response.ToolCalls[0].Function.Arguments != `{"city":"Rome"}` {
+		t.Errorf("unexpected tool calls: %+v", response.ToolCalls)
+	}
+	if response.FinishReason != types.FinishReasonToolCalls {
+		t.Errorf("unexpected finish reason: %q", response.FinishReason)
+	}
+	want := types.UsageMetadata{InputTokens: 40, OutputTokens: 16, TotalTokens: 56, CachedTokens: 16, ReasoningTokens: 6}
+	if response.UsageMetadata == nil || *response.UsageMetadata != want {
+		t.Errorf("unexpected usage: %+v", response.UsageMetadata)
+	}
+}
+
+func TestStream(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/models/gemini-1.5-flash:streamGenerateContent" || r.URL.Query().Get("alt") != "sse" {
+			t.Errorf("unexpected request: %s", r.URL)
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, chunk := range []string{
+			`{"candidates":[{"content":{"role":"model","parts":[{"text":"Hello"}]}}]}`,
+			`{"candidates":[{"content":{"role":"model","parts":[{"text":" world"}]}}],"usageMetadata":{"promptTokenCount":5,"candidatesTokenCount":2,"totalTokenCount":7}}`,
+			`{"candidates":[{"content":{"role":"model","parts":[{"functionCall":{"name":"get_weather","args":{"city":"Oslo"}}}]},"finishReason":"STOP"}]}`,
+		} {
+			fmt.Fprintf(w, "data: %s\r\n\r\n", chunk)
+		}
+	})
+
+	stream, err := client.Stream(context.Background(), []types.Message{types.NewUserMessage("Hi")})
+	if err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+
+	var content strings.Builder
+	var final types.Message
+	hasStart := false
+	for event := range stream {
+		switch event.Type {
+		case runnable.EventStart:
+			hasStart = true
+		case runnable.EventStream:
+			content.WriteString(event.Data.Content)
+		case runnable.EventEnd:
+			final = event.Data
+		case runnable.EventError:
+			t.Fatalf("stream error: %v", event.Error)
+		}
+	}
+
+	if !hasStart || content.String() != "Hello world" || final.Content != "Hello world" {
+		t.Errorf("unexpected stream: start=%v content=%q final=%q", hasStart, content.String(), final.Content)
+	}
+	if len(final.ToolCalls) != 1 || final.ToolCalls[0].Function.Arguments != `{"city":"Oslo"}` {
+		t.Errorf("unexpected tool calls: %+v", final.ToolCalls)
+	}
+	if final.FinishReason != types.FinishReasonToolCalls || final.UsageMetadata == nil || final.UsageMetadata.TotalTokens != 7 {
+		t.Errorf("unexpected finish reason / usage: %q %+v", final.FinishReason, final.UsageMetadata)
+	}
+}
+
+func TestThinking(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var req GeminiRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		thinking := req.GenerationConfig.ThinkingConfig
+		if thinking == nil || thinking.ThinkingBudget == nil || *thinking.ThinkingBudget != 2048 || !thinking.IncludeThoughts {
+			t.Errorf("unexpected thinking config: %+v", thinking)
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, chunk := range []string{
+			`{"candidates":[{"content":{"role":"model","parts":[{"text":"Thinking about it.","thought":true}]}}]}`,
+			`{"candidates":[{"content":{"role":"model","parts":[{"text":"42"}]},"finishReason":"STOP"}]}`,
+		} {
+			fmt.Fprintf(w, "data: %s\r\n\r\n", chunk)
+		}
+	})
+
+	stream, err := client.Stream(context.Background(), []types.Message{types.NewUserMessage("6*7?")},
+		runnable.WithThinking(types.NewThinkingConfig(2048)))
+	if err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+
+	var content, thinking string
+	var final types.Message
+	for event := range stream {
+		switch event.Type {
+		case runnable.EventStream:
+			content += event.Data.Content
+			thinking += event.Data.ThinkingText()
+		case runnable.EventEnd:
+			final = event.Data
+		case runnable.EventError:
+			t.Fatalf("stream error: %v", event.Error)
+		}
+	}
+
+	if content != "42" || thinking != "Thinking about it." {
+		t.Errorf("unexpected stream: content=%q thinking=%q", content, thinking)
+	}
+	if final.Content != "42" || final.ThinkingText() != "Thinking about it." {
+		t.Errorf("unexpected final message: %+v", final)
+	}
+
+	// 关闭思考时预算为 0，模型未配置时不发送
+	if config := toThinkingConfig(&types.ThinkingConfig{}); *config.ThinkingBudget != 0 || config.IncludeThoughts {
+		t.Errorf("unexpected disabled config: %+v", config)
+	}
+	if config := toThinkingConfig(&types.ThinkingConfig{Enabled: true}); *config.ThinkingBudget != -1 {
+		t.Errorf("expected dynamic budget, got %d", *config.ThinkingBudget)
+	}
+	req, _ := client.buildRequest([]types.Message{types.NewUserMessage("hi")}, runnable.NewOptions())
+	if req.GenerationConfig.ThinkingConfig != nil {
+		t.Errorf("expected no thinking config, got %+v", req.GenerationConfig.ThinkingConfig)
+	}
+}
+
+func TestInvoke_CallOptions(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var req GeminiRequest
+		json.NewDecoder(r.Body).Decode(&req)
+
+		config := req.GenerationConfig
+		if config.Temperature == nil || *config.Temperature != 0 || config.MaxOutputTokens != 64 {
+			t.Errorf("expected per-call temperature and max tokens, got %+v", config)
+		}
+		if len(config.StopSequences) != 1 || config.Seed == nil || *config.Seed != 7 {
+			t.Errorf("expected stop sequences and seed, got %+v", config)
+		}
+		if config.ResponseMimeType != "application/json" || config.ResponseSchema["type"] != "object" {
+			t.Errorf("expected response schema, got %+v", config)
+		}
+		calling := req.ToolConfig.FunctionCallingConfig
+		if calling.Mode != "ANY" || len(calling.AllowedFunctionNames) != 1 || calling.AllowedFunctionNames[0] != "get_weather" {
+			t.Errorf("unexpected function calling config: %+v", calling)
+		}
+		fmt.Fprint(w, `{"candidates":[{"content":{"role":"model","parts":[{"text":"{}"}]},"finishReason":"STOP"}]}`)
+	})
+
+	model := client.BindTools([]types.Tool{{Name: "get_weather", Parameters: types.Schema{Type: "object"}}})
+	_, err := model.Invoke(context.Background(), []types.Message{types.NewUserMessage("hi")},
+		runnable.WithTemperature(0),
+		runnable.WithMaxTokens(64),
+		runnable.WithStop("END"),
+		runnable.WithSeed(7),
+		runnable.WithToolChoice(types.NewSpecificToolChoice("get_weather")),
+		runnable.WithResponseFormat(types.NewJSONSchemaFormat("result", types.Schema{Type: "object"})))
+	if err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+}
+
+func TestStructuredOutput(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var req GeminiRequest
+		json.NewDecoder(r.Body).Decode(&req)
+
+		config := req.GenerationConfig
+		if config.ResponseMimeType != "application/json" || config.ResponseSchema["type"] != "object" {
+			t.Errorf("expected response schema, got %+v", config)
+		}
+		if _, ok := config.ResponseSchema["additionalProperties"]; ok {
+			t.Error("additionalProperties should be removed from the schema")
+		}
+		if config.TopK != 5 {
+			t.Errorf("expected topK 5, got %d", config.TopK)
+		}
+		fmt.Fprint(w, `{"candidates":[{"content":{"role":"model","parts":[{"text":"{\"answer\":42}"}]}}]}`)
+	})
+
+	closed := false
+	schema := types.Schema{
+		Type:                 "object",
+		Properties:           map[string]types.Schema{"answer": {Type: "integer"}},
+		AdditionalProperties: &closed,
+	}
+	model := client.WithOptions(WithTopK(5)).WithStructuredOutput(schema)
+
+	response, err := model.Invoke(context.Background(), []types.Message{types.NewUserMessage("?")})
+	if err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+	if response.Content != `{"answer":42}` {
+		t.Errorf("unexpected content: %q", response.Content)
+	}
+}
+
 // 集成测试（需要真实 API Key）
 func TestGeminiIntegration(t *testing.T) {
 	t.Skip("Integration test - requires GOOGLE_API_KEY environment variable")
@@ -27,19 +27,35 @@
 //
 //	stream, _ := client.Stream(ctx, messages)
 //	for event := range stream {
-//	    if event.Error != nil {
+//	    switch event.Type {
+//	    case runnable.EventStream:
+//	        fmt.Print(event.Data.Content)
+//	    case runnable.EventError:
 //	        log.Fatal(event.Error)
 //	    }
-//	    fmt.Print(event.Data.Content)
 //	}
 //
+// 工具调用：
+//
+// BindTools 把工具转换为 Gemini 的 functionDeclarations。返回的 functionCall
+// 转换为 types.ToolCall（Gemini 不返回调用 ID，会自动生成），
+// 工具结果消息按 ToolCallID 找回函数名后作为 functionResponse 发送：
+//
+//	modelWithTools := client.BindTools([]types.Tool{weatherTool})
+//	response, _ := modelWithTools.Invoke(ctx, messages)
+//	messages = append(messages, response,
+//	    types.NewToolMessage(response.ToolCalls[0].ID, `{"temp": 12}`))
+//
+// GeminiClient 实现了 chat.ChatModel，可以直接用于 Agent、StateGraph
+// 或作为其他模型的 fallback。
+//
 // 自定义参数：
 //
-//	response, _ := client.Invoke(ctx, messages,
+//	response, _ := client.WithOptions(
 //	    gemini.WithTemperature(0.9),
 //	    gemini.WithMaxTokens(1000),
 //	    gemini.WithTopP(0.95),
-//	)
+//	).Invoke(ctx, messages)
 //
 // 安全设置：
 //
@@ -49,9 +65,7 @@
 //	        Threshold: "BLOCK_MEDIUM_AND_ABOVE",
 //	    },
 //	}
-//	response, _ := client.Invoke(ctx, messages,
-//	    gemini.WithSafetySettings(safetySettings),
-//	)
+//	safeClient := client.WithOptions(gemini.WithSafetySettings(safetySettings))
 //
 // 特点：
 //   - 支持超长上下文（gemini-1.5-pro 支持 100万+ tokens）
@@ -2,7 +2,6 @@ package gemini
 
 import (
 	"bufio"
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -10,6 +9,8 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/zhucl121/langchain-go/core/chat"
+	chaterrors "github.com/zhucl121/langchain-go/core/chat/errors"
 	"github.com/zhucl121/langchain-go/pkg/types"
 )
 
@@ -18,37 +19,21 @@ import (
 // 参数：
 //   - ctx: 上下文
 //   - messages: 输入消息列表
+//   - opts: 选项
 //
 // 返回：
 //   - <-chan types.StreamEvent: 流式事件 channel
 //   - error: 错误
 //
 func (c *GeminiClient) StreamTokens(ctx context.Context, messages []types.Message, opts ...Option) (<-chan types.StreamEvent, error) {
-	// 应用选项
-	config := c.config
-	for _, opt := range opts {
-		opt(&config)
-	}
+	client := c.WithOptions(opts...)
 
-	// 转换消息格式
-	contents, err := c.convertMessages(messages)
+	// 构建请求
+	reqBody, err := client.buildRequest(messages, chat.ResolveOptions(client.config.Thinking))
 	if err != nil {
 		return nil, err
 	}
 
-	// 构建请求
-	reqBody := GeminiRequest{
-		Contents: contents,
-		GenerationConfig: GenerationConfig{
-			Temperature:     config.Temperature,
-			TopP:            config.TopP,
-			TopK:            config.TopK,
-			MaxOutputTokens: config.MaxTokens,
-			StopSequences:   config.StopSequences,
-		},
-		SafetySettings: config.SafetySettings,
-	}
-
 	// 创建输出 channel
 	out := make(chan types.StreamEvent, 100)
 
@@ -59,13 +44,13 @@ func (c *GeminiClient) StreamTokens(ctx context.Context, messages []types.Messag
 		out <- types.StreamEvent{
 			Type: types.StreamEventStart,
 			Metadata: map[string]any{
-				"model":    config.Model,
-				"provider": "gemini",
+				"model":    client.GetModelName(),
+				"provider": client.GetProvider(),
 			},
 		}
 
 		// 发送请求
-		resp, err := c.doStreamRequest(ctx, reqBody)
+		resp, err := client.doStreamRequest(ctx, reqBody)
 		if err != nil {
 			out <- types.NewErrorEvent(err)
 			return
@@ -73,7 +58,7 @@ func (c *GeminiClient) StreamTokens(ctx context.Context, messages []types.Messag
 		defer resp.Body.Close()
 
 		// 处理流式响应
-		if err := c.processTokenStream(resp.Body, out); err != nil {
+		if err := client.processTokenStream(resp.Body, out); err != nil {
 			out <- types.NewErrorEvent(err)
 			return
 		}
@@ -97,15 +82,12 @@ func (c *GeminiClient) processTokenStream(reader io.Reader, out chan<- types.Str
 	for scanner.Scan() {
 		line := scanner.Text()
 
-		// Gemini 使用 JSON 数组格式的流式响应
-		// 每行是一个完整的 JSON 对象
-		if line == "" || line == "[" || line == "]" {
+		// alt=sse 时每个数据块是一行 "data: {...}"
+		line = strings.TrimPrefix(strings.TrimSpace(line), "data: ")
+		if line == "" {
 			continue
 		}
 
-		// 去除可能的逗号
-		line = strings.TrimSuffix(strings.TrimSpace(line), ",")
-
 		// 解析 JSON
 		var chunk GeminiResponse
 		if err := json.Unmarshal([]byte(line), &chunk); err != nil {
@@ -122,7 +104,7 @@ func (c *GeminiClient) processTokenStream(reader io.Reader, out chan<- types.Str
 		// 处理内容部分
 		if len(candidate.Content.Parts) > 0 {
 			for _, part := range candidate.Content.Parts {
-				if part.Text != "" {
+				if part.Text != "" && !part.Thought {
 					fullContent.WriteString(part.Text)
 
 					tokenEvent := types.NewTokenEvent(part.Text)
@@ -223,38 +205,23 @@ func (c *GeminiClient) StreamWithAggregation(ctx context.Context, messages []typ
 }
 
 // doStreamRequest 执行流式请求。
-func (c *GeminiClient) doStreamRequest(ctx context.Context, reqBody GeminiRequest) (*http.Response, error) {
-	// 构建 URL（添加 streamGenerateContent 和 alt=sse）
-	url := fmt.Sprintf("%s/models/%s:streamGenerateContent?key=%s&alt=sse",
-		c.config.BaseURL,
-		c.config.Model,
-		c.config.APIKey)
-
-	// 序列化请求体
-	jsonData, err := json.Marshal(reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("gemini: failed to marshal request: %w", err)
-	}
-
-	// 创建请求
-	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(jsonData))
+func (c *GeminiClient) doStreamRequest(ctx context.Context, reqBody *GeminiRequest) (*http.Response, error) {
+	req, err := c.newRequest(ctx, reqBody, true)
 	if err != nil {
-		return nil, fmt.Errorf("gemini: failed to create request: %w", err)
+		return nil, err
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-
 	// 发送请求
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("gemini: request failed: %w", err)
+		return nil, chaterrors.FromTransport(c.GetProvider(), fmt.Errorf("gemini: request failed: %w", err))
 	}
 
 	// 检查状态码
 	if resp.StatusCode != http.StatusOK {
 		defer resp.Body.Close()
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("gemini: API error (status %d): %s", resp.StatusCode, string(body))
+		return nil, chaterrors.FromResponse(c.GetProvider(), resp, body)
 	}
 
 	return resp, nil
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -11,6 +12,7 @@ import (
 	"time"
 
 	"github.com/zhucl121/langchain-go/core/chat"
+	chaterrors "github.com/zhucl121/langchain-go/core/chat/errors"
 	"github.com/zhucl121/langchain-go/core/runnable"
 	"github.com/zhucl121/langchain-go/pkg/types"
 )
@@ -143,13 +145,20 @@ func New(config Config) (*ChatModel, error) {
 
 // Invoke implements the Runnable interface, performs a single invocation.
 func (m *ChatModel) Invoke(ctx context.Context, messages []types.Message, opts ...runnable.Option) (types.Message, error) {
+	return chat.TraceInvoke(ctx, m, messages, opts, func(ctx context.Context) (types.Message, error) {
+		return m.invoke(ctx, messages, opts...)
+	})
+}
+
+// invoke performs a single invocation.
+func (m *ChatModel) invoke(ctx context.Context, messages []types.Message, opts ...runnable.Option) (types.Message, error) {
 	// Validate messages
 	if err := chat.ValidateMessages(messages); err != nil {
 		return types.Message{}, err
 	}
 
 	// Build request
-	reqBody, err := m.buildRequest(messages, false)
+	reqBody, err := m.buildRequest(messages, false, runnable.NewOptions(opts...))
 	if err != nil {
 		return types.Message{}, fmt.Errorf("failed to build request: %w", err)
 	}
@@ -168,8 +177,10 @@ func (m *ChatModel) Invoke(ctx context.Context, messages []types.Message, opts .
 
 	// Build message
 	message := types.Message{
-		Role:    types.RoleAssistant,
-		Content: response.Message.Content,
+		Role:          types.RoleAssistant,
+		Content:       response.Message.Content,
+		FinishReason:  chat.NormalizeFinishReason(response.DoneReason),
+		UsageMetadata: response.usageMetadata(),
 	}
 
 	return message, nil
@@ -177,13 +188,20 @@ func (m *ChatModel) Invoke(ctx context.Context, messages []types.Message, opts .
 
 // Stream implements the Runnable interface, performs streaming invocation.
 func (m *ChatModel) Stream(ctx context.Context, messages []types.Message, opts ...runnable.Option) (<-chan runnable.StreamEvent[types.Message], error) {
+	return chat.TraceStream(ctx, m, messages, opts, func(ctx context.Context) (<-chan runnable.StreamEvent[types.Message], error) {
+		return m.stream(ctx, messages, opts...)
+	})
+}
+
+// stream performs a streaming invocation.
+func (m *ChatModel) stream(ctx context.Context, messages []types.Message, opts ...runnable.Option) (<-chan runnable.StreamEvent[types.Message], error) {
 	// Validate messages
 	if err := chat.ValidateMessages(messages); err != nil {
 		return nil, err
 	}
 
 	// Build request
-	reqBody, err := m.buildRequest(messages, true)
+	reqBody, err := m.buildRequest(messages, true, runnable.NewOptions(opts...))
 	if err != nil {
 		return nil, fmt.Errorf("failed to build request: %w", err)
 	}
@@ -213,7 +231,8 @@ func (m *ChatModel) Stream(ctx context.Context, messages []types.Message, opts .
 		defer resp.Body.Close()
 
 		// Read streaming response
-		if err := m.processStream(resp.Body, out); err != nil {
+		fullMessage, err := m.processStream(resp.Body, out)
+		if err != nil {
 			out <- runnable.StreamEvent[types.Message]{
 				Type:  runnable.EventError,
 				Error: err,
@@ -221,23 +240,54 @@ func (m *ChatModel) Stream(ctx context.Context, messages []types.Message, opts .
 			return
 		}
 
-		// Send end event
+		// Send end event with the aggregated message, usage and finish reason
 		out <- runnable.StreamEvent[types.Message]{
 			Type: runnable.EventEnd,
+			Data: fullMessage,
+			Name: m.GetName(),
 		}
 	}()
 
-	return out, nil
+	// 在图等外层执行器中运行时，把 token 事件转发给外层
+	return runnable.ForwardStream(ctx, out), nil
+}
+
+// convertImages extracts the images of a multimodal message as base64 strings.
+//
+// Ollama vision models (llava, llama3.2-vision, ...) accept raw image data only;
+// text parts are already joined into Message.Content.
+func convertImages(parts []*types.MultimodalContent) ([]string, error) {
+	var images []string
+	for _, part := range parts {
+		switch {
+		case part.IsText():
+			continue
+		case !part.IsImage():
+			return nil, fmt.Errorf("ollama does not support %s content", part.Type)
+		case len(part.ImageData) == 0:
+			return nil, fmt.Errorf("ollama requires image data, image URLs are not supported")
+		}
+		images = append(images, base64.StdEncoding.EncodeToString(part.ImageData))
+	}
+	return images, nil
 }
 
 // buildRequest builds the Ollama API request.
-func (m *ChatModel) buildRequest(messages []types.Message, stream bool) ([]byte, error) {
+//
+// Per-call options override the model config. Tool choice and parallel tool calls
+// are ignored since this client does not send tools.
+func (m *ChatModel) buildRequest(messages []types.Message, stream bool, options *runnable.Options) ([]byte, error) {
 	// Convert messages to Ollama format
 	ollamaMessages := make([]ollamaMessage, len(messages))
 	for i, msg := range messages {
+		images, err := convertImages(msg.Parts)
+		if err != nil {
+			return nil, fmt.Errorf("invalid message at index %d: %w", i, err)
+		}
 		ollamaMessages[i] = ollamaMessage{
 			Role:    string(msg.Role),
 			Content: msg.Content,
+			Images:  images,
 		}
 	}
 
@@ -260,6 +310,31 @@ func (m *ChatModel) buildRequest(messages []types.Message, stream bool) ([]byte,
 		req.Format = m.config.Format
 	}
 
+	// Apply per-call options
+	if options.Temperature != nil {
+		req.Options.Temperature = *options.Temperature
+	}
+	if options.MaxTokens > 0 {
+		req.Options.NumPredict = options.MaxTokens
+	}
+	if len(options.Stop) > 0 {
+		req.Options.Stop = options.Stop
+	}
+	if options.Seed != nil {
+		req.Options.Seed = options.Seed
+	}
+	if format := options.ResponseFormat; format != nil {
+		// "json" for JSON mode, the schema itself for structured outputs
+		switch {
+		case format.Type == types.ResponseFormatJSONSchema && format.Schema != nil:
+			req.Format = format.Schema.ToMap()
+		case format.IsJSON():
+			req.Format = "json"
+		default:
+			req.Format = nil
+		}
+	}
+
 	return json.Marshal(req)
 }
 
@@ -276,7 +351,7 @@ func (m *ChatModel) doRequest(ctx context.Context, reqBody []byte) ([]byte, erro
 	// Send request
 	resp, err := m.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return nil, chaterrors.FromTransport(m.GetProvider(), fmt.Errorf("failed to send request: %w", err))
 	}
 	defer resp.Body.Close()
 
@@ -288,7 +363,7 @@ func (m *ChatModel) doRequest(ctx context.Context, reqBody []byte) ([]byte, erro
 
 	// Check status
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("Ollama API error (status %d): %s", resp.StatusCode, string(body))
+		return nil, chaterrors.FromResponse(m.GetProvider(), resp, body)
 	}
 
 	return body, nil
@@ -307,24 +382,28 @@ func (m *ChatModel) doStreamRequest(ctx context.Context, reqBody []byte) (*http.
 	// Send request
 	resp, err := m.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return nil, chaterrors.FromTransport(m.GetProvider(), fmt.Errorf("failed to send request: %w", err))
 	}
 
 	// Check status
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		resp.Body.Close()
-		return nil, fmt.Errorf("Ollama API error (status %d): %s", resp.StatusCode, string(body))
+		return nil, chaterrors.FromResponse(m.GetProvider(), resp, body)
 	}
 
 	return resp, nil
 }
 
-// processStream processes the streaming response.
-func (m *ChatModel) processStream(body io.Reader, out chan<- runnable.StreamEvent[types.Message]) error {
+// processStream processes the streaming response and returns the aggregated message.
+//
+// The final chunk (done=true) carries the token counts and done_reason.
+func (m *ChatModel) processStream(body io.Reader, out chan<- runnable.StreamEvent[types.Message]) (types.Message, error) {
 	scanner := bufio.NewScanner(body)
 	scanner.Buffer(make([]byte, 64*1024), 1024*1024) // 1MB max line size
 
+	fullMessage := types.Message{Role: types.RoleAssistant}
+
 	for scanner.Scan() {
 		line := scanner.Text()
 		if line == "" {
@@ -334,11 +413,15 @@ func (m *ChatModel) processStream(body io.Reader, out chan<- runnable.StreamEven
 		// Parse chunk
 		var chunk ollamaStreamChunk
 		if err := json.Unmarshal([]byte(line), &chunk); err != nil {
-			return fmt.Errorf("failed to parse chunk: %w", err)
+			return types.Message{}, fmt.Errorf("failed to parse chunk: %w", err)
+		}
+		if chunk.Error != "" {
+			return types.Message{}, chaterrors.New(m.GetProvider(), 0, "", chunk.Error)
 		}
 
 		// Send stream event
 		if chunk.Message.Content != "" {
+			fullMessage.Content += chunk.Message.Content
 			out <- runnable.StreamEvent[types.Message]{
 				Type: runnable.EventStream,
 				Data: types.Message{
@@ -350,15 +433,17 @@ func (m *ChatModel) processStream(body io.Reader, out chan<- runnable.StreamEven
 
 		// Check if done
 		if chunk.Done {
+			fullMessage.FinishReason = chat.NormalizeFinishReason(chunk.DoneReason)
+			fullMessage.UsageMetadata = chunk.usageMetadata()
 			break
 		}
 	}
 
 	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("scanner error: %w", err)
+		return types.Message{}, fmt.Errorf("scanner error: %w", err)
 	}
 
-	return nil
+	return fullMessage, nil
 }
 
 // GetType returns the model type.
@@ -472,34 +557,48 @@ type ollamaRequest struct {
 	Model    string          `json:"model"`
 	Messages []ollamaMessage `json:"messages"`
 	Stream   bool            `json:"stream"`
-	Format   string          `json:"format,omitempty"`
+	Format   any             `json:"format,omitempty"`
 	Options  ollamaOptions   `json:"options,omitempty"`
 }
 
 type ollamaMessage struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role    string   `json:"role"`
+	Content string   `json:"content"`
+	Images  []string `json:"images,omitempty"`
 }
 
 type ollamaOptions struct {
-	Temperature   float64 `json:"temperature,omitempty"`
-	NumPredict    int     `json:"num_predict,omitempty"`
-	TopK          int     `json:"top_k,omitempty"`
-	TopP          float64 `json:"top_p,omitempty"`
-	RepeatPenalty float64 `json:"repeat_penalty,omitempty"`
-	Seed          *int    `json:"seed,omitempty"`
+	Temperature   float64  `json:"temperature,omitempty"`
+	NumPredict    int      `json:"num_predict,omitempty"`
+	TopK          int      `json:"top_k,omitempty"`
+	TopP          float64  `json:"top_p,omitempty"`
+	RepeatPenalty float64  `json:"repeat_penalty,omitempty"`
+	Seed          *int     `json:"seed,omitempty"`
+	Stop          []string `json:"stop,omitempty"`
 }
 
 type ollamaResponse struct {
-	Model     string        `json:"model"`
-	CreatedAt string        `json:"created_at"`
-	Message   ollamaMessage `json:"message"`
-	Done      bool          `json:"done"`
+	Model      string        `json:"model"`
+	CreatedAt  string        `json:"created_at"`
+	Message    ollamaMessage `json:"message"`
+	Done       bool          `json:"done"`
+	DoneReason string        `json:"done_reason,omitempty"`
+
+	// PromptEvalCount is the number of input tokens, EvalCount the number of output tokens.
+	PromptEvalCount int `json:"prompt_eval_count,omitempty"`
+	EvalCount       int `json:"eval_count,omitempty"`
+
+	// Error is set on a stream line reporting a failure after the response started.
+	Error string `json:"error,omitempty"`
 }
 
-type ollamaStreamChunk struct {
-	Model     string        `json:"model"`
-	CreatedAt string        `json:"created_at"`
-	Message   ollamaMessage `json:"message"`
-	Done      bool          `json:"done"`
+// usageMetadata returns the token usage, or nil when the response has no counts.
+func (r ollamaResponse) usageMetadata() *types.UsageMetadata {
+	if r.PromptEvalCount == 0 && r.EvalCount == 0 {
+		return nil
+	}
+	return types.NewUsageMetadata(r.PromptEvalCount, r.EvalCount)
 }
+
+// ollamaStreamChunk has the same shape as ollamaResponse; counts are only set on the final chunk.
+type ollamaStreamChunk = ollamaResponse
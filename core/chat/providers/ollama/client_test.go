@@ -176,7 +176,10 @@ func TestChatModel_Invoke(t *testing.T) {
 				Role:    "assistant",
 				Content: "Hello! How can I help you?",
 			},
-			Done: true,
+			Done:            true,
+			DoneReason:      "stop",
+			PromptEvalCount: 26,
+			EvalCount:       8,
 		}
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(resp)
@@ -209,6 +212,13 @@ func TestChatModel_Invoke(t *testing.T) {
 	if response.Content == "" {
 		t.Error("Expected non-empty content")
 	}
+	if response.FinishReason != types.FinishReasonStop {
+		t.Errorf("Expected finish reason stop, got %q", response.FinishReason)
+	}
+	if response.UsageMetadata == nil || response.UsageMetadata.InputTokens != 26 ||
+		response.UsageMetadata.OutputTokens != 8 || response.UsageMetadata.TotalTokens != 34 {
+		t.Errorf("Unexpected usage: %+v", response.UsageMetadata)
+	}
 }
 
 func TestChatModel_Stream(t *testing.T) {
@@ -250,6 +260,11 @@ func TestChatModel_Stream(t *testing.T) {
 				},
 				Done: i == len(chunks)-1,
 			}
+			if resp.Done {
+				resp.DoneReason = "length"
+				resp.PromptEvalCount = 10
+				resp.EvalCount = 4
+			}
 			json.NewEncoder(w).Encode(resp)
 			flusher.Flush()
 		}
@@ -278,6 +293,7 @@ func TestChatModel_Stream(t *testing.T) {
 
 	// Collect events
 	var content string
+	var final types.Message
 	eventCount := 0
 	hasStart := false
 	hasEnd := false
@@ -291,6 +307,7 @@ func TestChatModel_Stream(t *testing.T) {
 			content += event.Data.Content
 		case runnable.EventEnd:
 			hasEnd = true
+			final = event.Data
 		case runnable.EventError:
 			t.Fatalf("Stream error: %v", event.Error)
 		}
@@ -308,6 +325,12 @@ func TestChatModel_Stream(t *testing.T) {
 	if eventCount < 3 {
 		t.Errorf("Expected at least 3 events (start, stream, end), got %d", eventCount)
 	}
+	if final.Content != "Hello World!" || final.FinishReason != types.FinishReasonLength {
+		t.Errorf("Unexpected final message: %+v", final)
+	}
+	if final.UsageMetadata == nil || final.UsageMetadata.TotalTokens != 14 {
+		t.Errorf("Unexpected usage: %+v", final.UsageMetadata)
+	}
 }
 
 func TestChatModel_InvalidMessages(t *testing.T) {
@@ -347,6 +370,79 @@ func TestChatModel_InvalidMessages(t *testing.T) {
 	}
 }
 
+func TestChatModel_BuildRequest_Images(t *testing.T) {
+	model, err := New(Config{Model: "llava"})
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+
+	msg := types.NewMessageWithParts(types.RoleUser,
+		types.NewTextContent("What is in this image?"),
+		types.NewImageContentFromData([]byte("png"), types.ImageFormatPNG),
+	)
+
+	body, err := model.buildRequest([]types.Message{msg}, false, runnable.NewOptions())
+	if err != nil {
+		t.Fatalf("buildRequest failed: %v", err)
+	}
+
+	var req ollamaRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		t.Fatalf("Failed to decode request: %v", err)
+	}
+	if req.Messages[0].Content != "What is in this image?" {
+		t.Errorf("Expected text content, got %q", req.Messages[0].Content)
+	}
+	if len(req.Messages[0].Images) != 1 || req.Messages[0].Images[0] != "cG5n" {
+		t.Errorf("Expected base64 image, got %v", req.Messages[0].Images)
+	}
+
+	// Image URLs and audio are not supported
+	for _, part := range []*types.MultimodalContent{
+		types.NewImageContent("https://example.com/a.png", types.ImageFormatPNG),
+		types.NewAudioContentFromData([]byte("wav"), types.AudioFormatWAV),
+	} {
+		if _, err := model.buildRequest([]types.Message{types.NewMessageWithParts(types.RoleUser, part)}, false, runnable.NewOptions()); err == nil {
+			t.Errorf("Expected error for %s part", part.Type)
+		}
+	}
+}
+
+func TestChatModel_BuildRequest_CallOptions(t *testing.T) {
+	model, err := New(Config{Model: "llama2", Format: "json"})
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+
+	options := runnable.NewOptions(
+		runnable.WithTemperature(0.1),
+		runnable.WithMaxTokens(64),
+		runnable.WithStop("END"),
+		runnable.WithSeed(7),
+		runnable.WithResponseFormat(types.NewJSONSchemaFormat("result", types.Schema{Type: "object"})),
+	)
+	body, err := model.buildRequest([]types.Message{types.NewUserMessage("hi")}, false, options)
+	if err != nil {
+		t.Fatalf("buildRequest failed: %v", err)
+	}
+
+	var req map[string]any
+	if err := json.Unmarshal(body, &req); err != nil {
+		t.Fatalf("Failed to decode request: %v", err)
+	}
+	opts := req["options"].(map[string]any)
+	if opts["temperature"] != 0.1 || opts["num_predict"] != float64(64) || opts["seed"] != float64(7) {
+		t.Errorf("Expected per-call options, got %v", opts)
+	}
+	if stop := opts["stop"].([]any); len(stop) != 1 || stop[0] != "END" {
+		t.Errorf("Expected stop sequences, got %v", opts["stop"])
+	}
+	// The schema replaces the configured JSON mode
+	if format, ok := req["format"].(map[string]any); !ok || format["type"] != "object" {
+		t.Errorf("Expected schema format, got %v", req["format"])
+	}
+}
+
 func TestChatModel_GetType(t *testing.T) {
 	model, err := New(Config{
 		Model: "llama2",
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/zhucl121/langchain-go/core/chat"
+	chaterrors "github.com/zhucl121/langchain-go/core/chat/errors"
 	"github.com/zhucl121/langchain-go/core/runnable"
 	"github.com/zhucl121/langchain-go/pkg/types"
 )
@@ -28,6 +29,12 @@ const (
 
 	// DefaultMaxTokens 是默认的最大生成 token 数
 	DefaultMaxTokens = 4096
+
+	// DefaultProvider 是默认的提供商名称
+	DefaultProvider = "openai"
+
+	// chatCompletionsPath 是 Chat Completions API 的路径
+	chatCompletionsPath = "/chat/completions"
 )
 
 // Config 是 OpenAI ChatModel 的配置。
@@ -70,6 +77,29 @@ type Config struct {
 
 	// Seed 是随机种子（可选，用于可复现的输出）
 	Seed *int
+
+	// Thinking 是推理配置（可选，用于 o 系列等推理模型），可通过 runnable.WithThinking 按调用覆盖
+	//
+	// 启用后发送 reasoning_effort，不再发送 Temperature 和 TopP，MaxTokens 以
+	// max_completion_tokens 发送。设置 Summary 时改用 Responses API 以返回推理摘要。
+	Thinking *types.ThinkingConfig
+
+	// Provider 是提供商名称（可选，默认为 openai）
+	// 兼容 OpenAI API 的服务可设置为自己的名称，用于 GetProvider 和 GetName
+	Provider string
+
+	// Headers 是附加的请求头（可选，如网关鉴权或路由头）
+	Headers map[string]string
+
+	// RequestHook 在请求体序列化前调用（可选）
+	//
+	// 可以修改请求体以适配兼容服务的差异，返回错误时中止请求。
+	RequestHook func(request map[string]any) error
+
+	// ResponseHook 在返回最终消息前调用（可选）
+	//
+	// 对 Invoke 的结果和流式调用 EventEnd 中的完整消息生效，返回错误时调用失败。
+	ResponseHook func(message *types.Message) error
 }
 
 // Validate 验证配置的有效性。
@@ -123,6 +153,9 @@ func New(config Config) (*ChatModel, error) {
 	if config.Timeout == 0 {
 		config.Timeout = DefaultTimeout
 	}
+	if config.Provider == "" {
+		config.Provider = DefaultProvider
+	}
 
 	// 创建 HTTP 客户端
 	client := &http.Client{
@@ -130,7 +163,7 @@ func New(config Config) (*ChatModel, error) {
 	}
 
 	model := &ChatModel{
-		BaseChatModel: chat.NewBaseChatModel(config.Model, "openai"),
+		BaseChatModel: chat.NewBaseChatModel(config.Model, config.Provider),
 		config:        config,
 		client:        client,
 	}
@@ -140,19 +173,32 @@ func New(config Config) (*ChatModel, error) {
 
 // Invoke 实现 Runnable 接口，执行单次调用。
 func (m *ChatModel) Invoke(ctx context.Context, messages []types.Message, opts ...runnable.Option) (types.Message, error) {
+	return chat.TraceInvoke(ctx, m, messages, opts, func(ctx context.Context) (types.Message, error) {
+		return m.invoke(ctx, messages, opts...)
+	})
+}
+
+// invoke 执行单次调用。
+func (m *ChatModel) invoke(ctx context.Context, messages []types.Message, opts ...runnable.Option) (types.Message, error) {
 	// 验证消息
 	if err := chat.ValidateMessages(messages); err != nil {
 		return types.Message{}, err
 	}
 
+	// 需要推理摘要时使用 Responses API
+	options := chat.ResolveOptions(m.config.Thinking, opts...)
+	if useResponsesAPI(options.Thinking) {
+		return m.invokeResponses(ctx, messages, options)
+	}
+
 	// 构建请求
-	reqBody, err := m.buildRequest(messages, false)
+	reqBody, err := m.buildRequest(messages, false, options)
 	if err != nil {
 		return types.Message{}, fmt.Errorf("failed to build request: %w", err)
 	}
 
 	// 发送请求
-	respBody, err := m.doRequest(ctx, reqBody)
+	respBody, err := m.doRequest(ctx, chatCompletionsPath, reqBody)
 	if err != nil {
 		return types.Message{}, err
 	}
@@ -173,18 +219,37 @@ func (m *ChatModel) Invoke(ctx context.Context, messages []types.Message, opts .
 		return types.Message{}, fmt.Errorf("failed to convert response: %w", err)
 	}
 
-	return message, nil
+	message.FinishReason = chat.NormalizeFinishReason(response.Choices[0].FinishReason)
+	message.UsageMetadata = response.Usage.toUsageMetadata()
+
+	return m.applyResponseHook(message)
 }
 
 // Stream 实现 Runnable 接口，执行流式调用。
 func (m *ChatModel) Stream(ctx context.Context, messages []types.Message, opts ...runnable.Option) (<-chan runnable.StreamEvent[types.Message], error) {
+	return chat.TraceStream(ctx, m, messages, opts, func(ctx context.Context) (<-chan runnable.StreamEvent[types.Message], error) {
+		return m.stream(ctx, messages, opts...)
+	})
+}
+
+// stream 执行流式调用。
+func (m *ChatModel) stream(ctx context.Context, messages []types.Message, opts ...runnable.Option) (<-chan runnable.StreamEvent[types.Message], error) {
 	// 验证消息
 	if err := chat.ValidateMessages(messages); err != nil {
 		return nil, err
 	}
 
-	// 构建请求
-	reqBody, err := m.buildRequest(messages, true)
+	// 构建请求，需要推理摘要时使用 Responses API
+	options := chat.ResolveOptions(m.config.Thinking, opts...)
+	path, process := chatCompletionsPath, m.processStream
+	var reqBody []byte
+	var err error
+	if useResponsesAPI(options.Thinking) {
+		path, process = responsesPath, m.processResponsesStream
+		reqBody, err = m.buildResponsesRequest(messages, true, options)
+	} else {
+		reqBody, err = m.buildRequest(messages, true, options)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to build request: %w", err)
 	}
@@ -203,7 +268,7 @@ func (m *ChatModel) Stream(ctx context.Context, messages []types.Message, opts .
 		}
 
 		// 发送请求
-		resp, err := m.doStreamRequest(ctx, reqBody)
+		resp, err := m.doStreamRequest(ctx, path, reqBody)
 		if err != nil {
 			out <- runnable.StreamEvent[types.Message]{
 				Type:  runnable.EventError,
@@ -214,7 +279,7 @@ func (m *ChatModel) Stream(ctx context.Context, messages []types.Message, opts .
 		defer resp.Body.Close()
 
 		// 读取流式响应
-		if err := m.processStream(resp.Body, out); err != nil {
+		if err := process(resp.Body, out); err != nil {
 			out <- runnable.StreamEvent[types.Message]{
 				Type:  runnable.EventError,
 				Error: err,
@@ -223,14 +288,15 @@ func (m *ChatModel) Stream(ctx context.Context, messages []types.Message, opts .
 		}
 	}()
 
-	return out, nil
+	// 在图等外层执行器中运行时，把 token 事件转发给外层
+	return runnable.ForwardStream(ctx, out), nil
 }
 
 // BindTools 实现 ChatModel 接口，绑定工具。
 func (m *ChatModel) BindTools(tools []types.Tool) chat.ChatModel {
 	// 创建新实例
 	newModel := &ChatModel{
-		BaseChatModel: chat.NewBaseChatModel(m.config.Model, "openai"),
+		BaseChatModel: chat.NewBaseChatModel(m.config.Model, m.config.Provider),
 		config:        m.config,
 		client:        m.client,
 	}
@@ -245,7 +311,7 @@ func (m *ChatModel) BindTools(tools []types.Tool) chat.ChatModel {
 func (m *ChatModel) WithStructuredOutput(schema types.Schema) chat.ChatModel {
 	// 创建新实例
 	newModel := &ChatModel{
-		BaseChatModel: chat.NewBaseChatModel(m.config.Model, "openai"),
+		BaseChatModel: chat.NewBaseChatModel(m.config.Model, m.config.Provider),
 		config:        m.config,
 		client:        m.client,
 	}
@@ -309,7 +375,7 @@ func (m *ChatModel) Batch(ctx context.Context, inputs [][]types.Message, opts ..
 // WithConfig 实现 Runnable 接口。
 func (m *ChatModel) WithConfig(config *types.Config) runnable.Runnable[[]types.Message, types.Message] {
 	newModel := &ChatModel{
-		BaseChatModel: chat.NewBaseChatModel(m.config.Model, "openai"),
+		BaseChatModel: chat.NewBaseChatModel(m.config.Model, m.config.Provider),
 		config:        m.config,
 		client:        m.client,
 	}
@@ -332,7 +398,9 @@ func (m *ChatModel) WithFallbacks(fallbacks ...runnable.Runnable[[]types.Message
 }
 
 // buildRequest 构建 OpenAI API 请求体。
-func (m *ChatModel) buildRequest(messages []types.Message, stream bool) ([]byte, error) {
+//
+// options 中设置的单次调用参数优先于模型配置。
+func (m *ChatModel) buildRequest(messages []types.Message, stream bool, options *runnable.Options) ([]byte, error) {
 	// 转换消息格式
 	openaiMessages, err := chat.MessagesToOpenAI(messages)
 	if err != nil {
@@ -346,16 +414,39 @@ func (m *ChatModel) buildRequest(messages []types.Message, stream bool) ([]byte,
 		"stream":   stream,
 	}
 
-	// 添加可选参数
-	if m.config.Temperature > 0 {
-		request["temperature"] = m.config.Temperature
+	// 流式响应在最后一个数据块中返回 token 用量
+	if stream {
+		request["stream_options"] = map[string]any{"include_usage": true}
 	}
-	if m.config.MaxTokens > 0 {
-		request["max_tokens"] = m.config.MaxTokens
+
+	maxTokens := m.config.MaxTokens
+	if options.MaxTokens > 0 {
+		maxTokens = options.MaxTokens
 	}
-	if m.config.TopP > 0 {
-		request["top_p"] = m.config.TopP
+
+	// 推理模型不支持采样参数，最大 token 数使用 max_completion_tokens
+	if thinking := options.Thinking; thinking != nil && thinking.Enabled {
+		if effort := thinking.EffortLevel(); effort != "" {
+			request["reasoning_effort"] = effort
+		}
+		if maxTokens > 0 {
+			request["max_completion_tokens"] = maxTokens
+		}
+	} else {
+		if options.Temperature != nil {
+			request["temperature"] = *options.Temperature
+		} else if m.config.Temperature > 0 {
+			request["temperature"] = m.config.Temperature
+		}
+		if maxTokens > 0 {
+			request["max_tokens"] = maxTokens
+		}
+		if m.config.TopP > 0 {
+			request["top_p"] = m.config.TopP
+		}
 	}
+
+	// 添加可选参数
 	if m.config.FrequencyPenalty != 0 {
 		request["frequency_penalty"] = m.config.FrequencyPenalty
 	}
@@ -365,19 +456,32 @@ func (m *ChatModel) buildRequest(messages []types.Message, stream bool) ([]byte,
 	if m.config.User != "" {
 		request["user"] = m.config.User
 	}
-	if m.config.Seed != nil {
+	if options.Seed != nil {
+		request["seed"] = *options.Seed
+	} else if m.config.Seed != nil {
 		request["seed"] = *m.config.Seed
 	}
+	if len(options.Stop) > 0 {
+		request["stop"] = options.Stop
+	}
 
 	// 添加工具
 	tools := m.GetBoundTools()
 	if len(tools) > 0 {
 		request["tools"] = chat.ConvertToolsToOpenAI(tools)
 		request["tool_choice"] = "auto"
+		if options.ToolChoice != nil {
+			request["tool_choice"] = options.ToolChoice.ToOpenAI()
+		}
+		if options.ParallelToolCalls != nil {
+			request["parallel_tool_calls"] = *options.ParallelToolCalls
+		}
 	}
 
-	// 添加结构化输出
-	if schema := m.GetOutputSchema(); schema != nil {
+	// 添加结构化输出（单次调用的响应格式优先）
+	if format := options.ResponseFormat; format != nil {
+		request["response_format"] = format.ToOpenAI()
+	} else if schema := m.GetOutputSchema(); schema != nil {
 		request["response_format"] = map[string]any{
 			"type": "json_schema",
 			"json_schema": map[string]any{
@@ -388,28 +492,57 @@ func (m *ChatModel) buildRequest(messages []types.Message, stream bool) ([]byte,
 		}
 	}
 
+	return m.marshalRequest(request)
+}
+
+// marshalRequest 调用 RequestHook 后序列化请求体。
+func (m *ChatModel) marshalRequest(request map[string]any) ([]byte, error) {
+	if m.config.RequestHook != nil {
+		if err := m.config.RequestHook(request); err != nil {
+			return nil, err
+		}
+	}
 	return json.Marshal(request)
 }
 
-// doRequest 发送 HTTP 请求（非流式）。
-func (m *ChatModel) doRequest(ctx context.Context, body []byte) ([]byte, error) {
-	req, err := http.NewRequestWithContext(ctx, "POST",
-		m.config.BaseURL+"/chat/completions", bytes.NewReader(body))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+// applyResponseHook 对最终消息调用 ResponseHook。
+func (m *ChatModel) applyResponseHook(message types.Message) (types.Message, error) {
+	if m.config.ResponseHook == nil {
+		return message, nil
 	}
+	if err := m.config.ResponseHook(&message); err != nil {
+		return types.Message{}, fmt.Errorf("response hook: %w", err)
+	}
+	return message, nil
+}
 
-	// 设置请求头
+// setHeaders 设置通用请求头。
+func (m *ChatModel) setHeaders(req *http.Request) {
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+m.config.APIKey)
 	if m.config.Organization != "" {
 		req.Header.Set("OpenAI-Organization", m.config.Organization)
 	}
+	for key, value := range m.config.Headers {
+		req.Header.Set(key, value)
+	}
+}
+
+// doRequest 发送 HTTP 请求（非流式）。
+func (m *ChatModel) doRequest(ctx context.Context, path string, body []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST",
+		m.config.BaseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// 设置请求头
+	m.setHeaders(req)
 
 	// 发送请求
 	resp, err := m.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return nil, chaterrors.FromTransport(m.config.Provider, fmt.Errorf("failed to send request: %w", err))
 	}
 	defer resp.Body.Close()
 
@@ -421,50 +554,57 @@ func (m *ChatModel) doRequest(ctx context.Context, body []byte) ([]byte, error)
 
 	// 检查状态码
 	if resp.StatusCode != http.StatusOK {
-		return nil, m.parseError(resp.StatusCode, respBody)
+		return nil, m.parseError(resp, respBody)
 	}
 
 	return respBody, nil
 }
 
 // doStreamRequest 发送流式 HTTP 请求。
-func (m *ChatModel) doStreamRequest(ctx context.Context, body []byte) (*http.Response, error) {
+func (m *ChatModel) doStreamRequest(ctx context.Context, path string, body []byte) (*http.Response, error) {
 	req, err := http.NewRequestWithContext(ctx, "POST",
-		m.config.BaseURL+"/chat/completions", bytes.NewReader(body))
+		m.config.BaseURL+path, bytes.NewReader(body))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// 设置请求头
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+m.config.APIKey)
+	m.setHeaders(req)
 	req.Header.Set("Accept", "text/event-stream")
-	if m.config.Organization != "" {
-		req.Header.Set("OpenAI-Organization", m.config.Organization)
-	}
 
 	// 发送请求
 	resp, err := m.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return nil, chaterrors.FromTransport(m.config.Provider, fmt.Errorf("failed to send request: %w", err))
 	}
 
 	// 检查状态码
 	if resp.StatusCode != http.StatusOK {
 		defer resp.Body.Close()
 		respBody, _ := io.ReadAll(resp.Body)
-		return nil, m.parseError(resp.StatusCode, respBody)
+		return nil, m.parseError(resp, respBody)
 	}
 
 	return resp, nil
 }
 
 // processStream 处理流式响应。
+//
+// 为兼容 OpenAI 兼容服务的差异：
+//   - 接受 "data:" 后不带空格的数据行
+//   - 思考过程可以在 reasoning_content 或 reasoning 字段中
+//   - 同一 index 上出现新的工具调用 ID 时视为新的工具调用
+//   - 流结束但没有 [DONE] 标记时同样发送结束事件
+//
 func (m *ChatModel) processStream(reader io.Reader, out chan<- runnable.StreamEvent[types.Message]) error {
 	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
 	var fullMessage types.Message
 	fullMessage.Role = types.RoleAssistant
 
+	// 流中的工具调用 index 到 fullMessage.ToolCalls 下标的映射
+	toolCallSlots := make(map[int]int)
+
 	for scanner.Scan() {
 		line := scanner.Text()
 
@@ -474,21 +614,15 @@ func (m *ChatModel) processStream(reader io.Reader, out chan<- runnable.StreamEv
 		}
 
 		// 解析 SSE 数据
-		if !strings.HasPrefix(line, "data: ") {
+		if !strings.HasPrefix(line, "data:") {
 			continue
 		}
 
-		data := strings.TrimPrefix(line, "data: ")
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
 
 		// 检查结束标记
 		if data == "[DONE]" {
-			// 发送结束事件
-			out <- runnable.StreamEvent[types.Message]{
-				Type: runnable.EventEnd,
-				Data: fullMessage,
-				Name: m.GetName(),
-			}
-			return nil
+			break
 		}
 
 		// 解析 JSON
@@ -497,12 +631,47 @@ func (m *ChatModel) processStream(reader io.Reader, out chan<- runnable.StreamEv
 			continue
 		}
 
+		// 兼容服务（如 vLLM）在流中以数据块返回错误
+		if len(chunk.Error) > 0 && string(chunk.Error) != "null" {
+			return chaterrors.FromBody(m.config.Provider, 0, []byte(data))
+		}
+
+		// 用量在 choices 为空的最后一个数据块中
+		if chunk.Usage != nil {
+			fullMessage.UsageMetadata = chunk.Usage.toUsageMetadata()
+		}
+
 		if len(chunk.Choices) == 0 {
 			continue
 		}
 
+		if reason := chunk.Choices[0].FinishReason; reason != nil && *reason != "" {
+			fullMessage.FinishReason = chat.NormalizeFinishReason(*reason)
+		}
+
 		delta := chunk.Choices[0].Delta
 
+		// 兼容服务在 reasoning_content 中返回思考过程，以思考块发送，不计入 Content
+		reasoning := delta.ReasoningContent
+		if reasoning == "" {
+			reasoning = delta.Reasoning
+		}
+		if reasoning != "" {
+			if len(fullMessage.ContentBlocks) == 0 {
+				fullMessage.ContentBlocks = []*types.ContentBlock{types.NewThinkingContentBlock("")}
+			}
+			fullMessage.ContentBlocks[0].Content += reasoning
+
+			out <- runnable.StreamEvent[types.Message]{
+				Type: runnable.EventStream,
+				Data: types.Message{
+					Role:          types.RoleAssistant,
+					ContentBlocks: []*types.ContentBlock{types.NewThinkingContentBlock(reasoning)},
+				},
+				Name: m.GetName(),
+			}
+		}
+
 		// 累积内容
 		if delta.Content != "" {
 			fullMessage.Content += delta.Content
@@ -519,29 +688,28 @@ func (m *ChatModel) processStream(reader io.Reader, out chan<- runnable.StreamEv
 		}
 
 		// 处理工具调用
-		if len(delta.ToolCalls) > 0 {
-			for _, tc := range delta.ToolCalls {
-				// 查找或创建对应的 ToolCall
-				if tc.Index >= len(fullMessage.ToolCalls) {
-					// 扩展数组
-					for i := len(fullMessage.ToolCalls); i <= tc.Index; i++ {
-						fullMessage.ToolCalls = append(fullMessage.ToolCalls, types.ToolCall{})
-					}
-				}
-
-				// 累积工具调用信息
-				if tc.ID != "" {
-					fullMessage.ToolCalls[tc.Index].ID = tc.ID
-				}
-				if tc.Type != "" {
-					fullMessage.ToolCalls[tc.Index].Type = tc.Type
-				}
-				if tc.Function.Name != "" {
-					fullMessage.ToolCalls[tc.Index].Function.Name = tc.Function.Name
-				}
-				if tc.Function.Arguments != "" {
-					fullMessage.ToolCalls[tc.Index].Function.Arguments += tc.Function.Arguments
-				}
+		for _, tc := range delta.ToolCalls {
+			// 查找或创建对应的 ToolCall，部分服务对多个工具调用复用同一 index
+			slot, ok := toolCallSlots[tc.Index]
+			if !ok || (tc.ID != "" && fullMessage.ToolCalls[slot].ID != "" && fullMessage.ToolCalls[slot].ID != tc.ID) {
+				fullMessage.ToolCalls = append(fullMessage.ToolCalls, types.ToolCall{})
+				slot = len(fullMessage.ToolCalls) - 1
+				toolCallSlots[tc.Index] = slot
+			}
+
+			// 累积工具调用信息
+			toolCall := &fullMessage.ToolCalls[slot]
+			if tc.ID != "" {
+				toolCall.ID = tc.ID
+			}
+			if tc.Type != "" {
+				toolCall.Type = tc.Type
+			}
+			if tc.Function.Name != "" {
+				toolCall.Function.Name = tc.Function.Name
+			}
+			if tc.Function.Arguments != "" {
+				toolCall.Function.Arguments += tc.Function.Arguments
 			}
 		}
 	}
@@ -550,17 +718,30 @@ func (m *ChatModel) processStream(reader io.Reader, out chan<- runnable.StreamEv
 		return fmt.Errorf("error reading stream: %w", err)
 	}
 
-	return nil
-}
+	// 部分服务只在第一个增量中给出类型，或者完全省略
+	for i := range fullMessage.ToolCalls {
+		if fullMessage.ToolCalls[i].Type == "" {
+			fullMessage.ToolCalls[i].Type = "function"
+		}
+	}
+
+	message, err := m.applyResponseHook(fullMessage)
+	if err != nil {
+		return err
+	}
 
-// parseError 解析错误响应。
-func (m *ChatModel) parseError(statusCode int, body []byte) error {
-	var errResp errorResponse
-	if err := json.Unmarshal(body, &errResp); err != nil {
-		return fmt.Errorf("HTTP %d: %s", statusCode, string(body))
+	// 发送结束事件
+	out <- runnable.StreamEvent[types.Message]{
+		Type: runnable.EventEnd,
+		Data: message,
+		Name: m.GetName(),
 	}
+	return nil
+}
 
-	return fmt.Errorf("OpenAI API error (HTTP %d): %s", statusCode, errResp.Error.Message)
+// parseError 把错误响应转换为类型化错误（见 chat/errors）。
+func (m *ChatModel) parseError(resp *http.Response, body []byte) error {
+	return chaterrors.FromResponse(m.config.Provider, resp, body)
 }
 
 // openAIResponse 是 OpenAI API 的响应结构。
@@ -570,7 +751,7 @@ type openAIResponse struct {
 	Created int64    `json:"created"`
 	Model   string   `json:"model"`
 	Choices []choice `json:"choices"`
-	Usage   usage    `json:"usage"`
+	Usage   *usage   `json:"usage"`
 }
 
 type choice struct {
@@ -580,18 +761,40 @@ type choice struct {
 }
 
 type usage struct {
-	PromptTokens     int `json:"prompt_tokens"`
-	CompletionTokens int `json:"completion_tokens"`
-	TotalTokens      int `json:"total_tokens"`
+	PromptTokens        int `json:"prompt_tokens"`
+	CompletionTokens    int `json:"completion_tokens"`
+	TotalTokens         int `json:"total_tokens"`
+	PromptTokensDetails struct {
+		CachedTokens int `json:"cached_tokens"`
+	} `json:"prompt_tokens_details"`
+	CompletionTokensDetails struct {
+		ReasoningTokens int `json:"reasoning_tokens"`
+	} `json:"completion_tokens_details"`
+}
+
+// toUsageMetadata 转换为标准用量，u 为 nil 时返回 nil。
+func (u *usage) toUsageMetadata() *types.UsageMetadata {
+	if u == nil {
+		return nil
+	}
+	return &types.UsageMetadata{
+		InputTokens:     u.PromptTokens,
+		OutputTokens:    u.CompletionTokens,
+		TotalTokens:     u.TotalTokens,
+		CachedTokens:    u.PromptTokensDetails.CachedTokens,
+		ReasoningTokens: u.CompletionTokensDetails.ReasoningTokens,
+	}
 }
 
 // streamChunk 是流式响应的数据块。
 type streamChunk struct {
-	ID      string        `json:"id"`
-	Object  string        `json:"object"`
-	Created int64         `json:"created"`
-	Model   string        `json:"model"`
-	Choices []streamChoice `json:"choices"`
+	ID      string          `json:"id"`
+	Object  string          `json:"object"`
+	Created int64           `json:"created"`
+	Model   string          `json:"model"`
+	Choices []streamChoice  `json:"choices"`
+	Usage   *usage          `json:"usage"`
+	Error   json.RawMessage `json:"error"`
 }
 
 type streamChoice struct {
@@ -601,9 +804,11 @@ type streamChoice struct {
 }
 
 type streamDelta struct {
-	Role      string            `json:"role,omitempty"`
-	Content   string            `json:"content,omitempty"`
-	ToolCalls []streamToolCall  `json:"tool_calls,omitempty"`
+	Role             string           `json:"role,omitempty"`
+	Content          string           `json:"content,omitempty"`
+	ReasoningContent string           `json:"reasoning_content,omitempty"`
+	Reasoning        string           `json:"reasoning,omitempty"`
+	ToolCalls        []streamToolCall `json:"tool_calls,omitempty"`
 }
 
 type streamToolCall struct {
@@ -615,12 +820,3 @@ type streamToolCall struct {
 		Arguments string `json:"arguments,omitempty"`
 	} `json:"function,omitempty"`
 }
-
-// errorResponse 是 OpenAI API 的错误响应。
-type errorResponse struct {
-	Error struct {
-		Message string `json:"message"`
-		Type    string `json:"type"`
-		Code    string `json:"code"`
-	} `json:"error"`
-}
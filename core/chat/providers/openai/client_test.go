@@ -1,12 +1,23 @@
 package openai
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/zhucl121/langchain-go/core/callbacks"
+	chaterrors "github.com/zhucl121/langchain-go/core/chat/errors"
+	"github.com/zhucl121/langchain-go/core/runnable"
 	"github.com/zhucl121/langchain-go/pkg/types"
 )
 
@@ -245,3 +256,594 @@ func TestChatModel_GetModelName(t *testing.T) {
 //     assert.NotEmpty(t, response.Content)
 //     assert.Contains(t, strings.ToLower(response.Content), "hello")
 // }
+
+func TestChatModel_Invoke_Usage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{
+			"id": "chatcmpl-1",
+			"choices": [{"index": 0, "message": {"role": "assistant", "content": "Hi"}, "finish_reason": "length"}],
+			"usage": {
+				"prompt_tokens": 120, "completion_tokens": 40, "total_tokens": 160,
+				"prompt_tokens_details": {"cached_tokens": 100},
+				"completion_tokens_details": {"reasoning_tokens": 32}
+			}
+		}`)
+	}))
+	defer server.Close()
+
+	model, err := New(Config{APIKey: "test-key", BaseURL: server.URL})
+	require.NoError(t, err)
+
+	response, err := model.Invoke(context.Background(), []types.Message{types.NewUserMessage("Hello")})
+	require.NoError(t, err)
+
+	assert.Equal(t, types.FinishReasonLength, response.FinishReason)
+	require.NotNil(t, response.UsageMetadata)
+	assert.Equal(t, types.UsageMetadata{
+		InputTokens:     120,
+		OutputTokens:    40,
+		TotalTokens:     160,
+		CachedTokens:    100,
+		ReasoningTokens: 32,
+	}, *response.UsageMetadata)
+}
+
+func TestChatModel_Invoke_StreamWriter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"id": "chatcmpl-1", "choices": [{"index": 0, "message": {"role": "assistant", "content": "Hi"}, "finish_reason": "stop"}]}`)
+	}))
+	defer server.Close()
+
+	model, err := New(Config{APIKey: "test-key", BaseURL: server.URL})
+	require.NoError(t, err)
+
+	var forwarded []runnable.StreamEvent[any]
+	ctx := runnable.ContextWithStreamWriter(context.Background(), func(event runnable.StreamEvent[any]) {
+		forwarded = append(forwarded, event)
+	})
+
+	_, err = model.Invoke(ctx, []types.Message{types.NewUserMessage("Hello")})
+	require.NoError(t, err)
+
+	require.Len(t, forwarded, 1)
+	assert.Equal(t, runnable.EventStream, forwarded[0].Type)
+	assert.Equal(t, "Hi", forwarded[0].Data.(types.Message).Content)
+}
+
+func TestChatModel_Invoke_Multimodal(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Messages []struct {
+				Content []map[string]any `json:"content"`
+			} `json:"messages"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Len(t, req.Messages, 1)
+		require.Len(t, req.Messages[0].Content, 2)
+		assert.Equal(t, "text", req.Messages[0].Content[0]["type"])
+		assert.Equal(t, "image_url", req.Messages[0].Content[1]["type"])
+		assert.Equal(t, map[string]any{"url": "https://example.com/screenshot.png"}, req.Messages[0].Content[1]["image_url"])
+
+		io.WriteString(w, `{"id": "chatcmpl-1", "choices": [{"index": 0, "message": {"role": "assistant", "content": "A login form"}, "finish_reason": "stop"}]}`)
+	}))
+	defer server.Close()
+
+	model, err := New(Config{APIKey: "test-key", BaseURL: server.URL, Model: "gpt-4o"})
+	require.NoError(t, err)
+
+	msg := types.NewMessageWithParts(types.RoleUser,
+		types.NewTextContent("What is on this screen?"),
+		types.NewImageContent("https://example.com/screenshot.png", types.ImageFormatPNG),
+	)
+	response, err := model.Invoke(context.Background(), []types.Message{msg})
+	require.NoError(t, err)
+	assert.Equal(t, "A login form", response.Content)
+}
+
+func TestChatModel_Stream_Usage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+		json.NewDecoder(r.Body).Decode(&req)
+		assert.Equal(t, map[string]any{"include_usage": true}, req["stream_options"])
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, data := range []string{
+			`{"choices":[{"index":0,"delta":{"content":"Hi"},"finish_reason":null}]}`,
+			`{"choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`,
+			`{"choices":[],"usage":{"prompt_tokens":8,"completion_tokens":2,"total_tokens":10}}`,
+			`[DONE]`,
+		} {
+			io.WriteString(w, "data: "+data+"\n\n")
+		}
+	}))
+	defer server.Close()
+
+	model, err := New(Config{APIKey: "test-key", BaseURL: server.URL})
+	require.NoError(t, err)
+
+	stream, err := model.Stream(context.Background(), []types.Message{types.NewUserMessage("Hello")})
+	require.NoError(t, err)
+
+	var final types.Message
+	for event := range stream {
+		require.NoError(t, event.Error)
+		if event.Type == runnable.EventEnd {
+			final = event.Data
+		}
+	}
+
+	assert.Equal(t, "Hi", final.Content)
+	assert.Equal(t, types.FinishReasonStop, final.FinishReason)
+	require.NotNil(t, final.UsageMetadata)
+	assert.Equal(t, 10, final.UsageMetadata.TotalTokens)
+}
+
+func TestChatModel_Invoke_ReasoningEffort(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/chat/completions", r.URL.Path)
+
+		var req map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "high", req["reasoning_effort"])
+		assert.Equal(t, float64(500), req["max_completion_tokens"])
+		assert.NotContains(t, req, "temperature")
+		assert.NotContains(t, req, "max_tokens")
+
+		io.WriteString(w, `{"id": "chatcmpl-1", "choices": [{"index": 0, "message": {"role": "assistant", "content": "42"}, "finish_reason": "stop"}]}`)
+	}))
+	defer server.Close()
+
+	model, err := New(Config{APIKey: "test-key", BaseURL: server.URL, Model: "o3-mini", MaxTokens: 500})
+	require.NoError(t, err)
+
+	response, err := model.Invoke(context.Background(), []types.Message{types.NewUserMessage("6*7?")},
+		runnable.WithThinking(types.NewReasoningConfig(types.ReasoningEffortHigh)))
+	require.NoError(t, err)
+	assert.Equal(t, "42", response.Content)
+}
+
+func TestChatModel_Invoke_CallOptions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		// 单次调用参数覆盖模型配置，温度为 0 也要发送
+		assert.Equal(t, float64(0), req["temperature"])
+		assert.Equal(t, float64(64), req["max_tokens"])
+		assert.Equal(t, []any{"END"}, req["stop"])
+		assert.Equal(t, float64(7), req["seed"])
+		assert.Equal(t, map[string]any{"type": "function", "function": map[string]any{"name": "search"}}, req["tool_choice"])
+		assert.Equal(t, false, req["parallel_tool_calls"])
+		assert.Equal(t, map[string]any{"type": "json_object"}, req["response_format"])
+
+		io.WriteString(w, `{"id": "chatcmpl-1", "choices": [{"index": 0, "message": {"role": "assistant", "content": "{}"}, "finish_reason": "stop"}]}`)
+	}))
+	defer server.Close()
+
+	model, err := New(Config{APIKey: "test-key", BaseURL: server.URL, Temperature: 0.7, MaxTokens: 500})
+	require.NoError(t, err)
+	withTools := model.BindTools([]types.Tool{{Name: "search", Parameters: types.Schema{Type: "object"}}})
+
+	_, err = withTools.Invoke(context.Background(), []types.Message{types.NewUserMessage("Hello")},
+		runnable.WithTemperature(0),
+		runnable.WithMaxTokens(64),
+		runnable.WithStop("END"),
+		runnable.WithSeed(7),
+		runnable.WithToolChoice(types.NewSpecificToolChoice("search")),
+		runnable.WithParallelToolCalls(false),
+		runnable.WithResponseFormat(types.NewJSONObjectFormat()))
+	require.NoError(t, err)
+}
+
+func TestChatModel_Invoke_ReasoningSummary(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/responses", r.URL.Path)
+
+		var req map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, map[string]any{"effort": "low", "summary": "auto"}, req["reasoning"])
+		assert.Equal(t, []any{
+			map[string]any{"role": "user", "content": "Weather in Oslo?"},
+			map[string]any{"type": "function_call", "call_id": "call_1", "name": "get_weather", "arguments": `{"city":"Oslo"}`},
+			map[string]any{"type": "function_call_output", "call_id": "call_1", "output": "sunny"},
+		}, req["input"])
+		assert.Equal(t, []any{map[string]any{
+			"type": "function", "name": "get_weather", "description": "Get weather",
+			"parameters": map[string]any{"type": "object"},
+		}}, req["tools"])
+
+		io.WriteString(w, `{
+			"id": "resp_1", "status": "completed",
+			"output": [
+				{"type": "reasoning", "id": "rs_1", "summary": [{"type": "summary_text", "text": "The tool said sunny."}]},
+				{"type": "message", "role": "assistant", "content": [{"type": "output_text", "text": "It is sunny."}]}
+			],
+			"usage": {
+				"input_tokens": 50, "output_tokens": 30, "total_tokens": 80,
+				"input_tokens_details": {"cached_tokens": 10},
+				"output_tokens_details": {"reasoning_tokens": 20}
+			}
+		}`)
+	}))
+	defer server.Close()
+
+	model, err := New(Config{APIKey: "test-key", BaseURL: server.URL, Model: "o4-mini"})
+	require.NoError(t, err)
+
+	bound := model.BindTools([]types.Tool{{Name: "get_weather", Description: "Get weather", Parameters: types.Schema{Type: "object"}}})
+	messages := []types.Message{
+		types.NewUserMessage("Weather in Oslo?"),
+		{Role: types.RoleAssistant, ToolCalls: []types.ToolCall{
+			{ID: "call_1", Type: "function", Function: types.FunctionCall{Name: "get_weather", Arguments: `{"city":"Oslo"}`}},
+		}},
+		types.NewToolMessage("call_1", "sunny"),
+	}
+
+	response, err := bound.Invoke(context.Background(), messages,
+		runnable.WithThinking(types.NewReasoningConfig(types.ReasoningEffortLow).WithSummary(types.ReasoningSummaryAuto)))
+	require.NoError(t, err)
+
+	assert.Equal(t, "It is sunny.", response.Content)
+	assert.Equal(t, "The tool said sunny.", response.ThinkingText())
+	assert.Equal(t, "rs_1", response.ContentBlocks[0].ID)
+	assert.Equal(t, types.FinishReasonStop, response.FinishReason)
+	assert.Equal(t, types.UsageMetadata{
+		InputTokens: 50, OutputTokens: 30, TotalTokens: 80, CachedTokens: 10, ReasoningTokens: 20,
+	}, *response.UsageMetadata)
+}
+
+func TestChatModel_Stream_ReasoningSummary(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/responses", r.URL.Path)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, data := range []string{
+			`{"type":"response.created","response":{"id":"resp_1","status":"in_progress"}}`,
+			`{"type":"response.reasoning_summary_text.delta","delta":"Simple "}`,
+			`{"type":"response.reasoning_summary_text.delta","delta":"math."}`,
+			`{"type":"response.output_text.delta","delta":"42"}`,
+			`{"type":"response.completed","response":{"id":"resp_1","status":"completed","output":[` +
+				`{"type":"reasoning","id":"rs_1","summary":[{"type":"summary_text","text":"Simple math."}]},` +
+				`{"type":"message","content":[{"type":"output_text","text":"42"}]}],` +
+				`"usage":{"input_tokens":5,"output_tokens":10,"total_tokens":15}}}`,
+		} {
+			var event struct{ Type string }
+			json.Unmarshal([]byte(data), &event)
+			io.WriteString(w, "event: "+event.Type+"\ndata: "+data+"\n\n")
+		}
+	}))
+	defer server.Close()
+
+	model, err := New(Config{
+		APIKey:   "test-key",
+		BaseURL:  server.URL,
+		Thinking: types.NewReasoningConfig(types.ReasoningEffortMedium).WithSummary(types.ReasoningSummaryConcise),
+	})
+	require.NoError(t, err)
+
+	stream, err := model.Stream(context.Background(), []types.Message{types.NewUserMessage("6*7?")})
+	require.NoError(t, err)
+
+	var thinking, content string
+	var final types.Message
+	for event := range stream {
+		require.NoError(t, event.Error)
+		switch event.Type {
+		case runnable.EventStream:
+			content += event.Data.Content
+			thinking += event.Data.ThinkingText()
+		case runnable.EventEnd:
+			final = event.Data
+		}
+	}
+
+	assert.Equal(t, "Simple math.", thinking)
+	assert.Equal(t, "42", content)
+	assert.Equal(t, "42", final.Content)
+	assert.Equal(t, "Simple math.", final.ThinkingText())
+	assert.Equal(t, 15, final.UsageMetadata.TotalTokens)
+}
+
+func TestChatModel_Invoke_Hooks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "tenant-a", r.Header.Get("X-Tenant"))
+
+		var req map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, float64(40), req["top_k"])
+
+		io.WriteString(w, `{"id": "chatcmpl-1", "choices": [{"index": 0, "message": {"role": "assistant", "content": "  Hi  ", "reasoning": "greet"}, "finish_reason": "stop"}]}`)
+	}))
+	defer server.Close()
+
+	model, err := New(Config{
+		APIKey:   "test-key",
+		BaseURL:  server.URL,
+		Provider: "vllm",
+		Headers:  map[string]string{"X-Tenant": "tenant-a"},
+		RequestHook: func(request map[string]any) error {
+			request["top_k"] = 40
+			return nil
+		},
+		ResponseHook: func(message *types.Message) error {
+			message.Content = strings.TrimSpace(message.Content)
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "vllm", model.GetProvider())
+	assert.Equal(t, "vllm", model.BindTools(nil).GetProvider())
+
+	response, err := model.Invoke(context.Background(), []types.Message{types.NewUserMessage("Hello")})
+	require.NoError(t, err)
+	assert.Equal(t, "Hi", response.Content)
+	assert.Equal(t, "greet", response.ThinkingText())
+
+	// RequestHook 返回错误时不发送请求
+	failing, err := New(Config{
+		APIKey:      "test-key",
+		BaseURL:     server.URL,
+		RequestHook: func(map[string]any) error { return fmt.Errorf("tools not supported") },
+	})
+	require.NoError(t, err)
+	_, err = failing.Invoke(context.Background(), []types.Message{types.NewUserMessage("Hello")})
+	assert.ErrorContains(t, err, "tools not supported")
+}
+
+func TestChatModel_Stream_CompatQuirks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		// 不带空格的 data 行、reasoning 字段、同一 index 上的两个工具调用，且没有 [DONE]
+		for _, data := range []string{
+			`{"choices":[{"index":0,"delta":{"reasoning":"look up"}}]}`,
+			`{"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"call_1","function":{"name":"search","arguments":"{\"q\":"}}]}}]}`,
+			`{"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"go\"}"}}]}}]}`,
+			`{"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"call_2","function":{"name":"clock","arguments":"{}"}}]}}]}`,
+			`{"choices":[{"index":0,"delta":{},"finish_reason":"tool_calls"}]}`,
+		} {
+			io.WriteString(w, "data:"+data+"\n\n")
+		}
+	}))
+	defer server.Close()
+
+	model, err := New(Config{APIKey: "test-key", BaseURL: server.URL})
+	require.NoError(t, err)
+
+	stream, err := model.Stream(context.Background(), []types.Message{types.NewUserMessage("Hello")})
+	require.NoError(t, err)
+
+	var final *types.Message
+	for event := range stream {
+		require.NoError(t, event.Error)
+		if event.Type == runnable.EventEnd {
+			final = &event.Data
+		}
+	}
+
+	require.NotNil(t, final)
+	assert.Equal(t, "look up", final.ThinkingText())
+	assert.Equal(t, types.FinishReasonToolCalls, final.FinishReason)
+	require.Len(t, final.ToolCalls, 2)
+	assert.Equal(t, "call_1", final.ToolCalls[0].ID)
+	assert.Equal(t, "function", final.ToolCalls[0].Type)
+	assert.Equal(t, `{"q":"go"}`, final.ToolCalls[0].Function.Arguments)
+	assert.Equal(t, "clock", final.ToolCalls[1].Function.Name)
+}
+
+func TestChatModel_Stream_Retry(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			io.WriteString(w, `{"error":{"message":"overloaded","type":"server_error"}}`)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"Hi\"},\"finish_reason\":\"stop\"}]}\n\n")
+		io.WriteString(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	model, err := New(Config{APIKey: "test-key", BaseURL: server.URL})
+	require.NoError(t, err)
+
+	retrying := model.WithRetry(types.RetryPolicy{MaxRetries: 2, InitialDelay: time.Millisecond, MaxDelay: time.Second, Multiplier: 2})
+	stream, err := retrying.Stream(context.Background(), []types.Message{types.NewUserMessage("Hello")})
+	require.NoError(t, err)
+
+	var final *types.Message
+	for event := range stream {
+		require.NoError(t, event.Error)
+		if event.Type == runnable.EventEnd {
+			final = &event.Data
+		}
+	}
+
+	require.NotNil(t, final)
+	assert.Equal(t, "Hi", final.Content)
+	assert.Equal(t, 2, requests)
+}
+
+func TestChatModel_Invoke_TypedErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("retry-after-ms", "1500")
+		w.WriteHeader(http.StatusTooManyRequests)
+		io.WriteString(w, `{"error":{"message":"Rate limit reached","type":"requests","code":"rate_limit_exceeded"}}`)
+	}))
+	defer server.Close()
+
+	model, err := New(Config{APIKey: "test-key", BaseURL: server.URL})
+	require.NoError(t, err)
+
+	_, err = model.Invoke(context.Background(), []types.Message{types.NewUserMessage("Hello")})
+
+	var rateLimited *chaterrors.RateLimitedError
+	require.ErrorAs(t, err, &rateLimited)
+	assert.Equal(t, "openai", rateLimited.Provider)
+	assert.Equal(t, "rate_limit_exceeded", rateLimited.Code)
+	assert.Equal(t, 1500*time.Millisecond, rateLimited.RetryAfter())
+	assert.Equal(t, runnable.ErrorKindRateLimit, runnable.ClassifyError(err))
+}
+
+func TestChatModel_Stream_ErrorChunk(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"Hi\"}}]}\n\n")
+		io.WriteString(w, "data: {\"error\":{\"object\":\"error\",\"message\":\"This model's maximum context length is 4096 tokens\",\"type\":\"BadRequestError\",\"code\":400}}\n\n")
+	}))
+	defer server.Close()
+
+	model, err := New(Config{APIKey: "EMPTY", BaseURL: server.URL, Provider: "vllm"})
+	require.NoError(t, err)
+
+	stream, err := model.Stream(context.Background(), []types.Message{types.NewUserMessage("Hello")})
+	require.NoError(t, err)
+
+	var streamErr error
+	for event := range stream {
+		if event.Type == runnable.EventError {
+			streamErr = event.Error
+		}
+	}
+
+	assert.ErrorIs(t, streamErr, chaterrors.ErrContextLengthExceeded)
+	assert.Contains(t, streamErr.Error(), "vllm: context length exceeded (status 400")
+}
+
+// llmRecorder 记录 LLM 回调事件
+type llmRecorder struct {
+	callbacks.BaseHandler
+
+	mu     sync.Mutex
+	starts []*callbacks.LLMStartEvent
+	tokens []string
+	ends   []*callbacks.LLMEndEvent
+}
+
+func (r *llmRecorder) OnLLMStart(ctx context.Context, e *callbacks.LLMStartEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.starts = append(r.starts, e)
+}
+
+func (r *llmRecorder) OnLLMToken(ctx context.Context, e *callbacks.LLMTokenEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tokens = append(r.tokens, e.Token)
+}
+
+func (r *llmRecorder) OnLLMEnd(ctx context.Context, e *callbacks.LLMEndEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ends = append(r.ends, e)
+}
+
+func TestChatModel_Callbacks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+		json.NewDecoder(r.Body).Decode(&req)
+		if req["stream"] != true {
+			w.WriteHeader(http.StatusUnauthorized)
+			io.WriteString(w, `{"error":{"message":"Incorrect API key","type":"invalid_request_error","code":"invalid_api_key"}}`)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, data := range []string{
+			`{"choices":[{"index":0,"delta":{"content":"Hel"},"finish_reason":null}]}`,
+			`{"choices":[{"index":0,"delta":{"content":"lo"},"finish_reason":"stop"}]}`,
+			`[DONE]`,
+		} {
+			io.WriteString(w, "data: "+data+"\n\n")
+		}
+	}))
+	defer server.Close()
+
+	model, err := New(Config{APIKey: "test-key", BaseURL: server.URL, Model: "gpt-4o-mini"})
+	require.NoError(t, err)
+	messages := []types.Message{types.NewUserMessage("Hello")}
+
+	t.Run("stream", func(t *testing.T) {
+		rec := &llmRecorder{}
+		stream, err := model.Stream(context.Background(), messages, runnable.WithCallbacks(rec))
+		require.NoError(t, err)
+		for range stream {
+		}
+
+		require.Len(t, rec.starts, 1)
+		assert.Equal(t, "openai", rec.starts[0].Provider)
+		assert.Equal(t, "gpt-4o-mini", rec.starts[0].Model)
+		assert.Equal(t, messages, rec.starts[0].Messages)
+		assert.Equal(t, []string{"Hel", "lo"}, rec.tokens)
+		require.Len(t, rec.ends, 1)
+		assert.NoError(t, rec.ends[0].Err)
+		assert.Equal(t, "Hello", rec.ends[0].Output.Content)
+		assert.Equal(t, rec.starts[0].RunID, rec.ends[0].RunID)
+	})
+
+	t.Run("global handler", func(t *testing.T) {
+		// 只安装全局处理器，上下文和调用选项中都没有处理器
+		rec := &llmRecorder{}
+		callbacks.AddGlobalHandlers(rec)
+		defer callbacks.ResetGlobalHandlers()
+
+		stream, err := model.Stream(context.Background(), messages)
+		require.NoError(t, err)
+		for range stream {
+		}
+
+		require.Len(t, rec.starts, 1)
+		assert.Equal(t, "gpt-4o-mini", rec.starts[0].Model)
+		require.Len(t, rec.ends, 1)
+		assert.Equal(t, "Hello", rec.ends[0].Output.Content)
+	})
+
+	t.Run("invoke error", func(t *testing.T) {
+		rec := &llmRecorder{}
+		ctx := callbacks.WithHandlers(context.Background(), rec)
+		parentCtx, parent := callbacks.StartChain(ctx, "parent", nil)
+
+		_, err := model.Invoke(parentCtx, messages)
+		require.Error(t, err)
+
+		require.Len(t, rec.starts, 1)
+		assert.Equal(t, parent.ID(), rec.starts[0].ParentRunID)
+		require.Len(t, rec.ends, 1)
+		assert.ErrorIs(t, rec.ends[0].Err, chaterrors.ErrAuthentication)
+	})
+}
+
+func TestChatModel_Callbacks_StreamCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for i := 0; i < 20; i++ {
+			io.WriteString(w, `data: {"choices":[{"index":0,"delta":{"content":"x"},"finish_reason":null}]}`+"\n\n")
+		}
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	model, err := New(Config{APIKey: "test-key", BaseURL: server.URL})
+	require.NoError(t, err)
+
+	rec := &llmRecorder{}
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := model.Stream(ctx, []types.Message{types.NewUserMessage("Hello")}, runnable.WithCallbacks(rec))
+	require.NoError(t, err)
+
+	// 读到第一个 token 后放弃读取
+	for event := range stream {
+		if event.Type == runnable.EventStream {
+			break
+		}
+	}
+	cancel()
+
+	require.Eventually(t, func() bool {
+		rec.mu.Lock()
+		defer rec.mu.Unlock()
+		return len(rec.ends) == 1
+	}, time.Second, 5*time.Millisecond)
+	assert.ErrorIs(t, rec.ends[0].Err, context.Canceled)
+}
@@ -0,0 +1,418 @@
+package openai
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/zhucl121/langchain-go/core/chat"
+	chaterrors "github.com/zhucl121/langchain-go/core/chat/errors"
+	"github.com/zhucl121/langchain-go/core/runnable"
+	"github.com/zhucl121/langchain-go/pkg/types"
+)
+
+// responsesPath 是 Responses API 的路径
+const responsesPath = "/responses"
+
+// useResponsesAPI 判断是否使用 Responses API。
+//
+// Chat Completions API 不返回推理摘要，需要推理摘要时改用 Responses API。
+func useResponsesAPI(thinking *types.ThinkingConfig) bool {
+	return thinking != nil && thinking.Enabled && thinking.Summary != ""
+}
+
+// invokeResponses 通过 Responses API 执行单次调用。
+func (m *ChatModel) invokeResponses(ctx context.Context, messages []types.Message, options *runnable.Options) (types.Message, error) {
+	reqBody, err := m.buildResponsesRequest(messages, false, options)
+	if err != nil {
+		return types.Message{}, fmt.Errorf("failed to build request: %w", err)
+	}
+
+	respBody, err := m.doRequest(ctx, responsesPath, reqBody)
+	if err != nil {
+		return types.Message{}, err
+	}
+
+	var response responsesResponse
+	if err := json.Unmarshal(respBody, &response); err != nil {
+		return types.Message{}, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	message, err := response.toMessage(m.config.Provider)
+	if err != nil {
+		return types.Message{}, err
+	}
+	return m.applyResponseHook(message)
+}
+
+// buildResponsesRequest 构建 Responses API 请求体。
+//
+// Responses API 不支持停止序列和随机种子，options 中的这两个参数会被忽略。
+func (m *ChatModel) buildResponsesRequest(messages []types.Message, stream bool, options *runnable.Options) ([]byte, error) {
+	input, err := messagesToResponsesInput(messages)
+	if err != nil {
+		return nil, err
+	}
+
+	thinking := options.Thinking
+	reasoning := map[string]any{"summary": thinking.Summary}
+	if effort := thinking.EffortLevel(); effort != "" {
+		reasoning["effort"] = effort
+	}
+
+	request := map[string]any{
+		"model":     m.config.Model,
+		"input":     input,
+		"reasoning": reasoning,
+		"stream":    stream,
+	}
+
+	// 添加可选参数（推理模型不支持采样参数）
+	if options.MaxTokens > 0 {
+		request["max_output_tokens"] = options.MaxTokens
+	} else if m.config.MaxTokens > 0 {
+		request["max_output_tokens"] = m.config.MaxTokens
+	}
+	if m.config.User != "" {
+		request["user"] = m.config.User
+	}
+
+	// 添加工具（Responses API 的函数定义是扁平结构）
+	if tools := m.GetBoundTools(); len(tools) > 0 {
+		responsesTools := make([]map[string]any, len(tools))
+		for i, tool := range tools {
+			responsesTools[i] = map[string]any{
+				"type":        "function",
+				"name":        tool.Name,
+				"description": tool.Description,
+				"parameters":  tool.Parameters.ToMap(),
+			}
+		}
+		request["tools"] = responsesTools
+
+		if choice := options.ToolChoice; choice != nil {
+			request["tool_choice"] = string(choice.Mode)
+			if choice.Mode == types.ToolChoiceTool {
+				request["tool_choice"] = map[string]any{"type": "function", "name": choice.Name}
+			}
+		}
+		if options.ParallelToolCalls != nil {
+			request["parallel_tool_calls"] = *options.ParallelToolCalls
+		}
+	}
+
+	// 添加结构化输出（单次调用的响应格式优先）
+	if format := options.ResponseFormat; format != nil {
+		// Responses API 的 format 是扁平结构
+		textFormat := format.ToOpenAI()
+		if schema, ok := textFormat["json_schema"].(map[string]any); ok {
+			delete(textFormat, "json_schema")
+			for k, v := range schema {
+				textFormat[k] = v
+			}
+		}
+		request["text"] = map[string]any{"format": textFormat}
+	} else if schema := m.GetOutputSchema(); schema != nil {
+		request["text"] = map[string]any{
+			"format": map[string]any{
+				"type":   "json_schema",
+				"name":   "response",
+				"schema": schema.ToMap(),
+				"strict": true,
+			},
+		}
+	}
+
+	return m.marshalRequest(request)
+}
+
+// messagesToResponsesInput 将消息列表转换为 Responses API 的 input 数组。
+//
+// 工具调用和工具结果分别转换为 function_call 和 function_call_output 项。
+func messagesToResponsesInput(messages []types.Message) ([]map[string]any, error) {
+	input := make([]map[string]any, 0, len(messages))
+
+	for i, msg := range messages {
+		switch msg.Role {
+		case types.RoleTool:
+			input = append(input, map[string]any{
+				"type":    "function_call_output",
+				"call_id": msg.ToolCallID,
+				"output":  msg.Content,
+			})
+
+		case types.RoleAssistant:
+			if msg.Content != "" {
+				input = append(input, map[string]any{
+					"role":    "assistant",
+					"content": msg.Content,
+				})
+			}
+			for _, tc := range msg.ToolCalls {
+				input = append(input, map[string]any{
+					"type":      "function_call",
+					"call_id":   tc.ID,
+					"name":      tc.Function.Name,
+					"arguments": tc.Function.Arguments,
+				})
+			}
+
+		default:
+			item := map[string]any{
+				"role":    string(msg.Role),
+				"content": msg.Content,
+			}
+			if len(msg.Parts) > 0 {
+				content, err := partsToResponses(msg.Parts)
+				if err != nil {
+					return nil, fmt.Errorf("invalid message at index %d: %w", i, err)
+				}
+				item["content"] = content
+			}
+			input = append(input, item)
+		}
+	}
+
+	return input, nil
+}
+
+// partsToResponses 将多模态内容转换为 Responses API 的 content 数组。
+//
+// 基于 Chat Completions 的格式转换：text → input_text，image_url → input_image，
+// file → input_file；音频不受支持。
+func partsToResponses(parts []*types.MultimodalContent) ([]map[string]any, error) {
+	converted, err := chat.PartsToOpenAI(parts)
+	if err != nil {
+		return nil, err
+	}
+
+	content := make([]map[string]any, len(converted))
+	for i, part := range converted {
+		switch part["type"] {
+		case "text":
+			content[i] = map[string]any{"type": "input_text", "text": part["text"]}
+
+		case "image_url":
+			image := part["image_url"].(map[string]any)
+			item := map[string]any{"type": "input_image", "image_url": image["url"]}
+			if detail, ok := image["detail"]; ok {
+				item["detail"] = detail
+			}
+			content[i] = item
+
+		case "file":
+			item := map[string]any{"type": "input_file"}
+			for k, v := range part["file"].(map[string]any) {
+				item[k] = v
+			}
+			content[i] = item
+
+		default:
+			return nil, fmt.Errorf("content type %v is not supported by the Responses API", part["type"])
+		}
+	}
+
+	return content, nil
+}
+
+// processResponsesStream 处理 Responses API 的流式响应。
+//
+// 文本和推理摘要增量作为流式事件发送，完整消息以 response.completed 中的响应为准。
+func (m *ChatModel) processResponsesStream(reader io.Reader, out chan<- runnable.StreamEvent[types.Message]) error {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		// 只处理数据行，事件类型在数据中重复给出
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+
+		var event responsesStreamEvent
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event); err != nil {
+			continue
+		}
+
+		switch event.Type {
+		case "response.output_text.delta":
+			out <- runnable.StreamEvent[types.Message]{
+				Type: runnable.EventStream,
+				Data: types.Message{
+					Role:    types.RoleAssistant,
+					Content: event.Delta,
+				},
+				Name: m.GetName(),
+			}
+
+		case "response.reasoning_summary_text.delta":
+			// 推理摘要增量，以思考块发送，不计入 Content
+			out <- runnable.StreamEvent[types.Message]{
+				Type: runnable.EventStream,
+				Data: types.Message{
+					Role:          types.RoleAssistant,
+					ContentBlocks: []*types.ContentBlock{types.NewThinkingContentBlock(event.Delta)},
+				},
+				Name: m.GetName(),
+			}
+
+		case "response.completed", "response.incomplete":
+			if event.Response == nil {
+				return fmt.Errorf("stream error: %s without response", event.Type)
+			}
+			message, err := event.Response.toMessage(m.config.Provider)
+			if err != nil {
+				return err
+			}
+			if message, err = m.applyResponseHook(message); err != nil {
+				return err
+			}
+			out <- runnable.StreamEvent[types.Message]{
+				Type: runnable.EventEnd,
+				Data: message,
+				Name: m.GetName(),
+			}
+			return nil
+
+		case "response.failed":
+			if event.Response != nil && event.Response.Error != nil {
+				return chaterrors.New(m.config.Provider, 0, event.Response.Error.Code, event.Response.Error.Message)
+			}
+			return chaterrors.New(m.config.Provider, 0, "", "response failed")
+
+		case "error":
+			return chaterrors.New(m.config.Provider, 0, event.Code, event.Message)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("error reading stream: %w", err)
+	}
+
+	return nil
+}
+
+// responsesResponse 是 Responses API 的响应结构。
+type responsesResponse struct {
+	ID                string          `json:"id"`
+	Status            string          `json:"status"`
+	Output            []responsesItem `json:"output"`
+	Usage             *responsesUsage `json:"usage"`
+	IncompleteDetails *struct {
+		Reason string `json:"reason"`
+	} `json:"incomplete_details"`
+	Error *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// responsesItem 是响应中的输出项（message、reasoning 或 function_call）。
+type responsesItem struct {
+	Type    string `json:"type"`
+	ID      string `json:"id"`
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	Summary []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"summary"`
+	CallID    string `json:"call_id"`
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+// responsesUsage 是 Responses API 的 token 用量。
+type responsesUsage struct {
+	InputTokens        int `json:"input_tokens"`
+	OutputTokens       int `json:"output_tokens"`
+	TotalTokens        int `json:"total_tokens"`
+	InputTokensDetails struct {
+		CachedTokens int `json:"cached_tokens"`
+	} `json:"input_tokens_details"`
+	OutputTokensDetails struct {
+		ReasoningTokens int `json:"reasoning_tokens"`
+	} `json:"output_tokens_details"`
+}
+
+// responsesStreamEvent 是 Responses API 的流式事件。
+type responsesStreamEvent struct {
+	Type     string             `json:"type"`
+	Delta    string             `json:"delta"`
+	Message  string             `json:"message"`
+	Code     string             `json:"code"`
+	Response *responsesResponse `json:"response"`
+}
+
+// toMessage 将响应转换为 Message，推理摘要转换为思考块。
+func (r *responsesResponse) toMessage(provider string) (types.Message, error) {
+	if r.Status == "failed" && r.Error != nil {
+		return types.Message{}, chaterrors.New(provider, 0, r.Error.Code, r.Error.Message)
+	}
+
+	msg := types.Message{Role: types.RoleAssistant}
+	var texts []string
+
+	for _, item := range r.Output {
+		switch item.Type {
+		case "message":
+			for _, content := range item.Content {
+				if content.Type == "output_text" {
+					texts = append(texts, content.Text)
+				}
+			}
+
+		case "reasoning":
+			summaries := make([]string, 0, len(item.Summary))
+			for _, summary := range item.Summary {
+				summaries = append(summaries, summary.Text)
+			}
+			if len(summaries) > 0 {
+				msg.ContentBlocks = append(msg.ContentBlocks,
+					types.NewThinkingContentBlock(strings.Join(summaries, "\n\n")).WithID(item.ID))
+			}
+
+		case "function_call":
+			msg.ToolCalls = append(msg.ToolCalls, types.ToolCall{
+				ID:   item.CallID,
+				Type: "function",
+				Function: types.FunctionCall{
+					Name:      item.Name,
+					Arguments: item.Arguments,
+				},
+			})
+		}
+	}
+
+	msg.Content = strings.Join(texts, "")
+
+	// 结束原因
+	switch {
+	case r.IncompleteDetails != nil && r.IncompleteDetails.Reason == "max_output_tokens":
+		msg.FinishReason = types.FinishReasonLength
+	case r.IncompleteDetails != nil && r.IncompleteDetails.Reason == "content_filter":
+		msg.FinishReason = types.FinishReasonContentFilter
+	case len(msg.ToolCalls) > 0:
+		msg.FinishReason = types.FinishReasonToolCalls
+	default:
+		msg.FinishReason = types.FinishReasonStop
+	}
+
+	if u := r.Usage; u != nil {
+		msg.UsageMetadata = &types.UsageMetadata{
+			InputTokens:     u.InputTokens,
+			OutputTokens:    u.OutputTokens,
+			TotalTokens:     u.TotalTokens,
+			CachedTokens:    u.InputTokensDetails.CachedTokens,
+			ReasoningTokens: u.OutputTokensDetails.ReasoningTokens,
+		}
+	}
+
+	return msg, nil
+}
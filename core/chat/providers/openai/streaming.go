@@ -43,7 +43,7 @@ func (m *ChatModel) StreamTokens(ctx context.Context, messages []types.Message)
 		}
 
 		// 发送请求
-		resp, err := m.doStreamRequest(ctx, reqBody)
+		resp, err := m.doStreamRequest(ctx, chatCompletionsPath, reqBody)
 		if err != nil {
 			out <- types.NewErrorEvent(err)
 			return
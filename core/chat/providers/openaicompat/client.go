@@ -0,0 +1,285 @@
+package openaicompat
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zhucl121/langchain-go/core/chat/providers/openai"
+	"github.com/zhucl121/langchain-go/pkg/types"
+)
+
+const (
+	// ProfileVLLM 是 vLLM 的预置配置名称
+	ProfileVLLM = "vllm"
+
+	// ProfileLlamaCpp 是 llama.cpp server 的预置配置名称
+	ProfileLlamaCpp = "llamacpp"
+
+	// ProfileLMStudio 是 LM Studio 的预置配置名称
+	ProfileLMStudio = "lmstudio"
+
+	// DefaultName 是未设置名称且未使用预置配置时的提供商名称
+	DefaultName = "openai-compatible"
+
+	// DefaultAPIKey 是未设置 APIKey 时发送的占位密钥（本地服务通常不校验）
+	DefaultAPIKey = "EMPTY"
+)
+
+// ErrUnsupported 表示请求使用了服务不支持的能力。
+var ErrUnsupported = errors.New("openaicompat: capability not supported")
+
+// Capabilities 描述兼容服务支持的 OpenAI API 能力。
+//
+// 不支持的能力在请求发送前处理：
+//   - Tools: 绑定了工具时返回 ErrUnsupported
+//   - JSONSchema: json_schema 响应格式降级为 json_object
+//   - StrictSchema: 删除响应格式和工具定义中的 strict 标志
+//   - StreamUsage: 不发送 stream_options（部分服务遇到未知字段会报错）
+//   - Vision: 消息包含图片时返回 ErrUnsupported
+//
+type Capabilities struct {
+	Tools        bool `json:"tools"`
+	JSONSchema   bool `json:"json_schema"`
+	StrictSchema bool `json:"strict_schema"`
+	StreamUsage  bool `json:"stream_usage"`
+	Vision       bool `json:"vision"`
+}
+
+// Profile 是一类兼容服务的预置配置。
+type Profile struct {
+	// BaseURL 是服务的默认地址
+	BaseURL string
+
+	// Capabilities 是服务支持的能力
+	Capabilities Capabilities
+}
+
+// Profiles 是预置的服务配置，可以添加新的条目供 Config.Profile 引用。
+var Profiles = map[string]Profile{
+	ProfileVLLM: {
+		BaseURL: "http://localhost:8000/v1",
+		Capabilities: Capabilities{
+			Tools:       true,
+			JSONSchema:  true,
+			StreamUsage: true,
+			Vision:      true,
+		},
+	},
+	ProfileLlamaCpp: {
+		BaseURL: "http://localhost:8080/v1",
+		Capabilities: Capabilities{
+			Tools:      true,
+			JSONSchema: true,
+		},
+	},
+	ProfileLMStudio: {
+		BaseURL: "http://localhost:1234/v1",
+		Capabilities: Capabilities{
+			Tools:      true,
+			JSONSchema: true,
+			Vision:     true,
+		},
+	},
+}
+
+// DefaultCapabilities 是未使用预置配置且未设置能力时的能力（只假设支持工具调用）。
+var DefaultCapabilities = Capabilities{Tools: true}
+
+// RequestHook 在请求体序列化前调用，可以修改请求体，返回错误时中止请求。
+type RequestHook func(request map[string]any) error
+
+// ResponseHook 在返回最终消息前调用，可以修改消息，返回错误时调用失败。
+type ResponseHook func(message *types.Message) error
+
+// Config 是兼容服务 ChatModel 的配置。
+//
+// 除钩子外的字段都可以从 JSON 加载，见 LoadRegistry。
+type Config struct {
+	// Name 是服务名称（可选，默认为预置配置名称或 openai-compatible）
+	// 作为 ChatModel 的提供商名称，也是注册表中的键
+	Name string `json:"name"`
+
+	// Profile 是预置配置名称（可选，见 Profiles）
+	// 提供 BaseURL 和 Capabilities 的默认值
+	Profile string `json:"profile,omitempty"`
+
+	// BaseURL 是 API 基础地址（未使用预置配置时必需），如 http://localhost:8000/v1
+	BaseURL string `json:"base_url,omitempty"`
+
+	// APIKey 是 API 密钥（可选，默认为 EMPTY）
+	APIKey string `json:"api_key,omitempty"`
+
+	// Model 是模型名称（必需），即服务加载的模型
+	Model string `json:"model"`
+
+	// Temperature 控制输出的随机性（可选，0.0-2.0，默认 0.7）
+	Temperature float64 `json:"temperature,omitempty"`
+
+	// MaxTokens 是最大生成 token 数（可选）
+	MaxTokens int `json:"max_tokens,omitempty"`
+
+	// Timeout 是请求超时时间（可选，默认 60 秒），JSON 中以纳秒表示
+	Timeout time.Duration `json:"timeout,omitempty"`
+
+	// Headers 是附加的请求头（可选）
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// Capabilities 是服务支持的能力（可选，覆盖预置配置）
+	Capabilities *Capabilities `json:"capabilities,omitempty"`
+
+	// RequestHooks 按顺序在能力处理之后调用（可选）
+	RequestHooks []RequestHook `json:"-"`
+
+	// ResponseHooks 按顺序对最终消息调用（可选）
+	ResponseHooks []ResponseHook `json:"-"`
+}
+
+// Validate 验证配置的有效性。
+func (c Config) Validate() error {
+	if c.Profile != "" {
+		if _, ok := Profiles[c.Profile]; !ok {
+			return fmt.Errorf("unknown profile %q", c.Profile)
+		}
+	} else if c.BaseURL == "" {
+		return fmt.Errorf("BaseURL is required when Profile is not set")
+	}
+
+	if c.Model == "" {
+		return fmt.Errorf("Model is required")
+	}
+
+	return nil
+}
+
+// resolve 返回填充了默认值的配置和生效的能力。
+func (c Config) resolve() (Config, Capabilities) {
+	capabilities := DefaultCapabilities
+	if profile, ok := Profiles[c.Profile]; ok {
+		capabilities = profile.Capabilities
+		if c.BaseURL == "" {
+			c.BaseURL = profile.BaseURL
+		}
+		if c.Name == "" {
+			c.Name = c.Profile
+		}
+	}
+	if c.Capabilities != nil {
+		capabilities = *c.Capabilities
+	}
+	if c.Name == "" {
+		c.Name = DefaultName
+	}
+	if c.APIKey == "" {
+		c.APIKey = DefaultAPIKey
+	}
+	return c, capabilities
+}
+
+// New 创建兼容服务的 ChatModel。
+//
+// 返回的是 openai.ChatModel，请求构建和流式解析与 OpenAI 相同，
+// 提供商名称为 Config.Name，请求发送前按能力处理不支持的参数。
+//
+// 参数：
+//   - config: 服务配置
+//
+// 返回：
+//   - *openai.ChatModel: ChatModel 实例
+//   - error: 配置错误
+//
+func New(config Config) (*openai.ChatModel, error) {
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	config, capabilities := config.resolve()
+
+	requestHooks := append([]RequestHook{capabilities.apply}, config.RequestHooks...)
+	responseHooks := config.ResponseHooks
+
+	openaiConfig := openai.Config{
+		APIKey:      config.APIKey,
+		BaseURL:     config.BaseURL,
+		Model:       config.Model,
+		Temperature: config.Temperature,
+		MaxTokens:   config.MaxTokens,
+		Timeout:     config.Timeout,
+		Provider:    config.Name,
+		Headers:     config.Headers,
+		RequestHook: func(request map[string]any) error {
+			for _, hook := range requestHooks {
+				if err := hook(request); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	if len(responseHooks) > 0 {
+		openaiConfig.ResponseHook = func(message *types.Message) error {
+			for _, hook := range responseHooks {
+				if err := hook(message); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+
+	return openai.New(openaiConfig)
+}
+
+// apply 按能力处理请求体中服务不支持的参数。
+func (c Capabilities) apply(request map[string]any) error {
+	if tools, ok := request["tools"].([]map[string]any); ok && len(tools) > 0 {
+		if !c.Tools {
+			return fmt.Errorf("%w: tools", ErrUnsupported)
+		}
+		if !c.StrictSchema {
+			for _, tool := range tools {
+				if function, ok := tool["function"].(map[string]any); ok {
+					delete(function, "strict")
+				}
+			}
+		}
+	}
+
+	if format, ok := request["response_format"].(map[string]any); ok && format["type"] == "json_schema" {
+		if !c.JSONSchema {
+			request["response_format"] = map[string]any{"type": "json_object"}
+		} else if schema, ok := format["json_schema"].(map[string]any); ok && !c.StrictSchema {
+			delete(schema, "strict")
+		}
+	}
+
+	if !c.StreamUsage {
+		delete(request, "stream_options")
+	}
+
+	if !c.Vision && hasImageContent(request["messages"]) {
+		return fmt.Errorf("%w: image input", ErrUnsupported)
+	}
+
+	return nil
+}
+
+// hasImageContent 返回 OpenAI 格式的消息中是否包含图片。
+func hasImageContent(messages any) bool {
+	list, ok := messages.([]map[string]any)
+	if !ok {
+		return false
+	}
+	for _, message := range list {
+		parts, ok := message["content"].([]map[string]any)
+		if !ok {
+			continue
+		}
+		for _, part := range parts {
+			if part["type"] == "image_url" {
+				return true
+			}
+		}
+	}
+	return false
+}
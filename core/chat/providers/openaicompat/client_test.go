@@ -0,0 +1,158 @@
+package openaicompat
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zhucl121/langchain-go/core/runnable"
+	"github.com/zhucl121/langchain-go/pkg/types"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr string
+	}{
+		{name: "profile", config: Config{Profile: ProfileVLLM, Model: "qwen"}},
+		{name: "base url", config: Config{BaseURL: "http://localhost:9000/v1", Model: "qwen"}},
+		{name: "unknown profile", config: Config{Profile: "tgi", Model: "qwen"}, wantErr: "unknown profile"},
+		{name: "missing base url", config: Config{Model: "qwen"}, wantErr: "BaseURL is required"},
+		{name: "missing model", config: Config{Profile: ProfileVLLM}, wantErr: "Model is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestNew_Defaults(t *testing.T) {
+	model, err := New(Config{Profile: ProfileLMStudio, Model: "qwen2.5-7b-instruct"})
+	require.NoError(t, err)
+	assert.Equal(t, ProfileLMStudio, model.GetProvider())
+	assert.Equal(t, "lmstudio/qwen2.5-7b-instruct", model.GetName())
+
+	model, err = New(Config{BaseURL: "http://localhost:9000/v1", Model: "llama"})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultName, model.GetProvider())
+}
+
+func TestNew_Invoke(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/chat/completions", r.URL.Path)
+		assert.Equal(t, "Bearer EMPTY", r.Header.Get("Authorization"))
+
+		var req map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "qwen", req["model"])
+		assert.Equal(t, float64(40), req["top_k"])
+
+		io.WriteString(w, `{"id": "cmpl-1", "choices": [{"index": 0, "message": {"role": "assistant", "content": "Hi", "reasoning_content": "say hi"}, "finish_reason": "stop"}]}`)
+	}))
+	defer server.Close()
+
+	model, err := New(Config{
+		Name:    "gpu-box",
+		BaseURL: server.URL,
+		Model:   "qwen",
+		RequestHooks: []RequestHook{func(request map[string]any) error {
+			request["top_k"] = 40
+			return nil
+		}},
+		ResponseHooks: []ResponseHook{func(message *types.Message) error {
+			message.Content = strings.ToUpper(message.Content)
+			return nil
+		}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "gpu-box", model.GetProvider())
+
+	response, err := model.Invoke(context.Background(), []types.Message{types.NewUserMessage("Hello")})
+	require.NoError(t, err)
+	assert.Equal(t, "HI", response.Content)
+	assert.Equal(t, "say hi", response.ThinkingText())
+}
+
+func TestNew_Capabilities(t *testing.T) {
+	var req map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req = nil
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"{}\"},\"finish_reason\":\"stop\"}]}\n\n")
+		io.WriteString(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	strictTool := types.Tool{Name: "search", Parameters: types.Schema{Type: "object"}, Strict: true}
+	schemaFormat := types.NewJSONSchemaFormat("answer", types.Schema{Type: "object"})
+	messages := []types.Message{types.NewUserMessage("Hello")}
+
+	drain := func(t *testing.T, stream <-chan runnable.StreamEvent[types.Message]) {
+		for event := range stream {
+			require.NoError(t, event.Error)
+		}
+	}
+
+	t.Run("downgrade", func(t *testing.T) {
+		model, err := New(Config{BaseURL: server.URL, Model: "llama", Capabilities: &Capabilities{Tools: true}})
+		require.NoError(t, err)
+
+		stream, err := model.BindTools([]types.Tool{strictTool}).Stream(context.Background(), messages,
+			runnable.WithResponseFormat(schemaFormat))
+		require.NoError(t, err)
+		drain(t, stream)
+
+		assert.NotContains(t, req, "stream_options")
+		assert.Equal(t, map[string]any{"type": "json_object"}, req["response_format"])
+		function := req["tools"].([]any)[0].(map[string]any)["function"].(map[string]any)
+		assert.NotContains(t, function, "strict")
+	})
+
+	t.Run("json schema without strict", func(t *testing.T) {
+		model, err := New(Config{Profile: ProfileVLLM, BaseURL: server.URL, Model: "qwen"})
+		require.NoError(t, err)
+
+		stream, err := model.Stream(context.Background(), messages, runnable.WithResponseFormat(schemaFormat))
+		require.NoError(t, err)
+		drain(t, stream)
+
+		assert.Equal(t, map[string]any{"include_usage": true}, req["stream_options"])
+		format := req["response_format"].(map[string]any)
+		assert.Equal(t, "json_schema", format["type"])
+		assert.NotContains(t, format["json_schema"], "strict")
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		req = nil
+		model, err := New(Config{BaseURL: server.URL, Model: "llama", Capabilities: &Capabilities{}})
+		require.NoError(t, err)
+
+		_, err = model.BindTools([]types.Tool{strictTool}).Invoke(context.Background(), messages)
+		assert.ErrorIs(t, err, ErrUnsupported)
+
+		image := types.NewMessageWithParts(types.RoleUser,
+			types.NewTextContent("What is this?"),
+			types.NewImageContent("https://example.com/cat.png", types.ImageFormatPNG),
+		)
+		_, err = model.Invoke(context.Background(), []types.Message{image})
+		assert.ErrorIs(t, err, ErrUnsupported)
+		assert.Nil(t, req, "unsupported requests must not be sent")
+	})
+}
@@ -0,0 +1,54 @@
+// Package openaicompat 提供兼容 OpenAI API 的本地/自托管服务的 ChatModel。
+//
+// vLLM、llama.cpp server、LM Studio 等服务实现了 OpenAI Chat Completions API，
+// 但支持程度不一：不支持 strict 模式的 JSON Schema、不返回流式用量、
+// 工具调用的流式增量格式不同、在 reasoning_content 中返回思考过程等。
+//
+// 本包基于 openai 包的请求构建和流式解析实现，通过能力标志（Capabilities）
+// 在请求发送前降级或拒绝服务不支持的参数，并支持请求/响应钩子处理其他差异。
+// 新的服务只需配置即可注册，不需要编写新的提供商代码。
+//
+// 支持特性：
+//   - 预置 vLLM、llama.cpp、LM Studio 的能力配置和默认地址
+//   - 能力标志：工具调用、JSON Schema、strict 模式、流式用量、视觉输入
+//   - 请求钩子和响应钩子
+//   - 从 JSON 配置加载多个服务的注册表
+//
+// 基本用法：
+//
+//	import "github.com/zhucl121/langchain-go/core/chat/providers/openaicompat"
+//
+//	model, err := openaicompat.New(openaicompat.Config{
+//	    Profile: openaicompat.ProfileVLLM,
+//	    Model:   "Qwen/Qwen2.5-7B-Instruct",
+//	})
+//
+//	response, err := model.Invoke(ctx, messages)
+//
+// 自定义服务：
+//
+//	model, err := openaicompat.New(openaicompat.Config{
+//	    Name:    "my-gateway",
+//	    BaseURL: "http://gpu-box:9000/v1",
+//	    Model:   "llama-3.1-8b",
+//	    Capabilities: &openaicompat.Capabilities{
+//	        Tools:      true,
+//	        JSONSchema: true,
+//	    },
+//	    RequestHooks: []openaicompat.RequestHook{
+//	        func(request map[string]any) error {
+//	            request["top_k"] = 40
+//	            return nil
+//	        },
+//	    },
+//	})
+//
+// 通过配置注册：
+//
+//	registry, err := openaicompat.LoadRegistry([]byte(`[
+//	    {"name": "local-vllm", "profile": "vllm", "model": "Qwen/Qwen2.5-7B-Instruct"},
+//	    {"name": "laptop", "profile": "lmstudio", "model": "qwen2.5-7b-instruct"}
+//	]`))
+//	model, err := registry.New("local-vllm")
+//
+package openaicompat
@@ -0,0 +1,127 @@
+package openaicompat
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/zhucl121/langchain-go/core/chat/providers/openai"
+)
+
+// Registry 是兼容服务的注册表。
+//
+// 以 Config.Name 为键保存服务配置，按名称创建 ChatModel。
+// 可以并发使用。
+type Registry struct {
+	mu      sync.RWMutex
+	configs map[string]Config
+}
+
+// NewRegistry 创建空的注册表。
+//
+// 返回：
+//   - *Registry: 注册表实例
+//
+func NewRegistry() *Registry {
+	return &Registry{
+		configs: make(map[string]Config),
+	}
+}
+
+// LoadRegistry 从 JSON 数组加载注册表。
+//
+// 每个元素是一个 Config，钩子不能通过 JSON 配置，需要时通过 Get 取出
+// 配置、设置钩子后重新 Register。
+//
+// 参数：
+//   - data: Config 的 JSON 数组
+//
+// 返回：
+//   - *Registry: 注册表实例
+//   - error: 解析或验证错误
+//
+func LoadRegistry(data []byte) (*Registry, error) {
+	var configs []Config
+	if err := json.Unmarshal(data, &configs); err != nil {
+		return nil, fmt.Errorf("failed to parse registry: %w", err)
+	}
+
+	registry := NewRegistry()
+	for i, config := range configs {
+		if err := registry.Register(config); err != nil {
+			return nil, fmt.Errorf("invalid endpoint at index %d: %w", i, err)
+		}
+	}
+	return registry, nil
+}
+
+// Register 注册服务，同名服务会被覆盖。
+//
+// 参数：
+//   - config: 服务配置，未设置 Name 时使用预置配置名称
+//
+// 返回：
+//   - error: 配置错误
+//
+func (r *Registry) Register(config Config) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	if config.Name == "" {
+		config.Name = config.Profile
+	}
+	if config.Name == "" {
+		return fmt.Errorf("Name is required")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.configs[config.Name] = config
+	return nil
+}
+
+// Get 获取服务配置。
+//
+// 参数：
+//   - name: 服务名称
+//
+// 返回：
+//   - Config: 服务配置
+//   - bool: 是否存在
+//
+func (r *Registry) Get(name string) (Config, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	config, ok := r.configs[name]
+	return config, ok
+}
+
+// Names 返回已注册的服务名称（按名称排序）。
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.configs))
+	for name := range r.configs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New 按名称创建 ChatModel。
+//
+// 参数：
+//   - name: 服务名称
+//
+// 返回：
+//   - *openai.ChatModel: ChatModel 实例
+//   - error: 服务未注册或配置错误
+//
+func (r *Registry) New(name string) (*openai.ChatModel, error) {
+	config, ok := r.Get(name)
+	if !ok {
+		return nil, fmt.Errorf("endpoint %q not registered", name)
+	}
+	return New(config)
+}
@@ -0,0 +1,51 @@
+package openaicompat
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadRegistry(t *testing.T) {
+	registry, err := LoadRegistry([]byte(`[
+		{"name": "local-vllm", "profile": "vllm", "model": "Qwen/Qwen2.5-7B-Instruct"},
+		{"profile": "llamacpp", "model": "default"},
+		{
+			"name": "gateway",
+			"base_url": "http://gateway:9000/v1",
+			"api_key": "secret",
+			"model": "llama-3.1-8b",
+			"headers": {"X-Route": "gpu"},
+			"capabilities": {"tools": true, "json_schema": true}
+		}
+	]`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gateway", "llamacpp", "local-vllm"}, registry.Names())
+
+	config, ok := registry.Get("gateway")
+	require.True(t, ok)
+	assert.Equal(t, "http://gateway:9000/v1", config.BaseURL)
+	assert.Equal(t, map[string]string{"X-Route": "gpu"}, config.Headers)
+	require.NotNil(t, config.Capabilities)
+	assert.Equal(t, Capabilities{Tools: true, JSONSchema: true}, *config.Capabilities)
+
+	model, err := registry.New("local-vllm")
+	require.NoError(t, err)
+	assert.Equal(t, "local-vllm", model.GetProvider())
+	assert.Equal(t, "Qwen/Qwen2.5-7B-Instruct", model.GetModelName())
+
+	_, err = registry.New("missing")
+	assert.ErrorContains(t, err, "not registered")
+}
+
+func TestLoadRegistry_Invalid(t *testing.T) {
+	_, err := LoadRegistry([]byte(`{"name": "x"}`))
+	assert.ErrorContains(t, err, "failed to parse registry")
+
+	_, err = LoadRegistry([]byte(`[{"name": "x", "model": "m"}]`))
+	assert.ErrorContains(t, err, "index 0")
+
+	registry := NewRegistry()
+	assert.ErrorContains(t, registry.Register(Config{BaseURL: "http://localhost/v1", Model: "m"}), "Name is required")
+}
@@ -0,0 +1,34 @@
+// Package router 提供按请求选择模型的路由 ChatModel。
+//
+// Router 包装多个候选模型，每次调用根据请求特征和各模型的运行状况选择模型：
+//
+//   - 路由条件：提示词长度、是否绑定工具、是否包含图像、是否要求结构化输出、
+//     租户等级，以及自定义规则（Route.Match）
+//   - 运行状况：复用 balancer.AdaptiveBalancer 的评分，根据最近调用的
+//     成功率和延迟计算健康得分
+//   - 费用：按 cost.PriceTable 中的单价折减得分，便宜的模型优先
+//
+// 调用失败时自动回退到下一个候选，路由决策（候选得分、跳过原因、失败记录）
+// 写入响应消息的 Metadata[MetadataRoute]。请求本身导致的错误（如 chat/errors 中的
+// ContextLengthExceededError）同样回退，但不降低该路由的健康得分。
+//
+// # 使用示例
+//
+//	r, err := router.New([]router.Route{
+//	    {Model: gpt4oMini, MaxPromptTokens: 8000, Tools: true, StructuredOutput: true},
+//	    {Model: gpt4o, Tools: true, Vision: true, StructuredOutput: true, Tiers: []string{"pro"}},
+//	    {Model: localLlama, MaxPromptTokens: 4000},
+//	}, router.WithCostWeight(0.05))
+//
+//	ctx = router.WithTier(ctx, "pro")
+//	response, err := r.Invoke(ctx, messages)
+//
+//	decision := response.Metadata[router.MetadataRoute].(router.Decision)
+//	fmt.Println(decision.Route, decision.Failures)
+//
+// # 租户等级
+//
+// 租户等级优先取 WithTier 设置的值，其次取上下文中租户（tenant.WithTenant）
+// 的 Metadata["tier"]。
+//
+package router
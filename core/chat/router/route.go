@@ -0,0 +1,159 @@
+package router
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zhucl121/langchain-go/core/chat"
+	"github.com/zhucl121/langchain-go/core/runnable"
+	"github.com/zhucl121/langchain-go/pkg/enterprise/tenant"
+	"github.com/zhucl121/langchain-go/pkg/types"
+)
+
+// Route 是路由器的一个候选模型。
+//
+// 能力字段为 false 时，需要该能力的请求不会路由到此模型。
+type Route struct {
+	// Name 是路由名称（可选，默认为模型的 GetName()），在路由器内唯一
+	Name string
+
+	// Model 是候选模型（必需）
+	Model chat.ChatModel
+
+	// MaxPromptTokens 是可以处理的最大提示词长度（估算的 token 数），0 表示不限制
+	MaxPromptTokens int
+
+	// Tools 表示是否支持工具调用
+	Tools bool
+
+	// Vision 表示是否支持图像输入
+	Vision bool
+
+	// StructuredOutput 表示是否支持结构化输出
+	StructuredOutput bool
+
+	// Tiers 是允许使用的租户等级，为空时不限制
+	Tiers []string
+
+	// Match 是自定义路由条件（可选），返回 false 时跳过此路由
+	Match func(req Request) bool
+}
+
+// name 返回路由名称。
+func (r Route) name() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return r.Model.GetName()
+}
+
+// reject 返回路由不能处理请求的原因，可以处理时返回空字符串。
+func (r Route) reject(req Request) string {
+	if r.MaxPromptTokens > 0 && req.PromptTokens > r.MaxPromptTokens {
+		return fmt.Sprintf("prompt too long (%d > %d tokens)", req.PromptTokens, r.MaxPromptTokens)
+	}
+	if req.Tools && !r.Tools {
+		return "tools not supported"
+	}
+	if req.Images && !r.Vision {
+		return "images not supported"
+	}
+	if req.StructuredOutput && !r.StructuredOutput {
+		return "structured output not supported"
+	}
+	if len(r.Tiers) > 0 && !containsString(r.Tiers, req.Tier) {
+		return fmt.Sprintf("tier %q not allowed", req.Tier)
+	}
+	if r.Match != nil && !r.Match(req) {
+		return "custom rule not matched"
+	}
+	return ""
+}
+
+// Request 是路由时使用的请求特征。
+type Request struct {
+	// PromptTokens 是估算的提示词 token 数（约 4 个字符一个 token）
+	PromptTokens int `json:"prompt_tokens"`
+
+	// Tools 表示是否绑定了工具
+	Tools bool `json:"tools,omitempty"`
+
+	// Images 表示消息中是否包含图像
+	Images bool `json:"images,omitempty"`
+
+	// StructuredOutput 表示是否要求结构化输出
+	StructuredOutput bool `json:"structured_output,omitempty"`
+
+	// Tier 是租户等级
+	Tier string `json:"tier,omitempty"`
+}
+
+// tierKey 是租户等级的上下文键
+type tierKey struct{}
+
+// WithTier 在上下文中设置租户等级。
+//
+// 参数：
+//   - ctx: 上下文
+//   - tier: 租户等级（如 "free"、"pro"）
+//
+// 返回：
+//   - context.Context: 带租户等级的上下文
+//
+func WithTier(ctx context.Context, tier string) context.Context {
+	return context.WithValue(ctx, tierKey{}, tier)
+}
+
+// TierFromContext 返回上下文中的租户等级。
+//
+// 优先使用 WithTier 设置的等级，其次使用上下文中租户（tenant.WithTenant）
+// 的 Metadata["tier"]，都没有时返回空字符串。
+func TierFromContext(ctx context.Context) string {
+	if tier, ok := ctx.Value(tierKey{}).(string); ok {
+		return tier
+	}
+	if t, ok := tenant.GetTenant(ctx); ok && t != nil {
+		if tier, ok := t.Metadata["tier"].(string); ok {
+			return tier
+		}
+	}
+	return ""
+}
+
+// newRequest 分析一次调用的请求特征。
+func newRequest(ctx context.Context, messages []types.Message, tools bool, structured bool, opts []runnable.Option) Request {
+	req := Request{
+		Tools:            tools,
+		StructuredOutput: structured,
+		Tier:             TierFromContext(ctx),
+	}
+
+	if len(opts) > 0 {
+		if format := runnable.NewOptions(opts...).ResponseFormat; format != nil && format.IsJSON() {
+			req.StructuredOutput = true
+		}
+	}
+
+	chars := 0
+	for _, msg := range messages {
+		chars += len([]rune(msg.Content))
+		for _, part := range msg.Parts {
+			if part.IsImage() {
+				req.Images = true
+			}
+		}
+	}
+	req.PromptTokens = chars / 4
+
+	return req
+}
+
+// containsString 返回 list 是否包含 s。
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
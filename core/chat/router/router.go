@@ -0,0 +1,547 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zhucl121/langchain-go/core/chat"
+	chaterrors "github.com/zhucl121/langchain-go/core/chat/errors"
+	"github.com/zhucl121/langchain-go/core/runnable"
+	"github.com/zhucl121/langchain-go/pkg/cluster/balancer"
+	"github.com/zhucl121/langchain-go/pkg/cluster/node"
+	"github.com/zhucl121/langchain-go/pkg/cost"
+	"github.com/zhucl121/langchain-go/pkg/types"
+)
+
+const (
+	// MetadataRoute 是响应消息 Metadata 中路由决策（Decision）的键
+	MetadataRoute = "route"
+
+	// DefaultCostWeight 是默认的费用权重
+	//
+	// AdaptiveBalancer 中成功率的权重为 0.15，费用权重应小于它，
+	// 否则持续失败的便宜模型仍会排在健康的昂贵模型之前。
+	DefaultCostWeight = 0.1
+
+	// DefaultWindowSize 是默认的指标窗口大小
+	DefaultWindowSize = 50
+)
+
+var (
+	// ErrNoRoute 没有路由满足请求的条件
+	ErrNoRoute = errors.New("router: no route matches request")
+
+	// ErrAllRoutesFailed 所有候选路由都调用失败
+	ErrAllRoutesFailed = errors.New("router: all routes failed")
+)
+
+// Decision 是一次调用的路由决策。
+type Decision struct {
+	// Route 是最终使用的路由，全部失败时为空
+	Route string `json:"route"`
+
+	// Request 是用于路由的请求特征
+	Request Request `json:"request"`
+
+	// Candidates 是满足条件的路由，按得分从高到低排列
+	Candidates []Candidate `json:"candidates"`
+
+	// Skipped 是不满足条件的路由及原因
+	Skipped map[string]string `json:"skipped,omitempty"`
+
+	// Failures 是回退前失败的路由
+	Failures []Failure `json:"failures,omitempty"`
+}
+
+// Candidate 是一个候选路由的得分。
+type Candidate struct {
+	// Route 是路由名称
+	Route string `json:"route"`
+
+	// Score 是最终得分（健康得分按费用折减）
+	Score float64 `json:"score"`
+
+	// HealthScore 是 AdaptiveBalancer 根据成功率和延迟计算的得分
+	HealthScore float64 `json:"health_score"`
+
+	// Price 是输入和输出单价之和（美元 / 百万 token），价格表中没有时为 0
+	Price float64 `json:"price"`
+}
+
+// Failure 是一次失败的路由尝试。
+type Failure struct {
+	Route string `json:"route"`
+	Error string `json:"error"`
+}
+
+// Router 是按请求选择模型的 ChatModel。
+//
+// 每次调用先按路由条件（提示词长度、工具、图像、结构化输出、租户等级、
+// 自定义规则）筛选候选，再按得分排序：
+//
+//	score = health * (1 - costWeight * price / maxPrice)
+//
+// 其中 health 复用 balancer.AdaptiveBalancer 的评分，根据每个路由
+// 最近调用的成功率和延迟计算；price 来自 cost.PriceTable。
+//
+// 调用失败时按得分依次回退到下一个候选。流式调用在收到第一个数据事件前
+// 失败时回退，之后的错误直接返回。响应消息的 Metadata[MetadataRoute]
+// 是本次调用的 Decision。
+//
+// BindTools、WithStructuredOutput 返回的路由器与原路由器共享调用统计。
+//
+type Router struct {
+	routes     []Route
+	balancer   *balancer.AdaptiveBalancer
+	prices     *cost.PriceTable
+	costWeight float64
+	windowSize int
+	tools      bool
+	structured bool
+}
+
+// Option 是 Router 的配置选项
+type Option func(*Router)
+
+// WithPriceTable 设置用于比较费用的价格表（默认为 cost.DefaultPriceTable()）。
+func WithPriceTable(prices *cost.PriceTable) Option {
+	return func(r *Router) {
+		r.prices = prices
+	}
+}
+
+// WithCostWeight 设置费用权重（0-1，默认 0.1），0 表示不考虑费用。
+func WithCostWeight(weight float64) Option {
+	return func(r *Router) {
+		r.costWeight = weight
+	}
+}
+
+// WithWindowSize 设置计算成功率和延迟的指标窗口大小（默认 50 次调用）。
+func WithWindowSize(size int) Option {
+	return func(r *Router) {
+		r.windowSize = size
+	}
+}
+
+// New 创建模型路由器。
+//
+// 参数：
+//   - routes: 候选路由，得分相同时按顺序优先
+//   - opts: 配置选项
+//
+// 返回：
+//   - *Router: 路由器
+//   - error: 路由配置错误
+//
+// 示例：
+//
+//	r, err := router.New([]router.Route{
+//	    {Model: gpt4oMini, MaxPromptTokens: 8000, Tools: true, StructuredOutput: true},
+//	    {Model: gpt4o, Tools: true, Vision: true, StructuredOutput: true, Tiers: []string{"pro"}},
+//	    {Model: localLlama, MaxPromptTokens: 4000},
+//	})
+//
+//	response, err := r.Invoke(router.WithTier(ctx, "pro"), messages)
+//	decision := response.Metadata[router.MetadataRoute].(router.Decision)
+//
+func New(routes []Route, opts ...Option) (*Router, error) {
+	if len(routes) == 0 {
+		return nil, fmt.Errorf("at least one route is required")
+	}
+
+	r := &Router{
+		prices:     cost.DefaultPriceTable(),
+		costWeight: DefaultCostWeight,
+		windowSize: DefaultWindowSize,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if r.costWeight < 0 || r.costWeight > 1 {
+		return nil, fmt.Errorf("cost weight must be between 0 and 1")
+	}
+
+	nodes := make([]*node.Node, len(routes))
+	seen := make(map[string]bool, len(routes))
+	for i, route := range routes {
+		if route.Model == nil {
+			return nil, fmt.Errorf("route %d: model is required", i)
+		}
+		name := route.name()
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate route name %q", name)
+		}
+		seen[name] = true
+
+		// 每个路由对应一个节点，由 AdaptiveBalancer 维护成功率和延迟窗口
+		nodes[i] = &node.Node{ID: name, Name: name, Status: node.StatusOnline}
+	}
+
+	r.routes = routes
+	r.balancer = balancer.NewAdaptiveBalancer(nodes, r.windowSize)
+	return r, nil
+}
+
+// Stats 返回各路由的调用统计（节点 ID 为路由名称）。
+func (r *Router) Stats() *balancer.Stats {
+	return r.balancer.GetStats()
+}
+
+// rank 筛选并排序候选路由。
+func (r *Router) rank(req Request) ([]Route, Decision) {
+	decision := Decision{Request: req}
+
+	candidates := make([]Route, 0, len(r.routes))
+	prices := make([]float64, 0, len(r.routes))
+	maxPrice := 0.0
+	for _, route := range r.routes {
+		if reason := route.reject(req); reason != "" {
+			if decision.Skipped == nil {
+				decision.Skipped = make(map[string]string)
+			}
+			decision.Skipped[route.name()] = reason
+			continue
+		}
+
+		price := 0.0
+		if r.prices != nil {
+			if p, ok := r.prices.Lookup(route.Model.GetProvider(), route.Model.GetModelName()); ok {
+				price = p.Input + p.Output
+			}
+		}
+		if price > maxPrice {
+			maxPrice = price
+		}
+		candidates = append(candidates, route)
+		prices = append(prices, price)
+	}
+
+	decision.Candidates = make([]Candidate, len(candidates))
+	for i, route := range candidates {
+		health := r.balancer.GetScore(route.name())
+		score := health
+		if maxPrice > 0 {
+			score = health * (1 - r.costWeight*prices[i]/maxPrice)
+		}
+		decision.Candidates[i] = Candidate{
+			Route:       route.name(),
+			Score:       score,
+			HealthScore: health,
+			Price:       prices[i],
+		}
+	}
+
+	order := make([]int, len(candidates))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return decision.Candidates[order[a]].Score > decision.Candidates[order[b]].Score
+	})
+
+	ranked := make([]Route, len(order))
+	sorted := make([]Candidate, len(order))
+	for i, idx := range order {
+		ranked[i] = candidates[idx]
+		sorted[i] = decision.Candidates[idx]
+	}
+	decision.Candidates = sorted
+
+	return ranked, decision
+}
+
+// route 分析请求并返回排序后的候选路由。
+func (r *Router) route(ctx context.Context, messages []types.Message, opts []runnable.Option) ([]Route, Decision, error) {
+	if err := chat.ValidateMessages(messages); err != nil {
+		return nil, Decision{}, err
+	}
+
+	routes, decision := r.rank(newRequest(ctx, messages, r.tools, r.structured, opts))
+	if len(routes) == 0 {
+		return nil, decision, fmt.Errorf("%w: %s", ErrNoRoute, formatSkipped(decision.Skipped))
+	}
+	return routes, decision, nil
+}
+
+// Invoke 实现 ChatModel 接口
+func (r *Router) Invoke(ctx context.Context, messages []types.Message, opts ...runnable.Option) (types.Message, error) {
+	routes, decision, err := r.route(ctx, messages, opts)
+	if err != nil {
+		return types.Message{}, err
+	}
+
+	var lastErr error
+	for _, route := range routes {
+		name := route.name()
+		start := time.Now()
+		result, err := route.Model.Invoke(ctx, messages, opts...)
+		r.record(name, err, time.Since(start))
+		if err == nil {
+			decision.Route = name
+			return withDecision(result, decision), nil
+		}
+
+		// 调用方取消时不再回退
+		if ctx.Err() != nil {
+			return types.Message{}, err
+		}
+		decision.Failures = append(decision.Failures, Failure{Route: name, Error: err.Error()})
+		lastErr = err
+	}
+
+	return types.Message{}, fmt.Errorf("%w: %w", ErrAllRoutesFailed, lastErr)
+}
+
+// Batch 实现 ChatModel 接口
+//
+// 每组消息单独路由。
+//
+func (r *Router) Batch(ctx context.Context, inputs [][]types.Message, opts ...runnable.Option) ([]types.Message, error) {
+	results := make([]types.Message, len(inputs))
+	errs := make([]error, len(inputs))
+
+	done := make(chan struct{}, len(inputs))
+	for i, input := range inputs {
+		go func(idx int, messages []types.Message) {
+			results[idx], errs[idx] = r.Invoke(ctx, messages, opts...)
+			done <- struct{}{}
+		}(i, input)
+	}
+	for range inputs {
+		<-done
+	}
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("batch failed at index %d: %w", i, err)
+		}
+	}
+	return results, nil
+}
+
+// Stream 实现 ChatModel 接口
+//
+// 在收到第一个数据事件（EventStream 或 EventEnd）前失败时回退到下一个候选，
+// 此前的 EventStart 事件不会重复发送。EventEnd 中的完整消息带有路由决策。
+//
+func (r *Router) Stream(ctx context.Context, messages []types.Message, opts ...runnable.Option) (<-chan runnable.StreamEvent[types.Message], error) {
+	routes, decision, err := r.route(ctx, messages, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan runnable.StreamEvent[types.Message])
+
+	go func() {
+		defer close(out)
+
+		send := func(event runnable.StreamEvent[types.Message]) bool {
+			select {
+			case out <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		var lastErr error
+		for _, route := range routes {
+			name := route.name()
+			start := time.Now()
+
+			stream, err := route.Model.Stream(ctx, messages, opts...)
+			if err == nil {
+				var pending []runnable.StreamEvent[types.Message]
+				pending, err = firstEvent(stream)
+				if err == nil {
+					decision.Route = name
+					for _, event := range pending {
+						if !r.forward(event, name, start, decision, send) {
+							drain(stream)
+							return
+						}
+					}
+					for event := range stream {
+						if !r.forward(event, name, start, decision, send) {
+							drain(stream)
+							return
+						}
+					}
+					return
+				}
+			}
+
+			r.record(name, err, time.Since(start))
+			if ctx.Err() != nil {
+				send(runnable.StreamEvent[types.Message]{Type: runnable.EventError, Error: err})
+				return
+			}
+			decision.Failures = append(decision.Failures, Failure{Route: name, Error: err.Error()})
+			lastErr = err
+		}
+
+		send(runnable.StreamEvent[types.Message]{
+			Type:  runnable.EventError,
+			Name:  r.GetName(),
+			Error: fmt.Errorf("%w: %w", ErrAllRoutesFailed, lastErr),
+		})
+	}()
+
+	return out, nil
+}
+
+// forward 发送路由选定后的事件，在结束或出错时记录调用结果。
+func (r *Router) forward(event runnable.StreamEvent[types.Message], name string, start time.Time, decision Decision, send func(runnable.StreamEvent[types.Message]) bool) bool {
+	switch event.Type {
+	case runnable.EventEnd:
+		r.record(name, nil, time.Since(start))
+		event.Data = withDecision(event.Data, decision)
+	case runnable.EventError:
+		r.record(name, event.Error, time.Since(start))
+	}
+	return send(event)
+}
+
+// record 记录调用结果。
+//
+// 请求本身导致的错误（超出上下文长度、内容拦截、无效请求，见 chaterrors.IsProviderFault）
+// 不说明路由不健康，不计入健康得分。
+func (r *Router) record(name string, err error, elapsed time.Duration) {
+	if err != nil && !chaterrors.IsProviderFault(err) {
+		return
+	}
+	r.balancer.RecordResult(name, err == nil, elapsed)
+}
+
+// firstEvent 读取到第一个数据事件为止，返回已读取的事件。
+//
+// 在此之前收到错误事件或流提前结束时返回错误，剩余事件在后台丢弃。
+func firstEvent(stream <-chan runnable.StreamEvent[types.Message]) ([]runnable.StreamEvent[types.Message], error) {
+	var pending []runnable.StreamEvent[types.Message]
+	for event := range stream {
+		switch event.Type {
+		case runnable.EventError:
+			go drain(stream)
+			return nil, event.Error
+		case runnable.EventStream, runnable.EventEnd:
+			return append(pending, event), nil
+		default:
+			pending = append(pending, event)
+		}
+	}
+	return nil, fmt.Errorf("stream closed without output")
+}
+
+// drain 丢弃流中剩余的事件，使上游 goroutine 可以退出。
+func drain(stream <-chan runnable.StreamEvent[types.Message]) {
+	for range stream {
+	}
+}
+
+// withDecision 将路由决策写入响应的元数据。
+func withDecision(message types.Message, decision Decision) types.Message {
+	metadata := make(map[string]any, len(message.Metadata)+1)
+	for k, v := range message.Metadata {
+		metadata[k] = v
+	}
+	metadata[MetadataRoute] = decision
+	message.Metadata = metadata
+	return message
+}
+
+// formatSkipped 按路由名称格式化跳过原因。
+func formatSkipped(skipped map[string]string) string {
+	names := make([]string, 0, len(skipped))
+	for name := range skipped {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	reasons := make([]string, len(names))
+	for i, name := range names {
+		reasons[i] = name + ": " + skipped[name]
+	}
+	return strings.Join(reasons, "; ")
+}
+
+// with 对每个路由的模型应用 transform，创建共享调用统计的路由器。
+func (r *Router) with(transform func(chat.ChatModel) chat.ChatModel) *Router {
+	routes := make([]Route, len(r.routes))
+	for i, route := range r.routes {
+		route.Name = route.name()
+		route.Model = transform(route.Model)
+		routes[i] = route
+	}
+
+	clone := *r
+	clone.routes = routes
+	return &clone
+}
+
+// BindTools 实现 ChatModel 接口
+//
+// 工具绑定到所有路由，之后只路由到支持工具调用的模型。
+//
+func (r *Router) BindTools(tools []types.Tool) chat.ChatModel {
+	clone := r.with(func(model chat.ChatModel) chat.ChatModel {
+		return model.BindTools(tools)
+	})
+	clone.tools = len(tools) > 0
+	return clone
+}
+
+// WithStructuredOutput 实现 ChatModel 接口
+//
+// 之后只路由到支持结构化输出的模型。
+//
+func (r *Router) WithStructuredOutput(schema types.Schema) chat.ChatModel {
+	clone := r.with(func(model chat.ChatModel) chat.ChatModel {
+		return model.WithStructuredOutput(schema)
+	})
+	clone.structured = true
+	return clone
+}
+
+// GetModelName 实现 ChatModel 接口，返回以逗号分隔的路由名称。
+func (r *Router) GetModelName() string {
+	names := make([]string, len(r.routes))
+	for i, route := range r.routes {
+		names[i] = route.name()
+	}
+	return strings.Join(names, ",")
+}
+
+// GetProvider 实现 ChatModel 接口
+func (r *Router) GetProvider() string {
+	return "router"
+}
+
+// GetName 实现 Runnable 接口
+func (r *Router) GetName() string {
+	return "router"
+}
+
+// WithConfig 实现 Runnable 接口
+func (r *Router) WithConfig(config *types.Config) runnable.Runnable[[]types.Message, types.Message] {
+	return r.with(func(model chat.ChatModel) chat.ChatModel {
+		if configured, ok := model.WithConfig(config).(chat.ChatModel); ok {
+			return configured
+		}
+		return model
+	})
+}
+
+// WithRetry 实现 Runnable 接口
+func (r *Router) WithRetry(policy types.RetryPolicy) runnable.Runnable[[]types.Message, types.Message] {
+	return runnable.NewRetryRunnable[[]types.Message, types.Message](r, policy)
+}
+
+// WithFallbacks 实现 Runnable 接口
+func (r *Router) WithFallbacks(fallbacks ...runnable.Runnable[[]types.Message, types.Message]) runnable.Runnable[[]types.Message, types.Message] {
+	return runnable.NewFallbackRunnable[[]types.Message, types.Message](r, fallbacks)
+}
@@ -0,0 +1,288 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/zhucl121/langchain-go/core/chat"
+	chaterrors "github.com/zhucl121/langchain-go/core/chat/errors"
+	"github.com/zhucl121/langchain-go/core/runnable"
+	"github.com/zhucl121/langchain-go/pkg/cost"
+	"github.com/zhucl121/langchain-go/pkg/enterprise/tenant"
+	"github.com/zhucl121/langchain-go/pkg/types"
+)
+
+// fakeModel 返回固定内容或固定错误的模型
+type fakeModel struct {
+	name string
+	err  error
+
+	mu    sync.Mutex
+	calls int
+}
+
+func (m *fakeModel) Invoke(ctx context.Context, messages []types.Message, opts ...runnable.Option) (types.Message, error) {
+	m.mu.Lock()
+	m.calls++
+	m.mu.Unlock()
+	if m.err != nil {
+		return types.Message{}, m.err
+	}
+	return types.Message{Role: types.RoleAssistant, Content: m.name}, nil
+}
+
+func (m *fakeModel) Batch(ctx context.Context, inputs [][]types.Message, opts ...runnable.Option) ([]types.Message, error) {
+	results := make([]types.Message, len(inputs))
+	for i, input := range inputs {
+		results[i], _ = m.Invoke(ctx, input, opts...)
+	}
+	return results, nil
+}
+
+func (m *fakeModel) Stream(ctx context.Context, messages []types.Message, opts ...runnable.Option) (<-chan runnable.StreamEvent[types.Message], error) {
+	out := make(chan runnable.StreamEvent[types.Message], 3)
+	result, err := m.Invoke(ctx, messages, opts...)
+	out <- runnable.StreamEvent[types.Message]{Type: runnable.EventStart}
+	if err != nil {
+		out <- runnable.StreamEvent[types.Message]{Type: runnable.EventError, Error: err}
+	} else {
+		out <- runnable.StreamEvent[types.Message]{Type: runnable.EventStream, Data: types.Message{Content: result.Content}}
+		out <- runnable.StreamEvent[types.Message]{Type: runnable.EventEnd, Data: result}
+	}
+	close(out)
+	return out, nil
+}
+
+func (m *fakeModel) BindTools(tools []types.Tool) chat.ChatModel             { return m }
+func (m *fakeModel) WithStructuredOutput(schema types.Schema) chat.ChatModel { return m }
+func (m *fakeModel) GetModelName() string                                    { return m.name }
+func (m *fakeModel) GetProvider() string                                     { return "openai" }
+func (m *fakeModel) GetName() string                                         { return m.name }
+
+func (m *fakeModel) WithConfig(config *types.Config) runnable.Runnable[[]types.Message, types.Message] {
+	return m
+}
+
+func (m *fakeModel) WithRetry(policy types.RetryPolicy) runnable.Runnable[[]types.Message, types.Message] {
+	return runnable.NewRetryRunnable[[]types.Message, types.Message](m, policy)
+}
+
+func (m *fakeModel) WithFallbacks(fallbacks ...runnable.Runnable[[]types.Message, types.Message]) runnable.Runnable[[]types.Message, types.Message] {
+	return runnable.NewFallbackRunnable[[]types.Message, types.Message](m, fallbacks)
+}
+
+func testPrices() *cost.PriceTable {
+	return cost.NewPriceTable("test").
+		Set("openai", "big", cost.Price{Input: 10, Output: 30}).
+		Set("openai", "small", cost.Price{Input: 1, Output: 3})
+}
+
+var messages = []types.Message{types.NewUserMessage("hi")}
+
+func decisionOf(t *testing.T, message types.Message) Decision {
+	t.Helper()
+	decision, ok := message.Metadata[MetadataRoute].(Decision)
+	if !ok {
+		t.Fatalf("missing route decision: %v", message.Metadata)
+	}
+	return decision
+}
+
+func TestRouter_PrefersCheaperModel(t *testing.T) {
+	big := &fakeModel{name: "big"}
+	small := &fakeModel{name: "small"}
+	r, err := New([]Route{{Model: big}, {Model: small}}, WithPriceTable(testPrices()))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	result, err := r.Invoke(context.Background(), messages)
+	if err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+	if result.Content != "small" {
+		t.Errorf("routed to %q, want small", result.Content)
+	}
+
+	decision := decisionOf(t, result)
+	if decision.Route != "small" || len(decision.Candidates) != 2 || decision.Candidates[0].Route != "small" {
+		t.Errorf("decision = %+v", decision)
+	}
+	if decision.Candidates[1].Price != 40 {
+		t.Errorf("big price = %v", decision.Candidates[1].Price)
+	}
+}
+
+func TestRouter_Rules(t *testing.T) {
+	big := &fakeModel{name: "big"}
+	small := &fakeModel{name: "small"}
+	r, err := New([]Route{
+		{Model: big, Tools: true, Vision: true, StructuredOutput: true},
+		{Model: small, MaxPromptTokens: 10, Tiers: []string{"free", "pro"}},
+	}, WithPriceTable(testPrices()))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	image := types.NewMessageWithParts(types.RoleUser,
+		types.NewTextContent("What is this?"),
+		types.NewImageContent("https://example.com/cat.png", types.ImageFormatPNG),
+	)
+	proTenant := &tenant.Tenant{ID: "t1", Metadata: map[string]any{"tier": "pro"}}
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		model    chat.ChatModel
+		messages []types.Message
+		opts     []runnable.Option
+		want     string
+		skipped  string
+	}{
+		{name: "default", ctx: WithTier(context.Background(), "free"), model: r, messages: messages, want: "small"},
+		{name: "tenant tier", ctx: tenant.WithTenant(context.Background(), proTenant), model: r, messages: messages, want: "small"},
+		{name: "unknown tier", ctx: context.Background(), model: r, messages: messages, want: "big", skipped: "tier"},
+		{name: "long prompt", ctx: WithTier(context.Background(), "free"), model: r, messages: []types.Message{types.NewUserMessage(strings.Repeat("a", 100))}, want: "big", skipped: "prompt too long"},
+		{name: "images", ctx: WithTier(context.Background(), "free"), model: r, messages: []types.Message{image}, want: "big", skipped: "images"},
+		{name: "tools", ctx: WithTier(context.Background(), "free"), model: r.BindTools([]types.Tool{{Name: "search"}}), messages: messages, want: "big", skipped: "tools"},
+		{name: "structured output", ctx: WithTier(context.Background(), "free"), model: r, messages: messages, opts: []runnable.Option{runnable.WithResponseFormat(types.NewJSONObjectFormat())}, want: "big", skipped: "structured output"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.model.Invoke(tt.ctx, tt.messages, tt.opts...)
+			if err != nil {
+				t.Fatalf("Invoke failed: %v", err)
+			}
+			if result.Content != tt.want {
+				t.Errorf("routed to %q, want %q", result.Content, tt.want)
+			}
+			if tt.skipped != "" && !strings.Contains(decisionOf(t, result).Skipped["small"], tt.skipped) {
+				t.Errorf("skipped = %v, want reason containing %q", decisionOf(t, result).Skipped, tt.skipped)
+			}
+		})
+	}
+
+	_, err = r.BindTools([]types.Tool{{Name: "search"}}).Invoke(context.Background(), []types.Message{image},
+		runnable.WithResponseFormat(types.NewJSONObjectFormat()))
+	if err != nil {
+		t.Fatalf("big route should accept all capabilities: %v", err)
+	}
+
+	onlySmall, _ := New([]Route{{Model: small, Tiers: []string{"pro"}}})
+	if _, err := onlySmall.Invoke(context.Background(), messages); !errors.Is(err, ErrNoRoute) {
+		t.Errorf("expected ErrNoRoute, got %v", err)
+	}
+}
+
+func TestRouter_Fallback(t *testing.T) {
+	broken := &fakeModel{name: "small", err: errors.New("503 service unavailable")}
+	big := &fakeModel{name: "big"}
+	r, err := New([]Route{{Model: big}, {Model: broken}}, WithPriceTable(testPrices()))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	result, err := r.Invoke(context.Background(), messages)
+	if err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+	decision := decisionOf(t, result)
+	if decision.Route != "big" || len(decision.Failures) != 1 || decision.Failures[0].Route != "small" {
+		t.Errorf("decision = %+v", decision)
+	}
+
+	// 失败降低了健康得分，下一次直接选择 big
+	result, _ = r.Invoke(context.Background(), messages)
+	if decision := decisionOf(t, result); decision.Candidates[0].Route != "big" || len(decision.Failures) != 0 {
+		t.Errorf("failing route should rank lower: %+v", decision)
+	}
+	if broken.calls != 1 {
+		t.Errorf("broken route called %d times", broken.calls)
+	}
+	if stats := r.Stats().NodeStats["small"]; stats.FailedRequests != 1 {
+		t.Errorf("small stats = %+v", stats)
+	}
+
+	allBroken, _ := New([]Route{{Model: broken}, {Name: "broken-2", Model: broken}})
+	if _, err := allBroken.Invoke(context.Background(), messages); !errors.Is(err, ErrAllRoutesFailed) {
+		t.Errorf("expected ErrAllRoutesFailed, got %v", err)
+	}
+}
+
+func TestRouter_StreamFallback(t *testing.T) {
+	broken := &fakeModel{name: "small", err: errors.New("connection refused")}
+	big := &fakeModel{name: "big"}
+	r, err := New([]Route{{Model: big}, {Model: broken}}, WithPriceTable(testPrices()))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	stream, err := r.Stream(context.Background(), messages)
+	if err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+
+	var starts int
+	var final types.Message
+	for event := range stream {
+		switch event.Type {
+		case runnable.EventError:
+			t.Fatalf("unexpected error: %v", event.Error)
+		case runnable.EventStart:
+			starts++
+		case runnable.EventEnd:
+			final = event.Data
+		}
+	}
+
+	if starts != 1 {
+		t.Errorf("got %d start events, want 1", starts)
+	}
+	if final.Content != "big" {
+		t.Errorf("final content = %q", final.Content)
+	}
+	if decision := decisionOf(t, final); decision.Route != "big" || len(decision.Failures) != 1 {
+		t.Errorf("decision = %+v", decision)
+	}
+}
+
+func TestNew_Invalid(t *testing.T) {
+	model := &fakeModel{name: "small"}
+
+	if _, err := New(nil); err == nil {
+		t.Error("expected error for empty routes")
+	}
+	if _, err := New([]Route{{Model: model}, {Model: model}}); err == nil {
+		t.Error("expected error for duplicate route names")
+	}
+	if _, err := New([]Route{{}}); err == nil {
+		t.Error("expected error for missing model")
+	}
+	if _, err := New([]Route{{Model: model}}, WithCostWeight(2)); err == nil {
+		t.Error("expected error for invalid cost weight")
+	}
+}
+
+func TestRouter_RequestErrorsDoNotAffectHealth(t *testing.T) {
+	small := &fakeModel{name: "small", err: chaterrors.New("openai", 400, "context_length_exceeded", "too long")}
+	big := &fakeModel{name: "big"}
+	r, err := New([]Route{{Model: big}, {Model: small}}, WithPriceTable(testPrices()))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	result, err := r.Invoke(context.Background(), messages)
+	if err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+	if decision := decisionOf(t, result); decision.Route != "big" || len(decision.Failures) != 1 {
+		t.Errorf("decision = %+v", decision)
+	}
+	if stats := r.Stats().NodeStats["small"]; stats.FailedRequests != 0 {
+		t.Errorf("request error counted against route: %+v", stats)
+	}
+}
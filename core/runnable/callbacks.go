@@ -0,0 +1,44 @@
+package runnable
+
+import (
+	"context"
+
+	"github.com/zhucl121/langchain-go/core/callbacks"
+)
+
+// CallbackContext 把选项中的回调处理器、标签、元数据和运行名称加入上下文。
+//
+// 包括 Options.Config 中的对应字段。只实现了 types.CallbackHandler 的处理器
+// 通过 callbacks.FromCallbackHandlers 适配。组合 Runnable 传给子 Runnable 的选项
+// 带有继承标记，子 Runnable 不会重复加入（它们已经在上下文中）。
+//
+// 参数：
+//   - ctx: 上下文
+//
+// 返回：
+//   - context.Context: 新的上下文
+//
+func (o *Options) CallbackContext(ctx context.Context) context.Context {
+	if o == nil || o.inherited {
+		return ctx
+	}
+
+	ctx = callbacks.WithConfig(ctx, o.Config)
+	ctx = callbacks.WithHandlers(ctx, callbacks.FromCallbackHandlers(o.Callbacks...)...)
+	ctx = callbacks.WithTags(ctx, o.Tags...)
+	ctx = callbacks.WithMetadata(ctx, o.Metadata)
+	if o.RunName != "" {
+		ctx = callbacks.WithRunName(ctx, o.RunName)
+	}
+	return ctx
+}
+
+// inherit 返回传给子 Runnable 的选项。
+//
+// 在 opts 之后追加继承标记，子 Runnable 沿用其余选项（如生成参数），
+// 但不再把回调相关的选项加入上下文。
+func inherit(opts []Option) []Option {
+	inherited := make([]Option, 0, len(opts)+1)
+	inherited = append(inherited, opts...)
+	return append(inherited, func(o *Options) { o.inherited = true })
+}
@@ -0,0 +1,89 @@
+package runnable
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zhucl121/langchain-go/core/callbacks"
+)
+
+// chainRecorder 记录链的开始和结束事件
+type chainRecorder struct {
+	callbacks.BaseHandler
+
+	mu     sync.Mutex
+	starts []callbacks.RunInfo
+	ends   []*callbacks.ChainEndEvent
+}
+
+func (r *chainRecorder) OnChainStart(ctx context.Context, e *callbacks.ChainStartEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.starts = append(r.starts, e.RunInfo)
+}
+
+func (r *chainRecorder) OnChainEnd(ctx context.Context, e *callbacks.ChainEndEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ends = append(r.ends, e)
+}
+
+func TestCallbacks_Sequence(t *testing.T) {
+	rec := &chainRecorder{}
+	double := LambdaWithName("double", func(ctx context.Context, x int) (int, error) { return x * 2, nil })
+	inc := LambdaWithName("inc", func(ctx context.Context, x int) (int, error) { return x + 1, nil })
+
+	result, err := NewSequence[int, int, int](double, inc).Invoke(context.Background(), 5,
+		WithCallbacks(rec), WithRunName("pipeline"), WithTags("test"))
+	require.NoError(t, err)
+	assert.Equal(t, 11, result)
+
+	// 处理器只加入一次：序列 + 两个 Lambda
+	require.Len(t, rec.starts, 3)
+	require.Len(t, rec.ends, 3)
+
+	root := rec.starts[0]
+	assert.Equal(t, "pipeline", root.Name)
+	assert.Empty(t, root.ParentRunID)
+	for i, name := range []string{"double", "inc"} {
+		child := rec.starts[i+1]
+		assert.Equal(t, name, child.Name)
+		assert.Equal(t, root.RunID, child.ParentRunID)
+		assert.Equal(t, []string{"test"}, child.Tags)
+	}
+	assert.Equal(t, 11, rec.ends[2].Outputs)
+}
+
+func TestCallbacks_LambdaError(t *testing.T) {
+	rec := &chainRecorder{}
+	ctx := callbacks.WithHandlers(context.Background(), rec)
+	failing := Lambda(func(ctx context.Context, x int) (int, error) { return 0, errors.New("failed") })
+
+	_, err := failing.Invoke(ctx, 1)
+	require.Error(t, err)
+	require.Len(t, rec.ends, 1)
+	assert.EqualError(t, rec.ends[0].Err, "failed")
+}
+
+func TestCallbacks_Parallel(t *testing.T) {
+	rec := &chainRecorder{}
+	ctx := callbacks.WithHandlers(context.Background(), rec)
+	parallel := NewParallel(map[string]Runnable[int, any]{
+		"a": LambdaWithName("a", func(ctx context.Context, x int) (any, error) { return x, nil }),
+		"b": LambdaWithName("b", func(ctx context.Context, x int) (any, error) { return x * 2, nil }),
+	})
+
+	_, err := parallel.Invoke(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, rec.starts, 3)
+
+	root := rec.starts[0]
+	for _, child := range rec.starts[1:] {
+		assert.Equal(t, root.RunID, child.ParentRunID)
+	}
+}
@@ -9,6 +9,7 @@
 //   - 链式组合（Pipe）
 //   - 并行执行（Batch 自动并行）
 //   - 流式输出（Stream）
+//   - 嵌套流式转发（ContextWithStreamWriter / ForwardStream）
 //
 // 使用示例：
 //
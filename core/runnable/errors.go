@@ -0,0 +1,189 @@
+package runnable
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrorKind 是错误在重试和降级时的分类。
+type ErrorKind string
+
+const (
+	// ErrorKindRateLimit 限流（HTTP 429），等待后重试，服务端给出 Retry-After 时按其等待
+	ErrorKindRateLimit ErrorKind = "rate_limit"
+
+	// ErrorKindServer 服务端错误（HTTP 5xx），可以重试
+	ErrorKindServer ErrorKind = "server"
+
+	// ErrorKindTimeout 超时，可以重试
+	ErrorKindTimeout ErrorKind = "timeout"
+
+	// ErrorKindContextLength 超出模型上下文长度，重试无效，但可以降级到上下文更大的模型
+	ErrorKindContextLength ErrorKind = "context_length"
+
+	// ErrorKindPermanent 不可重试的错误（鉴权失败、无效请求等其他 4xx）
+	ErrorKindPermanent ErrorKind = "permanent"
+
+	// ErrorKindCanceled 调用方取消，既不重试也不降级
+	ErrorKindCanceled ErrorKind = "canceled"
+
+	// ErrorKindUnknown 无法分类的错误，按可重试处理
+	ErrorKindUnknown ErrorKind = "unknown"
+)
+
+// Retryable 返回该类错误是否值得重试。
+func (k ErrorKind) Retryable() bool {
+	switch k {
+	case ErrorKindRateLimit, ErrorKindServer, ErrorKindTimeout, ErrorKindUnknown:
+		return true
+	default:
+		return false
+	}
+}
+
+// Fallbackable 返回该类错误是否应该降级到备用 Runnable。
+func (k ErrorKind) Fallbackable() bool {
+	return k != ErrorKindCanceled
+}
+
+// ClassifiedError 由能够自行分类的错误实现。
+//
+// 提供商的类型化错误实现此接口后，ClassifyError 直接使用其分类，
+// 不再根据错误信息推断。
+type ClassifiedError interface {
+	error
+	ErrorKind() ErrorKind
+}
+
+// RetryAfterError 由带有服务端建议重试间隔的错误实现（如 HTTP 429 的 Retry-After）。
+type RetryAfterError interface {
+	error
+	RetryAfter() time.Duration
+}
+
+var (
+	// httpStatusPattern 匹配提供商错误信息中的 HTTP 状态码，如 "HTTP 429"、"status code: 503"
+	httpStatusPattern = regexp.MustCompile(`(?i)(?:HTTP|status(?: code)?:?)\s*(\d{3})\b`)
+
+	// contextLengthPhrases 是各提供商表示超出上下文长度的错误信息
+	contextLengthPhrases = []string{
+		"context_length_exceeded",
+		"maximum context length",
+		"context window",
+		"prompt is too long",
+		"too many tokens",
+		"input is too long",
+	}
+)
+
+// ClassifyError 对错误进行分类。
+//
+// 分类顺序：
+//   - context.Canceled 为 ErrorKindCanceled，context.DeadlineExceeded 为 ErrorKindTimeout
+//   - 实现了 ClassifiedError 的错误使用其自身分类
+//   - 超时的 net.Error 为 ErrorKindTimeout
+//   - 根据错误信息中的 HTTP 状态码和关键字推断
+//
+// 参数：
+//   - err: 错误
+//
+// 返回：
+//   - ErrorKind: 错误分类，err 为 nil 时为空字符串
+//
+func ClassifyError(err error) ErrorKind {
+	if err == nil {
+		return ""
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return ErrorKindCanceled
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrorKindTimeout
+	}
+
+	var classified ClassifiedError
+	if errors.As(err, &classified) {
+		return classified.ErrorKind()
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return ErrorKindTimeout
+	}
+
+	message := strings.ToLower(err.Error())
+	for _, phrase := range contextLengthPhrases {
+		if strings.Contains(message, phrase) {
+			return ErrorKindContextLength
+		}
+	}
+
+	if match := httpStatusPattern.FindStringSubmatch(message); match != nil {
+		status, _ := strconv.Atoi(match[1])
+		switch {
+		case status == 429:
+			return ErrorKindRateLimit
+		case status == 408:
+			return ErrorKindTimeout
+		case status >= 500:
+			return ErrorKindServer
+		case status >= 400:
+			return ErrorKindPermanent
+		}
+	}
+
+	switch {
+	case strings.Contains(message, "rate limit"), strings.Contains(message, "too many requests"):
+		return ErrorKindRateLimit
+	case strings.Contains(message, "timeout"), strings.Contains(message, "timed out"):
+		return ErrorKindTimeout
+	}
+
+	return ErrorKindUnknown
+}
+
+// RetryAfter 返回错误中服务端建议的重试间隔。
+//
+// 返回：
+//   - time.Duration: 重试间隔
+//   - bool: 错误是否带有重试间隔
+//
+func RetryAfter(err error) (time.Duration, bool) {
+	var retryAfter RetryAfterError
+	if errors.As(err, &retryAfter) && retryAfter.RetryAfter() > 0 {
+		return retryAfter.RetryAfter(), true
+	}
+	return 0, false
+}
+
+// StreamInterruptedError 表示流在输出部分数据后失败。
+//
+// 已发送给调用方的数据无法撤回，因此中途失败不会重试或降级，
+// 而是以此错误结束流。调用方可以通过 errors.As 判断并决定是否重新发起。
+type StreamInterruptedError struct {
+	// Name 是发生错误的 Runnable 名称
+	Name string
+
+	// Events 是失败前已发送的数据事件数
+	Events int
+
+	// Err 是原始错误
+	Err error
+}
+
+// Error 实现 error 接口。
+func (e *StreamInterruptedError) Error() string {
+	return fmt.Sprintf("stream %s interrupted after %d events: %v", e.Name, e.Events, e.Err)
+}
+
+// Unwrap 返回原始错误。
+func (e *StreamInterruptedError) Unwrap() error {
+	return e.Err
+}
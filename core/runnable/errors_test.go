@@ -0,0 +1,66 @@
+package runnable
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// rateLimitError 是带 Retry-After 的类型化限流错误
+type rateLimitError struct {
+	after time.Duration
+}
+
+func (e *rateLimitError) Error() string             { return "rate limited" }
+func (e *rateLimitError) ErrorKind() ErrorKind      { return ErrorKindRateLimit }
+func (e *rateLimitError) RetryAfter() time.Duration { return e.after }
+
+// timeoutError 是超时的 net.Error
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o deadline" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want ErrorKind
+	}{
+		{nil, ""},
+		{context.Canceled, ErrorKindCanceled},
+		{fmt.Errorf("call: %w", context.DeadlineExceeded), ErrorKindTimeout},
+		{fmt.Errorf("wrapped: %w", &rateLimitError{}), ErrorKindRateLimit},
+		{fmt.Errorf("send: %w", timeoutError{}), ErrorKindTimeout},
+		{errors.New("OpenAI API error (HTTP 429): Rate limit reached"), ErrorKindRateLimit},
+		{errors.New("gemini: API error (status 503): overloaded"), ErrorKindServer},
+		{errors.New("Anthropic API error (HTTP 401): invalid x-api-key"), ErrorKindPermanent},
+		{errors.New("OpenAI API error (HTTP 400): This model's maximum context length is 8192 tokens"), ErrorKindContextLength},
+		{errors.New("Anthropic API error (HTTP 400): prompt is too long: 210000 tokens > 200000 maximum"), ErrorKindContextLength},
+		{errors.New("HTTP 408: request timeout"), ErrorKindTimeout},
+		{errors.New("connection timed out"), ErrorKindTimeout},
+		{errors.New("something odd"), ErrorKindUnknown},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, ClassifyError(tt.err), "%v", tt.err)
+	}
+
+	assert.True(t, ErrorKindServer.Retryable())
+	assert.False(t, ErrorKindContextLength.Retryable())
+	assert.True(t, ErrorKindContextLength.Fallbackable())
+	assert.False(t, ErrorKindCanceled.Fallbackable())
+}
+
+func TestRetryAfter(t *testing.T) {
+	after, ok := RetryAfter(fmt.Errorf("call: %w", &rateLimitError{after: 2 * time.Second}))
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Second, after)
+
+	_, ok = RetryAfter(errors.New("HTTP 429"))
+	assert.False(t, ok)
+}
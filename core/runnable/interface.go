@@ -176,6 +176,36 @@ type Options struct {
 
 	// RunName 运行名称
 	RunName string
+
+	// Thinking 本次调用的扩展思考配置（仅聊天模型使用），为 nil 时使用模型配置
+	Thinking *types.ThinkingConfig
+
+	// 以下是本次调用的生成参数（仅聊天模型使用），未设置时使用模型配置。
+	// 提供商不支持的参数会被忽略（如 Anthropic 不支持 Seed 和 ResponseFormat）。
+
+	// Temperature 采样温度
+	Temperature *float64
+
+	// MaxTokens 最大生成 token 数，为 0 时使用模型配置
+	MaxTokens int
+
+	// Stop 停止序列
+	Stop []string
+
+	// Seed 随机种子
+	Seed *int
+
+	// ToolChoice 工具选择策略，为 nil 时由模型决定（auto）
+	ToolChoice *types.ToolChoice
+
+	// ParallelToolCalls 是否允许并行调用多个工具，为 nil 时使用提供商默认值
+	ParallelToolCalls *bool
+
+	// ResponseFormat 响应格式，设置后覆盖模型的结构化输出配置
+	ResponseFormat *types.ResponseFormat
+
+	// inherited 表示选项由组合 Runnable 传给子 Runnable，回调相关选项已在上下文中
+	inherited bool
 }
 
 // NewOptions 创建新的选项
@@ -232,6 +262,62 @@ func WithRunName(name string) Option {
 	}
 }
 
+// WithThinking 设置本次调用的扩展思考配置
+func WithThinking(config *types.ThinkingConfig) Option {
+	return func(o *Options) {
+		o.Thinking = config
+	}
+}
+
+// WithTemperature 设置本次调用的采样温度
+func WithTemperature(temperature float64) Option {
+	return func(o *Options) {
+		o.Temperature = &temperature
+	}
+}
+
+// WithMaxTokens 设置本次调用的最大生成 token 数
+func WithMaxTokens(maxTokens int) Option {
+	return func(o *Options) {
+		o.MaxTokens = maxTokens
+	}
+}
+
+// WithStop 设置本次调用的停止序列
+func WithStop(stop ...string) Option {
+	return func(o *Options) {
+		o.Stop = stop
+	}
+}
+
+// WithSeed 设置本次调用的随机种子
+func WithSeed(seed int) Option {
+	return func(o *Options) {
+		o.Seed = &seed
+	}
+}
+
+// WithToolChoice 设置本次调用的工具选择策略
+func WithToolChoice(choice types.ToolChoice) Option {
+	return func(o *Options) {
+		o.ToolChoice = &choice
+	}
+}
+
+// WithParallelToolCalls 设置本次调用是否允许并行调用多个工具
+func WithParallelToolCalls(enabled bool) Option {
+	return func(o *Options) {
+		o.ParallelToolCalls = &enabled
+	}
+}
+
+// WithResponseFormat 设置本次调用的响应格式
+func WithResponseFormat(format types.ResponseFormat) Option {
+	return func(o *Options) {
+		o.ResponseFormat = &format
+	}
+}
+
 // GetContext 从选项中获取上下文
 func (o *Options) GetContext() context.Context {
 	if o.Config != nil {
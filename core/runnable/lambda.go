@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"sync"
 
+	"github.com/zhucl121/langchain-go/core/callbacks"
 	"github.com/zhucl121/langchain-go/pkg/types"
 )
 
@@ -101,29 +102,13 @@ func (r *RunnableLambda[I, O]) Invoke(ctx context.Context, input I, opts ...Opti
 	default:
 	}
 
-	// 执行回调：OnStart
-	if options.Config != nil {
-		for _, cb := range options.Config.Callbacks {
-			if err := cb.OnStart(execCtx, input); err != nil {
-				var zero O
-				return zero, fmt.Errorf("callback OnStart failed: %w", err)
-			}
-		}
-	}
+	// 开始运行，向上下文中的回调处理器发送事件
+	execCtx = options.CallbackContext(execCtx)
+	execCtx, run := callbacks.StartChain(execCtx, r.name, input)
 
 	// 执行函数
 	result, err := r.fn(execCtx, input)
-
-	// 执行回调：OnEnd 或 OnError
-	if options.Config != nil {
-		for _, cb := range options.Config.Callbacks {
-			if err != nil {
-				cb.OnError(execCtx, err)
-			} else {
-				cb.OnEnd(execCtx, result)
-			}
-		}
-	}
+	run.EndChain(execCtx, result, err)
 
 	return result, err
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"sync"
 
+	"github.com/zhucl121/langchain-go/core/callbacks"
 	"github.com/zhucl121/langchain-go/pkg/types"
 )
 
@@ -71,6 +72,15 @@ func (p *RunnableParallel[I]) Invoke(ctx context.Context, input I, opts ...Optio
 		return make(map[string]any), nil
 	}
 
+	ctx = NewOptions(opts...).CallbackContext(ctx)
+	ctx, run := callbacks.StartChain(ctx, p.name, input)
+	results, err := p.invoke(ctx, input, inherit(opts))
+	run.EndChain(ctx, results, err)
+	return results, err
+}
+
+// invoke 并行执行所有 Runnable。
+func (p *RunnableParallel[I]) invoke(ctx context.Context, input I, opts []Option) (map[string]any, error) {
 	results := make(map[string]any)
 	errors := make(map[string]error)
 
@@ -2,6 +2,7 @@ package runnable
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -12,6 +13,17 @@ import (
 //
 // RetryRunnable 在执行失败时会根据重试策略自动重试。
 //
+// 是否重试：
+//   - 策略设置了 RetryableErrors 时，只重试与其中之一匹配（errors.Is）的错误
+//   - 否则按 ClassifyError 的分类，限流、服务端错误、超时和未知错误重试，
+//     超出上下文长度、鉴权失败等永久错误和取消直接返回
+//
+// 限流错误带有 Retry-After（RetryAfterError）时，等待时间不少于服务端建议的间隔
+// （设置了 MaxDelay 时不超过 MaxDelay）。
+//
+// 流式调用在收到第一个数据事件前失败时重试，之后的失败以
+// StreamInterruptedError 结束流。
+//
 // 类型参数：
 //   - I: 输入类型
 //   - O: 输出类型
@@ -72,21 +84,21 @@ func (r *RetryRunnable[I, O]) Invoke(ctx context.Context, input I, opts ...Optio
 
 		lastErr = err
 
+		// 不可重试的错误直接返回
+		if !r.shouldRetry(err) {
+			var zero O
+			return zero, err
+		}
+
 		// 最后一次尝试不需要等待
 		if attempt == r.policy.MaxRetries {
 			break
 		}
 
-		// 计算延迟
-		delay := r.policy.GetDelay(attempt)
-
 		// 等待后重试
-		select {
-		case <-ctx.Done():
+		if !sleep(ctx, r.delay(attempt, err)) {
 			var zero O
 			return zero, ctx.Err()
-		case <-time.After(delay):
-			// 继续重试
 		}
 	}
 
@@ -94,6 +106,31 @@ func (r *RetryRunnable[I, O]) Invoke(ctx context.Context, input I, opts ...Optio
 	return zero, fmt.Errorf("retry exhausted after %d attempts: %w", r.policy.MaxRetries+1, lastErr)
 }
 
+// shouldRetry 返回错误是否可以重试。
+func (r *RetryRunnable[I, O]) shouldRetry(err error) bool {
+	if len(r.policy.RetryableErrors) > 0 {
+		for _, target := range r.policy.RetryableErrors {
+			if errors.Is(err, target) {
+				return true
+			}
+		}
+		return false
+	}
+	return ClassifyError(err).Retryable()
+}
+
+// delay 计算第 attempt 次失败后的等待时间。
+func (r *RetryRunnable[I, O]) delay(attempt int, err error) time.Duration {
+	delay := r.policy.GetDelay(attempt)
+	if after, ok := RetryAfter(err); ok && after > delay {
+		delay = after
+		if r.policy.MaxDelay > 0 && delay > r.policy.MaxDelay {
+			delay = r.policy.MaxDelay
+		}
+	}
+	return delay
+}
+
 // Batch 实现 Runnable 接口。
 func (r *RetryRunnable[I, O]) Batch(ctx context.Context, inputs []I, opts ...Option) ([]O, error) {
 	results := make([]O, len(inputs))
@@ -108,22 +145,40 @@ func (r *RetryRunnable[I, O]) Batch(ctx context.Context, inputs []I, opts ...Opt
 }
 
 // Stream 实现 Runnable 接口。
+//
+// 在收到第一个数据事件前失败时按策略重试，失败尝试的事件不会发送给调用方；
+// 之后的失败以 StreamInterruptedError 结束流。
+//
 func (r *RetryRunnable[I, O]) Stream(ctx context.Context, input I, opts ...Option) (<-chan StreamEvent[O], error) {
 	out := make(chan StreamEvent[O], 10)
 
 	go func() {
 		defer close(out)
 
-		out <- StreamEvent[O]{Type: EventStart, Name: r.name}
-
-		result, err := r.Invoke(ctx, input, opts...)
-		if err != nil {
-			out <- StreamEvent[O]{Type: EventError, Name: r.name, Error: err}
-			return
+		var lastErr error
+		for attempt := 0; attempt <= r.policy.MaxRetries; attempt++ {
+			pending, rest, err := openStream(ctx, r.runnable, input, opts)
+			if err == nil {
+				relayStream(ctx, out, r.name, pending, rest)
+				return
+			}
+
+			lastErr = err
+			if !r.shouldRetry(err) {
+				sendStreamError(ctx, out, r.name, err)
+				return
+			}
+			if attempt == r.policy.MaxRetries {
+				break
+			}
+			if !sleep(ctx, r.delay(attempt, err)) {
+				sendStreamError(ctx, out, r.name, ctx.Err())
+				return
+			}
 		}
 
-		out <- StreamEvent[O]{Type: EventStream, Name: r.name, Data: result}
-		out <- StreamEvent[O]{Type: EventEnd, Name: r.name, Data: result}
+		sendStreamError(ctx, out, r.name,
+			fmt.Errorf("retry exhausted after %d attempts: %w", r.policy.MaxRetries+1, lastErr))
 	}()
 
 	return out, nil
@@ -206,6 +261,12 @@ func (f *FallbackRunnable[I, O]) Invoke(ctx context.Context, input I, opts ...Op
 
 	primaryErr := err
 
+	// 调用方取消时不降级
+	if !ClassifyError(err).Fallbackable() {
+		var zero O
+		return zero, err
+	}
+
 	// 依次尝试 fallbacks
 	for i, fallback := range f.fallbacks {
 		// 检查上下文取消
@@ -247,22 +308,43 @@ func (f *FallbackRunnable[I, O]) Batch(ctx context.Context, inputs []I, opts ...
 }
 
 // Stream 实现 Runnable 接口。
+//
+// 在收到第一个数据事件前失败时透明地切换到下一个 fallback，失败尝试的事件
+// 不会发送给调用方；之后的失败以 StreamInterruptedError 结束流。
+//
 func (f *FallbackRunnable[I, O]) Stream(ctx context.Context, input I, opts ...Option) (<-chan StreamEvent[O], error) {
 	out := make(chan StreamEvent[O], 10)
 
 	go func() {
 		defer close(out)
 
-		out <- StreamEvent[O]{Type: EventStart, Name: f.name}
-
-		result, err := f.Invoke(ctx, input, opts...)
-		if err != nil {
-			out <- StreamEvent[O]{Type: EventError, Name: f.name, Error: err}
-			return
+		candidates := append([]Runnable[I, O]{f.primary}, f.fallbacks...)
+
+		var primaryErr, lastErr error
+		for i, candidate := range candidates {
+			pending, rest, err := openStream(ctx, candidate, input, opts)
+			if err == nil {
+				relayStream(ctx, out, f.name, pending, rest)
+				return
+			}
+
+			if i == 0 {
+				primaryErr = err
+			}
+			lastErr = err
+
+			// 调用方取消时不降级
+			if !ClassifyError(err).Fallbackable() || ctx.Err() != nil {
+				sendStreamError(ctx, out, f.name, err)
+				return
+			}
 		}
 
-		out <- StreamEvent[O]{Type: EventStream, Name: f.name, Data: result}
-		out <- StreamEvent[O]{Type: EventEnd, Name: f.name, Data: result}
+		err := fmt.Errorf("primary failed: %w", primaryErr)
+		if len(f.fallbacks) > 0 {
+			err = fmt.Errorf("all fallbacks failed, primary error: %w, last fallback error: %v", primaryErr, lastErr)
+		}
+		sendStreamError(ctx, out, f.name, err)
 	}()
 
 	return out, nil
@@ -296,3 +378,105 @@ func (f *FallbackRunnable[I, O]) WithRetry(policy types.RetryPolicy) Runnable[I,
 func (f *FallbackRunnable[I, O]) WithFallbacks(fallbacks ...Runnable[I, O]) Runnable[I, O] {
 	return NewFallbackRunnable(f, fallbacks)
 }
+
+// openStream 启动流并读取到第一个数据事件（EventStream 或 EventEnd）为止。
+//
+// 返回已读取的事件和剩余的流。在第一个数据事件前收到错误事件或流提前结束时
+// 返回错误，剩余事件在后台丢弃。
+func openStream[I, O any](ctx context.Context, runnable Runnable[I, O], input I, opts []Option) ([]StreamEvent[O], <-chan StreamEvent[O], error) {
+	stream, err := runnable.Stream(ctx, input, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var pending []StreamEvent[O]
+	for event := range stream {
+		switch event.Type {
+		case EventError:
+			go drainStream(stream)
+			if event.Error == nil {
+				return nil, nil, fmt.Errorf("stream %s failed", runnable.GetName())
+			}
+			return nil, nil, event.Error
+		case EventStream, EventEnd:
+			return append(pending, event), stream, nil
+		default:
+			pending = append(pending, event)
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	return nil, nil, fmt.Errorf("stream %s closed without output", runnable.GetName())
+}
+
+// relayStream 转发已选定的流，中途的错误包装为 StreamInterruptedError。
+func relayStream[O any](ctx context.Context, out chan<- StreamEvent[O], name string, pending []StreamEvent[O], rest <-chan StreamEvent[O]) {
+	events := 0
+	forward := func(event StreamEvent[O]) bool {
+		switch event.Type {
+		case EventStream, EventEnd:
+			events++
+		case EventError:
+			var interrupted *StreamInterruptedError
+			if !errors.As(event.Error, &interrupted) {
+				if event.Name != "" {
+					name = event.Name
+				}
+				event.Error = &StreamInterruptedError{Name: name, Events: events, Err: event.Error}
+			}
+		}
+
+		select {
+		case out <- event:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	for _, event := range pending {
+		if !forward(event) {
+			go drainStream(rest)
+			return
+		}
+	}
+	for event := range rest {
+		if !forward(event) {
+			go drainStream(rest)
+			return
+		}
+	}
+}
+
+// sendStreamError 发送错误事件，上下文取消时放弃发送。
+func sendStreamError[O any](ctx context.Context, out chan<- StreamEvent[O], name string, err error) {
+	select {
+	case out <- StreamEvent[O]{Type: EventError, Name: name, Error: err}:
+	case <-ctx.Done():
+	}
+}
+
+// drainStream 丢弃流中剩余的事件，使上游 goroutine 可以退出。
+func drainStream[O any](stream <-chan StreamEvent[O]) {
+	for range stream {
+	}
+}
+
+// sleep 等待指定时间，上下文取消时返回 false。
+func sleep(ctx context.Context, delay time.Duration) bool {
+	if delay <= 0 {
+		return ctx.Err() == nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
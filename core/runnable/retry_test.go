@@ -3,6 +3,7 @@ package runnable
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -325,3 +326,191 @@ func BenchmarkFallbackRunnable(b *testing.B) {
 		_, _ = fb.Invoke(ctx, i)
 	}
 }
+
+func TestRetryRunnable_Classification(t *testing.T) {
+	ctx := context.Background()
+	policy := types.RetryPolicy{MaxRetries: 3, InitialDelay: time.Millisecond, MaxDelay: time.Second, Multiplier: 2}
+
+	t.Run("permanent errors are not retried", func(t *testing.T) {
+		var attempts atomic.Int32
+		lambda := Lambda(func(ctx context.Context, x int) (int, error) {
+			attempts.Add(1)
+			return 0, errors.New("OpenAI API error (HTTP 400): maximum context length exceeded")
+		})
+
+		_, err := NewRetryRunnable(lambda, policy).Invoke(ctx, 1)
+		require.Error(t, err)
+		assert.Equal(t, ErrorKindContextLength, ClassifyError(err))
+		assert.Equal(t, int32(1), attempts.Load())
+	})
+
+	t.Run("retryable errors from policy", func(t *testing.T) {
+		errFlaky := errors.New("flaky")
+		var attempts atomic.Int32
+		lambda := Lambda(func(ctx context.Context, x int) (int, error) {
+			if attempts.Add(1) == 1 {
+				return 0, fmt.Errorf("call: %w", errFlaky)
+			}
+			return 0, errors.New("HTTP 503")
+		})
+
+		p := policy
+		p.RetryableErrors = []error{errFlaky}
+		_, err := NewRetryRunnable(lambda, p).Invoke(ctx, 1)
+		require.Error(t, err)
+		assert.Equal(t, "HTTP 503", err.Error())
+		assert.Equal(t, int32(2), attempts.Load())
+	})
+
+	t.Run("honours retry-after", func(t *testing.T) {
+		var attempts atomic.Int32
+		lambda := Lambda(func(ctx context.Context, x int) (int, error) {
+			if attempts.Add(1) == 1 {
+				return 0, &rateLimitError{after: 60 * time.Millisecond}
+			}
+			return x, nil
+		})
+
+		start := time.Now()
+		result, err := NewRetryRunnable(lambda, policy).Invoke(ctx, 7)
+		require.NoError(t, err)
+		assert.Equal(t, 7, result)
+		assert.GreaterOrEqual(t, time.Since(start), 55*time.Millisecond)
+	})
+}
+
+// scriptedStream 创建按脚本输出的流式 Runnable。
+//
+// 每次调用取 scripts 中的下一个脚本：token 依次输出，以 "!" 开头的项表示以该错误结束。
+func scriptedStream(name string, attempts *atomic.Int32, scripts ...[]string) *StreamableRunnable[int, string] {
+	return NewStreamableRunnable(name,
+		func(ctx context.Context, x int) (string, error) { return "", errors.New("not used") },
+		func(ctx context.Context, x int) (<-chan types.StreamEvent, error) {
+			script := scripts[int(attempts.Add(1))-1]
+			out := make(chan types.StreamEvent, len(script)+2)
+			out <- types.StreamEvent{Type: types.StreamEventStart}
+			for _, item := range script {
+				if len(item) > 0 && item[0] == '!' {
+					out <- types.NewErrorEvent(errors.New(item[1:]))
+					close(out)
+					return out, nil
+				}
+				out <- types.NewTokenEvent(item)
+			}
+			out <- types.StreamEvent{Type: types.StreamEventEnd}
+			close(out)
+			return out, nil
+		},
+		func(event types.StreamEvent) StreamEvent[string] {
+			return ConvertToRunnableEvent(name, event, event.Token)
+		},
+	)
+}
+
+// collectStream 收集流中的数据、开始事件数和错误。
+func collectStream(t *testing.T, stream <-chan StreamEvent[string]) (tokens []string, starts int, err error) {
+	t.Helper()
+	for event := range stream {
+		switch event.Type {
+		case EventStart:
+			starts++
+		case EventStream:
+			tokens = append(tokens, event.Data)
+		case EventError:
+			err = event.Error
+		}
+	}
+	return tokens, starts, err
+}
+
+func TestRetryRunnable_Stream(t *testing.T) {
+	ctx := context.Background()
+	policy := types.RetryPolicy{MaxRetries: 2, InitialDelay: time.Millisecond, MaxDelay: time.Second, Multiplier: 2}
+
+	t.Run("retry before first token", func(t *testing.T) {
+		var attempts atomic.Int32
+		model := scriptedStream("model", &attempts, []string{"!HTTP 503"}, []string{"Hel", "lo"})
+
+		stream, err := model.WithRetry(policy).Stream(ctx, 1)
+		require.NoError(t, err)
+
+		tokens, starts, err := collectStream(t, stream)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Hel", "lo"}, tokens)
+		assert.Equal(t, 1, starts, "failed attempts must not leak events")
+		assert.Equal(t, int32(2), attempts.Load())
+	})
+
+	t.Run("mid-stream failure is typed", func(t *testing.T) {
+		var attempts atomic.Int32
+		model := scriptedStream("model", &attempts, []string{"Hel", "!HTTP 503"}, []string{"Hello"})
+
+		stream, err := model.WithRetry(policy).Stream(ctx, 1)
+		require.NoError(t, err)
+
+		tokens, _, err := collectStream(t, stream)
+		assert.Equal(t, []string{"Hel"}, tokens)
+
+		var interrupted *StreamInterruptedError
+		require.ErrorAs(t, err, &interrupted)
+		assert.Equal(t, 1, interrupted.Events)
+		assert.Equal(t, ErrorKindServer, ClassifyError(err))
+		assert.Equal(t, int32(1), attempts.Load())
+	})
+
+	t.Run("exhausted", func(t *testing.T) {
+		var attempts atomic.Int32
+		model := scriptedStream("model", &attempts, []string{"!HTTP 500"}, []string{"!HTTP 500"}, []string{"!HTTP 500"})
+
+		stream, err := model.WithRetry(policy).Stream(ctx, 1)
+		require.NoError(t, err)
+
+		_, _, err = collectStream(t, stream)
+		assert.ErrorContains(t, err, "retry exhausted after 3 attempts")
+	})
+}
+
+func TestFallbackRunnable_Stream(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("switch before first token", func(t *testing.T) {
+		var primaryAttempts, fallbackAttempts atomic.Int32
+		primary := scriptedStream("primary", &primaryAttempts, []string{"!HTTP 400: prompt is too long"})
+		fallback := scriptedStream("fallback", &fallbackAttempts, []string{"Hi"})
+
+		stream, err := primary.WithFallbacks(fallback).Stream(ctx, 1)
+		require.NoError(t, err)
+
+		tokens, starts, err := collectStream(t, stream)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Hi"}, tokens)
+		assert.Equal(t, 1, starts)
+	})
+
+	t.Run("mid-stream failure does not switch", func(t *testing.T) {
+		var primaryAttempts, fallbackAttempts atomic.Int32
+		primary := scriptedStream("primary", &primaryAttempts, []string{"Hi", "!connection reset"})
+		fallback := scriptedStream("fallback", &fallbackAttempts, []string{"Hello"})
+
+		stream, err := primary.WithFallbacks(fallback).Stream(ctx, 1)
+		require.NoError(t, err)
+
+		tokens, _, err := collectStream(t, stream)
+		assert.Equal(t, []string{"Hi"}, tokens)
+		var interrupted *StreamInterruptedError
+		assert.ErrorAs(t, err, &interrupted)
+		assert.Equal(t, int32(0), fallbackAttempts.Load())
+	})
+
+	t.Run("all failed", func(t *testing.T) {
+		var primaryAttempts, fallbackAttempts atomic.Int32
+		primary := scriptedStream("primary", &primaryAttempts, []string{"!HTTP 500"})
+		fallback := scriptedStream("fallback", &fallbackAttempts, []string{"!HTTP 502"})
+
+		stream, err := primary.WithFallbacks(fallback).Stream(ctx, 1)
+		require.NoError(t, err)
+
+		_, _, err = collectStream(t, stream)
+		assert.ErrorContains(t, err, "all fallbacks failed")
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/zhucl121/langchain-go/core/callbacks"
 	"github.com/zhucl121/langchain-go/pkg/types"
 )
 
@@ -78,6 +79,15 @@ func (s *RunnableSequence[I, M, O]) Invoke(ctx context.Context, input I, opts ..
 	default:
 	}
 
+	ctx = NewOptions(opts...).CallbackContext(ctx)
+	ctx, run := callbacks.StartChain(ctx, s.name, input)
+	result, err := s.invoke(ctx, input, inherit(opts))
+	run.EndChain(ctx, result, err)
+	return result, err
+}
+
+// invoke 依次执行序列中的 Runnable。
+func (s *RunnableSequence[I, M, O]) invoke(ctx context.Context, input I, opts []Option) (O, error) {
 	// 执行第一个 Runnable
 	mid, err := s.first.Invoke(ctx, input, opts...)
 	if err != nil {
@@ -151,9 +161,14 @@ func (s *RunnableSequence[I, M, O]) Batch(ctx context.Context, inputs []I, opts
 func (s *RunnableSequence[I, M, O]) Stream(ctx context.Context, input I, opts ...Option) (<-chan StreamEvent[O], error) {
 	out := make(chan StreamEvent[O], 10)
 
+	ctx = NewOptions(opts...).CallbackContext(ctx)
+	childOpts := inherit(opts)
+
 	go func() {
 		defer close(out)
 
+		ctx, run := callbacks.StartChain(ctx, s.name, input)
+
 		// 发送开始事件
 		out <- StreamEvent[O]{
 			Type: EventStart,
@@ -161,33 +176,49 @@ func (s *RunnableSequence[I, M, O]) Stream(ctx context.Context, input I, opts ..
 		}
 
 		// 执行第一个 Runnable
-		mid, err := s.first.Invoke(ctx, input, opts...)
+		mid, err := s.first.Invoke(ctx, input, childOpts...)
 		if err != nil {
+			err = fmt.Errorf("first runnable failed: %w", err)
+			run.EndChain(ctx, nil, err)
 			out <- StreamEvent[O]{
 				Type:  EventError,
 				Name:  s.name,
-				Error: fmt.Errorf("first runnable failed: %w", err),
+				Error: err,
 			}
 			return
 		}
 
 		// 流式执行第二个 Runnable
-		stream, err := s.second.Stream(ctx, mid, opts...)
+		stream, err := s.second.Stream(ctx, mid, childOpts...)
 		if err != nil {
+			err = fmt.Errorf("second runnable stream failed: %w", err)
+			run.EndChain(ctx, nil, err)
 			out <- StreamEvent[O]{
 				Type:  EventError,
 				Name:  s.name,
-				Error: fmt.Errorf("second runnable stream failed: %w", err),
+				Error: err,
 			}
 			return
 		}
 
 		// 转发第二个 Runnable 的流式事件
+		var (
+			output    any
+			streamErr error
+		)
 		for event := range stream {
+			switch event.Type {
+			case EventEnd:
+				output = event.Data
+			case EventError:
+				streamErr = event.Error
+			}
+
 			// 更新事件名称为序列名称
 			event.Name = s.name
 			out <- event
 		}
+		run.EndChain(ctx, output, streamErr)
 	}()
 
 	return out, nil
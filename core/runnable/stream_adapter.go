@@ -333,15 +333,19 @@ func (s *StreamableRunnable[I, O]) WithConfig(config *types.Config) Runnable[I,
 }
 
 // WithRetry 实现 Runnable 接口。
+//
+// 流式调用在第一个数据事件前失败时重试，见 RetryRunnable。
+//
 func (s *StreamableRunnable[I, O]) WithRetry(policy types.RetryPolicy) Runnable[I, O] {
-	// 暂时返回自身（TODO: 实现 Retry 包装）
-	return s
+	return NewRetryRunnable[I, O](s, policy)
 }
 
 // WithFallbacks 实现 Runnable 接口。
+//
+// 流式调用在第一个数据事件前失败时切换到 fallback，见 FallbackRunnable。
+//
 func (s *StreamableRunnable[I, O]) WithFallbacks(fallbacks ...Runnable[I, O]) Runnable[I, O] {
-	// 暂时返回自身（TODO: 实现 Fallback 包装）
-	return s
+	return NewFallbackRunnable[I, O](s, fallbacks)
 }
 
 // ConvertToRunnableEvent 将 types.StreamEvent 转换为 Runnable StreamEvent[T]。
@@ -0,0 +1,112 @@
+package runnable
+
+import (
+	"context"
+)
+
+// StreamWriter 接收嵌套组件在执行过程中产生的流式事件。
+//
+// 外层执行器（例如状态图）把 StreamWriter 放入上下文，
+// 内层组件（例如节点中调用的 ChatModel）通过 EmitStreamEvent
+// 把自己的流式事件转发给外层，而无需知道外层是谁。
+//
+// 注意：
+//   - StreamWriter 可能被多个协程同时调用，实现必须是并发安全的
+//   - StreamWriter 不应长时间阻塞，否则会拖慢产生事件的组件
+//
+type StreamWriter func(event StreamEvent[any])
+
+// streamWriterKey 是 StreamWriter 在上下文中的键。
+type streamWriterKey struct{}
+
+// ContextWithStreamWriter 返回携带 StreamWriter 的上下文。
+//
+// 参数：
+//   - ctx: 父上下文
+//   - writer: 流式事件接收者
+//
+// 返回：
+//   - context.Context: 新的上下文
+//
+func ContextWithStreamWriter(ctx context.Context, writer StreamWriter) context.Context {
+	return context.WithValue(ctx, streamWriterKey{}, writer)
+}
+
+// StreamWriterFromContext 返回上下文中的 StreamWriter，没有时返回 nil。
+func StreamWriterFromContext(ctx context.Context) StreamWriter {
+	writer, _ := ctx.Value(streamWriterKey{}).(StreamWriter)
+	return writer
+}
+
+// EmitStreamEvent 把事件发送给上下文中的 StreamWriter。
+//
+// 返回：
+//   - bool: 上下文中存在 StreamWriter 时返回 true
+//
+func EmitStreamEvent(ctx context.Context, event StreamEvent[any]) bool {
+	writer := StreamWriterFromContext(ctx)
+	if writer == nil {
+		return false
+	}
+
+	writer(event)
+	return true
+}
+
+// ForwardStream 把流中的数据事件（EventStream）转发给上下文中的 StreamWriter。
+//
+// 返回的 channel 原样传递所有事件，调用方的消费方式不变。
+// 上下文中没有 StreamWriter 时直接返回输入 channel。
+// ctx 取消后不再向返回的 channel 发送事件，并读完输入 channel，
+// 调用方停止读取时不会阻塞上游的生产者。
+//
+// 非流式调用没有 token，聊天模型的 Invoke 通过 chat.TraceInvoke
+// 把完整的响应作为一个 EventStream 事件发送给 StreamWriter。
+//
+// 参数：
+//   - ctx: 上下文
+//   - stream: 输入的流式事件 channel
+//
+// 返回：
+//   - <-chan StreamEvent[T]: 输出的流式事件 channel
+//
+// 示例：
+//
+//	out := make(chan runnable.StreamEvent[types.Message], 10)
+//	go produce(out)
+//	return runnable.ForwardStream(ctx, out), nil
+//
+func ForwardStream[T any](ctx context.Context, stream <-chan StreamEvent[T]) <-chan StreamEvent[T] {
+	writer := StreamWriterFromContext(ctx)
+	if writer == nil {
+		return stream
+	}
+
+	out := make(chan StreamEvent[T], cap(stream))
+
+	go func() {
+		defer close(out)
+
+		for event := range stream {
+			if event.Type == EventStream {
+				writer(StreamEvent[any]{
+					Type:     event.Type,
+					Data:     event.Data,
+					Name:     event.Name,
+					Metadata: event.Metadata,
+				})
+			}
+
+			select {
+			case out <- event:
+			case <-ctx.Done():
+				// 调用方可能已不再读取，读完上游让生产者退出
+				for range stream {
+				}
+				return
+			}
+		}
+	}()
+
+	return out
+}
@@ -0,0 +1,81 @@
+package runnable
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForwardStream(t *testing.T) {
+	produce := func() <-chan StreamEvent[string] {
+		in := make(chan StreamEvent[string], 3)
+		in <- StreamEvent[string]{Type: EventStart, Name: "model"}
+		in <- StreamEvent[string]{Type: EventStream, Name: "model", Data: "hello"}
+		in <- StreamEvent[string]{Type: EventEnd, Name: "model", Data: "hello"}
+		close(in)
+		return in
+	}
+
+	t.Run("without writer", func(t *testing.T) {
+		in := produce()
+		assert.Equal(t, in, ForwardStream(context.Background(), in))
+		assert.False(t, EmitStreamEvent(context.Background(), StreamEvent[any]{}))
+	})
+
+	t.Run("forwards stream events", func(t *testing.T) {
+		var forwarded []StreamEvent[any]
+		ctx := ContextWithStreamWriter(context.Background(), func(event StreamEvent[any]) {
+			forwarded = append(forwarded, event)
+		})
+
+		count := 0
+		for range ForwardStream(ctx, produce()) {
+			count++
+		}
+
+		assert.Equal(t, 3, count)
+		if assert.Len(t, forwarded, 1) {
+			assert.Equal(t, EventStream, forwarded[0].Type)
+			assert.Equal(t, "hello", forwarded[0].Data)
+			assert.Equal(t, "model", forwarded[0].Name)
+		}
+	})
+
+	t.Run("stops when context is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ContextWithStreamWriter(context.Background(), func(StreamEvent[any]) {}))
+
+		in := make(chan StreamEvent[string])
+		produced := make(chan struct{})
+		go func() {
+			defer close(produced)
+			defer close(in)
+			for i := 0; i < 100; i++ {
+				in <- StreamEvent[string]{Type: EventStream, Data: "token"}
+			}
+		}()
+
+		out := ForwardStream(ctx, in)
+		<-out
+		cancel()
+
+		select {
+		case <-produced:
+		case <-time.After(time.Second):
+			t.Fatal("producer blocked after the consumer stopped reading")
+		}
+
+		deadline := time.After(time.Second)
+		for {
+			select {
+			case _, ok := <-out:
+				if !ok {
+					return
+				}
+			case <-deadline:
+				t.Fatal("output channel was not closed")
+			}
+		}
+	})
+}
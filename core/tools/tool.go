@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/zhucl121/langchain-go/core/callbacks"
 	"github.com/zhucl121/langchain-go/pkg/types"
 )
 
@@ -146,6 +147,27 @@ func (t *FunctionTool) ToTypesTool() types.Tool {
 	}
 }
 
+// Run 执行工具，并向上下文中的回调处理器发送 OnToolStart 和 OnToolEnd。
+//
+// Agent、ToolExecutor 和图的 ToolNode 都通过 Run 执行工具，
+// 工具内部发起的调用（如模型或检索器）成为这次工具运行的子运行。
+//
+// 参数：
+//   - ctx: 上下文
+//   - tool: 工具
+//   - args: 工具参数
+//
+// 返回：
+//   - any: 执行结果
+//   - error: 执行错误
+//
+func Run(ctx context.Context, tool Tool, args map[string]any) (any, error) {
+	ctx, run := callbacks.StartTool(ctx, tool.GetName(), args)
+	result, err := tool.Execute(ctx, args)
+	run.EndTool(ctx, result, err)
+	return result, err
+}
+
 // ToolExecutor 是工具执行器。
 //
 // ToolExecutor 管理多个工具，并提供统一的执行接口。
@@ -212,7 +234,7 @@ func (e *ToolExecutor) Execute(ctx context.Context, toolName string, args map[st
 	resultChan := make(chan executeResult, 1)
 
 	go func() {
-		result, err := tool.Execute(ctx, args)
+		result, err := Run(ctx, tool, args)
 		resultChan <- executeResult{result: result, err: err}
 	}()
 
@@ -198,6 +198,29 @@ func TestMemoryCheckpointSaver_LoadLatest(t *testing.T) {
 	}
 }
 
+// TestMemoryCheckpointSaver_LoadLatestNamespace 测试按命名空间加载最新检查点
+func TestMemoryCheckpointSaver_LoadLatestNamespace(t *testing.T) {
+	saver := NewMemoryCheckpointSaver[TestState]()
+	ctx := context.Background()
+
+	saver.Save(ctx, NewCheckpoint("a", TestState{Counter: 1}, NewCheckpointConfig("thread-1")))
+	saver.Save(ctx, NewCheckpoint("b", TestState{Counter: 2}, NewCheckpointConfig("thread-1").WithNamespace("sub")))
+
+	latest, err := saver.Load(ctx, NewCheckpointConfig("thread-1"))
+	if err != nil || latest.ID != "a" {
+		t.Errorf("expected root checkpoint a, got %v, %v", latest, err)
+	}
+
+	latest, err = saver.Load(ctx, NewCheckpointConfig("thread-1").WithNamespace("sub"))
+	if err != nil || latest.ID != "b" || latest.CheckpointNS != "sub" {
+		t.Errorf("expected sub checkpoint b, got %v, %v", latest, err)
+	}
+
+	if _, err := saver.Load(ctx, NewCheckpointConfig("thread-1").WithNamespace("other")); !errors.Is(err, ErrCheckpointNotFound) {
+		t.Errorf("expected ErrCheckpointNotFound, got %v", err)
+	}
+}
+
 // TestMemoryCheckpointSaver_List 测试列出检查点
 func TestMemoryCheckpointSaver_List(t *testing.T) {
 	saver := NewMemoryCheckpointSaver[TestState]()
@@ -97,17 +97,15 @@ func (m *MemoryCheckpointSaver[S]) Load(ctx context.Context, config *CheckpointC
 		return checkpoint.Clone(), nil
 	}
 
-	// 否则，加载该线程的最新检查点
-	checkpointIDs, exists := m.threads[config.ThreadID]
-	if !exists || len(checkpointIDs) == 0 {
-		return nil, fmt.Errorf("%w: no checkpoints for thread %s", ErrCheckpointNotFound, config.ThreadID)
+	// 否则，加载该线程在命名空间中的最新检查点（最后一个）
+	checkpointIDs := m.threads[config.ThreadID]
+	for i := len(checkpointIDs) - 1; i >= 0; i-- {
+		if checkpoint := m.checkpoints[checkpointIDs[i]]; checkpoint.CheckpointNS == config.CheckpointNS {
+			return checkpoint.Clone(), nil
+		}
 	}
 
-	// 获取最新的检查点（最后一个）
-	latestID := checkpointIDs[len(checkpointIDs)-1]
-	checkpoint := m.checkpoints[latestID]
-
-	return checkpoint.Clone(), nil
+	return nil, fmt.Errorf("%w: no checkpoints for thread %s", ErrCheckpointNotFound, config.ThreadID)
 }
 
 // List 实现 CheckpointSaver 接口。
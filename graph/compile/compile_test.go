@@ -212,6 +212,51 @@ func TestValidator_Validate_WithConditionals(t *testing.T) {
 	}
 }
 
+// TestValidator_Validate_ConflictingEdges 测试条件边与普通边冲突
+func TestValidator_Validate_ConflictingEdges(t *testing.T) {
+	graph := &MockGraph[TestState]{
+		name: "test",
+		nodes: map[string]NodeInfo{
+			"router": {Name: "router"},
+			"node1":  {Name: "node1"},
+			"node2":  {Name: "node2"},
+		},
+		conditionals: []ConditionalInfo[TestState]{
+			{
+				Source:    "router",
+				PathMap:   map[string]string{"path1": "node1", "done": "__end__"},
+				Exclusive: true,
+			},
+		},
+		edges: []EdgeInfo{
+			{From: "router", To: "node2"},
+			{From: "router", To: "__end__"},
+			{From: "node1", To: "__end__"},
+			{From: "node2", To: "__end__"},
+		},
+		entryPoint: "router",
+	}
+
+	err := NewValidator[TestState]().Validate(graph)
+	if !errors.Is(err, ErrConflictingEdges) {
+		t.Fatalf("expected ErrConflictingEdges, got %v", err)
+	}
+
+	var valErr *ValidationError
+	if !errors.As(err, &valErr) || len(valErr.Issues) != 1 {
+		t.Fatalf("expected exactly one issue, got %v", err)
+	}
+	if issue := valErr.Issues[0]; issue.Node != "router" || issue.Target != "node2" {
+		t.Errorf("unexpected issue: %+v", issue)
+	}
+
+	// 分支边可以与普通边同时使用
+	graph.conditionals[0].Exclusive = false
+	if err := NewValidator[TestState]().Validate(graph); err != nil {
+		t.Errorf("expected no error for non-exclusive conditional, got %v", err)
+	}
+}
+
 // TestValidator_Validate_Cycle 测试循环检测
 func TestValidator_Validate_Cycle(t *testing.T) {
 	graph := &MockGraph[TestState]{
@@ -222,12 +267,20 @@ func TestValidator_Validate_Cycle(t *testing.T) {
 		},
 		edges: []EdgeInfo{
 			{From: "node1", To: "node2"},
-			{From: "node2", To: "node1"}, // 循环
+		},
+		conditionals: []ConditionalInfo[TestState]{
+			{
+				Source: "node2",
+				PathMap: map[string]string{
+					"again": "node1", // 循环
+					"done":  "__end__",
+				},
+			},
 		},
 		entryPoint: "node1",
 	}
 
-	// 不检测循环（默认）
+	// 不检测循环（默认）：带条件出口的循环是合法的
 	validator1 := NewValidator[TestState]()
 	if err := validator1.Validate(graph); err != nil {
 		t.Errorf("expected no error without cycle check, got %v", err)
@@ -247,6 +300,91 @@ func TestValidator_Validate_Cycle(t *testing.T) {
 	}
 }
 
+// TestValidator_Validate_CycleWithoutExit 测试没有条件出口的循环
+func TestValidator_Validate_CycleWithoutExit(t *testing.T) {
+	graph := &MockGraph[TestState]{
+		name: "test",
+		nodes: map[string]NodeInfo{
+			"node1": {Name: "node1"},
+			"node2": {Name: "node2"},
+		},
+		edges: []EdgeInfo{
+			{From: "node1", To: "node2"},
+			{From: "node2", To: "node1"}, // 循环
+		},
+		entryPoint: "node1",
+	}
+
+	err := NewValidator[TestState]().Validate(graph)
+	if !errors.Is(err, ErrCyclicGraph) {
+		t.Fatalf("expected ErrCyclicGraph, got %v", err)
+	}
+
+	var valErr *ValidationError
+	errors.As(err, &valErr)
+	if len(valErr.Issues) != 1 {
+		t.Fatalf("expected 1 issue, got %v", valErr.Issues)
+	}
+
+	issue := valErr.Issues[0]
+	if len(issue.Nodes) != 2 || issue.Nodes[0] != "node1" || issue.Nodes[1] != "node2" {
+		t.Errorf("expected cycle nodes [node1 node2], got %v", issue.Nodes)
+	}
+}
+
+// TestValidator_Validate_ReportsAllIssues 测试一次报告所有问题
+func TestValidator_Validate_ReportsAllIssues(t *testing.T) {
+	graph := &MockGraph[TestState]{
+		name: "test",
+		nodes: map[string]NodeInfo{
+			"router":   {Name: "router"},
+			"dead_end": {Name: "dead_end"},
+			"orphan":   {Name: "orphan"},
+		},
+		edges: []EdgeInfo{
+			{From: "orphan", To: "__end__"},
+		},
+		conditionals: []ConditionalInfo[TestState]{
+			{
+				Source: "router",
+				PathMap: map[string]string{
+					"next":    "dead_end",
+					"missing": "ghost",
+				},
+			},
+		},
+		entryPoint: "router",
+	}
+
+	err := NewValidator[TestState]().Validate(graph)
+
+	var valErr *ValidationError
+	if !errors.As(err, &valErr) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+
+	for _, kind := range []error{ErrInvalidConditional, ErrUnreachableNode, ErrNoOutgoingEdge} {
+		if !errors.Is(err, kind) {
+			t.Errorf("expected %v to be reported", kind)
+		}
+	}
+
+	if len(valErr.Issues) != 3 || len(valErr.Details) != 3 {
+		t.Fatalf("expected 3 issues, got %v", valErr.Details)
+	}
+
+	conditional := valErr.Issues[0]
+	if conditional.Node != "router" || conditional.Target != "ghost" || conditional.Path != "missing" {
+		t.Errorf("unexpected conditional issue: %+v", conditional)
+	}
+	if valErr.Issues[1].Node != "orphan" {
+		t.Errorf("expected orphan to be unreachable, got %+v", valErr.Issues[1])
+	}
+	if valErr.Issues[2].Node != "dead_end" {
+		t.Errorf("expected dead_end to have no outgoing edge, got %+v", valErr.Issues[2])
+	}
+}
+
 // TestValidateQuick 测试快速验证
 func TestValidateQuick(t *testing.T) {
 	graph := &MockGraph[TestState]{
@@ -386,12 +524,16 @@ func TestCompiledGraph_Adjacency(t *testing.T) {
 		edges: []EdgeInfo{
 			{From: "node1", To: "node2"},
 			{From: "node2", To: "node3"},
+			{From: "node3", To: "__end__"},
 		},
 		entryPoint: "node1",
 	}
 
 	compiler := NewCompiler[TestState]()
-	compiled, _ := compiler.Compile(graph)
+	compiled, err := compiler.Compile(graph)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
 
 	adjacency := compiled.GetAdjacency()
 
@@ -10,9 +10,10 @@
 //   - 节点和边的有效性
 //   - 无孤立节点
 //   - 无无效边
+//   - 每个节点都有出边
 //
 // 2. **拓扑分析** - 分析图的结构
-//   - 检测循环
+//   - 检测循环（每个循环都必须有条件出口）
 //   - 检查可达性
 //   - 构建执行顺序
 //
@@ -40,9 +41,31 @@
 //
 // # 错误处理
 //
-// 编译器会返回详细的错误信息：
-//   - ValidationError: 验证错误
-//   - CyclicGraphError: 循环检测错误
-//   - UnreachableNodeError: 不可达节点错误
+// 验证器一次报告所有问题，返回 *ValidationError：
+//   - Issues: 每个问题的类型（错误哨兵）和相关节点、目标、路径
+//   - Details: 每个问题的文字描述
+//
+// 可以用 errors.Is 判断是否存在某类问题：
+//
+//	err := validator.Validate(stateGraph)
+//	if errors.Is(err, compile.ErrUnreachableNode) {
+//	    // 存在不可达节点
+//	}
+//
+//	var valErr *compile.ValidationError
+//	if errors.As(err, &valErr) {
+//	    for _, issue := range valErr.Issues {
+//	        fmt.Println(issue.Kind, issue.Node, issue.Target)
+//	    }
+//	}
+//
+// 问题类型：
+//   - ErrNoEntryPoint / ErrInvalidEntryPoint: 入口点缺失或不存在
+//   - ErrNodeNotFound / ErrDanglingEdge: 边引用了不存在的节点
+//   - ErrInvalidConditional: 条件边的 PathMap 指向不存在的节点
+//   - ErrConflictingEdges: 条件边的源节点还有指向其他节点的普通边
+//   - ErrUnreachableNode: 从入口点无法到达的节点
+//   - ErrNoOutgoingEdge: 没有出边也不是结束点的节点
+//   - ErrCyclicGraph: 没有条件出口的循环（严格模式下为任意循环）
 //
 package compile
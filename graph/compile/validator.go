@@ -3,6 +3,7 @@ package compile
 import (
 	"errors"
 	"fmt"
+	"sort"
 )
 
 // 错误定义
@@ -15,12 +16,62 @@ var (
 	ErrCyclicGraph        = errors.New("compile: cyclic graph detected")
 	ErrDanglingEdge       = errors.New("compile: dangling edge")
 	ErrInvalidConditional = errors.New("compile: invalid conditional edge")
+	ErrConflictingEdges   = errors.New("compile: conditional edge combined with static edge")
 )
 
+// Issue 是验证发现的单个问题。
+//
+// Kind 是对应的错误哨兵（例如 ErrUnreachableNode），可以用 errors.Is 判断。
+// Node、Target、Path、Nodes 只在相关时设置，便于工具定位问题。
+//
+type Issue struct {
+	// Kind 问题类型（错误哨兵）
+	Kind error
+
+	// Node 出问题的节点（边的源节点、不可达节点等）
+	Node string
+
+	// Target 出问题的目标节点（边或条件边的目标）
+	Target string
+
+	// Path 条件边的路径名称
+	Path string
+
+	// Nodes 涉及的节点集合（例如循环中的节点）
+	Nodes []string
+
+	// Message 问题描述
+	Message string
+}
+
+// Error 实现 error 接口。
+func (i *Issue) Error() string {
+	if i.Message == "" {
+		return i.Kind.Error()
+	}
+	return fmt.Sprintf("%s: %s", i.Kind.Error(), i.Message)
+}
+
+// Unwrap 返回问题类型，支持 errors.Is。
+func (i *Issue) Unwrap() error {
+	return i.Kind
+}
+
 // ValidationError 是验证错误。
+//
+// ValidationError 包含一次验证发现的所有问题，而不是只返回第一个。
+// errors.Is 对其中任意一个问题的类型都成立：
+//
+//	if errors.Is(err, compile.ErrUnreachableNode) {
+//	    var valErr *compile.ValidationError
+//	    errors.As(err, &valErr)
+//	    for _, issue := range valErr.Issues { ... }
+//	}
+//
 type ValidationError struct {
 	Message string
 	Details []string
+	Issues  []*Issue
 }
 
 // Error 实现 error 接口。
@@ -31,6 +82,15 @@ func (e *ValidationError) Error() string {
 	return fmt.Sprintf("%s: %v", e.Message, e.Details)
 }
 
+// Unwrap 返回所有问题，支持 errors.Is 和 errors.As。
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, len(e.Issues))
+	for i, issue := range e.Issues {
+		errs[i] = issue
+	}
+	return errs
+}
+
 // Validator 是图验证器。
 //
 // Validator 负责验证状态图的完整性和合法性。
@@ -38,9 +98,12 @@ func (e *ValidationError) Error() string {
 // 验证项目：
 //   - 入口点检查
 //   - 节点完整性
-//   - 边有效性
+//   - 边有效性（包括条件边的 PathMap 目标）
+//   - 条件边与普通边冲突检查
 //   - 可达性分析
-//   - 循环检测（可选）
+//   - 出边检查（每个节点都必须有出边或结束点）
+//   - 循环出口检查（每个循环都必须有能离开循环的条件边）
+//   - 循环检测（可选，严格模式下拒绝任何循环）
 //
 type Validator[S any] struct {
 	checkCycles bool // 是否检测循环（默认 false，因为合法的图可以有循环）
@@ -89,160 +152,242 @@ type EdgeInfo struct {
 }
 
 // ConditionalInfo 是条件边信息。
+//
+// PathMap 为空表示目标在运行时动态决定，验证时视为可能到达任意节点或结束。
+//
 type ConditionalInfo[S any] struct {
 	Source  string
 	PathMap map[string]string
+
+	// Exclusive 表示条件边只选择一个目标（AddConditionalEdges），
+	// 不能与同一源节点指向其他节点的普通边同时使用。
+	// 分支边和 Send 边可以与普通边同时触发，为 false。
+	Exclusive bool
 }
 
+// endNode 是结束节点的名称（与 state.END 一致）。
+const endNode = "__end__"
+
 // Validate 验证图。
 //
 // 参数：
 //   - graph: 要验证的图
 //
 // 返回：
-//   - error: 验证错误
+//   - error: 验证错误（*ValidationError，包含所有问题）
 //
 func (v *Validator[S]) Validate(graph GraphInfo[S]) error {
-	var errors []string
+	var issues []*Issue
 
 	// 1. 检查入口点
-	if err := v.validateEntryPoint(graph); err != nil {
-		errors = append(errors, err.Error())
-	}
+	issues = append(issues, v.validateEntryPoint(graph)...)
 
 	// 2. 检查节点
-	if err := v.validateNodes(graph); err != nil {
-		errors = append(errors, err.Error())
-	}
+	issues = append(issues, v.validateNodes(graph)...)
 
 	// 3. 检查边
-	if err := v.validateEdges(graph); err != nil {
-		errors = append(errors, err.Error())
-	}
+	issues = append(issues, v.validateEdges(graph)...)
 
 	// 4. 检查条件边
-	if err := v.validateConditionals(graph); err != nil {
-		errors = append(errors, err.Error())
-	}
+	issues = append(issues, v.validateConditionals(graph)...)
+	issues = append(issues, v.validateConflictingEdges(graph)...)
 
 	// 5. 检查可达性
-	if err := v.validateReachability(graph); err != nil {
-		errors = append(errors, err.Error())
-	}
+	issues = append(issues, v.validateReachability(graph)...)
 
-	// 6. 检查循环（可选）
+	// 6. 检查出边
+	issues = append(issues, v.validateOutgoingEdges(graph)...)
+
+	// 7. 检查循环出口
+	issues = append(issues, v.validateCycleExits(graph)...)
+
+	// 8. 检查循环（可选）
 	if v.checkCycles {
-		if err := v.validateNoCycles(graph); err != nil {
-			errors = append(errors, err.Error())
-		}
+		issues = append(issues, v.validateNoCycles(graph)...)
 	}
 
-	if len(errors) > 0 {
-		return &ValidationError{
-			Message: "graph validation failed",
-			Details: errors,
-		}
+	if len(issues) > 0 {
+		return newValidationError(issues)
 	}
 
 	return nil
 }
 
+// newValidationError 从问题列表创建验证错误。
+func newValidationError(issues []*Issue) *ValidationError {
+	details := make([]string, len(issues))
+	for i, issue := range issues {
+		details[i] = issue.Error()
+	}
+
+	return &ValidationError{
+		Message: "graph validation failed",
+		Details: details,
+		Issues:  issues,
+	}
+}
+
 // validateEntryPoint 验证入口点。
-func (v *Validator[S]) validateEntryPoint(graph GraphInfo[S]) error {
+func (v *Validator[S]) validateEntryPoint(graph GraphInfo[S]) []*Issue {
 	entryPoint := graph.GetEntryPoint()
 
 	if entryPoint == "" {
-		return ErrNoEntryPoint
+		return []*Issue{{Kind: ErrNoEntryPoint}}
 	}
 
 	nodes := graph.GetNodes()
 	if _, exists := nodes[entryPoint]; !exists {
-		return fmt.Errorf("%w: %s", ErrInvalidEntryPoint, entryPoint)
+		return []*Issue{{
+			Kind:    ErrInvalidEntryPoint,
+			Node:    entryPoint,
+			Message: entryPoint,
+		}}
 	}
 
 	return nil
 }
 
 // validateNodes 验证节点。
-func (v *Validator[S]) validateNodes(graph GraphInfo[S]) error {
+func (v *Validator[S]) validateNodes(graph GraphInfo[S]) []*Issue {
 	nodes := graph.GetNodes()
 
 	if len(nodes) == 0 {
-		return fmt.Errorf("graph has no nodes")
+		return []*Issue{{Kind: ErrNodeNotFound, Message: "graph has no nodes"}}
 	}
 
 	return nil
 }
 
 // validateEdges 验证边。
-func (v *Validator[S]) validateEdges(graph GraphInfo[S]) error {
+func (v *Validator[S]) validateEdges(graph GraphInfo[S]) []*Issue {
 	nodes := graph.GetNodes()
 	edges := graph.GetEdges()
 
+	var issues []*Issue
 	for _, edge := range edges {
 		// 验证源节点存在
 		if _, exists := nodes[edge.From]; !exists {
-			return fmt.Errorf("%w: edge from %s to %s", ErrNodeNotFound, edge.From, edge.To)
+			issues = append(issues, &Issue{
+				Kind:    ErrNodeNotFound,
+				Node:    edge.From,
+				Target:  edge.To,
+				Message: fmt.Sprintf("edge from %s to %s", edge.From, edge.To),
+			})
+			continue
 		}
 
 		// 验证目标节点存在（END 除外）
-		if edge.To != "__end__" {
+		if edge.To != endNode {
 			if _, exists := nodes[edge.To]; !exists {
-				return fmt.Errorf("%w: edge from %s to %s", ErrNodeNotFound, edge.From, edge.To)
+				issues = append(issues, &Issue{
+					Kind:    ErrDanglingEdge,
+					Node:    edge.From,
+					Target:  edge.To,
+					Message: fmt.Sprintf("edge from %s to unknown node %s", edge.From, edge.To),
+				})
 			}
 		}
 	}
 
-	return nil
+	return issues
 }
 
 // validateConditionals 验证条件边。
-func (v *Validator[S]) validateConditionals(graph GraphInfo[S]) error {
+func (v *Validator[S]) validateConditionals(graph GraphInfo[S]) []*Issue {
 	nodes := graph.GetNodes()
 	conditionals := graph.GetConditionals()
 
+	var issues []*Issue
 	for _, cond := range conditionals {
 		// 验证源节点存在
 		if _, exists := nodes[cond.Source]; !exists {
-			return fmt.Errorf("%w: conditional edge from %s", ErrNodeNotFound, cond.Source)
+			issues = append(issues, &Issue{
+				Kind:    ErrNodeNotFound,
+				Node:    cond.Source,
+				Message: fmt.Sprintf("conditional edge from %s", cond.Source),
+			})
+			continue
 		}
 
 		// 验证所有目标节点存在（END 除外）
-		for pathName, target := range cond.PathMap {
-			if target != "__end__" {
-				if _, exists := nodes[target]; !exists {
-					return fmt.Errorf("%w: conditional target %s (path %s from %s)",
-						ErrNodeNotFound, target, pathName, cond.Source)
-				}
+		for _, pathName := range sortedKeys(cond.PathMap) {
+			target := cond.PathMap[pathName]
+			if target == endNode {
+				continue
+			}
+			if _, exists := nodes[target]; !exists {
+				issues = append(issues, &Issue{
+					Kind:   ErrInvalidConditional,
+					Node:   cond.Source,
+					Target: target,
+					Path:   pathName,
+					Message: fmt.Sprintf("path %s from %s targets unknown node %s",
+						pathName, cond.Source, target),
+				})
 			}
 		}
 	}
 
-	return nil
+	return issues
+}
+
+// validateConflictingEdges 验证条件边的源节点没有指向其他节点的普通边。
+//
+// 条件边和普通边在下一步同时触发，条件边选择 END 时普通边的目标仍会执行，
+// 与只看条件边的预期不符，因此拒绝这种组合。指向 END 的普通边不影响执行，允许存在。
+//
+func (v *Validator[S]) validateConflictingEdges(graph GraphInfo[S]) []*Issue {
+	exclusive := make(map[string]bool)
+	for _, cond := range graph.GetConditionals() {
+		if cond.Exclusive {
+			exclusive[cond.Source] = true
+		}
+	}
+
+	var issues []*Issue
+	for _, edge := range graph.GetEdges() {
+		if !exclusive[edge.From] || edge.To == endNode {
+			continue
+		}
+		issues = append(issues, &Issue{
+			Kind:   ErrConflictingEdges,
+			Node:   edge.From,
+			Target: edge.To,
+			Message: fmt.Sprintf("node %s has both a conditional edge and an edge to %s",
+				edge.From, edge.To),
+		})
+	}
+
+	return issues
 }
 
 // validateReachability 验证可达性。
-func (v *Validator[S]) validateReachability(graph GraphInfo[S]) error {
+func (v *Validator[S]) validateReachability(graph GraphInfo[S]) []*Issue {
 	nodes := graph.GetNodes()
 	entryPoint := graph.GetEntryPoint()
 
+	if _, exists := nodes[entryPoint]; !exists {
+		// 入口点无效时已经报告过，不再报告所有节点不可达
+		return nil
+	}
+
 	// 构建可达节点集合
 	reachable := make(map[string]bool)
 	v.markReachable(graph, entryPoint, reachable)
 
 	// 检查是否有不可达节点
-	unreachable := make([]string, 0)
-	for nodeName := range nodes {
+	var issues []*Issue
+	for _, nodeName := range sortedKeys(nodes) {
 		if !reachable[nodeName] {
-			unreachable = append(unreachable, nodeName)
+			issues = append(issues, &Issue{
+				Kind:    ErrUnreachableNode,
+				Node:    nodeName,
+				Message: nodeName,
+			})
 		}
 	}
 
-	if len(unreachable) > 0 {
-		return fmt.Errorf("%w: %v", ErrUnreachableNode, unreachable)
-	}
-
-	return nil
+	return issues
 }
 
 // markReachable 标记从给定节点可达的所有节点（DFS）。
@@ -253,92 +398,228 @@ func (v *Validator[S]) markReachable(graph GraphInfo[S], nodeName string, reacha
 
 	reachable[nodeName] = true
 
+	for _, target := range v.successors(graph, nodeName) {
+		v.markReachable(graph, target, reachable)
+	}
+}
+
+// successors 返回节点所有可能的后继节点（不含 END）。
+//
+// 动态条件边（PathMap 为空）视为可能到达任意节点。
+//
+func (v *Validator[S]) successors(graph GraphInfo[S], nodeName string) []string {
+	var result []string
+
 	// 遍历普通边
-	edges := graph.GetEdges()
-	for _, edge := range edges {
-		if edge.From == nodeName && edge.To != "__end__" {
-			v.markReachable(graph, edge.To, reachable)
+	for _, edge := range graph.GetEdges() {
+		if edge.From == nodeName && edge.To != endNode {
+			result = append(result, edge.To)
 		}
 	}
 
 	// 遍历条件边
-	conditionals := graph.GetConditionals()
-	for _, cond := range conditionals {
-		if cond.Source == nodeName {
-			for _, target := range cond.PathMap {
-				if target != "__end__" {
-					v.markReachable(graph, target, reachable)
-				}
+	for _, cond := range graph.GetConditionals() {
+		if cond.Source != nodeName {
+			continue
+		}
+		if len(cond.PathMap) == 0 {
+			return sortedKeys(graph.GetNodes())
+		}
+		for _, pathName := range sortedKeys(cond.PathMap) {
+			if target := cond.PathMap[pathName]; target != endNode {
+				result = append(result, target)
 			}
 		}
 	}
+
+	return result
 }
 
-// validateNoCycles 验证无循环（严格模式）。
+// validateOutgoingEdges 验证每个节点都有出边。
 //
-// 注意：此检查默认禁用，因为合法的图可以有循环（通过条件边控制）。
+// 没有普通边、没有条件边的节点会让执行停在半路。
+// 指向 END 的边（包括 SetFinishPoint）也算作出边。
 //
-func (v *Validator[S]) validateNoCycles(graph GraphInfo[S]) error {
-	// 使用 DFS 检测循环
-	visited := make(map[string]bool)
-	recStack := make(map[string]bool)
+func (v *Validator[S]) validateOutgoingEdges(graph GraphInfo[S]) []*Issue {
+	hasOutgoing := make(map[string]bool)
+	for _, edge := range graph.GetEdges() {
+		hasOutgoing[edge.From] = true
+	}
+	for _, cond := range graph.GetConditionals() {
+		hasOutgoing[cond.Source] = true
+	}
 
-	nodes := graph.GetNodes()
-	for nodeName := range nodes {
-		if !visited[nodeName] {
-			if v.hasCycleDFS(graph, nodeName, visited, recStack) {
-				return fmt.Errorf("%w: cycle detected involving node %s", ErrCyclicGraph, nodeName)
+	var issues []*Issue
+	for _, nodeName := range sortedKeys(graph.GetNodes()) {
+		if !hasOutgoing[nodeName] {
+			issues = append(issues, &Issue{
+				Kind:    ErrNoOutgoingEdge,
+				Node:    nodeName,
+				Message: nodeName,
+			})
+		}
+	}
+
+	return issues
+}
+
+// validateCycleExits 验证每个循环都有条件出口。
+//
+// 只由普通边组成的循环一旦进入就无法退出。
+// 循环（强连通分量）中至少要有一条条件边能够到达循环之外的节点或 END。
+//
+func (v *Validator[S]) validateCycleExits(graph GraphInfo[S]) []*Issue {
+	var issues []*Issue
+
+	for _, component := range v.stronglyConnectedComponents(graph) {
+		if !v.isCycle(graph, component) {
+			continue
+		}
+
+		if v.hasConditionalExit(graph, component) {
+			continue
+		}
+
+		nodes := sortedKeys(component)
+		issues = append(issues, &Issue{
+			Kind:    ErrCyclicGraph,
+			Node:    nodes[0],
+			Nodes:   nodes,
+			Message: fmt.Sprintf("cycle %v has no conditional exit", nodes),
+		})
+	}
+
+	return issues
+}
+
+// isCycle 判断强连通分量是否构成循环（多个节点或自环）。
+func (v *Validator[S]) isCycle(graph GraphInfo[S], component map[string]bool) bool {
+	if len(component) > 1 {
+		return true
+	}
+
+	for nodeName := range component {
+		for _, target := range v.successors(graph, nodeName) {
+			if target == nodeName {
+				return true
 			}
 		}
 	}
 
-	return nil
+	return false
 }
 
-// hasCycleDFS 使用 DFS 检测循环。
-func (v *Validator[S]) hasCycleDFS(
-	graph GraphInfo[S],
-	nodeName string,
-	visited map[string]bool,
-	recStack map[string]bool,
-) bool {
-	visited[nodeName] = true
-	recStack[nodeName] = true
-
-	// 检查普通边
-	edges := graph.GetEdges()
-	for _, edge := range edges {
-		if edge.From == nodeName && edge.To != "__end__" {
-			if !visited[edge.To] {
-				if v.hasCycleDFS(graph, edge.To, visited, recStack) {
-					return true
-				}
-			} else if recStack[edge.To] {
-				return true // 发现循环
+// hasConditionalExit 判断循环中是否有能离开循环的条件边。
+func (v *Validator[S]) hasConditionalExit(graph GraphInfo[S], component map[string]bool) bool {
+	for _, cond := range graph.GetConditionals() {
+		if !component[cond.Source] {
+			continue
+		}
+
+		// 动态条件边可能到达任意位置
+		if len(cond.PathMap) == 0 {
+			return true
+		}
+
+		for _, target := range cond.PathMap {
+			if target == endNode || !component[target] {
+				return true
 			}
 		}
 	}
 
-	// 检查条件边
-	conditionals := graph.GetConditionals()
-	for _, cond := range conditionals {
-		if cond.Source == nodeName {
-			for _, target := range cond.PathMap {
-				if target != "__end__" {
-					if !visited[target] {
-						if v.hasCycleDFS(graph, target, visited, recStack) {
-							return true
-						}
-					} else if recStack[target] {
-						return true // 发现循环
-					}
+	return false
+}
+
+// stronglyConnectedComponents 计算图的强连通分量（Tarjan 算法）。
+func (v *Validator[S]) stronglyConnectedComponents(graph GraphInfo[S]) []map[string]bool {
+	var (
+		index      int
+		stack      []string
+		onStack    = make(map[string]bool)
+		indices    = make(map[string]int)
+		lowLinks   = make(map[string]int)
+		components []map[string]bool
+	)
+
+	nodes := graph.GetNodes()
+
+	var strongConnect func(nodeName string)
+	strongConnect = func(nodeName string) {
+		indices[nodeName] = index
+		lowLinks[nodeName] = index
+		index++
+		stack = append(stack, nodeName)
+		onStack[nodeName] = true
+
+		for _, target := range v.successors(graph, nodeName) {
+			if _, exists := nodes[target]; !exists {
+				continue
+			}
+			if _, visited := indices[target]; !visited {
+				strongConnect(target)
+				lowLinks[nodeName] = min(lowLinks[nodeName], lowLinks[target])
+			} else if onStack[target] {
+				lowLinks[nodeName] = min(lowLinks[nodeName], indices[target])
+			}
+		}
+
+		if lowLinks[nodeName] == indices[nodeName] {
+			component := make(map[string]bool)
+			for {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[top] = false
+				component[top] = true
+				if top == nodeName {
+					break
 				}
 			}
+			components = append(components, component)
 		}
 	}
 
-	recStack[nodeName] = false
-	return false
+	for _, nodeName := range sortedKeys(nodes) {
+		if _, visited := indices[nodeName]; !visited {
+			strongConnect(nodeName)
+		}
+	}
+
+	return components
+}
+
+// validateNoCycles 验证无循环（严格模式）。
+//
+// 注意：此检查默认禁用，因为合法的图可以有循环（通过条件边控制）。
+//
+func (v *Validator[S]) validateNoCycles(graph GraphInfo[S]) []*Issue {
+	var issues []*Issue
+
+	for _, component := range v.stronglyConnectedComponents(graph) {
+		if !v.isCycle(graph, component) {
+			continue
+		}
+
+		nodes := sortedKeys(component)
+		issues = append(issues, &Issue{
+			Kind:    ErrCyclicGraph,
+			Node:    nodes[0],
+			Nodes:   nodes,
+			Message: fmt.Sprintf("cycle detected involving nodes %v", nodes),
+		})
+	}
+
+	return issues
+}
+
+// sortedKeys 返回 map 排序后的键，保证报告顺序稳定。
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
 }
 
 // ValidateQuick 快速验证（仅基础检查）。
@@ -354,20 +635,14 @@ func ValidateQuick[S any](graph GraphInfo[S]) error {
 	validator.checkCycles = false
 
 	// 仅做基础验证，跳过可达性和循环检测
-	if err := validator.validateEntryPoint(graph); err != nil {
-		return err
-	}
-
-	if err := validator.validateNodes(graph); err != nil {
-		return err
-	}
-
-	if err := validator.validateEdges(graph); err != nil {
-		return err
-	}
-
-	if err := validator.validateConditionals(graph); err != nil {
-		return err
+	var issues []*Issue
+	issues = append(issues, validator.validateEntryPoint(graph)...)
+	issues = append(issues, validator.validateNodes(graph)...)
+	issues = append(issues, validator.validateEdges(graph)...)
+	issues = append(issues, validator.validateConditionals(graph)...)
+
+	if len(issues) > 0 {
+		return newValidationError(issues)
 	}
 
 	return nil
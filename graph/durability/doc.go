@@ -17,12 +17,17 @@
 //   - AtLeastOnce: 至少执行一次
 //   - ExactlyOnce: 恰好执行一次（最强保证）
 //
-// 2. **DurableTask** - 持久化任务
+// 2. **PersistMode** - 检查点写入时机
+//   - ModeSync: 每步完成后同步写入（默认）
+//   - ModeAsync: 后台按顺序写入，执行结束前全部落盘
+//   - ModeExit: 仅在执行完成或失败时写入
+//
+// 3. **DurableTask** - 持久化任务
 //   - 任务包装
 //   - 状态追踪
 //   - 重试逻辑
 //
-// 3. **RecoveryManager** - 恢复管理器
+// 4. **RecoveryManager** - 恢复管理器
 //   - 故障检测
 //   - 状态恢复
 //   - 续传执行
@@ -32,14 +37,24 @@
 // 配置 Durability 模式：
 //
 //	graph := state.NewStateGraph[MyState]("my-graph")
-//	graph.WithDurability(durability.ExactlyOnce)
+//	graph.WithCheckpointer(checkpointer)
+//	graph.WithDurability(durability.ModeAsync)
+//
+// 或者配置完整策略：
+//
+//	graph.WithDurabilityConfig(
+//	    durability.NewDurabilityConfig(durability.AtLeastOnce).
+//	        WithPersistMode(durability.ModeSync).
+//	        WithCheckpointInterval(5),
+//	)
 //
-// 包装持久化任务：
+// 包装持久化任务并作为节点添加（应用任务的重试和超时设置）：
 //
 //	task := durability.NewDurableTask("task-1", func(ctx context.Context, state S) (S, error) {
 //	    // 任务逻辑
 //	    return state, nil
-//	})
+//	}).WithTimeout(30 * time.Second)
+//	graph.AddTask(task)
 //
 // 恢复执行：
 //
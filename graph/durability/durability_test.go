@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"testing"
 	"time"
+
+	"github.com/zhucl121/langchain-go/graph/hitl"
 )
 
 // TestState 测试用状态
@@ -74,6 +76,30 @@ func TestNewDurabilityConfig(t *testing.T) {
 	}
 }
 
+// TestPersistMode 测试检查点写入模式
+func TestPersistMode(t *testing.T) {
+	modes := []PersistMode{ModeExit, ModeAsync, ModeSync}
+
+	for _, mode := range modes {
+		if err := mode.Validate(); err != nil {
+			t.Errorf("mode %s validation failed: %v", mode, err)
+		}
+	}
+
+	if !errors.Is(PersistMode("invalid").Validate(), ErrInvalidPersistMode) {
+		t.Error("expected ErrInvalidPersistMode for invalid mode")
+	}
+
+	config := NewDurabilityConfig(AtLeastOnce)
+	if config.PersistMode != ModeSync {
+		t.Errorf("expected default persist mode sync, got %s", config.PersistMode)
+	}
+
+	if config.WithPersistMode(ModeExit).PersistMode != ModeExit {
+		t.Error("persist mode not set")
+	}
+}
+
 // TestDurabilityConfig_WithMethods 测试配置链式调用
 func TestDurabilityConfig_WithMethods(t *testing.T) {
 	config := NewDurabilityConfig(ExactlyOnce).
@@ -287,6 +313,58 @@ func TestDurableTask_Retry(t *testing.T) {
 	}
 }
 
+// TestDurableTask_InterruptNotRetried 测试人工中断不重试
+func TestDurableTask_InterruptNotRetried(t *testing.T) {
+	attempts := 0
+	task := NewDurableTask("task-1", func(ctx context.Context, state TestState) (TestState, error) {
+		attempts++
+		return state, fmt.Errorf("waiting: %w", hitl.ErrInterrupted)
+	}).WithRetryPolicy(NewRetryPolicy(5))
+
+	execCtx := NewExecutionContext("thread-1", NewDurabilityConfig(AtLeastOnce))
+	_, err := task.Execute(context.Background(), TestState{}, execCtx)
+
+	if !errors.Is(err, hitl.ErrInterrupted) {
+		t.Fatalf("expected ErrInterrupted, got %v", err)
+	}
+	if attempts != 1 {
+		t.Errorf("expected 1 attempt, got %d", attempts)
+	}
+}
+
+// TestDurableTask_Timeout 测试单次尝试超时
+func TestDurableTask_Timeout(t *testing.T) {
+	attempts := 0
+	policy := NewRetryPolicy(1)
+	policy.InitialDelay = time.Millisecond
+
+	task := NewDurableTask("task-1", func(ctx context.Context, state TestState) (TestState, error) {
+		attempts++
+		if attempts == 1 {
+			<-ctx.Done()
+			return state, ctx.Err()
+		}
+		state.Counter++
+		return state, nil
+	}).WithRetryPolicy(policy).WithTimeout(10 * time.Millisecond)
+
+	config := NewDurabilityConfig(AtLeastOnce).WithTimeoutPerTask(time.Hour)
+	execCtx := NewExecutionContext("thread-1", config)
+
+	newState, err := task.Execute(context.Background(), TestState{}, execCtx)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	if attempts != 2 {
+		t.Errorf("expected 2 attempts, got %d", attempts)
+	}
+
+	if newState.Counter != 1 {
+		t.Errorf("expected Counter 1, got %d", newState.Counter)
+	}
+}
+
 // TestDurableTask_ExactlyOnce 测试 ExactlyOnce 模式
 func TestDurableTask_ExactlyOnce(t *testing.T) {
 	calls := 0
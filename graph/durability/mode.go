@@ -3,16 +3,18 @@ package durability
 import (
 	"context"
 	"errors"
+	"sync"
 	"time"
 )
 
 // 错误定义
 var (
-	ErrTaskNotFound     = errors.New("durability: task not found")
-	ErrInvalidMode      = errors.New("durability: invalid durability mode")
-	ErrRecoveryFailed   = errors.New("durability: recovery failed")
-	ErrTaskAlreadyDone  = errors.New("durability: task already completed")
-	ErrMaxRetriesReached = errors.New("durability: max retries reached")
+	ErrTaskNotFound       = errors.New("durability: task not found")
+	ErrInvalidMode        = errors.New("durability: invalid durability mode")
+	ErrRecoveryFailed     = errors.New("durability: recovery failed")
+	ErrTaskAlreadyDone    = errors.New("durability: task already completed")
+	ErrMaxRetriesReached  = errors.New("durability: max retries reached")
+	ErrInvalidPersistMode = errors.New("durability: invalid persist mode")
 )
 
 // DurabilityMode 是持久性模式。
@@ -67,6 +69,45 @@ func (m DurabilityMode) NeedsDeduplication() bool {
 	return m == ExactlyOnce
 }
 
+// PersistMode 是检查点写入时机。
+//
+// PersistMode 对应 LangGraph 的 durability 参数，决定图执行过程中
+// 何时把状态写入检查点保存器。
+//
+type PersistMode string
+
+const (
+	// ModeExit 仅在执行结束（完成或失败）时写入
+	// - 性能最好
+	// - 进程崩溃时会丢失本次执行的中间状态
+	ModeExit PersistMode = "exit"
+
+	// ModeAsync 每步完成后在后台写入
+	// - 不阻塞下一步执行
+	// - 进程崩溃时可能丢失最后几步
+	ModeAsync PersistMode = "async"
+
+	// ModeSync 每步完成后同步写入（默认）
+	// - 下一步开始前检查点已落盘
+	// - 可靠性最高
+	ModeSync PersistMode = "sync"
+)
+
+// Validate 验证写入模式。
+func (m PersistMode) Validate() error {
+	switch m {
+	case ModeExit, ModeAsync, ModeSync:
+		return nil
+	default:
+		return ErrInvalidPersistMode
+	}
+}
+
+// String 返回字符串表示。
+func (m PersistMode) String() string {
+	return string(m)
+}
+
 // DurabilityConfig 是持久性配置。
 //
 // DurabilityConfig 定义了执行的持久性策略。
@@ -75,6 +116,9 @@ type DurabilityConfig struct {
 	// Mode 持久性模式
 	Mode DurabilityMode
 
+	// PersistMode 检查点写入时机（exit/async/sync）
+	PersistMode PersistMode
+
 	// CheckpointInterval 检查点间隔（步数）
 	// 0 表示每步都保存
 	CheckpointInterval int
@@ -104,6 +148,7 @@ type DurabilityConfig struct {
 func NewDurabilityConfig(mode DurabilityMode) *DurabilityConfig {
 	return &DurabilityConfig{
 		Mode:                mode,
+		PersistMode:         ModeSync,
 		CheckpointInterval:  1, // 默认每步保存
 		MaxRetries:          3,
 		RetryDelay:          time.Second,
@@ -112,6 +157,12 @@ func NewDurabilityConfig(mode DurabilityMode) *DurabilityConfig {
 	}
 }
 
+// WithPersistMode 设置检查点写入时机。
+func (c *DurabilityConfig) WithPersistMode(mode PersistMode) *DurabilityConfig {
+	c.PersistMode = mode
+	return c
+}
+
 // WithCheckpointInterval 设置检查点间隔。
 func (c *DurabilityConfig) WithCheckpointInterval(interval int) *DurabilityConfig {
 	c.CheckpointInterval = interval
@@ -142,6 +193,12 @@ func (c *DurabilityConfig) Validate() error {
 		return err
 	}
 
+	if c.PersistMode != "" {
+		if err := c.PersistMode.Validate(); err != nil {
+			return err
+		}
+	}
+
 	if c.CheckpointInterval < 0 {
 		return errors.New("checkpoint interval cannot be negative")
 	}
@@ -271,6 +328,8 @@ type ExecutionContext struct {
 
 	// Config Durability 配置
 	Config *DurabilityConfig
+
+	mu sync.Mutex
 }
 
 // NewExecutionContext 创建执行上下文。
@@ -283,7 +342,13 @@ func NewExecutionContext(threadID string, config *DurabilityConfig) *ExecutionCo
 }
 
 // GetTaskExecution 获取任务执行记录。
+//
+// 可以被并行执行的任务同时调用。
+//
 func (ec *ExecutionContext) GetTaskExecution(taskID string) *TaskExecution {
+	ec.mu.Lock()
+	defer ec.mu.Unlock()
+
 	if exec, exists := ec.TaskExecutions[taskID]; exists {
 		return exec
 	}
@@ -293,6 +358,20 @@ func (ec *ExecutionContext) GetTaskExecution(taskID string) *TaskExecution {
 	return exec
 }
 
+// ResetTaskExecution 为任务创建新的执行记录，丢弃之前的记录。
+//
+// 用于同一任务在一次执行中被多次调度（例如循环）的场景，
+// 避免 ExactlyOnce 模式把新的调度当作重复执行跳过。
+//
+func (ec *ExecutionContext) ResetTaskExecution(taskID string) *TaskExecution {
+	ec.mu.Lock()
+	defer ec.mu.Unlock()
+
+	exec := NewTaskExecution(taskID)
+	ec.TaskExecutions[taskID] = exec
+	return exec
+}
+
 // ShouldCheckpoint 是否应该保存检查点。
 func (ec *ExecutionContext) ShouldCheckpoint(step int) bool {
 	if !ec.Config.Mode.NeedsCheckpoint() {
@@ -309,6 +388,9 @@ func (ec *ExecutionContext) ShouldCheckpoint(step int) bool {
 
 // IsTaskCompleted 任务是否已完成。
 func (ec *ExecutionContext) IsTaskCompleted(taskID string) bool {
+	ec.mu.Lock()
+	defer ec.mu.Unlock()
+
 	if exec, exists := ec.TaskExecutions[taskID]; exists {
 		return exec.IsCompleted()
 	}
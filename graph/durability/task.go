@@ -2,9 +2,12 @@ package durability
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
+
+	"github.com/zhucl121/langchain-go/graph/hitl"
 )
 
 // DurableTask 是持久化任务。
@@ -24,6 +27,10 @@ type DurableTask[S any] struct {
 	// IsIdempotent 是否幂等
 	IsIdempotent bool
 
+	// Timeout 单次尝试的超时时间
+	// 0 表示使用 DurabilityConfig.TimeoutPerTask
+	Timeout time.Duration
+
 	// Metadata 元数据
 	Metadata map[string]any
 
@@ -61,6 +68,12 @@ func (dt *DurableTask[S]) WithIdempotent(idempotent bool) *DurableTask[S] {
 	return dt
 }
 
+// WithTimeout 设置单次尝试的超时时间。
+func (dt *DurableTask[S]) WithTimeout(timeout time.Duration) *DurableTask[S] {
+	dt.Timeout = timeout
+	return dt
+}
+
 // WithMetadata 设置元数据。
 func (dt *DurableTask[S]) WithMetadata(key string, value any) *DurableTask[S] {
 	dt.mu.Lock()
@@ -95,7 +108,21 @@ func (dt *DurableTask[S]) Execute(
 	}
 
 	// 执行任务（带重试）
-	return dt.executeWithRetry(ctx, state, taskExec)
+	return dt.executeWithRetry(ctx, state, taskExec, dt.attemptTimeout(execCtx.Config))
+}
+
+// attemptTimeout 返回单次尝试的超时时间。
+//
+// 任务自身的 Timeout 优先，其次是配置中的 TimeoutPerTask。
+//
+func (dt *DurableTask[S]) attemptTimeout(config *DurabilityConfig) time.Duration {
+	if dt.Timeout > 0 {
+		return dt.Timeout
+	}
+	if config != nil {
+		return config.TimeoutPerTask
+	}
+	return 0
 }
 
 // executeWithRetry 执行任务（带重试）。
@@ -103,6 +130,7 @@ func (dt *DurableTask[S]) executeWithRetry(
 	ctx context.Context,
 	state S,
 	taskExec *TaskExecution,
+	timeout time.Duration,
 ) (S, error) {
 	var lastErr error
 	maxRetries := dt.RetryPolicy.MaxRetries
@@ -120,7 +148,7 @@ func (dt *DurableTask[S]) executeWithRetry(
 		taskExec.MarkRunning()
 
 		// 执行任务
-		newState, err := dt.Func(ctx, state)
+		newState, err := dt.runAttempt(ctx, state, timeout)
 
 		if err == nil {
 			// 成功
@@ -128,6 +156,12 @@ func (dt *DurableTask[S]) executeWithRetry(
 			return newState, nil
 		}
 
+		// 人工中断（hitl）不是失败，直接返回，不重试
+		if errors.Is(err, hitl.ErrInterrupted) {
+			var zero S
+			return zero, err
+		}
+
 		// 记录错误
 		lastErr = err
 		taskExec.MarkFailed(err)
@@ -160,6 +194,18 @@ func (dt *DurableTask[S]) executeWithRetry(
 	return zero, fmt.Errorf("%w: %v (after %d attempts)", ErrMaxRetriesReached, lastErr, taskExec.Attempts)
 }
 
+// runAttempt 执行一次尝试（带超时）。
+func (dt *DurableTask[S]) runAttempt(ctx context.Context, state S, timeout time.Duration) (S, error) {
+	if timeout <= 0 {
+		return dt.Func(ctx, state)
+	}
+
+	attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	return dt.Func(attemptCtx, state)
+}
+
 // GetID 返回任务 ID。
 func (dt *DurableTask[S]) GetID() string {
 	return dt.ID
@@ -214,9 +214,8 @@ func (e *ConditionalEdge[S]) RemovePath(pathName string) *ConditionalEdge[S] {
 //
 // BranchEdge 可以同时路由到多个节点（用于并行执行）。
 //
-// 注意：
-//   - 并行执行功能将在后续实现
-//   - 目前仅作为接口预留
+// 通过 state.StateGraph.AddBranchEdge 添加后，Select 选中的所有目标
+// 在下一个超步中并行执行。
 //
 type BranchEdge[S any] struct {
 	source   string
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"time"
+
+	"github.com/zhucl121/langchain-go/core/callbacks"
 )
 
 // CompiledGraphInfo 是已编译图的接口（避免循环依赖）。
@@ -239,7 +241,10 @@ func (e *Executor[S]) executeNode(
 		return zero, fmt.Errorf("%w: %s", ErrNodeNotFound, nodeName)
 	}
 
-	return nodeFunc(ctx, state)
+	ctx, run := callbacks.StartNode(ctx, nodeName, state)
+	output, err := nodeFunc(ctx, state)
+	run.EndNode(ctx, output, err)
+	return output, err
 }
 
 // routeNext 路由到下一个节点。
@@ -285,6 +285,30 @@ func TestScheduler_ScheduleNode_ContextCancelled(t *testing.T) {
 	}
 }
 
+// TestScheduler_Acquire 测试获取和释放并发槽位
+func TestScheduler_Acquire(t *testing.T) {
+	scheduler := NewScheduler[TestState]().WithMaxConcurrent(1)
+
+	release, err := scheduler.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+
+	// 槽位已满，等待超时
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := scheduler.Acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+
+	release()
+	release, err = scheduler.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Acquire after release failed: %v", err)
+	}
+	release()
+}
+
 // MockGraph 模拟编译后的图
 type MockGraph struct {
 	name       string
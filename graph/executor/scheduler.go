@@ -156,13 +156,12 @@ func (s *Scheduler[S]) ScheduleNode(
 	state S,
 ) (S, error) {
 	// 获取信号量
-	select {
-	case s.semaphore <- struct{}{}:
-		defer func() { <-s.semaphore }()
-	case <-ctx.Done():
+	release, err := s.Acquire(ctx)
+	if err != nil {
 		var zero S
-		return zero, ctx.Err()
+		return zero, err
 	}
+	defer release()
 
 	// 执行节点
 	result, err := executor.Execute(ctx, state)
@@ -181,6 +180,31 @@ func (s *Scheduler[S]) ScheduleNode(
 	return newState, nil
 }
 
+// Acquire 获取一个并发槽位，阻塞直到有空闲槽位或 ctx 取消。
+//
+// 由外部的执行循环（例如 state.CompiledGraph 的超步）使用，
+// 与 ScheduleNode 共享 MaxConcurrent 限制。
+//
+// 参数：
+//   - ctx: 上下文
+//
+// 返回：
+//   - func(): 释放槽位的函数，执行完成后必须调用
+//   - error: ctx 取消时返回 ctx.Err()
+//
+func (s *Scheduler[S]) Acquire(ctx context.Context) (func(), error) {
+	s.mu.RLock()
+	semaphore := s.semaphore
+	s.mu.RUnlock()
+
+	select {
+	case semaphore <- struct{}{}:
+		return func() { <-semaphore }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
 // ScheduleNodes 调度多个节点执行。
 //
 // 根据调度策略执行：
@@ -35,23 +35,30 @@
 //
 // # 基本使用
 //
-// 添加中断点：
+// 在状态图中添加中断点：
 //
-//	graph := state.NewStateGraph[MyState]("my-graph")
-//	graph.AddInterruptBefore("critical_decision")
+//	compiled, _ := graph.Compile(state.InterruptBefore("critical_decision"))
 //
-// 处理中断：
+// 节点内动态中断，等待人类输入：
 //
-//	result, err := graph.Invoke(ctx, initialState)
-//	if errors.Is(err, hitl.ErrInterrupted) {
+//	answer, err := state.Interrupt(ctx, payload)
+//	if err != nil {
+//	    return s, err
+//	}
+//
+// 处理中断（需要配置检查点，恢复可以在另一个进程中进行）：
+//
+//	result, err := compiled.Invoke(ctx, initialState, state.WithThreadID("t-1"))
+//	var ierr *state.InterruptError
+//	if errors.As(err, &ierr) {
 //	    // 获取中断信息
-//	    interrupt := graph.GetCurrentInterrupt()
-//	    
+//	    interrupt := ierr.Interrupts[0]
+//
 //	    // 获取人类输入
-//	    approval := getHumanApproval(interrupt)
-//	    
+//	    input := getHumanInput(interrupt.Payload)
+//
 //	    // 恢复执行
-//	    result, err = graph.Resume(ctx, approval)
+//	    result, err = compiled.ResumeWithInput(ctx, "t-1", input)
 //	}
 //
 // 审批流程：
@@ -117,6 +117,9 @@ type Interrupt struct {
 	// State 中断时的状态（序列化）
 	State any
 
+	// Payload 节点动态中断时携带的数据（例如需要人类确认的内容）
+	Payload any
+
 	// Timestamp 中断时间
 	Timestamp time.Time
 
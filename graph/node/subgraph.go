@@ -18,6 +18,8 @@ import (
 // 注意：
 //   - 需要提供状态映射函数
 //   - 子图必须已编译
+//   - 通过 StateGraph.AddGraphNode 加入状态图后，子图的检查点写入父图线程中
+//     以节点名称为命名空间的检查点，子图中的中断可以从父图恢复
 //
 // 示例：
 //
@@ -47,6 +49,7 @@ import (
 //	        },
 //	    ),
 //	)
+//	parentGraph.AddGraphNode(subgraphNode)
 //
 type SubgraphNode[ParentState, ChildState any] struct {
 	metadata       *Metadata
@@ -127,6 +130,11 @@ func WithStateMapper[ParentState, ChildState any](
 	}
 }
 
+// GetSubgraph 返回节点执行的子图。
+func (n *SubgraphNode[ParentState, ChildState]) GetSubgraph() any {
+	return n.subgraph
+}
+
 // GetName 实现 Node 接口。
 func (n *SubgraphNode[ParentState, ChildState]) GetName() string {
 	return n.metadata.Name
@@ -1,5 +1,10 @@
 package state
 
+import (
+	"fmt"
+	"reflect"
+)
+
 // Channel 表示状态通道。
 //
 // Channel 用于管理状态的特定字段，支持自定义的更新策略。
@@ -14,7 +19,7 @@ package state
 //
 // 注意：
 //   - 这是高级特性，大多数情况下直接更新状态即可
-//   - 将在后续版本中提供更多内置 Channel 类型
+//   - 通过 StateGraph.WithChannel 注册后，用于合并并行节点对同一字段的更新
 //
 type Channel interface {
 	// GetName 返回通道名称
@@ -111,22 +116,119 @@ func (c *AppendChannel) GetName() string {
 // 如果 current 为 nil 或不是切片，会创建新切片。
 //
 // 注意：
-//   - 目前仅支持 []interface{} 类型
-//   - 后续版本将支持泛型切片
+//   - current 为任意类型的切片时，update 可以是单个元素，
+//     也可以是同类型的切片（逐个追加）
+//   - 不返回与 current 共享底层数组的切片
 //
 func (c *AppendChannel) Update(current any, update any) (any, error) {
 	// 如果 current 为 nil，创建新切片
 	if current == nil {
+		if isSlice(update) {
+			return update, nil
+		}
 		return []any{update}, nil
 	}
 
 	// 尝试转换为切片
-	currentSlice, ok := current.([]any)
-	if !ok {
+	currentSlice := reflect.ValueOf(current)
+	if currentSlice.Kind() != reflect.Slice {
 		// 如果不是切片，创建新切片
 		return []any{update}, nil
 	}
 
+	result := reflect.MakeSlice(currentSlice.Type(), 0, currentSlice.Len()+1)
+	result = reflect.AppendSlice(result, currentSlice)
+
 	// 追加新值
-	return append(currentSlice, update), nil
+	if update == nil {
+		if !canBeNil(currentSlice.Type().Elem()) {
+			return nil, fmt.Errorf("%w: cannot append nil to %s channel %s",
+				ErrInvalidChannelUpdate, currentSlice.Type(), c.name)
+		}
+		return reflect.Append(result, reflect.Zero(currentSlice.Type().Elem())).Interface(), nil
+	}
+
+	updateValue := reflect.ValueOf(update)
+	switch {
+	case updateValue.Kind() == reflect.Slice && updateValue.Type().AssignableTo(currentSlice.Type()):
+		result = reflect.AppendSlice(result, updateValue)
+	case updateValue.Type().AssignableTo(currentSlice.Type().Elem()):
+		result = reflect.Append(result, updateValue)
+	default:
+		return nil, fmt.Errorf("%w: cannot append %T to %s channel %s",
+			ErrInvalidChannelUpdate, update, currentSlice.Type(), c.name)
+	}
+
+	return result.Interface(), nil
+}
+
+// ReducerChannel 是基于 Reducer 的通道。
+//
+// ReducerChannel 让 MergeReducer、SumReducer 等归约器可以用于状态的单个字段。
+//
+// 示例：
+//
+//	graph.WithChannel(state.NewReducerChannel("Total", state.SumReducer[int]()))
+//	graph.WithChannel(state.NewReducerChannel("Metadata", state.MergeReducer()))
+//
+type ReducerChannel[T any] struct {
+	name    string
+	reducer Reducer[T]
+}
+
+// NewReducerChannel 创建基于 Reducer 的通道。
+//
+// 参数：
+//   - name: 通道名称（字段名或 map 键）
+//   - reducer: 归约器
+//
+// 返回：
+//   - *ReducerChannel[T]: 通道实例
+//
+func NewReducerChannel[T any](name string, reducer Reducer[T]) *ReducerChannel[T] {
+	return &ReducerChannel[T]{name: name, reducer: reducer}
+}
+
+// GetName 实现 Channel 接口。
+func (c *ReducerChannel[T]) GetName() string {
+	return c.name
+}
+
+// Update 实现 Channel 接口。
+//
+// current 为 nil 时视为 T 的零值。
+//
+func (c *ReducerChannel[T]) Update(current any, update any) (any, error) {
+	var cur T
+	if current != nil {
+		v, ok := current.(T)
+		if !ok {
+			return nil, fmt.Errorf("%w: channel %s expects %T, got %T",
+				ErrInvalidChannelUpdate, c.name, cur, current)
+		}
+		cur = v
+	}
+
+	upd, ok := update.(T)
+	if !ok {
+		return nil, fmt.Errorf("%w: channel %s expects %T, got %T",
+			ErrInvalidChannelUpdate, c.name, cur, update)
+	}
+
+	return c.reducer(cur, upd), nil
+}
+
+// isSlice 判断值是否为切片。
+func isSlice(v any) bool {
+	return v != nil && reflect.TypeOf(v).Kind() == reflect.Slice
+}
+
+// canBeNil 判断类型的值是否可以为 nil。
+func canBeNil(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Interface, reflect.Pointer, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		return true
+	default:
+		return false
+	}
 }
@@ -1,6 +1,7 @@
 package state
 
 import (
+	"errors"
 	"testing"
 )
 
@@ -113,6 +114,57 @@ func TestAppendChannel_NotSlice(t *testing.T) {
 	}
 }
 
+// TestAppendChannel_TypedSlice 测试追加到具体类型的切片
+func TestAppendChannel_TypedSlice(t *testing.T) {
+	channel := NewAppendChannel("names")
+
+	current := []string{"a"}
+	result, err := channel.Update(current, []string{"b", "c"})
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	resultSlice, ok := result.([]string)
+	if !ok || len(resultSlice) != 3 || resultSlice[2] != "c" {
+		t.Errorf("expected [a b c], got %v", result)
+	}
+
+	// 不修改原切片
+	if len(current) != 1 {
+		t.Error("current slice should not be modified")
+	}
+
+	if _, err := channel.Update(current, 42); !errors.Is(err, ErrInvalidChannelUpdate) {
+		t.Errorf("expected ErrInvalidChannelUpdate, got %v", err)
+	}
+}
+
+// TestReducerChannel 测试基于 Reducer 的通道
+func TestReducerChannel(t *testing.T) {
+	channel := NewReducerChannel("total", SumReducer[int]())
+
+	if channel.GetName() != "total" {
+		t.Errorf("expected name 'total', got %s", channel.GetName())
+	}
+
+	result, err := channel.Update(10, 5)
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if result != 15 {
+		t.Errorf("expected 15, got %v", result)
+	}
+
+	result, err = channel.Update(nil, 3)
+	if err != nil || result != 3 {
+		t.Errorf("expected 3 for nil current, got %v (%v)", result, err)
+	}
+
+	if _, err := channel.Update(10, "x"); !errors.Is(err, ErrInvalidChannelUpdate) {
+		t.Errorf("expected ErrInvalidChannelUpdate, got %v", err)
+	}
+}
+
 // TestLastValueReducer 测试覆盖归约器
 func TestLastValueReducer(t *testing.T) {
 	reducer := LastValueReducer[int]()
@@ -0,0 +1,208 @@
+package state
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zhucl121/langchain-go/graph/checkpoint"
+	"github.com/zhucl121/langchain-go/graph/hitl"
+)
+
+// 检查点元数据的键
+const (
+	// metadataNextKey 记录下一步要执行的节点列表
+	metadataNextKey = "next"
+
+	// metadataErrorKey 记录导致执行中止的错误
+	metadataErrorKey = "error"
+
+	// metadataInterruptsKey 记录等待处理的中断
+	metadataInterruptsKey = "interrupts"
+
+	// metadataResumeKey 记录恢复时提供给各节点的恢复值
+	metadataResumeKey = "resume"
+
+	// metadataResumeInputKey 记录恢复时传给子图的输入
+	metadataResumeInputKey = "resume_input"
+
+	// metadataSendsKey 记录下一步的 Send 调度
+	metadataSendsKey = "sends"
+
+	// metadataWritesKey 记录未完成的超步中已完成的 Send 和持久化任务的输出
+	metadataWritesKey = "writes"
+)
+
+// 检查点来源
+const (
+	// SourceInput 执行开始时写入的输入检查点
+	SourceInput = "input"
+
+	// SourceLoop 节点执行完成后写入的检查点
+	SourceLoop = "loop"
+
+	// SourceResume 从中断恢复时写入的检查点
+	SourceResume = "resume"
+)
+
+// checkpointWrite 是一次待写入的检查点。
+type checkpointWrite[S any] struct {
+	state    S
+	source   string
+	step     int
+	nodeName string       // 刚完成的节点，多个节点以逗号分隔（输入检查点为空）
+	next     []string     // 下一步要执行的节点
+	sends    []Send[S]    // 下一步的 Send 调度
+	writes   map[string]S // 下一步中已完成的任务的输出（按任务标识）
+	err      error        // 执行失败时的错误
+
+	interrupts []*hitl.Interrupt // 等待处理的中断
+	resume     map[string][]any  // 恢复值（按节点）
+	input      any               // 传给子图的恢复输入
+}
+
+// threadCheckpointer 负责单个线程在一次执行中的检查点读写。
+//
+// 每个检查点的 ParentID 指向同一线程的上一个检查点，
+// 元数据中记录步数、刚完成的节点以及下一步要执行的节点。
+// 子图的检查点写入父图线程中以节点路径命名的命名空间。
+//
+type threadCheckpointer[S any] struct {
+	saver    checkpoint.CheckpointSaver[S]
+	threadID string
+	ns       string
+
+	// parentID 是最近写入（或恢复）的检查点 ID
+	parentID string
+}
+
+// newThreadCheckpointer 创建线程检查点读写器，ns 为检查点命名空间（顶层图为空）。
+func newThreadCheckpointer[S any](saver checkpoint.CheckpointSaver[S], threadID, ns string) *threadCheckpointer[S] {
+	return &threadCheckpointer[S]{
+		saver:    saver,
+		threadID: threadID,
+		ns:       ns,
+	}
+}
+
+// resume 加载线程的检查点，之后写入的检查点以它为父节点。
+//
+// 参数：
+//   - ctx: 上下文
+//   - checkpointID: 要加载的检查点，为空时加载最新检查点
+//
+// 返回：
+//   - *checkpoint.Checkpoint[S]: 检查点（线程没有检查点时为 nil）
+//   - error: 加载错误；指定的检查点不存在时返回 checkpoint.ErrCheckpointNotFound
+//
+func (t *threadCheckpointer[S]) resume(ctx context.Context, checkpointID string) (*checkpoint.Checkpoint[S], error) {
+	config := checkpoint.NewCheckpointConfig(t.threadID).
+		WithNamespace(t.ns).
+		WithCheckpointID(checkpointID)
+
+	cp, err := t.saver.Load(ctx, config)
+	if err != nil {
+		if checkpointID == "" && errors.Is(err, checkpoint.ErrCheckpointNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("state: failed to load checkpoint for thread %s: %w", t.threadID, err)
+	}
+
+	t.parentID = cp.ID
+	return cp, nil
+}
+
+// save 写入一个检查点。
+//
+// 参数：
+//   - ctx: 上下文
+//   - write: 要写入的内容
+//
+func (t *threadCheckpointer[S]) save(ctx context.Context, write checkpointWrite[S]) error {
+	metadata := checkpoint.NewCheckpointMetadata().
+		WithSource(write.source).
+		WithStep(write.step).
+		WithNodeName(write.nodeName)
+	metadata.Extra[metadataNextKey] = write.next
+	if len(write.sends) > 0 {
+		metadata.Extra[metadataSendsKey] = write.sends
+	}
+	if len(write.writes) > 0 {
+		metadata.Extra[metadataWritesKey] = write.writes
+	}
+	if write.err != nil {
+		metadata.Extra[metadataErrorKey] = write.err.Error()
+	}
+	if len(write.interrupts) > 0 {
+		metadata.Extra[metadataInterruptsKey] = encodeInterrupts(write.interrupts)
+	}
+	if len(write.resume) > 0 {
+		metadata.Extra[metadataResumeKey] = write.resume
+	}
+	if write.input != nil {
+		metadata.Extra[metadataResumeInputKey] = write.input
+	}
+
+	config := checkpoint.NewCheckpointConfig(t.threadID).WithNamespace(t.ns)
+	for k, v := range metadata.ToMap() {
+		config.WithMetadata(k, v)
+	}
+
+	cp := checkpoint.NewCheckpoint(newCheckpointID(), write.state, config)
+	cp.ParentID = t.parentID
+
+	if err := t.saver.Save(ctx, cp); err != nil {
+		return fmt.Errorf("state: failed to save checkpoint for thread %s: %w", t.threadID, err)
+	}
+
+	t.parentID = cp.ID
+	return nil
+}
+
+// newCheckpointID 生成检查点 ID。
+//
+// ID 以纳秒时间戳开头，保证同一线程内按写入顺序排列。
+//
+func newCheckpointID() string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return fmt.Sprintf("cp-%016x-%s", time.Now().UnixNano(), hex.EncodeToString(b))
+}
+
+// metadataInt 读取元数据中的整数。
+//
+// 经过 JSON 序列化的检查点（SQLite、Postgres）中数字会变成 float64。
+//
+func metadataInt(v any) int {
+	switch n := v.(type) {
+	case int:
+		return n
+	case int64:
+		return int(n)
+	case float64:
+		return int(n)
+	default:
+		return 0
+	}
+}
+
+// metadataStrings 读取元数据中的字符串列表。
+func metadataStrings(v any) []string {
+	switch s := v.(type) {
+	case []string:
+		return append([]string(nil), s...)
+	case []any:
+		result := make([]string, 0, len(s))
+		for _, item := range s {
+			if str, ok := item.(string); ok {
+				result = append(result, str)
+			}
+		}
+		return result
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,201 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/zhucl121/langchain-go/graph/checkpoint"
+)
+
+// newCheckpointTestGraph 创建 step1 -> step2 -> step3 的测试图
+//
+// step2 在 failStep2 为 true 时返回错误。
+func newCheckpointTestGraph(saver checkpoint.CheckpointSaver[TestState], failStep2 *bool, calls map[string]int) *CompiledGraph[TestState] {
+	graph := NewStateGraph[TestState]("checkpoint-test")
+
+	graph.AddNode("step1", func(ctx context.Context, s TestState) (TestState, error) {
+		calls["step1"]++
+		s.Counter += 1
+		return s, nil
+	})
+	graph.AddNode("step2", func(ctx context.Context, s TestState) (TestState, error) {
+		calls["step2"]++
+		if *failStep2 {
+			return s, errors.New("step2 failed")
+		}
+		s.Counter += 10
+		return s, nil
+	})
+	graph.AddNode("step3", func(ctx context.Context, s TestState) (TestState, error) {
+		calls["step3"]++
+		s.Counter += 100
+		s.Done = true
+		return s, nil
+	})
+
+	graph.SetEntryPoint("step1")
+	graph.AddEdge("step1", "step2")
+	graph.AddEdge("step2", "step3")
+	graph.AddEdge("step3", END)
+	graph.WithCheckpointer(saver)
+
+	compiled, _ := graph.Compile()
+	return compiled
+}
+
+// TestInvoke_WritesCheckpoints 测试每个节点完成后写入检查点
+func TestInvoke_WritesCheckpoints(t *testing.T) {
+	saver := checkpoint.NewMemoryCheckpointSaver[TestState]()
+	fail := false
+	calls := make(map[string]int)
+	compiled := newCheckpointTestGraph(saver, &fail, calls)
+
+	ctx := context.Background()
+	result, err := compiled.Invoke(ctx, TestState{}, WithThreadID("thread-1"))
+	if err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+
+	if result.Counter != 111 {
+		t.Errorf("expected Counter=111, got %d", result.Counter)
+	}
+
+	checkpoints, err := saver.List(ctx, "thread-1")
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+
+	// 1 个输入检查点 + 3 个节点检查点
+	if len(checkpoints) != 4 {
+		t.Fatalf("expected 4 checkpoints, got %d", len(checkpoints))
+	}
+
+	expectedNodes := []string{"", "step1", "step2", "step3"}
+	for i, cp := range checkpoints {
+		if cp.Metadata["node_name"] != expectedNodes[i] {
+			t.Errorf("checkpoint %d: expected node %q, got %v", i, expectedNodes[i], cp.Metadata["node_name"])
+		}
+		if cp.Metadata["step"] != i {
+			t.Errorf("checkpoint %d: expected step %d, got %v", i, i, cp.Metadata["step"])
+		}
+		if i > 0 && cp.ParentID != checkpoints[i-1].ID {
+			t.Errorf("checkpoint %d: expected parent %s, got %s", i, checkpoints[i-1].ID, cp.ParentID)
+		}
+	}
+
+	last := checkpoints[len(checkpoints)-1]
+	if len(metadataStrings(last.Metadata[metadataNextKey])) != 0 {
+		t.Errorf("last checkpoint should have no next node, got %v", last.Metadata[metadataNextKey])
+	}
+}
+
+// TestInvoke_ResumeFromLastCompletedNode 测试同一线程从最后完成的节点恢复
+func TestInvoke_ResumeFromLastCompletedNode(t *testing.T) {
+	saver := checkpoint.NewMemoryCheckpointSaver[TestState]()
+	fail := true
+	calls := make(map[string]int)
+	compiled := newCheckpointTestGraph(saver, &fail, calls)
+
+	ctx := context.Background()
+
+	// 第一次执行在 step2 失败
+	_, err := compiled.Invoke(ctx, TestState{}, WithThreadID("thread-1"))
+	if err == nil {
+		t.Fatal("expected error from step2")
+	}
+
+	// 第二次执行应从 step2 继续，忽略新的初始状态
+	fail = false
+	result, err := compiled.Invoke(ctx, TestState{Counter: 1000}, WithThreadID("thread-1"))
+	if err != nil {
+		t.Fatalf("resume failed: %v", err)
+	}
+
+	if result.Counter != 111 {
+		t.Errorf("expected Counter=111, got %d", result.Counter)
+	}
+
+	if calls["step1"] != 1 {
+		t.Errorf("step1 should run once, ran %d times", calls["step1"])
+	}
+	if calls["step2"] != 2 {
+		t.Errorf("step2 should run twice, ran %d times", calls["step2"])
+	}
+}
+
+// TestInvoke_ResumeAcrossGraphInstances 测试用新的图实例恢复（模拟进程重启）
+func TestInvoke_ResumeAcrossGraphInstances(t *testing.T) {
+	saver := checkpoint.NewMemoryCheckpointSaver[TestState]()
+	ctx := context.Background()
+
+	fail := true
+	first := newCheckpointTestGraph(saver, &fail, make(map[string]int))
+	if _, err := first.Invoke(ctx, TestState{}, WithThreadID("thread-1")); err == nil {
+		t.Fatal("expected error from step2")
+	}
+
+	ok := false
+	calls := make(map[string]int)
+	second := newCheckpointTestGraph(saver, &ok, calls)
+	result, err := second.Invoke(ctx, TestState{}, WithThreadID("thread-1"))
+	if err != nil {
+		t.Fatalf("resume failed: %v", err)
+	}
+
+	if result.Counter != 111 || !result.Done {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if calls["step1"] != 0 {
+		t.Errorf("step1 should not run again, ran %d times", calls["step1"])
+	}
+}
+
+// TestInvoke_CompletedThreadStartsOver 测试已完成的线程重新从入口开始
+func TestInvoke_CompletedThreadStartsOver(t *testing.T) {
+	saver := checkpoint.NewMemoryCheckpointSaver[TestState]()
+	fail := false
+	calls := make(map[string]int)
+	compiled := newCheckpointTestGraph(saver, &fail, calls)
+
+	ctx := context.Background()
+	if _, err := compiled.Invoke(ctx, TestState{}, WithThreadID("thread-1")); err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+
+	result, err := compiled.Invoke(ctx, TestState{Counter: 1}, WithThreadID("thread-1"))
+	if err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+
+	if result.Counter != 112 {
+		t.Errorf("expected Counter=112, got %d", result.Counter)
+	}
+	if calls["step1"] != 2 {
+		t.Errorf("step1 should run twice, ran %d times", calls["step1"])
+	}
+
+	// 新的执行继续挂在同一条检查点链上
+	checkpoints, _ := saver.List(ctx, "thread-1")
+	if len(checkpoints) != 8 {
+		t.Fatalf("expected 8 checkpoints, got %d", len(checkpoints))
+	}
+	if checkpoints[4].ParentID != checkpoints[3].ID {
+		t.Error("second run should link to the previous run's last checkpoint")
+	}
+}
+
+// TestInvoke_NoThreadIDSkipsCheckpoints 测试未指定线程时不写检查点
+func TestInvoke_NoThreadIDSkipsCheckpoints(t *testing.T) {
+	saver := checkpoint.NewMemoryCheckpointSaver[TestState]()
+	fail := false
+	compiled := newCheckpointTestGraph(saver, &fail, make(map[string]int))
+
+	if _, err := compiled.Invoke(context.Background(), TestState{}); err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+
+	if stats := saver.GetStats(); stats["total_checkpoints"] != 0 {
+		t.Errorf("expected no checkpoints, got %d", stats["total_checkpoints"])
+	}
+}
@@ -57,21 +57,152 @@
 //	    },
 //	)
 //
+// # 并行分支 (Fan-out / Fan-in)
+//
+// 执行按超步进行：一个节点的所有出边在下一步同时触发，同一步的节点并行执行。
+// 多个节点写入同一字段时，用 Channel 或 Reducer 合并：
+//
+//	graph.AddEdge("plan", "search_web")
+//	graph.AddEdge("plan", "search_docs")
+//	graph.AddEdge("search_web", "summarize")
+//	graph.AddEdge("search_docs", "summarize") // summarize 只执行一次
+//
+//	graph.WithChannel(state.NewAppendChannel("Documents"))
+//	graph.WithChannel(state.NewReducerChannel("Cost", state.SumReducer[float64]()))
+//
+// 需要根据状态选择分支时使用 edge.BranchEdge：
+//
+//	graph.AddBranchEdge(edge.NewBranchEdge("plan", branches, selectSources))
+//
+// 分支边和 Send 边与同一节点的普通边一起触发。AddConditionalEdges 只选择一个目标，
+// 同一节点不能再有指向其他节点的普通边（指向 END 的除外），否则 Compile 返回
+// compile.ErrConflictingEdges。
+//
+// # Map-Reduce (Send)
+//
+// 同一个节点需要按数据项执行多次时，用 AddSendEdges 返回一组 Send，
+// 每个 Send 以自己的输入状态执行一次目标节点，输出通过 Channel 或 Reducer 合并。
+// 并发数由 WithScheduler 配置的 executor.Scheduler 限制；
+// 每个 Send 都记录在检查点中，失败后恢复时已完成的 Send 不会重复执行：
+//
+//	graph.WithChannel(state.NewAppendChannel("Summaries"))
+//	graph.WithScheduler(executor.NewScheduler[DocState]().WithMaxConcurrent(4))
+//	graph.AddSendEdges("split", func(s DocState) []state.Send[DocState] {
+//	    var sends []state.Send[DocState]
+//	    for _, doc := range s.Docs {
+//	        sends = append(sends, state.NewSend("summarize", DocState{Doc: doc}))
+//	    }
+//	    return sends
+//	}, "summarize")
+//	graph.AddEdge("summarize", "combine")
+//
 // # Checkpointing (持久化)
 //
-// 配置检查点支持 Time Travel 和恢复：
+// 配置检查点后，每个节点完成时都会写入检查点。
+// 同一线程的下一次执行会从最后完成的节点继续，进程重启后也能恢复：
 //
-//	checkpointer := checkpoint.NewMemorySaver()
-//	graph.WithCheckpointer(checkpointer).
-//	    WithDurability(durability.ModeSync)
+//	checkpointer := checkpoint.NewMemoryCheckpointSaver[MyState]()
+//	graph.WithCheckpointer(checkpointer)
 //
 //	compiled, _ := graph.Compile()
 //	result, _ := compiled.Invoke(ctx, initialState,
-//	    execute.WithThreadID("user-123"))
+//	    state.WithThreadID("user-123"))
 //
 //	// 查看历史
 //	history, _ := compiled.GetHistory(ctx, "user-123", 10)
 //
+// 时间旅行：从任意历史检查点重新执行，或写入修正后的状态形成新分支：
+//
+//	result, _ = compiled.Invoke(ctx, initialState,
+//	    state.WithThreadID("user-123"),
+//	    state.WithCheckpointID(history[2].CheckpointID))
+//
+//	compiled.UpdateState(ctx, "user-123", fixedState, "plan")
+//
+// 写入时机由 WithDurability 控制（sync/async/exit），
+// 失败时总会写入失败前的状态，恢复时已完成的节点不会重复执行。
+// 持久化任务完成后立即写入它的输出，同一步的其他节点失败或进程崩溃后，
+// 恢复时已完成的任务不会重复执行；非幂等的持久化任务（ExactlyOnce 模式下为
+// 所有持久化任务）所在的步骤还不受检查点间隔限制，总是同步写入：
+//
+//	graph.WithDurability(durability.ModeAsync)
+//
+// # Human-in-the-Loop (中断与恢复)
+//
+// 编译时指定中断点，或在节点内调用 Interrupt 动态中断。
+// 中断时保存检查点并返回 *InterruptError，之后同一线程可以（在任意进程中）恢复：
+//
+//	compiled, _ := graph.Compile(state.InterruptBefore("send_email"))
+//
+//	_, err := compiled.Invoke(ctx, initialState, state.WithThreadID("user-123"))
+//	if errors.Is(err, hitl.ErrInterrupted) {
+//	    pending, _ := compiled.PendingInterrupts(ctx, "user-123")
+//	    // ... 展示给人类审批
+//	    result, err = compiled.ResumeWithInput(ctx, "user-123", "approved")
+//	}
+//
+// # Subgraphs (子图)
+//
+// 已编译的图可以作为节点嵌入另一个图。状态类型相同时使用 AddSubgraph，
+// 不同时用 node.NewSubgraphNode 配置状态映射后调用 AddGraphNode：
+//
+//	graph.AddSubgraph("review", reviewGraph)
+//	graph.AddGraphNode(node.NewSubgraphNode[ParentState, ChildState]("research", researchGraph,
+//	    node.WithStateMapper(toChild, fromChild),
+//	))
+//
+// 子图在父图的线程中执行，检查点写入以节点路径为命名空间（CheckpointNS，
+// 例如 "review" 或 "outer.review"）的检查点中。子图中的中断会传递给父图，
+// 通过父图的 PendingInterrupts 查看、Resume / ResumeWithInput 恢复。
+// 同类型的子图没有配置检查点保存器时使用父图的；状态类型不同的子图需要配置
+// 自己的检查点保存器（可以与父图共用存储），否则父图配置了检查点保存器时
+// Compile 返回 ErrNoCheckpointer。
+//
+// # Visualization (可视化)
+//
+// 已编译的图可以直接导出为 Mermaid、DOT、ASCII 或 JSON：
+//
+//	mermaid := compiled.Visualize().ToMermaid()
+//	dot, _ := compiled.Draw(visualization.FormatDOT)
+//
+// 通过 WithTracer 记录一次执行实际经过的节点和边，失败、重试次数和中断
+// 会标注在节点上；也可以用 TraceHistory 从检查点历史还原已完成的执行：
+//
+//	tracer := compiled.NewTracer()
+//	compiled.Invoke(ctx, initialState, state.WithTracer(tracer))
+//	fmt.Println(tracer.ToMermaidWithPath())
+//
+//	tracer, _ = compiled.TraceHistory(ctx, "user-123")
+//	out, _ := visualization.ExportWithPath(tracer, visualization.FormatJSON)
+//
+// # Streaming (流式执行)
+//
+// Stream 在后台执行图，通过 channel 输出执行过程：
+//   - StreamModeValues: 每个超步完成后的完整状态
+//   - StreamModeUpdates: 每个节点相对于本步输入的变化
+//   - StreamModeDebug: 节点开始、结束、失败事件及耗时
+//
+// 节点中调用的 ChatModel.Stream 产生的 token 在所有模式下都会以
+// StreamEventToken 事件转发（调用 Invoke 时整个响应作为一个 token 事件）：
+//
+//	events, _ := compiled.Stream(ctx, initialState, state.StreamModeUpdates)
+//	for event := range events {
+//	    switch event.Type {
+//	    case state.StreamEventUpdates:
+//	        fmt.Println(event.Node, event.Changes)
+//	    case state.StreamEventToken:
+//	        fmt.Print(event.Token.Data.(types.Message).Content)
+//	    }
+//	}
+//
+// # Callbacks (回调)
+//
+// 图的每次执行是一次链运行，每个节点是它的子运行（OnNodeStart / OnNodeEnd），
+// 节点中的模型、工具和检索器调用又是节点的子运行。处理器通过 WithCallbacks
+// 或 callbacks.WithHandlers 传入：
+//
+//	result, err := compiled.Invoke(ctx, initialState, state.WithCallbacks(handler))
+//
 // # 特殊常量
 //
 // StateGraph 定义了两个特殊的节点名称：
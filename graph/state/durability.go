@@ -0,0 +1,338 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/zhucl121/langchain-go/graph/checkpoint"
+	"github.com/zhucl121/langchain-go/graph/durability"
+)
+
+// defaultDurabilityConfig 返回未配置 Durability 时使用的默认配置。
+//
+// 默认每步同步写入检查点，节点不重试。
+//
+func defaultDurabilityConfig() *durability.DurabilityConfig {
+	return durability.NewDurabilityConfig(durability.AtLeastOnce).
+		WithPersistMode(durability.ModeSync).
+		WithMaxRetries(0)
+}
+
+// persister 按 durability.PersistMode 调度检查点写入。
+//
+// 写入策略：
+//   - ModeSync: 每个检查点步同步写入，写入完成后才执行下一步
+//   - ModeAsync: 每个检查点步交给后台协程按顺序写入，执行结束前等待全部写完
+//   - ModeExit: 执行过程中不写入，仅在完成或失败时写入
+//
+// 检查点步由 DurabilityConfig.CheckpointInterval 和 DurabilityMode 决定
+// （见 durability.ExecutionContext.ShouldCheckpoint）。
+// 无论哪种模式，执行失败时都会同步写入失败前的状态，
+// 恢复时从失败的节点继续，已完成的节点不会重复执行。
+//
+// 持久化任务（AddTask 添加）与其他任务并行时，在 sync/async 模式下任务一完成
+// 就写入它的输出（检查点元数据 writes），进程在同一步的其他任务完成前崩溃，
+// 恢复时也不会再执行它。完成后不能重复执行的持久化任务（见 exactlyOnceTask）
+// 还不受检查点间隔限制：所在的步骤总是同步写入。
+//
+type persister[S any] struct {
+	cp      *threadCheckpointer[S]
+	mode    durability.PersistMode
+	execCtx *durability.ExecutionContext
+
+	// last 是最近一步的写入内容，saved 表示它是否已写入
+	last  checkpointWrite[S]
+	saved bool
+
+	// from 是执行的起点检查点（为空时使用最新检查点）
+	from string
+
+	// resumed 是本次执行继续的未完成检查点（新的执行为 nil）
+	resumed *checkpoint.Checkpoint[S]
+
+	// 异步写入
+	queue    chan checkpointWrite[S]
+	wg       sync.WaitGroup
+	asyncErr error
+	mu       sync.Mutex
+}
+
+// newPersister 创建检查点写入调度器。
+func newPersister[S any](
+	saver checkpoint.CheckpointSaver[S],
+	threadID string,
+	ns string,
+	execCtx *durability.ExecutionContext,
+) *persister[S] {
+	mode := execCtx.Config.PersistMode
+	if mode == "" {
+		mode = durability.ModeSync
+	}
+
+	return &persister[S]{
+		cp:      newThreadCheckpointer(saver, threadID, ns),
+		mode:    mode,
+		execCtx: execCtx,
+		saved:   true,
+	}
+}
+
+// start 确定执行起点。
+//
+// 如果起点检查点（默认为最新检查点）的执行未完成，返回检查点中的状态和下一步；
+// 否则写入输入检查点，从入口节点开始。
+// 从较早的检查点开始时，新的检查点以它为父节点，形成新的分支。
+//
+// 返回：
+//   - checkpointWrite[S]: 起点（状态、已完成的步数、第一步的节点和 Send）
+//   - error: 读写错误
+//
+func (p *persister[S]) start(ctx context.Context, input S, entry []string) (checkpointWrite[S], error) {
+	cp, err := p.cp.resume(ctx, p.from)
+	if err != nil {
+		return checkpointWrite[S]{state: input, next: entry}, err
+	}
+
+	step := 0
+	if cp != nil {
+		step = metadataInt(cp.Metadata["step"])
+		next := metadataStrings(cp.Metadata[metadataNextKey])
+		sends := metadataSends[S](cp.Metadata[metadataSendsKey])
+		if len(next) > 0 || len(sends) > 0 {
+			// 上一次执行未完成，从检查点继续
+			nodeName, _ := cp.Metadata["node_name"].(string)
+			p.resumed = cp
+			p.last = checkpointWrite[S]{
+				state:    cp.State,
+				source:   SourceLoop,
+				step:     step,
+				nodeName: nodeName,
+				next:     next,
+				sends:    sends,
+				writes:   metadataWrites[S](cp.Metadata[metadataWritesKey]),
+			}
+			return p.last, nil
+		}
+
+		// 新的执行，接在该线程已有的检查点链之后
+		step++
+	}
+
+	return p.begin(ctx, input, entry, step)
+}
+
+// restart 放弃继续执行的检查点，在其后开始新的执行。
+//
+// 用于子图：父图重新执行子图节点（而不是从中断恢复）时，
+// 子图中上一次执行遗留的中断已经失效。
+//
+func (p *persister[S]) restart(ctx context.Context, input S, entry []string) (checkpointWrite[S], error) {
+	step := p.last.step + 1
+	p.resumed = nil
+	return p.begin(ctx, input, entry, step)
+}
+
+// begin 写入输入检查点，从入口节点开始新的执行。
+func (p *persister[S]) begin(ctx context.Context, input S, entry []string, step int) (checkpointWrite[S], error) {
+	write := checkpointWrite[S]{
+		state:  input,
+		source: SourceInput,
+		step:   step,
+		next:   entry,
+	}
+	return write, p.stepDone(ctx, write)
+}
+
+// stepDone 在一步完成后调用。
+//
+// 参数：
+//   - ctx: 上下文
+//   - write: 这一步的检查点内容
+//   - tasks: 这一步执行的持久化任务（普通节点不包含在内）
+//
+// 在 sync/async 模式下，包含不能重复执行的持久化任务的步骤总是同步写入，
+// 保证进程崩溃后不会被重放；其他步骤遵循检查点间隔，
+// 崩溃后可能从上一个检查点重新执行。
+//
+func (p *persister[S]) stepDone(ctx context.Context, write checkpointWrite[S], tasks ...*durability.DurableTask[S]) error {
+	p.last = write
+	p.saved = false
+
+	if p.mode == durability.ModeExit {
+		return nil
+	}
+
+	for _, task := range tasks {
+		if exactlyOnceTask(p.execCtx, task) {
+			return p.saveSync(ctx, write)
+		}
+	}
+
+	if !p.execCtx.ShouldCheckpoint(write.step) {
+		return nil
+	}
+
+	if p.mode == durability.ModeAsync {
+		return p.saveAsync(ctx, write)
+	}
+
+	return p.saveSync(ctx, write)
+}
+
+// record 在超步执行中途调用，写入本步已完成的持久化任务的输出。
+//
+// write 的状态为本步输入，下一步仍是本步的节点和 Send，
+// write.writes 是已完成任务的输出，恢复时这些任务不再执行。
+// ModeExit 模式下不写入。
+//
+func (p *persister[S]) record(ctx context.Context, write checkpointWrite[S]) error {
+	if p.mode == durability.ModeExit {
+		return nil
+	}
+
+	return p.saveSync(ctx, write)
+}
+
+// finish 在执行完成后调用，写入最终检查点并等待后台写入结束。
+func (p *persister[S]) finish(ctx context.Context) error {
+	if !p.saved {
+		if err := p.saveSync(ctx, p.last); err != nil {
+			return err
+		}
+	}
+
+	return p.wait()
+}
+
+// pause 在执行被中断后调用。
+//
+// 无论哪种写入模式，都同步写入中断检查点并等待后台写入结束，
+// 保证返回后可以在其他进程中恢复。
+//
+func (p *persister[S]) pause(ctx context.Context, write checkpointWrite[S]) error {
+	p.last = write
+	if err := p.saveSync(context.WithoutCancel(ctx), write); err != nil {
+		return err
+	}
+
+	return p.wait()
+}
+
+// fail 在执行失败后调用。
+//
+// 写入失败步骤执行前的状态，下一步指向失败步骤的节点和 Send
+// （write.writes 中已完成的 Send 恢复后不再执行）。
+// 写入使用不会被取消的上下文，以便在 ctx 取消时也能保存进度。
+//
+// 参数：
+//   - ctx: 上下文
+//   - write: 失败步骤的状态、步数、节点、Send 以及错误
+//
+// 返回：
+//   - error: 原始错误（写入失败时与写入错误合并）
+//
+func (p *persister[S]) fail(ctx context.Context, write checkpointWrite[S]) error {
+	write.source = SourceLoop
+	write.nodeName = p.last.nodeName
+
+	if err := p.saveSync(context.WithoutCancel(ctx), write); err != nil {
+		return errors.Join(write.err, err)
+	}
+
+	return write.err
+}
+
+// saveSync 同步写入检查点（先等待之前的后台写入完成，保证顺序）。
+func (p *persister[S]) saveSync(ctx context.Context, write checkpointWrite[S]) error {
+	if err := p.wait(); err != nil {
+		return err
+	}
+
+	if err := p.cp.save(ctx, write); err != nil {
+		return err
+	}
+
+	p.saved = true
+	return nil
+}
+
+// saveAsync 将检查点交给后台协程写入。
+func (p *persister[S]) saveAsync(ctx context.Context, write checkpointWrite[S]) error {
+	if err := p.getAsyncErr(); err != nil {
+		return err
+	}
+
+	if p.queue == nil {
+		p.queue = make(chan checkpointWrite[S], 16)
+		p.wg.Add(1)
+		go p.writeLoop(context.WithoutCancel(ctx))
+	}
+
+	p.queue <- write
+	p.saved = true
+	return nil
+}
+
+// writeLoop 按顺序写入后台队列中的检查点。
+func (p *persister[S]) writeLoop(ctx context.Context) {
+	defer p.wg.Done()
+
+	for write := range p.queue {
+		if p.getAsyncErr() != nil {
+			continue
+		}
+
+		if err := p.cp.save(ctx, write); err != nil {
+			p.mu.Lock()
+			p.asyncErr = err
+			p.mu.Unlock()
+		}
+	}
+}
+
+// wait 等待所有后台写入完成。
+func (p *persister[S]) wait() error {
+	if p.queue != nil {
+		close(p.queue)
+		p.wg.Wait()
+		p.queue = nil
+	}
+
+	return p.getAsyncErr()
+}
+
+// getAsyncErr 返回后台写入的第一个错误。
+func (p *persister[S]) getAsyncErr() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.asyncErr
+}
+
+// exactlyOnceTask 判断持久化任务完成后是否不能在恢复时重复执行。
+//
+// 非幂等任务总是如此；ExactlyOnce 模式下所有持久化任务都是如此。
+//
+func exactlyOnceTask[S any](execCtx *durability.ExecutionContext, task *durability.DurableTask[S]) bool {
+	return !task.IsIdempotent || execCtx.Config.Mode.NeedsDeduplication()
+}
+
+// executeNode 执行单个节点。
+//
+// 通过 AddTask 注册的节点以持久化任务方式执行，应用任务自身的
+// 重试策略和超时；普通节点直接调用节点函数。
+// 持久化任务的 ID 应为本次调度的标识（见 scheduledTask），
+// 每次调度在 execCtx 中有各自的执行记录。
+//
+func executeNode[S any](
+	ctx context.Context,
+	node Node[S],
+	state S,
+	execCtx *durability.ExecutionContext,
+) (S, error) {
+	if node.Task == nil {
+		return node.Func(ctx, state)
+	}
+
+	return node.Task.Execute(ctx, state, execCtx)
+}
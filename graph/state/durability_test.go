@@ -0,0 +1,462 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zhucl121/langchain-go/graph/checkpoint"
+	"github.com/zhucl121/langchain-go/graph/durability"
+)
+
+// newDurabilityTestGraph 创建 step1 -> step2 -> step3 的测试图，step1 是持久化任务
+func newDurabilityTestGraph(
+	saver checkpoint.CheckpointSaver[TestState],
+	config *durability.DurabilityConfig,
+	failStep2 *bool,
+	calls map[string]int,
+) *CompiledGraph[TestState] {
+	graph := NewStateGraph[TestState]("durability-test")
+
+	graph.AddTask(durability.NewDurableTask("step1", func(ctx context.Context, s TestState) (TestState, error) {
+		calls["step1"]++
+		s.Counter += 1
+		return s, nil
+	}).WithIdempotent(true))
+	graph.AddNode("step2", func(ctx context.Context, s TestState) (TestState, error) {
+		calls["step2"]++
+		if *failStep2 {
+			return s, errors.New("step2 failed")
+		}
+		s.Counter += 10
+		return s, nil
+	})
+	graph.AddNode("step3", func(ctx context.Context, s TestState) (TestState, error) {
+		calls["step3"]++
+		s.Counter += 100
+		return s, nil
+	})
+
+	graph.SetEntryPoint("step1")
+	graph.AddEdge("step1", "step2")
+	graph.AddEdge("step2", "step3")
+	graph.AddEdge("step3", END)
+	graph.WithCheckpointer(saver)
+	graph.WithDurabilityConfig(config)
+
+	compiled, _ := graph.Compile()
+	return compiled
+}
+
+// checkpointNodes 返回线程内各检查点的节点名称
+func checkpointNodes(t *testing.T, saver checkpoint.CheckpointSaver[TestState], threadID string) []any {
+	t.Helper()
+
+	checkpoints, err := saver.List(context.Background(), threadID)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+
+	nodes := make([]any, len(checkpoints))
+	for i, cp := range checkpoints {
+		nodes[i] = cp.Metadata["node_name"]
+	}
+	return nodes
+}
+
+func assertNodes(t *testing.T, got []any, want ...string) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("expected checkpoints %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected checkpoints %v, got %v", want, got)
+		}
+	}
+}
+
+// TestDurability_AsyncFlushesBeforeReturn 测试 async 模式在返回前写完所有检查点
+func TestDurability_AsyncFlushesBeforeReturn(t *testing.T) {
+	saver := checkpoint.NewMemoryCheckpointSaver[TestState]()
+	config := defaultDurabilityConfig().WithPersistMode(durability.ModeAsync)
+	fail := false
+	compiled := newDurabilityTestGraph(saver, config, &fail, make(map[string]int))
+
+	ctx := context.Background()
+	if _, err := compiled.Invoke(ctx, TestState{}, WithThreadID("thread-1")); err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+
+	assertNodes(t, checkpointNodes(t, saver, "thread-1"), "", "step1", "step2", "step3")
+
+	checkpoints, _ := saver.List(ctx, "thread-1")
+	for i := 1; i < len(checkpoints); i++ {
+		if checkpoints[i].ParentID != checkpoints[i-1].ID {
+			t.Errorf("checkpoint %d: broken parent chain", i)
+		}
+	}
+}
+
+// TestDurability_ExitPersistsOnlyOnCompletion 测试 exit 模式只在完成时写入
+func TestDurability_ExitPersistsOnlyOnCompletion(t *testing.T) {
+	saver := checkpoint.NewMemoryCheckpointSaver[TestState]()
+	config := defaultDurabilityConfig().WithPersistMode(durability.ModeExit)
+	fail := false
+	compiled := newDurabilityTestGraph(saver, config, &fail, make(map[string]int))
+
+	result, err := compiled.Invoke(context.Background(), TestState{}, WithThreadID("thread-1"))
+	if err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+	if result.Counter != 111 {
+		t.Errorf("expected Counter=111, got %d", result.Counter)
+	}
+
+	assertNodes(t, checkpointNodes(t, saver, "thread-1"), "step3")
+}
+
+// TestDurability_ExitPersistsOnFailure 测试 exit 模式在失败时写入，
+// 恢复时跳过已完成的任务
+func TestDurability_ExitPersistsOnFailure(t *testing.T) {
+	saver := checkpoint.NewMemoryCheckpointSaver[TestState]()
+	config := defaultDurabilityConfig().WithPersistMode(durability.ModeExit)
+	fail := true
+	calls := make(map[string]int)
+	compiled := newDurabilityTestGraph(saver, config, &fail, calls)
+
+	ctx := context.Background()
+	if _, err := compiled.Invoke(ctx, TestState{}, WithThreadID("thread-1")); err == nil {
+		t.Fatal("expected error from step2")
+	}
+
+	checkpoints, _ := saver.List(ctx, "thread-1")
+	if len(checkpoints) != 1 {
+		t.Fatalf("expected 1 checkpoint, got %d", len(checkpoints))
+	}
+
+	failed := checkpoints[0]
+	if failed.State.Counter != 1 {
+		t.Errorf("expected saved Counter=1, got %d", failed.State.Counter)
+	}
+	if next := metadataStrings(failed.Metadata[metadataNextKey]); len(next) != 1 || next[0] != "step2" {
+		t.Errorf("expected next [step2], got %v", next)
+	}
+	if failed.Metadata[metadataErrorKey] == nil {
+		t.Error("expected error recorded in metadata")
+	}
+
+	fail = false
+	result, err := compiled.Invoke(ctx, TestState{}, WithThreadID("thread-1"))
+	if err != nil {
+		t.Fatalf("resume failed: %v", err)
+	}
+	if result.Counter != 111 {
+		t.Errorf("expected Counter=111, got %d", result.Counter)
+	}
+	if calls["step1"] != 1 {
+		t.Errorf("step1 should not run again during recovery, ran %d times", calls["step1"])
+	}
+}
+
+// TestDurability_CheckpointInterval 测试按间隔写入检查点
+func TestDurability_CheckpointInterval(t *testing.T) {
+	saver := checkpoint.NewMemoryCheckpointSaver[TestState]()
+	config := defaultDurabilityConfig().WithCheckpointInterval(2)
+	fail := false
+	compiled := newDurabilityTestGraph(saver, config, &fail, make(map[string]int))
+
+	if _, err := compiled.Invoke(context.Background(), TestState{}, WithThreadID("thread-1")); err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+
+	// 第 0、2 步按间隔写入，最后一步在完成时写入
+	assertNodes(t, checkpointNodes(t, saver, "thread-1"), "", "step2", "step3")
+}
+
+// TestDurability_NonIdempotentTaskForcesCheckpoint 测试非幂等任务完成后总是写入
+func TestDurability_NonIdempotentTaskForcesCheckpoint(t *testing.T) {
+	saver := checkpoint.NewMemoryCheckpointSaver[TestState]()
+
+	graph := NewStateGraph[TestState]("charge")
+	graph.AddNode("prepare", func(ctx context.Context, s TestState) (TestState, error) {
+		return s, nil
+	})
+	graph.AddTask(durability.NewDurableTask("charge", func(ctx context.Context, s TestState) (TestState, error) {
+		s.Counter++
+		return s, nil
+	}))
+	graph.AddNode("notify", func(ctx context.Context, s TestState) (TestState, error) {
+		return s, nil
+	})
+	graph.SetEntryPoint("prepare")
+	graph.AddEdge("prepare", "charge")
+	graph.AddEdge("charge", "notify")
+	graph.AddEdge("notify", END)
+	graph.WithCheckpointer(saver)
+	graph.WithDurabilityConfig(defaultDurabilityConfig().WithCheckpointInterval(10))
+
+	compiled, err := graph.Compile()
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	if _, err := compiled.Invoke(context.Background(), TestState{}, WithThreadID("thread-1")); err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+
+	assertNodes(t, checkpointNodes(t, saver, "thread-1"), "", "charge", "notify")
+}
+
+// crashSaver 模拟进程崩溃：crashed 之后的写入全部丢失
+type crashSaver struct {
+	*checkpoint.MemoryCheckpointSaver[TestState]
+	crashed atomic.Bool
+}
+
+func (s *crashSaver) Save(ctx context.Context, cp *checkpoint.Checkpoint[TestState]) error {
+	if s.crashed.Load() {
+		return errors.New("process crashed")
+	}
+	return s.MemoryCheckpointSaver.Save(ctx, cp)
+}
+
+// TestDurability_ExactlyOnceSurvivesCrash 测试 ExactlyOnce 模式下进程崩溃后
+// 恢复时不重复执行已完成的任务（不受检查点间隔和写入模式影响）
+func TestDurability_ExactlyOnceSurvivesCrash(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *durability.DurabilityConfig
+	}{
+		{"sync with interval", defaultDurabilityConfig().WithCheckpointInterval(10)},
+		{"async", defaultDurabilityConfig().WithPersistMode(durability.ModeAsync).WithCheckpointInterval(10)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			saver := &crashSaver{MemoryCheckpointSaver: checkpoint.NewMemoryCheckpointSaver[TestState]()}
+			tt.config.Mode = durability.ExactlyOnce
+			charges, crash := 0, true
+
+			graph := NewStateGraph[TestState]("order")
+			graph.AddTask(durability.NewDurableTask("charge", func(ctx context.Context, s TestState) (TestState, error) {
+				charges++
+				s.Counter++
+				return s, nil
+			}).WithIdempotent(true))
+			graph.AddNode("ship", func(ctx context.Context, s TestState) (TestState, error) {
+				if crash {
+					saver.crashed.Store(true)
+					return s, errors.New("killed")
+				}
+				s.Message = "shipped"
+				return s, nil
+			})
+			graph.SetEntryPoint("charge")
+			graph.AddEdge("charge", "ship")
+			graph.AddEdge("ship", END)
+			graph.WithCheckpointer(saver)
+			graph.WithDurabilityConfig(tt.config)
+
+			compiled, err := graph.Compile()
+			if err != nil {
+				t.Fatalf("Compile failed: %v", err)
+			}
+
+			ctx := context.Background()
+			if _, err := compiled.Invoke(ctx, TestState{}, WithThreadID("thread-1")); err == nil {
+				t.Fatal("expected crash")
+			}
+
+			// 重启后恢复
+			saver.crashed.Store(false)
+			crash = false
+			result, err := compiled.Invoke(ctx, TestState{}, WithThreadID("thread-1"))
+			if err != nil {
+				t.Fatalf("resume failed: %v", err)
+			}
+			if charges != 1 {
+				t.Errorf("charge should run exactly once, ran %d times", charges)
+			}
+			if result.Counter != 1 || result.Message != "shipped" {
+				t.Errorf("unexpected result: %+v", result)
+			}
+		})
+	}
+}
+
+// TestDurability_ParallelCrash 测试与其他节点并行的持久化任务完成后立即写入，
+// 同一步的其他节点导致进程崩溃时，恢复后不再执行该任务（幂等任务也是如此）
+func TestDurability_ParallelCrash(t *testing.T) {
+	tests := []struct {
+		name string
+		mode durability.DurabilityMode
+	}{
+		{"idempotent at least once", durability.AtLeastOnce},
+		{"exactly once", durability.ExactlyOnce},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testParallelCrash(t, tt.mode)
+		})
+	}
+}
+
+// testParallelCrash 执行 start -> (charge, ship) 的图，charge 为幂等的持久化任务，
+// ship 在 charge 完成后模拟进程崩溃
+func testParallelCrash(t *testing.T, mode durability.DurabilityMode) {
+	saver := &crashSaver{MemoryCheckpointSaver: checkpoint.NewMemoryCheckpointSaver[TestState]()}
+	ctx := context.Background()
+	var charges atomic.Int32
+	crash := true
+
+	// recorded 判断 charge 的输出是否已写入检查点
+	recorded := func() bool {
+		checkpoints, _ := saver.List(ctx, "thread-1")
+		for _, cp := range checkpoints {
+			if _, ok := metadataWrites[TestState](cp.Metadata[metadataWritesKey])["charge"]; ok {
+				return true
+			}
+		}
+		return false
+	}
+
+	graph := NewStateGraph[TestState]("order")
+	graph.AddNode("start", func(ctx context.Context, s TestState) (TestState, error) {
+		return s, nil
+	})
+	graph.AddTask(durability.NewDurableTask("charge", func(ctx context.Context, s TestState) (TestState, error) {
+		charges.Add(1)
+		s.Counter++
+		return s, nil
+	}).WithIdempotent(true))
+	graph.AddNode("ship", func(ctx context.Context, s TestState) (TestState, error) {
+		if !crash {
+			s.Message = "shipped"
+			return s, nil
+		}
+
+		deadline := time.After(time.Second)
+		for !recorded() {
+			select {
+			case <-deadline:
+				return s, errors.New("charge was not recorded")
+			case <-time.After(time.Millisecond):
+			}
+		}
+		saver.crashed.Store(true)
+		return s, errors.New("killed")
+	})
+	graph.SetEntryPoint("start")
+	graph.AddEdge("start", "charge")
+	graph.AddEdge("start", "ship")
+	graph.AddEdge("charge", END)
+	graph.AddEdge("ship", END)
+	graph.WithCheckpointer(saver)
+
+	config := defaultDurabilityConfig().WithCheckpointInterval(10)
+	config.Mode = mode
+	graph.WithDurabilityConfig(config)
+
+	compiled, err := graph.Compile()
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	if _, err := compiled.Invoke(ctx, TestState{}, WithThreadID("thread-1")); err == nil {
+		t.Fatal("expected crash")
+	}
+
+	saver.crashed.Store(false)
+	crash = false
+	result, err := compiled.Invoke(ctx, TestState{}, WithThreadID("thread-1"))
+	if err != nil {
+		t.Fatalf("resume failed: %v", err)
+	}
+	if n := charges.Load(); n != 1 {
+		t.Errorf("charge should run exactly once, ran %d times", n)
+	}
+	if result.Counter != 1 || result.Message != "shipped" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
+
+// TestDurability_TaskRetry 测试节点级重试
+func TestDurability_TaskRetry(t *testing.T) {
+	attempts := 0
+	policy := durability.NewRetryPolicy(2)
+	policy.InitialDelay = time.Millisecond
+
+	graph := NewStateGraph[TestState]("retry")
+	graph.AddTask(durability.NewDurableTask("flaky", func(ctx context.Context, s TestState) (TestState, error) {
+		attempts++
+		if attempts < 3 {
+			return s, errors.New("temporary error")
+		}
+		s.Counter++
+		return s, nil
+	}).WithRetryPolicy(policy))
+	graph.SetEntryPoint("flaky")
+	graph.AddEdge("flaky", END)
+
+	compiled, err := graph.Compile()
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	result, err := compiled.Invoke(context.Background(), TestState{})
+	if err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+	if attempts != 3 || result.Counter != 1 {
+		t.Errorf("expected 3 attempts and Counter=1, got %d attempts and Counter=%d", attempts, result.Counter)
+	}
+}
+
+// TestDurability_TaskTimeout 测试节点级超时
+func TestDurability_TaskTimeout(t *testing.T) {
+	graph := NewStateGraph[TestState]("timeout")
+	graph.AddTask(durability.NewDurableTask("slow", func(ctx context.Context, s TestState) (TestState, error) {
+		select {
+		case <-ctx.Done():
+			return s, ctx.Err()
+		case <-time.After(time.Second):
+			return s, nil
+		}
+	}).WithRetryPolicy(durability.NewRetryPolicy(0)).WithTimeout(10 * time.Millisecond))
+	graph.SetEntryPoint("slow")
+	graph.AddEdge("slow", END)
+
+	compiled, err := graph.Compile()
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	start := time.Now()
+	_, err = compiled.Invoke(context.Background(), TestState{})
+	if !errors.Is(err, durability.ErrMaxRetriesReached) {
+		t.Fatalf("expected ErrMaxRetriesReached, got %v", err)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Error("task timeout was not applied")
+	}
+}
+
+// TestDurability_InvalidPersistMode 测试编译时校验写入模式
+func TestDurability_InvalidPersistMode(t *testing.T) {
+	graph := NewStateGraph[TestState]("invalid")
+	graph.AddNode("a", func(ctx context.Context, s TestState) (TestState, error) {
+		return s, nil
+	})
+	graph.SetEntryPoint("a")
+	graph.AddEdge("a", END)
+	graph.WithDurability(durability.PersistMode("sometimes"))
+
+	if _, err := graph.Compile(); !errors.Is(err, durability.ErrInvalidPersistMode) {
+		t.Errorf("expected ErrInvalidPersistMode, got %v", err)
+	}
+}
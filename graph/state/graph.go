@@ -4,6 +4,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+
+	"github.com/zhucl121/langchain-go/core/callbacks"
+	"github.com/zhucl121/langchain-go/graph/checkpoint"
+	"github.com/zhucl121/langchain-go/graph/compile"
+	"github.com/zhucl121/langchain-go/graph/durability"
+	"github.com/zhucl121/langchain-go/graph/edge"
+	"github.com/zhucl121/langchain-go/graph/executor"
+	"github.com/zhucl121/langchain-go/graph/hitl"
 )
 
 // 特殊节点名称常量
@@ -17,17 +26,22 @@ const (
 
 // 错误定义
 var (
-	ErrEmptyGraphName      = errors.New("state: graph name cannot be empty")
-	ErrNodeNotFound        = errors.New("state: node not found")
-	ErrNodeAlreadyExists   = errors.New("state: node already exists")
-	ErrNoEntryPoint        = errors.New("state: entry point not set")
-	ErrInvalidEntryPoint   = errors.New("state: invalid entry point")
-	ErrInvalidEdge         = errors.New("state: invalid edge")
-	ErrCyclicGraph         = errors.New("state: cyclic graph detected")
-	ErrUnreachableNodes    = errors.New("state: unreachable nodes detected")
-	ErrGraphNotCompiled    = errors.New("state: graph not compiled")
-	ErrEmptyNodeName       = errors.New("state: node name cannot be empty")
-	ErrReservedNodeName    = errors.New("state: node name is reserved")
+	ErrEmptyGraphName       = errors.New("state: graph name cannot be empty")
+	ErrNodeNotFound         = errors.New("state: node not found")
+	ErrNodeAlreadyExists    = errors.New("state: node already exists")
+	ErrNoEntryPoint         = errors.New("state: entry point not set")
+	ErrInvalidEntryPoint    = errors.New("state: invalid entry point")
+	ErrInvalidEdge          = errors.New("state: invalid edge")
+	ErrCyclicGraph          = errors.New("state: cyclic graph detected")
+	ErrUnreachableNodes     = errors.New("state: unreachable nodes detected")
+	ErrGraphNotCompiled     = errors.New("state: graph not compiled")
+	ErrEmptyNodeName        = errors.New("state: node name cannot be empty")
+	ErrReservedNodeName     = errors.New("state: node name is reserved")
+	ErrInvalidChannelUpdate = errors.New("state: invalid channel update")
+	ErrInvalidStreamMode    = errors.New("state: invalid stream mode")
+	ErrInvalidOption        = errors.New("state: invalid invoke option")
+	ErrInvalidInterrupt     = errors.New("state: invalid interrupt point")
+	ErrNoCheckpointer       = errors.New("state: checkpointer not configured")
 )
 
 // NodeFunc 是节点函数的类型。
@@ -43,10 +57,15 @@ type NodeFunc[S any] func(ctx context.Context, state S) (S, error)
 // Node 表示图中的一个节点。
 //
 // Node 包含节点的名称和执行函数。
+// 通过 AddTask 添加的节点还包含持久化任务，执行时应用任务的重试和超时设置。
 //
 type Node[S any] struct {
-	Name string
-	Func NodeFunc[S]
+	Name     string
+	Func     NodeFunc[S]
+	Task     *durability.DurableTask[S]
+	Subgraph bool // 通过 AddSubgraph 添加的子图节点
+
+	child any // 节点执行的子图（AddSubgraph / AddGraphNode 添加）
 }
 
 // Edge 表示图中的一条边。
@@ -76,6 +95,8 @@ type ConditionalEdge[S any] struct {
 // StateGraph 是 LangGraph 的 Go 实现，支持：
 //   - 节点和边的定义
 //   - 条件边和动态路由
+//   - 并行分支（fan-out / fan-in）和基于 Reducer/Channel 的状态合并
+//   - 动态调度（Send，map-reduce）
 //   - 检查点持久化
 //   - 持久化模式配置
 //   - Human-in-the-Loop
@@ -105,13 +126,17 @@ type StateGraph[S any] struct {
 	nodes        map[string]Node[S]
 	edges        []Edge
 	conditionals []ConditionalEdge[S]
+	branches     []*edge.BranchEdge[S]
+	sends        []SendEdge[S]
 	entryPoint   string
 	finishPoints map[string]bool
 
-	// LangGraph 1.0 新增功能（将在后续模块实现）
-	checkpointer interface{} // checkpoint.Saver (避免循环依赖，暂时用 interface{})
-	durability   interface{} // durability.Mode
-	channels     map[string]interface{} // Channel
+	// LangGraph 1.0 新增功能
+	checkpointer     checkpoint.CheckpointSaver[S]
+	durabilityConfig *durability.DurabilityConfig
+	channels         map[string]Channel
+	reducer          Reducer[S]
+	scheduler        *executor.Scheduler[S]
 }
 
 // NewStateGraph 创建一个新的状态图。
@@ -135,7 +160,7 @@ func NewStateGraph[S any](name string) *StateGraph[S] {
 		edges:        make([]Edge, 0),
 		conditionals: make([]ConditionalEdge[S], 0),
 		finishPoints: make(map[string]bool),
-		channels:     make(map[string]interface{}),
+		channels:     make(map[string]Channel),
 	}
 }
 
@@ -179,6 +204,41 @@ func (g *StateGraph[S]) AddNode(name string, fn NodeFunc[S]) *StateGraph[S] {
 	return g
 }
 
+// AddTask 以持久化任务的方式添加节点。
+//
+// 节点名称为 task.ID。执行时应用任务的重试策略（RetryPolicy）和超时
+// （Timeout，未设置时使用 DurabilityConfig.TimeoutPerTask）。
+// 任务完成后写入它的输出，恢复时已完成的任务不再执行；
+// 非幂等任务（ExactlyOnce 模式下为所有任务）所在的步骤还总是同步写入检查点，
+// 不受检查点间隔限制。
+//
+// 参数：
+//   - task: 持久化任务
+//
+// 返回：
+//   - *StateGraph[S]: 返回自身，支持链式调用
+//
+// 示例：
+//
+//	task := durability.NewDurableTask("charge", chargeFunc).
+//	    WithRetryPolicy(durability.NewRetryPolicy(5)).
+//	    WithTimeout(30 * time.Second)
+//	graph.AddTask(task)
+//
+func (g *StateGraph[S]) AddTask(task *durability.DurableTask[S]) *StateGraph[S] {
+	if task == nil {
+		panic(fmt.Errorf("state: task cannot be nil"))
+	}
+
+	g.AddNode(task.ID, NodeFunc[S](task.Func))
+
+	node := g.nodes[task.ID]
+	node.Task = task
+	g.nodes[task.ID] = node
+
+	return g
+}
+
 // AddEdge 添加一条从 from 到 to 的边。
 //
 // 参数：
@@ -236,6 +296,9 @@ func (g *StateGraph[S]) AddEdge(from, to string) *StateGraph[S] {
 // 返回：
 //   - *StateGraph[S]: 返回自身，支持链式调用
 //
+// 注意：
+//   - pathMap 的目标节点在 Compile 时验证
+//
 // 示例：
 //
 //	graph.AddConditionalEdges("agent",
@@ -273,14 +336,10 @@ func (g *StateGraph[S]) AddConditionalEdges(
 		panic(fmt.Errorf("%w: %s", ErrNodeNotFound, source))
 	}
 
-	// 验证 pathMap 中的所有目标节点存在（END 除外）
-	for pathName, target := range pathMap {
-		if target != END {
-			if _, exists := g.nodes[target]; !exists {
-				panic(fmt.Errorf("%w: target %s in path %s", ErrNodeNotFound, target, pathName))
-			}
-		} else {
-			// 如果目标是 END，标记 source 为可能的结束点
+	// 如果目标是 END，标记 source 为可能的结束点
+	// pathMap 中的其他目标在 Compile 时验证，目标节点可以稍后添加
+	for _, target := range pathMap {
+		if target == END {
 			g.finishPoints[source] = true
 		}
 	}
@@ -294,6 +353,50 @@ func (g *StateGraph[S]) AddConditionalEdges(
 	return g
 }
 
+// AddBranchEdge 添加分支边（并行 fan-out）。
+//
+// 源节点完成后，BranchEdge.Select 选中的所有目标在下一个超步中并行执行，
+// 它们的输出按 WithChannel / WithReducer 的配置合并。
+//
+// 参数：
+//   - branch: 分支边
+//
+// 返回：
+//   - *StateGraph[S]: 返回自身，支持链式调用
+//
+// 注意：
+//   - 源节点必须存在，分支目标在 Compile 时验证
+//
+// 示例：
+//
+//	graph.AddBranchEdge(edge.NewBranchEdge("plan",
+//	    map[string]string{"web": "search_web", "docs": "search_docs"},
+//	    func(s State) []string { return s.Sources },
+//	))
+//
+func (g *StateGraph[S]) AddBranchEdge(branch *edge.BranchEdge[S]) *StateGraph[S] {
+	if branch == nil {
+		panic(fmt.Errorf("state: branch edge cannot be nil"))
+	}
+
+	if err := branch.Validate(); err != nil {
+		panic(fmt.Errorf("state: %w", err))
+	}
+
+	if _, exists := g.nodes[branch.GetSource()]; !exists {
+		panic(fmt.Errorf("%w: %s", ErrNodeNotFound, branch.GetSource()))
+	}
+
+	for _, target := range branch.GetBranches() {
+		if target == END {
+			g.finishPoints[branch.GetSource()] = true
+		}
+	}
+
+	g.branches = append(g.branches, branch)
+	return g
+}
+
 // SetEntryPoint 设置图的入口点。
 //
 // 参数：
@@ -346,35 +449,108 @@ func (g *StateGraph[S]) SetFinishPoint(name string) *StateGraph[S] {
 
 // WithCheckpointer 配置检查点保存器（LangGraph 1.0）。
 //
+// 配置后，带 WithThreadID 的 Invoke 会在每个节点完成后写入检查点，
+// 同一线程的下一次 Invoke 从最后完成的节点继续执行。
+//
 // 参数：
-//   - saver: 检查点保存器实例
+//   - saver: 检查点保存器实例（Memory、SQLite、Postgres 等）
 //
 // 返回：
 //   - *StateGraph[S]: 返回自身，支持链式调用
 //
-// 注意：
-//   - 此功能将在 M38-M42 (Checkpoint 系统) 实现后启用
-//   - 目前仅保存配置，实际功能待后续模块实现
-//
-func (g *StateGraph[S]) WithCheckpointer(saver interface{}) *StateGraph[S] {
+func (g *StateGraph[S]) WithCheckpointer(saver checkpoint.CheckpointSaver[S]) *StateGraph[S] {
 	g.checkpointer = saver
 	return g
 }
 
-// WithDurability 配置持久化模式（LangGraph 1.0）。
+// WithDurability 配置检查点写入时机（LangGraph 1.0）。
 //
 // 参数：
-//   - mode: 持久化模式（exit/async/sync）
+//   - mode: 写入模式（exit/async/sync）
 //
 // 返回：
 //   - *StateGraph[S]: 返回自身，支持链式调用
 //
 // 注意：
-//   - 此功能将在 M43-M45 (Durability 系统) 实现后启用
-//   - 目前仅保存配置，实际功能待后续模块实现
+//   - 需要同时配置 WithCheckpointer 才会写入检查点
+//   - 未配置时默认为 durability.ModeSync
+//   - 需要调整检查点间隔、重试或超时时使用 WithDurabilityConfig
+//
+func (g *StateGraph[S]) WithDurability(mode durability.PersistMode) *StateGraph[S] {
+	if g.durabilityConfig == nil {
+		g.durabilityConfig = defaultDurabilityConfig()
+	}
+
+	g.durabilityConfig.PersistMode = mode
+	return g
+}
+
+// WithDurabilityConfig 配置完整的持久性策略。
+//
+// 参数：
+//   - config: 持久性配置（写入模式、检查点间隔、任务超时等）
+//
+// 返回：
+//   - *StateGraph[S]: 返回自身，支持链式调用
+//
+// 示例：
+//
+//	graph.WithDurabilityConfig(
+//	    durability.NewDurabilityConfig(durability.AtLeastOnce).
+//	        WithPersistMode(durability.ModeAsync).
+//	        WithCheckpointInterval(5),
+//	)
+//
+func (g *StateGraph[S]) WithDurabilityConfig(config *durability.DurabilityConfig) *StateGraph[S] {
+	g.durabilityConfig = config
+	return g
+}
+
+// WithChannel 为状态的一个字段注册通道，用于合并并行节点的更新。
+//
+// 通道名称对应结构体状态的导出字段名，或 map[string]V 状态的键。
+// 同一超步中多个节点写入该字段时，依次用各节点相对于本步输入的增量
+// 调用 Channel.Update（切片为追加的元素，map 为修改的键值，数值为差值）。
+// 未注册通道的字段由最后一个写入的节点（按节点名称排序）决定。
+//
+// 参数：
+//   - channel: 通道
+//
+// 返回：
+//   - *StateGraph[S]: 返回自身，支持链式调用
+//
+// 示例：
+//
+//	graph.WithChannel(state.NewAppendChannel("Results"))
+//	graph.WithChannel(state.NewReducerChannel("Total", state.SumReducer[int]()))
+//
+func (g *StateGraph[S]) WithChannel(channel Channel) *StateGraph[S] {
+	if channel == nil {
+		panic(fmt.Errorf("state: channel cannot be nil"))
+	}
+
+	g.channels[channel.GetName()] = channel
+	return g
+}
+
+// WithReducer 设置合并并行节点输出的归约器。
+//
+// 同一超步中有多个节点执行时，新状态为 reducer(本步输入, 各节点输出...)，
+// 输出按节点名称排序。设置后不再按字段使用 Channel 合并。
+//
+// 参数：
+//   - reducer: 归约器
+//
+// 返回：
+//   - *StateGraph[S]: 返回自身，支持链式调用
+//
+// 示例：
+//
+//	graph := state.NewStateGraph[map[string]any]("fanout")
+//	graph.WithReducer(state.MergeReducer())
 //
-func (g *StateGraph[S]) WithDurability(mode interface{}) *StateGraph[S] {
-	g.durability = mode
+func (g *StateGraph[S]) WithReducer(reducer Reducer[S]) *StateGraph[S] {
+	g.reducer = reducer
 	return g
 }
 
@@ -386,16 +562,28 @@ func (g *StateGraph[S]) WithDurability(mode interface{}) *StateGraph[S] {
 //   - 检查不可达节点
 //   - 构建执行计划
 //
+// 参数：
+//   - opts: 编译选项（例如 InterruptBefore、InterruptAfter）
+//
 // 返回：
 //   - *CompiledGraph[S]: 已编译的图
 //   - error: 编译错误
 //
 // 注意：
 //   - 图必须设置入口点
-//   - 图不能包含循环（除非有条件边）
-//   - 所有节点必须可达
-//
-func (g *StateGraph[S]) Compile() (*CompiledGraph[S], error) {
+//   - 所有节点必须可达，且都有出边（或是结束点）
+//   - 条件边的 pathMap 目标必须存在
+//   - 循环必须有能离开循环的条件边
+//   - 父图配置了检查点保存器时，状态类型不同的子图必须配置自己的检查点保存器
+//   - 结构问题以 *compile.ValidationError 一次全部返回，
+//     可以用 errors.Is(err, compile.ErrUnreachableNode) 等判断
+//
+func (g *StateGraph[S]) Compile(opts ...CompileOption) (*CompiledGraph[S], error) {
+	config := &CompileConfig{}
+	for _, opt := range opts {
+		opt(config)
+	}
+
 	// 验证入口点
 	if g.entryPoint == "" {
 		return nil, ErrNoEntryPoint
@@ -406,14 +594,39 @@ func (g *StateGraph[S]) Compile() (*CompiledGraph[S], error) {
 		return nil, fmt.Errorf("%w: %s", ErrInvalidEntryPoint, g.entryPoint)
 	}
 
-	// TODO: 在 M33-M34 (Compile 系统) 中实现完整的验证逻辑
-	// - 检查循环
-	// - 检查不可达节点
-	// - 构建拓扑排序
+	// 验证图结构，一次报告所有问题
+	if err := compile.NewValidator[S]().Validate(graphInfo[S]{graph: g}); err != nil {
+		return nil, fmt.Errorf("state: graph %s: %w", g.name, err)
+	}
+
+	// 子图中的中断需要子图的检查点才能恢复
+	if err := g.validateSubgraphs(); err != nil {
+		return nil, err
+	}
+
+	// 验证持久性配置
+	if g.durabilityConfig != nil {
+		if err := g.durabilityConfig.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	// 验证中断点
+	interrupts := hitl.NewInterruptManager()
+	for _, point := range config.InterruptPoints {
+		if point.Type != hitl.InterruptBefore && point.Type != hitl.InterruptAfter {
+			return nil, fmt.Errorf("%w: unsupported type %q at node %s", ErrInvalidInterrupt, point.Type, point.NodeName)
+		}
+		if _, exists := g.nodes[point.NodeName]; !exists {
+			return nil, fmt.Errorf("%w: interrupt at %s", ErrNodeNotFound, point.NodeName)
+		}
+		interrupts.AddInterruptPoint(point)
+	}
 
 	// 创建已编译的图
 	compiled := &CompiledGraph[S]{
-		graph: g,
+		graph:      g,
+		interrupts: interrupts,
 	}
 
 	return compiled, nil
@@ -423,15 +636,15 @@ func (g *StateGraph[S]) Compile() (*CompiledGraph[S], error) {
 //
 // CompiledGraph 可以执行，支持：
 //   - Invoke: 同步执行
-//   - Stream: 流式执行
-//   - Batch: 批量执行
-//
-// 注意：
-//   - 完整的执行功能将在 M35-M37 (Execute 系统) 实现
-//   - 目前提供基础的 Invoke 实现
+//   - Stream: 流式执行（values / updates / debug 模式）
+//   - 检查点持久化与按线程恢复
+//   - Human-in-the-Loop 中断与恢复（ResumeWithInput / ResumeWithModifiedState）
 //
 type CompiledGraph[S any] struct {
 	graph *StateGraph[S]
+
+	// interrupts 静态中断点
+	interrupts *hitl.InterruptManager
 }
 
 // Invoke 执行图，返回最终状态。
@@ -439,85 +652,286 @@ type CompiledGraph[S any] struct {
 // 参数：
 //   - ctx: 上下文
 //   - initialState: 初始状态
-//   - opts: 执行选项（可选，将在 M35 实现）
+//   - opts: 执行选项（InvokeOption，例如 WithThreadID）
 //
 // 返回：
 //   - S: 最终状态
-//   - error: 执行错误
+//   - error: 执行错误；选项不是 InvokeOption 时返回 ErrInvalidOption
+//
+// 检查点：
+//   - 图配置了检查点保存器且指定了 ThreadID 时，按 Durability 配置写入检查点
+//   - 如果该线程上一次执行未完成（例如进程重启或节点失败），
+//     本次执行忽略 initialState，从最后完成的节点之后继续
+//   - 如果该线程上一次执行已结束，使用 initialState 从入口点重新开始
+//
+// 并行执行：
+//   - 执行按超步（superstep）进行，一个节点的所有出边在下一步同时触发
+//   - 同一步中的节点并行执行，输出按 WithChannel / WithReducer 合并
+//   - 检查点在每个超步完成后写入，记录本步执行的节点和下一步的节点
+//
+// 中断：
+//   - 执行到 InterruptBefore / InterruptAfter 中断点或节点调用 Interrupt 时，
+//     保存检查点并返回 *InterruptError（errors.Is(err, hitl.ErrInterrupted)）
+//   - 之后用 ResumeWithInput / ResumeWithModifiedState 恢复；
+//     在恢复之前，对该线程调用 Invoke 会再次返回同一个中断
 //
 // 注意：
-//   - 这是简化版实现，完整功能在 M35-M37 (Execute 系统) 实现
-//   - 目前不支持 Checkpoint、HITL、Streaming 等高级功能
+//   - 需要观察执行过程时使用 Stream
 //
 func (c *CompiledGraph[S]) Invoke(ctx context.Context, initialState S, opts ...interface{}) (S, error) {
-	// 简化版执行逻辑
-	// 完整实现在 M35: execute/executor.go
+	config, err := newInvokeConfig(opts...)
+	if err != nil {
+		var zero S
+		return zero, err
+	}
+	return c.run(ctx, initialState, config, nil)
+}
+
+// run 执行图，Invoke、Resume 和 Stream 共用。
+//
+// 一次执行是一次链运行（callbacks.StartChain，名称为图的名称），
+// 每个节点是它的子运行。em 为 nil 时不输出流式事件。
+//
+func (c *CompiledGraph[S]) run(ctx context.Context, initialState S, config *InvokeConfig, em *emitter[S]) (S, error) {
+	ctx = callbacks.WithHandlers(ctx, config.Callbacks...)
+	ctx, run := callbacks.StartChain(ctx, c.graph.name, initialState)
+	state, err := c.execute(ctx, initialState, config, em)
+	run.EndChain(ctx, state, err)
+	return state, err
+}
+
+// execute 按超步执行图。
+func (c *CompiledGraph[S]) execute(ctx context.Context, initialState S, config *InvokeConfig, em *emitter[S]) (S, error) {
+	// 作为子图在父图的节点中执行时，使用父图的线程和节点的命名空间
+	parent := nodeScopeFromContext(ctx)
+	x := &execution[S]{threadID: config.ThreadID, em: em, tracer: config.Tracer}
+	if parent != nil && x.threadID == "" {
+		x.threadID = parent.threadID
+		x.ns = parent.ns
+	}
+
+	durabilityConfig := c.graph.durabilityConfig
+	if durabilityConfig == nil {
+		durabilityConfig = defaultDurabilityConfig()
+	}
+	x.execCtx = durability.NewExecutionContext(x.threadID, durabilityConfig)
 
 	state := initialState
-	currentNode := c.graph.entryPoint
+	current := []string{c.graph.entryPoint}
+	step := 0
+
+	// sends 是本步的 Send 调度，done 是其中已完成的 Send 的输出（从失败或中断恢复时）
+	var sends []Send[S]
+	var done map[string]S
+
+	// lastNodes 是最近完成的一步的节点，resume 是恢复信息（仅用于恢复后的第一步）
+	lastNodes := ""
+	var resume *resumeStep
+	skipBefore := false
+
+	// 子图没有自己的检查点保存器时使用父图的
+	saver := c.graph.checkpointer
+	if saver == nil && parent != nil {
+		saver, _ = parent.saver.(checkpoint.CheckpointSaver[S])
+	}
+
+	var p *persister[S]
+	if saver != nil && x.threadID != "" {
+		x.saver = saver
+		p = newPersister(saver, x.threadID, x.ns, x.execCtx)
+		p.from = config.CheckpointID
+
+		first, err := p.start(ctx, initialState, current)
+		state, current, sends, done, step = first.state, first.next, first.sends, first.writes, first.step
+		if err != nil {
+			return state, err
+		}
+
+		if cp := p.resumed; cp != nil {
+			var write *checkpointWrite[S]
+			if pending := decodeInterrupts(cp); len(pending) > 0 {
+				switch {
+				case x.ns == "":
+					// 未处理的中断需要通过 Resume 恢复
+					return state, &InterruptError{ThreadID: x.threadID, CheckpointID: cp.ID, Interrupts: pending}
+
+				case parent.resuming:
+					// 父图从中断恢复：子图以同一输入恢复自己的中断
+					resolution := hitl.NewResolution(hitl.ActionContinue).WithInput(parent.input)
+					resumed, err := resumeWrite(cp, pending, x.ns, resolution)
+					if err == nil {
+						err = p.cp.save(ctx, resumed)
+					}
+					if err != nil {
+						return state, err
+					}
+					write = &resumed
+
+				default:
+					// 父图重新执行子图节点，遗留的中断已失效
+					first, err := p.restart(ctx, initialState, []string{c.graph.entryPoint})
+					state, current, sends, done, step = first.state, first.next, first.sends, first.writes, first.step
+					if err != nil {
+						return state, err
+					}
+				}
+			} else if cp.Metadata["source"] == SourceResume {
+				write = &checkpointWrite[S]{
+					state:  cp.State,
+					next:   current,
+					sends:  sends,
+					writes: done,
+					resume: metadataResume(cp.Metadata[metadataResumeKey]),
+					input:  cp.Metadata[metadataResumeInputKey],
+				}
+			}
+
+			// 从中断恢复：不再触发本步的 InterruptBefore
+			if write != nil {
+				state, current, sends, done = write.state, write.next, write.sends, write.writes
+				resume = &resumeStep{values: write.resume, input: write.input}
+				skipBefore = true
+			}
+			lastNodes = p.last.nodeName
+		}
+
+		x.record = func(writes map[string]S) error {
+			return p.record(ctx, checkpointWrite[S]{
+				state:    state,
+				source:   SourceLoop,
+				step:     step,
+				nodeName: lastNodes,
+				next:     current,
+				sends:    sends,
+				writes:   writes,
+			})
+		}
+	}
+
+	// fail 在失败时写入检查点（如果启用）
+	fail := func(err error) (S, error) {
+		if p != nil {
+			err = p.fail(ctx, checkpointWrite[S]{
+				state:  state,
+				step:   step,
+				next:   current,
+				sends:  sends,
+				writes: done,
+				err:    err,
+			})
+		}
+		return state, err
+	}
 
-	// 执行循环
-	for currentNode != END {
+	// pause 在中断时写入检查点
+	pause := func(write checkpointWrite[S]) (S, error) {
+		return state, c.pause(ctx, p, x, write)
+	}
+
+	// 超步循环：每一步并行执行当前所有活跃节点
+	for len(current) > 0 || len(sends) > 0 {
 		// 检查上下文取消
 		select {
 		case <-ctx.Done():
-			return state, ctx.Err()
+			return fail(ctx.Err())
 		default:
 		}
 
-		// 获取当前节点
-		node, exists := c.graph.nodes[currentNode]
-		if !exists {
-			return state, fmt.Errorf("%w: %s", ErrNodeNotFound, currentNode)
+		nodes := stepNodes(current, sends)
+
+		// 节点执行前中断
+		if !skipBefore {
+			if pending := c.staticInterrupts(x.ns, hitl.InterruptBefore, nodes, state); len(pending) > 0 {
+				x.traceInterrupts(step+1, pending)
+				return pause(checkpointWrite[S]{
+					state:      state,
+					source:     SourceLoop,
+					step:       step,
+					nodeName:   lastNodes,
+					next:       current,
+					sends:      sends,
+					writes:     done,
+					interrupts: pending,
+				})
+			}
 		}
+		skipBefore = false
 
-		// 执行节点
-		newState, err := node.Func(ctx, state)
+		// 执行本步节点并合并输出
+		em.startStep(step + 1)
+		newState, completed, err := c.runStep(ctx, x, step+1, newStepTasks(current, sends, state), state, resume, done)
 		if err != nil {
-			return state, fmt.Errorf("error executing node %s: %w", currentNode, err)
+			done = completed
+
+			// 节点动态中断（包括子图中的中断）：恢复后重新执行本步（已完成的 Send 除外）
+			var ierr *InterruptError
+			if errors.As(err, &ierr) {
+				write := checkpointWrite[S]{
+					state:      state,
+					source:     SourceLoop,
+					step:       step,
+					nodeName:   lastNodes,
+					next:       current,
+					sends:      sends,
+					writes:     done,
+					interrupts: ierr.Interrupts,
+				}
+				if resume != nil {
+					write.resume = resume.values
+				}
+				return pause(write)
+			}
+			return fail(err)
 		}
+		resume = nil
+		done = nil
 
-		state = newState
-
-		// 确定下一个节点
-		nextNode, err := c.getNextNode(currentNode, state)
+		// 确定下一步节点
+		next, nextSends, err := c.nextNodes(nodes, newState)
 		if err != nil {
-			return state, err
+			return fail(err)
 		}
 
-		currentNode = nextNode
-	}
-
-	return state, nil
-}
+		state = newState
+		step++
+		lastNodes = strings.Join(nodes, ",")
+		em.values(step, state)
+
+		write := checkpointWrite[S]{
+			state:    state,
+			source:   SourceLoop,
+			step:     step,
+			nodeName: lastNodes,
+			next:     next,
+			sends:    nextSends,
+		}
 
-// getNextNode 确定下一个要执行的节点。
-func (c *CompiledGraph[S]) getNextNode(currentNode string, state S) (string, error) {
-	// 首先检查条件边
-	for _, conditional := range c.graph.conditionals {
-		if conditional.Source == currentNode {
-			pathName := conditional.Path(state)
-			if target, exists := conditional.PathMap[pathName]; exists {
-				return target, nil
+		// 节点执行后中断（执行已结束时不中断）
+		if len(next) > 0 || len(nextSends) > 0 {
+			if pending := c.staticInterrupts(x.ns, hitl.InterruptAfter, nodes, state); len(pending) > 0 {
+				write.interrupts = pending
+				x.traceInterrupts(step, pending)
+				return pause(write)
 			}
-			return "", fmt.Errorf("state: no target for path %s from node %s", pathName, currentNode)
 		}
-	}
 
-	// 然后检查普通边
-	for _, edge := range c.graph.edges {
-		if edge.From == currentNode {
-			return edge.To, nil
+		// 保存检查点
+		if p != nil {
+			if err := p.stepDone(ctx, write, c.stepTasks(nodes)...); err != nil {
+				return state, err
+			}
 		}
+
+		current, sends = next, nextSends
 	}
 
-	// 如果没有找到边，且当前节点是结束点，返回 END
-	if c.graph.finishPoints[currentNode] {
-		return END, nil
+	if p != nil {
+		if err := p.finish(ctx); err != nil {
+			return state, err
+		}
 	}
 
-	// 没有找到出边
-	return "", fmt.Errorf("state: no outgoing edge from node %s", currentNode)
+	return state, nil
 }
 
 // GetGraph 返回底层的 StateGraph（用于测试和调试）。
@@ -3,7 +3,14 @@ package state
 import (
 	"context"
 	"errors"
+	"reflect"
+	"sync"
 	"testing"
+
+	"github.com/zhucl121/langchain-go/core/callbacks"
+	"github.com/zhucl121/langchain-go/graph/checkpoint"
+	"github.com/zhucl121/langchain-go/graph/compile"
+	"github.com/zhucl121/langchain-go/graph/durability"
 )
 
 // 测试用的状态类型
@@ -350,6 +357,125 @@ func TestCompile_NoEntryPoint(t *testing.T) {
 	}
 }
 
+// TestCompile_ValidationIssues 测试编译时一次报告所有结构问题
+func TestCompile_ValidationIssues(t *testing.T) {
+	graph := NewStateGraph[TestState]("test")
+
+	noop := func(ctx context.Context, s TestState) (TestState, error) {
+		return s, nil
+	}
+	graph.AddNode("router", noop)
+	graph.AddNode("dead_end", noop)
+	graph.AddNode("orphan", noop)
+	graph.AddNode("ping", noop)
+	graph.AddNode("pong", noop)
+
+	graph.SetEntryPoint("router")
+	graph.AddConditionalEdges("router",
+		func(s TestState) string { return "next" },
+		map[string]string{
+			"next":    "dead_end",
+			"loop":    "ping",
+			"missing": "ghost",
+		},
+	)
+	graph.AddEdge("orphan", END)
+	graph.AddEdge("ping", "pong")
+	graph.AddEdge("pong", "ping")
+
+	_, err := graph.Compile()
+
+	var valErr *compile.ValidationError
+	if !errors.As(err, &valErr) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+
+	expected := []error{
+		compile.ErrInvalidConditional,
+		compile.ErrUnreachableNode,
+		compile.ErrNoOutgoingEdge,
+		compile.ErrCyclicGraph,
+	}
+	for _, kind := range expected {
+		if !errors.Is(err, kind) {
+			t.Errorf("expected %v to be reported, got %v", kind, err)
+		}
+	}
+
+	if len(valErr.Issues) != len(expected) {
+		t.Errorf("expected %d issues, got %v", len(expected), valErr.Details)
+	}
+}
+
+// TestCompile_LoopWithConditionalExit 测试带条件出口的循环可以编译
+func TestCompile_LoopWithConditionalExit(t *testing.T) {
+	graph := NewStateGraph[TestState]("test")
+
+	graph.AddNode("work", func(ctx context.Context, s TestState) (TestState, error) {
+		return s, nil
+	})
+	graph.AddNode("check", func(ctx context.Context, s TestState) (TestState, error) {
+		return s, nil
+	})
+
+	graph.SetEntryPoint("work")
+	graph.AddEdge("work", "check")
+	graph.AddConditionalEdges("check",
+		func(s TestState) string { return "done" },
+		map[string]string{
+			"again": "work",
+			"done":  END,
+		},
+	)
+
+	if _, err := graph.Compile(); err != nil {
+		t.Errorf("Compile failed: %v", err)
+	}
+}
+
+// TestCompile_ConditionalWithStaticEdge 测试条件边与普通边同源时编译失败
+func TestCompile_ConditionalWithStaticEdge(t *testing.T) {
+	graph := NewStateGraph[TestState]("test")
+
+	noop := func(ctx context.Context, s TestState) (TestState, error) {
+		return s, nil
+	}
+	graph.AddNode("router", noop)
+	graph.AddNode("branch", noop)
+	graph.AddNode("audit", noop)
+
+	graph.SetEntryPoint("router")
+	graph.AddConditionalEdges("router",
+		func(s TestState) string { return "done" },
+		map[string]string{
+			"next": "branch",
+			"done": END,
+		},
+	)
+	graph.AddEdge("router", "audit")
+	graph.AddEdge("branch", END)
+	graph.AddEdge("audit", END)
+
+	if _, err := graph.Compile(); !errors.Is(err, compile.ErrConflictingEdges) {
+		t.Fatalf("expected ErrConflictingEdges, got %v", err)
+	}
+}
+
+// TestCompile_FinishPoint 测试 SetFinishPoint 视为出边
+func TestCompile_FinishPoint(t *testing.T) {
+	graph := NewStateGraph[TestState]("test")
+
+	graph.AddNode("only", func(ctx context.Context, s TestState) (TestState, error) {
+		return s, nil
+	})
+	graph.SetEntryPoint("only")
+	graph.SetFinishPoint("only")
+
+	if _, err := graph.Compile(); err != nil {
+		t.Errorf("Compile failed: %v", err)
+	}
+}
+
 // TestInvoke_Simple 测试简单执行
 func TestInvoke_Simple(t *testing.T) {
 	graph := NewStateGraph[TestState]("test")
@@ -526,6 +652,107 @@ func TestInvoke_NodeError(t *testing.T) {
 	}
 }
 
+// TestInvoke_InvalidOption 测试不认识的执行选项返回错误
+func TestInvoke_InvalidOption(t *testing.T) {
+	ctx := context.Background()
+	calls := make(map[string]int)
+	compiled := newApprovalGraph(checkpoint.NewMemoryCheckpointSaver[TestState](), calls)
+
+	// 常见的误用：直接传线程 ID 而不是 WithThreadID
+	if _, err := compiled.Invoke(ctx, TestState{}, "thread-1"); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("Invoke: expected ErrInvalidOption, got %v", err)
+	}
+	if _, err := compiled.Stream(ctx, TestState{}, StreamModeValues, "thread-1"); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("Stream: expected ErrInvalidOption, got %v", err)
+	}
+	if _, err := compiled.Resume(ctx, "thread-1", nil, "checkpoint-1"); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("Resume: expected ErrInvalidOption, got %v", err)
+	}
+	if len(calls) != 0 {
+		t.Errorf("expected no node to run, got %v", calls)
+	}
+}
+
+// graphRecorder 记录图执行的回调事件
+type graphRecorder struct {
+	callbacks.BaseHandler
+
+	mu     sync.Mutex
+	events []string
+	infos  map[string]callbacks.RunInfo
+}
+
+func (r *graphRecorder) record(kind string, info callbacks.RunInfo) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, kind+":"+info.Name)
+	r.infos[info.Name] = info
+}
+
+func (r *graphRecorder) OnChainStart(ctx context.Context, e *callbacks.ChainStartEvent) {
+	r.record("chain_start", e.RunInfo)
+}
+
+func (r *graphRecorder) OnChainEnd(ctx context.Context, e *callbacks.ChainEndEvent) {
+	r.record("chain_end", e.RunInfo)
+}
+
+func (r *graphRecorder) OnNodeStart(ctx context.Context, e *callbacks.NodeStartEvent) {
+	r.record("node_start", e.RunInfo)
+}
+
+func (r *graphRecorder) OnNodeEnd(ctx context.Context, e *callbacks.NodeEndEvent) {
+	r.record("node_end", e.RunInfo)
+}
+
+func (r *graphRecorder) OnToolStart(ctx context.Context, e *callbacks.ToolStartEvent) {
+	r.record("tool_start", e.RunInfo)
+}
+
+// TestInvoke_Callbacks 测试图执行的回调事件
+func TestInvoke_Callbacks(t *testing.T) {
+	graph := NewStateGraph[TestState]("callbacks-graph")
+
+	graph.AddNode("increment", func(ctx context.Context, s TestState) (TestState, error) {
+		_, run := callbacks.StartTool(ctx, "counter", nil)
+		run.EndTool(ctx, nil, nil)
+		s.Counter++
+		return s, nil
+	})
+	graph.AddNode("double", func(ctx context.Context, s TestState) (TestState, error) {
+		s.Counter *= 2
+		return s, nil
+	})
+
+	graph.SetEntryPoint("increment")
+	graph.AddEdge("increment", "double")
+	graph.AddEdge("double", END)
+
+	compiled, _ := graph.Compile()
+	rec := &graphRecorder{infos: make(map[string]callbacks.RunInfo)}
+	if _, err := compiled.Invoke(context.Background(), TestState{Counter: 1}, WithCallbacks(rec)); err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+
+	expected := []string{
+		"chain_start:callbacks-graph",
+		"node_start:increment", "tool_start:counter", "node_end:increment",
+		"node_start:double", "node_end:double",
+		"chain_end:callbacks-graph",
+	}
+	if !reflect.DeepEqual(rec.events, expected) {
+		t.Fatalf("expected events %v, got %v", expected, rec.events)
+	}
+
+	graphRun := rec.infos["callbacks-graph"]
+	if rec.infos["increment"].ParentRunID != graphRun.RunID {
+		t.Errorf("node parent = %q, want graph run %q", rec.infos["increment"].ParentRunID, graphRun.RunID)
+	}
+	if rec.infos["counter"].ParentRunID != rec.infos["increment"].RunID {
+		t.Errorf("tool parent = %q, want node run %q", rec.infos["counter"].ParentRunID, rec.infos["increment"].RunID)
+	}
+}
+
 // TestInvoke_ContextCancellation 测试上下文取消
 func TestInvoke_ContextCancellation(t *testing.T) {
 	graph := NewStateGraph[TestState]("test")
@@ -553,16 +780,15 @@ func TestInvoke_ContextCancellation(t *testing.T) {
 func TestWithCheckpointer(t *testing.T) {
 	graph := NewStateGraph[TestState]("test")
 
-	// 使用占位符（实际实现在 M38-M42）
-	mockCheckpointer := "mock_checkpointer"
+	saver := checkpoint.NewMemoryCheckpointSaver[TestState]()
 
-	result := graph.WithCheckpointer(mockCheckpointer)
+	result := graph.WithCheckpointer(saver)
 
 	if result != graph {
 		t.Error("WithCheckpointer should return self")
 	}
 
-	if graph.checkpointer != mockCheckpointer {
+	if graph.checkpointer != saver {
 		t.Error("checkpointer not set correctly")
 	}
 }
@@ -571,16 +797,13 @@ func TestWithCheckpointer(t *testing.T) {
 func TestWithDurability(t *testing.T) {
 	graph := NewStateGraph[TestState]("test")
 
-	// 使用占位符（实际实现在 M43-M45）
-	mockMode := "sync"
-
-	result := graph.WithDurability(mockMode)
+	result := graph.WithDurability(durability.ModeAsync)
 
 	if result != graph {
 		t.Error("WithDurability should return self")
 	}
 
-	if graph.durability != mockMode {
+	if graph.durabilityConfig == nil || graph.durabilityConfig.PersistMode != durability.ModeAsync {
 		t.Error("durability not set correctly")
 	}
 }
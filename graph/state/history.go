@@ -0,0 +1,240 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zhucl121/langchain-go/graph/checkpoint"
+	"github.com/zhucl121/langchain-go/graph/hitl"
+)
+
+// SourceUpdate 是 UpdateState 写入的检查点来源。
+const SourceUpdate = "update"
+
+// StateSnapshot 是线程在某个检查点时的状态快照。
+//
+// StateSnapshot 由 GetState / GetHistory / UpdateState 返回，
+// 用于查看执行历史、定位问题以及选择重新执行的起点。
+//
+type StateSnapshot[S any] struct {
+	// Values 检查点中的状态
+	Values S
+
+	// Next 从该检查点继续时要执行的节点，包括 Sends 的目标（为空表示执行已结束）
+	Next []string
+
+	// Sends 从该检查点继续时要执行的 Send 调度
+	Sends []Send[S]
+
+	// ThreadID 所属线程
+	ThreadID string
+
+	// CheckpointID 检查点 ID，可以传给 WithCheckpointID
+	CheckpointID string
+
+	// ParentID 父检查点 ID（分支时指向分叉点）
+	ParentID string
+
+	// Step 步数
+	Step int
+
+	// Source 检查点来源（SourceInput、SourceLoop、SourceResume、SourceUpdate）
+	Source string
+
+	// NodeName 写入检查点前完成的节点（多个节点以逗号分隔）
+	NodeName string
+
+	// Error 执行失败时的错误信息
+	Error string
+
+	// Interrupts 等待处理的中断
+	Interrupts []*hitl.Interrupt
+
+	// Metadata 原始元数据
+	Metadata map[string]any
+
+	// CreatedAt 创建时间
+	CreatedAt time.Time
+}
+
+// newStateSnapshot 从检查点创建状态快照。
+func newStateSnapshot[S any](cp *checkpoint.Checkpoint[S]) *StateSnapshot[S] {
+	source, _ := cp.Metadata["source"].(string)
+	nodeName, _ := cp.Metadata["node_name"].(string)
+	errMsg, _ := cp.Metadata[metadataErrorKey].(string)
+	sends := metadataSends[S](cp.Metadata[metadataSendsKey])
+
+	return &StateSnapshot[S]{
+		Values:       cp.State,
+		Next:         stepNodes(metadataStrings(cp.Metadata[metadataNextKey]), sends),
+		Sends:        sends,
+		ThreadID:     cp.ThreadID,
+		CheckpointID: cp.ID,
+		ParentID:     cp.ParentID,
+		Step:         metadataInt(cp.Metadata["step"]),
+		Source:       source,
+		NodeName:     nodeName,
+		Error:        errMsg,
+		Interrupts:   decodeInterrupts(cp),
+		Metadata:     cp.Metadata,
+		CreatedAt:    cp.Timestamp,
+	}
+}
+
+// GetState 返回线程的当前状态快照。
+//
+// 参数：
+//   - ctx: 上下文
+//   - threadID: 线程标识
+//   - opts: 执行选项（WithCheckpointID 指定历史检查点）
+//
+// 返回：
+//   - *StateSnapshot[S]: 状态快照
+//   - error: 未配置检查点、线程没有检查点或读取失败
+//
+func (c *CompiledGraph[S]) GetState(ctx context.Context, threadID string, opts ...interface{}) (*StateSnapshot[S], error) {
+	if c.graph.checkpointer == nil {
+		return nil, ErrNoCheckpointer
+	}
+
+	config, err := newInvokeConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+	cp, err := newThreadCheckpointer(c.graph.checkpointer, threadID, "").resume(ctx, config.CheckpointID)
+	if err != nil {
+		return nil, err
+	}
+	if cp == nil {
+		return nil, fmt.Errorf("%w: no checkpoints for thread %s", checkpoint.ErrCheckpointNotFound, threadID)
+	}
+
+	return newStateSnapshot(cp), nil
+}
+
+// GetHistory 返回线程的状态历史，最新的在前。
+//
+// 历史包含线程的所有分支；通过 ParentID 可以还原执行树。
+// 子图的检查点不包含在内。
+//
+// 参数：
+//   - ctx: 上下文
+//   - threadID: 线程标识
+//   - limit: 最多返回的数量，<= 0 时返回全部
+//
+// 返回：
+//   - []*StateSnapshot[S]: 状态快照列表
+//   - error: 未配置检查点或读取失败
+//
+// 示例：
+//
+//	history, _ := compiled.GetHistory(ctx, "user-123", 10)
+//	for _, snapshot := range history {
+//	    fmt.Println(snapshot.Step, snapshot.NodeName, snapshot.Next)
+//	}
+//
+func (c *CompiledGraph[S]) GetHistory(ctx context.Context, threadID string, limit int) ([]*StateSnapshot[S], error) {
+	if c.graph.checkpointer == nil {
+		return nil, ErrNoCheckpointer
+	}
+
+	checkpoints, err := c.graph.checkpointer.List(ctx, threadID)
+	if err != nil {
+		return nil, fmt.Errorf("state: failed to list checkpoints for thread %s: %w", threadID, err)
+	}
+
+	history := make([]*StateSnapshot[S], 0, len(checkpoints))
+	for i := len(checkpoints) - 1; i >= 0; i-- {
+		if limit > 0 && len(history) >= limit {
+			break
+		}
+		if checkpoints[i].CheckpointNS != "" {
+			continue
+		}
+		history = append(history, newStateSnapshot(checkpoints[i]))
+	}
+
+	return history, nil
+}
+
+// UpdateState 以补丁状态写入新的检查点，形成新的分支。
+//
+// 新检查点的父节点是起点检查点（默认为最新检查点，可用 WithCheckpointID 指定），
+// 之后对该线程调用 Invoke 会从新检查点继续执行，原来的历史保持不变。
+//
+// 参数：
+//   - ctx: 上下文
+//   - threadID: 线程标识
+//   - values: 新的状态
+//   - asNode: 视为由该节点写入；下一步节点按该节点的出边计算。
+//     为空时沿用起点检查点的下一步节点（线程没有检查点时从入口点开始）
+//   - opts: 执行选项（WithCheckpointID）
+//
+// 返回：
+//   - *StateSnapshot[S]: 新检查点的状态快照
+//   - error: 未配置检查点、节点不存在或读写失败
+//
+// 注意：
+//   - 新检查点不携带起点检查点上的中断；节点中的动态中断会在重新执行时再次触发
+//
+// 示例：
+//
+//	// 修正 agent 的错误决策后重新执行
+//	history, _ := compiled.GetHistory(ctx, "user-123", 0)
+//	fixed := history[3].Values
+//	fixed.Route = "search"
+//	snapshot, _ := compiled.UpdateState(ctx, "user-123", fixed, "",
+//	    state.WithCheckpointID(history[3].CheckpointID))
+//	result, _ := compiled.Invoke(ctx, fixed, state.WithThreadID("user-123"))
+//
+func (c *CompiledGraph[S]) UpdateState(
+	ctx context.Context,
+	threadID string,
+	values S,
+	asNode string,
+	opts ...interface{},
+) (*StateSnapshot[S], error) {
+	if c.graph.checkpointer == nil {
+		return nil, ErrNoCheckpointer
+	}
+
+	config, err := newInvokeConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+	cp := newThreadCheckpointer(c.graph.checkpointer, threadID, "")
+	base, err := cp.resume(ctx, config.CheckpointID)
+	if err != nil {
+		return nil, err
+	}
+
+	write := checkpointWrite[S]{
+		state:    values,
+		source:   SourceUpdate,
+		nodeName: asNode,
+		next:     []string{c.graph.entryPoint},
+	}
+	if base != nil {
+		write.step = metadataInt(base.Metadata["step"]) + 1
+		write.next = metadataStrings(base.Metadata[metadataNextKey])
+		write.sends = metadataSends[S](base.Metadata[metadataSendsKey])
+	}
+
+	if asNode != "" {
+		if _, exists := c.graph.nodes[asNode]; !exists {
+			return nil, fmt.Errorf("%w: %s", ErrNodeNotFound, asNode)
+		}
+
+		write.next, write.sends, err = c.nextNodes([]string{asNode}, values)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cp.save(ctx, write); err != nil {
+		return nil, err
+	}
+
+	return c.GetState(ctx, threadID, WithCheckpointID(cp.parentID))
+}
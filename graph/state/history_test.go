@@ -0,0 +1,161 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/zhucl121/langchain-go/graph/checkpoint"
+)
+
+// newHistoryTestGraph 创建 step1 -> step2 -> step3 的测试图，记录每个节点的执行次数
+func newHistoryTestGraph(saver checkpoint.CheckpointSaver[TestState], calls map[string]int) *CompiledGraph[TestState] {
+	graph := NewStateGraph[TestState]("history")
+
+	for i, name := range []string{"step1", "step2", "step3"} {
+		name, amount := name, []int{1, 10, 100}[i]
+		graph.AddNode(name, func(ctx context.Context, s TestState) (TestState, error) {
+			calls[name]++
+			s.Counter += amount
+			return s, nil
+		})
+	}
+
+	graph.SetEntryPoint("step1")
+	graph.AddEdge("step1", "step2")
+	graph.AddEdge("step2", "step3")
+	graph.AddEdge("step3", END)
+	graph.WithCheckpointer(saver)
+
+	compiled, _ := graph.Compile()
+	return compiled
+}
+
+// TestHistory_GetHistory 测试读取状态历史
+func TestHistory_GetHistory(t *testing.T) {
+	ctx := context.Background()
+	saver := checkpoint.NewMemoryCheckpointSaver[TestState]()
+	compiled := newHistoryTestGraph(saver, make(map[string]int))
+
+	if _, err := compiled.Invoke(ctx, TestState{}, WithThreadID("t-1")); err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+
+	history, err := compiled.GetHistory(ctx, "t-1", 0)
+	if err != nil {
+		t.Fatalf("GetHistory failed: %v", err)
+	}
+	if len(history) != 4 {
+		t.Fatalf("expected 4 snapshots, got %d", len(history))
+	}
+
+	latest := history[0]
+	if latest.NodeName != "step3" || latest.Values.Counter != 111 || len(latest.Next) != 0 {
+		t.Errorf("unexpected latest snapshot: %+v", latest)
+	}
+	if input := history[3]; input.Source != SourceInput || len(input.Next) != 1 || input.Next[0] != "step1" {
+		t.Errorf("unexpected input snapshot: %+v", input)
+	}
+
+	limited, _ := compiled.GetHistory(ctx, "t-1", 2)
+	if len(limited) != 2 || limited[1].NodeName != "step2" {
+		t.Errorf("unexpected limited history: %v", limited)
+	}
+
+	snapshot, err := compiled.GetState(ctx, "t-1", WithCheckpointID(history[2].CheckpointID))
+	if err != nil || snapshot.NodeName != "step1" || snapshot.Values.Counter != 1 {
+		t.Errorf("unexpected snapshot: %+v, %v", snapshot, err)
+	}
+
+	if _, err := compiled.GetState(ctx, "missing"); !errors.Is(err, checkpoint.ErrCheckpointNotFound) {
+		t.Errorf("expected ErrCheckpointNotFound, got %v", err)
+	}
+}
+
+// TestHistory_Replay 测试从较早的检查点重新执行
+func TestHistory_Replay(t *testing.T) {
+	ctx := context.Background()
+	saver := checkpoint.NewMemoryCheckpointSaver[TestState]()
+	calls := make(map[string]int)
+	compiled := newHistoryTestGraph(saver, calls)
+
+	if _, err := compiled.Invoke(ctx, TestState{}, WithThreadID("t-1")); err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+
+	history, _ := compiled.GetHistory(ctx, "t-1", 0)
+	afterStep1 := history[2]
+
+	result, err := compiled.Invoke(ctx, TestState{}, WithThreadID("t-1"), WithCheckpointID(afterStep1.CheckpointID))
+	if err != nil {
+		t.Fatalf("replay failed: %v", err)
+	}
+	if result.Counter != 111 {
+		t.Errorf("expected Counter=111, got %d", result.Counter)
+	}
+	if calls["step1"] != 1 || calls["step2"] != 2 || calls["step3"] != 2 {
+		t.Errorf("unexpected calls: %v", calls)
+	}
+
+	// 新分支以重放的检查点为父节点，原历史保持不变
+	history, _ = compiled.GetHistory(ctx, "t-1", 0)
+	if len(history) != 6 {
+		t.Fatalf("expected 6 snapshots, got %d", len(history))
+	}
+	if branch := history[1]; branch.NodeName != "step2" || branch.ParentID != afterStep1.CheckpointID {
+		t.Errorf("expected branch from %s, got %+v", afterStep1.CheckpointID, branch)
+	}
+}
+
+// TestHistory_UpdateState 测试写入补丁状态形成分支
+func TestHistory_UpdateState(t *testing.T) {
+	ctx := context.Background()
+	saver := checkpoint.NewMemoryCheckpointSaver[TestState]()
+	calls := make(map[string]int)
+	compiled := newHistoryTestGraph(saver, calls)
+
+	if _, err := compiled.Invoke(ctx, TestState{}, WithThreadID("t-1")); err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+
+	history, _ := compiled.GetHistory(ctx, "t-1", 0)
+	afterStep1 := history[2]
+
+	patched := afterStep1.Values
+	patched.Message = "patched"
+	snapshot, err := compiled.UpdateState(ctx, "t-1", patched, "", WithCheckpointID(afterStep1.CheckpointID))
+	if err != nil {
+		t.Fatalf("UpdateState failed: %v", err)
+	}
+	if snapshot.ParentID != afterStep1.CheckpointID || snapshot.Source != SourceUpdate {
+		t.Errorf("unexpected fork snapshot: %+v", snapshot)
+	}
+	if len(snapshot.Next) != 1 || snapshot.Next[0] != "step2" {
+		t.Errorf("expected next [step2], got %v", snapshot.Next)
+	}
+
+	result, err := compiled.Invoke(ctx, TestState{}, WithThreadID("t-1"))
+	if err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+	if result.Message != "patched" || result.Counter != 111 || calls["step1"] != 1 {
+		t.Errorf("unexpected result %+v, calls %v", result, calls)
+	}
+
+	// asNode：视为 step2 写入，下一步是 step3
+	snapshot, err = compiled.UpdateState(ctx, "t-2", TestState{Counter: 5}, "step2")
+	if err != nil {
+		t.Fatalf("UpdateState failed: %v", err)
+	}
+	if snapshot.ParentID != "" || len(snapshot.Next) != 1 || snapshot.Next[0] != "step3" {
+		t.Errorf("unexpected snapshot: %+v", snapshot)
+	}
+	result, _ = compiled.Invoke(ctx, TestState{}, WithThreadID("t-2"))
+	if result.Counter != 105 {
+		t.Errorf("expected Counter=105, got %d", result.Counter)
+	}
+
+	if _, err := compiled.UpdateState(ctx, "t-2", TestState{}, "missing"); !errors.Is(err, ErrNodeNotFound) {
+		t.Errorf("expected ErrNodeNotFound, got %v", err)
+	}
+}
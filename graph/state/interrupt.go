@@ -0,0 +1,457 @@
+package state
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/zhucl121/langchain-go/graph/checkpoint"
+	"github.com/zhucl121/langchain-go/graph/hitl"
+)
+
+// InterruptError 表示图执行被 Human-in-the-Loop 中断。
+//
+// 执行到中断点（InterruptBefore / InterruptAfter）或节点调用 Interrupt 时，
+// Invoke 保存检查点并返回 *InterruptError。errors.Is(err, hitl.ErrInterrupted) 为 true。
+//
+// 同一线程之后可以（在任意进程中）用 ResumeWithInput、ResumeWithModifiedState
+// 或 Resume 继续执行。
+//
+type InterruptError struct {
+	// ThreadID 被中断的线程
+	ThreadID string
+
+	// CheckpointID 中断检查点（未配置检查点时为空，无法恢复）
+	CheckpointID string
+
+	// Interrupts 等待处理的中断
+	Interrupts []*hitl.Interrupt
+}
+
+// Error 实现 error 接口。
+func (e *InterruptError) Error() string {
+	return fmt.Sprintf("%s at node %s", hitl.ErrInterrupted, strings.Join(interruptedNodes(e.Interrupts), ","))
+}
+
+// Unwrap 返回 hitl.ErrInterrupted。
+func (e *InterruptError) Unwrap() error {
+	return hitl.ErrInterrupted
+}
+
+// Interrupt 在节点内动态中断执行，等待人类输入。
+//
+// 第一次执行时返回 hitl.ErrInterrupted 错误，节点应直接返回该错误；
+// 图保存检查点并返回 *InterruptError，payload 记录在 hitl.Interrupt.Payload 中。
+// 用 ResumeWithInput 恢复后节点重新执行，此时 Interrupt 返回恢复时提供的输入。
+//
+// 同一节点可以多次调用 Interrupt，恢复值按调用顺序对应。
+//
+// 参数：
+//   - ctx: 节点的上下文
+//   - payload: 展示给人类的数据
+//
+// 返回：
+//   - any: 恢复时提供的输入
+//   - error: 需要中断时返回的错误
+//
+// 注意：
+//   - 节点在恢复后会从头重新执行，Interrupt 之前的副作用应当是幂等的
+//   - 使用 JSON 序列化的检查点保存器时，payload 和之前的恢复值会经过 JSON 往返
+//
+// 示例：
+//
+//	graph.AddNode("review", func(ctx context.Context, s MyState) (MyState, error) {
+//	    answer, err := state.Interrupt(ctx, s.Draft)
+//	    if err != nil {
+//	        return s, err
+//	    }
+//	    s.Approved = answer == "yes"
+//	    return s, nil
+//	})
+//
+func Interrupt(ctx context.Context, payload any) (any, error) {
+	scope := nodeScopeFromContext(ctx)
+	if scope == nil {
+		return nil, &nodeInterrupt{payload: payload}
+	}
+
+	scope.mu.Lock()
+	defer scope.mu.Unlock()
+
+	index := scope.calls
+	scope.calls++
+	if index < len(scope.resume) {
+		return scope.resume[index], nil
+	}
+
+	return nil, &nodeInterrupt{payload: payload}
+}
+
+// nodeInterrupt 是 Interrupt 返回的错误。
+type nodeInterrupt struct {
+	payload any
+}
+
+// Error 实现 error 接口。
+func (e *nodeInterrupt) Error() string {
+	return hitl.ErrInterrupted.Error()
+}
+
+// Unwrap 返回 hitl.ErrInterrupted。
+func (e *nodeInterrupt) Unwrap() error {
+	return hitl.ErrInterrupted
+}
+
+// PendingInterrupts 返回线程中等待处理的中断。
+//
+// 可以在与执行不同的进程中调用，用于展示待审批内容。
+//
+// 返回：
+//   - []*hitl.Interrupt: 等待处理的中断（没有时为空）
+//   - error: 未配置检查点或读取失败
+//
+func (c *CompiledGraph[S]) PendingInterrupts(ctx context.Context, threadID string) ([]*hitl.Interrupt, error) {
+	if c.graph.checkpointer == nil {
+		return nil, ErrNoCheckpointer
+	}
+
+	cp, err := newThreadCheckpointer(c.graph.checkpointer, threadID, "").resume(ctx, "")
+	if err != nil || cp == nil {
+		return nil, err
+	}
+
+	return decodeInterrupts(cp), nil
+}
+
+// Resume 按解决方案恢复被中断的线程。
+//
+// 支持的操作：
+//   - hitl.ActionContinue / hitl.ActionRetry: 继续执行，Input 作为节点中 Interrupt 的返回值
+//   - hitl.ActionModify: 用 ModifiedState（类型必须是 S）替换状态后继续执行
+//   - hitl.ActionAbort: 结束线程，不再执行后续节点
+//
+// 恢复时不会再次触发本次中断的 InterruptBefore；
+// 节点中的动态中断全部使用 Input 作为返回值。
+// 默认恢复线程的最新检查点，可以用 WithCheckpointID 指定历史上的中断检查点。
+//
+// 参数：
+//   - ctx: 上下文
+//   - threadID: 被中断的线程
+//   - resolution: 解决方案
+//   - opts: 执行选项
+//
+// 返回：
+//   - S: 最终状态（再次中断时为中断时的状态）
+//   - error: 执行错误；线程没有等待处理的中断时返回 hitl.ErrNoInterrupt
+//
+func (c *CompiledGraph[S]) Resume(
+	ctx context.Context,
+	threadID string,
+	resolution *hitl.InterruptResolution,
+	opts ...interface{},
+) (S, error) {
+	var zero S
+	if c.graph.checkpointer == nil {
+		return zero, ErrNoCheckpointer
+	}
+
+	config, err := newInvokeConfig(opts...)
+	if err != nil {
+		return zero, err
+	}
+	config.ThreadID = threadID
+
+	cp := newThreadCheckpointer(c.graph.checkpointer, threadID, "")
+	latest, err := cp.resume(ctx, config.CheckpointID)
+	if err != nil {
+		return zero, err
+	}
+	if latest == nil {
+		return zero, fmt.Errorf("%w: thread %s", hitl.ErrNoInterrupt, threadID)
+	}
+
+	pending := decodeInterrupts(latest)
+	if len(pending) == 0 {
+		return latest.State, fmt.Errorf("%w: thread %s", hitl.ErrNoInterrupt, threadID)
+	}
+
+	write, err := resumeWrite(latest, pending, "", resolution)
+	if err != nil {
+		return latest.State, err
+	}
+
+	if err := cp.save(ctx, write); err != nil {
+		return write.state, err
+	}
+	if resolution.Action == hitl.ActionAbort {
+		return write.state, nil
+	}
+
+	// 从刚写入的恢复检查点继续
+	config.CheckpointID = ""
+	return c.run(ctx, write.state, config, nil)
+}
+
+// resumeWrite 根据解决方案生成恢复检查点。
+//
+// 当前图（命名空间 ns）中的动态中断以 Input 作为恢复值；
+// 来自子图的中断由子图在恢复执行时处理，Input 记录在检查点中传给子图。
+// hitl.ActionAbort 生成没有下一步节点的检查点。
+//
+// 参数：
+//   - latest: 中断检查点
+//   - pending: 等待处理的中断
+//   - ns: 当前图的检查点命名空间
+//   - resolution: 解决方案
+//
+// 返回：
+//   - checkpointWrite[S]: 要写入的恢复检查点
+//   - error: 操作不支持或修改后的状态类型不匹配
+//
+func resumeWrite[S any](
+	latest *checkpoint.Checkpoint[S],
+	pending []*hitl.Interrupt,
+	ns string,
+	resolution *hitl.InterruptResolution,
+) (checkpointWrite[S], error) {
+	nodeName, _ := latest.Metadata["node_name"].(string)
+	write := checkpointWrite[S]{
+		state:    latest.State,
+		source:   SourceResume,
+		step:     metadataInt(latest.Metadata["step"]),
+		nodeName: nodeName,
+		next:     metadataStrings(latest.Metadata[metadataNextKey]),
+		sends:    metadataSends[S](latest.Metadata[metadataSendsKey]),
+		writes:   metadataWrites[S](latest.Metadata[metadataWritesKey]),
+		resume:   metadataResume(latest.Metadata[metadataResumeKey]),
+	}
+
+	switch resolution.Action {
+	case hitl.ActionContinue, hitl.ActionRetry:
+	case hitl.ActionModify:
+		modified, ok := resolution.ModifiedState.(S)
+		if !ok {
+			return write, fmt.Errorf("%w: modified state has type %T", hitl.ErrInvalidInput, resolution.ModifiedState)
+		}
+		write.state = modified
+	case hitl.ActionAbort:
+		write.next = nil
+		write.sends = nil
+		write.writes = nil
+		write.resume = nil
+		return write, nil
+	default:
+		return write, fmt.Errorf("%w: unsupported action %s", hitl.ErrInvalidInput, resolution.Action)
+	}
+
+	for _, interrupt := range pending {
+		switch {
+		case interruptNamespace(interrupt) != ns:
+			write.input = resolution.Input
+		case interrupt.Point.Type == hitl.InterruptManual:
+			node := interrupt.GetNodeName()
+			write.resume[node] = append(write.resume[node], resolution.Input)
+		}
+	}
+
+	return write, nil
+}
+
+// ResumeWithInput 使用人类输入恢复被中断的线程。
+//
+// 示例：
+//
+//	_, err := compiled.Invoke(ctx, input, state.WithThreadID("t-1"))
+//	if errors.Is(err, hitl.ErrInterrupted) {
+//	    // ... 可以在另一个进程中
+//	    result, err = compiled.ResumeWithInput(ctx, "t-1", "yes")
+//	}
+//
+func (c *CompiledGraph[S]) ResumeWithInput(ctx context.Context, threadID string, input any, opts ...interface{}) (S, error) {
+	return c.Resume(ctx, threadID, hitl.NewResolution(hitl.ActionContinue).WithInput(input), opts...)
+}
+
+// ResumeWithModifiedState 用修改后的状态恢复被中断的线程。
+func (c *CompiledGraph[S]) ResumeWithModifiedState(ctx context.Context, threadID string, state S, opts ...interface{}) (S, error) {
+	return c.Resume(ctx, threadID, hitl.NewResolution(hitl.ActionModify).WithModifiedState(state), opts...)
+}
+
+// staticInterrupts 返回节点上满足条件的静态中断点。
+func (c *CompiledGraph[S]) staticInterrupts(ns string, kind hitl.InterruptType, nodes []string, state S) []*hitl.Interrupt {
+	if c.interrupts == nil {
+		return nil
+	}
+
+	var result []*hitl.Interrupt
+	for _, node := range nodes {
+		for _, point := range c.interrupts.GetInterruptPoints(node, kind) {
+			if point.ShouldInterrupt(state) {
+				interrupt := hitl.NewInterrupt(newInterruptID(), point, "")
+				interrupt.Reason = hitl.ReasonApprovalRequired
+				setInterruptNamespace(interrupt, ns)
+				result = append(result, interrupt)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// pause 保存中断检查点并返回 *InterruptError。
+//
+// 来自子图的中断保留子图检查点的信息。
+//
+func (c *CompiledGraph[S]) pause(
+	ctx context.Context,
+	p *persister[S],
+	x *execution[S],
+	write checkpointWrite[S],
+) error {
+	ierr := &InterruptError{ThreadID: x.threadID, Interrupts: write.interrupts}
+
+	if p != nil {
+		if err := p.pause(ctx, write); err != nil {
+			return err
+		}
+		ierr.CheckpointID = p.cp.parentID
+	}
+
+	for _, interrupt := range ierr.Interrupts {
+		if interruptNamespace(interrupt) != x.ns {
+			continue
+		}
+		interrupt.ThreadID = x.threadID
+		interrupt.CheckpointID = ierr.CheckpointID
+		interrupt.State = write.state
+	}
+
+	return ierr
+}
+
+// newDynamicInterrupt 根据节点中 Interrupt 的调用创建中断。
+func newDynamicInterrupt(ns, node string, payload any) *hitl.Interrupt {
+	interrupt := hitl.NewInterrupt(newInterruptID(), hitl.NewInterruptPoint(node, hitl.InterruptManual), "")
+	interrupt.Reason = hitl.ReasonInputRequired
+	interrupt.Payload = payload
+	setInterruptNamespace(interrupt, ns)
+	return interrupt
+}
+
+// setInterruptNamespace 记录中断所在子图的检查点命名空间（顶层图不记录）。
+func setInterruptNamespace(interrupt *hitl.Interrupt, ns string) {
+	if ns != "" {
+		interrupt.Metadata[interruptNamespaceKey] = ns
+	}
+}
+
+// interruptNamespace 返回中断所在子图的检查点命名空间，顶层图的中断返回空字符串。
+func interruptNamespace(interrupt *hitl.Interrupt) string {
+	ns, _ := interrupt.Metadata[interruptNamespaceKey].(string)
+	return ns
+}
+
+// newInterruptID 生成中断 ID。
+func newInterruptID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return "int-" + hex.EncodeToString(b)
+}
+
+// encodeInterrupts 把中断转换为可序列化的检查点元数据。
+func encodeInterrupts(interrupts []*hitl.Interrupt) []map[string]any {
+	result := make([]map[string]any, len(interrupts))
+	for i, interrupt := range interrupts {
+		result[i] = map[string]any{
+			"id":      interrupt.ID,
+			"node":    interrupt.GetNodeName(),
+			"type":    string(interrupt.Point.Type),
+			"reason":  string(interrupt.Reason),
+			"message": interrupt.GetMessage(),
+			"payload": interrupt.Payload,
+			"ns":      interruptNamespace(interrupt),
+
+			// 子图中断的检查点（当前图的中断在写入前还没有检查点 ID）
+			"checkpoint_id": interrupt.CheckpointID,
+		}
+	}
+	return result
+}
+
+// decodeInterrupts 从检查点元数据中读取等待处理的中断。
+func decodeInterrupts[S any](cp *checkpoint.Checkpoint[S]) []*hitl.Interrupt {
+	var items []map[string]any
+	switch v := cp.Metadata[metadataInterruptsKey].(type) {
+	case []map[string]any:
+		items = v
+	case []any:
+		for _, item := range v {
+			if m, ok := item.(map[string]any); ok {
+				items = append(items, m)
+			}
+		}
+	}
+
+	result := make([]*hitl.Interrupt, 0, len(items))
+	for _, item := range items {
+		id, _ := item["id"].(string)
+		node, _ := item["node"].(string)
+		kind, _ := item["type"].(string)
+		reason, _ := item["reason"].(string)
+		message, _ := item["message"].(string)
+		ns, _ := item["ns"].(string)
+		checkpointID, _ := item["checkpoint_id"].(string)
+
+		point := hitl.NewInterruptPoint(node, hitl.InterruptType(kind)).WithMessage(message)
+		interrupt := hitl.NewInterrupt(id, point, cp.ThreadID)
+		interrupt.Reason = hitl.InterruptReason(reason)
+		interrupt.Payload = item["payload"]
+		setInterruptNamespace(interrupt, ns)
+		interrupt.CheckpointID = checkpointID
+		if ns == cp.CheckpointNS {
+			// 子图的中断保留子图的检查点，不包含子图状态
+			interrupt.State = cp.State
+			interrupt.CheckpointID = cp.ID
+		}
+		interrupt.Timestamp = cp.Timestamp
+		result = append(result, interrupt)
+	}
+
+	return result
+}
+
+// metadataResume 读取元数据中的恢复值（返回副本）。
+func metadataResume(v any) map[string][]any {
+	result := make(map[string][]any)
+
+	switch m := v.(type) {
+	case map[string][]any:
+		for node, values := range m {
+			result[node] = append([]any(nil), values...)
+		}
+	case map[string]any:
+		for node, values := range m {
+			if list, ok := values.([]any); ok {
+				result[node] = append([]any(nil), list...)
+			}
+		}
+	}
+
+	return result
+}
+
+// interruptedNodes 返回中断所在的节点（排序、去重）。
+func interruptedNodes(interrupts []*hitl.Interrupt) []string {
+	seen := make(map[string]bool)
+	var nodes []string
+	for _, interrupt := range interrupts {
+		if node := interrupt.GetNodeName(); !seen[node] {
+			seen[node] = true
+			nodes = append(nodes, node)
+		}
+	}
+	sort.Strings(nodes)
+	return nodes
+}
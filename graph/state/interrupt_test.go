@@ -0,0 +1,270 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/zhucl121/langchain-go/graph/checkpoint"
+	"github.com/zhucl121/langchain-go/graph/hitl"
+)
+
+// jsonSaver 模拟 SQLite/Postgres：元数据经过 JSON 往返
+type jsonSaver[S any] struct {
+	*checkpoint.MemoryCheckpointSaver[S]
+}
+
+func (s *jsonSaver[S]) Save(ctx context.Context, cp *checkpoint.Checkpoint[S]) error {
+	data, err := json.Marshal(cp.Metadata)
+	if err != nil {
+		return err
+	}
+	cp.Metadata = make(map[string]any)
+	if err := json.Unmarshal(data, &cp.Metadata); err != nil {
+		return err
+	}
+	return s.MemoryCheckpointSaver.Save(ctx, cp)
+}
+
+// newApprovalGraph 创建 draft -> review -> send 的测试图，review 动态中断
+func newApprovalGraph(saver checkpoint.CheckpointSaver[TestState], calls map[string]int, opts ...CompileOption) *CompiledGraph[TestState] {
+	graph := NewStateGraph[TestState]("approval")
+
+	graph.AddNode("draft", func(ctx context.Context, s TestState) (TestState, error) {
+		calls["draft"]++
+		s.Message = "draft"
+		return s, nil
+	})
+	graph.AddNode("review", func(ctx context.Context, s TestState) (TestState, error) {
+		calls["review"]++
+		answer, err := Interrupt(ctx, "approve "+s.Message+"?")
+		if err != nil {
+			return s, err
+		}
+		s.Done = answer == "yes"
+		return s, nil
+	})
+	graph.AddNode("send", func(ctx context.Context, s TestState) (TestState, error) {
+		calls["send"]++
+		s.Counter++
+		return s, nil
+	})
+
+	graph.SetEntryPoint("draft")
+	graph.AddEdge("draft", "review")
+	graph.AddEdge("review", "send")
+	graph.AddEdge("send", END)
+	graph.WithCheckpointer(saver)
+
+	compiled, _ := graph.Compile(opts...)
+	return compiled
+}
+
+// TestInterrupt_Dynamic 测试节点动态中断和跨进程恢复
+func TestInterrupt_Dynamic(t *testing.T) {
+	ctx := context.Background()
+	saver := &jsonSaver[TestState]{checkpoint.NewMemoryCheckpointSaver[TestState]()}
+	calls := make(map[string]int)
+
+	_, err := newApprovalGraph(saver, calls).Invoke(ctx, TestState{}, WithThreadID("t-1"))
+	var ierr *InterruptError
+	if !errors.As(err, &ierr) || !errors.Is(err, hitl.ErrInterrupted) {
+		t.Fatalf("expected InterruptError, got %v", err)
+	}
+	if len(ierr.Interrupts) != 1 || ierr.Interrupts[0].GetNodeName() != "review" {
+		t.Fatalf("unexpected interrupts: %+v", ierr.Interrupts)
+	}
+	if ierr.Interrupts[0].Payload != "approve draft?" || ierr.CheckpointID == "" {
+		t.Errorf("unexpected interrupt: %+v", ierr.Interrupts[0])
+	}
+
+	// 另一个进程：新的已编译图，共享检查点存储
+	other := newApprovalGraph(saver, calls)
+
+	pending, err := other.PendingInterrupts(ctx, "t-1")
+	if err != nil || len(pending) != 1 || pending[0].Payload != "approve draft?" {
+		t.Fatalf("unexpected pending interrupts: %v, %v", pending, err)
+	}
+
+	// 未恢复前再次 Invoke 返回同一个中断
+	if _, err := other.Invoke(ctx, TestState{}, WithThreadID("t-1")); !errors.Is(err, hitl.ErrInterrupted) {
+		t.Fatalf("expected interrupt again, got %v", err)
+	}
+	if calls["review"] != 1 {
+		t.Errorf("review should not run before resume, ran %d times", calls["review"])
+	}
+
+	result, err := other.ResumeWithInput(ctx, "t-1", "yes")
+	if err != nil {
+		t.Fatalf("resume failed: %v", err)
+	}
+	if !result.Done || result.Counter != 1 || result.Message != "draft" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if calls["draft"] != 1 || calls["review"] != 2 || calls["send"] != 1 {
+		t.Errorf("unexpected calls: %v", calls)
+	}
+
+	if pending, _ := other.PendingInterrupts(ctx, "t-1"); len(pending) != 0 {
+		t.Errorf("expected no pending interrupts, got %d", len(pending))
+	}
+	if _, err := other.ResumeWithInput(ctx, "t-1", "yes"); !errors.Is(err, hitl.ErrNoInterrupt) {
+		t.Errorf("expected ErrNoInterrupt, got %v", err)
+	}
+}
+
+// TestInterrupt_Before 测试节点执行前中断和修改状态后恢复
+func TestInterrupt_Before(t *testing.T) {
+	ctx := context.Background()
+	saver := checkpoint.NewMemoryCheckpointSaver[TestState]()
+	calls := make(map[string]int)
+	compiled := newApprovalGraph(saver, calls, InterruptBefore("send"))
+
+	if _, err := compiled.Invoke(ctx, TestState{}, WithThreadID("t-1")); !errors.Is(err, hitl.ErrInterrupted) {
+		t.Fatalf("expected review interrupt, got %v", err)
+	}
+
+	_, err := compiled.ResumeWithInput(ctx, "t-1", "yes")
+	var ierr *InterruptError
+	if !errors.As(err, &ierr) || ierr.Interrupts[0].GetNodeName() != "send" {
+		t.Fatalf("expected interrupt before send, got %v", err)
+	}
+	if ierr.Interrupts[0].Point.Type != hitl.InterruptBefore || calls["send"] != 0 {
+		t.Errorf("send should not have run: %+v", ierr.Interrupts[0])
+	}
+
+	modified := ierr.Interrupts[0].State.(TestState)
+	modified.Message = "edited"
+	result, err := compiled.ResumeWithModifiedState(ctx, "t-1", modified)
+	if err != nil {
+		t.Fatalf("resume failed: %v", err)
+	}
+	if result.Message != "edited" || result.Counter != 1 || calls["send"] != 1 {
+		t.Errorf("unexpected result %+v, calls %v", result, calls)
+	}
+}
+
+// TestInterrupt_After 测试节点执行后中断
+func TestInterrupt_After(t *testing.T) {
+	ctx := context.Background()
+	saver := checkpoint.NewMemoryCheckpointSaver[TestState]()
+	graph := NewStateGraph[TestState]("after")
+	calls := 0
+
+	graph.AddNode("a", func(ctx context.Context, s TestState) (TestState, error) {
+		calls++
+		s.Counter++
+		return s, nil
+	})
+	graph.AddNode("b", func(ctx context.Context, s TestState) (TestState, error) {
+		s.Counter += 10
+		return s, nil
+	})
+	graph.SetEntryPoint("a")
+	graph.AddEdge("a", "b")
+	graph.AddEdge("b", END)
+	graph.WithCheckpointer(saver)
+
+	compiled, err := graph.Compile(InterruptAfter("a", "b"))
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	state, err := compiled.Invoke(ctx, TestState{}, WithThreadID("t-1"))
+	if !errors.Is(err, hitl.ErrInterrupted) || state.Counter != 1 {
+		t.Fatalf("expected interrupt after a with Counter=1, got %+v, %v", state, err)
+	}
+
+	// b 之后执行已结束，不再中断
+	result, err := compiled.ResumeWithInput(ctx, "t-1", nil)
+	if err != nil {
+		t.Fatalf("resume failed: %v", err)
+	}
+	if result.Counter != 11 || calls != 1 {
+		t.Errorf("unexpected result %+v, a ran %d times", result, calls)
+	}
+}
+
+// TestInterrupt_Condition 测试带条件的中断点
+func TestInterrupt_Condition(t *testing.T) {
+	ctx := context.Background()
+	saver := checkpoint.NewMemoryCheckpointSaver[TestState]()
+	point := hitl.NewInterruptPoint("send", hitl.InterruptBefore).
+		WithCondition(func(s any) bool { return s.(TestState).Message == "large" }).
+		WithMessage("needs approval")
+
+	graph := NewStateGraph[TestState]("condition")
+	graph.AddNode("send", func(ctx context.Context, s TestState) (TestState, error) {
+		s.Done = true
+		return s, nil
+	})
+	graph.SetEntryPoint("send")
+	graph.AddEdge("send", END)
+	graph.WithCheckpointer(saver)
+
+	compiled, err := graph.Compile(WithInterruptPoints(point))
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	if result, err := compiled.Invoke(ctx, TestState{Message: "small"}, WithThreadID("t-1")); err != nil || !result.Done {
+		t.Errorf("expected no interrupt, got %+v, %v", result, err)
+	}
+
+	_, err = compiled.Invoke(ctx, TestState{Message: "large"}, WithThreadID("t-2"))
+	var ierr *InterruptError
+	if !errors.As(err, &ierr) || ierr.Interrupts[0].GetMessage() != "needs approval" {
+		t.Fatalf("expected interrupt with message, got %v", err)
+	}
+
+	// 中止后线程结束
+	result, err := compiled.Resume(ctx, "t-2", hitl.NewResolution(hitl.ActionAbort))
+	if err != nil || result.Done {
+		t.Errorf("expected abort without running send, got %+v, %v", result, err)
+	}
+	if pending, _ := compiled.PendingInterrupts(ctx, "t-2"); len(pending) != 0 {
+		t.Errorf("expected no pending interrupts after abort")
+	}
+}
+
+// TestInterrupt_Stream 测试流式执行中的中断事件
+func TestInterrupt_Stream(t *testing.T) {
+	saver := checkpoint.NewMemoryCheckpointSaver[TestState]()
+	compiled := newApprovalGraph(saver, make(map[string]int))
+
+	events, err := compiled.Stream(context.Background(), TestState{}, StreamModeValues, WithThreadID("t-1"))
+	if err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+
+	var last StreamEvent[TestState]
+	for event := range events {
+		last = event
+	}
+	if last.Type != StreamEventInterrupt || !errors.Is(last.Error, hitl.ErrInterrupted) {
+		t.Errorf("expected interrupt event, got %+v", last)
+	}
+}
+
+// TestInterrupt_CompileErrors 测试中断点校验
+func TestInterrupt_CompileErrors(t *testing.T) {
+	graph := NewStateGraph[TestState]("invalid")
+	graph.AddNode("a", func(ctx context.Context, s TestState) (TestState, error) {
+		return s, nil
+	})
+	graph.SetEntryPoint("a")
+	graph.AddEdge("a", END)
+
+	if _, err := graph.Compile(InterruptBefore("missing")); !errors.Is(err, ErrNodeNotFound) {
+		t.Errorf("expected ErrNodeNotFound, got %v", err)
+	}
+	if _, err := graph.Compile(WithInterruptPoints(hitl.NewInterruptPoint("a", hitl.InterruptOnError))); !errors.Is(err, ErrInvalidInterrupt) {
+		t.Errorf("expected ErrInvalidInterrupt, got %v", err)
+	}
+
+	compiled, _ := graph.Compile()
+	if _, err := compiled.ResumeWithInput(context.Background(), "t-1", "yes"); !errors.Is(err, ErrNoCheckpointer) {
+		t.Errorf("expected ErrNoCheckpointer, got %v", err)
+	}
+}
@@ -0,0 +1,332 @@
+package state
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// mergeUpdates 合并一个超步中多个任务的输出。
+//
+// 合并规则：
+//   - 只有一个输出且不是 Send 时直接使用该输出
+//   - 配置了 WithReducer 时，调用 reducer(input, outputs...)
+//   - 否则按字段合并（结构体的导出字段或 map[string]V 的键）：
+//     注册了 Channel 的字段依次用各任务的增量调用 Channel.Update；
+//     其他字段（以及 LastValueChannel）由最后一个写入的任务决定
+//
+// 任务"写入"某个字段是指它的输出与它自己的输入不同：
+// 按名称触发的节点的输入是本步输入，Send 的输入是 Send.State。
+// 传给 Channel 的增量是任务相对于自己输入的变化：
+//   - 切片：追加到末尾的元素
+//   - map：新增或修改的键值
+//   - 数值：差值
+//   - 其他类型：输出值本身
+//
+// outputs 的顺序即任务顺序（节点按名称排序，Send 按调度顺序在后），保证合并结果稳定。
+//
+// 参数：
+//   - input: 本步输入状态
+//   - inputs: 各任务的输入状态；为 nil 时都是 input
+//   - outputs: 各任务的输出状态
+//   - reducer: 归约器（可选）
+//   - channels: 字段的通道
+//
+func mergeUpdates[S any](
+	input S,
+	inputs []S,
+	outputs []S,
+	reducer Reducer[S],
+	channels map[string]Channel,
+) (S, error) {
+	if len(outputs) == 1 && inputs == nil {
+		return outputs[0], nil
+	}
+
+	if reducer != nil {
+		return reducer(input, outputs...), nil
+	}
+
+	in := reflect.ValueOf(&input).Elem()
+	outs := make([]reflect.Value, len(outputs))
+	bases := make([]reflect.Value, len(outputs))
+	for i := range outputs {
+		outs[i] = reflect.ValueOf(&outputs[i]).Elem()
+		bases[i] = in
+		if inputs != nil {
+			bases[i] = reflect.ValueOf(&inputs[i]).Elem()
+		}
+	}
+
+	switch in.Kind() {
+	case reflect.Struct:
+		merged, err := mergeStruct(in, bases, outs, channels)
+		if err != nil {
+			return input, err
+		}
+		return merged.Interface().(S), nil
+
+	case reflect.Map:
+		if in.Type().Key().Kind() != reflect.String {
+			break
+		}
+		merged, err := mergeMap(in, bases, outs, channels)
+		if err != nil {
+			return input, err
+		}
+		return merged.Interface().(S), nil
+	}
+
+	// 无法按字段合并的状态：最后一个写入的任务生效
+	result := input
+	for i, out := range outs {
+		if !reflect.DeepEqual(out.Interface(), bases[i].Interface()) {
+			result = outputs[i]
+		}
+	}
+	return result, nil
+}
+
+// fieldWrite 是一个任务对字段的写入。
+type fieldWrite struct {
+	base  reflect.Value // 任务输入中的值
+	value reflect.Value // 任务输出中的值（map 中删除的键为无效值）
+}
+
+// mergeStruct 按导出字段合并结构体状态。
+//
+// 未导出字段保留本步输入的值。
+//
+func mergeStruct(in reflect.Value, bases, outs []reflect.Value, channels map[string]Channel) (reflect.Value, error) {
+	result := reflect.New(in.Type()).Elem()
+	result.Set(in)
+
+	for i := 0; i < in.NumField(); i++ {
+		field := in.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		writes := make([]fieldWrite, 0, len(outs))
+		for j, out := range outs {
+			base := bases[j].Field(i)
+			if w := out.Field(i); !reflect.DeepEqual(w.Interface(), base.Interface()) {
+				writes = append(writes, fieldWrite{base: base, value: w})
+			}
+		}
+
+		if len(writes) == 0 {
+			continue
+		}
+
+		value, err := mergeField(field.Name, in.Field(i), writes, channels[field.Name])
+		if err != nil {
+			return result, err
+		}
+
+		converted, err := convertTo(value, field.Type)
+		if err != nil {
+			return result, fmt.Errorf("%w: field %s: %v", ErrInvalidChannelUpdate, field.Name, err)
+		}
+		result.Field(i).Set(converted)
+	}
+
+	return result, nil
+}
+
+// mergeMap 按键合并 map 状态。
+//
+// 任务删除某个键也算作写入；最后一个写入是删除时，合并结果中删除该键。
+//
+func mergeMap(in reflect.Value, bases, outs []reflect.Value, channels map[string]Channel) (reflect.Value, error) {
+	mapType := in.Type()
+	result := reflect.MakeMapWithSize(mapType, in.Len())
+	keys := make(map[string]reflect.Value)
+
+	iter := in.MapRange()
+	for iter.Next() {
+		result.SetMapIndex(iter.Key(), iter.Value())
+		keys[iter.Key().String()] = iter.Key()
+	}
+	for _, m := range append(append([]reflect.Value(nil), bases...), outs...) {
+		iter := m.MapRange()
+		for iter.Next() {
+			keys[iter.Key().String()] = iter.Key()
+		}
+	}
+
+	for _, name := range sortedMapKeys(keys) {
+		key := keys[name]
+
+		writes := make([]fieldWrite, 0, len(outs))
+		for j, out := range outs {
+			base := bases[j].MapIndex(key)
+			w := out.MapIndex(key)
+			if !sameMapValue(w, base) {
+				writes = append(writes, fieldWrite{base: unwrapInterface(base), value: unwrapInterface(w)})
+			}
+		}
+
+		if len(writes) == 0 {
+			continue
+		}
+
+		// 最后一个写入是删除
+		channel := channels[name]
+		if last := writes[len(writes)-1]; !last.value.IsValid() && (channel == nil || isLastValueChannel(channel)) {
+			result.SetMapIndex(key, reflect.Value{})
+			continue
+		}
+
+		value, err := mergeField(name, unwrapInterface(in.MapIndex(key)), writes, channel)
+		if err != nil {
+			return result, err
+		}
+
+		converted, err := convertTo(value, mapType.Elem())
+		if err != nil {
+			return result, fmt.Errorf("%w: key %s: %v", ErrInvalidChannelUpdate, name, err)
+		}
+		result.SetMapIndex(key, converted)
+	}
+
+	return result, nil
+}
+
+// mergeField 合并单个字段的多个写入。
+//
+// current 是本步输入中的值，各写入的增量相对于写入任务自己的输入计算。
+//
+func mergeField(name string, current reflect.Value, writes []fieldWrite, channel Channel) (reflect.Value, error) {
+	if channel == nil || isLastValueChannel(channel) {
+		return writes[len(writes)-1].value, nil
+	}
+
+	var acc any
+	if current.IsValid() {
+		acc = current.Interface()
+	}
+
+	for _, w := range writes {
+		if !w.value.IsValid() {
+			continue
+		}
+
+		var err error
+		acc, err = channel.Update(acc, fieldDelta(w.base, w.value).Interface())
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("state: merge %s: %w", name, err)
+		}
+	}
+
+	return reflect.ValueOf(acc), nil
+}
+
+// fieldDelta 计算写入值相对于基准值的增量。
+func fieldDelta(base, written reflect.Value) reflect.Value {
+	if !base.IsValid() || base.Type() != written.Type() {
+		return written
+	}
+
+	switch written.Kind() {
+	case reflect.Slice:
+		n := base.Len()
+		if written.Len() >= n && reflect.DeepEqual(written.Slice(0, n).Interface(), base.Interface()) {
+			return written.Slice(n, written.Len())
+		}
+		return written
+
+	case reflect.Map:
+		delta := reflect.MakeMap(written.Type())
+		iter := written.MapRange()
+		for iter.Next() {
+			old := base.MapIndex(iter.Key())
+			if !old.IsValid() || !reflect.DeepEqual(old.Interface(), iter.Value().Interface()) {
+				delta.SetMapIndex(iter.Key(), iter.Value())
+			}
+		}
+		return delta
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		delta := reflect.New(written.Type()).Elem()
+		delta.SetInt(written.Int() - base.Int())
+		return delta
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		delta := reflect.New(written.Type()).Elem()
+		delta.SetUint(written.Uint() - base.Uint())
+		return delta
+
+	case reflect.Float32, reflect.Float64:
+		delta := reflect.New(written.Type()).Elem()
+		delta.SetFloat(written.Float() - base.Float())
+		return delta
+
+	default:
+		return written
+	}
+}
+
+// convertTo 将合并结果转换为字段类型。
+//
+// Channel 返回 []any 而字段是具体类型的切片时，逐个元素转换。
+//
+func convertTo(value reflect.Value, target reflect.Type) (reflect.Value, error) {
+	if !value.IsValid() {
+		return reflect.Zero(target), nil
+	}
+
+	if value.Type().AssignableTo(target) {
+		return value, nil
+	}
+
+	if value.Kind() == reflect.Slice && target.Kind() == reflect.Slice {
+		result := reflect.MakeSlice(target, 0, value.Len())
+		for i := 0; i < value.Len(); i++ {
+			elem, err := convertTo(unwrapInterface(value.Index(i)), target.Elem())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			result = reflect.Append(result, elem)
+		}
+		return result, nil
+	}
+
+	if value.Type().ConvertibleTo(target) {
+		return value.Convert(target), nil
+	}
+
+	return reflect.Value{}, fmt.Errorf("cannot use %s as %s", value.Type(), target)
+}
+
+// sameMapValue 判断两个 map 值（可能不存在）是否相同。
+func sameMapValue(a, b reflect.Value) bool {
+	if !a.IsValid() || !b.IsValid() {
+		return a.IsValid() == b.IsValid()
+	}
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
+
+// unwrapInterface 取出接口值中的具体值，便于计算增量。
+func unwrapInterface(v reflect.Value) reflect.Value {
+	if v.IsValid() && v.Kind() == reflect.Interface {
+		return v.Elem()
+	}
+	return v
+}
+
+// isLastValueChannel 判断通道是否为覆盖通道。
+func isLastValueChannel(channel Channel) bool {
+	_, ok := channel.(*LastValueChannel)
+	return ok
+}
+
+// sortedMapKeys 返回排序后的键。
+func sortedMapKeys(m map[string]reflect.Value) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
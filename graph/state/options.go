@@ -0,0 +1,187 @@
+package state
+
+import (
+	"fmt"
+
+	"github.com/zhucl121/langchain-go/core/callbacks"
+	"github.com/zhucl121/langchain-go/graph/hitl"
+	"github.com/zhucl121/langchain-go/graph/visualization"
+)
+
+// InvokeConfig 是单次执行的配置。
+//
+// InvokeConfig 由 InvokeOption 构建，控制一次 Invoke 调用的行为。
+//
+type InvokeConfig struct {
+	// ThreadID 线程标识
+	//
+	// 配置了检查点保存器时，同一 ThreadID 的多次执行共享检查点，
+	// 后一次执行会从上一次最后完成的节点继续。
+	// 为空时不读写检查点。
+	ThreadID string
+
+	// CheckpointID 执行的起点检查点
+	//
+	// 为空时从线程的最新检查点继续。指定较早的检查点时，
+	// 从该检查点重新执行（replay），新的检查点形成以它为父节点的分支。
+	CheckpointID string
+
+	// Tracer 记录本次执行经过的节点、重试和中断（可选）
+	Tracer *visualization.ExecutionTracer
+
+	// Callbacks 本次执行的回调处理器，与上下文中的处理器一起接收事件
+	Callbacks []callbacks.Handler
+}
+
+// InvokeOption 是执行选项。
+type InvokeOption func(*InvokeConfig)
+
+// WithThreadID 设置执行所属的线程。
+//
+// 参数：
+//   - threadID: 线程标识
+//
+// 返回：
+//   - InvokeOption: 执行选项
+//
+// 示例：
+//
+//	result, err := compiled.Invoke(ctx, initialState, state.WithThreadID("user-123"))
+//
+func WithThreadID(threadID string) InvokeOption {
+	return func(c *InvokeConfig) {
+		c.ThreadID = threadID
+	}
+}
+
+// WithCheckpointID 指定执行的起点检查点。
+//
+// 用于时间旅行：从历史上的任意检查点重新执行，或读取该检查点的状态。
+//
+// 示例：
+//
+//	history, _ := compiled.GetHistory(ctx, "user-123", 0)
+//	result, err := compiled.Invoke(ctx, initialState,
+//	    state.WithThreadID("user-123"),
+//	    state.WithCheckpointID(history[2].CheckpointID))
+//
+func WithCheckpointID(checkpointID string) InvokeOption {
+	return func(c *InvokeConfig) {
+		c.CheckpointID = checkpointID
+	}
+}
+
+// WithTracer 记录本次执行的路径。
+//
+// 每个节点执行完成、失败或中断时写入一条记录，持久化任务记录尝试次数。
+// 子图内部的节点不记录。
+//
+// 示例：
+//
+//	tracer := compiled.NewTracer()
+//	_, err := compiled.Invoke(ctx, initialState, state.WithTracer(tracer))
+//	fmt.Println(tracer.ToMermaidWithPath())
+//
+func WithTracer(tracer *visualization.ExecutionTracer) InvokeOption {
+	return func(c *InvokeConfig) {
+		c.Tracer = tracer
+	}
+}
+
+// WithCallbacks 为本次执行添加回调处理器。
+//
+// 图的执行发送 OnChainStart / OnChainEnd，每个节点发送 OnNodeStart / OnNodeEnd，
+// 节点中的模型、工具和检索器调用是节点的子运行。
+// 效果与 callbacks.WithHandlers(ctx, handlers...) 相同。
+//
+// 示例：
+//
+//	result, err := compiled.Invoke(ctx, initialState, state.WithCallbacks(recorder))
+//
+func WithCallbacks(handlers ...callbacks.Handler) InvokeOption {
+	return func(c *InvokeConfig) {
+		c.Callbacks = append(c.Callbacks, handlers...)
+	}
+}
+
+// newInvokeConfig 从执行选项构建配置。
+//
+// 选项参数的类型为 interface{} 以实现 node.SubgraphExecutor，
+// 不是 InvokeOption 的选项返回 ErrInvalidOption，避免选项被静默忽略。
+//
+func newInvokeConfig(opts ...interface{}) (*InvokeConfig, error) {
+	config := &InvokeConfig{}
+
+	for _, opt := range opts {
+		switch o := opt.(type) {
+		case InvokeOption:
+			o(config)
+		case func(*InvokeConfig):
+			o(config)
+		default:
+			return nil, fmt.Errorf("%w: %T", ErrInvalidOption, opt)
+		}
+	}
+
+	return config, nil
+}
+
+// CompileConfig 是编译配置。
+//
+// CompileConfig 由 CompileOption 构建，控制已编译图的执行行为。
+//
+type CompileConfig struct {
+	// InterruptPoints 静态中断点（仅支持 hitl.InterruptBefore 和 hitl.InterruptAfter）
+	InterruptPoints []*hitl.InterruptPoint
+}
+
+// CompileOption 是编译选项。
+type CompileOption func(*CompileConfig)
+
+// InterruptBefore 在指定节点执行前中断。
+//
+// 执行到这些节点之前保存检查点并返回 *InterruptError，
+// 之后用 ResumeWithInput / ResumeWithModifiedState 恢复。
+//
+// 示例：
+//
+//	compiled, err := graph.Compile(state.InterruptBefore("send_email"))
+//
+func InterruptBefore(nodes ...string) CompileOption {
+	return func(c *CompileConfig) {
+		for _, node := range nodes {
+			c.InterruptPoints = append(c.InterruptPoints, hitl.NewInterruptPoint(node, hitl.InterruptBefore))
+		}
+	}
+}
+
+// InterruptAfter 在指定节点执行后中断。
+//
+// 节点所在的超步完成后保存检查点并返回 *InterruptError，
+// 恢复时从下一步继续，节点不会重复执行。
+//
+func InterruptAfter(nodes ...string) CompileOption {
+	return func(c *CompileConfig) {
+		for _, node := range nodes {
+			c.InterruptPoints = append(c.InterruptPoints, hitl.NewInterruptPoint(node, hitl.InterruptAfter))
+		}
+	}
+}
+
+// WithInterruptPoints 添加自定义中断点。
+//
+// 可以通过 InterruptPoint.WithCondition 只在满足条件时中断，
+// 条件函数接收节点执行前（before）或所在超步完成后（after）的状态。
+//
+// 示例：
+//
+//	point := hitl.NewInterruptPoint("transfer", hitl.InterruptBefore).
+//	    WithCondition(func(s any) bool { return s.(MyState).Amount > 1000 }).
+//	    WithMessage("大额转账需要审批")
+//	compiled, err := graph.Compile(state.WithInterruptPoints(point))
+//
+func WithInterruptPoints(points ...*hitl.InterruptPoint) CompileOption {
+	return func(c *CompileConfig) {
+		c.InterruptPoints = append(c.InterruptPoints, points...)
+	}
+}
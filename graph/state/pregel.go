@@ -0,0 +1,357 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/zhucl121/langchain-go/core/callbacks"
+	"github.com/zhucl121/langchain-go/graph/durability"
+	"github.com/zhucl121/langchain-go/graph/hitl"
+	"github.com/zhucl121/langchain-go/graph/visualization"
+)
+
+// execution 是一次图执行中各超步共享的信息。
+type execution[S any] struct {
+	threadID string
+	ns       string // 检查点命名空间（顶层图为空）
+	execCtx  *durability.ExecutionContext
+	em       *emitter[S]                    // 流式事件输出（Invoke 时为 nil）
+	tracer   *visualization.ExecutionTracer // 执行路径记录（可选）
+	saver    any                            // 使用的检查点保存器，传给节点中的子图（可选）
+
+	// record 在超步中途写入已完成任务的输出（未启用检查点时为 nil）
+	record func(writes map[string]S) error
+}
+
+// resumeStep 是从中断恢复的超步提供给节点的恢复信息。
+type resumeStep struct {
+	values map[string][]any // 各节点中 Interrupt 的恢复值
+	input  any              // 恢复输入，传给节点中的子图
+}
+
+// runStep 并行执行一个超步中的所有任务。
+//
+// 按名称触发的节点以本步输入状态开始执行，Send 以各自的输入状态开始执行；
+// 执行完成后按任务顺序合并输出。配置了调度器时，每个任务执行前先获取槽位。
+// 任意任务失败时取消同一超步中的其他任务，返回第一个失败任务（按任务顺序）的错误，
+// 状态保持为本步输入。节点调用 Interrupt 或节点中的子图被中断时返回 *InterruptError，
+// 恢复后整个超步重新执行（已完成的 Send 和不能重复执行的持久化任务除外）。
+// 不能重复执行的持久化任务与其他任务并行时，完成后立即通过 x.record 写入输出。
+//
+// 参数：
+//   - ctx: 上下文
+//   - x: 本次执行的信息
+//   - step: 超步序号
+//   - tasks: 本步要执行的任务
+//   - state: 本步输入状态
+//   - resume: 从中断恢复时的恢复信息（其他步骤为 nil）
+//   - done: 之前已完成的任务的输出（按任务标识），这些任务不再执行
+//
+// 返回：
+//   - S: 合并后的状态
+//   - map[string]S: 失败或中断时已完成的 Send 和持久化任务的输出（包括 done）
+//   - error: 执行或合并错误
+//
+func (c *CompiledGraph[S]) runStep(
+	ctx context.Context,
+	x *execution[S],
+	step int,
+	tasks []stepTask[S],
+	state S,
+	resume *resumeStep,
+	done map[string]S,
+) (S, map[string]S, error) {
+	for _, task := range tasks {
+		if _, exists := c.graph.nodes[task.node]; !exists {
+			return state, done, fmt.Errorf("%w: %s", ErrNodeNotFound, task.node)
+		}
+	}
+
+	outputs := make([]S, len(tasks))
+	errs := make([]error, len(tasks))
+	finished := make([]bool, len(tasks))
+
+	// recorded 是已写入检查点的任务输出（包括 done）
+	var recordMu sync.Mutex
+	recorded := maps.Clone(done)
+
+	run := func(ctx context.Context, i int) {
+		if output, ok := done[tasks[i].key]; ok {
+			outputs[i], finished[i] = output, true
+			return
+		}
+
+		if c.graph.scheduler != nil {
+			release, err := c.graph.scheduler.Acquire(ctx)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			defer release()
+		}
+
+		outputs[i], errs[i] = c.observeNode(ctx, x, step, tasks[i], resume)
+		finished[i] = errs[i] == nil
+
+		// 同一步的其他任务可能还在执行，先写入这个任务的输出
+		if finished[i] && len(tasks) > 1 && x.record != nil && c.recordsCompletion(tasks[i]) {
+			recordMu.Lock()
+			defer recordMu.Unlock()
+
+			if recorded == nil {
+				recorded = make(map[string]S)
+			}
+			recorded[tasks[i].key] = outputs[i]
+			errs[i] = x.record(maps.Clone(recorded))
+		}
+	}
+
+	if len(tasks) == 1 {
+		run(ctx, 0)
+	} else {
+		stepCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		var wg sync.WaitGroup
+		for i := range tasks {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+
+				run(stepCtx, i)
+				if errs[i] != nil {
+					cancel()
+				}
+			}(i)
+		}
+		wg.Wait()
+	}
+
+	// 动态中断优先于其他错误（被中断取消的兄弟节点会返回 context.Canceled）
+	var interrupts []*hitl.Interrupt
+	for i, err := range errs {
+		var ni *nodeInterrupt
+		var child *InterruptError
+		switch {
+		case errors.As(err, &ni):
+			interrupts = append(interrupts, newDynamicInterrupt(x.ns, tasks[i].key, ni.payload))
+		case errors.As(err, &child):
+			// 子图中的中断传递给父图
+			interrupts = append(interrupts, child.Interrupts...)
+		}
+	}
+	if len(interrupts) > 0 {
+		return state, c.completedWrites(x, tasks, outputs, finished), &InterruptError{Interrupts: interrupts}
+	}
+
+	for i, err := range errs {
+		if err != nil {
+			return state, c.completedWrites(x, tasks, outputs, finished), fmt.Errorf("error executing node %s: %w", tasks[i].key, err)
+		}
+	}
+
+	x.em.updates(step, tasks, outputs)
+
+	// Send 的输出相对于各自的输入合并
+	// 有 Send 时（Send 总在最后），各输出相对于各自的输入合并
+	var inputs []S
+	if tasks[len(tasks)-1].send {
+		inputs = make([]S, len(tasks))
+		for i, task := range tasks {
+			inputs[i] = task.input
+		}
+	}
+
+	merged, err := mergeUpdates(state, inputs, outputs, c.graph.reducer, c.graph.channels)
+	return merged, nil, err
+}
+
+// completedWrites 返回已完成的 Send 和持久化任务的输出（按任务标识）。
+func (c *CompiledGraph[S]) completedWrites(x *execution[S], tasks []stepTask[S], outputs []S, finished []bool) map[string]S {
+	var result map[string]S
+	for i, task := range tasks {
+		if !finished[i] || !(task.send || c.recordsCompletion(task)) {
+			continue
+		}
+		if result == nil {
+			result = make(map[string]S)
+		}
+		result[task.key] = outputs[i]
+	}
+	return result
+}
+
+// recordsCompletion 判断任务是否为持久化任务（AddTask 添加），完成后需要记录输出。
+//
+// 幂等和非幂等的持久化任务都记录，恢复时已完成的任务不再执行。
+func (c *CompiledGraph[S]) recordsCompletion(task stepTask[S]) bool {
+	return c.graph.nodes[task.node].Task != nil
+}
+
+// observeNode 执行单个任务并输出节点事件和 token 事件。
+//
+// 节点的上下文中携带 nodeScope：节点中的 Interrupt 按调用顺序返回恢复值，
+// 节点中执行的子图使用父图的线程和以任务标识命名的检查点命名空间。
+//
+func (c *CompiledGraph[S]) observeNode(
+	ctx context.Context,
+	x *execution[S],
+	step int,
+	task stepTask[S],
+	resume *resumeStep,
+) (S, error) {
+	x.em.nodeStart(step, task.node)
+	start := time.Now()
+
+	scope := &nodeScope{threadID: x.threadID, ns: subgraphNamespace(x.ns, task.key), saver: x.saver}
+	if resume != nil {
+		scope.resuming = true
+		scope.input = resume.input
+		scope.resume = resume.values[task.key]
+	}
+
+	nodeCtx := withNodeScope(x.em.nodeContext(ctx, step, task.node), scope)
+	node := c.graph.nodes[task.node]
+	if node.Task != nil {
+		node.Task = scheduledTask(node.Task, task.key, step)
+	}
+	nodeCtx, run := callbacks.StartNode(nodeCtx, task.node, task.input)
+	output, err := executeNode(nodeCtx, node, task.input, x.execCtx)
+	run.EndNode(nodeCtx, output, err)
+
+	x.em.nodeDone(step, task.node, output, time.Since(start), err)
+	x.traceNode(step, node, err)
+	return output, err
+}
+
+// nextNodes 计算下一个超步要执行的节点和 Send。
+//
+// 本步每个节点的所有出边都会被触发：
+//   - 普通边：全部目标
+//   - 条件边：路径函数选中的目标
+//   - 分支边：BranchEdge.Select 选中的所有目标
+//   - Send 边：路由函数返回的所有 Send
+//
+// 同一个目标被多个节点触发时只执行一次（fan-in），Send 则每个都执行一次。
+// 指向 END 的目标不会加入下一步；都为空表示执行结束。
+//
+func (c *CompiledGraph[S]) nextNodes(nodes []string, state S) ([]string, []Send[S], error) {
+	next := make(map[string]bool)
+	var sends []Send[S]
+
+	for _, name := range nodes {
+		targets, routed, err := c.successors(name, state)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, target := range targets {
+			if target != END {
+				next[target] = true
+			}
+		}
+		sends = append(sends, routed...)
+	}
+
+	result := make([]string, 0, len(next))
+	for name := range next {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+
+	return result, sends, nil
+}
+
+// successors 返回单个节点在给定状态下触发的目标和 Send。
+func (c *CompiledGraph[S]) successors(currentNode string, state S) ([]string, []Send[S], error) {
+	var targets []string
+
+	// 条件边
+	for _, conditional := range c.graph.conditionals {
+		if conditional.Source != currentNode {
+			continue
+		}
+
+		pathName := conditional.Path(state)
+		target, exists := conditional.PathMap[pathName]
+		if !exists {
+			return nil, nil, fmt.Errorf("state: no target for path %s from node %s", pathName, currentNode)
+		}
+		targets = append(targets, target)
+	}
+
+	// 分支边
+	for _, branch := range c.graph.branches {
+		if branch.GetSource() != currentNode {
+			continue
+		}
+
+		selected, err := branch.Select(state)
+		if err != nil {
+			return nil, nil, fmt.Errorf("state: branch from node %s: %w", currentNode, err)
+		}
+		targets = append(targets, selected...)
+	}
+
+	// Send 边
+	var sends []Send[S]
+	hasSendEdge := false
+	for _, edge := range c.graph.sends {
+		if edge.Source != currentNode {
+			continue
+		}
+
+		hasSendEdge = true
+		for _, send := range edge.Route(state) {
+			if !containsString(edge.Targets, send.Node) {
+				return nil, nil, fmt.Errorf("%w: send to undeclared node %s from node %s", ErrInvalidEdge, send.Node, currentNode)
+			}
+			sends = append(sends, send)
+		}
+	}
+
+	// 普通边
+	for _, edge := range c.graph.edges {
+		if edge.From == currentNode {
+			targets = append(targets, edge.To)
+		}
+	}
+
+	if len(targets) > 0 || hasSendEdge {
+		return targets, sends, nil
+	}
+
+	// 如果没有找到边，且当前节点是结束点，返回 END
+	if c.graph.finishPoints[currentNode] {
+		return []string{END}, nil, nil
+	}
+
+	// 没有找到出边
+	return nil, nil, fmt.Errorf("state: no outgoing edge from node %s", currentNode)
+}
+
+// containsString 判断列表中是否包含 s。
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// stepTasks 返回本步节点中的持久化任务。
+func (c *CompiledGraph[S]) stepTasks(nodes []string) []*durability.DurableTask[S] {
+	var tasks []*durability.DurableTask[S]
+	for _, name := range nodes {
+		if task := c.graph.nodes[name].Task; task != nil {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks
+}
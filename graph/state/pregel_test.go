@@ -0,0 +1,319 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zhucl121/langchain-go/graph/checkpoint"
+	"github.com/zhucl121/langchain-go/graph/edge"
+)
+
+// FanOutState 并行测试用的状态
+type FanOutState struct {
+	Results []string
+	Total   int
+	Winner  string
+	Joined  int
+}
+
+// newFanOutGraph 创建 start -> {a, b} -> join 的测试图
+func newFanOutGraph(barrier bool) *StateGraph[FanOutState] {
+	graph := NewStateGraph[FanOutState]("fanout")
+
+	// a 和 b 互相等待，只有并行执行时才能完成
+	aReady := make(chan struct{})
+	bReady := make(chan struct{})
+	wait := func(ctx context.Context, own, other chan struct{}) error {
+		if !barrier {
+			return nil
+		}
+		close(own)
+		select {
+		case <-other:
+			return nil
+		case <-time.After(time.Second):
+			return errors.New("branches did not run concurrently")
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	graph.AddNode("start", func(ctx context.Context, s FanOutState) (FanOutState, error) {
+		s.Results = append(s.Results, "start")
+		return s, nil
+	})
+	graph.AddNode("a", func(ctx context.Context, s FanOutState) (FanOutState, error) {
+		if err := wait(ctx, aReady, bReady); err != nil {
+			return s, err
+		}
+		s.Results = append(s.Results, "a")
+		s.Total += 1
+		s.Winner = "a"
+		return s, nil
+	})
+	graph.AddNode("b", func(ctx context.Context, s FanOutState) (FanOutState, error) {
+		if err := wait(ctx, bReady, aReady); err != nil {
+			return s, err
+		}
+		s.Results = append(s.Results, "b")
+		s.Total += 10
+		s.Winner = "b"
+		return s, nil
+	})
+	graph.AddNode("join", func(ctx context.Context, s FanOutState) (FanOutState, error) {
+		s.Joined++
+		return s, nil
+	})
+
+	graph.SetEntryPoint("start")
+	graph.AddEdge("start", "a")
+	graph.AddEdge("start", "b")
+	graph.AddEdge("a", "join")
+	graph.AddEdge("b", "join")
+	graph.AddEdge("join", END)
+
+	graph.WithChannel(NewAppendChannel("Results"))
+	graph.WithChannel(NewReducerChannel("Total", SumReducer[int]()))
+
+	return graph
+}
+
+// TestInvoke_FanOutFanIn 测试并行分支和合并
+func TestInvoke_FanOutFanIn(t *testing.T) {
+	compiled, err := newFanOutGraph(true).Compile()
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	result, err := compiled.Invoke(context.Background(), FanOutState{Total: 100})
+	if err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+
+	expected := []string{"start", "a", "b"}
+	if len(result.Results) != len(expected) {
+		t.Fatalf("expected results %v, got %v", expected, result.Results)
+	}
+	for i := range expected {
+		if result.Results[i] != expected[i] {
+			t.Fatalf("expected results %v, got %v", expected, result.Results)
+		}
+	}
+
+	if result.Total != 111 {
+		t.Errorf("expected Total=111, got %d", result.Total)
+	}
+
+	// 未注册通道的字段：按节点名称排序，最后写入的生效
+	if result.Winner != "b" {
+		t.Errorf("expected Winner=b, got %s", result.Winner)
+	}
+
+	// join 被两个分支触发，只执行一次
+	if result.Joined != 1 {
+		t.Errorf("expected join to run once, ran %d times", result.Joined)
+	}
+}
+
+// TestInvoke_BranchEdge 测试 BranchEdge 驱动的 fan-out
+func TestInvoke_BranchEdge(t *testing.T) {
+	graph := NewStateGraph[FanOutState]("branch")
+
+	graph.AddNode("plan", func(ctx context.Context, s FanOutState) (FanOutState, error) {
+		return s, nil
+	})
+	for _, name := range []string{"x", "y", "z"} {
+		name := name
+		graph.AddNode(name, func(ctx context.Context, s FanOutState) (FanOutState, error) {
+			s.Results = append(s.Results, name)
+			return s, nil
+		})
+		graph.AddEdge(name, END)
+	}
+
+	graph.SetEntryPoint("plan")
+	graph.AddBranchEdge(edge.NewBranchEdge("plan",
+		map[string]string{"x": "x", "y": "y", "z": "z"},
+		func(s FanOutState) []string { return []string{"z", "x"} },
+	))
+	graph.WithChannel(NewAppendChannel("Results"))
+
+	compiled, err := graph.Compile()
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	result, err := compiled.Invoke(context.Background(), FanOutState{})
+	if err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+
+	if len(result.Results) != 2 || result.Results[0] != "x" || result.Results[1] != "z" {
+		t.Errorf("expected [x z], got %v", result.Results)
+	}
+}
+
+// TestInvoke_MapStateWithReducer 测试 map 状态使用 MergeReducer 合并
+func TestInvoke_MapStateWithReducer(t *testing.T) {
+	graph := NewStateGraph[map[string]any]("merge")
+
+	graph.AddNode("start", func(ctx context.Context, s map[string]any) (map[string]any, error) {
+		return s, nil
+	})
+	graph.AddNode("left", func(ctx context.Context, s map[string]any) (map[string]any, error) {
+		return map[string]any{"left": true}, nil
+	})
+	graph.AddNode("right", func(ctx context.Context, s map[string]any) (map[string]any, error) {
+		return map[string]any{"right": true}, nil
+	})
+
+	graph.SetEntryPoint("start")
+	graph.AddEdge("start", "left")
+	graph.AddEdge("start", "right")
+	graph.AddEdge("left", END)
+	graph.AddEdge("right", END)
+	graph.WithReducer(MergeReducer())
+
+	compiled, err := graph.Compile()
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	result, err := compiled.Invoke(context.Background(), map[string]any{"input": 1})
+	if err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+
+	if result["input"] != 1 || result["left"] != true || result["right"] != true {
+		t.Errorf("unexpected merged state: %v", result)
+	}
+}
+
+// TestInvoke_MapStateWithChannels 测试 map 状态按键使用通道合并
+func TestInvoke_MapStateWithChannels(t *testing.T) {
+	graph := NewStateGraph[map[string]any]("channels")
+
+	graph.AddNode("start", func(ctx context.Context, s map[string]any) (map[string]any, error) {
+		return s, nil
+	})
+	for _, name := range []string{"left", "right"} {
+		name := name
+		graph.AddNode(name, func(ctx context.Context, s map[string]any) (map[string]any, error) {
+			out := make(map[string]any)
+			for k, v := range s {
+				out[k] = v
+			}
+			out["count"] = s["count"].(int) + 1
+			out["log"] = append(append([]any(nil), s["log"].([]any)...), name)
+			return out, nil
+		})
+		graph.AddEdge("start", name)
+		graph.AddEdge(name, END)
+	}
+
+	graph.SetEntryPoint("start")
+	graph.WithChannel(NewReducerChannel("count", SumReducer[int]()))
+	graph.WithChannel(NewAppendChannel("log"))
+
+	compiled, err := graph.Compile()
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	result, err := compiled.Invoke(context.Background(), map[string]any{"count": 5, "log": []any{"start"}})
+	if err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+
+	if result["count"] != 7 {
+		t.Errorf("expected count=7, got %v", result["count"])
+	}
+	if log := result["log"].([]any); len(log) != 3 || log[1] != "left" || log[2] != "right" {
+		t.Errorf("expected [start left right], got %v", log)
+	}
+}
+
+// TestInvoke_ParallelBranchError 测试并行分支失败
+func TestInvoke_ParallelBranchError(t *testing.T) {
+	graph := NewStateGraph[FanOutState]("error")
+
+	graph.AddNode("start", func(ctx context.Context, s FanOutState) (FanOutState, error) {
+		return s, nil
+	})
+	graph.AddNode("ok", func(ctx context.Context, s FanOutState) (FanOutState, error) {
+		select {
+		case <-ctx.Done():
+			return s, ctx.Err()
+		case <-time.After(time.Second):
+			return s, nil
+		}
+	})
+	graph.AddNode("broken", func(ctx context.Context, s FanOutState) (FanOutState, error) {
+		return s, errors.New("boom")
+	})
+
+	graph.SetEntryPoint("start")
+	graph.AddEdge("start", "ok")
+	graph.AddEdge("start", "broken")
+	graph.AddEdge("ok", END)
+	graph.AddEdge("broken", END)
+
+	compiled, err := graph.Compile()
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	start := time.Now()
+	_, err = compiled.Invoke(context.Background(), FanOutState{})
+	if err == nil {
+		t.Fatal("expected error from broken branch")
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Error("sibling branch was not cancelled")
+	}
+}
+
+// TestInvoke_ParallelCheckpoints 测试并行步骤的检查点和恢复
+func TestInvoke_ParallelCheckpoints(t *testing.T) {
+	saver := checkpoint.NewMemoryCheckpointSaver[FanOutState]()
+	ctx := context.Background()
+
+	failing := newFanOutGraph(false)
+	failing.AddNode("join", func(ctx context.Context, s FanOutState) (FanOutState, error) {
+		return s, errors.New("join failed")
+	})
+	failing.WithCheckpointer(saver)
+
+	compiled, err := failing.Compile()
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	if _, err := compiled.Invoke(ctx, FanOutState{}, WithThreadID("thread-1")); err == nil {
+		t.Fatal("expected error from join")
+	}
+
+	checkpoints, _ := saver.List(ctx, "thread-1")
+	parallel := checkpoints[2]
+	if parallel.Metadata["node_name"] != "a,b" {
+		t.Errorf("expected parallel step node_name a,b, got %v", parallel.Metadata["node_name"])
+	}
+	if next := metadataStrings(parallel.Metadata[metadataNextKey]); len(next) != 1 || next[0] != "join" {
+		t.Errorf("expected next [join], got %v", next)
+	}
+
+	// 恢复时不重复执行并行分支
+	resumed := newFanOutGraph(false).WithCheckpointer(saver)
+	compiled, err = resumed.Compile()
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	result, err := compiled.Invoke(ctx, FanOutState{}, WithThreadID("thread-1"))
+	if err != nil {
+		t.Fatalf("resume failed: %v", err)
+	}
+	if result.Total != 11 || result.Joined != 1 || len(result.Results) != 3 {
+		t.Errorf("unexpected resumed state: %+v", result)
+	}
+}
@@ -0,0 +1,256 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/zhucl121/langchain-go/graph/durability"
+	"github.com/zhucl121/langchain-go/graph/executor"
+)
+
+// Send 表示一次动态调度：在下一个超步中以 State 为输入执行 Node。
+//
+// Send 由 AddSendEdges 的路由函数返回，用于 map-reduce：
+// 同一个节点可以被调度多次，每次使用不同的输入状态，各次执行并行进行，
+// 输出按 WithChannel / WithReducer 的配置合并回图的状态。
+//
+type Send[S any] struct {
+	// Node 要执行的节点
+	Node string `json:"node"`
+
+	// State 节点的输入状态
+	State S `json:"state"`
+}
+
+// NewSend 创建一次动态调度。
+//
+// 参数：
+//   - node: 要执行的节点
+//   - state: 节点的输入状态
+//
+// 返回：
+//   - Send[S]: 调度
+//
+func NewSend[S any](node string, state S) Send[S] {
+	return Send[S]{Node: node, State: state}
+}
+
+// SendEdge 表示动态调度边。
+//
+// 源节点完成后调用 Route，返回的每个 Send 在下一个超步中各执行一次目标节点。
+//
+type SendEdge[S any] struct {
+	Source  string            // 源节点
+	Route   func(S) []Send[S] // 路由函数
+	Targets []string          // 可能的目标节点（用于验证和可视化）
+}
+
+// AddSendEdges 添加动态调度边（map-reduce）。
+//
+// 源节点完成后，route 根据状态返回一组 Send；每个 Send 的目标节点
+// 在下一个超步中以 Send.State 为输入执行一次，同一节点的多次执行并行进行
+// （并发数受 WithScheduler 配置的调度器限制）。
+//
+// 合并：
+//   - 每次执行的输出相对于它自己的输入（Send.State）计算增量，
+//     再按 WithChannel 注册的通道合并到图的状态，例如用 AppendChannel 收集结果
+//   - 配置了 WithReducer 时，调用 reducer(图的状态, 各次执行的输出...)
+//   - 合并顺序：按名称触发的节点在前，Send 按 route 返回的顺序在后
+//
+// 检查点：
+//   - 下一步的 Send 与下一步节点一起写入检查点，恢复时以相同的输入重新调度
+//   - 超步失败或中断时，已完成的 Send 的输出写入检查点，恢复后不再重复执行
+//
+// 参数：
+//   - source: 源节点名称
+//   - route: 路由函数，返回空列表时不调度
+//   - targets: route 可能调度的节点
+//
+// 返回：
+//   - *StateGraph[S]: 返回自身，支持链式调用
+//
+// 注意：
+//   - targets 不能为空，目标节点在 Compile 时验证
+//   - route 调度 targets 以外的节点时执行失败
+//   - 目标节点需要有出边（或是结束点），出边按合并后的状态计算一次
+//
+// 示例：
+//
+//	graph.WithChannel(state.NewAppendChannel("Summaries"))
+//	graph.AddSendEdges("split", func(s DocState) []state.Send[DocState] {
+//	    sends := make([]state.Send[DocState], len(s.Docs))
+//	    for i, doc := range s.Docs {
+//	        sends[i] = state.NewSend("summarize", DocState{Doc: doc})
+//	    }
+//	    return sends
+//	}, "summarize")
+//	graph.AddEdge("summarize", "combine")
+//
+func (g *StateGraph[S]) AddSendEdges(source string, route func(S) []Send[S], targets ...string) *StateGraph[S] {
+	if source == "" {
+		panic(fmt.Errorf("state: source node cannot be empty"))
+	}
+
+	if route == nil {
+		panic(fmt.Errorf("state: send route function cannot be nil"))
+	}
+
+	if len(targets) == 0 {
+		panic(fmt.Errorf("state: send targets cannot be empty"))
+	}
+
+	if _, exists := g.nodes[source]; !exists {
+		panic(fmt.Errorf("%w: %s", ErrNodeNotFound, source))
+	}
+
+	for _, target := range targets {
+		if target == END {
+			panic(fmt.Errorf("%w: cannot send to END", ErrInvalidEdge))
+		}
+	}
+
+	g.sends = append(g.sends, SendEdge[S]{
+		Source:  source,
+		Route:   route,
+		Targets: append([]string(nil), targets...),
+	})
+
+	return g
+}
+
+// WithScheduler 设置限制超步内并发执行数的调度器。
+//
+// 同一超步中的所有节点执行（包括 Send 调度的每次执行）都先从调度器获取槽位，
+// 同时执行的节点数不超过 Scheduler 的 MaxConcurrent。未设置时不限制并发。
+//
+// 参数：
+//   - scheduler: 调度器
+//
+// 返回：
+//   - *StateGraph[S]: 返回自身，支持链式调用
+//
+// 注意：
+//   - 子图应使用独立的调度器：子图节点在执行期间占用父图的槽位
+//
+// 示例：
+//
+//	graph.WithScheduler(executor.NewScheduler[DocState]().WithMaxConcurrent(4))
+//
+func (g *StateGraph[S]) WithScheduler(scheduler *executor.Scheduler[S]) *StateGraph[S] {
+	g.scheduler = scheduler
+	return g
+}
+
+// sendTargets 以 {目标: 目标} 的形式返回 Send 边的目标，用于验证和可视化。
+func sendTargets[S any](edge SendEdge[S]) map[string]string {
+	targets := make(map[string]string, len(edge.Targets))
+	for _, target := range edge.Targets {
+		targets[target] = target
+	}
+	return targets
+}
+
+// stepTask 是超步中的一次节点执行。
+type stepTask[S any] struct {
+	// key 任务标识：按名称触发的节点为节点名称，Send 为 "节点名称[序号]"
+	key   string
+	node  string
+	input S
+	send  bool
+}
+
+// newStepTasks 返回超步的任务：按名称触发的节点在前，Send 按顺序在后。
+func newStepTasks[S any](nodes []string, sends []Send[S], state S) []stepTask[S] {
+	tasks := make([]stepTask[S], 0, len(nodes)+len(sends))
+	for _, name := range nodes {
+		tasks = append(tasks, stepTask[S]{key: name, node: name, input: state})
+	}
+	for i, send := range sends {
+		tasks = append(tasks, stepTask[S]{
+			key:   fmt.Sprintf("%s[%d]", send.Node, i),
+			node:  send.Node,
+			input: send.State,
+			send:  true,
+		})
+	}
+	return tasks
+}
+
+// scheduledTask 返回以调度标识为 ID 的持久化任务副本。
+//
+// 调度标识为 "任务标识@超步序号"：同一持久化任务的多个 Send 并行执行时、
+// 或在循环中被多次调度时各自记录执行状态，重试次数互不影响，
+// ExactlyOnce 模式也不会把其他调度的完成当作重复执行。
+//
+func scheduledTask[S any](task *durability.DurableTask[S], key string, step int) *durability.DurableTask[S] {
+	return &durability.DurableTask[S]{
+		ID:           fmt.Sprintf("%s@%d", key, step),
+		Func:         task.Func,
+		RetryPolicy:  task.RetryPolicy,
+		IsIdempotent: task.IsIdempotent,
+		Timeout:      task.Timeout,
+		Metadata:     task.Metadata,
+	}
+}
+
+// stepNodes 返回超步中执行的节点（排序、去重）。
+func stepNodes[S any](nodes []string, sends []Send[S]) []string {
+	if len(sends) == 0 {
+		return nodes
+	}
+
+	seen := make(map[string]bool, len(nodes)+len(sends))
+	result := make([]string, 0, len(nodes)+len(sends))
+	for _, name := range nodes {
+		if !seen[name] {
+			seen[name] = true
+			result = append(result, name)
+		}
+	}
+	for _, send := range sends {
+		if !seen[send.Node] {
+			seen[send.Node] = true
+			result = append(result, send.Node)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// metadataSends 读取元数据中的 Send 列表。
+func metadataSends[S any](v any) []Send[S] {
+	sends, _ := metadataValue[[]Send[S]](v)
+	return sends
+}
+
+// metadataWrites 读取元数据中已完成的任务的输出。
+func metadataWrites[S any](v any) map[string]S {
+	writes, _ := metadataValue[map[string]S](v)
+	return writes
+}
+
+// metadataValue 读取元数据中的类型化值。
+//
+// 经过 JSON 序列化的检查点（SQLite、Postgres）中结构体会变成 map，
+// 此时通过 JSON 往返转换回原类型。
+//
+func metadataValue[T any](v any) (T, bool) {
+	var result T
+	if v == nil {
+		return result, false
+	}
+
+	if typed, ok := v.(T); ok {
+		return typed, true
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return result, false
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return result, false
+	}
+	return result, true
+}
@@ -0,0 +1,205 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zhucl121/langchain-go/graph/checkpoint"
+	"github.com/zhucl121/langchain-go/graph/executor"
+	"github.com/zhucl121/langchain-go/graph/visualization"
+)
+
+// MapReduceState map-reduce 测试用状态
+type MapReduceState struct {
+	Docs      []string
+	Doc       string
+	Summaries []string
+	Words     int
+	Report    string
+}
+
+// newMapReduceGraph 创建 split -(Send)-> summarize -> combine 的测试图
+func newMapReduceGraph(summarize NodeFunc[MapReduceState]) *StateGraph[MapReduceState] {
+	graph := NewStateGraph[MapReduceState]("map-reduce")
+	graph.WithChannel(NewAppendChannel("Summaries"))
+	graph.WithChannel(NewReducerChannel("Words", SumReducer[int]()))
+
+	graph.AddNode("split", func(ctx context.Context, s MapReduceState) (MapReduceState, error) {
+		s.Docs = []string{"alpha", "beta", "gamma", "delta"}
+		return s, nil
+	})
+	graph.AddNode("summarize", summarize)
+	graph.AddNode("combine", func(ctx context.Context, s MapReduceState) (MapReduceState, error) {
+		s.Report = strings.Join(s.Summaries, "|")
+		return s, nil
+	})
+
+	graph.SetEntryPoint("split")
+	graph.AddSendEdges("split", func(s MapReduceState) []Send[MapReduceState] {
+		sends := make([]Send[MapReduceState], len(s.Docs))
+		for i, doc := range s.Docs {
+			sends[i] = NewSend("summarize", MapReduceState{Doc: doc})
+		}
+		return sends
+	}, "summarize")
+	graph.AddEdge("summarize", "combine")
+	graph.AddEdge("combine", END)
+
+	return graph
+}
+
+// summarizeDoc 摘要单个文档
+func summarizeDoc(s MapReduceState) MapReduceState {
+	s.Summaries = append(s.Summaries, strings.ToUpper(s.Doc))
+	s.Words += len(s.Doc)
+	return s
+}
+
+// TestSend_MapReduce 测试 Send 并行执行并通过通道合并结果
+func TestSend_MapReduce(t *testing.T) {
+	var running, peak int32
+	var mu sync.Mutex
+	seen := make(map[string]int)
+
+	graph := newMapReduceGraph(func(ctx context.Context, s MapReduceState) (MapReduceState, error) {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		mu.Lock()
+		seen[s.Doc]++
+		mu.Unlock()
+		return summarizeDoc(s), nil
+	})
+	graph.WithScheduler(executor.NewScheduler[MapReduceState]().WithMaxConcurrent(2))
+
+	compiled, err := graph.Compile()
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	result, err := compiled.Invoke(context.Background(), MapReduceState{})
+	if err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+
+	if result.Report != "ALPHA|BETA|GAMMA|DELTA" {
+		t.Errorf("expected summaries in send order, got %q", result.Report)
+	}
+	if result.Words != 19 {
+		t.Errorf("expected 19 words, got %d", result.Words)
+	}
+	if result.Doc != "" {
+		t.Errorf("per-item input should not leak into graph state, got Doc=%q", result.Doc)
+	}
+	if len(seen) != 4 {
+		t.Errorf("expected each doc to be summarized once, got %v", seen)
+	}
+	if peak > 2 {
+		t.Errorf("expected at most 2 concurrent sends, got %d", peak)
+	}
+
+	mermaid, _ := compiled.Draw(visualization.FormatMermaid)
+	if !strings.Contains(mermaid, "split -->|Send: summarize| summarize") {
+		t.Errorf("expected send edge in mermaid output:\n%s", mermaid)
+	}
+}
+
+// TestSend_CheckpointedItems 测试失败后只重新执行未完成的 Send
+func TestSend_CheckpointedItems(t *testing.T) {
+	ctx := context.Background()
+	saver := &jsonSaver[MapReduceState]{checkpoint.NewMemoryCheckpointSaver[MapReduceState]()}
+
+	var mu sync.Mutex
+	calls := make(map[string]int)
+	graph := newMapReduceGraph(func(ctx context.Context, s MapReduceState) (MapReduceState, error) {
+		mu.Lock()
+		calls[s.Doc]++
+		n := calls[s.Doc]
+		mu.Unlock()
+
+		if s.Doc == "gamma" && n == 1 {
+			return s, errors.New("rate limited")
+		}
+		return summarizeDoc(s), nil
+	})
+	graph.WithCheckpointer(saver)
+	compiled, _ := graph.Compile()
+
+	if _, err := compiled.Invoke(ctx, MapReduceState{}, WithThreadID("t-1")); err == nil || !strings.Contains(err.Error(), "summarize[2]") {
+		t.Fatalf("expected failure of summarize[2], got %v", err)
+	}
+
+	snapshot, err := compiled.GetState(ctx, "t-1")
+	if err != nil {
+		t.Fatalf("GetState failed: %v", err)
+	}
+	if len(snapshot.Sends) != 4 || snapshot.Sends[2].State.Doc != "gamma" {
+		t.Fatalf("expected pending sends in checkpoint, got %+v", snapshot.Sends)
+	}
+	if len(snapshot.Next) != 1 || snapshot.Next[0] != "summarize" {
+		t.Errorf("expected next [summarize], got %v", snapshot.Next)
+	}
+
+	result, err := compiled.Invoke(ctx, MapReduceState{}, WithThreadID("t-1"))
+	if err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+	if result.Report != "ALPHA|BETA|GAMMA|DELTA" || result.Words != 19 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	// 已完成的 Send 不重复执行
+	for doc, want := range map[string]int{"alpha": 1, "beta": 1, "gamma": 2, "delta": 1} {
+		if calls[doc] != want {
+			t.Errorf("expected %s to run %d times, got %d", doc, want, calls[doc])
+		}
+	}
+}
+
+// TestSend_UndeclaredTarget 测试调度未声明的节点
+func TestSend_UndeclaredTarget(t *testing.T) {
+	graph := NewStateGraph[MapReduceState]("invalid")
+	graph.AddNode("split", func(ctx context.Context, s MapReduceState) (MapReduceState, error) {
+		return s, nil
+	})
+	graph.AddNode("summarize", func(ctx context.Context, s MapReduceState) (MapReduceState, error) {
+		return s, nil
+	})
+	graph.AddNode("other", func(ctx context.Context, s MapReduceState) (MapReduceState, error) {
+		return s, nil
+	})
+	graph.SetEntryPoint("split")
+	graph.AddSendEdges("split", func(s MapReduceState) []Send[MapReduceState] {
+		return []Send[MapReduceState]{NewSend("other", s)}
+	}, "summarize")
+	graph.AddEdge("summarize", "other")
+	graph.AddEdge("other", END)
+
+	compiled, err := graph.Compile()
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	if _, err := compiled.Invoke(context.Background(), MapReduceState{}); !errors.Is(err, ErrInvalidEdge) {
+		t.Errorf("expected ErrInvalidEdge, got %v", err)
+	}
+
+	// 目标不能为空
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for send edge without targets")
+		}
+	}()
+	graph.AddSendEdges("split", func(s MapReduceState) []Send[MapReduceState] { return nil })
+}
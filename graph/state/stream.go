@@ -0,0 +1,326 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/zhucl121/langchain-go/core/runnable"
+)
+
+// StreamMode 决定 Stream 输出哪些事件。
+type StreamMode string
+
+const (
+	// StreamModeValues 每个超步完成后输出合并后的完整状态
+	StreamModeValues StreamMode = "values"
+
+	// StreamModeUpdates 每个超步完成后输出各节点的更新（相对于本步输入的变化）
+	StreamModeUpdates StreamMode = "updates"
+
+	// StreamModeDebug 输出节点开始、结束、失败事件及执行耗时
+	StreamModeDebug StreamMode = "debug"
+)
+
+// IsValid 检查流式模式是否有效。
+func (m StreamMode) IsValid() bool {
+	switch m {
+	case StreamModeValues, StreamModeUpdates, StreamModeDebug:
+		return true
+	default:
+		return false
+	}
+}
+
+// StreamEventType 是图流式事件的类型。
+type StreamEventType string
+
+const (
+	// StreamEventValues 超步完成后的完整状态（values 模式）
+	StreamEventValues StreamEventType = "values"
+
+	// StreamEventUpdates 单个节点的更新（updates 模式）
+	StreamEventUpdates StreamEventType = "updates"
+
+	// StreamEventNodeStart 节点开始执行（debug 模式）
+	StreamEventNodeStart StreamEventType = "node_start"
+
+	// StreamEventNodeEnd 节点执行成功（debug 模式）
+	StreamEventNodeEnd StreamEventType = "node_end"
+
+	// StreamEventNodeError 节点执行失败（debug 模式）
+	StreamEventNodeError StreamEventType = "node_error"
+
+	// StreamEventToken 节点内模型产生的 token（所有模式）
+	StreamEventToken StreamEventType = "token"
+
+	// StreamEventInterrupt 图执行被中断，总是最后一个事件（所有模式）
+	StreamEventInterrupt StreamEventType = "interrupt"
+
+	// StreamEventError 图执行失败，总是最后一个事件（所有模式）
+	StreamEventError StreamEventType = "error"
+)
+
+// StreamEvent 是图执行过程中输出的事件。
+//
+// 不同类型的事件使用的字段：
+//   - values: Step, State（合并后的完整状态）
+//   - updates: Step, Node, State（节点输出）, Changes
+//   - node_start: Step, Node
+//   - node_end: Step, Node, State（节点输出）, Duration
+//   - node_error: Step, Node, Duration, Error
+//   - token: Step, Node, Token
+//   - interrupt: Step, Error（*InterruptError）
+//   - error: Step, Error
+//
+type StreamEvent[S any] struct {
+	// Type 事件类型
+	Type StreamEventType
+
+	// Step 超步序号（从 1 开始，与检查点的 step 一致）
+	Step int
+
+	// Node 产生事件的节点
+	Node string
+
+	// State 状态或节点输出
+	State S
+
+	// Changes 节点相对于本步输入修改的字段（结构体的导出字段或 map 的键）
+	//
+	// 被删除的 map 键对应的值为 nil。状态不是结构体或 map[string]V 时为 nil。
+	Changes map[string]any
+
+	// Token 节点内组件转发的原始流式事件（Data 通常是 types.Message 片段）
+	Token *runnable.StreamEvent[any]
+
+	// Duration 节点执行耗时
+	Duration time.Duration
+
+	// Error 错误
+	Error error
+
+	// Timestamp 事件产生时间
+	Timestamp time.Time
+}
+
+// Stream 以流式方式执行图。
+//
+// 图在后台执行，事件按产生顺序写入返回的 channel，执行结束后 channel 关闭。
+// 执行失败时最后一个事件的类型为 StreamEventError，
+// 被中断时为 StreamEventInterrupt。
+// 节点中调用的 chat.ChatModel 的 Stream 所产生的 token 会以 StreamEventToken
+// 事件转发，无论使用哪种模式；调用 Invoke 时整个响应作为一个 token 事件转发。
+//
+// 参数：
+//   - ctx: 上下文
+//   - initialState: 初始状态
+//   - mode: 流式模式
+//   - opts: 执行选项（与 Invoke 相同，例如 WithThreadID）
+//
+// 返回：
+//   - <-chan StreamEvent[S]: 事件 channel
+//   - error: 模式无效时返回 ErrInvalidStreamMode，选项不是 InvokeOption 时返回 ErrInvalidOption
+//
+// 注意：
+//   - 调用方需要持续读取 channel 直到关闭；提前放弃时应取消 ctx，否则执行会阻塞
+//   - 检查点和恢复行为与 Invoke 相同
+//
+// 示例：
+//
+//	events, err := compiled.Stream(ctx, initialState, state.StreamModeUpdates)
+//	if err != nil {
+//	    return err
+//	}
+//	for event := range events {
+//	    switch event.Type {
+//	    case state.StreamEventUpdates:
+//	        fmt.Println(event.Node, event.Changes)
+//	    case state.StreamEventToken:
+//	        fmt.Print(event.Token.Data.(types.Message).Content)
+//	    case state.StreamEventError:
+//	        return event.Error
+//	    }
+//	}
+//
+func (c *CompiledGraph[S]) Stream(
+	ctx context.Context,
+	initialState S,
+	mode StreamMode,
+	opts ...interface{},
+) (<-chan StreamEvent[S], error) {
+	if !mode.IsValid() {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidStreamMode, mode)
+	}
+	config, err := newInvokeConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan StreamEvent[S], 16)
+	em := &emitter[S]{ctx: ctx, mode: mode, out: out}
+
+	go func() {
+		defer close(out)
+
+		if _, err := c.run(ctx, initialState, config, em); err != nil {
+			eventType := StreamEventError
+			var ierr *InterruptError
+			if errors.As(err, &ierr) {
+				eventType = StreamEventInterrupt
+			}
+			em.send(StreamEvent[S]{Type: eventType, Step: em.step, Error: err})
+		}
+	}()
+
+	return out, nil
+}
+
+// emitter 把执行过程中的事件按模式写入 Stream 的 channel。
+//
+// nil 的 emitter 不输出任何事件，Invoke 使用 nil。
+// 节点事件和 token 事件可能来自并行执行的多个节点，send 是并发安全的。
+//
+type emitter[S any] struct {
+	ctx  context.Context
+	mode StreamMode
+	out  chan<- StreamEvent[S]
+
+	// step 最近开始的超步，仅在执行协程中修改
+	step int
+}
+
+// send 写入事件；ctx 取消后丢弃事件。
+func (e *emitter[S]) send(event StreamEvent[S]) {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	select {
+	case e.out <- event:
+	case <-e.ctx.Done():
+	}
+}
+
+// startStep 记录开始的超步。
+func (e *emitter[S]) startStep(step int) {
+	if e != nil {
+		e.step = step
+	}
+}
+
+// nodeContext 返回节点执行使用的上下文，把节点内的流式事件转发为 token 事件。
+func (e *emitter[S]) nodeContext(ctx context.Context, step int, node string) context.Context {
+	if e == nil {
+		return ctx
+	}
+
+	return runnable.ContextWithStreamWriter(ctx, func(event runnable.StreamEvent[any]) {
+		e.send(StreamEvent[S]{
+			Type:  StreamEventToken,
+			Step:  step,
+			Node:  node,
+			Token: &event,
+		})
+	})
+}
+
+// nodeStart 输出节点开始事件（debug 模式）。
+func (e *emitter[S]) nodeStart(step int, node string) {
+	if e == nil || e.mode != StreamModeDebug {
+		return
+	}
+	e.send(StreamEvent[S]{Type: StreamEventNodeStart, Step: step, Node: node})
+}
+
+// nodeDone 输出节点结束或失败事件（debug 模式）。
+func (e *emitter[S]) nodeDone(step int, node string, output S, duration time.Duration, err error) {
+	if e == nil || e.mode != StreamModeDebug {
+		return
+	}
+
+	event := StreamEvent[S]{Type: StreamEventNodeEnd, Step: step, Node: node, Duration: duration}
+	if err != nil {
+		event.Type = StreamEventNodeError
+		event.Error = err
+	} else {
+		event.State = output
+	}
+	e.send(event)
+}
+
+// updates 输出本步各任务的更新（updates 模式），按任务顺序。
+//
+// 变化相对于任务自己的输入计算（Send 为 Send.State）。
+//
+func (e *emitter[S]) updates(step int, tasks []stepTask[S], outputs []S) {
+	if e == nil || e.mode != StreamModeUpdates {
+		return
+	}
+
+	for i, task := range tasks {
+		e.send(StreamEvent[S]{
+			Type:    StreamEventUpdates,
+			Step:    step,
+			Node:    task.node,
+			State:   outputs[i],
+			Changes: stateChanges(task.input, outputs[i]),
+		})
+	}
+}
+
+// values 输出超步完成后的完整状态（values 模式）。
+func (e *emitter[S]) values(step int, state S) {
+	if e == nil || e.mode != StreamModeValues {
+		return
+	}
+	e.send(StreamEvent[S]{Type: StreamEventValues, Step: step, State: state})
+}
+
+// stateChanges 返回 output 相对于 input 修改的字段。
+//
+// 结构体比较导出字段，map[string]V 比较键（删除的键值为 nil）；
+// 其他类型的状态返回 nil。
+//
+func stateChanges[S any](input, output S) map[string]any {
+	in := reflect.ValueOf(&input).Elem()
+	out := reflect.ValueOf(&output).Elem()
+	changes := make(map[string]any)
+
+	switch in.Kind() {
+	case reflect.Struct:
+		for i := 0; i < in.NumField(); i++ {
+			if !in.Type().Field(i).IsExported() {
+				continue
+			}
+			if w := out.Field(i); !reflect.DeepEqual(w.Interface(), in.Field(i).Interface()) {
+				changes[in.Type().Field(i).Name] = w.Interface()
+			}
+		}
+
+	case reflect.Map:
+		if in.Type().Key().Kind() != reflect.String {
+			return nil
+		}
+
+		iter := out.MapRange()
+		for iter.Next() {
+			if !sameMapValue(iter.Value(), in.MapIndex(iter.Key())) {
+				changes[iter.Key().String()] = iter.Value().Interface()
+			}
+		}
+		iter = in.MapRange()
+		for iter.Next() {
+			if !out.MapIndex(iter.Key()).IsValid() {
+				changes[iter.Key().String()] = nil
+			}
+		}
+
+	default:
+		return nil
+	}
+
+	return changes
+}
@@ -0,0 +1,183 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/zhucl121/langchain-go/core/runnable"
+)
+
+// newStreamTestGraph 创建 greet -> count 的测试图，greet 通过上下文转发 token
+func newStreamTestGraph(failCount bool) *CompiledGraph[TestState] {
+	graph := NewStateGraph[TestState]("stream")
+
+	graph.AddNode("greet", func(ctx context.Context, s TestState) (TestState, error) {
+		for _, token := range []string{"hel", "lo"} {
+			runnable.EmitStreamEvent(ctx, runnable.StreamEvent[any]{Type: runnable.EventStream, Data: token})
+		}
+		s.Message = "hello"
+		return s, nil
+	})
+	graph.AddNode("count", func(ctx context.Context, s TestState) (TestState, error) {
+		if failCount {
+			return s, errors.New("count failed")
+		}
+		s.Counter++
+		return s, nil
+	})
+
+	graph.SetEntryPoint("greet")
+	graph.AddEdge("greet", "count")
+	graph.AddEdge("count", END)
+
+	compiled, _ := graph.Compile()
+	return compiled
+}
+
+// collect 读取所有事件
+func collect(t *testing.T, compiled *CompiledGraph[TestState], mode StreamMode) []StreamEvent[TestState] {
+	t.Helper()
+
+	events, err := compiled.Stream(context.Background(), TestState{}, mode)
+	if err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+
+	var result []StreamEvent[TestState]
+	for event := range events {
+		result = append(result, event)
+	}
+	return result
+}
+
+// eventsOfType 过滤指定类型的事件
+func eventsOfType(events []StreamEvent[TestState], eventType StreamEventType) []StreamEvent[TestState] {
+	var result []StreamEvent[TestState]
+	for _, event := range events {
+		if event.Type == eventType {
+			result = append(result, event)
+		}
+	}
+	return result
+}
+
+// TestStream_Values 测试 values 模式
+func TestStream_Values(t *testing.T) {
+	events := collect(t, newStreamTestGraph(false), StreamModeValues)
+
+	values := eventsOfType(events, StreamEventValues)
+	if len(values) != 2 {
+		t.Fatalf("expected 2 values events, got %d", len(values))
+	}
+	if values[0].Step != 1 || values[0].State.Message != "hello" || values[0].State.Counter != 0 {
+		t.Errorf("unexpected first values event: %+v", values[0])
+	}
+	if values[1].Step != 2 || values[1].State.Counter != 1 {
+		t.Errorf("unexpected second values event: %+v", values[1])
+	}
+}
+
+// TestStream_Updates 测试 updates 模式
+func TestStream_Updates(t *testing.T) {
+	events := collect(t, newStreamTestGraph(false), StreamModeUpdates)
+
+	updates := eventsOfType(events, StreamEventUpdates)
+	if len(updates) != 2 {
+		t.Fatalf("expected 2 updates events, got %d", len(updates))
+	}
+
+	if updates[0].Node != "greet" || len(updates[0].Changes) != 1 || updates[0].Changes["Message"] != "hello" {
+		t.Errorf("unexpected greet update: %+v", updates[0])
+	}
+	if updates[1].Node != "count" || len(updates[1].Changes) != 1 || updates[1].Changes["Counter"] != 1 {
+		t.Errorf("unexpected count update: %+v", updates[1])
+	}
+}
+
+// TestStream_UpdatesParallel 测试并行节点各自输出更新
+func TestStream_UpdatesParallel(t *testing.T) {
+	compiled, err := newFanOutGraph(true).Compile()
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	events, err := compiled.Stream(context.Background(), FanOutState{}, StreamModeUpdates)
+	if err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+
+	var nodes []string
+	for event := range events {
+		if event.Type == StreamEventUpdates && event.Step == 2 {
+			nodes = append(nodes, event.Node)
+			if results := event.Changes["Results"].([]string); len(results) != 2 || results[1] != event.Node {
+				t.Errorf("unexpected changes for %s: %v", event.Node, event.Changes)
+			}
+		}
+	}
+
+	if len(nodes) != 2 || nodes[0] != "a" || nodes[1] != "b" {
+		t.Errorf("expected updates from [a b], got %v", nodes)
+	}
+}
+
+// TestStream_Debug 测试 debug 模式和执行失败
+func TestStream_Debug(t *testing.T) {
+	events := collect(t, newStreamTestGraph(true), StreamModeDebug)
+
+	var types []StreamEventType
+	for _, event := range events {
+		if event.Type != StreamEventToken {
+			types = append(types, event.Type)
+		}
+	}
+
+	expected := []StreamEventType{
+		StreamEventNodeStart, StreamEventNodeEnd,
+		StreamEventNodeStart, StreamEventNodeError,
+		StreamEventError,
+	}
+	if len(types) != len(expected) {
+		t.Fatalf("expected events %v, got %v", expected, types)
+	}
+	for i := range expected {
+		if types[i] != expected[i] {
+			t.Fatalf("expected events %v, got %v", expected, types)
+		}
+	}
+
+	last := events[len(events)-1]
+	if last.Error == nil || last.Step != 2 {
+		t.Errorf("unexpected error event: %+v", last)
+	}
+
+	for _, event := range eventsOfType(events, StreamEventNodeError) {
+		if event.Node != "count" || event.Error == nil {
+			t.Errorf("unexpected node error event: %+v", event)
+		}
+	}
+}
+
+// TestStream_Tokens 测试转发节点内的 token
+func TestStream_Tokens(t *testing.T) {
+	events := collect(t, newStreamTestGraph(false), StreamModeValues)
+
+	tokens := eventsOfType(events, StreamEventToken)
+	if len(tokens) != 2 {
+		t.Fatalf("expected 2 token events, got %d", len(tokens))
+	}
+	for i, want := range []string{"hel", "lo"} {
+		if tokens[i].Node != "greet" || tokens[i].Step != 1 || tokens[i].Token.Data != want {
+			t.Errorf("unexpected token event %d: %+v", i, tokens[i])
+		}
+	}
+}
+
+// TestStream_InvalidMode 测试无效的流式模式
+func TestStream_InvalidMode(t *testing.T) {
+	_, err := newStreamTestGraph(false).Stream(context.Background(), TestState{}, StreamMode("tokens"))
+	if !errors.Is(err, ErrInvalidStreamMode) {
+		t.Errorf("expected ErrInvalidStreamMode, got %v", err)
+	}
+}
@@ -0,0 +1,179 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/zhucl121/langchain-go/graph/node"
+)
+
+// AddSubgraph 把已编译的图作为节点嵌入当前图。
+//
+// 子图与父图使用相同的状态类型，子图的输出即节点输出。
+// 状态类型不同时使用 node.NewSubgraphNode 配置状态映射后调用 AddGraphNode。
+//
+// 子图在父图的线程中执行，检查点写入以节点名称为命名空间
+// （嵌套时为 "parent.child"）的检查点中；子图中的中断会传递到父图，
+// 通过父图的 Resume / ResumeWithInput 恢复。子图没有配置检查点保存器时
+// 使用父图的检查点保存器。
+//
+// 参数：
+//   - name: 节点名称
+//   - subgraph: 已编译的子图（通常是 *CompiledGraph[S]）
+//
+// 返回：
+//   - *StateGraph[S]: 返回自身，支持链式调用
+//
+// 注意：
+//   - 子图不能为 nil
+//
+// 示例：
+//
+//	review, _ := reviewGraph.Compile()
+//
+//	graph := state.NewStateGraph[DocState]("pipeline")
+//	graph.AddNode("draft", draftFunc)
+//	graph.AddSubgraph("review", review)
+//	graph.AddEdge("draft", "review")
+//
+func (g *StateGraph[S]) AddSubgraph(name string, subgraph node.SubgraphExecutor[S]) *StateGraph[S] {
+	if subgraph == nil {
+		panic(fmt.Errorf("state: subgraph cannot be nil for node %s", name))
+	}
+
+	g.AddNode(name, func(ctx context.Context, s S) (S, error) {
+		return subgraph.Invoke(ctx, s)
+	})
+
+	node := g.nodes[name]
+	node.Subgraph = true
+	node.child = subgraph
+	g.nodes[name] = node
+
+	return g
+}
+
+// AddGraphNode 添加实现 node.Node 接口的节点。
+//
+// 节点名称为 n.GetName()。常用于添加 node.SubgraphNode，
+// 把状态类型不同的子图嵌入当前图。
+//
+// 状态类型不同的子图不能继承父图的检查点保存器：父图配置了检查点保存器时，
+// 这样的子图（*CompiledGraph）必须配置自己的检查点保存器，否则 Compile 返回
+// ErrNoCheckpointer，因为子图中的中断无法恢复。
+//
+// 参数：
+//   - n: 节点
+//
+// 返回：
+//   - *StateGraph[S]: 返回自身，支持链式调用
+//
+// 注意：
+//   - 节点的 Validate 失败时 panic
+//   - 子图的检查点保存器可以与父图共用同一存储
+//
+// 示例：
+//
+//	child, _ := childGraph.WithCheckpointer(childSaver).Compile()
+//	graph.AddGraphNode(node.NewSubgraphNode[ParentState, ChildState]("research", child,
+//	    node.WithStateMapper(toChild, fromChild),
+//	))
+//
+func (g *StateGraph[S]) AddGraphNode(n node.Node[S]) *StateGraph[S] {
+	if n == nil {
+		panic(fmt.Errorf("state: node cannot be nil"))
+	}
+
+	if err := n.Validate(); err != nil {
+		panic(fmt.Errorf("state: invalid node %s: %w", n.GetName(), err))
+	}
+
+	g.AddNode(n.GetName(), n.Invoke)
+	if sub, ok := n.(interface{ GetSubgraph() any }); ok {
+		node := g.nodes[n.GetName()]
+		node.child = sub.GetSubgraph()
+		g.nodes[n.GetName()] = node
+	}
+	return g
+}
+
+// checkpointedGraph 由 *CompiledGraph 实现，用于检查任意状态类型的子图是否配置了检查点保存器。
+type checkpointedGraph interface {
+	hasCheckpointer() bool
+}
+
+// hasCheckpointer 报告图是否配置了检查点保存器。
+func (c *CompiledGraph[S]) hasCheckpointer() bool {
+	return c.graph.checkpointer != nil
+}
+
+// validateSubgraphs 检查子图的中断能否恢复。
+//
+// 父图配置了检查点保存器时，子图的中断通过父图的 Resume 恢复，恢复值从子图的检查点读取。
+// 同类型的子图可以继承父图的检查点保存器；状态类型不同且没有检查点保存器的子图
+// 会在每次恢复时再次中断，因此拒绝编译。
+func (g *StateGraph[S]) validateSubgraphs() error {
+	if g.checkpointer == nil {
+		return nil
+	}
+	for name, n := range g.nodes {
+		if _, same := n.child.(*CompiledGraph[S]); same {
+			continue
+		}
+		if sub, ok := n.child.(checkpointedGraph); ok && !sub.hasCheckpointer() {
+			return fmt.Errorf("%w: subgraph node %s has a different state type and needs its own checkpointer", ErrNoCheckpointer, name)
+		}
+	}
+	return nil
+}
+
+// interruptNamespaceKey 是中断所在子图的检查点命名空间在 hitl.Interrupt.Metadata 中的键。
+const interruptNamespaceKey = "checkpoint_ns"
+
+// nodeScope 记录一次节点执行的上下文信息。
+//
+// 节点中的 Interrupt 按顺序读取恢复值；
+// 节点中执行的子图从中获取父图的线程和自己的检查点命名空间。
+//
+type nodeScope struct {
+	// threadID 父图的线程
+	threadID string
+
+	// ns 子图的检查点命名空间
+	ns string
+
+	// saver 父图使用的检查点保存器（checkpoint.CheckpointSaver[S]），
+	// 由没有配置检查点保存器的同类型子图继承
+	saver any
+
+	// resuming 表示父图正在从中断恢复本步，input 是恢复时提供的输入
+	resuming bool
+	input    any
+
+	mu     sync.Mutex
+	resume []any // Interrupt 的恢复值
+	calls  int   // Interrupt 的调用次数
+}
+
+// nodeScopeKey 是 nodeScope 在上下文中的键。
+type nodeScopeKey struct{}
+
+// withNodeScope 返回携带节点执行信息的上下文。
+func withNodeScope(ctx context.Context, scope *nodeScope) context.Context {
+	return context.WithValue(ctx, nodeScopeKey{}, scope)
+}
+
+// nodeScopeFromContext 返回上下文中的节点执行信息，不在节点中执行时返回 nil。
+func nodeScopeFromContext(ctx context.Context) *nodeScope {
+	scope, _ := ctx.Value(nodeScopeKey{}).(*nodeScope)
+	return scope
+}
+
+// subgraphNamespace 返回节点中子图的检查点命名空间。
+func subgraphNamespace(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
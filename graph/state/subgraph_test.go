@@ -0,0 +1,284 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/zhucl121/langchain-go/graph/checkpoint"
+	"github.com/zhucl121/langchain-go/graph/hitl"
+	"github.com/zhucl121/langchain-go/graph/node"
+)
+
+// newParentGraph 创建 start -> sub -> finish 的测试图，sub 为子图
+func newParentGraph(saver checkpoint.CheckpointSaver[TestState], sub node.SubgraphExecutor[TestState]) *CompiledGraph[TestState] {
+	graph := NewStateGraph[TestState]("parent")
+
+	graph.AddNode("start", func(ctx context.Context, s TestState) (TestState, error) {
+		s.Counter = 1
+		return s, nil
+	})
+	graph.AddSubgraph("sub", sub)
+	graph.AddNode("finish", func(ctx context.Context, s TestState) (TestState, error) {
+		s.Counter += 100
+		return s, nil
+	})
+
+	graph.SetEntryPoint("start")
+	graph.AddEdge("start", "sub")
+	graph.AddEdge("sub", "finish")
+	graph.AddEdge("finish", END)
+	if saver != nil {
+		graph.WithCheckpointer(saver)
+	}
+
+	compiled, _ := graph.Compile()
+	return compiled
+}
+
+// TestSubgraph_NamespacedCheckpoints 测试子图的检查点写入独立的命名空间
+func TestSubgraph_NamespacedCheckpoints(t *testing.T) {
+	ctx := context.Background()
+	saver := checkpoint.NewMemoryCheckpointSaver[TestState]()
+
+	inner := NewStateGraph[TestState]("inner")
+	inner.AddNode("inc", func(ctx context.Context, s TestState) (TestState, error) {
+		s.Counter++
+		return s, nil
+	})
+	inner.AddNode("label", func(ctx context.Context, s TestState) (TestState, error) {
+		s.Message = "inner"
+		return s, nil
+	})
+	inner.SetEntryPoint("inc")
+	inner.AddEdge("inc", "label")
+	inner.AddEdge("label", END)
+	inner.WithCheckpointer(saver)
+
+	child, err := inner.Compile()
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	compiled := newParentGraph(saver, child)
+
+	result, err := compiled.Invoke(ctx, TestState{}, WithThreadID("t-1"))
+	if err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+	if result.Counter != 102 || result.Message != "inner" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	checkpoints, _ := saver.List(ctx, "t-1")
+	childCount := 0
+	for _, cp := range checkpoints {
+		switch cp.CheckpointNS {
+		case "":
+		case "sub":
+			childCount++
+		default:
+			t.Errorf("unexpected namespace %q", cp.CheckpointNS)
+		}
+	}
+	// 输入检查点 + inc + label
+	if childCount != 3 {
+		t.Errorf("expected 3 child checkpoints, got %d", childCount)
+	}
+
+	history, err := compiled.GetHistory(ctx, "t-1", 0)
+	if err != nil {
+		t.Fatalf("GetHistory failed: %v", err)
+	}
+	if len(history) != len(checkpoints)-childCount {
+		t.Errorf("expected history without child checkpoints, got %d entries", len(history))
+	}
+
+	snapshot, err := compiled.GetState(ctx, "t-1")
+	if err != nil || snapshot.NodeName != "finish" || snapshot.Values.Counter != 102 {
+		t.Errorf("unexpected parent state: %+v, %v", snapshot, err)
+	}
+
+	// 同一线程再次执行：子图开始新的执行
+	result, err = compiled.Invoke(ctx, TestState{}, WithThreadID("t-1"))
+	if err != nil || result.Counter != 102 {
+		t.Errorf("unexpected second run: %+v, %v", result, err)
+	}
+}
+
+// TestSubgraph_MappedState 测试通过 node.SubgraphNode 嵌入不同状态类型的子图
+func TestSubgraph_MappedState(t *testing.T) {
+	inner := NewStateGraph[FanOutState]("inner")
+	inner.AddNode("work", func(ctx context.Context, s FanOutState) (FanOutState, error) {
+		s.Results = append(s.Results, "work")
+		s.Total *= 3
+		return s, nil
+	})
+	inner.SetEntryPoint("work")
+	inner.SetFinishPoint("work")
+
+	child, err := inner.Compile()
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	graph := NewStateGraph[TestState]("parent")
+	graph.AddGraphNode(node.NewSubgraphNode[TestState, FanOutState]("nested", child,
+		node.WithStateMapper(
+			func(parent TestState) (FanOutState, error) {
+				return FanOutState{Total: parent.Counter}, nil
+			},
+			func(parent TestState, child FanOutState) (TestState, error) {
+				parent.Counter = child.Total
+				parent.Message = child.Results[0]
+				return parent, nil
+			},
+		),
+	))
+	graph.SetEntryPoint("nested")
+	graph.SetFinishPoint("nested")
+
+	compiled, err := graph.Compile()
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	result, err := compiled.Invoke(context.Background(), TestState{Counter: 5})
+	if err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+	if result.Counter != 15 || result.Message != "work" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	// 未配置状态映射的节点无效
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for invalid subgraph node")
+		}
+	}()
+	graph.AddGraphNode(node.NewSubgraphNode[TestState, FanOutState]("broken", child))
+}
+
+// TestSubgraph_Interrupt 测试子图中的中断传递到父图并从父图恢复
+func TestSubgraph_Interrupt(t *testing.T) {
+	ctx := context.Background()
+	saver := &jsonSaver[TestState]{checkpoint.NewMemoryCheckpointSaver[TestState]()}
+	calls := make(map[string]int)
+
+	compiled := newParentGraph(saver, newApprovalGraph(saver, calls))
+
+	_, err := compiled.Invoke(ctx, TestState{}, WithThreadID("t-1"))
+	var ierr *InterruptError
+	if !errors.As(err, &ierr) {
+		t.Fatalf("expected InterruptError, got %v", err)
+	}
+	if len(ierr.Interrupts) != 1 {
+		t.Fatalf("unexpected interrupts: %+v", ierr.Interrupts)
+	}
+	interrupt := ierr.Interrupts[0]
+	if interrupt.GetNodeName() != "review" || interrupt.Payload != "approve draft?" {
+		t.Errorf("unexpected interrupt: %+v", interrupt)
+	}
+	if interruptNamespace(interrupt) != "sub" || interrupt.CheckpointID == "" || interrupt.CheckpointID == ierr.CheckpointID {
+		t.Errorf("expected interrupt to reference the child checkpoint, got %+v", interrupt)
+	}
+
+	// 另一个进程：从父图查看并恢复
+	other := newParentGraph(saver, newApprovalGraph(saver, calls))
+
+	pending, err := other.PendingInterrupts(ctx, "t-1")
+	if err != nil || len(pending) != 1 || pending[0].Payload != "approve draft?" {
+		t.Fatalf("unexpected pending interrupts: %v, %v", pending, err)
+	}
+	if pending[0].CheckpointID != interrupt.CheckpointID {
+		t.Errorf("expected child checkpoint %s, got %s", interrupt.CheckpointID, pending[0].CheckpointID)
+	}
+
+	if _, err := other.Invoke(ctx, TestState{}, WithThreadID("t-1")); !errors.Is(err, hitl.ErrInterrupted) {
+		t.Fatalf("expected interrupt again, got %v", err)
+	}
+
+	result, err := other.ResumeWithInput(ctx, "t-1", "yes")
+	if err != nil {
+		t.Fatalf("ResumeWithInput failed: %v", err)
+	}
+	if !result.Done || result.Counter != 102 || result.Message != "draft" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	// 子图中中断之前的节点不重复执行
+	if calls["draft"] != 1 || calls["review"] != 2 || calls["send"] != 1 {
+		t.Errorf("unexpected node calls: %v", calls)
+	}
+
+	// 放弃后重新执行：子图中遗留的中断失效，重新中断
+	if _, err := other.Invoke(ctx, TestState{}, WithThreadID("t-2")); !errors.Is(err, hitl.ErrInterrupted) {
+		t.Fatalf("expected interrupt, got %v", err)
+	}
+	if _, err := other.Resume(ctx, "t-2", hitl.NewResolution(hitl.ActionAbort)); err != nil {
+		t.Fatalf("Abort failed: %v", err)
+	}
+	if _, err := other.Invoke(ctx, TestState{}, WithThreadID("t-2")); !errors.Is(err, hitl.ErrInterrupted) {
+		t.Fatalf("expected new interrupt, got %v", err)
+	}
+	if calls["draft"] != 3 {
+		t.Errorf("expected child to restart, draft ran %d times", calls["draft"])
+	}
+}
+
+// TestSubgraph_InheritsCheckpointer 测试没有检查点保存器的子图使用父图的检查点保存器恢复中断
+func TestSubgraph_InheritsCheckpointer(t *testing.T) {
+	ctx := context.Background()
+	saver := &jsonSaver[TestState]{checkpoint.NewMemoryCheckpointSaver[TestState]()}
+	calls := make(map[string]int)
+
+	compiled := newParentGraph(saver, newApprovalGraph(nil, calls))
+
+	if _, err := compiled.Invoke(ctx, TestState{}, WithThreadID("t-1")); !errors.Is(err, hitl.ErrInterrupted) {
+		t.Fatalf("expected interrupt, got %v", err)
+	}
+	result, err := compiled.ResumeWithInput(ctx, "t-1", "yes")
+	if err != nil {
+		t.Fatalf("ResumeWithInput failed: %v", err)
+	}
+	if !result.Done || result.Counter != 102 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if calls["draft"] != 1 || calls["review"] != 2 || calls["send"] != 1 {
+		t.Errorf("unexpected node calls: %v", calls)
+	}
+}
+
+// TestSubgraph_MappedStateNeedsCheckpointer 测试不同状态类型的子图在父图有检查点保存器时必须配置自己的
+func TestSubgraph_MappedStateNeedsCheckpointer(t *testing.T) {
+	inner := NewStateGraph[FanOutState]("inner")
+	inner.AddNode("work", func(ctx context.Context, s FanOutState) (FanOutState, error) {
+		return s, nil
+	})
+	inner.SetEntryPoint("work")
+	inner.SetFinishPoint("work")
+
+	newParent := func(child *CompiledGraph[FanOutState]) *StateGraph[TestState] {
+		graph := NewStateGraph[TestState]("parent")
+		graph.AddGraphNode(node.NewSubgraphNode[TestState, FanOutState]("nested", child,
+			node.WithStateMapper(
+				func(parent TestState) (FanOutState, error) { return FanOutState{}, nil },
+				func(parent TestState, child FanOutState) (TestState, error) { return parent, nil },
+			),
+		))
+		graph.SetEntryPoint("nested")
+		graph.SetFinishPoint("nested")
+		graph.WithCheckpointer(checkpoint.NewMemoryCheckpointSaver[TestState]())
+		return graph
+	}
+
+	child, _ := inner.Compile()
+	if _, err := newParent(child).Compile(); !errors.Is(err, ErrNoCheckpointer) {
+		t.Errorf("expected ErrNoCheckpointer, got %v", err)
+	}
+
+	child, _ = inner.WithCheckpointer(checkpoint.NewMemoryCheckpointSaver[FanOutState]()).Compile()
+	if _, err := newParent(child).Compile(); err != nil {
+		t.Errorf("Compile failed: %v", err)
+	}
+}
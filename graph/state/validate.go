@@ -0,0 +1,85 @@
+package state
+
+import (
+	"github.com/zhucl121/langchain-go/graph/compile"
+)
+
+// graphInfo 将 StateGraph 适配为 compile.GraphInfo，供验证器使用。
+type graphInfo[S any] struct {
+	graph *StateGraph[S]
+}
+
+// GetName 返回图名称。
+func (g graphInfo[S]) GetName() string {
+	return g.graph.name
+}
+
+// GetNodes 返回节点信息。
+func (g graphInfo[S]) GetNodes() map[string]compile.NodeInfo {
+	nodes := make(map[string]compile.NodeInfo, len(g.graph.nodes))
+	for name := range g.graph.nodes {
+		nodes[name] = compile.NodeInfo{Name: name}
+	}
+	return nodes
+}
+
+// GetEdges 返回边信息。
+//
+// 通过 SetFinishPoint 设置的结束点视为指向 END 的边；
+// 从 START 出发的边不参与验证（入口由 SetEntryPoint 决定）。
+//
+func (g graphInfo[S]) GetEdges() []compile.EdgeInfo {
+	edges := make([]compile.EdgeInfo, 0, len(g.graph.edges)+len(g.graph.finishPoints))
+	toEnd := make(map[string]bool)
+
+	for _, edge := range g.graph.edges {
+		if edge.From == START {
+			continue
+		}
+		edges = append(edges, compile.EdgeInfo{From: edge.From, To: edge.To})
+		if edge.To == END {
+			toEnd[edge.From] = true
+		}
+	}
+
+	for name := range g.graph.finishPoints {
+		if !toEnd[name] {
+			edges = append(edges, compile.EdgeInfo{From: name, To: END})
+		}
+	}
+
+	return edges
+}
+
+// GetConditionals 返回条件边信息。
+//
+// 分支边和 Send 边的目标同样由状态决定，按条件边验证。
+//
+func (g graphInfo[S]) GetConditionals() []compile.ConditionalInfo[S] {
+	conditionals := make([]compile.ConditionalInfo[S], 0, len(g.graph.conditionals)+len(g.graph.branches)+len(g.graph.sends))
+	for _, cond := range g.graph.conditionals {
+		conditionals = append(conditionals, compile.ConditionalInfo[S]{
+			Source:    cond.Source,
+			PathMap:   cond.PathMap,
+			Exclusive: true,
+		})
+	}
+	for _, branch := range g.graph.branches {
+		conditionals = append(conditionals, compile.ConditionalInfo[S]{
+			Source:  branch.GetSource(),
+			PathMap: branch.GetBranches(),
+		})
+	}
+	for _, send := range g.graph.sends {
+		conditionals = append(conditionals, compile.ConditionalInfo[S]{
+			Source:  send.Source,
+			PathMap: sendTargets(send),
+		})
+	}
+	return conditionals
+}
+
+// GetEntryPoint 返回入口点。
+func (g graphInfo[S]) GetEntryPoint() string {
+	return g.graph.entryPoint
+}
@@ -0,0 +1,289 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/zhucl121/langchain-go/graph/hitl"
+	"github.com/zhucl121/langchain-go/graph/visualization"
+)
+
+// Visualize 返回图结构的可视化器。
+//
+// 图包含 START / END 节点、所有节点和边；条件边和分支边按路径展开，
+// 以路径名为标签。通过 AddSubgraph 添加的节点显示为子图，
+// 配置了静态中断点的节点在元数据中记录中断类型（interrupt: before / after）。
+//
+// 参数：
+//   - config: 可视化配置（可选）
+//
+// 返回：
+//   - *visualization.GraphVisualizer: 可视化器
+//
+// 示例：
+//
+//	fmt.Println(compiled.Visualize().ToMermaid())
+//
+func (c *CompiledGraph[S]) Visualize(config ...visualization.VisualizerConfig) *visualization.GraphVisualizer {
+	return visualization.FromAdapter(graphAdapter[S]{graph: c.graph, interrupts: c.interrupts}, config...)
+}
+
+// Draw 以指定格式（Mermaid、DOT、ASCII、JSON）导出图结构。
+//
+// 示例：
+//
+//	dot, err := compiled.Draw(visualization.FormatDOT)
+//
+func (c *CompiledGraph[S]) Draw(format visualization.VisualizationFormat) (string, error) {
+	return visualization.Export(c.Visualize(), format)
+}
+
+// NewTracer 创建基于本图结构的执行追踪器，配合 WithTracer 使用。
+func (c *CompiledGraph[S]) NewTracer(config ...visualization.VisualizerConfig) *visualization.ExecutionTracer {
+	return visualization.NewExecutionTracer(c.Visualize(config...))
+}
+
+// TraceHistory 从检查点历史还原线程的执行路径。
+//
+// 从最新检查点（或 WithCheckpointID 指定的检查点）沿父节点回溯到线程开始，
+// 按时间顺序记录完成的节点、失败的节点（恢复后再次执行时计入尝试次数）
+// 和中断。子图中的中断记录在父图中对应的子图节点上。
+//
+// 参数：
+//   - ctx: 上下文
+//   - threadID: 线程标识
+//   - opts: 执行选项（WithCheckpointID）
+//
+// 返回：
+//   - *visualization.ExecutionTracer: 执行追踪器
+//   - error: 未配置检查点、线程没有检查点或读取失败
+//
+// 示例：
+//
+//	tracer, _ := compiled.TraceHistory(ctx, "user-123")
+//	mermaid, _ := visualization.ExportWithPath(tracer, visualization.FormatMermaid)
+//
+func (c *CompiledGraph[S]) TraceHistory(ctx context.Context, threadID string, opts ...interface{}) (*visualization.ExecutionTracer, error) {
+	latest, err := c.GetState(ctx, threadID, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := c.GetHistory(ctx, threadID, 0)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*StateSnapshot[S], len(history))
+	for _, snapshot := range history {
+		byID[snapshot.CheckpointID] = snapshot
+	}
+
+	// 当前分支，按时间顺序
+	var branch []*StateSnapshot[S]
+	for snapshot := latest; snapshot != nil; snapshot = byID[snapshot.ParentID] {
+		branch = append(branch, snapshot)
+	}
+	for i, j := 0, len(branch)-1; i < j; i, j = i+1, j-1 {
+		branch[i], branch[j] = branch[j], branch[i]
+	}
+
+	tracer := c.NewTracer()
+	failures := make(map[string]int)
+	completedStep := -1
+
+	for _, snapshot := range branch {
+		switch {
+		case snapshot.Error != "":
+			// 失败检查点：Next 是失败的节点
+			for _, name := range snapshot.Next {
+				failures[name]++
+				tracer.Record(visualization.TraceEvent{
+					Step:     snapshot.Step + 1,
+					Node:     name,
+					Status:   visualization.TraceFailed,
+					Attempts: failures[name],
+					Error:    snapshot.Error,
+				})
+			}
+
+		case snapshot.Source == SourceLoop && snapshot.NodeName != "" && snapshot.Step > completedStep:
+			completedStep = snapshot.Step
+			for _, name := range strings.Split(snapshot.NodeName, ",") {
+				tracer.Record(visualization.TraceEvent{
+					Step:     snapshot.Step,
+					Node:     name,
+					Status:   visualization.TraceCompleted,
+					Attempts: failures[name] + 1,
+				})
+				delete(failures, name)
+			}
+		}
+
+		if len(snapshot.Interrupts) > 0 {
+			c.traceSnapshotInterrupts(tracer, snapshot)
+		}
+	}
+
+	return tracer, nil
+}
+
+// traceSnapshotInterrupts 记录检查点中等待处理的中断。
+func (c *CompiledGraph[S]) traceSnapshotInterrupts(tracer *visualization.ExecutionTracer, snapshot *StateSnapshot[S]) {
+	seen := make(map[string]bool)
+	for _, interrupt := range snapshot.Interrupts {
+		step := snapshot.Step + 1
+		// Send 任务的中断（"节点名称[序号]"）记录在目标节点上
+		name, _, _ := strings.Cut(interrupt.GetNodeName(), "[")
+		if ns := interruptNamespace(interrupt); ns != "" {
+			// 子图中的中断：记录在父图的子图节点上
+			name, _, _ = strings.Cut(ns, ".")
+			name, _, _ = strings.Cut(name, "[")
+		} else if interrupt.Point.Type == hitl.InterruptAfter {
+			step = snapshot.Step
+		}
+
+		if !seen[name] {
+			seen[name] = true
+			tracer.Record(visualization.TraceEvent{
+				Step:   step,
+				Node:   name,
+				Status: visualization.TraceInterrupted,
+				Error:  string(interrupt.Reason),
+			})
+		}
+	}
+}
+
+// traceNode 记录节点的执行结果。
+func (x *execution[S]) traceNode(step int, n Node[S], err error) {
+	if x.tracer == nil {
+		return
+	}
+
+	event := visualization.TraceEvent{Step: step, Node: n.Name, Status: visualization.TraceCompleted}
+	if n.Task != nil {
+		event.Attempts = x.execCtx.GetTaskExecution(n.Task.ID).Attempts
+	}
+
+	switch {
+	case err == nil:
+	case errors.Is(err, hitl.ErrInterrupted):
+		event.Status = visualization.TraceInterrupted
+	default:
+		event.Status = visualization.TraceFailed
+		event.Error = err.Error()
+	}
+
+	x.tracer.Record(event)
+}
+
+// traceInterrupts 记录静态中断。
+func (x *execution[S]) traceInterrupts(step int, interrupts []*hitl.Interrupt) {
+	if x.tracer == nil {
+		return
+	}
+
+	for _, name := range interruptedNodes(interrupts) {
+		x.tracer.Record(visualization.TraceEvent{
+			Step:   step,
+			Node:   name,
+			Status: visualization.TraceInterrupted,
+			Error:  string(interrupts[0].Point.Type),
+		})
+	}
+}
+
+// graphAdapter 把 StateGraph 转换为 visualization.GraphAdapter。
+type graphAdapter[S any] struct {
+	graph      *StateGraph[S]
+	interrupts *hitl.InterruptManager
+}
+
+// GetTitle 返回图名称。
+func (g graphAdapter[S]) GetTitle() string {
+	return g.graph.name
+}
+
+// GetNodes 返回 START、按名称排序的节点以及 END。
+func (g graphAdapter[S]) GetNodes() []visualization.NodeInfo {
+	names := make([]string, 0, len(g.graph.nodes))
+	for name := range g.graph.nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	nodes := make([]visualization.NodeInfo, 0, len(names)+2)
+	nodes = append(nodes, visualization.NodeInfo{ID: START, Label: "START", Type: visualization.NodeTypeStart})
+
+	for _, name := range names {
+		info := visualization.NodeInfo{ID: name, Label: name, Type: visualization.NodeTypeRegular}
+		if g.graph.nodes[name].Subgraph {
+			info.Type = visualization.NodeTypeSubgraph
+		}
+		if task := g.graph.nodes[name].Task; task != nil {
+			info.Description = "durable task"
+		}
+
+		var kinds []string
+		for _, kind := range []hitl.InterruptType{hitl.InterruptBefore, hitl.InterruptAfter} {
+			if g.interrupts != nil && len(g.interrupts.GetInterruptPoints(name, kind)) > 0 {
+				kinds = append(kinds, string(kind))
+			}
+		}
+		if len(kinds) > 0 {
+			info.Metadata = map[string]string{"interrupt": strings.Join(kinds, ",")}
+		}
+
+		nodes = append(nodes, info)
+	}
+
+	nodes = append(nodes, visualization.NodeInfo{ID: END, Label: "END", Type: visualization.NodeTypeEnd})
+	return nodes
+}
+
+// GetEdges 返回 START 到入口点的边、普通边以及结束点到 END 的边。
+func (g graphAdapter[S]) GetEdges() []visualization.EdgeInfo {
+	edges := make([]visualization.EdgeInfo, 0, len(g.graph.edges)+len(g.graph.finishPoints)+1)
+	if g.graph.entryPoint != "" {
+		edges = append(edges, visualization.EdgeInfo{From: START, To: g.graph.entryPoint})
+	}
+
+	seen := make(map[[2]string]bool)
+	for _, edge := range g.graph.edges {
+		if edge.From == START && edge.To == g.graph.entryPoint {
+			continue
+		}
+		seen[[2]string{edge.From, edge.To}] = true
+		edges = append(edges, visualization.EdgeInfo{From: edge.From, To: edge.To})
+	}
+
+	finish := make([]string, 0, len(g.graph.finishPoints))
+	for name := range g.graph.finishPoints {
+		if !seen[[2]string{name, END}] {
+			finish = append(finish, name)
+		}
+	}
+	sort.Strings(finish)
+	for _, name := range finish {
+		edges = append(edges, visualization.EdgeInfo{From: name, To: END})
+	}
+
+	return edges
+}
+
+// GetConditionalEdges 返回条件边、分支边（以路径名为标签）和 Send 边（标签为 Send）。
+func (g graphAdapter[S]) GetConditionalEdges() []visualization.ConditionalEdgeInfo {
+	edges := make([]visualization.ConditionalEdgeInfo, 0, len(g.graph.conditionals)+len(g.graph.branches)+len(g.graph.sends))
+	for _, cond := range g.graph.conditionals {
+		edges = append(edges, visualization.ConditionalEdgeInfo{From: cond.Source, Paths: cond.PathMap})
+	}
+	for _, branch := range g.graph.branches {
+		edges = append(edges, visualization.ConditionalEdgeInfo{From: branch.GetSource(), Paths: branch.GetBranches()})
+	}
+	for _, send := range g.graph.sends {
+		edges = append(edges, visualization.ConditionalEdgeInfo{From: send.Source, Paths: sendTargets(send), Label: "Send"})
+	}
+	return edges
+}
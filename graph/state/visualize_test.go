@@ -0,0 +1,246 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zhucl121/langchain-go/graph/checkpoint"
+	"github.com/zhucl121/langchain-go/graph/durability"
+	"github.com/zhucl121/langchain-go/graph/visualization"
+)
+
+// newRoutingGraph 创建 draft -> review -(approve|reject)-> send|draft 的测试图
+func newRoutingGraph(t *testing.T, flaky int) *CompiledGraph[TestState] {
+	graph := NewStateGraph[TestState]("routing")
+
+	graph.AddNode("draft", func(ctx context.Context, s TestState) (TestState, error) {
+		s.Counter++
+		return s, nil
+	})
+	graph.AddNode("review", func(ctx context.Context, s TestState) (TestState, error) {
+		return s, nil
+	})
+
+	// send 前 flaky 次失败，由持久化任务重试
+	attempts := 0
+	graph.AddTask(durability.NewDurableTask("send", func(ctx context.Context, s TestState) (TestState, error) {
+		attempts++
+		if attempts <= flaky {
+			return s, errors.New("smtp unavailable")
+		}
+		s.Done = true
+		return s, nil
+	}).WithRetryPolicy(&durability.RetryPolicy{MaxRetries: 3, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, Multiplier: 1}))
+
+	graph.SetEntryPoint("draft")
+	graph.AddEdge("draft", "review")
+	graph.AddConditionalEdges("review", func(s TestState) string {
+		if s.Counter >= 2 {
+			return "approve"
+		}
+		return "reject"
+	}, map[string]string{"approve": "send", "reject": "draft"})
+	graph.AddEdge("send", END)
+
+	compiled, err := graph.Compile(InterruptBefore("send"))
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	return compiled
+}
+
+// TestVisualize_Structure 测试导出图结构
+func TestVisualize_Structure(t *testing.T) {
+	compiled := newRoutingGraph(t, 0)
+
+	mermaid, err := compiled.Draw(visualization.FormatMermaid)
+	if err != nil {
+		t.Fatalf("Draw failed: %v", err)
+	}
+	for _, want := range []string{
+		"__start__([START])",
+		"__start__ --> draft",
+		"draft --> review",
+		"review -->|approve| send",
+		"review -->|reject| draft",
+		"send --> __end__",
+	} {
+		if !strings.Contains(mermaid, want) {
+			t.Errorf("expected %q in mermaid output:\n%s", want, mermaid)
+		}
+	}
+
+	dot, err := compiled.Draw(visualization.FormatDOT)
+	if err != nil {
+		t.Fatalf("Draw failed: %v", err)
+	}
+	if !strings.Contains(dot, `"review" -> "draft" [label="reject", style=dashed]`) {
+		t.Errorf("expected labelled conditional edge in DOT output:\n%s", dot)
+	}
+
+	raw, err := compiled.Draw(visualization.FormatJSON)
+	if err != nil {
+		t.Fatalf("Draw failed: %v", err)
+	}
+	var doc struct {
+		Nodes []struct {
+			ID       string            `json:"id"`
+			Type     string            `json:"type"`
+			Metadata map[string]string `json:"metadata"`
+		} `json:"nodes"`
+		Edges []map[string]string `json:"edges"`
+	}
+	if err := json.Unmarshal([]byte(raw), &doc); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, raw)
+	}
+	if len(doc.Nodes) != 5 || len(doc.Edges) != 5 {
+		t.Errorf("expected 5 nodes and 5 edges, got %d and %d", len(doc.Nodes), len(doc.Edges))
+	}
+	for _, n := range doc.Nodes {
+		if n.ID == "send" && n.Metadata["interrupt"] != "before" {
+			t.Errorf("expected interrupt metadata on send, got %v", n.Metadata)
+		}
+	}
+}
+
+// TestVisualize_SubgraphNode 测试子图节点的形状
+func TestVisualize_SubgraphNode(t *testing.T) {
+	inner := NewStateGraph[TestState]("inner")
+	inner.AddNode("work", func(ctx context.Context, s TestState) (TestState, error) { return s, nil })
+	inner.SetEntryPoint("work")
+	inner.SetFinishPoint("work")
+	child, _ := inner.Compile()
+
+	mermaid := newParentGraph(nil, child).Visualize().ToMermaid()
+	if !strings.Contains(mermaid, "sub[(sub)]") {
+		t.Errorf("expected subgraph shape in mermaid output:\n%s", mermaid)
+	}
+}
+
+// TestVisualize_TraceRun 测试记录实际执行的路径、重试和中断
+func TestVisualize_TraceRun(t *testing.T) {
+	ctx := context.Background()
+	saver := checkpoint.NewMemoryCheckpointSaver[TestState]()
+	compiled := newRoutingGraph(t, 1)
+	compiled.graph.WithCheckpointer(saver)
+
+	tracer := compiled.NewTracer()
+	if _, err := compiled.Invoke(ctx, TestState{}, WithThreadID("t-1"), WithTracer(tracer)); err == nil {
+		t.Fatal("expected interrupt before send")
+	}
+	if _, err := compiled.ResumeWithInput(ctx, "t-1", nil, WithTracer(tracer)); err != nil {
+		t.Fatalf("Resume failed: %v", err)
+	}
+
+	var got []string
+	for _, event := range tracer.GetEvents() {
+		got = append(got, event.Node+":"+string(event.Status))
+	}
+	want := "draft:completed review:completed draft:completed review:completed send:interrupted send:completed"
+	if strings.Join(got, " ") != want {
+		t.Errorf("expected events %s, got %v", want, got)
+	}
+
+	events := tracer.GetEvents()
+	if last := events[len(events)-1]; last.Attempts != 2 {
+		t.Errorf("expected send to take 2 attempts, got %d", last.Attempts)
+	}
+
+	mermaid := tracer.ToMermaidWithPath()
+	if !strings.Contains(mermaid, "send[send (2 attempts)]") || !strings.Contains(mermaid, "linkStyle ") {
+		t.Errorf("expected retry label and highlighted edges:\n%s", mermaid)
+	}
+
+	dot := tracer.ToDOTWithPath()
+	if !strings.Contains(dot, `"review" -> "draft" [label="reject", style=dashed, color="#FF0000", penwidth=2.5]`) {
+		t.Errorf("expected traversed conditional edge to be highlighted:\n%s", dot)
+	}
+}
+
+// TestVisualize_TraceHistory 测试从检查点历史还原执行路径
+func TestVisualize_TraceHistory(t *testing.T) {
+	ctx := context.Background()
+	saver := checkpoint.NewMemoryCheckpointSaver[TestState]()
+	calls := make(map[string]int)
+
+	compiled := newApprovalGraph(saver, calls)
+	if _, err := compiled.Invoke(ctx, TestState{}, WithThreadID("t-1")); err == nil {
+		t.Fatal("expected interrupt")
+	}
+
+	tracer, err := compiled.TraceHistory(ctx, "t-1")
+	if err != nil {
+		t.Fatalf("TraceHistory failed: %v", err)
+	}
+	events := tracer.GetEvents()
+	if len(events) != 2 || events[1].Node != "review" || events[1].Status != visualization.TraceInterrupted {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+
+	if _, err := compiled.ResumeWithInput(ctx, "t-1", "yes"); err != nil {
+		t.Fatalf("Resume failed: %v", err)
+	}
+
+	tracer, err = compiled.TraceHistory(ctx, "t-1")
+	if err != nil {
+		t.Fatalf("TraceHistory failed: %v", err)
+	}
+	if path := tracer.GetPath(); strings.Join(path, ",") != "draft,review,review,send" {
+		t.Errorf("unexpected path: %v", path)
+	}
+
+	raw, err := visualization.ExportWithPath(tracer, visualization.FormatJSON)
+	if err != nil {
+		t.Fatalf("ExportWithPath failed: %v", err)
+	}
+	if !strings.Contains(raw, `"status": "interrupted"`) || !strings.Contains(raw, `"to": "__end__"`) {
+		t.Errorf("expected trace in JSON output:\n%s", raw)
+	}
+
+	if _, err := compiled.TraceHistory(ctx, "missing"); !errors.Is(err, checkpoint.ErrCheckpointNotFound) {
+		t.Errorf("expected ErrCheckpointNotFound, got %v", err)
+	}
+}
+
+// TestVisualize_TraceHistoryFailure 测试历史中的失败和重新执行
+func TestVisualize_TraceHistoryFailure(t *testing.T) {
+	ctx := context.Background()
+	saver := checkpoint.NewMemoryCheckpointSaver[TestState]()
+
+	fail := true
+	graph := NewStateGraph[TestState]("flaky")
+	graph.AddNode("fetch", func(ctx context.Context, s TestState) (TestState, error) {
+		if fail {
+			return s, errors.New("timeout")
+		}
+		return s, nil
+	})
+	graph.SetEntryPoint("fetch")
+	graph.SetFinishPoint("fetch")
+	graph.WithCheckpointer(saver)
+	compiled, _ := graph.Compile()
+
+	if _, err := compiled.Invoke(ctx, TestState{}, WithThreadID("t-1")); err == nil {
+		t.Fatal("expected failure")
+	}
+	fail = false
+	if _, err := compiled.Invoke(ctx, TestState{}, WithThreadID("t-1")); err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+
+	tracer, err := compiled.TraceHistory(ctx, "t-1")
+	if err != nil {
+		t.Fatalf("TraceHistory failed: %v", err)
+	}
+	events := tracer.GetEvents()
+	if len(events) != 2 || events[0].Status != visualization.TraceFailed || events[0].Error == "" {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+	if events[1].Status != visualization.TraceCompleted || events[1].Attempts != 2 {
+		t.Errorf("expected fetch to complete on attempt 2, got %+v", events[1])
+	}
+}
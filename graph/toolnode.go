@@ -218,7 +218,7 @@ func (tn *ToolNode[S]) executeOne(ctx context.Context, toolCall types.ToolCall)
 	}
 	
 	// 执行工具
-	output, err := tool.Execute(ctx, input)
+	output, err := tools.Run(ctx, tool, input)
 	
 	return ToolCallResult{
 		ToolName: toolName,
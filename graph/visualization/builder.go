@@ -2,7 +2,9 @@ package visualization
 
 import (
 	"fmt"
+	"sort"
 	"strings"
+	"sync"
 )
 
 // GraphAdapter 图适配器接口
@@ -139,11 +141,41 @@ func (sgb *SimpleGraphBuilder) GetTitle() string {
 }
 
 // ExecutionTracer 执行追踪器
-// 用于可视化图的执行路径
+// 用于可视化图的执行路径，可以在执行过程中记录，也可以从检查点历史还原
+// 并行执行的节点可能同时调用 Record，ExecutionTracer 是并发安全的
 type ExecutionTracer struct {
 	visualizer *GraphVisualizer
 	path       []string
 	current    string
+	events     []TraceEvent
+	mu         sync.Mutex
+}
+
+// TraceStatus 节点执行状态
+type TraceStatus string
+
+const (
+	TraceCompleted   TraceStatus = "completed"
+	TraceFailed      TraceStatus = "failed"
+	TraceInterrupted TraceStatus = "interrupted"
+)
+
+// TraceEvent 一次节点执行记录
+type TraceEvent struct {
+	// Step 超步序号，同一步的节点并行执行
+	Step int
+	
+	// Node 节点 ID
+	Node string
+	
+	// Status 执行状态
+	Status TraceStatus
+	
+	// Attempts 尝试次数（大于 1 表示发生过重试）
+	Attempts int
+	
+	// Error 失败或中断的原因
+	Error string
 }
 
 // NewExecutionTracer 创建执行追踪器
@@ -154,25 +186,60 @@ func NewExecutionTracer(gv *GraphVisualizer) *ExecutionTracer {
 	}
 }
 
-// Visit 访问节点
+// Visit 访问节点（作为新的一步成功执行）
 func (et *ExecutionTracer) Visit(nodeID string) {
-	et.path = append(et.path, nodeID)
-	et.current = nodeID
+	et.Record(TraceEvent{Node: nodeID, Status: TraceCompleted})
+}
+
+// Record 记录一次节点执行
+// Step 为 0 时视为新的一步，Status 为空时视为成功，Attempts 为 0 时视为 1
+func (et *ExecutionTracer) Record(event TraceEvent) {
+	et.mu.Lock()
+	defer et.mu.Unlock()
+	
+	if event.Step == 0 {
+		event.Step = 1
+		if n := len(et.events); n > 0 {
+			event.Step = et.events[n-1].Step + 1
+		}
+	}
+	if event.Status == "" {
+		event.Status = TraceCompleted
+	}
+	if event.Attempts == 0 {
+		event.Attempts = 1
+	}
+	
+	et.events = append(et.events, event)
+	et.path = append(et.path, event.Node)
+	et.current = event.Node
 }
 
 // GetPath 获取执行路径
 func (et *ExecutionTracer) GetPath() []string {
-	return et.path
+	et.mu.Lock()
+	defer et.mu.Unlock()
+	return append([]string(nil), et.path...)
+}
+
+// GetEvents 获取执行记录
+func (et *ExecutionTracer) GetEvents() []TraceEvent {
+	et.mu.Lock()
+	defer et.mu.Unlock()
+	return append([]TraceEvent(nil), et.events...)
 }
 
 // ToMermaidWithPath 导出带执行路径的 Mermaid
+// 经过的节点按顺序由浅到深高亮，经过的边加粗；失败的节点标红，中断的节点以橙色虚线标出，
+// 重试过的节点在标签中注明尝试次数
 func (et *ExecutionTracer) ToMermaidWithPath() string {
-	base := et.visualizer.ToMermaid()
+	ov := et.overlay()
+	base := ov.visualizer.ToMermaid()
 	
 	// 添加路径高亮
 	var styles strings.Builder
-	for i, nodeID := range et.path {
-		opacity := float64(i+1) / float64(len(et.path))
+	for i, nodeID := range ov.path {
+		opacity := float64(i+1) / float64(len(ov.path))
 		color := fmt.Sprintf("#FF%02X%02X", 
 			255-int(opacity*100), 
 			255-int(opacity*100))
@@ -180,9 +247,227 @@ func (et *ExecutionTracer) ToMermaidWithPath() string {
 			nodeID, color))
 	}
 	
+	for _, nodeID := range ov.nodes(TraceFailed) {
+		styles.WriteString(fmt.Sprintf("    style %s fill:#FF6B6B,stroke:#B00020,stroke-width:3px\n", nodeID))
+	}
+	for _, nodeID := range ov.nodes(TraceInterrupted) {
+		styles.WriteString(fmt.Sprintf("    style %s fill:#FFA500,stroke:#FF8C00,stroke-width:3px,stroke-dasharray:5 5\n", nodeID))
+	}
+	
+	// 经过的边（linkStyle 按边的导出顺序编号）
+	var links []string
+	for i, edge := range ov.visualizer.allEdges() {
+		if ov.edges[edgeKey{edge.From, edge.To}] {
+			links = append(links, fmt.Sprint(i))
+		}
+	}
+	if len(links) > 0 {
+		styles.WriteString(fmt.Sprintf("    linkStyle %s stroke:#FF0000,stroke-width:3px\n", strings.Join(links, ",")))
+	}
+	
 	return base + styles.String()
 }
 
+// ToDOTWithPath 导出带执行路径的 DOT
+func (et *ExecutionTracer) ToDOTWithPath() string {
+	ov := et.overlay()
+	
+	var extra strings.Builder
+	extra.WriteString("\n")
+	for _, nodeID := range ov.path {
+		fill := "#FFC0C0"
+		switch ov.status[nodeID] {
+		case TraceFailed:
+			fill = "#FF6B6B"
+		case TraceInterrupted:
+			fill = "#FFA500"
+		}
+		extra.WriteString(fmt.Sprintf("    \"%s\" [style=\"rounded,filled,bold\", fillcolor=\"%s\", color=\"#FF0000\", penwidth=2.5];\n",
+			nodeID, fill))
+	}
+	
+	return ov.visualizer.toDOT(ov.edges, extra.String())
+}
+
+// ToJSONWithPath 导出带执行记录的 JSON
+func (et *ExecutionTracer) ToJSONWithPath() string {
+	ov := et.overlay()
+	
+	graph := ov.visualizer.jsonGraph()
+	graph.Trace = &jsonTrace{
+		Path:   ov.path,
+		Events: make([]jsonTraceEvent, 0, len(ov.events)),
+		Edges:  make([]jsonEdge, 0, len(ov.edges)),
+	}
+	for _, event := range ov.events {
+		graph.Trace.Events = append(graph.Trace.Events, jsonTraceEvent(event))
+	}
+	for _, edge := range ov.visualizer.allEdges() {
+		if ov.edges[edgeKey{edge.From, edge.To}] {
+			graph.Trace.Edges = append(graph.Trace.Edges, jsonEdge{From: edge.From, To: edge.To})
+		}
+	}
+	
+	return marshalJSON(graph)
+}
+
+// jsonTrace 是 JSON 导出中的执行记录
+type jsonTrace struct {
+	Path   []string         `json:"path"`
+	Events []jsonTraceEvent `json:"events"`
+	Edges  []jsonEdge       `json:"edges"`
+}
+
+type jsonTraceEvent struct {
+	Step     int         `json:"step"`
+	Node     string      `json:"node"`
+	Status   TraceStatus `json:"status"`
+	Attempts int         `json:"attempts"`
+	Error    string      `json:"error,omitempty"`
+}
+
+// traceOverlay 是执行记录叠加到图上的结果
+type traceOverlay struct {
+	visualizer *GraphVisualizer        // 标签已注明重试、失败和中断的图
+	events     []TraceEvent
+	path       []string                // 经过的节点（去重，按首次经过的顺序）
+	status     map[string]TraceStatus  // 节点最后一次执行的状态
+	edges      map[edgeKey]bool        // 经过的边
+}
+
+// nodes 返回最后一次执行为指定状态的节点
+func (ov *traceOverlay) nodes(status TraceStatus) []string {
+	var result []string
+	for _, nodeID := range ov.path {
+		if ov.status[nodeID] == status {
+			result = append(result, nodeID)
+		}
+	}
+	return result
+}
+
+// overlay 把执行记录叠加到图上
+func (et *ExecutionTracer) overlay() *traceOverlay {
+	events := et.GetEvents()
+	ov := &traceOverlay{
+		events: events,
+		status: make(map[string]TraceStatus),
+		edges:  make(map[edgeKey]bool),
+	}
+	
+	attempts := make(map[string]int)
+	var steps []int
+	byStep := make(map[int][]TraceEvent)
+	for _, event := range events {
+		if _, seen := ov.status[event.Node]; !seen {
+			ov.path = append(ov.path, event.Node)
+		}
+		ov.status[event.Node] = event.Status
+		if event.Attempts > attempts[event.Node] {
+			attempts[event.Node] = event.Attempts
+		}
+		
+		if _, seen := byStep[event.Step]; !seen {
+			steps = append(steps, event.Step)
+		}
+		byStep[event.Step] = append(byStep[event.Step], event)
+	}
+	sort.Ints(steps)
+	
+	// 原图的副本，标签注明执行结果
+	gv := et.visualizer
+	ov.visualizer = NewGraphVisualizer(gv.title, gv.config)
+	ov.visualizer.edges = gv.edges
+	ov.visualizer.conditionalEdges = gv.conditionalEdges
+	
+	var starts, ends []string
+	for _, node := range gv.nodes {
+		label := node.Label
+		if label == "" {
+			label = node.ID
+		}
+		switch {
+		case ov.status[node.ID] == TraceFailed:
+			label += " (failed)"
+		case ov.status[node.ID] == TraceInterrupted:
+			label += " (interrupted)"
+		}
+		if n := attempts[node.ID]; n > 1 {
+			label += fmt.Sprintf(" (%d attempts)", n)
+		}
+		node.Label = label
+		ov.visualizer.AddNode(node)
+		
+		switch node.Type {
+		case NodeTypeStart:
+			starts = append(starts, node.ID)
+		case NodeTypeEnd:
+			ends = append(ends, node.ID)
+		}
+	}
+	
+	// 相邻两步之间存在的边视为经过的边
+	// 记录中没有出现的起点和终点节点分别连接第一步和最后一步
+	mark := func(from []string, to []TraceEvent) {
+		for _, a := range from {
+			for _, b := range to {
+				ov.edges[edgeKey{a, b.Node}] = true
+			}
+		}
+	}
+	if len(steps) > 0 {
+		mark(filterUnvisited(starts, ov.status), byStep[steps[0]])
+	}
+	for i := 0; i+1 < len(steps); i++ {
+		mark(completedNodes(byStep[steps[i]]), byStep[steps[i+1]])
+	}
+	if len(steps) > 0 {
+		last := byStep[steps[len(steps)-1]]
+		var endEvents []TraceEvent
+		for _, nodeID := range filterUnvisited(ends, ov.status) {
+			endEvents = append(endEvents, TraceEvent{Node: nodeID})
+		}
+		if len(completedNodes(last)) == len(last) {
+			mark(completedNodes(last), endEvents)
+		}
+	}
+	
+	// 只保留图中存在的边
+	exists := make(map[edgeKey]bool)
+	for _, edge := range gv.allEdges() {
+		exists[edgeKey{edge.From, edge.To}] = true
+	}
+	for key := range ov.edges {
+		if !exists[key] {
+			delete(ov.edges, key)
+		}
+	}
+	
+	return ov
+}
+
+// completedNodes 返回成功执行的节点
+func completedNodes(events []TraceEvent) []string {
+	var nodes []string
+	for _, event := range events {
+		if event.Status == TraceCompleted {
+			nodes = append(nodes, event.Node)
+		}
+	}
+	return nodes
+}
+
+// filterUnvisited 返回没有执行记录的节点
+func filterUnvisited(nodes []string, status map[string]TraceStatus) []string {
+	var result []string
+	for _, nodeID := range nodes {
+		if _, visited := status[nodeID]; !visited {
+			result = append(result, nodeID)
+		}
+	}
+	return result
+}
+
 // VisualizationFormat 可视化格式
 type VisualizationFormat string
 
@@ -193,6 +478,40 @@ const (
 	FormatJSON    VisualizationFormat = "json"
 )
 
+// ExportWithPath 导出带执行路径的图
+// ASCII 格式在图之后列出每一步的执行记录
+func ExportWithPath(et *ExecutionTracer, format VisualizationFormat) (string, error) {
+	if err := et.visualizer.Validate(); err != nil {
+		return "", fmt.Errorf("validation failed: %w", err)
+	}
+	
+	switch format {
+	case FormatMermaid:
+		return et.ToMermaidWithPath(), nil
+	case FormatDOT:
+		return et.ToDOTWithPath(), nil
+	case FormatJSON:
+		return et.ToJSONWithPath(), nil
+	case FormatASCII:
+		var sb strings.Builder
+		sb.WriteString(et.overlay().visualizer.ToASCII())
+		sb.WriteString("\nTrace:\n")
+		for _, event := range et.GetEvents() {
+			sb.WriteString(fmt.Sprintf("  [%d] %s %s", event.Step, event.Node, event.Status))
+			if event.Attempts > 1 {
+				sb.WriteString(fmt.Sprintf(" (%d attempts)", event.Attempts))
+			}
+			if event.Error != "" {
+				sb.WriteString(": " + event.Error)
+			}
+			sb.WriteString("\n")
+		}
+		return sb.String(), nil
+	default:
+		return "", fmt.Errorf("unsupported format: %s", format)
+	}
+}
+
 // Export 导出为指定格式
 func Export(gv *GraphVisualizer, format VisualizationFormat) (string, error) {
 	if err := gv.Validate(); err != nil {
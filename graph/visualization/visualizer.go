@@ -1,7 +1,9 @@
 package visualization
 
 import (
+	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 )
 
@@ -117,8 +119,8 @@ func (gv *GraphVisualizer) ToMermaid() string {
 		sb.WriteString(gv.formatMermaidNode(node))
 	}
 	
-	// 边定义
-	for _, edge := range gv.edges {
+	// 边定义（条件边按路径展开，以路径名为标签）
+	for _, edge := range gv.allEdges() {
 		if edge.Label != "" {
 			sb.WriteString(fmt.Sprintf("    %s -->|%s| %s\n", edge.From, edge.Label, edge.To))
 		} else {
@@ -126,17 +128,6 @@ func (gv *GraphVisualizer) ToMermaid() string {
 		}
 	}
 	
-	// 条件边定义
-	for _, condEdge := range gv.conditionalEdges {
-		for path, target := range condEdge.Paths {
-			label := path
-			if condEdge.Label != "" {
-				label = fmt.Sprintf("%s: %s", condEdge.Label, path)
-			}
-			sb.WriteString(fmt.Sprintf("    %s -->|%s| %s\n", condEdge.From, label, target))
-		}
-	}
-	
 	// 样式
 	sb.WriteString(gv.getMermaidStyles())
 	
@@ -191,6 +182,12 @@ func (gv *GraphVisualizer) getMermaidStyles() string {
 
 // ToDOT 导出为 DOT/Graphviz 格式
 func (gv *GraphVisualizer) ToDOT() string {
+	return gv.toDOT(nil, "")
+}
+
+// toDOT 导出为 DOT 格式
+// highlight 中的边加粗显示，extra 在图末尾追加（用于覆盖节点样式）
+func (gv *GraphVisualizer) toDOT(highlight map[edgeKey]bool, extra string) string {
 	var sb strings.Builder
 	
 	// 图头部
@@ -213,27 +210,27 @@ func (gv *GraphVisualizer) ToDOT() string {
 	
 	sb.WriteString("\n")
 	
-	// 边定义
-	for _, edge := range gv.edges {
+	// 边定义（条件边为虚线）
+	for _, edge := range gv.allEdges() {
+		var attrs []string
 		if edge.Label != "" {
-			sb.WriteString(fmt.Sprintf("    \"%s\" -> \"%s\" [label=\"%s\"];\n", edge.From, edge.To, edge.Label))
+			attrs = append(attrs, fmt.Sprintf("label=\"%s\"", edge.Label))
+		}
+		if edge.Conditional {
+			attrs = append(attrs, "style=dashed")
+		}
+		if highlight[edgeKey{edge.From, edge.To}] {
+			attrs = append(attrs, "color=\"#FF0000\"", "penwidth=2.5")
+		}
+		
+		if len(attrs) > 0 {
+			sb.WriteString(fmt.Sprintf("    \"%s\" -> \"%s\" [%s];\n", edge.From, edge.To, strings.Join(attrs, ", ")))
 		} else {
 			sb.WriteString(fmt.Sprintf("    \"%s\" -> \"%s\";\n", edge.From, edge.To))
 		}
 	}
 	
-	// 条件边定义
-	for _, condEdge := range gv.conditionalEdges {
-		for path, target := range condEdge.Paths {
-			label := path
-			if condEdge.Label != "" {
-				label = fmt.Sprintf("%s: %s", condEdge.Label, path)
-			}
-			sb.WriteString(fmt.Sprintf("    \"%s\" -> \"%s\" [label=\"%s\", style=dashed];\n", 
-				condEdge.From, target, label))
-		}
-	}
-	
+	sb.WriteString(extra)
 	sb.WriteString("}\n")
 	
 	return sb.String()
@@ -326,24 +323,15 @@ func (gv *GraphVisualizer) ToASCII() string {
 	
 	sb.WriteString("\nEdges:\n")
 	
-	// 显示普通边
-	for _, edge := range gv.edges {
-		arrow := "→"
-		if edge.Label != "" {
+	// 显示边
+	for _, edge := range gv.allEdges() {
+		switch {
+		case edge.Conditional:
+			sb.WriteString(fmt.Sprintf("  %s --%s--> %s (conditional)\n", edge.From, edge.Label, edge.To))
+		case edge.Label != "":
 			sb.WriteString(fmt.Sprintf("  %s --%s--> %s\n", edge.From, edge.Label, edge.To))
-		} else {
-			sb.WriteString(fmt.Sprintf("  %s %s %s\n", edge.From, arrow, edge.To))
-		}
-	}
-	
-	// 显示条件边
-	for _, condEdge := range gv.conditionalEdges {
-		for path, target := range condEdge.Paths {
-			label := path
-			if condEdge.Label != "" {
-				label = fmt.Sprintf("%s: %s", condEdge.Label, path)
-			}
-			sb.WriteString(fmt.Sprintf("  %s --%s--> %s (conditional)\n", condEdge.From, label, target))
+		default:
+			sb.WriteString(fmt.Sprintf("  %s → %s\n", edge.From, edge.To))
 		}
 	}
 	
@@ -352,81 +340,124 @@ func (gv *GraphVisualizer) ToASCII() string {
 
 // ToJSON 导出为 JSON 格式
 func (gv *GraphVisualizer) ToJSON() string {
-	var sb strings.Builder
-	
-	sb.WriteString("{\n")
+	return marshalJSON(gv.jsonGraph())
+}
+
+// jsonGraph 是 JSON 导出的结构
+type jsonGraph struct {
+	Title  string     `json:"title,omitempty"`
+	Config jsonConfig `json:"config"`
+	Nodes  []jsonNode `json:"nodes"`
+	Edges  []jsonEdge `json:"edges"`
+	Trace  *jsonTrace `json:"trace,omitempty"`
+}
+
+type jsonConfig struct {
+	Direction string `json:"direction"`
+	Theme     string `json:"theme"`
+}
+
+type jsonNode struct {
+	ID          string            `json:"id"`
+	Label       string            `json:"label"`
+	Type        NodeType          `json:"type"`
+	Description string            `json:"description,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+}
+
+type jsonEdge struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Label string `json:"label,omitempty"`
+	Type  string `json:"type,omitempty"`
+}
+
+// jsonGraph 构建 JSON 导出的结构
+func (gv *GraphVisualizer) jsonGraph() *jsonGraph {
+	graph := &jsonGraph{
+		Title: gv.title,
+		Config: jsonConfig{
+			Direction: gv.config.Direction,
+			Theme:     gv.config.Theme,
+		},
+		Nodes: make([]jsonNode, 0, len(gv.nodes)),
+		Edges: make([]jsonEdge, 0, len(gv.edges)),
+	}
 	
-	// 标题
-	if gv.title != "" {
-		sb.WriteString(fmt.Sprintf("  \"title\": \"%s\",\n", gv.title))
+	for _, node := range gv.nodes {
+		graph.Nodes = append(graph.Nodes, jsonNode{
+			ID:          node.ID,
+			Label:       node.Label,
+			Type:        node.Type,
+			Description: node.Description,
+			Metadata:    node.Metadata,
+		})
 	}
 	
-	// 配置
-	sb.WriteString("  \"config\": {\n")
-	sb.WriteString(fmt.Sprintf("    \"direction\": \"%s\",\n", gv.config.Direction))
-	sb.WriteString(fmt.Sprintf("    \"theme\": \"%s\"\n", gv.config.Theme))
-	sb.WriteString("  },\n")
-	
-	// 节点
-	sb.WriteString("  \"nodes\": [\n")
-	for i, node := range gv.nodes {
-		sb.WriteString("    {\n")
-		sb.WriteString(fmt.Sprintf("      \"id\": \"%s\",\n", node.ID))
-		sb.WriteString(fmt.Sprintf("      \"label\": \"%s\",\n", node.Label))
-		sb.WriteString(fmt.Sprintf("      \"type\": \"%s\"", node.Type))
-		if node.Description != "" {
-			sb.WriteString(",\n")
-			sb.WriteString(fmt.Sprintf("      \"description\": \"%s\"", node.Description))
+	for _, edge := range gv.allEdges() {
+		e := jsonEdge{From: edge.From, To: edge.To, Label: edge.Label}
+		if edge.Conditional {
+			e.Type = "conditional"
 		}
-		sb.WriteString("\n    }")
-		if i < len(gv.nodes)-1 {
-			sb.WriteString(",")
-		}
-		sb.WriteString("\n")
+		graph.Edges = append(graph.Edges, e)
 	}
-	sb.WriteString("  ],\n")
-	
-	// 边
-	sb.WriteString("  \"edges\": [\n")
-	totalEdges := len(gv.edges) + len(gv.conditionalEdges)
-	edgeCount := 0
 	
+	return graph
+}
+
+// marshalJSON 以缩进格式序列化
+func marshalJSON(v any) string {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("{\"error\": %q}\n", err.Error())
+	}
+	return string(data) + "\n"
+}
+
+// edgeKey 标识一条边
+type edgeKey struct {
+	From string
+	To   string
+}
+
+// edgeView 是导出时的一条边
+type edgeView struct {
+	From        string
+	To          string
+	Label       string
+	Conditional bool
+}
+
+// allEdges 返回导出顺序的所有边
+// 普通边按添加顺序在前；条件边按路径展开，以路径名为标签，按路径名排序
+func (gv *GraphVisualizer) allEdges() []edgeView {
+	edges := make([]edgeView, 0, len(gv.edges))
 	for _, edge := range gv.edges {
-		sb.WriteString("    {\n")
-		sb.WriteString(fmt.Sprintf("      \"from\": \"%s\",\n", edge.From))
-		sb.WriteString(fmt.Sprintf("      \"to\": \"%s\"", edge.To))
-		if edge.Label != "" {
-			sb.WriteString(",\n")
-			sb.WriteString(fmt.Sprintf("      \"label\": \"%s\"", edge.Label))
-		}
-		sb.WriteString("\n    }")
-		edgeCount++
-		if edgeCount < totalEdges {
-			sb.WriteString(",")
-		}
-		sb.WriteString("\n")
+		edges = append(edges, edgeView{From: edge.From, To: edge.To, Label: edge.Label})
 	}
 	
 	for _, condEdge := range gv.conditionalEdges {
-		for path, target := range condEdge.Paths {
-			sb.WriteString("    {\n")
-			sb.WriteString(fmt.Sprintf("      \"from\": \"%s\",\n", condEdge.From))
-			sb.WriteString(fmt.Sprintf("      \"to\": \"%s\",\n", target))
-			sb.WriteString(fmt.Sprintf("      \"label\": \"%s\",\n", path))
-			sb.WriteString("      \"type\": \"conditional\"\n")
-			sb.WriteString("    }")
-			edgeCount++
-			if edgeCount < totalEdges {
-				sb.WriteString(",")
+		paths := make([]string, 0, len(condEdge.Paths))
+		for path := range condEdge.Paths {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		
+		for _, path := range paths {
+			label := path
+			if condEdge.Label != "" {
+				label = fmt.Sprintf("%s: %s", condEdge.Label, path)
 			}
-			sb.WriteString("\n")
+			edges = append(edges, edgeView{
+				From:        condEdge.From,
+				To:          condEdge.Paths[path],
+				Label:       label,
+				Conditional: true,
+			})
 		}
 	}
 	
-	sb.WriteString("  ]\n")
-	sb.WriteString("}\n")
-	
-	return sb.String()
+	return edges
 }
 
 // Validate 验证图结构
@@ -343,6 +343,49 @@ func TestExecutionTracer(t *testing.T) {
 	}
 }
 
+// TestExecutionTracerEvents 测试失败、重试和中断的标注
+func TestExecutionTracerEvents(t *testing.T) {
+	gv := NewGraphVisualizer("Events Test")
+	gv.AddNode(NodeInfo{ID: "A", Type: NodeTypeStart})
+	gv.AddNode(NodeInfo{ID: "B", Type: NodeTypeRegular})
+	gv.AddNode(NodeInfo{ID: "C", Type: NodeTypeRegular})
+	gv.AddNode(NodeInfo{ID: "D", Type: NodeTypeEnd})
+	gv.AddEdge(EdgeInfo{From: "A", To: "B"})
+	gv.AddConditionalEdge(ConditionalEdgeInfo{From: "B", Paths: map[string]string{"ok": "C", "skip": "D"}})
+	gv.AddEdge(EdgeInfo{From: "C", To: "D"})
+	
+	tracer := NewExecutionTracer(gv)
+	tracer.Visit("B")
+	tracer.Record(TraceEvent{Node: "C", Status: TraceFailed, Error: "boom"})
+	tracer.Record(TraceEvent{Step: 2, Node: "C", Attempts: 2})
+	tracer.Record(TraceEvent{Step: 3, Node: "D", Status: TraceInterrupted})
+	
+	events := tracer.GetEvents()
+	if len(events) != 4 || events[1].Step != 2 || events[1].Attempts != 1 {
+		t.Fatalf("Unexpected events: %+v", events)
+	}
+	
+	mermaid := tracer.ToMermaidWithPath()
+	for _, want := range []string{"C[C (2 attempts)]", "D([D (interrupted)])", "linkStyle 0,1"} {
+		if !strings.Contains(mermaid, want) {
+			t.Errorf("Expected %q in output:\n%s", want, mermaid)
+		}
+	}
+	
+	dot := tracer.ToDOTWithPath()
+	if !strings.Contains(dot, `"B" -> "C" [label="ok", style=dashed, color="#FF0000", penwidth=2.5]`) {
+		t.Errorf("Expected highlighted conditional edge:\n%s", dot)
+	}
+	if strings.Contains(dot, `"B" -> "D" [label="skip", style=dashed, color`) {
+		t.Error("Untraversed edge should not be highlighted")
+	}
+	
+	ascii, err := ExportWithPath(tracer, FormatASCII)
+	if err != nil || !strings.Contains(ascii, "Trace:") {
+		t.Errorf("Expected trace listing, got %q (%v)", ascii, err)
+	}
+}
+
 // TestConfigurations 测试不同配置
 func TestConfigurations(t *testing.T) {
 	t.Run("LR direction", func(t *testing.T) {
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"sync"
 	"time"
+
+	chaterrors "github.com/zhucl121/langchain-go/core/chat/errors"
 )
 
 var (
@@ -57,6 +59,13 @@ type CircuitBreakerConfig struct {
 
 	// OnStateChange 状态变化回调
 	OnStateChange func(from, to CircuitState)
+
+	// IsFailure 判断错误是否计为失败（可选）
+	//
+	// 返回 false 的错误说明服务仍能正常响应，按成功处理。
+	// 默认使用 chaterrors.IsProviderFault：超出上下文长度、内容拦截、无效请求
+	// 等由请求本身导致的错误以及调用方取消不会触发熔断。
+	IsFailure func(err error) bool
 }
 
 // DefaultCircuitBreakerConfig 返回默认配置
@@ -107,6 +116,9 @@ func NewCircuitBreaker(config CircuitBreakerConfig) *CircuitBreaker {
 	if config.MaxRequests == 0 {
 		config.MaxRequests = 1
 	}
+	if config.IsFailure == nil {
+		config.IsFailure = chaterrors.IsProviderFault
+	}
 
 	return &CircuitBreaker{
 		config: config,
@@ -179,11 +191,11 @@ func (cb *CircuitBreaker) afterRequest(err error) {
 	cb.mu.Lock()
 	defer cb.mu.Unlock()
 
-	if err != nil {
+	if err != nil && cb.config.IsFailure(err) {
 		// 请求失败
 		cb.onFailure()
 	} else {
-		// 请求成功
+		// 请求成功（包括不计为失败的错误）
 		cb.onSuccess()
 	}
 }
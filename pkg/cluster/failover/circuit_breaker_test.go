@@ -1,9 +1,12 @@
 package failover
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
+
+	chaterrors "github.com/zhucl121/langchain-go/core/chat/errors"
 )
 
 func TestNewCircuitBreaker(t *testing.T) {
@@ -317,3 +320,35 @@ func TestCircuitBreaker_Stats(t *testing.T) {
 		t.Errorf("FailedRequests = %d, want 2", stats.FailedRequests)
 	}
 }
+
+func TestCircuitBreaker_ProviderErrors(t *testing.T) {
+	config := DefaultCircuitBreakerConfig()
+	config.FailureThreshold = 2
+	cb := NewCircuitBreaker(config)
+
+	// 请求本身导致的错误不触发熔断
+	for i := 0; i < 3; i++ {
+		cb.Execute(func() error {
+			return chaterrors.New("openai", 400, "context_length_exceeded", "too long")
+		})
+		cb.Execute(func() error {
+			return context.Canceled
+		})
+	}
+	if cb.GetState() != StateClosed {
+		t.Fatalf("State = %s, want %s", cb.GetState(), StateClosed)
+	}
+
+	// 提供商故障触发熔断
+	for i := 0; i < 2; i++ {
+		cb.Execute(func() error {
+			return chaterrors.New("openai", 429, "", "slow down")
+		})
+	}
+	if cb.GetState() != StateOpen {
+		t.Errorf("State = %s, want %s", cb.GetState(), StateOpen)
+	}
+	if stats := cb.GetStats(); stats.FailedRequests != 2 {
+		t.Errorf("FailedRequests = %d, want 2", stats.FailedRequests)
+	}
+}
@@ -0,0 +1,111 @@
+package cost
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/zhucl121/langchain-go/core/chat"
+	"github.com/zhucl121/langchain-go/pkg/enterprise/tenant"
+)
+
+// ErrBudgetExceeded 预算已用完
+var ErrBudgetExceeded = errors.New("cost: budget exceeded")
+
+// BudgetExceededError 描述超出的预算。
+//
+// errors.Is(err, ErrBudgetExceeded) 对该错误返回 true。
+//
+type BudgetExceededError struct {
+	// Scope 预算范围
+	Scope Scope
+
+	// ID 运行、线程或租户 ID
+	ID string
+
+	// Limit 预算上限（美元）
+	Limit float64
+
+	// Spent 已花费（美元）
+	Spent float64
+}
+
+// Error 实现 error 接口
+func (e *BudgetExceededError) Error() string {
+	return fmt.Sprintf("cost: %s %q budget exceeded: spent $%.6f of $%.6f", e.Scope, e.ID, e.Spent, e.Limit)
+}
+
+// Unwrap 返回 ErrBudgetExceeded
+func (e *BudgetExceededError) Unwrap() error {
+	return ErrBudgetExceeded
+}
+
+// Budget 是一个范围内的费用上限。
+//
+// 调用前检查当前运行、线程或租户的累计费用，达到上限后：
+//   - Downgrade 为 nil：调用失败，返回 *BudgetExceededError
+//   - Downgrade 不为 nil：改用 Downgrade 模型（通常是更便宜的模型）
+//
+// 降级后的调用不再受该预算限制；需要硬上限时，再添加一个更高的、不降级的预算。
+//
+// 示例：
+//
+//	// 单次运行最多 $0.50，超出后失败
+//	cost.Budget{Scope: cost.ScopeRun, Limit: 0.50}
+//
+//	// 每个线程超过 $5 后改用 gpt-4o-mini
+//	cost.Budget{Scope: cost.ScopeThread, Limit: 5, Downgrade: miniModel}
+//
+type Budget struct {
+	// Scope 预算范围
+	Scope Scope
+
+	// Limit 上限（美元）
+	Limit float64
+
+	// Downgrade 超出后使用的模型，为 nil 时调用失败
+	Downgrade chat.ChatModel
+}
+
+// check 检查预算，超出时返回 *BudgetExceededError
+func (b Budget) check(tracker *Tracker, labels Labels) error {
+	id := labels.id(b.Scope)
+	if id == "" {
+		return nil
+	}
+
+	spent := tracker.Spend(b.Scope, id).Cost
+	if spent < b.Limit {
+		return nil
+	}
+
+	return &BudgetExceededError{Scope: b.Scope, ID: id, Limit: b.Limit, Spent: spent}
+}
+
+// tenantQuota 是由租户配额（tenant.Quota.MaxCostMicros）决定上限的预算
+type tenantQuota struct {
+	manager   tenant.TenantManager
+	downgrade chat.ChatModel
+}
+
+// check 检查租户的费用配额
+func (q tenantQuota) check(ctx context.Context, labels Labels) error {
+	if labels.TenantID == "" {
+		return nil
+	}
+
+	check, err := q.manager.CheckQuota(ctx, labels.TenantID, tenant.ResourceTypeCost)
+	if err != nil {
+		return fmt.Errorf("cost: check tenant quota: %w", err)
+	}
+	if check.Allowed {
+		return nil
+	}
+
+	return &BudgetExceededError{
+		Scope: ScopeTenant,
+		ID:    labels.TenantID,
+		Limit: float64(check.Limit) / 1_000_000,
+		Spent: float64(check.Current) / 1_000_000,
+	}
+}
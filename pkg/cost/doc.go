@@ -0,0 +1,45 @@
+// Package cost 提供模型调用的费用核算和预算控制。
+//
+// 费用根据响应消息的 UsageMetadata 和带版本号的价格表计算，
+// 按运行（run）、线程（thread）和租户（tenant）三个范围累计。
+//
+// # 核心组件
+//
+//   - PriceTable: 按提供商和模型组织的价格表（输入、输出、缓存命中单价），可从 JSON 加载
+//   - Tracker: 累计费用，并通过 Sink 写入租户配额、Prometheus 指标或账单存储
+//   - MeteredModel: 计费的 ChatModel 包装器，调用前检查预算
+//   - Budget: 费用上限，超出后失败（ErrBudgetExceeded）或降级到更便宜的模型
+//
+// # 使用示例
+//
+//	tracker := cost.NewTracker(cost.DefaultPriceTable(),
+//	    cost.NewTenantSink(tenantManager),   // 写入 tenant.ResourceTypeToken / ResourceTypeCost
+//	    cost.NewMetricsSink(metrics),        // 写入 llm_tokens_total / llm_cost_usd_total
+//	)
+//
+//	model := cost.NewMeteredModel(gpt4o, tracker,
+//	    cost.WithBudget(cost.Budget{Scope: cost.ScopeRun, Limit: 0.50}),
+//	    cost.WithBudget(cost.Budget{Scope: cost.ScopeThread, Limit: 5, Downgrade: gpt4oMini}),
+//	    cost.WithTenantQuota(tenantManager, nil),
+//	)
+//
+//	ctx = tenant.WithTenantID(ctx, "tenant-123")
+//	ctx = cost.WithThreadID(ctx, "thread-1")
+//	ctx = cost.WithRunID(ctx, "run-42")
+//
+//	response, err := model.Invoke(ctx, messages)
+//	fmt.Println(response.Metadata[cost.MetadataCost])
+//
+//	// 每月导出各租户费用
+//	for tenantID, spend := range tracker.Snapshot(cost.ScopeTenant) {
+//	    fmt.Printf("%s: $%.2f (%d calls)\n", tenantID, spend.Cost, spend.Calls)
+//	}
+//
+// # 计费规则
+//
+//   - 输入 token 中命中缓存的部分（CachedTokens）按 CachedInput 单价计费
+//   - 输入 token 中写入缓存的部分（CacheCreationTokens）按 CacheWrite 单价计费
+//   - 输出 token 包含推理 token，按 Output 单价计费
+//   - 价格表中没有的模型费用为 0，计入 Spend.UnpricedCalls
+//
+package cost
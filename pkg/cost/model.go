@@ -0,0 +1,374 @@
+package cost
+
+import (
+	"context"
+	"errors"
+
+	"github.com/zhucl121/langchain-go/core/chat"
+	"github.com/zhucl121/langchain-go/core/runnable"
+	"github.com/zhucl121/langchain-go/pkg/enterprise/tenant"
+	"github.com/zhucl121/langchain-go/pkg/observability"
+	"github.com/zhucl121/langchain-go/pkg/types"
+)
+
+// 响应消息 Metadata 中的键
+const (
+	// MetadataCost 本次调用的费用（美元，float64）
+	MetadataCost = "cost"
+
+	// MetadataDowngradedFrom 因预算降级时，原模型的名称
+	MetadataDowngradedFrom = "cost_downgraded_from"
+)
+
+// MeteredModel 是计费的 ChatModel 包装器。
+//
+// 每次调用结束后，根据响应的 UsageMetadata 计算费用并记录到 Tracker；
+// 调用前检查预算，超出时失败或降级到更便宜的模型。
+//
+// 费用计入上下文中的运行（WithRunID，或 runnable.Options 中的 Config.RunID）、
+// 线程（WithThreadID）和租户（tenant.WithTenantID）。
+//
+// 注意：
+//   - 预算在调用前检查，单次调用可能使累计费用略微超过上限
+//   - Sink 的错误不影响调用结果，交给 WithErrorHandler 设置的处理函数（默认写入日志）
+//
+type MeteredModel struct {
+	model   chat.ChatModel
+	tracker *Tracker
+	budgets []Budget
+	quota   *tenantQuota
+	onError func(record Record, err error)
+}
+
+// Option 是 MeteredModel 的配置选项
+type Option func(*MeteredModel)
+
+// WithBudget 添加预算。
+//
+// 参数：
+//   - budget: 预算
+//
+// 返回：
+//   - Option: 配置选项
+//
+func WithBudget(budget Budget) Option {
+	return func(m *MeteredModel) {
+		m.budgets = append(m.budgets, budget)
+	}
+}
+
+// WithTenantQuota 使用租户的费用配额（tenant.Quota.MaxCostMicros）作为预算。
+//
+// 配合 NewTenantSink 使用，租户本月费用达到配额后失败或降级。
+//
+// 参数：
+//   - manager: 租户管理器
+//   - downgrade: 超出后使用的模型，为 nil 时调用失败
+//
+// 返回：
+//   - Option: 配置选项
+//
+func WithTenantQuota(manager tenant.TenantManager, downgrade chat.ChatModel) Option {
+	return func(m *MeteredModel) {
+		m.quota = &tenantQuota{manager: manager, downgrade: downgrade}
+	}
+}
+
+// WithErrorHandler 设置记录费用时 Sink 返回错误的处理函数（默认写入全局日志）
+func WithErrorHandler(fn func(record Record, err error)) Option {
+	return func(m *MeteredModel) {
+		m.onError = fn
+	}
+}
+
+// NewMeteredModel 创建计费的 ChatModel。
+//
+// 参数：
+//   - model: 被包装的模型
+//   - tracker: 费用追踪器
+//   - opts: 配置选项
+//
+// 返回：
+//   - *MeteredModel: 计费模型
+//
+// 示例：
+//
+//	model := cost.NewMeteredModel(gpt4o, tracker,
+//	    cost.WithBudget(cost.Budget{Scope: cost.ScopeRun, Limit: 0.50}),
+//	    cost.WithBudget(cost.Budget{Scope: cost.ScopeThread, Limit: 5, Downgrade: gpt4oMini}),
+//	    cost.WithTenantQuota(tenantManager, nil),
+//	)
+//
+//	response, err := model.Invoke(cost.WithRunID(ctx, runID), messages)
+//	if errors.Is(err, cost.ErrBudgetExceeded) {
+//	    // 预算已用完
+//	}
+//
+func NewMeteredModel(model chat.ChatModel, tracker *Tracker, opts ...Option) *MeteredModel {
+	m := &MeteredModel{
+		model:   model,
+		tracker: tracker,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	if m.onError == nil {
+		m.onError = func(record Record, err error) {
+			observability.Error("failed to record cost",
+				observability.String("provider", record.Provider),
+				observability.String("model", record.Model),
+				observability.Err(err))
+		}
+	}
+	return m
+}
+
+// Tracker 返回费用追踪器。
+func (m *MeteredModel) Tracker() *Tracker {
+	return m.tracker
+}
+
+// Invoke 实现 ChatModel 接口
+func (m *MeteredModel) Invoke(ctx context.Context, messages []types.Message, opts ...runnable.Option) (types.Message, error) {
+	ctx = contextWithRunID(ctx, opts)
+
+	model, downgraded, err := m.selectModel(ctx)
+	if err != nil {
+		return types.Message{}, err
+	}
+
+	result, err := model.Invoke(ctx, messages, opts...)
+	if err != nil {
+		return result, err
+	}
+
+	return m.record(ctx, model, downgraded, result), nil
+}
+
+// Batch 实现 ChatModel 接口
+//
+// 预算在整批调用前检查一次。
+//
+func (m *MeteredModel) Batch(ctx context.Context, inputs [][]types.Message, opts ...runnable.Option) ([]types.Message, error) {
+	ctx = contextWithRunID(ctx, opts)
+
+	model, downgraded, err := m.selectModel(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := model.Batch(ctx, inputs, opts...)
+	if err != nil {
+		return results, err
+	}
+
+	for i := range results {
+		results[i] = m.record(ctx, model, downgraded, results[i])
+	}
+	return results, nil
+}
+
+// Stream 实现 ChatModel 接口
+//
+// 费用在 EventEnd 时记录，EventEnd 中的完整消息带有费用元数据。
+// 流在 EventEnd 之前结束（出错）时，按流中最近一次出现的用量记录。
+// ctx 取消后不再发送事件，但会读完上游的流并照常记录费用。
+//
+func (m *MeteredModel) Stream(ctx context.Context, messages []types.Message, opts ...runnable.Option) (<-chan runnable.StreamEvent[types.Message], error) {
+	ctx = contextWithRunID(ctx, opts)
+
+	model, downgraded, err := m.selectModel(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := model.Stream(ctx, messages, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan runnable.StreamEvent[types.Message])
+
+	go func() {
+		defer close(out)
+
+		// 调用方取消后仍需记录费用
+		recordCtx := context.WithoutCancel(ctx)
+
+		// usage 是流中最近一次出现的用量，没有收到 EventEnd 时按它记录
+		var usage *types.UsageMetadata
+		recorded, forward := false, true
+		for event := range stream {
+			switch event.Type {
+			case runnable.EventStream:
+				if event.Data.UsageMetadata != nil {
+					usage = event.Data.UsageMetadata
+				}
+			case runnable.EventEnd:
+				event.Data = m.record(recordCtx, model, downgraded, event.Data)
+				recorded = true
+			}
+
+			if !forward {
+				continue
+			}
+			select {
+			case out <- event:
+			case <-ctx.Done():
+				// 调用方不再读取，继续读完上游，让生产者退出并记录费用
+				forward = false
+			}
+		}
+
+		if !recorded && usage != nil {
+			m.record(recordCtx, model, downgraded, types.Message{UsageMetadata: usage})
+		}
+	}()
+
+	return out, nil
+}
+
+// selectModel 检查预算，返回本次调用使用的模型以及是否降级
+func (m *MeteredModel) selectModel(ctx context.Context) (chat.ChatModel, bool, error) {
+	labels := LabelsFromContext(ctx)
+
+	var downgrade chat.ChatModel
+	for _, budget := range m.budgets {
+		if err := budget.check(m.tracker, labels); err != nil {
+			if budget.Downgrade == nil {
+				return nil, false, err
+			}
+			if downgrade == nil {
+				downgrade = budget.Downgrade
+			}
+		}
+	}
+
+	if m.quota != nil {
+		if err := m.quota.check(ctx, labels); err != nil {
+			if m.quota.downgrade == nil || !errors.Is(err, ErrBudgetExceeded) {
+				return nil, false, err
+			}
+			if downgrade == nil {
+				downgrade = m.quota.downgrade
+			}
+		}
+	}
+
+	if downgrade != nil {
+		return downgrade, true, nil
+	}
+	return m.model, false, nil
+}
+
+// record 记录一次调用的费用，并将费用写入响应的元数据
+func (m *MeteredModel) record(ctx context.Context, model chat.ChatModel, downgraded bool, message types.Message) types.Message {
+	record, err := m.tracker.Record(ctx, model.GetProvider(), model.GetModelName(), message.UsageMetadata)
+	if err != nil {
+		m.onError(record, err)
+	}
+
+	metadata := make(map[string]any, len(message.Metadata)+2)
+	for k, v := range message.Metadata {
+		metadata[k] = v
+	}
+	metadata[MetadataCost] = record.Cost
+	if downgraded {
+		metadata[MetadataDowngradedFrom] = m.model.GetModelName()
+	}
+	message.Metadata = metadata
+
+	return message
+}
+
+// contextWithRunID 上下文中没有运行 ID 时，使用选项中的 Config.RunID
+func contextWithRunID(ctx context.Context, opts []runnable.Option) context.Context {
+	if id, _ := ctx.Value(runIDKey).(string); id != "" || len(opts) == 0 {
+		return ctx
+	}
+
+	if config := runnable.NewOptions(opts...).Config; config != nil && config.RunID != "" {
+		return WithRunID(ctx, config.RunID)
+	}
+	return ctx
+}
+
+// with 使用新的底层模型和降级模型创建包装器
+func (m *MeteredModel) with(transform func(chat.ChatModel) chat.ChatModel) *MeteredModel {
+	budgets := make([]Budget, len(m.budgets))
+	for i, budget := range m.budgets {
+		if budget.Downgrade != nil {
+			budget.Downgrade = transform(budget.Downgrade)
+		}
+		budgets[i] = budget
+	}
+
+	var quota *tenantQuota
+	if m.quota != nil {
+		q := *m.quota
+		if q.downgrade != nil {
+			q.downgrade = transform(q.downgrade)
+		}
+		quota = &q
+	}
+
+	return &MeteredModel{
+		model:   transform(m.model),
+		tracker: m.tracker,
+		budgets: budgets,
+		quota:   quota,
+		onError: m.onError,
+	}
+}
+
+// BindTools 实现 ChatModel 接口
+//
+// 工具同时绑定到降级模型，降级后的调用仍可使用工具。
+//
+func (m *MeteredModel) BindTools(tools []types.Tool) chat.ChatModel {
+	return m.with(func(model chat.ChatModel) chat.ChatModel {
+		return model.BindTools(tools)
+	})
+}
+
+// WithStructuredOutput 实现 ChatModel 接口
+func (m *MeteredModel) WithStructuredOutput(schema types.Schema) chat.ChatModel {
+	return m.with(func(model chat.ChatModel) chat.ChatModel {
+		return model.WithStructuredOutput(schema)
+	})
+}
+
+// GetModelName 实现 ChatModel 接口
+func (m *MeteredModel) GetModelName() string {
+	return m.model.GetModelName()
+}
+
+// GetProvider 实现 ChatModel 接口
+func (m *MeteredModel) GetProvider() string {
+	return m.model.GetProvider()
+}
+
+// GetName 实现 Runnable 接口
+func (m *MeteredModel) GetName() string {
+	return m.model.GetName()
+}
+
+// WithConfig 实现 Runnable 接口
+func (m *MeteredModel) WithConfig(config *types.Config) runnable.Runnable[[]types.Message, types.Message] {
+	return m.with(func(model chat.ChatModel) chat.ChatModel {
+		if configured, ok := model.WithConfig(config).(chat.ChatModel); ok {
+			return configured
+		}
+		return model
+	})
+}
+
+// WithRetry 实现 Runnable 接口
+func (m *MeteredModel) WithRetry(policy types.RetryPolicy) runnable.Runnable[[]types.Message, types.Message] {
+	return runnable.NewRetryRunnable[[]types.Message, types.Message](m, policy)
+}
+
+// WithFallbacks 实现 Runnable 接口
+func (m *MeteredModel) WithFallbacks(fallbacks ...runnable.Runnable[[]types.Message, types.Message]) runnable.Runnable[[]types.Message, types.Message] {
+	return runnable.NewFallbackRunnable[[]types.Message, types.Message](m, fallbacks)
+}
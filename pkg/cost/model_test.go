@@ -0,0 +1,298 @@
+package cost
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zhucl121/langchain-go/core/chat"
+	"github.com/zhucl121/langchain-go/core/runnable"
+	"github.com/zhucl121/langchain-go/pkg/enterprise/tenant"
+	"github.com/zhucl121/langchain-go/pkg/types"
+)
+
+// fakeModel 返回固定用量的模型
+type fakeModel struct {
+	name  string
+	usage *types.UsageMetadata
+	tools []types.Tool
+	calls int
+}
+
+func (m *fakeModel) Invoke(ctx context.Context, messages []types.Message, opts ...runnable.Option) (types.Message, error) {
+	m.calls++
+	return types.Message{Role: types.RoleAssistant, Content: m.name, UsageMetadata: m.usage}, nil
+}
+
+func (m *fakeModel) Batch(ctx context.Context, inputs [][]types.Message, opts ...runnable.Option) ([]types.Message, error) {
+	results := make([]types.Message, len(inputs))
+	for i, input := range inputs {
+		results[i], _ = m.Invoke(ctx, input, opts...)
+	}
+	return results, nil
+}
+
+func (m *fakeModel) Stream(ctx context.Context, messages []types.Message, opts ...runnable.Option) (<-chan runnable.StreamEvent[types.Message], error) {
+	out := make(chan runnable.StreamEvent[types.Message], 3)
+	result, _ := m.Invoke(ctx, messages, opts...)
+	out <- runnable.StreamEvent[types.Message]{Type: runnable.EventStart}
+	out <- runnable.StreamEvent[types.Message]{Type: runnable.EventStream, Data: types.Message{Content: result.Content}}
+	out <- runnable.StreamEvent[types.Message]{Type: runnable.EventEnd, Data: result}
+	close(out)
+	return out, nil
+}
+
+func (m *fakeModel) BindTools(tools []types.Tool) chat.ChatModel {
+	bound := *m
+	bound.tools = tools
+	return &bound
+}
+
+func (m *fakeModel) WithStructuredOutput(schema types.Schema) chat.ChatModel { return m }
+func (m *fakeModel) GetModelName() string                                    { return m.name }
+func (m *fakeModel) GetProvider() string                                     { return "openai" }
+func (m *fakeModel) GetName() string                                         { return m.name }
+
+func (m *fakeModel) WithConfig(config *types.Config) runnable.Runnable[[]types.Message, types.Message] {
+	return m
+}
+
+func (m *fakeModel) WithRetry(policy types.RetryPolicy) runnable.Runnable[[]types.Message, types.Message] {
+	return runnable.NewRetryRunnable[[]types.Message, types.Message](m, policy)
+}
+
+func (m *fakeModel) WithFallbacks(fallbacks ...runnable.Runnable[[]types.Message, types.Message]) runnable.Runnable[[]types.Message, types.Message] {
+	return runnable.NewFallbackRunnable[[]types.Message, types.Message](m, fallbacks)
+}
+
+func testPrices() *PriceTable {
+	return NewPriceTable("test").
+		Set("openai", "big", Price{Input: 10, Output: 10}).
+		Set("openai", "small", Price{Input: 1, Output: 1})
+}
+
+var messages = []types.Message{types.NewUserMessage("hi")}
+
+func TestMeteredModel_Invoke(t *testing.T) {
+	tracker := NewTracker(testPrices())
+	model := NewMeteredModel(&fakeModel{name: "big", usage: types.NewUsageMetadata(50_000, 50_000)}, tracker)
+
+	ctx := WithThreadID(context.Background(), "thread-1")
+	result, err := model.Invoke(ctx, messages, runnable.WithConfig(types.NewConfig().WithRunID("run-1")))
+	if err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+
+	if cost, _ := result.Metadata[MetadataCost].(float64); !almostEqual(cost, 1) {
+		t.Errorf("cost metadata = %v", result.Metadata[MetadataCost])
+	}
+	if spend := tracker.Spend(ScopeRun, "run-1"); !almostEqual(spend.Cost, 1) {
+		t.Errorf("run spend from options RunID: %+v", spend)
+	}
+	if spend := tracker.Spend(ScopeThread, "thread-1"); spend.Calls != 1 {
+		t.Errorf("thread spend: %+v", spend)
+	}
+}
+
+func TestMeteredModel_BudgetFail(t *testing.T) {
+	tracker := NewTracker(testPrices())
+	inner := &fakeModel{name: "big", usage: types.NewUsageMetadata(50_000, 50_000)}
+	model := NewMeteredModel(inner, tracker, WithBudget(Budget{Scope: ScopeRun, Limit: 1.5}))
+
+	ctx := WithRunID(context.Background(), "run-1")
+	for i := 0; i < 2; i++ {
+		if _, err := model.Invoke(ctx, messages); err != nil {
+			t.Fatalf("call %d failed: %v", i, err)
+		}
+	}
+
+	_, err := model.Invoke(ctx, messages)
+	if !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("expected ErrBudgetExceeded, got %v", err)
+	}
+	var exceeded *BudgetExceededError
+	if !errors.As(err, &exceeded) || exceeded.Scope != ScopeRun || exceeded.ID != "run-1" || !almostEqual(exceeded.Spent, 2) {
+		t.Errorf("unexpected error: %#v", err)
+	}
+	if inner.calls != 2 {
+		t.Errorf("expected the model not to be called after the budget is exceeded, got %d calls", inner.calls)
+	}
+
+	// 其他运行不受影响
+	if _, err := model.Invoke(WithRunID(context.Background(), "run-2"), messages); err != nil {
+		t.Errorf("run-2 failed: %v", err)
+	}
+
+	if _, err := model.Stream(ctx, messages); !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("expected Stream to fail, got %v", err)
+	}
+}
+
+func TestMeteredModel_BudgetDowngrade(t *testing.T) {
+	tracker := NewTracker(testPrices())
+	small := &fakeModel{name: "small", usage: types.NewUsageMetadata(50_000, 50_000)}
+	model := NewMeteredModel(&fakeModel{name: "big", usage: types.NewUsageMetadata(50_000, 50_000)}, tracker,
+		WithBudget(Budget{Scope: ScopeThread, Limit: 1, Downgrade: small}),
+	).BindTools([]types.Tool{{Name: "search"}})
+
+	ctx := WithThreadID(context.Background(), "thread-1")
+	first, _ := model.Invoke(ctx, messages)
+	if first.Content != "big" {
+		t.Errorf("first call used %q", first.Content)
+	}
+
+	stream, err := model.Stream(ctx, messages)
+	if err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+	var end types.Message
+	for event := range stream {
+		if event.Type == runnable.EventEnd {
+			end = event.Data
+		}
+	}
+
+	if end.Content != "small" || end.Metadata[MetadataDowngradedFrom] != "big" {
+		t.Errorf("expected downgrade, got %+v", end)
+	}
+	if cost, _ := end.Metadata[MetadataCost].(float64); !almostEqual(cost, 0.1) {
+		t.Errorf("downgraded cost = %v", end.Metadata[MetadataCost])
+	}
+	if spend := tracker.Spend(ScopeThread, "thread-1"); !almostEqual(spend.Cost, 1.1) || spend.Calls != 2 {
+		t.Errorf("thread spend: %+v", spend)
+	}
+	// 工具同时绑定到降级模型
+	downgrade := model.(*MeteredModel).budgets[0].Downgrade.(*fakeModel)
+	if len(downgrade.tools) != 1 {
+		t.Errorf("expected tools bound to the downgrade model, got %d", len(downgrade.tools))
+	}
+}
+
+func TestMeteredModel_TenantQuota(t *testing.T) {
+	ctx := context.Background()
+	manager := tenant.NewDefaultTenantManager(tenant.NewMemoryStore())
+	quota := tenant.DefaultQuota()
+	quota.MaxCostMicros = ToMicros(1)
+	manager.CreateTenant(ctx, &tenant.Tenant{ID: "tenant-1", Name: "测试租户", Quota: quota})
+
+	tracker := NewTracker(testPrices(), NewTenantSink(manager))
+	model := NewMeteredModel(&fakeModel{name: "big", usage: types.NewUsageMetadata(50_000, 50_000)}, tracker,
+		WithTenantQuota(manager, nil))
+
+	ctx = tenant.WithTenantID(ctx, "tenant-1")
+	if _, err := model.Invoke(ctx, messages); err != nil {
+		t.Fatalf("first call failed: %v", err)
+	}
+
+	_, err := model.Invoke(ctx, messages)
+	var exceeded *BudgetExceededError
+	if !errors.As(err, &exceeded) || exceeded.Scope != ScopeTenant || !almostEqual(exceeded.Limit, 1) {
+		t.Errorf("expected tenant budget error, got %v", err)
+	}
+
+	// 未注册的租户返回错误
+	if _, err := model.Invoke(tenant.WithTenantID(context.Background(), "missing"), messages); !errors.Is(err, tenant.ErrTenantNotFound) {
+		t.Errorf("expected ErrTenantNotFound, got %v", err)
+	}
+}
+
+// streamModel 由测试控制流式事件的模型
+type streamModel struct {
+	fakeModel
+	events chan runnable.StreamEvent[types.Message]
+}
+
+func (m *streamModel) Stream(ctx context.Context, messages []types.Message, opts ...runnable.Option) (<-chan runnable.StreamEvent[types.Message], error) {
+	return m.events, nil
+}
+
+func TestMeteredModel_StreamCancelled(t *testing.T) {
+	tracker := NewTracker(testPrices())
+	inner := &streamModel{fakeModel: fakeModel{name: "big"}, events: make(chan runnable.StreamEvent[types.Message])}
+	model := NewMeteredModel(inner, tracker)
+
+	// 上游在调用方放弃读取后才结束
+	proceed, produced := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(produced)
+		defer close(inner.events)
+		inner.events <- runnable.StreamEvent[types.Message]{Type: runnable.EventStart}
+		<-proceed
+		inner.events <- runnable.StreamEvent[types.Message]{Type: runnable.EventStream, Data: types.Message{Content: "big"}}
+		inner.events <- runnable.StreamEvent[types.Message]{
+			Type: runnable.EventEnd,
+			Data: types.Message{Content: "big", UsageMetadata: types.NewUsageMetadata(50_000, 50_000)},
+		}
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := model.Stream(ctx, messages)
+	if err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+	<-stream
+	cancel()
+	close(proceed)
+
+	select {
+	case <-produced:
+	case <-time.After(time.Second):
+		t.Fatal("upstream stream was not drained")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for tracker.Total().Calls == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if total := tracker.Total(); total.Calls != 1 || !almostEqual(total.Cost, 1) {
+		t.Errorf("expected cancelled stream to be recorded, got %+v", total)
+	}
+}
+
+func TestMeteredModel_StreamPartialUsage(t *testing.T) {
+	tracker := NewTracker(testPrices())
+	inner := &streamModel{fakeModel: fakeModel{name: "big"}, events: make(chan runnable.StreamEvent[types.Message], 3)}
+	inner.events <- runnable.StreamEvent[types.Message]{Type: runnable.EventStart}
+	inner.events <- runnable.StreamEvent[types.Message]{
+		Type: runnable.EventStream,
+		Data: types.Message{Content: "b", UsageMetadata: types.NewUsageMetadata(50_000, 0)},
+	}
+	inner.events <- runnable.StreamEvent[types.Message]{Type: runnable.EventError, Error: errors.New("connection reset")}
+	close(inner.events)
+
+	stream, err := NewMeteredModel(inner, tracker).Stream(context.Background(), messages)
+	if err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+	for range stream {
+	}
+
+	if total := tracker.Total(); total.Calls != 1 || !almostEqual(total.Cost, 0.5) {
+		t.Errorf("expected partial usage to be recorded, got %+v", total)
+	}
+}
+
+func TestMeteredModel_ErrorHandler(t *testing.T) {
+	sinkErr := errors.New("sink unavailable")
+	tracker := NewTracker(testPrices(), SinkFunc(func(ctx context.Context, record Record) error {
+		return sinkErr
+	}))
+
+	var handled []error
+	model := NewMeteredModel(&fakeModel{name: "big", usage: types.NewUsageMetadata(50_000, 50_000)}, tracker,
+		WithErrorHandler(func(record Record, err error) {
+			if record.Model != "big" {
+				t.Errorf("unexpected record: %+v", record)
+			}
+			handled = append(handled, err)
+		}),
+	).BindTools([]types.Tool{{Name: "search"}})
+
+	if _, err := model.Invoke(context.Background(), messages); err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+	if len(handled) != 1 || !errors.Is(handled[0], sinkErr) {
+		t.Errorf("expected sink error to be handled, got %v", handled)
+	}
+}
@@ -0,0 +1,242 @@
+package cost
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/zhucl121/langchain-go/pkg/types"
+)
+
+// Price 是一个模型的单价，单位为美元 / 百万 token。
+type Price struct {
+	// Input 输入 token 单价
+	Input float64 `json:"input"`
+
+	// Output 输出 token 单价（包含推理 token）
+	Output float64 `json:"output"`
+
+	// CachedInput 命中提示词缓存的输入 token 单价，0 表示按 Input 计费
+	CachedInput float64 `json:"cached_input,omitempty"`
+
+	// CacheWrite 写入提示词缓存的输入 token 单价，0 表示按 Input 计费
+	CacheWrite float64 `json:"cache_write,omitempty"`
+}
+
+// Cost 计算一次调用的费用（美元）。
+//
+// 参数：
+//   - usage: token 用量，为 nil 时返回 0
+//
+// 返回：
+//   - float64: 费用
+//
+func (p Price) Cost(usage *types.UsageMetadata) float64 {
+	if usage == nil {
+		return 0
+	}
+
+	cached := usage.CachedTokens
+	if cached > usage.InputTokens {
+		cached = usage.InputTokens
+	}
+	cachedRate := p.CachedInput
+	if cachedRate == 0 {
+		cachedRate = p.Input
+	}
+
+	written := usage.CacheCreationTokens
+	if written > usage.InputTokens-cached {
+		written = usage.InputTokens - cached
+	}
+	writeRate := p.CacheWrite
+	if writeRate == 0 {
+		writeRate = p.Input
+	}
+
+	total := float64(usage.InputTokens-cached-written)*p.Input +
+		float64(cached)*cachedRate +
+		float64(written)*writeRate +
+		float64(usage.OutputTokens)*p.Output
+
+	return total / 1_000_000
+}
+
+// PriceTable 是带版本号的价格表，按提供商和模型组织。
+//
+// 模型名称按以下顺序匹配：
+//   - 完全相同的名称
+//   - 最长的前缀（"gpt-4o" 匹配 "gpt-4o-2024-08-06"，Bedrock 的
+//     "anthropic.claude-3-haiku" 匹配 "anthropic.claude-3-haiku-20240307-v1:0"）
+//   - 提供商的通配价格 "*"（如本地运行的 Ollama 模型统一为 0）
+//
+// 价格表并发安全。每条费用记录都带有计价时的版本号，便于对账。
+//
+type PriceTable struct {
+	mu      sync.RWMutex
+	version string
+	prices  map[string]map[string]Price
+}
+
+// NewPriceTable 创建空的价格表。
+//
+// 参数：
+//   - version: 版本号（如 "2025-01"）
+//
+// 返回：
+//   - *PriceTable: 价格表
+//
+func NewPriceTable(version string) *PriceTable {
+	return &PriceTable{
+		version: version,
+		prices:  make(map[string]map[string]Price),
+	}
+}
+
+// Version 返回价格表版本。
+func (t *PriceTable) Version() string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.version
+}
+
+// Set 设置模型的价格。
+//
+// 参数：
+//   - provider: 提供商名称（与 ChatModel.GetProvider 一致）
+//   - model: 模型名称、名称前缀或 "*"
+//   - price: 单价
+//
+// 返回：
+//   - *PriceTable: 价格表本身，便于链式调用
+//
+func (t *PriceTable) Set(provider, model string, price Price) *PriceTable {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.prices[provider] == nil {
+		t.prices[provider] = make(map[string]Price)
+	}
+	t.prices[provider][model] = price
+	return t
+}
+
+// Lookup 查找模型的价格。
+//
+// 参数：
+//   - provider: 提供商名称
+//   - model: 模型名称
+//
+// 返回：
+//   - Price: 单价
+//   - bool: 是否找到
+//
+func (t *PriceTable) Lookup(provider, model string) (Price, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	models := t.prices[provider]
+	if price, ok := models[model]; ok {
+		return price, true
+	}
+
+	best := ""
+	for prefix := range models {
+		if prefix != "*" && strings.HasPrefix(model, prefix) && len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+	if best != "" {
+		return models[best], true
+	}
+
+	price, ok := models["*"]
+	return price, ok
+}
+
+// priceTableJSON 是价格表的 JSON 格式
+type priceTableJSON struct {
+	Version string                      `json:"version"`
+	Prices  map[string]map[string]Price `json:"prices"`
+}
+
+// MarshalJSON 实现 json.Marshaler 接口。
+func (t *PriceTable) MarshalJSON() ([]byte, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return json.Marshal(priceTableJSON{Version: t.version, Prices: t.prices})
+}
+
+// LoadPriceTable 从 JSON 读取价格表。
+//
+// 价格调整时只需更新配置文件并提升版本号，无需重新发布代码。
+//
+// 参数：
+//   - r: JSON 数据，格式为 {"version": "...", "prices": {"openai": {"gpt-4o": {"input": 2.5, ...}}}}
+//
+// 返回：
+//   - *PriceTable: 价格表
+//   - error: 解析错误
+//
+func LoadPriceTable(r io.Reader) (*PriceTable, error) {
+	var data priceTableJSON
+	if err := json.NewDecoder(r).Decode(&data); err != nil {
+		return nil, fmt.Errorf("cost: invalid price table: %w", err)
+	}
+	if data.Version == "" {
+		return nil, fmt.Errorf("cost: price table version is required")
+	}
+
+	table := NewPriceTable(data.Version)
+	for provider, models := range data.Prices {
+		for model, price := range models {
+			table.Set(provider, model, price)
+		}
+	}
+	return table, nil
+}
+
+// DefaultPriceVersion 是内置价格表的版本
+const DefaultPriceVersion = "2025-01"
+
+// DefaultPriceTable 返回内置的公开价格表（美元 / 百万 token）。
+//
+// 注意：
+//   - 内置价格仅供参考，以提供商官网和账单为准
+//   - Claude 模型的缓存写入单价为输入单价的 1.25 倍（5 分钟缓存）
+//   - Azure 的模型名称是部署名，需要按部署自行设置价格
+//   - Gemini 使用 128K 上下文以内的价格
+//
+func DefaultPriceTable() *PriceTable {
+	return NewPriceTable(DefaultPriceVersion).
+		// OpenAI
+		Set("openai", "gpt-4o", Price{Input: 2.50, Output: 10.00, CachedInput: 1.25}).
+		Set("openai", "gpt-4o-mini", Price{Input: 0.15, Output: 0.60, CachedInput: 0.075}).
+		Set("openai", "gpt-4-turbo", Price{Input: 10.00, Output: 30.00}).
+		Set("openai", "gpt-4", Price{Input: 30.00, Output: 60.00}).
+		Set("openai", "gpt-3.5-turbo", Price{Input: 0.50, Output: 1.50}).
+		Set("openai", "o1", Price{Input: 15.00, Output: 60.00, CachedInput: 7.50}).
+		Set("openai", "o1-mini", Price{Input: 1.10, Output: 4.40, CachedInput: 0.55}).
+		Set("openai", "o3-mini", Price{Input: 1.10, Output: 4.40, CachedInput: 0.55}).
+		// Anthropic
+		Set("anthropic", "claude-3-5-sonnet", Price{Input: 3.00, Output: 15.00, CachedInput: 0.30, CacheWrite: 3.75}).
+		Set("anthropic", "claude-3-5-haiku", Price{Input: 0.80, Output: 4.00, CachedInput: 0.08, CacheWrite: 1.00}).
+		Set("anthropic", "claude-3-opus", Price{Input: 15.00, Output: 75.00, CachedInput: 1.50, CacheWrite: 18.75}).
+		Set("anthropic", "claude-3-sonnet", Price{Input: 3.00, Output: 15.00, CachedInput: 0.30, CacheWrite: 3.75}).
+		Set("anthropic", "claude-3-haiku", Price{Input: 0.25, Output: 1.25, CachedInput: 0.03, CacheWrite: 0.3125}).
+		// Google Gemini
+		Set("gemini", "gemini-2.0-flash", Price{Input: 0.10, Output: 0.40, CachedInput: 0.025}).
+		Set("gemini", "gemini-1.5-pro", Price{Input: 1.25, Output: 5.00, CachedInput: 0.3125}).
+		Set("gemini", "gemini-1.5-flash", Price{Input: 0.075, Output: 0.30, CachedInput: 0.01875}).
+		// AWS Bedrock（按需计费）
+		Set("bedrock", "anthropic.claude-3-5-sonnet", Price{Input: 3.00, Output: 15.00, CachedInput: 0.30, CacheWrite: 3.75}).
+		Set("bedrock", "anthropic.claude-3-haiku", Price{Input: 0.25, Output: 1.25, CacheWrite: 0.3125}).
+		Set("bedrock", "anthropic.claude-3-opus", Price{Input: 15.00, Output: 75.00, CacheWrite: 18.75}).
+		Set("bedrock", "meta.llama3-1-70b-instruct", Price{Input: 0.72, Output: 0.72}).
+		Set("bedrock", "meta.llama3-1-8b-instruct", Price{Input: 0.22, Output: 0.22}).
+		Set("bedrock", "amazon.titan-text-express", Price{Input: 0.20, Output: 0.60}).
+		// 本地模型不计费
+		Set("ollama", "*", Price{})
+}
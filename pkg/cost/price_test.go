@@ -0,0 +1,120 @@
+package cost
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/zhucl121/langchain-go/pkg/types"
+)
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-12
+}
+
+func TestPrice_Cost(t *testing.T) {
+	price := Price{Input: 2.50, Output: 10.00, CachedInput: 1.25}
+
+	usage := &types.UsageMetadata{InputTokens: 1000, OutputTokens: 500, TotalTokens: 1500, CachedTokens: 400}
+	want := (600*2.50 + 400*1.25 + 500*10.00) / 1_000_000
+	if got := price.Cost(usage); !almostEqual(got, want) {
+		t.Errorf("Cost = %v, want %v", got, want)
+	}
+
+	// 未设置缓存单价时按输入单价计费
+	price.CachedInput = 0
+	want = (1000*2.50 + 500*10.00) / 1_000_000
+	if got := price.Cost(usage); !almostEqual(got, want) {
+		t.Errorf("Cost without cached rate = %v, want %v", got, want)
+	}
+
+	if got := price.Cost(nil); got != 0 {
+		t.Errorf("Cost(nil) = %v", got)
+	}
+}
+
+func TestPrice_Cost_CacheWrite(t *testing.T) {
+	price := Price{Input: 3.00, Output: 15.00, CachedInput: 0.30, CacheWrite: 3.75}
+
+	usage := &types.UsageMetadata{InputTokens: 1000, OutputTokens: 100, CachedTokens: 200, CacheCreationTokens: 500}
+	want := (300*3.00 + 200*0.30 + 500*3.75 + 100*15.00) / 1_000_000
+	if got := price.Cost(usage); !almostEqual(got, want) {
+		t.Errorf("Cost = %v, want %v", got, want)
+	}
+
+	// 未设置缓存写入单价时按输入单价计费
+	price.CacheWrite = 0
+	want = (800*3.00 + 200*0.30 + 100*15.00) / 1_000_000
+	if got := price.Cost(usage); !almostEqual(got, want) {
+		t.Errorf("Cost without cache write rate = %v, want %v", got, want)
+	}
+
+	// 默认价格表中 Claude 模型的缓存写入单价为输入单价的 1.25 倍
+	table := DefaultPriceTable()
+	for _, key := range [][2]string{
+		{"anthropic", "claude-3-5-sonnet-20241022"},
+		{"anthropic", "claude-3-haiku-20240307"},
+		{"bedrock", "anthropic.claude-3-5-sonnet-20240620-v1:0"},
+		{"bedrock", "anthropic.claude-3-opus-20240229-v1:0"},
+	} {
+		p, ok := table.Lookup(key[0], key[1])
+		if !ok || !almostEqual(p.CacheWrite, p.Input*1.25) {
+			t.Errorf("Lookup(%s, %s) cache write = %v, want %v", key[0], key[1], p.CacheWrite, p.Input*1.25)
+		}
+	}
+}
+
+func TestPriceTable_Lookup(t *testing.T) {
+	table := DefaultPriceTable()
+
+	tests := []struct {
+		provider, model string
+		want            Price
+		found           bool
+	}{
+		{"openai", "gpt-4o", Price{Input: 2.50, Output: 10.00, CachedInput: 1.25}, true},
+		{"openai", "gpt-4o-2024-08-06", Price{Input: 2.50, Output: 10.00, CachedInput: 1.25}, true},
+		{"openai", "gpt-4o-mini-2024-07-18", Price{Input: 0.15, Output: 0.60, CachedInput: 0.075}, true},
+		{"openai", "gpt-4-0613", Price{Input: 30.00, Output: 60.00}, true},
+		{"bedrock", "anthropic.claude-3-haiku-20240307-v1:0", Price{Input: 0.25, Output: 1.25, CacheWrite: 0.3125}, true},
+		{"ollama", "llama3", Price{}, true},
+		{"openai", "unknown-model", Price{}, false},
+		{"unknown", "gpt-4o", Price{}, false},
+	}
+
+	for _, tt := range tests {
+		got, found := table.Lookup(tt.provider, tt.model)
+		if found != tt.found || got != tt.want {
+			t.Errorf("Lookup(%s, %s) = %+v, %v; want %+v, %v", tt.provider, tt.model, got, found, tt.want, tt.found)
+		}
+	}
+}
+
+func TestLoadPriceTable(t *testing.T) {
+	table, err := LoadPriceTable(strings.NewReader(`{
+		"version": "2025-06",
+		"prices": {"azure": {"my-gpt4o": {"input": 2.5, "output": 10, "cached_input": 1.25}}}
+	}`))
+	if err != nil {
+		t.Fatalf("LoadPriceTable failed: %v", err)
+	}
+
+	if table.Version() != "2025-06" {
+		t.Errorf("Version = %q", table.Version())
+	}
+	if price, ok := table.Lookup("azure", "my-gpt4o"); !ok || price.CachedInput != 1.25 {
+		t.Errorf("Lookup = %+v, %v", price, ok)
+	}
+
+	data, err := table.MarshalJSON()
+	if err != nil || !strings.Contains(string(data), `"version":"2025-06"`) {
+		t.Errorf("MarshalJSON = %s, %v", data, err)
+	}
+
+	if _, err := LoadPriceTable(strings.NewReader(`{"prices": {}}`)); err == nil {
+		t.Error("expected error for missing version")
+	}
+	if _, err := LoadPriceTable(strings.NewReader(`not json`)); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
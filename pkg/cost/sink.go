@@ -0,0 +1,61 @@
+package cost
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	"github.com/zhucl121/langchain-go/pkg/enterprise/tenant"
+	"github.com/zhucl121/langchain-go/pkg/observability"
+)
+
+// ToMicros 将美元换算为微美元（租户配额的费用单位），四舍五入。
+func ToMicros(usd float64) int64 {
+	return int64(math.Round(usd * 1_000_000))
+}
+
+// NewTenantSink 创建写入租户用量的 Sink。
+//
+// 每次调用后增加租户本月的 token 用量（tenant.ResourceTypeToken）和
+// 费用（tenant.ResourceTypeCost，微美元），没有租户 ID 的调用被忽略。
+//
+// 参数：
+//   - manager: 租户管理器
+//
+// 返回：
+//   - Sink: 租户用量 Sink
+//
+func NewTenantSink(manager tenant.TenantManager) Sink {
+	return SinkFunc(func(ctx context.Context, record Record) error {
+		if record.TenantID == "" {
+			return nil
+		}
+
+		var errs []error
+		if tokens := record.Usage.TotalTokens; tokens > 0 {
+			errs = append(errs, manager.IncrementUsage(ctx, record.TenantID, tenant.ResourceTypeToken, tokens))
+		}
+		if micros := ToMicros(record.Cost); micros > 0 {
+			errs = append(errs, manager.IncrementUsage(ctx, record.TenantID, tenant.ResourceTypeCost, int(micros)))
+		}
+		return errors.Join(errs...)
+	})
+}
+
+// NewMetricsSink 创建写入 Prometheus 指标的 Sink。
+//
+// 记录 llm_tokens_total 和 llm_cost_usd_total，标签为提供商和模型。
+//
+// 参数：
+//   - metrics: 指标收集器
+//
+// 返回：
+//   - Sink: 指标 Sink
+//
+func NewMetricsSink(metrics *observability.MetricsCollector) Sink {
+	return SinkFunc(func(ctx context.Context, record Record) error {
+		metrics.RecordLLMTokens(record.Provider, record.Model, record.Usage.InputTokens, record.Usage.OutputTokens)
+		metrics.RecordLLMCost(record.Provider, record.Model, record.Cost)
+		return nil
+	})
+}
@@ -0,0 +1,299 @@
+package cost
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/zhucl121/langchain-go/pkg/enterprise/tenant"
+	"github.com/zhucl121/langchain-go/pkg/types"
+)
+
+// Scope 费用的统计范围
+type Scope string
+
+const (
+	// ScopeRun 单次运行（如一次 Agent 执行）
+	ScopeRun Scope = "run"
+
+	// ScopeThread 会话线程（多次运行共享）
+	ScopeThread Scope = "thread"
+
+	// ScopeTenant 租户
+	ScopeTenant Scope = "tenant"
+)
+
+// contextKey 是费用上下文键类型
+type contextKey string
+
+const (
+	runIDKey    contextKey = "cost.run_id"
+	threadIDKey contextKey = "cost.thread_id"
+)
+
+// WithRunID 在上下文中设置运行 ID，之后的模型调用费用计入该运行。
+func WithRunID(ctx context.Context, runID string) context.Context {
+	return context.WithValue(ctx, runIDKey, runID)
+}
+
+// WithThreadID 在上下文中设置线程 ID，之后的模型调用费用计入该线程。
+func WithThreadID(ctx context.Context, threadID string) context.Context {
+	return context.WithValue(ctx, threadIDKey, threadID)
+}
+
+// Labels 是一次调用所属的运行、线程和租户
+type Labels struct {
+	RunID    string `json:"run_id,omitempty"`
+	ThreadID string `json:"thread_id,omitempty"`
+	TenantID string `json:"tenant_id,omitempty"`
+}
+
+// LabelsFromContext 从上下文读取运行、线程和租户 ID。
+//
+// 租户 ID 通过 tenant.WithTenantID 设置。
+//
+func LabelsFromContext(ctx context.Context) Labels {
+	var labels Labels
+	labels.RunID, _ = ctx.Value(runIDKey).(string)
+	labels.ThreadID, _ = ctx.Value(threadIDKey).(string)
+	labels.TenantID, _ = tenant.GetTenantID(ctx)
+	return labels
+}
+
+// id 返回指定范围的 ID
+func (l Labels) id(scope Scope) string {
+	switch scope {
+	case ScopeRun:
+		return l.RunID
+	case ScopeThread:
+		return l.ThreadID
+	case ScopeTenant:
+		return l.TenantID
+	default:
+		return ""
+	}
+}
+
+// Record 是一次模型调用的费用记录
+type Record struct {
+	Labels
+
+	// Provider 提供商
+	Provider string `json:"provider"`
+
+	// Model 模型名称
+	Model string `json:"model"`
+
+	// Usage token 用量
+	Usage types.UsageMetadata `json:"usage"`
+
+	// Cost 费用（美元）
+	Cost float64 `json:"cost"`
+
+	// Priced 价格表中是否有该模型；为 false 时 Cost 为 0
+	Priced bool `json:"priced"`
+
+	// PriceVersion 计价使用的价格表版本
+	PriceVersion string `json:"price_version"`
+
+	// Time 记录时间
+	Time time.Time `json:"time"`
+}
+
+// Spend 是一个范围内累计的费用
+type Spend struct {
+	// Cost 费用（美元）
+	Cost float64 `json:"cost"`
+
+	// Usage 累计 token 用量
+	Usage types.UsageMetadata `json:"usage"`
+
+	// Calls 调用次数
+	Calls int `json:"calls"`
+
+	// UnpricedCalls 价格表中没有对应模型的调用次数
+	UnpricedCalls int `json:"unpriced_calls,omitempty"`
+}
+
+// add 累加一条记录
+func (s *Spend) add(record Record) {
+	s.Cost += record.Cost
+	s.Usage = *s.Usage.Add(&record.Usage)
+	s.Calls++
+	if !record.Priced {
+		s.UnpricedCalls++
+	}
+}
+
+// Sink 接收每条费用记录，用于对接配额、指标或账单存储。
+type Sink interface {
+	// RecordCost 处理一条费用记录
+	RecordCost(ctx context.Context, record Record) error
+}
+
+// SinkFunc 是函数形式的 Sink
+type SinkFunc func(ctx context.Context, record Record) error
+
+// RecordCost 实现 Sink 接口
+func (f SinkFunc) RecordCost(ctx context.Context, record Record) error {
+	return f(ctx, record)
+}
+
+// Tracker 按运行、线程和租户累计模型调用费用。
+//
+// Tracker 并发安全，通常在进程内共享一个实例。累计值保存在内存中，
+// 需要持久化时通过 Sink 写入外部存储。
+//
+// 示例：
+//
+//	tracker := cost.NewTracker(cost.DefaultPriceTable(),
+//	    cost.NewTenantSink(tenantManager),
+//	    cost.NewMetricsSink(metrics),
+//	)
+//	model := cost.NewMeteredModel(openaiModel, tracker)
+//
+//	ctx = tenant.WithTenantID(ctx, "tenant-123")
+//	ctx = cost.WithThreadID(ctx, "thread-1")
+//	response, _ := model.Invoke(ctx, messages)
+//
+//	fmt.Println(tracker.Spend(cost.ScopeTenant, "tenant-123").Cost)
+//
+type Tracker struct {
+	prices *PriceTable
+	sinks  []Sink
+
+	mu     sync.RWMutex
+	total  Spend
+	spends map[Scope]map[string]*Spend
+	now    func() time.Time
+}
+
+// NewTracker 创建费用追踪器。
+//
+// 参数：
+//   - prices: 价格表，为 nil 时使用 DefaultPriceTable
+//   - sinks: 费用记录的接收方（可选）
+//
+// 返回：
+//   - *Tracker: 费用追踪器
+//
+func NewTracker(prices *PriceTable, sinks ...Sink) *Tracker {
+	if prices == nil {
+		prices = DefaultPriceTable()
+	}
+	return &Tracker{
+		prices: prices,
+		sinks:  sinks,
+		spends: map[Scope]map[string]*Spend{
+			ScopeRun:    {},
+			ScopeThread: {},
+			ScopeTenant: {},
+		},
+		now: time.Now,
+	}
+}
+
+// Prices 返回价格表。
+func (t *Tracker) Prices() *PriceTable {
+	return t.prices
+}
+
+// Record 计算一次调用的费用并累计到所属的运行、线程和租户。
+//
+// 累计值总是会更新；Sink 返回的错误合并后返回，不影响其他 Sink。
+//
+// 参数：
+//   - ctx: 上下文，从中读取运行、线程和租户 ID
+//   - provider: 提供商
+//   - model: 模型名称
+//   - usage: token 用量，为 nil 时只计调用次数
+//
+// 返回：
+//   - Record: 费用记录
+//   - error: Sink 错误
+//
+func (t *Tracker) Record(ctx context.Context, provider, model string, usage *types.UsageMetadata) (Record, error) {
+	price, priced := t.prices.Lookup(provider, model)
+
+	record := Record{
+		Labels:       LabelsFromContext(ctx),
+		Provider:     provider,
+		Model:        model,
+		Cost:         price.Cost(usage),
+		Priced:       priced,
+		PriceVersion: t.prices.Version(),
+		Time:         t.now(),
+	}
+	if usage != nil {
+		record.Usage = *usage
+	}
+
+	t.mu.Lock()
+	t.total.add(record)
+	for scope, spends := range t.spends {
+		id := record.id(scope)
+		if id == "" {
+			continue
+		}
+		if spends[id] == nil {
+			spends[id] = &Spend{}
+		}
+		spends[id].add(record)
+	}
+	t.mu.Unlock()
+
+	var errs []error
+	for _, sink := range t.sinks {
+		if err := sink.RecordCost(ctx, record); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return record, errors.Join(errs...)
+}
+
+// Spend 返回指定范围内的累计费用。
+//
+// 参数：
+//   - scope: 统计范围
+//   - id: 运行、线程或租户 ID
+//
+// 返回：
+//   - Spend: 累计费用，没有记录时为零值
+//
+func (t *Tracker) Spend(scope Scope, id string) Spend {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if spend := t.spends[scope][id]; spend != nil {
+		return *spend
+	}
+	return Spend{}
+}
+
+// Total 返回所有调用的累计费用。
+func (t *Tracker) Total() Spend {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.total
+}
+
+// Snapshot 返回指定范围内所有 ID 的累计费用（如每月导出各租户费用）。
+func (t *Tracker) Snapshot(scope Scope) map[string]Spend {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	result := make(map[string]Spend, len(t.spends[scope]))
+	for id, spend := range t.spends[scope] {
+		result[id] = *spend
+	}
+	return result
+}
+
+// Reset 清除指定范围内某个 ID 的累计费用（如运行结束或账期切换）。
+func (t *Tracker) Reset(scope Scope, id string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.spends[scope], id)
+}
@@ -0,0 +1,123 @@
+package cost
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/zhucl121/langchain-go/pkg/enterprise/tenant"
+	"github.com/zhucl121/langchain-go/pkg/observability"
+	"github.com/zhucl121/langchain-go/pkg/types"
+)
+
+func TestTracker_Record(t *testing.T) {
+	tracker := NewTracker(NewPriceTable("test").Set("openai", "gpt-4o", Price{Input: 2, Output: 8}))
+
+	ctx := tenant.WithTenantID(context.Background(), "tenant-1")
+	ctx = WithThreadID(ctx, "thread-1")
+
+	record, err := tracker.Record(WithRunID(ctx, "run-1"), "openai", "gpt-4o", types.NewUsageMetadata(1_000_000, 500_000))
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if !almostEqual(record.Cost, 6) || !record.Priced || record.PriceVersion != "test" {
+		t.Errorf("unexpected record: %+v", record)
+	}
+	if record.RunID != "run-1" || record.ThreadID != "thread-1" || record.TenantID != "tenant-1" {
+		t.Errorf("unexpected labels: %+v", record.Labels)
+	}
+
+	tracker.Record(WithRunID(ctx, "run-2"), "openai", "gpt-4o", types.NewUsageMetadata(500_000, 0))
+	tracker.Record(WithRunID(ctx, "run-2"), "openai", "unknown", types.NewUsageMetadata(100, 100))
+
+	if spend := tracker.Spend(ScopeRun, "run-1"); !almostEqual(spend.Cost, 6) || spend.Calls != 1 {
+		t.Errorf("run-1 spend: %+v", spend)
+	}
+	if spend := tracker.Spend(ScopeRun, "run-2"); !almostEqual(spend.Cost, 1) || spend.Calls != 2 || spend.UnpricedCalls != 1 {
+		t.Errorf("run-2 spend: %+v", spend)
+	}
+	if spend := tracker.Spend(ScopeThread, "thread-1"); !almostEqual(spend.Cost, 7) || spend.Usage.InputTokens != 1_500_100 {
+		t.Errorf("thread spend: %+v", spend)
+	}
+	if spend := tracker.Spend(ScopeTenant, "tenant-1"); !almostEqual(spend.Cost, 7) || spend.Calls != 3 {
+		t.Errorf("tenant spend: %+v", spend)
+	}
+	if total := tracker.Total(); !almostEqual(total.Cost, 7) {
+		t.Errorf("total: %+v", total)
+	}
+
+	if snapshot := tracker.Snapshot(ScopeRun); len(snapshot) != 2 {
+		t.Errorf("snapshot: %+v", snapshot)
+	}
+
+	tracker.Reset(ScopeRun, "run-1")
+	if spend := tracker.Spend(ScopeRun, "run-1"); spend.Calls != 0 {
+		t.Errorf("expected reset spend, got %+v", spend)
+	}
+}
+
+func TestTracker_SinkErrors(t *testing.T) {
+	failing := SinkFunc(func(context.Context, Record) error { return errors.New("sink failed") })
+
+	var received []Record
+	collecting := SinkFunc(func(_ context.Context, record Record) error {
+		received = append(received, record)
+		return nil
+	})
+
+	tracker := NewTracker(nil, failing, collecting)
+	_, err := tracker.Record(context.Background(), "openai", "gpt-4o", types.NewUsageMetadata(10, 10))
+	if err == nil || !strings.Contains(err.Error(), "sink failed") {
+		t.Errorf("expected sink error, got %v", err)
+	}
+	if len(received) != 1 {
+		t.Errorf("expected the other sink to be called, got %d records", len(received))
+	}
+	if tracker.Total().Calls != 1 {
+		t.Error("expected totals to be updated despite sink error")
+	}
+}
+
+func TestTenantSink(t *testing.T) {
+	ctx := context.Background()
+	manager := tenant.NewDefaultTenantManager(tenant.NewMemoryStore())
+	manager.CreateTenant(ctx, &tenant.Tenant{ID: "tenant-1", Name: "测试租户"})
+
+	tracker := NewTracker(NewPriceTable("test").Set("openai", "gpt-4o", Price{Input: 2, Output: 8}), NewTenantSink(manager))
+
+	if _, err := tracker.Record(tenant.WithTenantID(ctx, "tenant-1"), "openai", "gpt-4o", types.NewUsageMetadata(1000, 500)); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	// 没有租户 ID 的调用被忽略
+	if _, err := tracker.Record(ctx, "openai", "gpt-4o", types.NewUsageMetadata(1000, 500)); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	got, _ := manager.GetTenant(ctx, "tenant-1")
+	if got.Usage.TokensThisMonth != 1500 {
+		t.Errorf("TokensThisMonth = %d", got.Usage.TokensThisMonth)
+	}
+	if got.Usage.CostThisMonthMicros != 6000 {
+		t.Errorf("CostThisMonthMicros = %d", got.Usage.CostThisMonthMicros)
+	}
+}
+
+func TestMetricsSink(t *testing.T) {
+	metrics := observability.NewMetricsCollector(observability.MetricsConfig{})
+	tracker := NewTracker(NewPriceTable("test").Set("openai", "gpt-4o", Price{Input: 2, Output: 8}), NewMetricsSink(metrics))
+
+	tracker.Record(context.Background(), "openai", "gpt-4o", types.NewUsageMetadata(1_000_000, 0))
+
+	w := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	body := w.Body.String()
+	if !strings.Contains(body, `langchain_go_llm_cost_usd_total{model="gpt-4o",provider="openai"} 2`) {
+		t.Error("expected llm_cost_usd_total metric")
+	}
+	if !strings.Contains(body, `langchain_go_llm_tokens_total{model="gpt-4o",provider="openai",type="input"} 1e+06`) {
+		t.Error("expected llm_tokens_total metric")
+	}
+}
@@ -3,7 +3,7 @@
 // 核心功能：
 //   - AES-256-GCM 加密
 //   - 字段级加密
-//   - 数据脱敏（邮箱、手机号、身份证、银行卡）
+//   - 数据脱敏（邮箱、手机号、身份证、银行卡，以及自由文本中的这些信息）
 //   - 密钥管理
 //
 // 使用示例：
@@ -21,4 +21,8 @@
 //	phoneMasker := security.NewPhoneMasker()
 //	masked := phoneMasker.Mask("13812345678") // -> 138****5678
 //
+//	// 自由文本脱敏（提示词、模型输出等）
+//	textMasker := security.NewTextMasker()
+//	masked := textMasker.Mask("手机 13812345678") // -> 手机 138****5678
+//
 package security
@@ -189,6 +189,59 @@ func (m *AddressMasker) Mask(address string) string {
 	return string(runes[:8]) + "****"
 }
 
+// TextMasker 文本脱敏器
+//
+// 在自由文本中查找敏感信息，用对应的脱敏器替换每一处匹配，
+// 用于提示词、模型输出等无法按字段脱敏的内容。
+//
+// 示例：请联系 user@example.com -> 请联系 u***@example.com
+type TextMasker struct {
+	rules []textMaskRule
+}
+
+// textMaskRule 文本脱敏规则
+type textMaskRule struct {
+	pattern *regexp.Regexp
+	masker  Masker
+}
+
+// NewTextMasker 创建文本脱敏器
+//
+// 默认识别邮箱、18 位身份证号、银行卡号和手机号。
+//
+// 返回：
+//   - *TextMasker: 文本脱敏器
+func NewTextMasker() *TextMasker {
+	return (&TextMasker{}).
+		AddPattern(regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`), NewEmailMasker()).
+		AddPattern(regexp.MustCompile(`\b\d{17}[\dXx]\b`), NewIDCardMasker()).
+		AddPattern(regexp.MustCompile(`\b\d{16,19}\b`), NewBankCardMasker()).
+		AddPattern(regexp.MustCompile(`\b1[3-9]\d{9}\b`), NewPhoneMasker())
+}
+
+// AddPattern 添加识别规则
+//
+// 规则按添加顺序依次应用，匹配 pattern 的文本交给 masker 脱敏。
+//
+// 参数：
+//   - pattern: 敏感信息的正则表达式
+//   - masker: 匹配文本的脱敏器
+//
+// 返回：
+//   - *TextMasker: 文本脱敏器本身，便于链式调用
+func (m *TextMasker) AddPattern(pattern *regexp.Regexp, masker Masker) *TextMasker {
+	m.rules = append(m.rules, textMaskRule{pattern: pattern, masker: masker})
+	return m
+}
+
+// Mask 脱敏文本中所有匹配的敏感信息
+func (m *TextMasker) Mask(text string) string {
+	for _, rule := range m.rules {
+		text = rule.pattern.ReplaceAllStringFunc(text, rule.masker.Mask)
+	}
+	return text
+}
+
 // FieldMasker 字段脱敏器
 //
 // 用于对文档或对象中的特定字段进行脱敏。
@@ -281,6 +281,17 @@ func (m *DefaultTenantManager) CheckQuota(ctx context.Context, tenantID string,
 		current = int64(tenant.Usage.StorageUsedGB * 1024) // 转换为 MB
 		limit = int64(tenant.Quota.StorageGB * 1024)
 
+	case ResourceTypeCost:
+		if tenant.Quota.MaxCostMicros <= 0 {
+			return &QuotaCheck{
+				Allowed: true,
+				Current: tenant.Usage.CostThisMonthMicros,
+				Message: "no cost limit",
+			}, nil
+		}
+		current = tenant.Usage.CostThisMonthMicros
+		limit = tenant.Quota.MaxCostMicros
+
 	default:
 		return &QuotaCheck{
 			Allowed: true,
@@ -332,6 +343,8 @@ func (m *DefaultTenantManager) IncrementUsage(ctx context.Context, tenantID stri
 		tenant.Usage.TokensThisMonth += int64(amount)
 	case ResourceTypeStorage:
 		tenant.Usage.StorageUsedGB += float64(amount)
+	case ResourceTypeCost:
+		tenant.Usage.CostThisMonthMicros += int64(amount)
 	}
 
 	// 保存到存储
@@ -380,6 +393,11 @@ func (m *DefaultTenantManager) DecrementUsage(ctx context.Context, tenantID stri
 		if tenant.Usage.StorageUsedGB < 0 {
 			tenant.Usage.StorageUsedGB = 0
 		}
+	case ResourceTypeCost:
+		tenant.Usage.CostThisMonthMicros -= int64(amount)
+		if tenant.Usage.CostThisMonthMicros < 0 {
+			tenant.Usage.CostThisMonthMicros = 0
+		}
 	}
 
 	// 保存到存储
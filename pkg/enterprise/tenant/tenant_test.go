@@ -93,6 +93,39 @@ func TestDefaultTenantManager_IncrementUsage(t *testing.T) {
 	}
 }
 
+func TestDefaultTenantManager_CostQuota(t *testing.T) {
+	store := NewMemoryStore()
+	manager := NewDefaultTenantManager(store)
+	ctx := context.Background()
+
+	tenant := &Tenant{
+		ID:    "test-tenant",
+		Name:  "测试租户",
+		Quota: DefaultQuota(),
+	}
+	manager.CreateTenant(ctx, tenant)
+
+	// 未设置费用上限时不限制
+	check, err := manager.CheckQuota(ctx, "test-tenant", ResourceTypeCost)
+	if err != nil {
+		t.Fatalf("CheckQuota failed: %v", err)
+	}
+	if !check.Allowed {
+		t.Error("Expected cost check to pass without limit")
+	}
+
+	tenant.Quota.MaxCostMicros = 1_000_000
+	manager.IncrementUsage(ctx, "test-tenant", ResourceTypeCost, 1_000_000)
+
+	check, _ = manager.CheckQuota(ctx, "test-tenant", ResourceTypeCost)
+	if check.Allowed {
+		t.Error("Expected cost check to fail after reaching the limit")
+	}
+	if check.Current != 1_000_000 || check.Limit != 1_000_000 {
+		t.Errorf("Unexpected check: %s", check)
+	}
+}
+
 func TestDefaultTenantManager_Member(t *testing.T) {
 	store := NewMemoryStore()
 	manager := NewDefaultTenantManager(store)
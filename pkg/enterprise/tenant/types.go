@@ -56,6 +56,9 @@ const (
 
 	// ResourceTypeStorage 存储空间（GB）
 	ResourceTypeStorage ResourceType = "storage"
+
+	// ResourceTypeCost 模型调用费用（微美元，1 USD = 1,000,000）
+	ResourceTypeCost ResourceType = "cost"
 )
 
 // Quota 资源配额
@@ -78,6 +81,9 @@ type Quota struct {
 	// StorageGB 最大存储空间 (GB)
 	StorageGB float64 `json:"storage_gb"`
 
+	// MaxCostMicros 最大模型调用费用/月（微美元），0 表示不限制
+	MaxCostMicros int64 `json:"max_cost_micros,omitempty"`
+
 	// CustomLimits 自定义限制
 	CustomLimits map[string]int64 `json:"custom_limits,omitempty"`
 }
@@ -115,6 +121,9 @@ type Usage struct {
 	// StorageUsedGB 已使用存储空间 (GB)
 	StorageUsedGB float64 `json:"storage_used_gb"`
 
+	// CostThisMonthMicros 本月模型调用费用（微美元）
+	CostThisMonthMicros int64 `json:"cost_this_month_micros"`
+
 	// LastResetAt 最后重置时间
 	LastResetAt time.Time `json:"last_reset_at"`
 }
@@ -200,6 +209,12 @@ func (t *Tenant) CanUseResource(resourceType ResourceType, amount int) bool {
 	case ResourceTypeStorage:
 		return t.Usage.StorageUsedGB+float64(amount) <= t.Quota.StorageGB
 
+	case ResourceTypeCost:
+		if t.Quota.MaxCostMicros <= 0 {
+			return true // 未设置费用上限
+		}
+		return t.Usage.CostThisMonthMicros+int64(amount) <= t.Quota.MaxCostMicros
+
 	default:
 		return true
 	}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"time"
 	
+	"github.com/zhucl121/langchain-go/pkg/types"
+	
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -97,11 +99,13 @@ type OperationTracker struct {
 }
 
 // StartOperation 开始追踪一个操作
-func StartOperation(ctx context.Context, operationName string, labels map[string]string) *OperationTracker {
+//
+// opts 传给 Span 的创建（例如 Span 类型、开始时间）。
+func StartOperation(ctx context.Context, operationName string, labels map[string]string, opts ...trace.SpanStartOption) *OperationTracker {
 	obs, _ := FromContext(ctx)
 	
 	// 创建 span
-	newCtx, span := StartSpan(ctx, operationName)
+	newCtx, span := StartSpan(ctx, operationName, opts...)
 	spanHelper := NewSpanHelper(span)
 	
 	// 获取 logger
@@ -258,13 +262,13 @@ type LLMOperationTracker struct {
 }
 
 // StartLLMOperation 开始追踪 LLM 操作
-func StartLLMOperation(ctx context.Context, provider, model string) *LLMOperationTracker {
+func StartLLMOperation(ctx context.Context, provider, model string, opts ...trace.SpanStartOption) *LLMOperationTracker {
 	labels := map[string]string{
 		"provider": provider,
 		"model":    model,
 	}
 	
-	tracker := StartOperation(ctx, "llm.call", labels)
+	tracker := StartOperation(ctx, "llm.call", labels, opts...)
 	
 	return &LLMOperationTracker{
 		OperationTracker: tracker,
@@ -286,6 +290,22 @@ func (lt *LLMOperationTracker) SetTokens(inputTokens, outputTokens int) {
 	}
 }
 
+// SetUsage 从模型响应的 UsageMetadata 设置 token 数量
+//
+// usage 为 nil（提供商未返回用量）时不做任何处理。
+func (lt *LLMOperationTracker) SetUsage(usage *types.UsageMetadata) {
+	if usage == nil {
+		return
+	}
+	
+	lt.SetTokens(usage.InputTokens, usage.OutputTokens)
+	
+	if lt.spanHelper != nil {
+		lt.spanHelper.SetAttribute(AttrLLMTokensCached, usage.CachedTokens)
+		lt.spanHelper.SetAttribute(AttrLLMTokensReasoning, usage.ReasoningTokens)
+	}
+}
+
 // End 结束 LLM 操作
 func (lt *LLMOperationTracker) End(err error) {
 	duration := time.Since(lt.startTime)
@@ -358,14 +378,18 @@ type ToolOperationTracker struct {
 }
 
 // StartToolOperation 开始追踪 Tool 操作
-func StartToolOperation(ctx context.Context, toolName string, input any) *ToolOperationTracker {
+//
+// input 为 nil 时不记录 tool.input 属性。
+func StartToolOperation(ctx context.Context, toolName string, input any, opts ...trace.SpanStartOption) *ToolOperationTracker {
 	labels := map[string]string{
 		"tool_name": toolName,
 	}
 	
-	tracker := StartOperation(ctx, "tool.call", labels)
+	tracker := StartOperation(ctx, "tool.call", labels, opts...)
 	tracker.SetAttribute(AttrToolName, toolName)
-	tracker.SetAttribute(AttrToolInput, fmt.Sprintf("%v", input))
+	if input != nil {
+		tracker.SetAttribute(AttrToolInput, fmt.Sprintf("%v", input))
+	}
 	
 	return &ToolOperationTracker{
 		OperationTracker: tracker,
@@ -10,6 +10,8 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/otel"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	
+	"github.com/zhucl121/langchain-go/pkg/types"
 )
 
 func TestObservabilityContext(t *testing.T) {
@@ -227,6 +229,25 @@ func TestLLMOperationTracker(t *testing.T) {
 	tracker.End(nil)
 }
 
+func TestLLMOperationTracker_SetUsage(t *testing.T) {
+	metrics := NewMetricsCollector(MetricsConfig{})
+	obs := NewObservabilityContext(nil, nil, metrics)
+	ctx := WithObservability(context.Background(), obs)
+	
+	tracker := StartLLMOperation(ctx, "anthropic", "claude")
+	
+	// 未返回用量时保持为 0
+	tracker.SetUsage(nil)
+	assert.Equal(t, 0, tracker.totalTokens)
+	
+	tracker.SetUsage(&types.UsageMetadata{InputTokens: 120, OutputTokens: 30, TotalTokens: 150, CachedTokens: 100})
+	assert.Equal(t, 120, tracker.inputTokens)
+	assert.Equal(t, 30, tracker.outputTokens)
+	assert.Equal(t, 150, tracker.totalTokens)
+	
+	tracker.End(nil)
+}
+
 func TestRAGOperationTracker(t *testing.T) {
 	tp := sdktrace.NewTracerProvider()
 	otel.SetTracerProvider(tp)
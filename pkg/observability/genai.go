@@ -0,0 +1,444 @@
+package observability
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/zhucl121/langchain-go/core/callbacks"
+	"github.com/zhucl121/langchain-go/pkg/enterprise/security"
+	"github.com/zhucl121/langchain-go/pkg/types"
+)
+
+// GenAI 语义约定属性（OpenTelemetry Semantic Conventions for Generative AI）
+const (
+	AttrGenAISystem                = "gen_ai.system"
+	AttrGenAIOperationName         = "gen_ai.operation.name"
+	AttrGenAIRequestModel          = "gen_ai.request.model"
+	AttrGenAIResponseModel         = "gen_ai.response.model"
+	AttrGenAIResponseFinishReasons = "gen_ai.response.finish_reasons"
+	AttrGenAIUsageInputTokens      = "gen_ai.usage.input_tokens"
+	AttrGenAIUsageOutputTokens     = "gen_ai.usage.output_tokens"
+	AttrGenAIToolName              = "gen_ai.tool.name"
+	AttrGenAIAgentName             = "gen_ai.agent.name"
+
+	// 内容属性，只在 GenAIConfig.CaptureContent 为 true 时记录
+	AttrGenAIPrompt            = "gen_ai.prompt"
+	AttrGenAICompletion        = "gen_ai.completion"
+	AttrGenAIToolCallArguments = "gen_ai.tool.call.arguments"
+	AttrGenAIToolCallResult    = "gen_ai.tool.call.result"
+
+	// 运行信息
+	AttrRunID   = "langchain.run.id"
+	AttrRunType = "langchain.run.type"
+	AttrRunTags = "langchain.run.tags"
+)
+
+// GenAI 操作名称（gen_ai.operation.name）
+const (
+	GenAIOperationChat        = "chat"
+	GenAIOperationExecuteTool = "execute_tool"
+	GenAIOperationInvokeAgent = "invoke_agent"
+)
+
+// genAIInstrumentationName 默认 Tracer 的名称
+const genAIInstrumentationName = "github.com/zhucl121/langchain-go"
+
+// GenAIConfig GenAI 追踪配置
+type GenAIConfig struct {
+	// Tracer 使用的 Tracer（可选）
+	//
+	// 为空时使用上下文中 ObservabilityContext 的 Tracer，
+	// 其次使用全局 TracerProvider（NewTracerProvider 会设置全局 TracerProvider）。
+	Tracer trace.Tracer
+
+	// CaptureContent 是否记录提示词、模型输出、工具输入输出和检索查询（默认不记录）
+	CaptureContent bool
+
+	// Masker 记录内容前使用的脱敏器（可选，默认为 security.NewTextMasker()）
+	Masker security.Masker
+}
+
+// GenAIHandler 把回调事件转换为 OpenTelemetry Span 的回调处理器
+//
+// 每次运行对应一个 Span，父子关系与运行树一致：
+// Agent → 步骤 → 模型调用 / 工具调用 → 检索 → 向量存储查询。
+// 模型调用的 Span 按 GenAI 语义约定记录 gen_ai.system、gen_ai.request.model、
+// token 用量和结束原因。模型调用和工具调用的 Span 分别通过 StartLLMOperation
+// 和 StartToolOperation 创建，同时记录这两个函数的属性、日志和指标。
+//
+// GenAIHandler 实现了 callbacks.ContextHandler：组件在携带本次运行 Span 的上下文中执行，
+// 因此组件发起的下游调用（例如 otelhttp 的 HTTP 请求）的 Span 也位于运行的 Span 之下。
+type GenAIHandler struct {
+	callbacks.BaseHandler
+
+	config GenAIConfig
+	runs   sync.Map // run ID -> *genAIRun
+}
+
+// genAIRun 运行对应的 Span
+//
+// 模型调用和工具调用还保存创建 Span 的操作追踪器，由追踪器结束 Span。
+type genAIRun struct {
+	span trace.Span
+	llm  *LLMOperationTracker
+	tool *ToolOperationTracker
+}
+
+var _ callbacks.ContextHandler = (*GenAIHandler)(nil)
+
+// NewGenAIHandler 创建 GenAI 追踪处理器
+//
+// 参数：
+//   - config: 追踪配置
+//
+// 返回：
+//   - *GenAIHandler: 回调处理器，通过 callbacks.WithHandlers 或
+//     callbacks.AddGlobalHandlers 使用
+//
+func NewGenAIHandler(config GenAIConfig) *GenAIHandler {
+	if config.CaptureContent && config.Masker == nil {
+		config.Masker = security.NewTextMasker()
+	}
+	return &GenAIHandler{config: config}
+}
+
+// InstallGenAITracing 创建 GenAI 追踪处理器并注册为全局回调处理器
+//
+// 之后所有模型、工具、检索器、向量存储、链、图和 Agent 的运行都会自动产生 Span。
+//
+// 示例：
+//
+//	tp, _ := observability.NewTracerProvider(config)
+//	defer tp.Shutdown(ctx)
+//	observability.InstallGenAITracing(observability.GenAIConfig{})
+//
+func InstallGenAITracing(config GenAIConfig) *GenAIHandler {
+	handler := NewGenAIHandler(config)
+	callbacks.AddGlobalHandlers(handler)
+	return handler
+}
+
+// tracer 返回本次运行使用的 Tracer
+func (h *GenAIHandler) tracer(ctx context.Context) trace.Tracer {
+	if h.config.Tracer != nil {
+		return h.config.Tracer
+	}
+	if obs, ok := FromContext(ctx); ok && obs.Tracer != nil {
+		return obs.Tracer
+	}
+	return otel.Tracer(genAIInstrumentationName)
+}
+
+// RunContext 返回携带运行 Span 的上下文，实现 callbacks.ContextHandler
+func (h *GenAIHandler) RunContext(ctx context.Context, info callbacks.RunInfo) context.Context {
+	span, ok := h.span(info.RunID)
+	if !ok {
+		return ctx
+	}
+	return trace.ContextWithSpan(ctx, span)
+}
+
+// spanOptions 返回运行 Span 的创建选项
+func (h *GenAIHandler) spanOptions(info callbacks.RunInfo, kind trace.SpanKind, attrs ...attribute.KeyValue) []trace.SpanStartOption {
+	attrs = append(attrs,
+		attribute.String(AttrRunID, info.RunID),
+		attribute.String(AttrRunType, string(info.Type)),
+	)
+	if len(info.Tags) > 0 {
+		attrs = append(attrs, attribute.StringSlice(AttrRunTags, info.Tags))
+	}
+	return []trace.SpanStartOption{
+		trace.WithSpanKind(kind),
+		trace.WithTimestamp(info.StartTime),
+		trace.WithAttributes(attrs...),
+	}
+}
+
+// parentContext 返回以父运行 Span 为父 Span 的上下文
+func (h *GenAIHandler) parentContext(ctx context.Context, info callbacks.RunInfo) context.Context {
+	if info.ParentRunID != "" {
+		if parent, ok := h.span(info.ParentRunID); ok {
+			ctx = trace.ContextWithSpan(ctx, parent)
+		}
+	}
+	return ctx
+}
+
+// operationContext 返回供 StartLLMOperation / StartToolOperation 使用的上下文
+//
+// 操作追踪器通过上下文中 ObservabilityContext 的 Tracer 创建 Span，
+// 这里把 Tracer 换成本处理器使用的 Tracer，保留原有的 Logger 和 Metrics。
+func (h *GenAIHandler) operationContext(ctx context.Context, info callbacks.RunInfo) context.Context {
+	obs := &ObservabilityContext{}
+	if current, ok := FromContext(ctx); ok {
+		*obs = *current
+	}
+	obs.Tracer = h.tracer(ctx)
+	return WithObservability(h.parentContext(ctx, info), obs)
+}
+
+// start 开始运行对应的 Span
+func (h *GenAIHandler) start(ctx context.Context, info callbacks.RunInfo, name string, kind trace.SpanKind, attrs ...attribute.KeyValue) {
+	ctx = h.parentContext(ctx, info)
+	_, span := h.tracer(ctx).Start(ctx, name, h.spanOptions(info, kind, attrs...)...)
+	h.runs.Store(info.RunID, &genAIRun{span: span})
+}
+
+// run 返回运行 ID 对应的运行
+func (h *GenAIHandler) run(runID string) (*genAIRun, bool) {
+	value, ok := h.runs.Load(runID)
+	if !ok {
+		return nil, false
+	}
+	return value.(*genAIRun), true
+}
+
+// span 返回运行对应的 Span
+func (h *GenAIHandler) span(runID string) (trace.Span, bool) {
+	run, ok := h.run(runID)
+	if !ok {
+		return nil, false
+	}
+	return run.span, true
+}
+
+// end 结束运行对应的 Span
+func (h *GenAIHandler) end(info callbacks.RunInfo, err error, endTime time.Time, attrs ...attribute.KeyValue) {
+	value, ok := h.runs.LoadAndDelete(info.RunID)
+	if !ok {
+		return
+	}
+	run := value.(*genAIRun)
+	run.span.SetAttributes(attrs...)
+	switch {
+	case run.llm != nil:
+		run.llm.End(err)
+	case run.tool != nil:
+		run.tool.End(err)
+	default:
+		if err != nil {
+			run.span.RecordError(err)
+			run.span.SetStatus(codes.Error, err.Error())
+		} else {
+			run.span.SetStatus(codes.Ok, "")
+		}
+		run.span.End(trace.WithTimestamp(endTime))
+	}
+}
+
+// content 返回脱敏后的内容属性，未开启内容记录时返回 false
+func (h *GenAIHandler) content(key string, value any) (attribute.KeyValue, bool) {
+	if !h.config.CaptureContent {
+		return attribute.KeyValue{}, false
+	}
+
+	var text string
+	switch v := value.(type) {
+	case string:
+		text = v
+	case fmt.Stringer:
+		text = v.String()
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			text = fmt.Sprintf("%v", v)
+		} else {
+			text = string(data)
+		}
+	}
+	return attribute.String(key, h.config.Masker.Mask(text)), true
+}
+
+// promptContent 返回消息在 gen_ai.prompt 中记录的内容
+//
+// 多模态消息按顺序记录每个部分：文本部分记录原文，其他部分记录
+// "[image]"、"[audio]" 等占位符，不记录二进制数据或 URL。
+func promptContent(msg types.Message) string {
+	if len(msg.Parts) == 0 {
+		return msg.Content
+	}
+	parts := make([]string, 0, len(msg.Parts))
+	for _, part := range msg.Parts {
+		if part == nil {
+			continue
+		}
+		if part.Type == types.ContentTypeText {
+			parts = append(parts, part.Text)
+		} else {
+			parts = append(parts, "["+string(part.Type)+"]")
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// OnLLMStart 通过 StartLLMOperation 开始 "chat {model}" Span
+func (h *GenAIHandler) OnLLMStart(ctx context.Context, event *callbacks.LLMStartEvent) {
+	attrs := []attribute.KeyValue{
+		attribute.String(AttrGenAIOperationName, GenAIOperationChat),
+		attribute.String(AttrGenAISystem, event.Provider),
+		attribute.String(AttrGenAIRequestModel, event.Model),
+	}
+	if len(event.Messages) > 0 {
+		prompt := make([]map[string]string, len(event.Messages))
+		for i, msg := range event.Messages {
+			prompt[i] = map[string]string{"role": string(msg.Role), "content": promptContent(msg)}
+		}
+		if attr, ok := h.content(AttrGenAIPrompt, prompt); ok {
+			attrs = append(attrs, attr)
+		}
+	}
+
+	op := StartLLMOperation(h.operationContext(ctx, event.RunInfo), event.Provider, event.Model,
+		h.spanOptions(event.RunInfo, trace.SpanKindClient, attrs...)...)
+	op.span.SetName(GenAIOperationChat + " " + event.Model)
+	h.runs.Store(event.RunID, &genAIRun{span: op.span, llm: op})
+}
+
+// OnLLMEnd 记录 token 用量和结束原因并结束 Span
+func (h *GenAIHandler) OnLLMEnd(ctx context.Context, event *callbacks.LLMEndEvent) {
+	var attrs []attribute.KeyValue
+	if event.Err == nil {
+		output := event.Output
+		if model, ok := output.Metadata["model"].(string); ok && model != "" {
+			attrs = append(attrs, attribute.String(AttrGenAIResponseModel, model))
+		}
+		if output.FinishReason != "" {
+			attrs = append(attrs, attribute.StringSlice(AttrGenAIResponseFinishReasons, []string{string(output.FinishReason)}))
+		}
+		if usage := output.UsageMetadata; usage != nil {
+			attrs = append(attrs,
+				attribute.Int(AttrGenAIUsageInputTokens, usage.InputTokens),
+				attribute.Int(AttrGenAIUsageOutputTokens, usage.OutputTokens),
+			)
+			if run, ok := h.run(event.RunID); ok && run.llm != nil {
+				run.llm.SetUsage(usage)
+			}
+		}
+		if attr, ok := h.content(AttrGenAICompletion, output.Content); ok {
+			attrs = append(attrs, attr)
+		}
+	}
+	h.end(event.RunInfo, event.Err, event.EndTime, attrs...)
+}
+
+// OnToolStart 通过 StartToolOperation 开始 "execute_tool {name}" Span
+//
+// 未开启内容记录时不向 StartToolOperation 传入工具输入。
+func (h *GenAIHandler) OnToolStart(ctx context.Context, event *callbacks.ToolStartEvent) {
+	attrs := []attribute.KeyValue{
+		attribute.String(AttrGenAIOperationName, GenAIOperationExecuteTool),
+		attribute.String(AttrGenAIToolName, event.Name),
+	}
+	var input any
+	if attr, ok := h.content(AttrGenAIToolCallArguments, event.Input); ok {
+		attrs = append(attrs, attr)
+		input = attr.Value.AsString()
+	}
+
+	op := StartToolOperation(h.operationContext(ctx, event.RunInfo), event.Name, input,
+		h.spanOptions(event.RunInfo, trace.SpanKindInternal, attrs...)...)
+	op.span.SetName(GenAIOperationExecuteTool + " " + event.Name)
+	h.runs.Store(event.RunID, &genAIRun{span: op.span, tool: op})
+}
+
+// OnToolEnd 结束工具调用 Span
+func (h *GenAIHandler) OnToolEnd(ctx context.Context, event *callbacks.ToolEndEvent) {
+	var attrs []attribute.KeyValue
+	if event.Err == nil {
+		if attr, ok := h.content(AttrGenAIToolCallResult, event.Output); ok {
+			attrs = append(attrs, attr)
+			if run, ok := h.run(event.RunID); ok && run.tool != nil {
+				run.tool.SetOutput(attr.Value.AsString())
+			}
+		}
+	}
+	h.end(event.RunInfo, event.Err, event.EndTime, attrs...)
+}
+
+// OnRetrieverStart 开始检索（"retrieve {name}"）或向量存储查询（"query {name}"）Span
+func (h *GenAIHandler) OnRetrieverStart(ctx context.Context, event *callbacks.RetrieverStartEvent) {
+	name := "retrieve " + event.Name
+	kind := trace.SpanKindInternal
+	var attrs []attribute.KeyValue
+	if event.Type == callbacks.RunTypeVectorStore {
+		name = "query " + event.Name
+		kind = trace.SpanKindClient
+		attrs = append(attrs, attribute.String(AttrRAGVectorStore, event.Name))
+	}
+	if attr, ok := h.content(AttrRAGQuery, event.Query); ok {
+		attrs = append(attrs, attr)
+	}
+	h.start(ctx, event.RunInfo, name, kind, attrs...)
+}
+
+// OnRetrieverEnd 记录文档数量并结束 Span
+func (h *GenAIHandler) OnRetrieverEnd(ctx context.Context, event *callbacks.RetrieverEndEvent) {
+	h.end(event.RunInfo, event.Err, event.EndTime, attribute.Int(AttrRAGDocCount, len(event.Documents)))
+}
+
+// OnChainStart 开始链、图或 Agent 的 Span
+func (h *GenAIHandler) OnChainStart(ctx context.Context, event *callbacks.ChainStartEvent) {
+	if event.Type == callbacks.RunTypeAgent {
+		h.start(ctx, event.RunInfo, GenAIOperationInvokeAgent+" "+event.Name, trace.SpanKindInternal,
+			attribute.String(AttrGenAIOperationName, GenAIOperationInvokeAgent),
+			attribute.String(AttrGenAIAgentName, event.Name),
+		)
+		return
+	}
+
+	attrs := []attribute.KeyValue{attribute.String(AttrChainName, event.Name)}
+	if step, ok := event.Metadata["step"].(int); ok {
+		attrs = append(attrs, attribute.Int(AttrAgentStep, step))
+	}
+	h.start(ctx, event.RunInfo, event.Name, trace.SpanKindInternal, attrs...)
+}
+
+// OnChainEnd 结束链、图或 Agent 的 Span
+func (h *GenAIHandler) OnChainEnd(ctx context.Context, event *callbacks.ChainEndEvent) {
+	h.end(event.RunInfo, event.Err, event.EndTime)
+}
+
+// OnNodeStart 开始图节点 Span
+func (h *GenAIHandler) OnNodeStart(ctx context.Context, event *callbacks.NodeStartEvent) {
+	h.start(ctx, event.RunInfo, event.Name, trace.SpanKindInternal)
+}
+
+// OnNodeEnd 结束图节点 Span
+func (h *GenAIHandler) OnNodeEnd(ctx context.Context, event *callbacks.NodeEndEvent) {
+	h.end(event.RunInfo, event.Err, event.EndTime)
+}
+
+// OnAgentAction 在步骤 Span 上记录 agent_action 事件
+func (h *GenAIHandler) OnAgentAction(ctx context.Context, event *callbacks.AgentActionEvent) {
+	span, ok := h.span(event.RunID)
+	if !ok {
+		return
+	}
+	attrs := []attribute.KeyValue{attribute.String(AttrAgentTool, event.Tool)}
+	if attr, ok := h.content(AttrGenAIToolCallArguments, event.ToolInput); ok {
+		attrs = append(attrs, attr)
+	}
+	span.AddEvent("agent_action", trace.WithTimestamp(event.Time), trace.WithAttributes(attrs...))
+}
+
+// OnAgentFinish 在步骤 Span 上记录 agent_finish 事件
+func (h *GenAIHandler) OnAgentFinish(ctx context.Context, event *callbacks.AgentFinishEvent) {
+	span, ok := h.span(event.RunID)
+	if !ok {
+		return
+	}
+	var attrs []attribute.KeyValue
+	if attr, ok := h.content(AttrGenAICompletion, event.Output); ok {
+		attrs = append(attrs, attr)
+	}
+	span.AddEvent("agent_finish", trace.WithTimestamp(event.Time), trace.WithAttributes(attrs...))
+}
@@ -0,0 +1,274 @@
+package observability
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/zhucl121/langchain-go/core/callbacks"
+	"github.com/zhucl121/langchain-go/core/chat/providers/openai"
+	"github.com/zhucl121/langchain-go/pkg/types"
+)
+
+// spanAttr 返回 Span 的属性值
+func spanAttr(span sdktrace.ReadOnlySpan, key string) (attribute.Value, bool) {
+	for _, kv := range span.Attributes() {
+		if string(kv.Key) == key {
+			return kv.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+// runAgent 模拟一次 Agent 执行：Agent → 步骤 → 模型调用、工具调用 → 检索 → 向量存储查询
+func runAgent(ctx context.Context) {
+	ctx, agent := callbacks.StartAgent(ctx, "react", "question")
+	stepCtx, step := callbacks.StartChain(callbacks.WithMetadata(ctx, map[string]any{"step": 1}), "agent_step", 1)
+
+	llmCtx, llm := callbacks.StartLLM(stepCtx, "gpt-4o", callbacks.LLMStartEvent{
+		Provider: "openai",
+		Model:    "gpt-4o",
+		Messages: []types.Message{types.NewUserMessage("我的邮箱是 alice@example.com")},
+	})
+	output := types.NewAssistantMessage("好的")
+	output.FinishReason = types.FinishReasonToolCalls
+	output.UsageMetadata = &types.UsageMetadata{InputTokens: 12, OutputTokens: 3, TotalTokens: 15}
+	llm.EndLLM(llmCtx, output, nil)
+	step.AgentAction(stepCtx, "search", map[string]any{"query": "go"}, "")
+
+	toolCtx, tool := callbacks.StartTool(stepCtx, "search", map[string]any{"phone": "13812345678"})
+	retrieverCtx, retriever := callbacks.StartRetriever(toolCtx, "VectorStoreRetriever", "go")
+	storeCtx, store := callbacks.StartVectorStore(retrieverCtx, "InMemoryVectorStore", "go")
+	store.EndRetriever(storeCtx, []*types.Document{{Content: "a"}, {Content: "b"}}, nil)
+	retriever.EndRetriever(retrieverCtx, nil, errors.New("rerank failed"))
+	tool.EndTool(toolCtx, "done", nil)
+
+	step.EndChain(stepCtx, nil, nil)
+	agent.EndChain(ctx, "answer", nil)
+}
+
+// TestGenAIHandler 测试 GenAI Span 的属性和父子关系
+func TestGenAIHandler(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	defer provider.Shutdown(context.Background())
+
+	handler := NewGenAIHandler(GenAIConfig{Tracer: provider.Tracer("test")})
+	runAgent(callbacks.WithHandlers(context.Background(), handler))
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	if len(spans) != 6 {
+		t.Fatalf("Expected 6 spans, got %d", len(spans))
+	}
+
+	// 父子关系
+	parents := map[string]string{
+		"agent_step":                    "invoke_agent react",
+		"chat gpt-4o":                   "agent_step",
+		"execute_tool search":           "agent_step",
+		"retrieve VectorStoreRetriever": "execute_tool search",
+		"query InMemoryVectorStore":     "retrieve VectorStoreRetriever",
+	}
+	for child, parent := range parents {
+		if spans[child].Parent().SpanID() != spans[parent].SpanContext().SpanID() {
+			t.Errorf("Expected %q to be child of %q", child, parent)
+		}
+	}
+
+	chat := spans["chat gpt-4o"]
+	expected := map[string]attribute.Value{
+		AttrGenAIOperationName:         attribute.StringValue("chat"),
+		AttrGenAISystem:                attribute.StringValue("openai"),
+		AttrGenAIRequestModel:          attribute.StringValue("gpt-4o"),
+		AttrGenAIUsageInputTokens:      attribute.IntValue(12),
+		AttrGenAIUsageOutputTokens:     attribute.IntValue(3),
+		AttrGenAIResponseFinishReasons: attribute.StringSliceValue([]string{"tool_calls"}),
+	}
+	for key, want := range expected {
+		got, ok := spanAttr(chat, key)
+		if !ok || got.Emit() != want.Emit() {
+			t.Errorf("Expected %s=%s, got %s", key, want.Emit(), got.Emit())
+		}
+	}
+
+	// 默认不记录内容
+	if _, ok := spanAttr(chat, AttrGenAIPrompt); ok {
+		t.Error("Expected prompt not to be captured by default")
+	}
+
+	if v, _ := spanAttr(spans["query InMemoryVectorStore"], AttrRAGDocCount); v.AsInt64() != 2 {
+		t.Errorf("Expected document count 2, got %d", v.AsInt64())
+	}
+	if v, _ := spanAttr(spans["agent_step"], AttrAgentStep); v.AsInt64() != 1 {
+		t.Errorf("Expected step 1, got %d", v.AsInt64())
+	}
+	if events := spans["agent_step"].Events(); len(events) != 1 || events[0].Name != "agent_action" {
+		t.Errorf("Expected agent_action event on step span, got %v", events)
+	}
+
+	retriever := spans["retrieve VectorStoreRetriever"]
+	if retriever.Status().Code != codes.Error {
+		t.Errorf("Expected error status, got %v", retriever.Status().Code)
+	}
+}
+
+// TestGenAIHandler_CaptureContent 测试内容记录和脱敏
+func TestGenAIHandler_CaptureContent(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	defer provider.Shutdown(context.Background())
+
+	handler := NewGenAIHandler(GenAIConfig{Tracer: provider.Tracer("test"), CaptureContent: true})
+	runAgent(callbacks.WithHandlers(context.Background(), handler))
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+
+	prompt, ok := spanAttr(spans["chat gpt-4o"], AttrGenAIPrompt)
+	if !ok {
+		t.Fatal("Expected prompt to be captured")
+	}
+	if strings.Contains(prompt.AsString(), "alice@example.com") || !strings.Contains(prompt.AsString(), "a***@example.com") {
+		t.Errorf("Expected email to be masked, got %s", prompt.AsString())
+	}
+	if v, _ := spanAttr(spans["chat gpt-4o"], AttrGenAICompletion); v.AsString() != "好的" {
+		t.Errorf("Expected completion '好的', got %q", v.AsString())
+	}
+
+	args, _ := spanAttr(spans["execute_tool search"], AttrGenAIToolCallArguments)
+	if !strings.Contains(args.AsString(), "138****5678") {
+		t.Errorf("Expected phone to be masked, got %s", args.AsString())
+	}
+	if v, _ := spanAttr(spans["query InMemoryVectorStore"], AttrRAGQuery); v.AsString() != "go" {
+		t.Errorf("Expected query 'go', got %q", v.AsString())
+	}
+}
+
+// TestGenAIHandler_RunContext 测试组件上下文携带运行的 Span
+func TestGenAIHandler_RunContext(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	defer provider.Shutdown(context.Background())
+
+	handler := NewGenAIHandler(GenAIConfig{Tracer: provider.Tracer("test")})
+	ctx := callbacks.WithHandlers(context.Background(), handler)
+
+	llmCtx, llm := callbacks.StartLLM(ctx, "gpt-4o", callbacks.LLMStartEvent{Provider: "openai", Model: "gpt-4o"})
+	// 模拟 otelhttp：下游请求的 Span 从组件的上下文开始
+	_, request := provider.Tracer("otelhttp").Start(llmCtx, "HTTP POST")
+	request.End()
+	output := types.NewAssistantMessage("ok")
+	output.UsageMetadata = &types.UsageMetadata{InputTokens: 5, OutputTokens: 2, TotalTokens: 7}
+	llm.EndLLM(llmCtx, output, nil)
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	chat := spans["chat gpt-4o"]
+	if chat == nil {
+		t.Fatalf("Expected chat span, got %v", recorder.Ended())
+	}
+	if spans["HTTP POST"].Parent().SpanID() != chat.SpanContext().SpanID() {
+		t.Error("Expected downstream span to be child of chat span")
+	}
+	if chat.SpanKind() != trace.SpanKindClient {
+		t.Errorf("Expected client span, got %v", chat.SpanKind())
+	}
+
+	// StartLLMOperation 的属性
+	if v, _ := spanAttr(chat, "provider"); v.AsString() != "openai" {
+		t.Errorf("Expected provider 'openai', got %q", v.AsString())
+	}
+	if v, _ := spanAttr(chat, AttrLLMTokensInput); v.AsInt64() != 5 {
+		t.Errorf("Expected llm.tokens.input 5, got %d", v.AsInt64())
+	}
+}
+
+// TestGenAIHandler_MultimodalPrompt 测试多模态消息的非文本部分记录为占位符
+func TestGenAIHandler_MultimodalPrompt(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	defer provider.Shutdown(context.Background())
+
+	handler := NewGenAIHandler(GenAIConfig{Tracer: provider.Tracer("test"), CaptureContent: true})
+	ctx := callbacks.WithHandlers(context.Background(), handler)
+
+	msg := types.NewMessageWithParts(types.RoleUser,
+		types.NewTextContent("描述这张图"),
+		types.NewImageContentFromData([]byte("png"), types.ImageFormatPNG),
+	)
+	llmCtx, llm := callbacks.StartLLM(ctx, "gpt-4o", callbacks.LLMStartEvent{
+		Provider: "openai",
+		Model:    "gpt-4o",
+		Messages: []types.Message{msg},
+	})
+	llm.EndLLM(llmCtx, types.NewAssistantMessage("一只猫"), nil)
+
+	ended := recorder.Ended()
+	if len(ended) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(ended))
+	}
+	prompt, _ := spanAttr(ended[0], AttrGenAIPrompt)
+	if !strings.Contains(prompt.AsString(), `描述这张图\n[image]`) {
+		t.Errorf("Expected image placeholder in prompt, got %s", prompt.AsString())
+	}
+}
+
+// TestInstallGenAITracing 测试全局安装
+func TestInstallGenAITracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	defer provider.Shutdown(context.Background())
+
+	InstallGenAITracing(GenAIConfig{Tracer: provider.Tracer("test")})
+	defer callbacks.ResetGlobalHandlers()
+
+	// 上下文中没有处理器
+	ctx, run := callbacks.StartTool(context.Background(), "calculator", nil)
+	run.EndTool(ctx, 2, nil)
+
+	ended := recorder.Ended()
+	if len(ended) != 1 || ended[0].Name() != "execute_tool calculator" {
+		t.Fatalf("Expected execute_tool span, got %v", ended)
+	}
+
+	// 聊天模型调用同样产生 Span
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"choices":[{"index":0,"message":{"role":"assistant","content":"4"},"finish_reason":"stop"}],`+
+			`"usage":{"prompt_tokens":7,"completion_tokens":1,"total_tokens":8}}`)
+	}))
+	defer server.Close()
+
+	model, err := openai.New(openai.Config{APIKey: "test-key", BaseURL: server.URL, Model: "gpt-4o-mini"})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if _, err := model.Invoke(context.Background(), []types.Message{types.NewUserMessage("2+2")}); err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+
+	ended = recorder.Ended()
+	if len(ended) != 2 || ended[1].Name() != "chat gpt-4o-mini" {
+		t.Fatalf("Expected chat span, got %v", ended)
+	}
+	if v, _ := spanAttr(ended[1], AttrGenAIUsageInputTokens); v.AsInt64() != 7 {
+		t.Errorf("Expected input tokens 7, got %d", v.AsInt64())
+	}
+}
@@ -36,6 +36,7 @@ type MetricsCollector struct {
 	llmCallDuration      *prometheus.HistogramVec
 	llmTokensTotal       *prometheus.CounterVec
 	llmErrorsTotal       *prometheus.CounterVec
+	llmCostTotal         *prometheus.CounterVec
 	
 	// Agent 指标
 	agentStepsTotal      *prometheus.CounterVec
@@ -144,6 +145,16 @@ func (mc *MetricsCollector) initMetrics() {
 		[]string{"provider", "model", "error_type"},
 	)
 	
+	mc.llmCostTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: mc.config.Namespace,
+			Subsystem: mc.config.Subsystem,
+			Name:      "llm_cost_usd_total",
+			Help:      "Total cost of LLM calls in US dollars",
+		},
+		[]string{"provider", "model"},
+	)
+	
 	// Agent 指标
 	mc.agentStepsTotal = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
@@ -320,6 +331,7 @@ func (mc *MetricsCollector) initMetrics() {
 		mc.llmCallDuration,
 		mc.llmTokensTotal,
 		mc.llmErrorsTotal,
+		mc.llmCostTotal,
 		mc.agentStepsTotal,
 		mc.agentStepDuration,
 		mc.agentIterationsTotal,
@@ -357,6 +369,14 @@ func (mc *MetricsCollector) RecordLLMTokens(provider, model string, inputTokens,
 	mc.llmTokensTotal.WithLabelValues(provider, model, "output").Add(float64(outputTokens))
 }
 
+// RecordLLMCost 记录 LLM 调用费用（美元）
+func (mc *MetricsCollector) RecordLLMCost(provider, model string, cost float64) {
+	if cost <= 0 {
+		return
+	}
+	mc.llmCostTotal.WithLabelValues(provider, model).Add(cost)
+}
+
 // RecordAgentStep 记录 Agent 步骤
 func (mc *MetricsCollector) RecordAgentStep(agentType string, duration time.Duration, err error) {
 	status := "success"
@@ -97,6 +97,19 @@ func TestLLMMetrics(t *testing.T) {
 			t.Error("Expected llm_tokens_total metric")
 		}
 	})
+	
+	t.Run("cost", func(t *testing.T) {
+		mc.RecordLLMCost("openai", "gpt-4", 0.25)
+		
+		req := httptest.NewRequest("GET", "/metrics", nil)
+		w := httptest.NewRecorder()
+		mc.Handler().ServeHTTP(w, req)
+		
+		body := w.Body.String()
+		if !strings.Contains(body, `langchain_go_llm_cost_usd_total{model="gpt-4",provider="openai"} 0.25`) {
+			t.Error("Expected llm_cost_usd_total metric")
+		}
+	})
 }
 
 // TestAgentMetrics 测试 Agent 指标
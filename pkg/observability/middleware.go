@@ -58,6 +58,7 @@ func (cmt *ChatModelTracer) Invoke(ctx context.Context, messages []types.Message
 				truncated = truncated[:500] + "..."
 			}
 			span.SetAttribute(AttrLLMResponse, truncated)
+			setResponseAttributes(span, result)
 		}
 		
 		return err
@@ -66,6 +67,21 @@ func (cmt *ChatModelTracer) Invoke(ctx context.Context, messages []types.Message
 	return result, err
 }
 
+// setResponseAttributes 记录响应的 token 用量和结束原因
+func setResponseAttributes(span *SpanHelper, message types.Message) {
+	if message.FinishReason != "" {
+		span.SetAttribute(AttrLLMFinishReason, string(message.FinishReason))
+	}
+	
+	if usage := message.UsageMetadata; usage != nil {
+		span.SetAttribute(AttrLLMTokensInput, usage.InputTokens)
+		span.SetAttribute(AttrLLMTokensOutput, usage.OutputTokens)
+		span.SetAttribute(AttrLLMTokensTotal, usage.TotalTokens)
+		span.SetAttribute(AttrLLMTokensCached, usage.CachedTokens)
+		span.SetAttribute(AttrLLMTokensReasoning, usage.ReasoningTokens)
+	}
+}
+
 // Batch 实现 ChatModel 接口
 func (cmt *ChatModelTracer) Batch(ctx context.Context, messages [][]types.Message, opts ...runnable.Option) ([]types.Message, error) {
 	var results []types.Message
@@ -114,6 +130,9 @@ func (cmt *ChatModelTracer) Stream(ctx context.Context, messages []types.Message
 		
 		var chunkCount int
 		for event := range stream {
+			if event.Type == runnable.EventEnd {
+				setResponseAttributes(helper, event.Data)
+			}
 			wrappedStream <- event
 			chunkCount++
 		}
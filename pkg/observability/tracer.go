@@ -267,6 +267,9 @@ const (
 	AttrLLMTokensInput = "llm.tokens.input"
 	AttrLLMTokensOutput = "llm.tokens.output"
 	AttrLLMTokensTotal = "llm.tokens.total"
+	AttrLLMTokensCached = "llm.tokens.cached"
+	AttrLLMTokensReasoning = "llm.tokens.reasoning"
+	AttrLLMFinishReason = "llm.finish_reason"
 	
 	// Agent 相关
 	AttrAgentType      = "agent.type"
@@ -0,0 +1,162 @@
+package tracing
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// ChangeKind 差异类型
+type ChangeKind string
+
+const (
+	// ChangeAdded 运行只出现在右侧
+	ChangeAdded ChangeKind = "added"
+
+	// ChangeRemoved 运行只出现在左侧
+	ChangeRemoved ChangeKind = "removed"
+
+	// ChangeModified 两侧都有该运行，但字段不同
+	ChangeModified ChangeKind = "modified"
+)
+
+// Change 两棵运行树之间的一处差异
+type Change struct {
+	// Path 运行在树中的路径，例如 "react/agent_step[1]/gpt-4o[0]"
+	Path string `json:"path"`
+
+	// Kind 差异类型
+	Kind ChangeKind `json:"kind"`
+
+	// Field 不同的字段（type、status、error、inputs、outputs、usage），Kind 为 modified 时填写
+	Field string `json:"field,omitempty"`
+
+	// Left 左侧的值
+	Left any `json:"left,omitempty"`
+
+	// Right 右侧的值
+	Right any `json:"right,omitempty"`
+}
+
+// Diff 两棵运行树的比较结果
+type Diff struct {
+	// LeftID 左侧根运行 ID
+	LeftID string `json:"left_id"`
+
+	// RightID 右侧根运行 ID
+	RightID string `json:"right_id"`
+
+	// LeftDuration 左侧耗时
+	LeftDuration time.Duration `json:"left_duration"`
+
+	// RightDuration 右侧耗时
+	RightDuration time.Duration `json:"right_duration"`
+
+	// Changes 差异列表，按路径的先序遍历顺序排列
+	Changes []Change `json:"changes"`
+}
+
+// DiffRuns 比较两棵运行树（BuildTree 的结果）。
+//
+// 子运行按名称对齐：同一父运行下第 n 个同名的子运行互相比较。
+// 耗时总是不同，只在 Diff 中给出根运行的耗时，不作为差异。
+//
+// 参数：
+//   - left: 左侧根运行
+//   - right: 右侧根运行
+//
+// 返回：
+//   - *Diff: 比较结果
+//
+func DiffRuns(left, right *Run) *Diff {
+	diff := &Diff{
+		LeftID:        left.ID,
+		RightID:       right.ID,
+		LeftDuration:  left.Duration(),
+		RightDuration: right.Duration(),
+		Changes:       []Change{},
+	}
+	diffRun(diff, left.Name, left, right)
+	return diff
+}
+
+// diffRun 比较两个对齐的运行及其子运行
+func diffRun(diff *Diff, path string, left, right *Run) {
+	modified := func(field string, l, r any) {
+		diff.Changes = append(diff.Changes, Change{Path: path, Kind: ChangeModified, Field: field, Left: l, Right: r})
+	}
+
+	if left.Type != right.Type {
+		modified("type", left.Type, right.Type)
+	}
+	if left.Status != right.Status {
+		modified("status", left.Status, right.Status)
+	}
+	if left.Error != right.Error {
+		modified("error", left.Error, right.Error)
+	}
+	if !jsonEqual(left.Inputs, right.Inputs) {
+		modified("inputs", left.Inputs, right.Inputs)
+	}
+	if !jsonEqual(left.Outputs, right.Outputs) {
+		modified("outputs", left.Outputs, right.Outputs)
+	}
+	if !reflect.DeepEqual(left.Usage, right.Usage) {
+		modified("usage", left.Usage, right.Usage)
+	}
+
+	leftChildren := keyChildren(left.Children)
+	rightChildren := keyChildren(right.Children)
+
+	for _, child := range left.Children {
+		key := leftChildren.keys[child]
+		childPath := path + "/" + key
+		if match, ok := rightChildren.byKey[key]; ok {
+			diffRun(diff, childPath, child, match)
+		} else {
+			diff.Changes = append(diff.Changes, Change{Path: childPath, Kind: ChangeRemoved, Left: child.ID})
+		}
+	}
+	for _, child := range right.Children {
+		key := rightChildren.keys[child]
+		if _, ok := leftChildren.byKey[key]; !ok {
+			diff.Changes = append(diff.Changes, Change{Path: path + "/" + key, Kind: ChangeAdded, Right: child.ID})
+		}
+	}
+}
+
+// childKeys 子运行的对齐键
+type childKeys struct {
+	keys  map[*Run]string
+	byKey map[string]*Run
+}
+
+// keyChildren 为子运行生成 "名称[序号]" 形式的对齐键
+func keyChildren(children []*Run) childKeys {
+	keys := childKeys{
+		keys:  make(map[*Run]string, len(children)),
+		byKey: make(map[string]*Run, len(children)),
+	}
+	counts := make(map[string]int)
+	for _, child := range children {
+		key := fmt.Sprintf("%s[%d]", child.Name, counts[child.Name])
+		counts[child.Name]++
+		keys.keys[child] = key
+		keys.byKey[key] = child
+	}
+	return keys
+}
+
+// jsonEqual 判断两个 JSON 值是否相等（忽略格式和键顺序）
+func jsonEqual(a, b json.RawMessage) bool {
+	if bytes.Equal(a, b) {
+		return true
+	}
+	var va, vb any
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
@@ -0,0 +1,42 @@
+// Package tracing 记录运行树，并提供本地查看器。
+//
+// Recorder 是一个回调处理器（callbacks.Handler），把链、Agent、图、模型、工具、
+// 检索器和向量存储的每次运行写入 Store：输入、输出、耗时、token 用量、错误、
+// 标签和元数据，运行之间通过父运行 ID 组成运行树。NewHandler 提供查看器页面和
+// JSON API，用于浏览、筛选和比较运行。
+//
+// # 存储
+//
+//   - MemoryStore: 内存存储，适合开发和测试
+//   - NewSQLiteStore: SQLite 存储（需要 sqlite 构建标签）
+//   - NewPostgresStore: PostgreSQL 存储（需要 postgres 构建标签）
+//   - NewSQLStore: 使用已打开的 *sql.DB
+//
+// # 使用示例
+//
+//	store, _ := tracing.NewSQLiteStore("runs.db")
+//	defer store.Close()
+//
+//	recorder := tracing.NewRecorder(store)
+//	defer recorder.Close()
+//
+//	// 记录所有运行
+//	callbacks.AddGlobalHandlers(recorder)
+//
+//	// 或者只记录某次调用
+//	result, err := executor.Execute(callbacks.WithHandlers(ctx, recorder), "今天天气如何？")
+//
+//	// 查看器
+//	http.Handle("/traces/", http.StripPrefix("/traces", tracing.NewHandler(store)))
+//
+// 旧记录可以定期清理：
+//
+//	store.DeleteBefore(ctx, time.Now().Add(-7*24*time.Hour))
+//
+// # 注意
+//
+// 运行开始和结束时各保存一次，保存在后台按顺序进行；需要立即读到记录时调用
+// Recorder.Flush。输入输出以 JSON 保存，不能序列化的值保存为 fmt 格式的字符串。
+// 运行中的模型调用、工具输入等可能包含敏感信息，查看器应部署在受保护的路径下。
+//
+package tracing
@@ -0,0 +1,225 @@
+package tracing
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/zhucl121/langchain-go/core/callbacks"
+)
+
+//go:embed viewer.html
+var viewerHTML []byte
+
+// NewHandler 创建运行记录查看器的 HTTP 处理器
+//
+// 提供一个查看器页面和 JSON API：
+//
+//	GET /                       查看器页面
+//	GET /api/runs               查询运行（参数见下），默认只返回顶层运行
+//	GET /api/runs/{id}          运行详情
+//	GET /api/traces/{id}        运行树（根运行数组，子运行在 children 中）
+//	GET /api/diff?left=&right=  比较两个运行及其子运行（参数为运行 ID，可以是任意层级的运行）
+//
+// /api/runs 的参数：trace_id、root（默认 true，指定 trace_id 时默认 false）、
+// type、name、status、tag、since、until（RFC 3339）、limit、offset。
+//
+// 挂载到子路径时使用 http.StripPrefix，并保证子路径以 / 结尾：
+//
+//	mux.Handle("/traces/", http.StripPrefix("/traces", tracing.NewHandler(store)))
+//
+// 参数：
+//   - store: 运行记录存储
+//
+// 返回：
+//   - http.Handler: HTTP 处理器
+//
+func NewHandler(store Store) http.Handler {
+	h := &httpHandler{store: store}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", h.viewer)
+	mux.HandleFunc("GET /api/runs", h.listRuns)
+	mux.HandleFunc("GET /api/runs/{id}", h.getRun)
+	mux.HandleFunc("GET /api/traces/{id}", h.getTrace)
+	mux.HandleFunc("GET /api/diff", h.diff)
+	return mux
+}
+
+// httpHandler 运行记录查看器
+type httpHandler struct {
+	store Store
+}
+
+// viewer 返回查看器页面
+func (h *httpHandler) viewer(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(viewerHTML)
+}
+
+// listRuns 查询运行
+func (h *httpHandler) listRuns(w http.ResponseWriter, r *http.Request) {
+	query, err := parseQuery(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	runs, err := h.store.ListRuns(r.Context(), query)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, runs)
+}
+
+// getRun 返回运行详情
+func (h *httpHandler) getRun(w http.ResponseWriter, r *http.Request) {
+	run, err := h.store.GetRun(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	writeJSON(w, run)
+}
+
+// getTrace 返回运行树
+func (h *httpHandler) getTrace(w http.ResponseWriter, r *http.Request) {
+	roots, err := h.trace(r, r.PathValue("id"))
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	writeJSON(w, roots)
+}
+
+// diff 比较两个运行及其子运行
+func (h *httpHandler) diff(w http.ResponseWriter, r *http.Request) {
+	leftID, rightID := r.URL.Query().Get("left"), r.URL.Query().Get("right")
+	if leftID == "" || rightID == "" {
+		writeError(w, http.StatusBadRequest, errors.New("left and right are required"))
+		return
+	}
+
+	left, err := h.subtree(r, leftID)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	right, err := h.subtree(r, rightID)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	writeJSON(w, DiffRuns(left, right))
+}
+
+// subtree 读取运行所在的运行树，返回以该运行为根的子树
+func (h *httpHandler) subtree(r *http.Request, runID string) (*Run, error) {
+	run, err := h.store.GetRun(r.Context(), runID)
+	if err != nil {
+		return nil, err
+	}
+	roots, err := h.trace(r, run.TraceID)
+	if err != nil {
+		return nil, err
+	}
+	if found := findRun(roots, runID); found != nil {
+		return found, nil
+	}
+	return nil, ErrRunNotFound
+}
+
+// findRun 在运行树中查找运行
+func findRun(runs []*Run, id string) *Run {
+	for _, run := range runs {
+		if run.ID == id {
+			return run
+		}
+		if found := findRun(run.Children, id); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// trace 读取运行树，没有运行时返回 ErrRunNotFound
+func (h *httpHandler) trace(r *http.Request, traceID string) ([]*Run, error) {
+	runs, err := h.store.GetTrace(r.Context(), traceID)
+	if err != nil {
+		return nil, err
+	}
+	if len(runs) == 0 {
+		return nil, ErrRunNotFound
+	}
+	return BuildTree(runs), nil
+}
+
+// parseQuery 从请求参数解析查询条件
+func parseQuery(r *http.Request) (*Query, error) {
+	params := r.URL.Query()
+	query := &Query{
+		TraceID: params.Get("trace_id"),
+		Type:    callbacks.RunType(params.Get("type")),
+		Name:    params.Get("name"),
+		Status:  RunStatus(params.Get("status")),
+		Tag:     params.Get("tag"),
+	}
+
+	query.RootOnly = query.TraceID == ""
+	if root := params.Get("root"); root != "" {
+		v, err := strconv.ParseBool(root)
+		if err != nil {
+			return nil, fmt.Errorf("invalid root: %w", err)
+		}
+		query.RootOnly = v
+	}
+
+	for name, dest := range map[string]*time.Time{"since": &query.StartTime, "until": &query.EndTime} {
+		if value := params.Get(name); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", name, err)
+			}
+			*dest = t
+		}
+	}
+
+	for name, dest := range map[string]*int{"limit": &query.Limit, "offset": &query.Offset} {
+		if value := params.Get(name); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid %s: %q", name, value)
+			}
+			*dest = n
+		}
+	}
+
+	return query, nil
+}
+
+// writeJSON 写入 JSON 响应
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError 写入 JSON 错误响应
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
+
+// writeStoreError 写入存储错误，运行不存在时返回 404
+func writeStoreError(w http.ResponseWriter, err error) {
+	if errors.Is(err, ErrRunNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	writeError(w, http.StatusInternalServerError, err)
+}
@@ -0,0 +1,177 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/zhucl121/langchain-go/core/callbacks"
+)
+
+// newTestServer 记录两次 Agent 执行并启动查看器
+func newTestServer(t *testing.T) (*httptest.Server, string, string) {
+	store := NewMemoryStore()
+	recorder := NewRecorder(store)
+	ctx := callbacks.WithHandlers(context.Background(), recorder)
+
+	first := recordAgent(ctx, "answer", nil)
+	second := recordAgent(ctx, "other answer", errors.New("timeout"))
+	recorder.Close()
+
+	server := httptest.NewServer(http.StripPrefix("/traces", NewHandler(store)))
+	t.Cleanup(server.Close)
+	return server, first, second
+}
+
+// getJSON 请求 JSON API
+func getJSON(t *testing.T, url string, v any) int {
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s failed: %v", url, err)
+	}
+	defer resp.Body.Close()
+	if v != nil {
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			t.Fatalf("failed to decode %s: %v", url, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestHandler(t *testing.T) {
+	server, first, second := newTestServer(t)
+	base := server.URL + "/traces"
+
+	resp, err := http.Get(base + "/")
+	if err != nil {
+		t.Fatalf("GET / failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") {
+		t.Errorf("Expected viewer page, got %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	var runs []*Run
+	getJSON(t, base+"/api/runs", &runs)
+	if got := runIDs(runs); !equalStrings(got, []string{second, first}) {
+		t.Errorf("Expected root runs [%s %s], got %v", second, first, got)
+	}
+
+	getJSON(t, base+"/api/runs?root=false&type=tool&status=error", &runs)
+	if len(runs) != 1 || runs[0].TraceID != second {
+		t.Errorf("Expected the failed tool run, got %+v", runs)
+	}
+
+	getJSON(t, base+"/api/runs?trace_id="+first, &runs)
+	if len(runs) != 4 {
+		t.Errorf("Expected 4 runs in trace, got %d", len(runs))
+	}
+
+	var run Run
+	if status := getJSON(t, base+"/api/runs/"+first, &run); status != http.StatusOK || run.Name != "react" {
+		t.Errorf("Expected react run, got %d %+v", status, run)
+	}
+
+	var roots []*Run
+	getJSON(t, base+"/api/traces/"+first, &roots)
+	if len(roots) != 1 || len(roots[0].Children) != 1 || len(roots[0].Children[0].Children) != 2 {
+		t.Errorf("Unexpected trace tree: %+v", roots)
+	}
+
+	var errResp map[string]string
+	if status := getJSON(t, base+"/api/traces/missing", &errResp); status != http.StatusNotFound || errResp["error"] == "" {
+		t.Errorf("Expected 404 with error, got %d %v", status, errResp)
+	}
+	if status := getJSON(t, base+"/api/runs?limit=x", nil); status != http.StatusBadRequest {
+		t.Errorf("Expected 400, got %d", status)
+	}
+	if status := getJSON(t, base+"/api/diff?left="+first, nil); status != http.StatusBadRequest {
+		t.Errorf("Expected 400, got %d", status)
+	}
+}
+
+func TestHandler_Diff(t *testing.T) {
+	server, first, second := newTestServer(t)
+
+	var diff Diff
+	getJSON(t, server.URL+"/traces/api/diff?left="+first+"&right="+second, &diff)
+	if diff.LeftID != first || diff.RightID != second {
+		t.Errorf("Unexpected diff ids: %s %s", diff.LeftID, diff.RightID)
+	}
+
+	changes := make(map[string]Change)
+	for _, change := range diff.Changes {
+		changes[change.Path+" "+change.Field] = change
+	}
+	if len(changes) != 3 {
+		t.Errorf("Expected 3 changes, got %+v", diff.Changes)
+	}
+	if _, ok := changes["react outputs"]; !ok {
+		t.Errorf("Expected agent outputs change, got %+v", diff.Changes)
+	}
+	if c, ok := changes["react/agent_step[0]/search[0] status"]; !ok || c.Left != "success" || c.Right != "error" {
+		t.Errorf("Expected tool status change, got %+v", diff.Changes)
+	}
+	if _, ok := changes["react/agent_step[0]/search[0] error"]; !ok {
+		t.Errorf("Expected tool error change, got %+v", diff.Changes)
+	}
+}
+
+func TestHandler_DiffChildRuns(t *testing.T) {
+	server, first, second := newTestServer(t)
+	base := server.URL + "/traces"
+
+	// 比较两次执行中的步骤运行（不是顶层运行）
+	var left, right []*Run
+	getJSON(t, base+"/api/runs?trace_id="+first+"&name=agent_step", &left)
+	getJSON(t, base+"/api/runs?trace_id="+second+"&name=agent_step", &right)
+	if len(left) != 1 || len(right) != 1 {
+		t.Fatalf("Expected one step run per trace, got %d and %d", len(left), len(right))
+	}
+
+	var diff Diff
+	if status := getJSON(t, base+"/api/diff?left="+left[0].ID+"&right="+right[0].ID, &diff); status != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", status)
+	}
+	if diff.LeftID != left[0].ID || diff.RightID != right[0].ID {
+		t.Errorf("Unexpected diff ids: %s %s", diff.LeftID, diff.RightID)
+	}
+	if len(diff.Changes) != 2 {
+		t.Errorf("Expected tool status and error changes, got %+v", diff.Changes)
+	}
+	for _, change := range diff.Changes {
+		if change.Path != "agent_step/search[0]" {
+			t.Errorf("Expected changes on the tool run, got %+v", change)
+		}
+	}
+
+	if status := getJSON(t, base+"/api/diff?left=missing&right="+second, nil); status != http.StatusNotFound {
+		t.Errorf("Expected 404, got %d", status)
+	}
+}
+
+func TestDiffRuns_AddedRemoved(t *testing.T) {
+	left := &Run{ID: "l", Name: "graph", Children: []*Run{
+		{ID: "l1", Name: "plan"},
+		{ID: "l2", Name: "search"},
+	}}
+	right := &Run{ID: "r", Name: "graph", Children: []*Run{
+		{ID: "r1", Name: "plan"},
+		{ID: "r2", Name: "plan"},
+	}}
+
+	diff := DiffRuns(left, right)
+	if len(diff.Changes) != 2 {
+		t.Fatalf("Expected 2 changes, got %+v", diff.Changes)
+	}
+	if c := diff.Changes[0]; c.Kind != ChangeRemoved || c.Path != "graph/search[0]" || c.Left != "l2" {
+		t.Errorf("Unexpected change: %+v", c)
+	}
+	if c := diff.Changes[1]; c.Kind != ChangeAdded || c.Path != "graph/plan[1]" || c.Right != "r2" {
+		t.Errorf("Unexpected change: %+v", c)
+	}
+}
@@ -0,0 +1,361 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/zhucl121/langchain-go/core/callbacks"
+	"github.com/zhucl121/langchain-go/pkg/observability"
+	"github.com/zhucl121/langchain-go/pkg/types"
+)
+
+// ErrQueueFull 保存队列已满，运行快照被丢弃
+var ErrQueueFull = errors.New("tracing: recorder queue full")
+
+// RecorderOption 记录器选项
+type RecorderOption func(*Recorder)
+
+// WithErrorHandler 设置保存运行失败时的处理函数（默认写入全局日志）
+func WithErrorHandler(fn func(run *Run, err error)) RecorderOption {
+	return func(r *Recorder) {
+		r.onError = fn
+	}
+}
+
+// defaultQueueSize 是待保存运行队列的默认长度
+const defaultQueueSize = 1024
+
+// WithQueueSize 设置待保存运行的队列长度（默认 1024，小于等于 0 时使用默认值）
+//
+// 队列满时新的运行快照被丢弃，回调不会等待，参见 Recorder.Dropped。
+func WithQueueSize(size int) RecorderOption {
+	return func(r *Recorder) {
+		if size <= 0 {
+			size = defaultQueueSize
+		}
+		r.queueSize = size
+	}
+}
+
+// Recorder 把运行记录到 Store 的回调处理器
+//
+// 运行开始和结束时各保存一次，运行中的记录状态为 RunStatusRunning。
+// 保存在后台 goroutine 中按顺序进行，不阻塞组件的执行：
+// 队列满时丢弃新的快照，并以 ErrQueueFull 调用错误处理函数。
+// 模型调用的 token 用量会累加到所有祖先运行上。
+type Recorder struct {
+	callbacks.BaseHandler
+
+	store     Store
+	onError   func(run *Run, err error)
+	queueSize int
+
+	mu     sync.Mutex
+	active map[string]*Run // 运行中的运行
+
+	queue     chan recorderItem
+	dropped   atomic.Int64
+	stop      chan struct{} // Close 时关闭
+	closeOnce sync.Once
+	done      chan struct{} // 后台 goroutine 退出时关闭
+}
+
+// recorderItem 保存队列中的一项
+type recorderItem struct {
+	run   *Run
+	flush chan struct{}
+}
+
+// NewRecorder 创建运行记录器
+//
+// 参数：
+//   - store: 运行记录存储
+//   - opts: 选项
+//
+// 返回：
+//   - *Recorder: 回调处理器，通过 callbacks.WithHandlers 或 callbacks.AddGlobalHandlers 使用，
+//     不再使用时调用 Close
+//
+func NewRecorder(store Store, opts ...RecorderOption) *Recorder {
+	r := &Recorder{
+		store:     store,
+		queueSize: defaultQueueSize,
+		active:    make(map[string]*Run),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if r.onError == nil {
+		r.onError = func(run *Run, err error) {
+			observability.Error("failed to save run",
+				observability.String("run_id", run.ID),
+				observability.String("run_name", run.Name),
+				observability.Err(err))
+		}
+	}
+
+	r.queue = make(chan recorderItem, r.queueSize)
+	go r.loop()
+	return r
+}
+
+// loop 按顺序保存队列中的运行，Close 之后保存剩余的运行并退出
+func (r *Recorder) loop() {
+	defer close(r.done)
+	for {
+		select {
+		case item := <-r.queue:
+			r.handle(item)
+		case <-r.stop:
+			for {
+				select {
+				case item := <-r.queue:
+					r.handle(item)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// handle 保存队列中的一项
+func (r *Recorder) handle(item recorderItem) {
+	if item.flush != nil {
+		close(item.flush)
+		return
+	}
+	if err := r.store.SaveRun(context.Background(), item.run); err != nil {
+		r.onError(item.run, err)
+	}
+}
+
+// Flush 等待已经开始或结束的运行全部保存。
+//
+// 与回调不同，队列满时 Flush 会等待队列有空位。
+func (r *Recorder) Flush() {
+	flush := make(chan struct{})
+	select {
+	case r.queue <- recorderItem{flush: flush}:
+	case <-r.stop:
+		return
+	}
+	select {
+	case <-flush:
+	case <-r.done:
+	}
+}
+
+// Close 保存队列中剩余的运行并停止后台 goroutine，不关闭 Store。
+//
+// Close 之后的运行不再记录。
+func (r *Recorder) Close() error {
+	r.closeOnce.Do(func() { close(r.stop) })
+	<-r.done
+	return nil
+}
+
+// Dropped 返回因队列已满而丢弃的运行快照数量。
+func (r *Recorder) Dropped() int64 {
+	return r.dropped.Load()
+}
+
+// save 把运行的快照加入保存队列，不等待
+//
+// 已关闭时忽略；队列满时丢弃快照，计入 Dropped 并调用错误处理函数。
+func (r *Recorder) save(run *Run) {
+	select {
+	case <-r.stop:
+		return
+	default:
+	}
+
+	select {
+	case r.queue <- recorderItem{run: run}:
+	default:
+		r.dropped.Add(1)
+		r.onError(run, ErrQueueFull)
+	}
+}
+
+// begin 记录运行开始
+func (r *Recorder) begin(info callbacks.RunInfo, inputs any) {
+	run := &Run{
+		ID:        info.RunID,
+		ParentID:  info.ParentRunID,
+		TraceID:   info.TraceID,
+		Type:      info.Type,
+		Name:      info.Name,
+		Status:    RunStatusRunning,
+		Inputs:    toJSON(inputs),
+		Tags:      info.Tags,
+		Metadata:  info.Metadata,
+		StartTime: info.StartTime,
+	}
+
+	if run.TraceID == "" {
+		run.TraceID = run.ID
+	}
+
+	r.mu.Lock()
+	r.active[run.ID] = run
+	snapshot := run.clone()
+	r.mu.Unlock()
+
+	r.save(snapshot)
+}
+
+// finish 记录运行结束
+func (r *Recorder) finish(info callbacks.RunInfo, outputs any, err error, endTime time.Time) {
+	r.mu.Lock()
+	run, ok := r.active[info.RunID]
+	if !ok {
+		r.mu.Unlock()
+		return
+	}
+	delete(r.active, info.RunID)
+
+	run.Outputs = toJSON(outputs)
+	run.EndTime = endTime
+	run.Status = RunStatusSuccess
+	if err != nil {
+		run.Status = RunStatusError
+		run.Error = err.Error()
+	}
+	snapshot := run.clone()
+	r.mu.Unlock()
+
+	r.save(snapshot)
+}
+
+// addEvent 在运行中的运行上记录事件
+func (r *Recorder) addEvent(runID string, name string, t time.Time, data any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if run, ok := r.active[runID]; ok {
+		run.Events = append(run.Events, RunEvent{Name: name, Time: t, Data: toJSON(data)})
+	}
+}
+
+// addUsage 把 token 用量累加到运行及其运行中的祖先上
+func (r *Recorder) addUsage(runID string, usage *types.UsageMetadata) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for run, ok := r.active[runID]; ok; run, ok = r.active[run.ParentID] {
+		run.Usage = run.Usage.Add(usage)
+	}
+}
+
+// OnLLMStart 实现 callbacks.Handler
+func (r *Recorder) OnLLMStart(ctx context.Context, event *callbacks.LLMStartEvent) {
+	inputs := map[string]any{
+		"provider": event.Provider,
+		"model":    event.Model,
+		"messages": event.Messages,
+	}
+	if len(event.Tools) > 0 {
+		tools := make([]string, len(event.Tools))
+		for i, tool := range event.Tools {
+			tools[i] = tool.Name
+		}
+		inputs["tools"] = tools
+	}
+	r.begin(event.RunInfo, inputs)
+}
+
+// OnLLMToken 实现 callbacks.Handler，记录第一个 token 的时间
+func (r *Recorder) OnLLMToken(ctx context.Context, event *callbacks.LLMTokenEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if run, ok := r.active[event.RunID]; ok && !hasEvent(run.Events, "first_token") {
+		run.Events = append(run.Events, RunEvent{Name: "first_token", Time: event.Time})
+	}
+}
+
+// OnLLMEnd 实现 callbacks.Handler
+func (r *Recorder) OnLLMEnd(ctx context.Context, event *callbacks.LLMEndEvent) {
+	if event.Err == nil && event.Output.UsageMetadata != nil {
+		r.addUsage(event.RunID, event.Output.UsageMetadata)
+	}
+	var outputs any
+	if event.Err == nil {
+		outputs = event.Output
+	}
+	r.finish(event.RunInfo, outputs, event.Err, event.EndTime)
+}
+
+// OnToolStart 实现 callbacks.Handler
+func (r *Recorder) OnToolStart(ctx context.Context, event *callbacks.ToolStartEvent) {
+	r.begin(event.RunInfo, event.Input)
+}
+
+// OnToolEnd 实现 callbacks.Handler
+func (r *Recorder) OnToolEnd(ctx context.Context, event *callbacks.ToolEndEvent) {
+	r.finish(event.RunInfo, event.Output, event.Err, event.EndTime)
+}
+
+// OnRetrieverStart 实现 callbacks.Handler
+func (r *Recorder) OnRetrieverStart(ctx context.Context, event *callbacks.RetrieverStartEvent) {
+	r.begin(event.RunInfo, map[string]any{"query": event.Query})
+}
+
+// OnRetrieverEnd 实现 callbacks.Handler
+func (r *Recorder) OnRetrieverEnd(ctx context.Context, event *callbacks.RetrieverEndEvent) {
+	var outputs any
+	if event.Err == nil {
+		outputs = event.Documents
+	}
+	r.finish(event.RunInfo, outputs, event.Err, event.EndTime)
+}
+
+// OnChainStart 实现 callbacks.Handler
+func (r *Recorder) OnChainStart(ctx context.Context, event *callbacks.ChainStartEvent) {
+	r.begin(event.RunInfo, event.Inputs)
+}
+
+// OnChainEnd 实现 callbacks.Handler
+func (r *Recorder) OnChainEnd(ctx context.Context, event *callbacks.ChainEndEvent) {
+	r.finish(event.RunInfo, event.Outputs, event.Err, event.EndTime)
+}
+
+// OnNodeStart 实现 callbacks.Handler
+func (r *Recorder) OnNodeStart(ctx context.Context, event *callbacks.NodeStartEvent) {
+	r.begin(event.RunInfo, event.State)
+}
+
+// OnNodeEnd 实现 callbacks.Handler
+func (r *Recorder) OnNodeEnd(ctx context.Context, event *callbacks.NodeEndEvent) {
+	r.finish(event.RunInfo, event.State, event.Err, event.EndTime)
+}
+
+// OnAgentAction 实现 callbacks.Handler
+func (r *Recorder) OnAgentAction(ctx context.Context, event *callbacks.AgentActionEvent) {
+	r.addEvent(event.RunID, "agent_action", event.Time, map[string]any{
+		"tool":       event.Tool,
+		"tool_input": event.ToolInput,
+		"log":        event.Log,
+	})
+}
+
+// OnAgentFinish 实现 callbacks.Handler
+func (r *Recorder) OnAgentFinish(ctx context.Context, event *callbacks.AgentFinishEvent) {
+	r.addEvent(event.RunID, "agent_finish", event.Time, map[string]any{
+		"output": event.Output,
+		"log":    event.Log,
+	})
+}
+
+// hasEvent 判断是否已有指定名称的事件
+func hasEvent(events []RunEvent, name string) bool {
+	for _, event := range events {
+		if event.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,242 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/zhucl121/langchain-go/core/callbacks"
+	"github.com/zhucl121/langchain-go/pkg/types"
+)
+
+// recordAgent 模拟一次 Agent 执行：Agent → 步骤 → 模型调用、工具调用
+func recordAgent(ctx context.Context, answer string, toolErr error) string {
+	ctx, agent := callbacks.StartAgent(callbacks.WithTags(ctx, "prod"), "react", "question")
+	stepCtx, step := callbacks.StartChain(ctx, "agent_step", 1)
+
+	llmCtx, llm := callbacks.StartLLM(stepCtx, "gpt-4o", callbacks.LLMStartEvent{
+		Provider: "openai",
+		Model:    "gpt-4o",
+		Messages: []types.Message{types.NewUserMessage("question")},
+	})
+	llm.LLMToken(llmCtx, "hel")
+	llm.LLMToken(llmCtx, "lo")
+	output := types.NewAssistantMessage("hello")
+	output.UsageMetadata = &types.UsageMetadata{InputTokens: 10, OutputTokens: 2, TotalTokens: 12}
+	llm.EndLLM(llmCtx, output, nil)
+	step.AgentAction(stepCtx, "search", map[string]any{"query": "go"}, "")
+
+	toolCtx, tool := callbacks.StartTool(stepCtx, "search", map[string]any{"query": "go"})
+	tool.EndTool(toolCtx, "result", toolErr)
+
+	step.EndChain(stepCtx, nil, nil)
+	agent.AgentFinish(ctx, answer, "")
+	agent.EndChain(ctx, answer, nil)
+	return agent.ID()
+}
+
+func TestRecorder(t *testing.T) {
+	store := NewMemoryStore()
+	recorder := NewRecorder(store)
+	defer recorder.Close()
+
+	traceID := recordAgent(callbacks.WithHandlers(context.Background(), recorder), "answer", errors.New("timeout"))
+	recorder.Flush()
+
+	runs, err := store.GetTrace(context.Background(), traceID)
+	if err != nil {
+		t.Fatalf("GetTrace failed: %v", err)
+	}
+	if len(runs) != 4 {
+		t.Fatalf("Expected 4 runs, got %d", len(runs))
+	}
+
+	roots := BuildTree(runs)
+	if len(roots) != 1 {
+		t.Fatalf("Expected 1 root, got %d", len(roots))
+	}
+	agent := roots[0]
+	if agent.Type != callbacks.RunTypeAgent || agent.Status != RunStatusSuccess || agent.ParentID != "" {
+		t.Errorf("Unexpected agent run: %+v", agent)
+	}
+	if string(agent.Outputs) != `"answer"` || len(agent.Tags) != 1 || agent.Tags[0] != "prod" {
+		t.Errorf("Unexpected agent outputs or tags: %s %v", agent.Outputs, agent.Tags)
+	}
+	if agent.Usage == nil || agent.Usage.TotalTokens != 12 {
+		t.Errorf("Expected usage to be aggregated on agent, got %+v", agent.Usage)
+	}
+	if len(agent.Events) != 1 || agent.Events[0].Name != "agent_finish" {
+		t.Errorf("Expected agent_finish event, got %+v", agent.Events)
+	}
+
+	step := agent.Children[0]
+	if step.Name != "agent_step" || len(step.Children) != 2 {
+		t.Fatalf("Unexpected step run: %+v", step)
+	}
+	if len(step.Events) != 1 || step.Events[0].Name != "agent_action" {
+		t.Errorf("Expected agent_action event, got %+v", step.Events)
+	}
+
+	llm, tool := step.Children[0], step.Children[1]
+	if llm.Type != callbacks.RunTypeLLM || llm.Usage == nil || llm.Usage.InputTokens != 10 {
+		t.Errorf("Unexpected llm run: %+v", llm)
+	}
+	if len(llm.Events) != 1 || llm.Events[0].Name != "first_token" {
+		t.Errorf("Expected single first_token event, got %+v", llm.Events)
+	}
+	var inputs map[string]any
+	if err := json.Unmarshal(llm.Inputs, &inputs); err != nil || inputs["model"] != "gpt-4o" {
+		t.Errorf("Unexpected llm inputs: %s", llm.Inputs)
+	}
+	if tool.Status != RunStatusError || tool.Error != "timeout" {
+		t.Errorf("Unexpected tool run: %+v", tool)
+	}
+	for _, run := range runs {
+		if run.TraceID != traceID || run.EndTime.IsZero() {
+			t.Errorf("Unexpected run %s: trace %s, end %v", run.Name, run.TraceID, run.EndTime)
+		}
+	}
+}
+
+func TestRecorder_RunningAndErrors(t *testing.T) {
+	store := NewMemoryStore()
+	var saveErrs []error
+	recorder := NewRecorder(&failingStore{Store: store}, WithErrorHandler(func(run *Run, err error) {
+		saveErrs = append(saveErrs, err)
+	}))
+
+	ctx := callbacks.WithHandlers(context.Background(), recorder)
+	ctx, chain := callbacks.StartChain(ctx, "pipeline", map[string]any{"x": 1})
+	recorder.Flush()
+
+	run, err := store.GetRun(context.Background(), chain.ID())
+	if err != nil {
+		t.Fatalf("GetRun failed: %v", err)
+	}
+	if run.Status != RunStatusRunning || string(run.Inputs) != `{"x":1}` {
+		t.Errorf("Unexpected running run: %+v", run)
+	}
+
+	// 保存失败交给错误处理函数
+	_, fail := callbacks.StartChain(ctx, "fail", nil)
+	fail.EndChain(ctx, nil, nil)
+
+	// 无法序列化的输出保存为字符串
+	_, unencodable := callbacks.StartChain(ctx, "unencodable", nil)
+	unencodable.EndChain(ctx, func() {}, nil)
+	chain.EndChain(ctx, nil, nil)
+	recorder.Close()
+
+	if len(saveErrs) != 2 {
+		t.Errorf("Expected 2 save errors, got %v", saveErrs)
+	}
+	run, err = store.GetRun(context.Background(), unencodable.ID())
+	if err != nil {
+		t.Fatalf("GetRun failed: %v", err)
+	}
+	var output string
+	if err := json.Unmarshal(run.Outputs, &output); err != nil || output == "" {
+		t.Errorf("Expected output saved as string, got %s", run.Outputs)
+	}
+
+	// Close 之后不再记录
+	_, late := callbacks.StartChain(callbacks.WithHandlers(context.Background(), recorder), "late", nil)
+	late.EndChain(ctx, nil, nil)
+	if runs, _ := store.ListRuns(context.Background(), &Query{Name: "late"}); len(runs) != 0 {
+		t.Errorf("Expected no runs after Close, got %d", len(runs))
+	}
+}
+
+func TestRecorder_TraceIDWithoutRecordedParent(t *testing.T) {
+	store := NewMemoryStore()
+	recorder := NewRecorder(store)
+	defer recorder.Close()
+
+	// 记录器只加在步骤的上下文中，Agent 和步骤本身不被记录
+	ctx, agent := callbacks.StartAgent(callbacks.WithHandlers(context.Background(), callbacks.BaseHandler{}), "react", nil)
+	stepCtx, step := callbacks.StartChain(ctx, "agent_step", 1)
+	toolCtx, tool := callbacks.StartTool(callbacks.WithHandlers(stepCtx, recorder), "search", nil)
+	tool.EndTool(toolCtx, "result", nil)
+	step.EndChain(stepCtx, nil, nil)
+	agent.EndChain(ctx, nil, nil)
+	recorder.Flush()
+
+	run, err := store.GetRun(context.Background(), tool.ID())
+	if err != nil {
+		t.Fatalf("GetRun failed: %v", err)
+	}
+	if run.TraceID != agent.ID() || run.ParentID != step.ID() {
+		t.Errorf("Expected trace %s and parent %s, got %s and %s", agent.ID(), step.ID(), run.TraceID, run.ParentID)
+	}
+}
+
+func TestRecorder_QueueFull(t *testing.T) {
+	store := &blockingStore{Store: NewMemoryStore(), started: make(chan struct{}, 1), release: make(chan struct{})}
+	var saveErrs []error
+	recorder := NewRecorder(store, WithQueueSize(1), WithErrorHandler(func(run *Run, err error) {
+		saveErrs = append(saveErrs, err)
+	}))
+
+	ctx := callbacks.WithHandlers(context.Background(), recorder)
+	_, first := callbacks.StartChain(ctx, "first", nil)
+	<-store.started
+
+	// 后台 goroutine 阻塞在第一次保存上，队列只能再放一个快照，回调不等待
+	first.EndChain(ctx, nil, nil)
+	_, second := callbacks.StartChain(ctx, "second", nil)
+	second.EndChain(ctx, nil, nil)
+
+	if recorder.Dropped() != 2 {
+		t.Errorf("Expected 2 dropped snapshots, got %d", recorder.Dropped())
+	}
+	if len(saveErrs) != 2 || !errors.Is(saveErrs[0], ErrQueueFull) {
+		t.Errorf("Expected ErrQueueFull, got %v", saveErrs)
+	}
+
+	close(store.release)
+	recorder.Close()
+	run, err := store.GetRun(context.Background(), first.ID())
+	if err != nil || run.Status != RunStatusSuccess {
+		t.Errorf("Expected first run to be saved as success, got %+v, %v", run, err)
+	}
+}
+
+func TestRecorder_QueueSizeDefault(t *testing.T) {
+	for _, size := range []int{0, -1} {
+		recorder := NewRecorder(NewMemoryStore(), WithQueueSize(size))
+		if cap(recorder.queue) != defaultQueueSize {
+			t.Errorf("WithQueueSize(%d): expected queue size %d, got %d", size, defaultQueueSize, cap(recorder.queue))
+		}
+		recorder.Close()
+	}
+}
+
+// blockingStore 第一次保存时等待 release
+type blockingStore struct {
+	Store
+	once    sync.Once
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingStore) SaveRun(ctx context.Context, run *Run) error {
+	s.once.Do(func() {
+		s.started <- struct{}{}
+		<-s.release
+	})
+	return s.Store.SaveRun(ctx, run)
+}
+
+// failingStore 保存名为 fail 的运行时失败
+type failingStore struct {
+	Store
+}
+
+func (s *failingStore) SaveRun(ctx context.Context, run *Run) error {
+	if run.Name == "fail" {
+		return errors.New("disk full")
+	}
+	return s.Store.SaveRun(ctx, run)
+}
@@ -0,0 +1,250 @@
+package tracing
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/zhucl121/langchain-go/core/callbacks"
+	"github.com/zhucl121/langchain-go/pkg/types"
+)
+
+// ErrRunNotFound 运行记录不存在
+var ErrRunNotFound = errors.New("tracing: run not found")
+
+// RunStatus 运行状态
+type RunStatus string
+
+const (
+	// RunStatusRunning 运行中
+	RunStatusRunning RunStatus = "running"
+
+	// RunStatusSuccess 成功
+	RunStatusSuccess RunStatus = "success"
+
+	// RunStatusError 失败
+	RunStatusError RunStatus = "error"
+)
+
+// RunEvent 运行过程中的事件
+//
+// 记录 Agent 的行动（"agent_action"）、最终答案（"agent_finish"）
+// 和流式输出的第一个 token（"first_token"）。
+type RunEvent struct {
+	// Name 事件名称
+	Name string `json:"name"`
+
+	// Time 事件时间
+	Time time.Time `json:"time"`
+
+	// Data 事件数据（JSON）
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+// Run 一次运行的记录
+//
+// 运行之间通过 ParentID 组成运行树，同一棵树中所有运行的 TraceID 都是顶层运行的 ID。
+type Run struct {
+	// ID 运行 ID
+	ID string `json:"id"`
+
+	// ParentID 父运行 ID，顶层运行为空
+	ParentID string `json:"parent_id,omitempty"`
+
+	// TraceID 顶层运行 ID（即 callbacks.RunInfo.TraceID，顶层运行本身不一定被记录）
+	TraceID string `json:"trace_id"`
+
+	// Type 运行类型
+	Type callbacks.RunType `json:"type"`
+
+	// Name 运行名称
+	Name string `json:"name"`
+
+	// Status 运行状态
+	Status RunStatus `json:"status"`
+
+	// Inputs 输入（JSON）
+	Inputs json.RawMessage `json:"inputs,omitempty"`
+
+	// Outputs 输出（JSON）
+	Outputs json.RawMessage `json:"outputs,omitempty"`
+
+	// Error 错误信息
+	Error string `json:"error,omitempty"`
+
+	// Tags 标签
+	Tags []string `json:"tags,omitempty"`
+
+	// Metadata 元数据
+	Metadata map[string]any `json:"metadata,omitempty"`
+
+	// Events 运行过程中的事件
+	Events []RunEvent `json:"events,omitempty"`
+
+	// Usage token 用量，模型调用为本次调用的用量，其他运行为所有子运行的合计
+	Usage *types.UsageMetadata `json:"usage,omitempty"`
+
+	// StartTime 开始时间
+	StartTime time.Time `json:"start_time"`
+
+	// EndTime 结束时间，运行中为零值
+	EndTime time.Time `json:"end_time,omitzero"`
+
+	// Children 子运行，只在 BuildTree 返回的运行树中填写
+	Children []*Run `json:"children,omitempty"`
+}
+
+// Duration 返回运行耗时，运行中返回 0。
+func (r *Run) Duration() time.Duration {
+	if r.EndTime.IsZero() {
+		return 0
+	}
+	return r.EndTime.Sub(r.StartTime)
+}
+
+// clone 返回运行的副本（不含子运行）。
+func (r *Run) clone() *Run {
+	c := *r
+	c.Tags = append([]string(nil), r.Tags...)
+	c.Events = append([]RunEvent(nil), r.Events...)
+	if r.Usage != nil {
+		usage := *r.Usage
+		c.Usage = &usage
+	}
+	c.Children = nil
+	return &c
+}
+
+// BuildTree 把运行列表组装成运行树。
+//
+// 父运行不在列表中的运行作为根返回，子运行按开始时间排序。
+//
+// 参数：
+//   - runs: 运行列表（通常为 Store.GetTrace 的结果）
+//
+// 返回：
+//   - []*Run: 根运行，Children 中为子运行
+//
+func BuildTree(runs []*Run) []*Run {
+	byID := make(map[string]*Run, len(runs))
+	for _, run := range runs {
+		run.Children = nil
+		byID[run.ID] = run
+	}
+
+	var roots []*Run
+	for _, run := range runs {
+		if parent, ok := byID[run.ParentID]; ok && run.ParentID != "" {
+			parent.Children = append(parent.Children, run)
+		} else {
+			roots = append(roots, run)
+		}
+	}
+
+	var sortRuns func(runs []*Run)
+	sortRuns = func(runs []*Run) {
+		sort.SliceStable(runs, func(i, j int) bool {
+			return runs[i].StartTime.Before(runs[j].StartTime)
+		})
+		for _, run := range runs {
+			sortRuns(run.Children)
+		}
+	}
+	sortRuns(roots)
+
+	return roots
+}
+
+// Query 运行查询条件
+type Query struct {
+	// TraceID 只返回该运行树中的运行（可选）
+	TraceID string
+
+	// RootOnly 只返回顶层运行
+	RootOnly bool
+
+	// Type 运行类型（可选）
+	Type callbacks.RunType
+
+	// Name 运行名称（可选）
+	Name string
+
+	// Status 运行状态（可选）
+	Status RunStatus
+
+	// Tag 包含该标签（可选）
+	Tag string
+
+	// StartTime 开始时间不早于（可选）
+	StartTime time.Time
+
+	// EndTime 开始时间早于（可选）
+	EndTime time.Time
+
+	// Limit 返回数量限制（0 表示默认 100）
+	Limit int
+
+	// Offset 偏移量
+	Offset int
+}
+
+// limit 返回查询的数量限制
+func (q *Query) limit() int {
+	if q.Limit <= 0 {
+		return 100
+	}
+	return q.Limit
+}
+
+// match 判断运行是否满足查询条件
+func (q *Query) match(run *Run) bool {
+	if q.TraceID != "" && run.TraceID != q.TraceID {
+		return false
+	}
+	if q.RootOnly && run.ParentID != "" {
+		return false
+	}
+	if q.Type != "" && run.Type != q.Type {
+		return false
+	}
+	if q.Name != "" && run.Name != q.Name {
+		return false
+	}
+	if q.Status != "" && run.Status != q.Status {
+		return false
+	}
+	if q.Tag != "" && !containsString(run.Tags, q.Tag) {
+		return false
+	}
+	if !q.StartTime.IsZero() && run.StartTime.Before(q.StartTime) {
+		return false
+	}
+	if !q.EndTime.IsZero() && !run.StartTime.Before(q.EndTime) {
+		return false
+	}
+	return true
+}
+
+// containsString 判断切片中是否包含 s
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// toJSON 把值序列化为 JSON，无法序列化时记录为字符串
+func toJSON(v any) json.RawMessage {
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		data, _ = json.Marshal(fmt.Sprintf("%v", v))
+	}
+	return data
+}
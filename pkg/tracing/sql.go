@@ -0,0 +1,310 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zhucl121/langchain-go/core/callbacks"
+)
+
+// Dialect SQL 方言
+type Dialect string
+
+const (
+	// DialectSQLite SQLite
+	DialectSQLite Dialect = "sqlite"
+
+	// DialectPostgres PostgreSQL
+	DialectPostgres Dialect = "postgres"
+)
+
+// SQLStore 基于 database/sql 的运行记录存储
+//
+// 运行保存在 trace_runs 表中，输入、输出、标签、元数据、事件和 token 用量以 JSON 文本保存。
+// 通常通过 NewSQLiteStore 或 NewPostgresStore 创建。
+type SQLStore struct {
+	db      *sql.DB
+	dialect Dialect
+}
+
+// NewSQLStore 使用已打开的数据库创建运行记录存储，并创建表结构。
+//
+// 参数：
+//   - db: 数据库连接
+//   - dialect: SQL 方言
+//
+// 返回：
+//   - *SQLStore: 存储实例
+//   - error: 创建表结构失败时返回错误
+//
+func NewSQLStore(db *sql.DB, dialect Dialect) (*SQLStore, error) {
+	if dialect != DialectSQLite && dialect != DialectPostgres {
+		return nil, fmt.Errorf("tracing: unsupported dialect %q", dialect)
+	}
+
+	store := &SQLStore{db: db, dialect: dialect}
+	if err := store.initSchema(); err != nil {
+		return nil, fmt.Errorf("failed to initialize schema: %w", err)
+	}
+	return store, nil
+}
+
+// initSchema 初始化表结构
+func (s *SQLStore) initSchema() error {
+	schema := []string{
+		`CREATE TABLE IF NOT EXISTS trace_runs (
+			id TEXT PRIMARY KEY,
+			parent_id TEXT NOT NULL DEFAULT '',
+			trace_id TEXT NOT NULL,
+			run_type TEXT NOT NULL,
+			name TEXT NOT NULL,
+			status TEXT NOT NULL,
+			inputs TEXT,
+			outputs TEXT,
+			error TEXT NOT NULL DEFAULT '',
+			tags TEXT,
+			metadata TEXT,
+			events TEXT,
+			usage TEXT,
+			start_time BIGINT NOT NULL,
+			end_time BIGINT NOT NULL DEFAULT 0
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_trace_runs_trace ON trace_runs(trace_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_trace_runs_parent_start ON trace_runs(parent_id, start_time DESC)`,
+		`CREATE INDEX IF NOT EXISTS idx_trace_runs_start ON trace_runs(start_time DESC)`,
+	}
+	for _, stmt := range schema {
+		if _, err := s.db.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rebind 把 ? 占位符转换为方言的占位符
+func (s *SQLStore) rebind(query string) string {
+	if s.dialect != DialectPostgres {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, c := range query {
+		if c == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// runColumns 查询的列
+const runColumns = `id, parent_id, trace_id, run_type, name, status, inputs, outputs, error,
+	tags, metadata, events, usage, start_time, end_time`
+
+// SaveRun 实现 Store 接口
+func (s *SQLStore) SaveRun(ctx context.Context, run *Run) error {
+	var endTime int64
+	if !run.EndTime.IsZero() {
+		endTime = run.EndTime.UnixNano()
+	}
+
+	query := s.rebind(`INSERT INTO trace_runs (` + runColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			status = excluded.status,
+			outputs = excluded.outputs,
+			error = excluded.error,
+			metadata = excluded.metadata,
+			events = excluded.events,
+			usage = excluded.usage,
+			end_time = excluded.end_time`)
+
+	_, err := s.db.ExecContext(ctx, query,
+		run.ID, run.ParentID, run.TraceID, string(run.Type), run.Name, string(run.Status),
+		nullJSON(run.Inputs), nullJSON(run.Outputs), run.Error,
+		nullJSON(toJSON(run.Tags)), nullJSON(toJSON(run.Metadata)), nullJSON(toJSON(run.Events)),
+		nullJSON(toJSON(run.Usage)), run.StartTime.UnixNano(), endTime,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save run: %w", err)
+	}
+	return nil
+}
+
+// GetRun 实现 Store 接口
+func (s *SQLStore) GetRun(ctx context.Context, id string) (*Run, error) {
+	row := s.db.QueryRowContext(ctx, s.rebind(`SELECT `+runColumns+` FROM trace_runs WHERE id = ?`), id)
+	run, err := scanRun(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrRunNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get run: %w", err)
+	}
+	return run, nil
+}
+
+// likeEscaper 转义 LIKE 模式中的通配符和转义字符（ESCAPE '\'）
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLike 返回按字面匹配 s 的 LIKE 模式片段
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// ListRuns 实现 Store 接口
+func (s *SQLStore) ListRuns(ctx context.Context, query *Query) ([]*Run, error) {
+	if query == nil {
+		query = &Query{}
+	}
+
+	var conditions []string
+	var args []any
+	add := func(condition string, arg any) {
+		conditions = append(conditions, condition)
+		args = append(args, arg)
+	}
+	if query.TraceID != "" {
+		add("trace_id = ?", query.TraceID)
+	}
+	if query.RootOnly {
+		add("parent_id = ?", "")
+	}
+	if query.Type != "" {
+		add("run_type = ?", string(query.Type))
+	}
+	if query.Name != "" {
+		add("name = ?", query.Name)
+	}
+	if query.Status != "" {
+		add("status = ?", string(query.Status))
+	}
+	if query.Tag != "" {
+		// 标签以 JSON 数组保存
+		add(`tags LIKE ? ESCAPE '\'`, "%"+escapeLike(string(toJSON(query.Tag)))+"%")
+	}
+	if !query.StartTime.IsZero() {
+		add("start_time >= ?", query.StartTime.UnixNano())
+	}
+	if !query.EndTime.IsZero() {
+		add("start_time < ?", query.EndTime.UnixNano())
+	}
+
+	stmt := `SELECT ` + runColumns + ` FROM trace_runs`
+	if len(conditions) > 0 {
+		stmt += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	stmt += ` ORDER BY start_time DESC LIMIT ? OFFSET ?`
+	args = append(args, query.limit(), query.Offset)
+
+	return s.queryRuns(ctx, s.rebind(stmt), args...)
+}
+
+// GetTrace 实现 Store 接口
+func (s *SQLStore) GetTrace(ctx context.Context, traceID string) ([]*Run, error) {
+	return s.queryRuns(ctx,
+		s.rebind(`SELECT `+runColumns+` FROM trace_runs WHERE trace_id = ? ORDER BY start_time`),
+		traceID)
+}
+
+// DeleteBefore 实现 Store 接口
+func (s *SQLStore) DeleteBefore(ctx context.Context, before time.Time) (int, error) {
+	// 没有保存顶层运行的运行树（如顶层运行的快照被丢弃）按所有运行的开始时间判断
+	result, err := s.db.ExecContext(ctx, s.rebind(`DELETE FROM trace_runs WHERE trace_id IN (
+		SELECT id FROM trace_runs WHERE parent_id = '' AND start_time < ?)
+		OR (start_time < ? AND trace_id NOT IN (
+		SELECT trace_id FROM trace_runs WHERE parent_id = '' OR start_time >= ?))`),
+		before.UnixNano(), before.UnixNano(), before.UnixNano())
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete runs: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(deleted), nil
+}
+
+// Close 关闭数据库连接
+func (s *SQLStore) Close() error {
+	return s.db.Close()
+}
+
+// queryRuns 执行查询并读取运行
+func (s *SQLStore) queryRuns(ctx context.Context, query string, args ...any) ([]*Run, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := []*Run{}
+	for rows.Next() {
+		run, err := scanRun(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan run: %w", err)
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+// scanRun 从一行中读取运行
+func scanRun(row interface{ Scan(dest ...any) error }) (*Run, error) {
+	var (
+		run                                            Run
+		runType, status                                string
+		inputs, outputs, tags, metadata, events, usage sql.NullString
+		startTime, endTime                             int64
+	)
+	err := row.Scan(&run.ID, &run.ParentID, &run.TraceID, &runType, &run.Name, &status,
+		&inputs, &outputs, &run.Error, &tags, &metadata, &events, &usage, &startTime, &endTime)
+	if err != nil {
+		return nil, err
+	}
+
+	run.Type = callbacks.RunType(runType)
+	run.Status = RunStatus(status)
+	if inputs.Valid {
+		run.Inputs = json.RawMessage(inputs.String)
+	}
+	if outputs.Valid {
+		run.Outputs = json.RawMessage(outputs.String)
+	}
+	for _, field := range []struct {
+		value sql.NullString
+		dest  any
+	}{
+		{tags, &run.Tags},
+		{metadata, &run.Metadata},
+		{events, &run.Events},
+		{usage, &run.Usage},
+	} {
+		if field.value.Valid {
+			if err := json.Unmarshal([]byte(field.value.String), field.dest); err != nil {
+				return nil, err
+			}
+		}
+	}
+	run.StartTime = time.Unix(0, startTime)
+	if endTime != 0 {
+		run.EndTime = time.Unix(0, endTime)
+	}
+	return &run, nil
+}
+
+// nullJSON 把空 JSON 转换为 NULL
+func nullJSON(data json.RawMessage) any {
+	if len(data) == 0 || string(data) == "null" {
+		return nil
+	}
+	return string(data)
+}
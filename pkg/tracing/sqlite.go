@@ -0,0 +1,37 @@
+// +build sqlite
+
+package tracing
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// NewSQLiteStore 创建 SQLite 运行记录存储。
+//
+// 适用于本地开发和单机部署。
+//
+// 参数：
+//   - dbPath: 数据库文件路径（":memory:" 表示内存数据库）
+//
+// 返回：
+//   - *SQLStore: 存储实例
+//   - error: 创建错误
+//
+func NewSQLiteStore(dbPath string) (*SQLStore, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	// SQLite 同一时间只允许一个写入者
+	db.SetMaxOpenConns(1)
+
+	store, err := NewSQLStore(db, DialectSQLite)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return store, nil
+}
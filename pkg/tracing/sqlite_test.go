@@ -0,0 +1,15 @@
+// +build sqlite
+
+package tracing
+
+import "testing"
+
+func TestSQLiteStore(t *testing.T) {
+	store, err := NewSQLiteStore(":memory:")
+	if err != nil {
+		t.Fatalf("NewSQLiteStore failed: %v", err)
+	}
+	defer store.Close()
+
+	testStore(t, store)
+}
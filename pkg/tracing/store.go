@@ -0,0 +1,146 @@
+package tracing
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Store 运行记录存储接口
+type Store interface {
+	// SaveRun 保存运行，ID 已存在时覆盖
+	SaveRun(ctx context.Context, run *Run) error
+
+	// GetRun 获取运行，不存在时返回 ErrRunNotFound
+	GetRun(ctx context.Context, id string) (*Run, error)
+
+	// ListRuns 查询运行，按开始时间倒序
+	ListRuns(ctx context.Context, query *Query) ([]*Run, error)
+
+	// GetTrace 获取运行树中的所有运行，按开始时间排序
+	GetTrace(ctx context.Context, traceID string) ([]*Run, error)
+
+	// DeleteBefore 删除顶层运行早于 before 开始的运行树，返回删除的运行数。
+	// 没有保存顶层运行的运行树在其中所有运行都早于 before 开始时删除。
+	DeleteBefore(ctx context.Context, before time.Time) (int, error)
+
+	// Close 关闭存储
+	Close() error
+}
+
+// MemoryStore 内存运行记录存储
+//
+// 适用于开发和测试，进程退出后记录丢失。
+type MemoryStore struct {
+	mu   sync.RWMutex
+	runs map[string]*Run
+}
+
+// NewMemoryStore 创建内存运行记录存储
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		runs: make(map[string]*Run),
+	}
+}
+
+// SaveRun 实现 Store 接口
+func (s *MemoryStore) SaveRun(ctx context.Context, run *Run) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.runs[run.ID] = run.clone()
+	return nil
+}
+
+// GetRun 实现 Store 接口
+func (s *MemoryStore) GetRun(ctx context.Context, id string) (*Run, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	run, ok := s.runs[id]
+	if !ok {
+		return nil, ErrRunNotFound
+	}
+	return run.clone(), nil
+}
+
+// ListRuns 实现 Store 接口
+func (s *MemoryStore) ListRuns(ctx context.Context, query *Query) ([]*Run, error) {
+	if query == nil {
+		query = &Query{}
+	}
+
+	s.mu.RLock()
+	var runs []*Run
+	for _, run := range s.runs {
+		if query.match(run) {
+			runs = append(runs, run.clone())
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].StartTime.After(runs[j].StartTime)
+	})
+
+	if query.Offset >= len(runs) {
+		return []*Run{}, nil
+	}
+	runs = runs[query.Offset:]
+	if limit := query.limit(); len(runs) > limit {
+		runs = runs[:limit]
+	}
+	return runs, nil
+}
+
+// GetTrace 实现 Store 接口
+func (s *MemoryStore) GetTrace(ctx context.Context, traceID string) ([]*Run, error) {
+	s.mu.RLock()
+	var runs []*Run
+	for _, run := range s.runs {
+		if run.TraceID == traceID {
+			runs = append(runs, run.clone())
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].StartTime.Before(runs[j].StartTime)
+	})
+	return runs, nil
+}
+
+// DeleteBefore 实现 Store 接口
+func (s *MemoryStore) DeleteBefore(ctx context.Context, before time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expired := make(map[string]bool)
+	rooted := make(map[string]bool)
+	recent := make(map[string]bool)
+	for _, run := range s.runs {
+		if run.ParentID == "" {
+			rooted[run.ID] = true
+			if run.StartTime.Before(before) {
+				expired[run.ID] = true
+			}
+		}
+		if !run.StartTime.Before(before) {
+			recent[run.TraceID] = true
+		}
+	}
+
+	deleted := 0
+	for id, run := range s.runs {
+		orphan := !rooted[run.TraceID] && !recent[run.TraceID]
+		if expired[run.TraceID] || orphan {
+			delete(s.runs, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// Close 实现 Store 接口
+func (s *MemoryStore) Close() error {
+	return nil
+}
@@ -0,0 +1,174 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zhucl121/langchain-go/core/callbacks"
+	"github.com/zhucl121/langchain-go/pkg/types"
+)
+
+// testStore 对 Store 实现执行通用测试
+func testStore(t *testing.T, store Store) {
+	ctx := context.Background()
+	base := time.Unix(1700000000, 0)
+
+	runs := []*Run{
+		{ID: "a", TraceID: "a", Type: callbacks.RunTypeAgent, Name: "react", Status: RunStatusSuccess,
+			Inputs: json.RawMessage(`"question"`), Tags: []string{"prod"}, StartTime: base, EndTime: base.Add(3 * time.Second),
+			Usage: &types.UsageMetadata{InputTokens: 10, OutputTokens: 5, TotalTokens: 15}},
+		{ID: "a1", ParentID: "a", TraceID: "a", Type: callbacks.RunTypeLLM, Name: "gpt-4o", Status: RunStatusSuccess,
+			Metadata: map[string]any{"step": "1"}, StartTime: base.Add(time.Second), EndTime: base.Add(2 * time.Second),
+			Events: []RunEvent{{Name: "first_token", Time: base.Add(time.Second)}}},
+		{ID: "b", TraceID: "b", Type: callbacks.RunTypeChain, Name: "rag", Status: RunStatusRunning,
+			Tags: []string{`50%_off`}, StartTime: base.Add(time.Minute)},
+	}
+	for _, run := range runs {
+		if err := store.SaveRun(ctx, run); err != nil {
+			t.Fatalf("SaveRun(%s) failed: %v", run.ID, err)
+		}
+	}
+
+	// 覆盖运行中的记录
+	ended := *runs[2]
+	ended.Status = RunStatusError
+	ended.Error = "boom"
+	ended.EndTime = base.Add(time.Minute + time.Second)
+	if err := store.SaveRun(ctx, &ended); err != nil {
+		t.Fatalf("SaveRun failed: %v", err)
+	}
+
+	run, err := store.GetRun(ctx, "b")
+	if err != nil {
+		t.Fatalf("GetRun failed: %v", err)
+	}
+	if run.Status != RunStatusError || run.Error != "boom" || run.Duration() != time.Second {
+		t.Errorf("Unexpected run: %+v", run)
+	}
+
+	run, err = store.GetRun(ctx, "a1")
+	if err != nil {
+		t.Fatalf("GetRun failed: %v", err)
+	}
+	if run.Metadata["step"] != "1" || len(run.Events) != 1 || !run.StartTime.Equal(base.Add(time.Second)) {
+		t.Errorf("Unexpected run: %+v", run)
+	}
+
+	if _, err := store.GetRun(ctx, "missing"); !errors.Is(err, ErrRunNotFound) {
+		t.Errorf("Expected ErrRunNotFound, got %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		query Query
+		want  []string
+	}{
+		{"all", Query{}, []string{"b", "a1", "a"}},
+		{"root only", Query{RootOnly: true}, []string{"b", "a"}},
+		{"trace", Query{TraceID: "a"}, []string{"a1", "a"}},
+		{"type", Query{Type: callbacks.RunTypeLLM}, []string{"a1"}},
+		{"name", Query{Name: "rag"}, []string{"b"}},
+		{"status", Query{Status: RunStatusError}, []string{"b"}},
+		{"tag", Query{Tag: "prod"}, []string{"a"}},
+		{"tag with wildcards", Query{Tag: `50%_off`}, []string{"b"}},
+		{"tag wildcards match literally", Query{Tag: "p_od"}, nil},
+		{"tag percent matches literally", Query{Tag: "%"}, nil},
+		{"time range", Query{StartTime: base.Add(time.Second), EndTime: base.Add(time.Minute)}, []string{"a1"}},
+		{"limit offset", Query{Limit: 1, Offset: 1}, []string{"a1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runs, err := store.ListRuns(ctx, &tt.query)
+			if err != nil {
+				t.Fatalf("ListRuns failed: %v", err)
+			}
+			if got := runIDs(runs); !equalStrings(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	trace, err := store.GetTrace(ctx, "a")
+	if err != nil {
+		t.Fatalf("GetTrace failed: %v", err)
+	}
+	if got := runIDs(trace); !equalStrings(got, []string{"a", "a1"}) {
+		t.Errorf("Expected [a a1], got %v", got)
+	}
+	if trace[0].Usage == nil || trace[0].Usage.TotalTokens != 15 {
+		t.Errorf("Expected usage to round-trip, got %+v", trace[0].Usage)
+	}
+
+	// 顶层运行没有保存的运行树：x 全部过期，y 中还有较新的运行
+	orphans := []*Run{
+		{ID: "x1", ParentID: "x", TraceID: "x", Type: callbacks.RunTypeLLM, Name: "gpt-4o", Status: RunStatusSuccess, StartTime: base},
+		{ID: "y1", ParentID: "y", TraceID: "y", Type: callbacks.RunTypeLLM, Name: "gpt-4o", Status: RunStatusSuccess, StartTime: base},
+		{ID: "y2", ParentID: "y", TraceID: "y", Type: callbacks.RunTypeLLM, Name: "gpt-4o", Status: RunStatusSuccess, StartTime: base.Add(2 * time.Minute)},
+	}
+	for _, run := range orphans {
+		if err := store.SaveRun(ctx, run); err != nil {
+			t.Fatalf("SaveRun(%s) failed: %v", run.ID, err)
+		}
+	}
+
+	deleted, err := store.DeleteBefore(ctx, base.Add(time.Second))
+	if err != nil {
+		t.Fatalf("DeleteBefore failed: %v", err)
+	}
+	if deleted != 3 {
+		t.Errorf("Expected 3 deleted runs, got %d", deleted)
+	}
+	remaining, _ := store.ListRuns(ctx, nil)
+	if got := runIDs(remaining); !equalStrings(got, []string{"y2", "b", "y1"}) {
+		t.Errorf("Expected [y2 b y1], got %v", got)
+	}
+}
+
+func runIDs(runs []*Run) []string {
+	ids := make([]string, len(runs))
+	for i, run := range runs {
+		ids[i] = run.ID
+	}
+	return ids
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
+
+func TestBuildTree(t *testing.T) {
+	base := time.Now()
+	runs := []*Run{
+		{ID: "c2", ParentID: "a", StartTime: base.Add(2)},
+		{ID: "a", StartTime: base},
+		{ID: "c1", ParentID: "a", StartTime: base.Add(1)},
+		{ID: "g", ParentID: "c1", StartTime: base.Add(3)},
+		{ID: "orphan", ParentID: "missing", StartTime: base.Add(4)},
+	}
+
+	roots := BuildTree(runs)
+	if got := runIDs(roots); !equalStrings(got, []string{"a", "orphan"}) {
+		t.Fatalf("Expected roots [a orphan], got %v", got)
+	}
+	if got := runIDs(roots[0].Children); !equalStrings(got, []string{"c1", "c2"}) {
+		t.Errorf("Expected children [c1 c2], got %v", got)
+	}
+	if got := runIDs(roots[0].Children[0].Children); !equalStrings(got, []string{"g"}) {
+		t.Errorf("Expected grandchildren [g], got %v", got)
+	}
+}
@@ -0,0 +1,48 @@
+package types
+
+// CacheControl 是提示词缓存断点。
+//
+// 标记在消息、多模态内容或工具上，表示请求从开头到该位置的前缀可以被缓存。
+// Anthropic 转换为 cache_control；OpenAI 自动缓存前缀，忽略此标记。
+//
+// 示例：
+//
+//	system := types.NewSystemMessage(longPrompt).WithCacheControl(types.NewCacheControl())
+//	tool := types.Tool{Name: "search", ..., CacheControl: types.NewCacheControl()}
+//
+type CacheControl struct {
+	// Type 缓存类型，目前只有 "ephemeral"
+	Type string `json:"type"`
+
+	// TTL 缓存有效期（如 "5m"、"1h"），为空时使用提供商默认值
+	TTL string `json:"ttl,omitempty"`
+}
+
+// CacheControlEphemeral 是临时缓存类型
+const CacheControlEphemeral = "ephemeral"
+
+// NewCacheControl 创建使用默认有效期的缓存断点。
+func NewCacheControl() *CacheControl {
+	return &CacheControl{Type: CacheControlEphemeral}
+}
+
+// NewCacheControlWithTTL 创建指定有效期的缓存断点。
+//
+// 参数：
+//   - ttl: 缓存有效期（如 "1h"）
+//
+// 返回：
+//   - *CacheControl: 缓存断点
+//
+func NewCacheControlWithTTL(ttl string) *CacheControl {
+	return &CacheControl{Type: CacheControlEphemeral, TTL: ttl}
+}
+
+// ToMap 转换为 API 请求中的 cache_control 对象。
+func (c *CacheControl) ToMap() map[string]any {
+	result := map[string]any{"type": c.Type}
+	if c.TTL != "" {
+		result["ttl"] = c.TTL
+	}
+	return result
+}
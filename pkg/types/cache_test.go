@@ -0,0 +1,20 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheControl_ToMap(t *testing.T) {
+	assert.Equal(t, map[string]any{"type": "ephemeral"}, NewCacheControl().ToMap())
+	assert.Equal(t, map[string]any{"type": "ephemeral", "ttl": "1h"}, NewCacheControlWithTTL("1h").ToMap())
+}
+
+func TestMessage_WithCacheControl(t *testing.T) {
+	msg := NewSystemMessage("You are a helpful assistant.")
+	cached := msg.WithCacheControl(NewCacheControl())
+
+	assert.Nil(t, msg.CacheControl)
+	assert.Equal(t, CacheControlEphemeral, cached.CacheControl.Type)
+}
@@ -12,7 +12,7 @@ type ContentBlockType string
 const (
 	// ContentBlockText 文本内容块
 	ContentBlockText ContentBlockType = "text"
-	// ContentBlockThinking 思考过程内容块（用于 o1 等模型）
+	// ContentBlockThinking 思考过程内容块（Anthropic 扩展思考、OpenAI 推理摘要、Gemini 思考）
 	ContentBlockThinking ContentBlockType = "thinking"
 	// ContentBlockToolUse 工具使用内容块
 	ContentBlockToolUse ContentBlockType = "tool_use"
@@ -79,6 +79,14 @@ type ContentBlock struct {
 
 	// Error 错误信息（如果类型是 error）
 	Error *ErrorInfo `json:"error,omitempty"`
+
+	// Signature 思考块签名（Anthropic 扩展思考），工具调用轮次中需要原样回传
+	//
+	// Redacted 为 true 时保存提供商加密后的思考数据。
+	Signature string `json:"signature,omitempty"`
+
+	// Redacted 思考内容是否被提供商加密隐藏（Content 为空）
+	Redacted bool `json:"redacted,omitempty"`
 }
 
 // Citation 引用来源。
@@ -168,6 +176,39 @@ func NewThinkingContentBlock(content string) *ContentBlock {
 	}
 }
 
+// NewRedactedThinkingContentBlock 创建被加密隐藏的思考过程内容块。
+//
+// 参数：
+//   - data: 提供商返回的加密数据
+//
+// 返回：
+//   - *ContentBlock: 内容块实例
+//
+func NewRedactedThinkingContentBlock(data string) *ContentBlock {
+	return &ContentBlock{
+		Type:      ContentBlockThinking,
+		Signature: data,
+		Redacted:  true,
+		Timestamp: time.Now(),
+		Metadata:  make(map[string]any),
+	}
+}
+
+// WithSignature 设置思考块签名。
+//
+// 返回 self，支持链式调用。
+//
+// 参数：
+//   - signature: 提供商返回的签名
+//
+// 返回：
+//   - *ContentBlock: 自身
+//
+func (cb *ContentBlock) WithSignature(signature string) *ContentBlock {
+	cb.Signature = signature
+	return cb
+}
+
 // NewToolUseContentBlock 创建工具使用内容块。
 //
 // 参数：
@@ -431,6 +472,8 @@ func (cb *ContentBlock) Clone() *ContentBlock {
 		Timestamp: cb.Timestamp,
 		ID:        cb.ID,
 		ParentID:  cb.ParentID,
+		Signature: cb.Signature,
+		Redacted:  cb.Redacted,
 	}
 
 	// 深拷贝 Reasoning
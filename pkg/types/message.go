@@ -3,6 +3,7 @@ package types
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 )
 
 // Role 消息角色类型
@@ -55,8 +56,14 @@ type Message struct {
 	Role Role `json:"role"`
 
 	// Content 消息内容
+	//
+	// 消息包含 Parts 时，Content 为其中文本内容的拼接，供只处理文本的组件
+	// （记忆、日志等）使用；提供商以 Parts 为准。
 	Content string `json:"content"`
 
+	// Parts 有序的多模态内容（文本、图像、音频、文件），为空时消息只有 Content
+	Parts []*MultimodalContent `json:"parts,omitempty"`
+
 	// Name 可选的消息发送者名称（用于多用户场景）
 	Name string `json:"name,omitempty"`
 
@@ -68,6 +75,20 @@ type Message struct {
 
 	// Metadata 附加元数据
 	Metadata map[string]any `json:"metadata,omitempty"`
+
+	// UsageMetadata token 用量（仅模型返回的 RoleAssistant 消息，流式响应在 EventEnd 中）
+	UsageMetadata *UsageMetadata `json:"usage_metadata,omitempty"`
+
+	// FinishReason 生成结束原因（仅模型返回的 RoleAssistant 消息）
+	FinishReason FinishReason `json:"finish_reason,omitempty"`
+
+	// CacheControl 提示词缓存断点（可选），缓存到该消息为止的请求前缀
+	CacheControl *CacheControl `json:"cache_control,omitempty"`
+
+	// ContentBlocks 模型返回的非文本内容块（如思考过程），不计入 Content
+	//
+	// 带签名的思考块在后续请求中由提供商原样回传。
+	ContentBlocks []*ContentBlock `json:"content_blocks,omitempty"`
 }
 
 // ToolCall 表示一次工具调用。
@@ -141,6 +162,73 @@ func NewUserMessage(content string) Message {
 	}
 }
 
+// NewMessageWithParts 创建一条多模态消息。
+//
+// 内容按顺序保存在 Parts 中，Content 为文本内容的拼接（以换行分隔）。
+//
+// 参数：
+//   - role: 消息角色
+//   - parts: 有序的内容
+//
+// 返回：
+//   - Message: 多模态消息
+//
+// 示例：
+//
+//	image, _ := types.NewImageContentFromFile("screenshot.png")
+//	msg := types.NewMessageWithParts(types.RoleUser,
+//	    types.NewTextContent("What is wrong in this screenshot?"),
+//	    image,
+//	)
+//	response, err := model.Invoke(ctx, []types.Message{msg})
+//
+func NewMessageWithParts(role Role, parts ...*MultimodalContent) Message {
+	var texts []string
+	for _, part := range parts {
+		if part.IsText() {
+			texts = append(texts, part.Text)
+		}
+	}
+
+	return Message{
+		Role:    role,
+		Content: strings.Join(texts, "\n"),
+		Parts:   parts,
+	}
+}
+
+// HasMedia 判断消息是否包含非文本内容（图像、音频、视频或文件）。
+func (m Message) HasMedia() bool {
+	for _, part := range m.Parts {
+		if !part.IsText() {
+			return true
+		}
+	}
+	return false
+}
+
+// ThinkingBlocks 返回消息中的思考过程内容块。
+func (m Message) ThinkingBlocks() []*ContentBlock {
+	var blocks []*ContentBlock
+	for _, block := range m.ContentBlocks {
+		if block.Type == ContentBlockThinking {
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks
+}
+
+// ThinkingText 返回思考过程文本的拼接（以换行分隔），不包含被加密隐藏的思考块。
+func (m Message) ThinkingText() string {
+	var texts []string
+	for _, block := range m.ThinkingBlocks() {
+		if !block.Redacted && block.Content != "" {
+			texts = append(texts, block.Content)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
 // NewAssistantMessage 创建一条助手消息。
 //
 // 助手消息表示来自 AI 的响应。
@@ -211,6 +299,21 @@ func (m Message) WithMetadata(key string, value any) Message {
 	return m
 }
 
+// WithCacheControl 设置提示词缓存断点。
+//
+// 返回新的 Message 实例，不修改原消息。
+//
+// 参数：
+//   - cacheControl: 缓存断点
+//
+// 返回：
+//   - Message: 新的消息实例
+//
+func (m Message) WithCacheControl(cacheControl *CacheControl) Message {
+	m.CacheControl = cacheControl
+	return m
+}
+
 // GetToolCallArgs 解析工具调用参数。
 //
 // 将 Arguments 字符串解析为 map。
@@ -276,6 +379,23 @@ func (m Message) Clone() Message {
 		copy(clone.ToolCalls, m.ToolCalls)
 	}
 
+	// 深拷贝 Parts
+	if len(m.Parts) > 0 {
+		clone.Parts = make([]*MultimodalContent, len(m.Parts))
+		for i, part := range m.Parts {
+			p := *part
+			clone.Parts[i] = &p
+		}
+	}
+
+	// 深拷贝 ContentBlocks
+	if len(m.ContentBlocks) > 0 {
+		clone.ContentBlocks = make([]*ContentBlock, len(m.ContentBlocks))
+		for i, block := range m.ContentBlocks {
+			clone.ContentBlocks[i] = block.Clone()
+		}
+	}
+
 	// 深拷贝 Metadata
 	if m.Metadata != nil {
 		clone.Metadata = make(map[string]any, len(m.Metadata))
@@ -284,6 +404,12 @@ func (m Message) Clone() Message {
 		}
 	}
 
+	// 深拷贝 UsageMetadata
+	if m.UsageMetadata != nil {
+		usage := *m.UsageMetadata
+		clone.UsageMetadata = &usage
+	}
+
 	return clone
 }
 
@@ -296,6 +422,11 @@ func (m Message) String() string {
 		contentPreview = contentPreview[:50] + "..."
 	}
 
+	if len(m.Parts) > 0 {
+		return fmt.Sprintf("Message{Role:%s, Content:%q, Parts:%d}",
+			m.Role, contentPreview, len(m.Parts))
+	}
+
 	if len(m.ToolCalls) > 0 {
 		return fmt.Sprintf("Message{Role:%s, Content:%q, ToolCalls:%d}",
 			m.Role, contentPreview, len(m.ToolCalls))
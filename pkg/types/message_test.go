@@ -258,6 +258,45 @@ func TestMessage_Clone(t *testing.T) {
 	assert.NotEqual(t, original.Metadata["key"], clone.Metadata["key"])
 }
 
+func TestNewMessageWithParts(t *testing.T) {
+	image := NewImageContentFromData([]byte{0x89, 'P', 'N', 'G'}, ImageFormatPNG)
+	msg := NewMessageWithParts(RoleUser, NewTextContent("Describe"), image, NewTextContent("briefly"))
+
+	assert.Equal(t, RoleUser, msg.Role)
+	assert.Equal(t, "Describe\nbriefly", msg.Content)
+	assert.Len(t, msg.Parts, 3)
+	assert.True(t, msg.HasMedia())
+	assert.False(t, NewMessageWithParts(RoleUser, NewTextContent("text only")).HasMedia())
+
+	// Clone 复制 Parts
+	clone := msg.Clone()
+	clone.Parts[1].ImageFormat = ImageFormatJPEG
+	assert.Equal(t, ImageFormatPNG, msg.Parts[1].ImageFormat)
+
+	assert.Contains(t, msg.String(), "Parts:3")
+}
+
+func TestMessage_ThinkingBlocks(t *testing.T) {
+	msg := Message{
+		Role:    RoleAssistant,
+		Content: "42",
+		ContentBlocks: []*ContentBlock{
+			NewThinkingContentBlock("step 1").WithSignature("sig"),
+			NewRedactedThinkingContentBlock("encrypted"),
+			NewThinkingContentBlock("step 2"),
+		},
+	}
+
+	assert.Len(t, msg.ThinkingBlocks(), 3)
+	assert.Equal(t, "step 1\nstep 2", msg.ThinkingText())
+
+	// Clone 复制 ContentBlocks
+	clone := msg.Clone()
+	clone.ContentBlocks[0].Signature = "changed"
+	assert.Equal(t, "sig", msg.ContentBlocks[0].Signature)
+	assert.True(t, clone.ContentBlocks[1].Redacted)
+}
+
 func TestMessage_String(t *testing.T) {
 	t.Run("short content", func(t *testing.T) {
 		msg := NewUserMessage("Hello")
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"os"
 	"path/filepath"
 )
@@ -82,6 +83,9 @@ type MultimodalContent struct {
 	// ImageFormat 图像格式
 	ImageFormat ImageFormat `json:"image_format,omitempty"`
 	
+	// ImageDetail 图像细节级别（"low"、"high"、"auto"，仅 OpenAI 使用）
+	ImageDetail string `json:"image_detail,omitempty"`
+	
 	// AudioURL 音频 URL（当 Type 为 audio 时）
 	AudioURL string `json:"audio_url,omitempty"`
 	
@@ -100,6 +104,21 @@ type MultimodalContent struct {
 	// VideoFormat 视频格式
 	VideoFormat VideoFormat `json:"video_format,omitempty"`
 	
+	// FileURL 文件 URL（当 Type 为 file 时）
+	FileURL string `json:"file_url,omitempty"`
+	
+	// FileData 文件数据
+	FileData []byte `json:"file_data,omitempty"`
+	
+	// FileName 文件名
+	FileName string `json:"file_name,omitempty"`
+	
+	// MIMEType 媒体类型（如 "application/pdf"），为空时根据格式推断
+	MIMEType string `json:"mime_type,omitempty"`
+	
+	// CacheControl 提示词缓存断点（可选）
+	CacheControl *CacheControl `json:"cache_control,omitempty"`
+	
 	// Metadata 元数据
 	Metadata map[string]interface{} `json:"metadata,omitempty"`
 }
@@ -224,6 +243,49 @@ func NewVideoContentFromFile(filePath string) (*MultimodalContent, error) {
 	}, nil
 }
 
+// NewFileContent 创建文件内容（从 URL）
+func NewFileContent(fileURL, mimeType string) *MultimodalContent {
+	return &MultimodalContent{
+		Type:     ContentTypeFile,
+		FileURL:  fileURL,
+		MIMEType: mimeType,
+	}
+}
+
+// NewFileContentFromData 创建文件内容（从数据）
+func NewFileContentFromData(fileData []byte, mimeType, fileName string) *MultimodalContent {
+	return &MultimodalContent{
+		Type:     ContentTypeFile,
+		FileData: fileData,
+		FileName: fileName,
+		MIMEType: mimeType,
+	}
+}
+
+// NewFileContentFromFile 创建文件内容（从文件）
+func NewFileContentFromFile(filePath string) (*MultimodalContent, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	
+	return &MultimodalContent{
+		Type:     ContentTypeFile,
+		FileData: data,
+		FileName: filepath.Base(filePath),
+		MIMEType: mime.TypeByExtension(filepath.Ext(filePath)),
+		Metadata: map[string]interface{}{
+			"file_path": filePath,
+		},
+	}, nil
+}
+
+// WithCacheControl 设置提示词缓存断点，返回自身以便链式调用
+func (c *MultimodalContent) WithCacheControl(cacheControl *CacheControl) *MultimodalContent {
+	c.CacheControl = cacheControl
+	return c
+}
+
 // IsText 是否为文本内容
 func (c *MultimodalContent) IsText() bool {
 	return c.Type == ContentTypeText
@@ -244,6 +306,107 @@ func (c *MultimodalContent) IsVideo() bool {
 	return c.Type == ContentTypeVideo
 }
 
+// IsFile 是否为文件内容
+func (c *MultimodalContent) IsFile() bool {
+	return c.Type == ContentTypeFile
+}
+
+// URL 返回内容的 URL（图像、音频、视频或文件），没有时返回空字符串
+func (c *MultimodalContent) URL() string {
+	switch c.Type {
+	case ContentTypeImage:
+		return c.ImageURL
+	case ContentTypeAudio:
+		return c.AudioURL
+	case ContentTypeVideo:
+		return c.VideoURL
+	case ContentTypeFile:
+		return c.FileURL
+	default:
+		return ""
+	}
+}
+
+// Data 返回内容的原始数据（图像、音频、视频或文件），没有时返回 nil
+func (c *MultimodalContent) Data() []byte {
+	switch c.Type {
+	case ContentTypeImage:
+		return c.ImageData
+	case ContentTypeAudio:
+		return c.AudioData
+	case ContentTypeVideo:
+		return c.VideoData
+	case ContentTypeFile:
+		return c.FileData
+	default:
+		return nil
+	}
+}
+
+// MediaType 返回内容的媒体类型（如 "image/png"、"audio/wav"）
+//
+// 优先使用 MIMEType，否则根据格式推断；无法推断时返回空字符串。
+func (c *MultimodalContent) MediaType() string {
+	if c.MIMEType != "" {
+		return c.MIMEType
+	}
+	
+	switch c.Type {
+	case ContentTypeImage:
+		if c.ImageFormat == "" {
+			return ""
+		}
+		if c.ImageFormat == ImageFormatJPEG {
+			return "image/jpeg"
+		}
+		return "image/" + string(c.ImageFormat)
+	case ContentTypeAudio:
+		switch c.AudioFormat {
+		case "":
+			return ""
+		case AudioFormatMP3:
+			return "audio/mpeg"
+		case AudioFormatM4A:
+			return "audio/mp4"
+		default:
+			return "audio/" + string(c.AudioFormat)
+		}
+	case ContentTypeVideo:
+		switch c.VideoFormat {
+		case "":
+			return ""
+		case VideoFormatMOV:
+			return "video/quicktime"
+		case VideoFormatMKV:
+			return "video/x-matroska"
+		case VideoFormatAVI:
+			return "video/x-msvideo"
+		default:
+			return "video/" + string(c.VideoFormat)
+		}
+	case ContentTypeFile:
+		if c.FileName != "" {
+			return mime.TypeByExtension(filepath.Ext(c.FileName))
+		}
+	}
+	
+	return ""
+}
+
+// DataURL 返回 Base64 编码的 data URL（如 "data:image/png;base64,..."），没有数据时返回空字符串
+func (c *MultimodalContent) DataURL() string {
+	data := c.Data()
+	if len(data) == 0 {
+		return ""
+	}
+	
+	mediaType := c.MediaType()
+	if mediaType == "" {
+		mediaType = "application/octet-stream"
+	}
+	return "data:" + mediaType + ";base64," + base64.StdEncoding.EncodeToString(data)
+}
+
 // GetText 获取文本内容
 func (c *MultimodalContent) GetText() (string, error) {
 	if !c.IsText() {
@@ -323,6 +486,8 @@ func (c *MultimodalContent) Size() int {
 		return len(c.AudioData)
 	case ContentTypeVideo:
 		return len(c.VideoData)
+	case ContentTypeFile:
+		return len(c.FileData)
 	default:
 		return 0
 	}
@@ -342,6 +507,8 @@ func (c *MultimodalContent) LoadFromReader(reader io.Reader) error {
 		c.AudioData = data
 	case ContentTypeVideo:
 		c.VideoData = data
+	case ContentTypeFile:
+		c.FileData = data
 	default:
 		return fmt.Errorf("unsupported content type for LoadFromReader: %s", c.Type)
 	}
@@ -508,14 +675,10 @@ func (m *MultimodalMessage) HasVideos() bool {
 	return false
 }
 
-// ToMessage 转换为普通 Message（仅保留文本）
+// ToMessage 转换为普通 Message
+//
+// 所有内容按顺序保存在 Message.Parts 中，Content 为全部文本内容的拼接。
 func (m *MultimodalMessage) ToMessage() *Message {
-	texts := m.GetTextContents()
-	content := ""
-	if len(texts) > 0 {
-		content = texts[0] // 取第一个文本
-	}
-	
 	// 将字符串角色转换为 Role 类型
 	role := RoleUser
 	switch m.Role {
@@ -529,8 +692,6 @@ func (m *MultimodalMessage) ToMessage() *Message {
 		role = RoleTool
 	}
 	
-	return &Message{
-		Role:    role,
-		Content: content,
-	}
+	msg := NewMessageWithParts(role, m.Contents...)
+	return &msg
 }
@@ -172,9 +172,14 @@ func TestMultimodalMessage_ToMessage(t *testing.T) {
 		t.Errorf("Role = %v, want user", msg.Role)
 	}
 	
-	// 应该只保留第一个文本
-	if msg.Content != "First" {
-		t.Errorf("Content = %v, want First", msg.Content)
+	// 文本内容拼接到 Content
+	if msg.Content != "First\nSecond" {
+		t.Errorf("Content = %q, want %q", msg.Content, "First\nSecond")
+	}
+	
+	// 所有内容按顺序保留
+	if len(msg.Parts) != 3 || msg.Parts[1] != image {
+		t.Errorf("Parts = %v, want 3 parts with image second", msg.Parts)
 	}
 }
 
@@ -268,3 +273,41 @@ func TestMultimodalContent_GetImageDataBase64(t *testing.T) {
 		t.Errorf("GetImageDataBase64() = %v, want %v", base64Str, expected)
 	}
 }
+
+func TestMultimodalContent_MediaType(t *testing.T) {
+	tests := []struct {
+		content *MultimodalContent
+		want    string
+	}{
+		{NewImageContent("a.jpg", ImageFormatJPEG), "image/jpeg"},
+		{NewImageContentFromData(nil, ImageFormatPNG), "image/png"},
+		{NewAudioContentFromData(nil, AudioFormatMP3), "audio/mpeg"},
+		{NewAudioContentFromData(nil, AudioFormatWAV), "audio/wav"},
+		{NewVideoContent("a.mov", VideoFormatMOV), "video/quicktime"},
+		{NewFileContentFromData(nil, "", "report.pdf"), "application/pdf"},
+		{NewFileContent("https://example.com/a", "text/csv"), "text/csv"},
+		{NewImageContent("a", ""), ""},
+	}
+	
+	for _, tt := range tests {
+		if got := tt.content.MediaType(); got != tt.want {
+			t.Errorf("MediaType(%+v) = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestMultimodalContent_DataURL(t *testing.T) {
+	content := NewImageContentFromData([]byte("abc"), ImageFormatPNG)
+	if got := content.DataURL(); got != "data:image/png;base64,YWJj" {
+		t.Errorf("DataURL = %q", got)
+	}
+	
+	if got := NewImageContent("https://example.com/a.png", ImageFormatPNG).DataURL(); got != "" {
+		t.Errorf("DataURL without data = %q", got)
+	}
+	
+	file := NewFileContentFromData([]byte("abc"), "application/pdf", "a.pdf")
+	if file.URL() != "" || string(file.Data()) != "abc" || !file.IsFile() {
+		t.Errorf("unexpected file content: %+v", file)
+	}
+}
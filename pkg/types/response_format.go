@@ -0,0 +1,85 @@
+package types
+
+// ResponseFormatType 响应格式类型。
+type ResponseFormatType string
+
+const (
+	// ResponseFormatText 普通文本
+	ResponseFormatText ResponseFormatType = "text"
+	// ResponseFormatJSONObject 任意 JSON 对象（JSON 模式）
+	ResponseFormatJSONObject ResponseFormatType = "json_object"
+	// ResponseFormatJSONSchema 符合 JSON Schema 的 JSON 对象
+	ResponseFormatJSONSchema ResponseFormatType = "json_schema"
+)
+
+// ResponseFormat 是单次调用的响应格式。
+//
+// 与 ChatModel.WithStructuredOutput 不同，ResponseFormat 只影响一次调用，
+// 设置后覆盖模型上的结构化输出配置。
+//
+// 示例：
+//
+//	response, _ := model.Invoke(ctx, messages,
+//	    runnable.WithResponseFormat(types.NewJSONSchemaFormat("person", personSchema)))
+//
+type ResponseFormat struct {
+	// Type 格式类型
+	Type ResponseFormatType `json:"type"`
+
+	// Name Schema 名称（仅 ResponseFormatJSONSchema）
+	Name string `json:"name,omitempty"`
+
+	// Schema 输出的 JSON Schema（仅 ResponseFormatJSONSchema）
+	Schema *Schema `json:"schema,omitempty"`
+
+	// Strict 是否严格遵循 Schema（OpenAI 专用）
+	Strict bool `json:"strict,omitempty"`
+}
+
+// NewJSONObjectFormat 创建 JSON 模式的响应格式。
+func NewJSONObjectFormat() ResponseFormat {
+	return ResponseFormat{Type: ResponseFormatJSONObject}
+}
+
+// NewJSONSchemaFormat 创建严格遵循 JSON Schema 的响应格式。
+//
+// 参数：
+//   - name: Schema 名称
+//   - schema: 输出的 JSON Schema
+//
+// 返回：
+//   - ResponseFormat: 响应格式
+//
+func NewJSONSchemaFormat(name string, schema Schema) ResponseFormat {
+	return ResponseFormat{Type: ResponseFormatJSONSchema, Name: name, Schema: &schema, Strict: true}
+}
+
+// ToOpenAI 转换为 OpenAI Chat Completions 的 response_format。
+//
+// 返回：
+//   - map[string]any: OpenAI 格式的 response_format
+//
+func (f ResponseFormat) ToOpenAI() map[string]any {
+	if f.Type != ResponseFormatJSONSchema || f.Schema == nil {
+		return map[string]any{"type": string(f.Type)}
+	}
+
+	name := f.Name
+	if name == "" {
+		name = "response"
+	}
+
+	return map[string]any{
+		"type": string(f.Type),
+		"json_schema": map[string]any{
+			"name":   name,
+			"schema": f.Schema.ToMap(),
+			"strict": f.Strict,
+		},
+	}
+}
+
+// IsJSON 返回是否要求 JSON 输出。
+func (f ResponseFormat) IsJSON() bool {
+	return f.Type == ResponseFormatJSONObject || f.Type == ResponseFormatJSONSchema
+}
@@ -0,0 +1,28 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResponseFormat_ToOpenAI(t *testing.T) {
+	assert.Equal(t, map[string]any{"type": "json_object"}, NewJSONObjectFormat().ToOpenAI())
+	assert.Equal(t, map[string]any{"type": "text"}, ResponseFormat{Type: ResponseFormatText}.ToOpenAI())
+
+	format := NewJSONSchemaFormat("person", Schema{Type: "object"})
+	assert.Equal(t, map[string]any{
+		"type": "json_schema",
+		"json_schema": map[string]any{
+			"name":   "person",
+			"schema": map[string]any{"type": "object"},
+			"strict": true,
+		},
+	}, format.ToOpenAI())
+}
+
+func TestResponseFormat_IsJSON(t *testing.T) {
+	assert.True(t, NewJSONObjectFormat().IsJSON())
+	assert.True(t, NewJSONSchemaFormat("person", Schema{Type: "object"}).IsJSON())
+	assert.False(t, ResponseFormat{Type: ResponseFormatText}.IsJSON())
+}
@@ -0,0 +1,137 @@
+package types
+
+// ReasoningEffort 推理强度（OpenAI reasoning_effort）。
+type ReasoningEffort string
+
+const (
+	// ReasoningEffortMinimal 最低推理强度
+	ReasoningEffortMinimal ReasoningEffort = "minimal"
+	// ReasoningEffortLow 低推理强度
+	ReasoningEffortLow ReasoningEffort = "low"
+	// ReasoningEffortMedium 中等推理强度
+	ReasoningEffortMedium ReasoningEffort = "medium"
+	// ReasoningEffortHigh 高推理强度
+	ReasoningEffortHigh ReasoningEffort = "high"
+)
+
+// ReasoningSummary 推理摘要的详细程度（OpenAI reasoning.summary）。
+type ReasoningSummary string
+
+const (
+	// ReasoningSummaryAuto 由模型决定摘要详细程度
+	ReasoningSummaryAuto ReasoningSummary = "auto"
+	// ReasoningSummaryConcise 简洁摘要
+	ReasoningSummaryConcise ReasoningSummary = "concise"
+	// ReasoningSummaryDetailed 详细摘要
+	ReasoningSummaryDetailed ReasoningSummary = "detailed"
+)
+
+// ThinkingConfig 是扩展思考（推理）的配置。
+//
+// 各提供商的参数不同，这里统一为预算和强度两种表达，提供商按需换算：
+//   - Anthropic: thinking.budget_tokens
+//   - OpenAI: reasoning_effort，设置 Summary 时使用 Responses API 返回推理摘要
+//   - Gemini: thinkingConfig.thinkingBudget
+//
+// 思考内容以 ContentBlockThinking 类型的内容块出现在 Message.ContentBlocks 中，
+// 不会混入 Content。
+//
+// 示例：
+//
+//	// 模型级别
+//	model, _ := anthropic.New(anthropic.Config{..., Thinking: types.NewThinkingConfig(4096)})
+//
+//	// 单次调用
+//	response, _ := model.Invoke(ctx, messages,
+//	    runnable.WithThinking(types.NewReasoningConfig(types.ReasoningEffortHigh)))
+//	fmt.Println(response.ThinkingText())
+//
+type ThinkingConfig struct {
+	// Enabled 是否启用思考，为 false 时显式关闭（如 Gemini 的 thinkingBudget=0）
+	Enabled bool `json:"enabled"`
+
+	// BudgetTokens 思考 token 预算，为 0 时按 Effort 换算
+	BudgetTokens int `json:"budget_tokens,omitempty"`
+
+	// Effort 推理强度，为空时按 BudgetTokens 换算
+	Effort ReasoningEffort `json:"effort,omitempty"`
+
+	// Summary 推理摘要详细程度（仅 OpenAI），为空时不返回推理摘要
+	Summary ReasoningSummary `json:"summary,omitempty"`
+}
+
+// NewThinkingConfig 创建按 token 预算配置的思考。
+//
+// 参数：
+//   - budgetTokens: 思考 token 预算
+//
+// 返回：
+//   - *ThinkingConfig: 思考配置
+//
+func NewThinkingConfig(budgetTokens int) *ThinkingConfig {
+	return &ThinkingConfig{Enabled: true, BudgetTokens: budgetTokens}
+}
+
+// NewReasoningConfig 创建按推理强度配置的思考。
+//
+// 参数：
+//   - effort: 推理强度
+//
+// 返回：
+//   - *ThinkingConfig: 思考配置
+//
+func NewReasoningConfig(effort ReasoningEffort) *ThinkingConfig {
+	return &ThinkingConfig{Enabled: true, Effort: effort}
+}
+
+// WithSummary 设置推理摘要详细程度。
+//
+// 返回新的 ThinkingConfig 实例，不修改原配置。
+//
+// 参数：
+//   - summary: 摘要详细程度
+//
+// 返回：
+//   - *ThinkingConfig: 新的配置实例
+//
+func (c *ThinkingConfig) WithSummary(summary ReasoningSummary) *ThinkingConfig {
+	clone := *c
+	clone.Summary = summary
+	return &clone
+}
+
+// Budget 返回思考 token 预算，未设置时按推理强度换算。
+func (c *ThinkingConfig) Budget() int {
+	if c.BudgetTokens > 0 {
+		return c.BudgetTokens
+	}
+
+	switch c.Effort {
+	case ReasoningEffortMinimal:
+		return 1024
+	case ReasoningEffortLow:
+		return 4096
+	case ReasoningEffortHigh:
+		return 16384
+	default:
+		return 8192
+	}
+}
+
+// EffortLevel 返回推理强度，未设置时按 token 预算换算，两者都未设置时返回空。
+func (c *ThinkingConfig) EffortLevel() ReasoningEffort {
+	if c.Effort != "" {
+		return c.Effort
+	}
+
+	switch {
+	case c.BudgetTokens <= 0:
+		return ""
+	case c.BudgetTokens <= 4096:
+		return ReasoningEffortLow
+	case c.BudgetTokens <= 8192:
+		return ReasoningEffortMedium
+	default:
+		return ReasoningEffortHigh
+	}
+}
@@ -0,0 +1,27 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThinkingConfig_Budget(t *testing.T) {
+	assert.Equal(t, 2048, NewThinkingConfig(2048).Budget())
+	assert.Equal(t, 16384, NewReasoningConfig(ReasoningEffortHigh).Budget())
+	assert.Equal(t, 8192, (&ThinkingConfig{Enabled: true}).Budget())
+}
+
+func TestThinkingConfig_EffortLevel(t *testing.T) {
+	assert.Equal(t, ReasoningEffortMinimal, NewReasoningConfig(ReasoningEffortMinimal).EffortLevel())
+	assert.Equal(t, ReasoningEffortLow, NewThinkingConfig(1024).EffortLevel())
+	assert.Equal(t, ReasoningEffortMedium, NewThinkingConfig(8192).EffortLevel())
+	assert.Equal(t, ReasoningEffortHigh, NewThinkingConfig(32000).EffortLevel())
+	assert.Equal(t, ReasoningEffort(""), (&ThinkingConfig{Enabled: true}).EffortLevel())
+
+	// WithSummary 不修改原配置
+	config := NewReasoningConfig(ReasoningEffortLow)
+	withSummary := config.WithSummary(ReasoningSummaryAuto)
+	assert.Equal(t, ReasoningSummaryAuto, withSummary.Summary)
+	assert.Empty(t, config.Summary)
+}
@@ -39,6 +39,10 @@ type Tool struct {
 	// Strict 是否严格模式（OpenAI 专用）
 	// 严格模式会强制 LLM 生成符合 Schema 的参数
 	Strict bool `json:"strict,omitempty"`
+
+	// CacheControl 提示词缓存断点（可选，Anthropic 专用）
+	// 缓存到该工具为止的所有工具定义
+	CacheControl *CacheControl `json:"cache_control,omitempty"`
 }
 
 // Validate 验证工具定义的有效性。
@@ -90,11 +94,17 @@ func (t Tool) ToOpenAITool() map[string]any {
 //   - map[string]any: Anthropic 格式的工具定义
 //
 func (t Tool) ToAnthropicTool() map[string]any {
-	return map[string]any{
+	tool := map[string]any{
 		"name":         t.Name,
 		"description":  t.Description,
 		"input_schema": t.Parameters.ToMap(),
 	}
+
+	if t.CacheControl != nil {
+		tool["cache_control"] = t.CacheControl.ToMap()
+	}
+
+	return tool
 }
 
 // Clone 创建工具的深拷贝。
@@ -0,0 +1,94 @@
+package types
+
+// ToolChoiceMode 工具选择模式。
+type ToolChoiceMode string
+
+const (
+	// ToolChoiceAuto 由模型决定是否调用工具
+	ToolChoiceAuto ToolChoiceMode = "auto"
+	// ToolChoiceNone 不调用工具
+	ToolChoiceNone ToolChoiceMode = "none"
+	// ToolChoiceRequired 必须调用至少一个工具
+	ToolChoiceRequired ToolChoiceMode = "required"
+	// ToolChoiceTool 必须调用指定的工具
+	ToolChoiceTool ToolChoiceMode = "tool"
+)
+
+// ToolChoice 控制模型如何使用绑定的工具。
+//
+// 各提供商的格式不同，这里统一为四种模式，提供商按需转换：
+//   - OpenAI: "auto" / "none" / "required" / {"type": "function", ...}
+//   - Anthropic: {"type": "auto" | "none" | "any" | "tool"}
+//   - Gemini: functionCallingConfig.mode AUTO / NONE / ANY
+//
+// 示例：
+//
+//	response, _ := model.Invoke(ctx, messages,
+//	    runnable.WithToolChoice(types.NewSpecificToolChoice("get_weather")))
+//
+type ToolChoice struct {
+	// Mode 选择模式
+	Mode ToolChoiceMode `json:"mode"`
+
+	// Name 指定的工具名称（仅 ToolChoiceTool 模式）
+	Name string `json:"name,omitempty"`
+}
+
+// NewToolChoice 创建指定模式的工具选择。
+func NewToolChoice(mode ToolChoiceMode) ToolChoice {
+	return ToolChoice{Mode: mode}
+}
+
+// NewSpecificToolChoice 创建强制调用指定工具的工具选择。
+//
+// 参数：
+//   - name: 工具名称
+//
+// 返回：
+//   - ToolChoice: 工具选择
+//
+func NewSpecificToolChoice(name string) ToolChoice {
+	return ToolChoice{Mode: ToolChoiceTool, Name: name}
+}
+
+// ToOpenAI 转换为 OpenAI Chat Completions 的 tool_choice。
+//
+// 返回：
+//   - any: 模式字符串，或指定函数的对象
+//
+func (c ToolChoice) ToOpenAI() any {
+	if c.Mode == ToolChoiceTool {
+		return map[string]any{
+			"type":     "function",
+			"function": map[string]any{"name": c.Name},
+		}
+	}
+	return string(c.Mode)
+}
+
+// ToAnthropic 转换为 Anthropic 的 tool_choice。
+//
+// 参数：
+//   - parallel: 是否允许并行工具调用，为 nil 时使用 Anthropic 默认值（允许）
+//
+// 返回：
+//   - map[string]any: Anthropic 格式的 tool_choice
+//
+func (c ToolChoice) ToAnthropic(parallel *bool) map[string]any {
+	var choice map[string]any
+	switch c.Mode {
+	case ToolChoiceRequired:
+		choice = map[string]any{"type": "any"}
+	case ToolChoiceTool:
+		choice = map[string]any{"type": "tool", "name": c.Name}
+	default:
+		choice = map[string]any{"type": string(c.Mode)}
+	}
+
+	// none 模式不接受 disable_parallel_tool_use
+	if parallel != nil && c.Mode != ToolChoiceNone {
+		choice["disable_parallel_tool_use"] = !*parallel
+	}
+
+	return choice
+}
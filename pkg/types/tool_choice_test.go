@@ -0,0 +1,29 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToolChoice_ToOpenAI(t *testing.T) {
+	assert.Equal(t, "auto", NewToolChoice(ToolChoiceAuto).ToOpenAI())
+	assert.Equal(t, "none", NewToolChoice(ToolChoiceNone).ToOpenAI())
+	assert.Equal(t, "required", NewToolChoice(ToolChoiceRequired).ToOpenAI())
+	assert.Equal(t, map[string]any{
+		"type":     "function",
+		"function": map[string]any{"name": "search"},
+	}, NewSpecificToolChoice("search").ToOpenAI())
+}
+
+func TestToolChoice_ToAnthropic(t *testing.T) {
+	sequential := false
+
+	assert.Equal(t, map[string]any{"type": "auto"}, NewToolChoice(ToolChoiceAuto).ToAnthropic(nil))
+	assert.Equal(t, map[string]any{"type": "any", "disable_parallel_tool_use": true},
+		NewToolChoice(ToolChoiceRequired).ToAnthropic(&sequential))
+	assert.Equal(t, map[string]any{"type": "tool", "name": "search"}, NewSpecificToolChoice("search").ToAnthropic(nil))
+
+	// none 模式不发送 disable_parallel_tool_use
+	assert.Equal(t, map[string]any{"type": "none"}, NewToolChoice(ToolChoiceNone).ToAnthropic(&sequential))
+}
@@ -109,6 +109,18 @@ func TestTool_ToAnthropicTool(t *testing.T) {
 	assert.NotNil(t, result["input_schema"])
 }
 
+func TestTool_ToAnthropicTool_CacheControl(t *testing.T) {
+	tool := Tool{
+		Name:         "calculator",
+		Description:  "Perform calculations",
+		Parameters:   Schema{Type: "object"},
+		CacheControl: NewCacheControl(),
+	}
+
+	assert.Equal(t, map[string]any{"type": "ephemeral"}, tool.ToAnthropicTool()["cache_control"])
+	assert.NotContains(t, tool.ToOpenAITool(), "cache_control")
+}
+
 func TestTool_Clone(t *testing.T) {
 	original := Tool{
 		Name:        "search",
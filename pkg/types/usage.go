@@ -0,0 +1,92 @@
+package types
+
+// UsageMetadata 是一次模型调用的 token 用量。
+//
+// 各提供商的计数方式不同，这里统一为：
+//   - InputTokens 包含缓存命中和缓存写入的 token（CachedTokens、CacheCreationTokens 是其中的一部分）
+//   - OutputTokens 包含推理 token（ReasoningTokens 是其中的一部分）
+//
+// 示例：
+//
+//	response, _ := model.Invoke(ctx, messages)
+//	if usage := response.UsageMetadata; usage != nil {
+//	    fmt.Println(usage.InputTokens, usage.OutputTokens, usage.CachedTokens)
+//	}
+//
+type UsageMetadata struct {
+	// InputTokens 输入（提示词）token 数
+	InputTokens int `json:"input_tokens"`
+
+	// OutputTokens 输出（生成）token 数
+	OutputTokens int `json:"output_tokens"`
+
+	// TotalTokens 总 token 数
+	TotalTokens int `json:"total_tokens"`
+
+	// CachedTokens 输入中命中提示词缓存的 token 数
+	CachedTokens int `json:"cached_tokens,omitempty"`
+
+	// CacheCreationTokens 输入中写入提示词缓存的 token 数（Anthropic 等显式缓存的提供商）
+	CacheCreationTokens int `json:"cache_creation_tokens,omitempty"`
+
+	// ReasoningTokens 输出中用于推理（思考）的 token 数
+	ReasoningTokens int `json:"reasoning_tokens,omitempty"`
+}
+
+// NewUsageMetadata 创建 token 用量，TotalTokens 为输入与输出之和。
+//
+// 参数：
+//   - inputTokens: 输入 token 数
+//   - outputTokens: 输出 token 数
+//
+// 返回：
+//   - *UsageMetadata: token 用量
+//
+func NewUsageMetadata(inputTokens, outputTokens int) *UsageMetadata {
+	return &UsageMetadata{
+		InputTokens:  inputTokens,
+		OutputTokens: outputTokens,
+		TotalTokens:  inputTokens + outputTokens,
+	}
+}
+
+// Add 返回两次用量之和，用于累计多次调用（如 Agent 的多轮推理）。
+//
+// 参数：
+//   - other: 另一次调用的用量，可以为 nil
+//
+// 返回：
+//   - *UsageMetadata: 新的用量，不修改原值
+//
+func (u *UsageMetadata) Add(other *UsageMetadata) *UsageMetadata {
+	var sum UsageMetadata
+	for _, v := range []*UsageMetadata{u, other} {
+		if v == nil {
+			continue
+		}
+		sum.InputTokens += v.InputTokens
+		sum.OutputTokens += v.OutputTokens
+		sum.TotalTokens += v.TotalTokens
+		sum.CachedTokens += v.CachedTokens
+		sum.CacheCreationTokens += v.CacheCreationTokens
+		sum.ReasoningTokens += v.ReasoningTokens
+	}
+	return &sum
+}
+
+// FinishReason 生成结束的原因。
+//
+// 提供商返回的原因会被归一化为以下常量；无法识别的原因保留原值。
+//
+type FinishReason string
+
+const (
+	// FinishReasonStop 正常结束或遇到停止序列
+	FinishReasonStop FinishReason = "stop"
+	// FinishReasonLength 达到最大 token 数
+	FinishReasonLength FinishReason = "length"
+	// FinishReasonToolCalls 模型请求调用工具
+	FinishReasonToolCalls FinishReason = "tool_calls"
+	// FinishReasonContentFilter 被内容安全策略拦截
+	FinishReasonContentFilter FinishReason = "content_filter"
+)
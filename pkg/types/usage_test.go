@@ -0,0 +1,59 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestNewUsageMetadata(t *testing.T) {
+	usage := NewUsageMetadata(100, 20)
+	if usage.InputTokens != 100 || usage.OutputTokens != 20 || usage.TotalTokens != 120 {
+		t.Errorf("unexpected usage: %+v", usage)
+	}
+}
+
+func TestUsageMetadata_Add(t *testing.T) {
+	a := &UsageMetadata{InputTokens: 100, OutputTokens: 20, TotalTokens: 120, CachedTokens: 80}
+	b := &UsageMetadata{InputTokens: 50, OutputTokens: 30, TotalTokens: 80, ReasoningTokens: 10, CacheCreationTokens: 40}
+
+	sum := a.Add(b)
+	want := UsageMetadata{InputTokens: 150, OutputTokens: 50, TotalTokens: 200, CachedTokens: 80, ReasoningTokens: 10, CacheCreationTokens: 40}
+	if *sum != want {
+		t.Errorf("expected %+v, got %+v", want, *sum)
+	}
+	if a.InputTokens != 100 {
+		t.Error("Add should not modify the receiver")
+	}
+
+	// nil 安全
+	var empty *UsageMetadata
+	if got := empty.Add(a); *got != *a {
+		t.Errorf("expected %+v, got %+v", *a, *got)
+	}
+}
+
+func TestMessage_UsageMetadata(t *testing.T) {
+	msg := NewAssistantMessage("hi")
+	msg.UsageMetadata = NewUsageMetadata(10, 2)
+	msg.FinishReason = FinishReasonStop
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded Message
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.UsageMetadata == nil || decoded.UsageMetadata.TotalTokens != 12 || decoded.FinishReason != FinishReasonStop {
+		t.Errorf("unexpected round trip: %s", data)
+	}
+
+	// Clone 深拷贝用量
+	clone := msg.Clone()
+	clone.UsageMetadata.InputTokens = 99
+	if msg.UsageMetadata.InputTokens != 10 {
+		t.Error("Clone should deep copy UsageMetadata")
+	}
+}
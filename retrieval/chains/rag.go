@@ -7,6 +7,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/zhucl121/langchain-go/core/callbacks"
 	"github.com/zhucl121/langchain-go/core/chat"
 	"github.com/zhucl121/langchain-go/core/prompts"
 	"github.com/zhucl121/langchain-go/pkg/types"
@@ -113,6 +114,14 @@ func WithContextFormatter(formatter ContextFormatter) Option {
 //  6. 返回结果
 //
 func (c *RAGChain) Run(ctx context.Context, question string) (RAGResult, error) {
+	ctx, run := callbacks.StartChain(ctx, "RAGChain", question)
+	result, err := c.run(ctx, question)
+	run.EndChain(ctx, result, err)
+	return result, err
+}
+
+// run 执行 RAG 流程
+func (c *RAGChain) run(ctx context.Context, question string) (RAGResult, error) {
 	start := time.Now()
 
 	// 1. 检索相关文档
@@ -205,6 +214,38 @@ func (c *RAGChain) Run(ctx context.Context, question string) (RAGResult, error)
 //   - "error": 发生错误，Data 为 error
 //
 func (c *RAGChain) Stream(ctx context.Context, question string) (<-chan RAGChunk, error) {
+	ctx, run := callbacks.StartChain(ctx, "RAGChain", question)
+	chunks, err := c.stream(ctx, question)
+	if run == nil || err != nil {
+		run.EndChain(ctx, nil, err)
+		return chunks, err
+	}
+
+	out := make(chan RAGChunk, cap(chunks))
+	go func() {
+		defer close(out)
+
+		var (
+			output    any
+			streamErr error
+		)
+		for chunk := range chunks {
+			switch chunk.Type {
+			case "done":
+				output = chunk.Data
+			case "error":
+				streamErr, _ = chunk.Data.(error)
+			}
+			out <- chunk
+		}
+		run.EndChain(ctx, output, streamErr)
+	}()
+
+	return out, nil
+}
+
+// stream 流式执行 RAG 流程
+func (c *RAGChain) stream(ctx context.Context, question string) (<-chan RAGChunk, error) {
 	resultChan := make(chan RAGChunk, 10)
 
 	go func() {
@@ -95,7 +95,7 @@ func WithRRFK(k int) EnsembleOption {
 // GetRelevantDocuments 实现 Retriever 接口
 func (r *EnsembleRetriever) GetRelevantDocuments(ctx context.Context, query string) ([]*loaders.Document, error) {
 	// 触发开始回调
-	r.triggerStart(ctx, query)
+	ctx, run := r.triggerStart(ctx, "EnsembleRetriever", query)
 
 	// 1. 从所有检索器获取带分数的结果
 	var allResults [][]DocumentWithScore
@@ -110,7 +110,7 @@ func (r *EnsembleRetriever) GetRelevantDocuments(ctx context.Context, query stri
 	}
 
 	if len(allResults) == 0 {
-		r.triggerEnd(ctx, []*loaders.Document{})
+		r.triggerEnd(ctx, run, []*loaders.Document{})
 		return []*loaders.Document{}, nil
 	}
 
@@ -124,7 +124,7 @@ func (r *EnsembleRetriever) GetRelevantDocuments(ctx context.Context, query stri
 	}
 
 	// 触发结束回调
-	r.triggerEnd(ctx, docs)
+	r.triggerEnd(ctx, run, docs)
 
 	return docs, nil
 }
@@ -132,7 +132,7 @@ func (r *EnsembleRetriever) GetRelevantDocuments(ctx context.Context, query stri
 // GetRelevantDocumentsWithScore 实现 Retriever 接口
 func (r *EnsembleRetriever) GetRelevantDocumentsWithScore(ctx context.Context, query string) ([]DocumentWithScore, error) {
 	// 触发开始回调
-	r.triggerStart(ctx, query)
+	ctx, run := r.triggerStart(ctx, "EnsembleRetriever", query)
 
 	// 1. 从所有检索器获取结果
 	var allResults [][]DocumentWithScore
@@ -146,7 +146,7 @@ func (r *EnsembleRetriever) GetRelevantDocumentsWithScore(ctx context.Context, q
 	}
 
 	if len(allResults) == 0 {
-		r.triggerEnd(ctx, []*loaders.Document{})
+		r.triggerEnd(ctx, run, []*loaders.Document{})
 		return []DocumentWithScore{}, nil
 	}
 
@@ -158,7 +158,7 @@ func (r *EnsembleRetriever) GetRelevantDocumentsWithScore(ctx context.Context, q
 	for i, d := range fusedResults {
 		plainDocs[i] = d.Document
 	}
-	r.triggerEnd(ctx, plainDocs)
+	r.triggerEnd(ctx, run, plainDocs)
 
 	return fusedResults, nil
 }
@@ -6,9 +6,11 @@ import (
 	"sync"
 	"time"
 
+	"github.com/zhucl121/langchain-go/core/callbacks"
 	"github.com/zhucl121/langchain-go/pkg/types"
 	"github.com/zhucl121/langchain-go/retrieval/graphdb"
 	"github.com/zhucl121/langchain-go/retrieval/graphdb/builder"
+	"github.com/zhucl121/langchain-go/retrieval/vectorstores"
 )
 
 // GraphRAGRetriever GraphRAG 检索器。
@@ -83,6 +85,14 @@ func NewGraphRAGRetriever(config Config) (*GraphRAGRetriever, error) {
 //   - error: 错误
 //
 func (r *GraphRAGRetriever) Search(ctx context.Context, query string, opts ...SearchOptions) ([]*types.Document, error) {
+	ctx, run := callbacks.StartRetriever(ctx, "GraphRAGRetriever", query)
+	docs, err := r.search(ctx, query, opts...)
+	run.EndRetriever(ctx, docs, err)
+	return docs, err
+}
+
+// search 执行检索。
+func (r *GraphRAGRetriever) search(ctx context.Context, query string, opts ...SearchOptions) ([]*types.Document, error) {
 	startTime := time.Now()
 
 	// 合并选项
@@ -191,7 +201,7 @@ func (r *GraphRAGRetriever) hybridSearch(ctx context.Context, query string, opti
 
 // vectorSearch 向量检索。
 func (r *GraphRAGRetriever) vectorSearch(ctx context.Context, query string, k int) ([]*types.Document, error) {
-	docs, err := r.config.VectorStore.SimilaritySearch(ctx, query, k)
+	docs, err := vectorstores.Search(ctx, r.config.VectorStore, query, k)
 	if err != nil {
 		return nil, err
 	}
@@ -23,6 +23,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/zhucl121/langchain-go/core/callbacks"
 	"github.com/zhucl121/langchain-go/pkg/types"
 	"github.com/zhucl121/langchain-go/retrieval/loaders"
 	"github.com/zhucl121/langchain-go/retrieval/retrievers/fusion"
@@ -118,6 +119,20 @@ func NewHybridRetriever(config Config) (*HybridRetriever, error) {
 //   - []SearchResult: 融合并排序后的结果
 //   - error: 错误
 func (h *HybridRetriever) Search(ctx context.Context, query string, topK int) ([]SearchResult, error) {
+	ctx, run := callbacks.StartRetriever(ctx, "HybridRetriever", query)
+	results, err := h.search(ctx, query, topK)
+	if run != nil {
+		docs := make([]*types.Document, len(results))
+		for i := range results {
+			docs[i] = &results[i].Document
+		}
+		run.EndRetriever(ctx, docs, err)
+	}
+	return results, err
+}
+
+// search 执行混合检索
+func (h *HybridRetriever) search(ctx context.Context, query string, topK int) ([]SearchResult, error) {
 	// 确定各检索器的 TopK
 	vectorTopK := h.config.VectorTopK
 	if vectorTopK == 0 {
@@ -135,7 +150,7 @@ func (h *HybridRetriever) Search(ctx context.Context, query string, topK int) ([
 
 	// 向量检索
 	go func() {
-		results, err := h.vectorStore.SimilaritySearchWithScore(ctx, query, vectorTopK)
+		results, err := vectorstores.SearchWithScore(ctx, h.vectorStore, query, vectorTopK)
 		// 转换为文档和分数列表
 		docs := make([]types.Document, len(results))
 		scores := make([]float64, len(results))
@@ -200,7 +215,7 @@ func (h *HybridRetriever) Search(ctx context.Context, query string, topK int) ([
 
 // SearchVectorOnly 仅执行向量检索（用于对比测试）
 func (h *HybridRetriever) SearchVectorOnly(ctx context.Context, query string, topK int) ([]SearchResult, error) {
-	results, err := h.vectorStore.SimilaritySearchWithScore(ctx, query, topK)
+	results, err := vectorstores.SearchWithScore(ctx, h.vectorStore, query, topK)
 	if err != nil {
 		return nil, fmt.Errorf("vector search failed: %w", err)
 	}
@@ -92,6 +92,13 @@ func NewHyDERetriever(llm chat.ChatModel, embedder embeddings.Embeddings, vector
 
 // GetRelevantDocuments 获取相关文档
 func (r *HyDERetriever) GetRelevantDocuments(ctx context.Context, query string) ([]types.Document, error) {
+	return traceDocuments(ctx, "HyDERetriever", query, func(ctx context.Context) ([]types.Document, error) {
+		return r.getRelevantDocuments(ctx, query)
+	})
+}
+
+// getRelevantDocuments 执行检索
+func (r *HyDERetriever) getRelevantDocuments(ctx context.Context, query string) ([]types.Document, error) {
 	// 生成假设文档
 	hypotheticalDocs, err := r.generateHypotheticalDocuments(ctx, query)
 	if err != nil {
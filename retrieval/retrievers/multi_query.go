@@ -89,6 +89,13 @@ func NewMultiQueryRetriever(baseRetriever Retriever, llm chat.ChatModel, opts ..
 
 // GetRelevantDocuments 获取相关文档
 func (r *MultiQueryRetriever) GetRelevantDocuments(ctx context.Context, query string) ([]types.Document, error) {
+	return traceDocuments(ctx, "MultiQueryRetriever", query, func(ctx context.Context) ([]types.Document, error) {
+		return r.getRelevantDocuments(ctx, query)
+	})
+}
+
+// getRelevantDocuments 执行检索
+func (r *MultiQueryRetriever) getRelevantDocuments(ctx context.Context, query string) ([]types.Document, error) {
 	// 生成查询变体
 	queries, err := r.generateQueries(ctx, query)
 	if err != nil {
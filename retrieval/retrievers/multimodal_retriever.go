@@ -120,7 +120,7 @@ func (r *MultimodalRetriever) Search(ctx context.Context, query *types.Multimoda
 	// 注意: 这里需要 VectorStore 支持向量查询
 	// 简化实现使用文本查询
 	queryText := r.contentToString(query)
-	results, err := r.config.VectorStore.SimilaritySearchWithScore(ctx, queryText, k*2) // 多检索一些以便过滤
+	results, err := vectorstores.SearchWithScore(ctx, r.config.VectorStore, queryText, k*2) // 多检索一些以便过滤
 	if err != nil {
 		return nil, fmt.Errorf("failed to search: %w", err)
 	}
@@ -160,6 +160,13 @@ func (r *ParentDocumentRetriever) AddDocuments(ctx context.Context, documents []
 
 // GetRelevantDocuments 获取相关文档
 func (r *ParentDocumentRetriever) GetRelevantDocuments(ctx context.Context, query string) ([]types.Document, error) {
+	return traceDocuments(ctx, "ParentDocumentRetriever", query, func(ctx context.Context) ([]types.Document, error) {
+		return r.getRelevantDocuments(ctx, query)
+	})
+}
+
+// getRelevantDocuments 执行检索
+func (r *ParentDocumentRetriever) getRelevantDocuments(ctx context.Context, query string) ([]types.Document, error) {
 	// 从向量存储检索子文档
 	childDocs, err := r.vectorStore.SimilaritySearch(ctx, query, r.config.TopK)
 	if err != nil {
@@ -22,6 +22,8 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 
+	"github.com/zhucl121/langchain-go/core/callbacks"
+	"github.com/zhucl121/langchain-go/pkg/types"
 	"github.com/zhucl121/langchain-go/retrieval/loaders"
 )
 
@@ -108,25 +110,50 @@ func (b *BaseRetriever) GetMetadata(key string) interface{} {
 	return b.metadata[key]
 }
 
-// triggerStart 触发开始回调
-func (b *BaseRetriever) triggerStart(ctx context.Context, query string) {
+// triggerStart 触发开始回调，并开始一次检索器运行（见 callbacks.StartRetriever）
+//
+// 返回的上下文携带本次运行，检索器内部的调用应使用它。
+//
+func (b *BaseRetriever) triggerStart(ctx context.Context, name, query string) (context.Context, *callbacks.Run) {
+	ctx, run := callbacks.StartRetriever(ctx, name, query)
 	for _, cb := range b.callbacks {
 		cb.OnRetrieverStart(ctx, query)
 	}
+	return ctx, run
 }
 
-// triggerEnd 触发结束回调
-func (b *BaseRetriever) triggerEnd(ctx context.Context, docs []*loaders.Document) {
+// triggerEnd 触发结束回调并结束检索器运行
+func (b *BaseRetriever) triggerEnd(ctx context.Context, run *callbacks.Run, docs []*loaders.Document) {
 	for _, cb := range b.callbacks {
 		cb.OnRetrieverEnd(ctx, docs)
 	}
+	run.EndRetriever(ctx, docs, nil)
 }
 
-// triggerError 触发错误回调
-func (b *BaseRetriever) triggerError(ctx context.Context, err error) {
+// triggerError 触发错误回调并结束检索器运行
+func (b *BaseRetriever) triggerError(ctx context.Context, run *callbacks.Run, err error) {
 	for _, cb := range b.callbacks {
 		cb.OnRetrieverError(ctx, err)
 	}
+	run.EndRetriever(ctx, nil, err)
+}
+
+// traceDocuments 在一次检索器运行中执行返回 []types.Document 的检索
+func traceDocuments(
+	ctx context.Context,
+	name, query string,
+	retrieve func(ctx context.Context) ([]types.Document, error),
+) ([]types.Document, error) {
+	ctx, run := callbacks.StartRetriever(ctx, name, query)
+	docs, err := retrieve(ctx)
+	if run != nil {
+		ptrs := make([]*types.Document, len(docs))
+		for i := range docs {
+			ptrs[i] = &docs[i]
+		}
+		run.EndRetriever(ctx, ptrs, err)
+	}
+	return docs, err
 }
 
 // SearchType 搜索类型
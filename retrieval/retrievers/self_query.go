@@ -120,6 +120,13 @@ func NewSelfQueryRetriever(
 
 // GetRelevantDocuments 获取相关文档
 func (r *SelfQueryRetriever) GetRelevantDocuments(ctx context.Context, query string) ([]types.Document, error) {
+	return traceDocuments(ctx, "SelfQueryRetriever", query, func(ctx context.Context) ([]types.Document, error) {
+		return r.getRelevantDocuments(ctx, query)
+	})
+}
+
+// getRelevantDocuments 执行检索
+func (r *SelfQueryRetriever) getRelevantDocuments(ctx context.Context, query string) ([]types.Document, error) {
 	// 解析查询
 	structuredQuery, err := r.parseQuery(ctx, query)
 	if err != nil {
@@ -97,7 +97,7 @@ func WithFilter(filter map[string]interface{}) VectorStoreOption {
 //
 func (r *VectorStoreRetriever) GetRelevantDocuments(ctx context.Context, query string) ([]*loaders.Document, error) {
 	// 触发开始回调
-	r.triggerStart(ctx, query)
+	ctx, run := r.triggerStart(ctx, "VectorStoreRetriever", query)
 
 	var docs []*loaders.Document
 	var err error
@@ -105,7 +105,7 @@ func (r *VectorStoreRetriever) GetRelevantDocuments(ctx context.Context, query s
 	// 根据搜索类型执行不同的检索策略
 	switch r.searchType {
 	case SearchSimilarity:
-		docs, err = r.vectorStore.SimilaritySearch(ctx, query, r.k)
+		docs, err = vectorstores.Search(ctx, r.vectorStore, query, r.k)
 
 	case SearchMMR:
 		// MMR 搜索需要向量存储支持
@@ -113,10 +113,12 @@ func (r *VectorStoreRetriever) GetRelevantDocuments(ctx context.Context, query s
 		if mmrStore, ok := r.vectorStore.(interface {
 			MMRSearch(ctx context.Context, query string, k int) ([]*loaders.Document, error)
 		}); ok {
-			docs, err = mmrStore.MMRSearch(ctx, query, r.k)
+			docs, err = vectorstores.TraceQuery(ctx, r.vectorStore, query, func(ctx context.Context) ([]*loaders.Document, error) {
+				return mmrStore.MMRSearch(ctx, query, r.k)
+			})
 		} else {
 			// 降级到相似度搜索
-			docs, err = r.vectorStore.SimilaritySearch(ctx, query, r.k)
+			docs, err = vectorstores.Search(ctx, r.vectorStore, query, r.k)
 		}
 
 	case SearchHybrid:
@@ -126,7 +128,7 @@ func (r *VectorStoreRetriever) GetRelevantDocuments(ctx context.Context, query s
 		}); ok {
 			results, err := hybridStore.HybridSearch(ctx, query, r.k, r.filter)
 			if err != nil {
-				r.triggerError(ctx, err)
+				r.triggerError(ctx, run, err)
 				return nil, err
 			}
 
@@ -141,15 +143,15 @@ func (r *VectorStoreRetriever) GetRelevantDocuments(ctx context.Context, query s
 			}
 		} else {
 			// 降级到相似度搜索
-			docs, err = r.vectorStore.SimilaritySearch(ctx, query, r.k)
+			docs, err = vectorstores.Search(ctx, r.vectorStore, query, r.k)
 		}
 
 	default:
-		docs, err = r.vectorStore.SimilaritySearch(ctx, query, r.k)
+		docs, err = vectorstores.Search(ctx, r.vectorStore, query, r.k)
 	}
 
 	if err != nil {
-		r.triggerError(ctx, err)
+		r.triggerError(ctx, run, err)
 		return nil, fmt.Errorf("search failed: %w", err)
 	}
 
@@ -159,7 +161,7 @@ func (r *VectorStoreRetriever) GetRelevantDocuments(ctx context.Context, query s
 	}
 
 	// 触发结束回调
-	r.triggerEnd(ctx, docs)
+	r.triggerEnd(ctx, run, docs)
 
 	return docs, nil
 }
@@ -170,12 +172,12 @@ func (r *VectorStoreRetriever) GetRelevantDocuments(ctx context.Context, query s
 //
 func (r *VectorStoreRetriever) GetRelevantDocumentsWithScore(ctx context.Context, query string) ([]DocumentWithScore, error) {
 	// 触发开始回调
-	r.triggerStart(ctx, query)
+	ctx, run := r.triggerStart(ctx, "VectorStoreRetriever", query)
 
 	// 使用带分数的搜索
-	results, err := r.vectorStore.SimilaritySearchWithScore(ctx, query, r.k)
+	results, err := vectorstores.SearchWithScore(ctx, r.vectorStore, query, r.k)
 	if err != nil {
-		r.triggerError(ctx, err)
+		r.triggerError(ctx, run, err)
 		return nil, fmt.Errorf("search with score failed: %w", err)
 	}
 
@@ -210,7 +212,7 @@ func (r *VectorStoreRetriever) GetRelevantDocumentsWithScore(ctx context.Context
 	for i, d := range docs {
 		plainDocs[i] = d.Document
 	}
-	r.triggerEnd(ctx, plainDocs)
+	r.triggerEnd(ctx, run, plainDocs)
 
 	return docs, nil
 }
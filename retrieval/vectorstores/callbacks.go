@@ -0,0 +1,67 @@
+package vectorstores
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/zhucl121/langchain-go/core/callbacks"
+	"github.com/zhucl121/langchain-go/retrieval/loaders"
+)
+
+// Search 在 store 中执行相似度搜索。
+//
+// 搜索作为一次向量存储查询运行（callbacks.RunTypeVectorStore）发送回调，
+// 运行名称为 store 的类型名。检索器应通过本函数而不是直接调用
+// store.SimilaritySearch，使查询出现在运行树中。
+//
+// 参数：
+//   - ctx: 上下文
+//   - store: 向量存储
+//   - query: 查询文本
+//   - k: 返回结果数量
+//
+// 返回：
+//   - []*loaders.Document: 相似文档列表
+//   - error: 错误
+//
+func Search(ctx context.Context, store VectorStore, query string, k int) ([]*loaders.Document, error) {
+	return TraceQuery(ctx, store, query, func(ctx context.Context) ([]*loaders.Document, error) {
+		return store.SimilaritySearch(ctx, query, k)
+	})
+}
+
+// SearchWithScore 在 store 中执行带分数的相似度搜索，回调与 Search 相同。
+func SearchWithScore(ctx context.Context, store VectorStore, query string, k int) ([]DocumentWithScore, error) {
+	ctx, run := callbacks.StartVectorStore(ctx, StoreName(store), query)
+	results, err := store.SimilaritySearchWithScore(ctx, query, k)
+	if run != nil {
+		docs := make([]*loaders.Document, len(results))
+		for i, result := range results {
+			docs[i] = result.Document
+		}
+		run.EndRetriever(ctx, docs, err)
+	}
+	return results, err
+}
+
+// TraceQuery 把 fn 中对 store 的查询作为一次向量存储查询运行。
+//
+// 用于 MMR、混合搜索等 VectorStore 接口之外的查询方法。
+func TraceQuery(ctx context.Context, store any, query string, fn func(ctx context.Context) ([]*loaders.Document, error)) ([]*loaders.Document, error) {
+	ctx, run := callbacks.StartVectorStore(ctx, StoreName(store), query)
+	docs, err := fn(ctx)
+	run.EndRetriever(ctx, docs, err)
+	return docs, err
+}
+
+// StoreName 返回向量存储的名称（去掉指针的类型名，例如 "InMemoryVectorStore"）。
+func StoreName(store any) string {
+	t := reflect.TypeOf(store)
+	if t == nil {
+		return ""
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Name()
+}
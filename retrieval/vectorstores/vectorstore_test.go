@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	
+	"github.com/zhucl121/langchain-go/core/callbacks"
 	"github.com/zhucl121/langchain-go/retrieval/embeddings"
 	"github.com/zhucl121/langchain-go/retrieval/loaders"
 )
@@ -266,3 +267,47 @@ func BenchmarkInMemoryVectorStore_SimilaritySearch(b *testing.B) {
 		_, _ = store.SimilaritySearch(ctx, "query", 10)
 	}
 }
+
+// queryRecorder 记录向量存储查询运行
+type queryRecorder struct {
+	callbacks.BaseHandler
+	starts []*callbacks.RetrieverStartEvent
+	ends   []*callbacks.RetrieverEndEvent
+}
+
+func (r *queryRecorder) OnRetrieverStart(ctx context.Context, e *callbacks.RetrieverStartEvent) {
+	r.starts = append(r.starts, e)
+}
+
+func (r *queryRecorder) OnRetrieverEnd(ctx context.Context, e *callbacks.RetrieverEndEvent) {
+	r.ends = append(r.ends, e)
+}
+
+// TestSearch_Callbacks
+func TestSearch_Callbacks(t *testing.T) {
+	store := NewInMemoryVectorStore(embeddings.NewFakeEmbeddings(16))
+	_, err := store.AddDocuments(context.Background(), []*loaders.Document{
+		loaders.NewDocument("Go is fast", nil),
+		loaders.NewDocument("Python is popular", nil),
+	})
+	require.NoError(t, err)
+
+	rec := &queryRecorder{}
+	ctx := callbacks.WithHandlers(context.Background(), rec)
+
+	docs, err := Search(ctx, store, "go", 1)
+	require.NoError(t, err)
+	assert.Len(t, docs, 1)
+
+	scored, err := SearchWithScore(ctx, store, "python", 2)
+	require.NoError(t, err)
+	assert.Len(t, scored, 2)
+
+	require.Len(t, rec.starts, 2)
+	require.Len(t, rec.ends, 2)
+	assert.Equal(t, callbacks.RunTypeVectorStore, rec.starts[0].Type)
+	assert.Equal(t, "InMemoryVectorStore", rec.starts[0].Name)
+	assert.Equal(t, "go", rec.starts[0].Query)
+	assert.Len(t, rec.ends[0].Documents, 1)
+	assert.Len(t, rec.ends[1].Documents, 2)
+}